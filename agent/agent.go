@@ -34,6 +34,8 @@ type Agent struct {
 	logOutput io.Writer
 
 	client *ucli.Client
+	// recent log lines of the allocations run by the client
+	allocLogs *allocLogs
 
 	server *usrv.Server
 
@@ -49,7 +51,9 @@ func NewAgent(config *Config, logOutput io.Writer, log *logrus.Logger) (*Agent,
 		logger:     log,
 		logOutput:  logOutput,
 		shutdownCh: make(chan struct{}),
+		allocLogs:  newAllocLogs(log.Formatter),
 	}
+	log.AddHook(a.allocLogs)
 	if err := a.setupServer(); err != nil {
 		return nil, err
 	}
@@ -191,6 +195,7 @@ func (a *Agent) clientConfig() (*uconf.ClientConfig, error) {
 	conf.Node.HTTPAddr = fmt.Sprintf("%s:%d", a.config.BindAddr, a.config.Ports.HTTP) //a.config.AdvertiseAddrs.HTTP
 	conf.Node.NatsAddr = a.config.normalizedAddrs.Nats
 	conf.Node.NatsAdvertiseAddr = a.config.AdvertiseAddrs.Nats
+	conf.Node.MaxRunningTasks = a.config.Client.MaxRunningTasks
 
 	conf.Version = a.config.Version
 
@@ -206,6 +211,7 @@ func (a *Agent) clientConfig() (*uconf.ClientConfig, error) {
 	conf.PublishAllocationMetrics = a.config.Metric.PublishAllocationMetrics
 
 	conf.NoHostUUID = a.config.Client.NoHostUUID
+	conf.Notifications = a.config.Client.Notifications
 
 	return conf, nil
 }
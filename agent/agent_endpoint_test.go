@@ -7,13 +7,13 @@
 package agent
 
 import (
-	"log"
 	"net"
 	"net/http"
 	"reflect"
 	"testing"
 
 	"github.com/hashicorp/serf/serf"
+	"github.com/sirupsen/logrus"
 )
 
 func Test_udupMember(t *testing.T) {
@@ -41,7 +41,7 @@ func TestHTTPServer_AgentSelfRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -83,7 +83,7 @@ func TestHTTPServer_AgentJoinRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -125,7 +125,7 @@ func TestHTTPServer_AgentMembersRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -167,7 +167,7 @@ func TestHTTPServer_AgentForceLeaveRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -209,7 +209,7 @@ func TestHTTPServer_AgentServersRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -251,7 +251,7 @@ func TestHTTPServer_listServers(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -293,7 +293,7 @@ func TestHTTPServer_updateServers(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
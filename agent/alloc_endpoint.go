@@ -9,6 +9,7 @@ package agent
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	umodel "github.com/actiontech/dtle/internal/models"
@@ -94,6 +95,10 @@ func (s *HTTPServer) ClientAllocRequest(resp http.ResponseWriter, req *http.Requ
 	switch tokens[1] {
 	case "stats":
 		return s.allocStats(allocID, resp, req)
+	case "logs":
+		return s.allocLogs(allocID, resp, req)
+	case "health":
+		return s.allocHealth(allocID, resp, req)
 	}
 
 	return nil, CodedError(404, resourceNotFoundErr)
@@ -109,3 +114,79 @@ func (s *HTTPServer) allocStats(allocID string, resp http.ResponseWriter, req *h
 	task := req.URL.Query().Get("task")
 	return aStats.LatestAllocStats(task)
 }
+
+// allocHealth is for health checks, e.g. of Consul or a liveness probe of Kubernetes. It responds 200 if the
+// task of an allocation run on the agent is healthy, and 503 with the reason otherwise.
+// See driver.HealthChecker.
+func (s *HTTPServer) allocHealth(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	checker, err := s.agent.client.GetAllocHealth(allocID)
+	if err != nil {
+		return nil, CodedError(404, err.Error())
+	}
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	// not by CodedError, which logs an error on each check
+	if err := checker.CheckHealth(); err != nil {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(resp, err)
+		return nil, nil
+	}
+	fmt.Fprintln(resp, "OK")
+	return nil, nil
+}
+
+// allocLogs writes the recent log lines of the tasks of an allocation run on the agent, in plain text.
+// With follow=true, the new lines are streamed until the request is canceled. With origin=end, only
+// the new lines are written.
+func (s *HTTPServer) allocLogs(allocID string, resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	follow := false
+	if v := req.URL.Query().Get("follow"); v != "" {
+		var err error
+		if follow, err = strconv.ParseBool(v); err != nil {
+			return nil, CodedError(400, fmt.Sprintf("Failed to parse follow: %v", err))
+		}
+	}
+	origin := req.URL.Query().Get("origin")
+	switch origin {
+	case "", "start", "end":
+	default:
+		return nil, CodedError(400, fmt.Sprintf("Invalid origin %q: must be start or end", origin))
+	}
+
+	lines, ch, cancel := s.agent.allocLogs.follow(allocID, follow)
+	defer cancel()
+	if origin == "end" {
+		lines = nil
+	}
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		if _, err := resp.Write(line); err != nil {
+			return nil, nil
+		}
+	}
+	if !follow {
+		return nil, nil
+	}
+
+	flusher, _ := resp.(http.Flusher)
+	for {
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case line := <-ch:
+			if _, err := resp.Write(line); err != nil {
+				return nil, nil
+			}
+		case <-req.Context().Done():
+			return nil, nil
+		case <-s.agent.shutdownCh:
+			return nil, nil
+		}
+	}
+}
@@ -90,6 +90,7 @@ func (c *Command) readConfig() *Config {
 	flags.StringVar(&cmdConfig.DataDir, "data-dir", "", "")
 	flags.StringVar(&cmdConfig.Datacenter, "dc", "", "")
 	flags.StringVar(&cmdConfig.LogLevel, "log-level", "", "")
+	flags.StringVar(&cmdConfig.LogFormat, "log-format", "", "")
 	flags.StringVar(&cmdConfig.PidFile, "pid-file", "", "")
 	flags.BoolVar(&cmdConfig.PprofSwitch, "pprof-switch", false, "")
 	flags.Int64Var(&cmdConfig.PprofTime, "pprof-time", 0, "")
@@ -98,6 +99,7 @@ func (c *Command) readConfig() *Config {
 	flags.StringVar(&cmdConfig.NodeName, "node", "", "")
 	flags.StringVar(&cmdConfig.JaegerAgentAddress, "jaeger-agent-address", "", "")
 	flags.StringVar(&cmdConfig.JaegerAgentPort, "jaeger-agent-port", "", "")
+	flags.Float64Var(&cmdConfig.JaegerSamplerRate, "jaeger-sampler-rate", 0, "")
 
 	if err := flags.Parse(c.args); err != nil {
 		return nil
@@ -183,6 +185,11 @@ func (c *Command) readConfig() *Config {
 		return nil
 	}
 
+	if config.JaegerSamplerRate < 0 || config.JaegerSamplerRate > 1 {
+		c.Ui.Error(fmt.Sprintf("jaeger_sampler_rate must be in (0, 1]: got %v", config.JaegerSamplerRate))
+		return nil
+	}
+
 	// Verify the paths are absolute.
 	dirs := map[string]string{
 		"data-dir":  config.DataDir,
@@ -230,6 +237,13 @@ func (c *Command) setupLoggers(config *Config) (io.Writer, error) {
 	}
 	c.logger = logrus.New()
 	c.logger.SetLevel(logrus.DebugLevel) //ulog.New(oFile, ulog.ParseLevel(config.LogLevel))
+	switch config.LogFormat {
+	case "", "text":
+	case "json":
+		c.logger.Formatter = &logrus.JSONFormatter{}
+	default:
+		return nil, fmt.Errorf("unknown log_format %v. should be text or json", config.LogFormat)
+	}
 	//log.SetOutput(c.logOutput)
 	c.logger.SetOutput(c.logOutput)
 	return c.logOutput, nil
@@ -276,10 +290,7 @@ func (c *Command) Run(args []string) int {
 	}
 	if config.JaegerAgentAddress != "" && config.JaegerAgentPort != "" {
 		cfg := jaegercnf.Configuration{
-			Sampler: &jaegercnf.SamplerConfig{
-				Type:  "const",
-				Param: 1,
-			},
+			Sampler:     jaegerSamplerConfig(config.JaegerSamplerRate),
 			ServiceName: "dtle",
 			Reporter: &jaegercnf.ReporterConfig{
 				LogSpans:            true,
@@ -357,6 +368,7 @@ func (c *Command) Run(args []string) int {
 	info["revision"] = config.Revision
 	info["agent"] = strconv.FormatBool(config.Client.Enabled)
 	info["log level"] = config.LogLevel
+	info["log format"] = config.LogFormat
 	info["manager"] = strconv.FormatBool(config.Server.Enabled)
 	//info["region"] = fmt.Sprintf("%s (DC: %s)", config.Region, config.Datacenter)
 
@@ -508,6 +520,20 @@ func (c *Command) setupMetric(config *Config) error {
 	return nil
 }
 
+// jaegerSamplerConfig samples all the traces, or the fraction rate of them.
+func jaegerSamplerConfig(rate float64) *jaegercnf.SamplerConfig {
+	if rate == 0 || rate >= 1 {
+		return &jaegercnf.SamplerConfig{
+			Type:  jaeger.SamplerTypeConst,
+			Param: 1,
+		}
+	}
+	return &jaegercnf.SamplerConfig{
+		Type:  jaeger.SamplerTypeProbabilistic,
+		Param: rate,
+	}
+}
+
 func (c *Command) startupJoin(config *Config) error {
 	if len(config.Server.StartJoin) == 0 || !config.Server.Enabled {
 		return nil
@@ -597,6 +623,12 @@ General Options (agents and managers):
     DEBUG, INFO, and WARN, in decreasing order of verbosity. The
     default is INFO.
 
+  -log-format=<format>
+    Specify the format of Dtle's logs. Valid values are "text" and "json".
+    With "json", each entry is a JSON line with fields such as job, alloc,
+    task and event, which can be used to correlate logs of a job. The
+    default is text.
+
   -node=<name>
     The name of the local server. This name is used to identify the node
     in the cluster. The name must be unique per region. The default is
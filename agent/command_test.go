@@ -8,12 +8,13 @@ package agent
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"reflect"
 	"testing"
-	ulog "github.com/actiontech/dtle/internal/logger"
 
 	"github.com/mitchellh/cli"
+	"github.com/sirupsen/logrus"
 )
 
 func TestCommand_readConfig(t *testing.T) {
@@ -24,7 +25,7 @@ func TestCommand_readConfig(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -101,7 +102,7 @@ func TestCommand_setupLoggers(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -135,7 +136,7 @@ func TestCommand_setupLoggers(t *testing.T) {
 				t.Errorf("Command.setupLoggers() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got := in0.String(); got != tt.want {
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Command.setupLoggers() = %v, want %v", got, tt.want)
 			}
 		})
@@ -150,7 +151,7 @@ func TestCommand_setupAgent(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -199,7 +200,7 @@ func TestCommand_Run(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -242,7 +243,7 @@ func TestCommand_handleSignals(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -285,7 +286,7 @@ func TestCommand_handleReload(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -328,7 +329,7 @@ func TestCommand_setupMetric(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -371,7 +372,7 @@ func TestCommand_startupJoin(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -414,7 +415,7 @@ func TestCommand_retryJoin(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -454,7 +455,7 @@ func TestCommand_Synopsis(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -493,7 +494,7 @@ func TestCommand_Help(t *testing.T) {
 		args           []string
 		agent          *Agent
 		httpServer     *HTTPServer
-		logger         *ulog.Logger
+		logger         *logrus.Logger
 		logOutput      io.Writer
 		retryJoinErrCh chan struct{}
 	}
@@ -523,3 +524,28 @@ func TestCommand_Help(t *testing.T) {
 		})
 	}
 }
+
+func TestCommand_setupLoggers_LogFormat(t *testing.T) {
+	c := &Command{}
+	_, err := c.setupLoggers(&Config{LogToStdout: true, LogFormat: "json"})
+	if err != nil {
+		t.Fatalf("Command.setupLoggers() error = %v", err)
+	}
+	buf := new(bytes.Buffer)
+	c.logger.SetOutput(buf)
+	c.logger.WithFields(logrus.Fields{"job": "j1", "alloc": "a1", "task": "Src"}).Infof("hello")
+
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log entry is not JSON: %v. entry: %s", err, buf.String())
+	}
+	for k, v := range map[string]string{"job": "j1", "alloc": "a1", "task": "Src", "msg": "hello"} {
+		if entry[k] != v {
+			t.Errorf("log entry field %v = %v, want %v", k, entry[k], v)
+		}
+	}
+
+	if _, err := c.setupLoggers(&Config{LogToStdout: true, LogFormat: "xml"}); err == nil {
+		t.Errorf("Command.setupLoggers() with unknown LogFormat: want error")
+	}
+}
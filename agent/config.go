@@ -52,6 +52,10 @@ type Config struct {
 
 	LogToStdout bool `mapstructure:"log_to_stdout"`
 
+	// LogFormat is "text" or "json". With "json", each entry is a JSON line
+	// with fields like job, alloc and task.
+	LogFormat string `mapstructure:"log_format"`
+
 	// file to write our pid to
 	PidFile string `mapstructure:"pid_file"`
 
@@ -137,10 +141,13 @@ type Config struct {
 	// CoverageReportRawCodeDir is the root deploy directory of coverage report raw code
 	CoverageReportRawCodeDir string `mapstructure:"coverage_report_raw_code_dir"`
 
-	//jaegerAgentAddress is jaeger tracing Data reporting address
+	//jaegerAgentAddress is jaeger tracing Data reporting address. The traces are OpenTracing spans sent to a
+	//Jaeger agent over UDP. There is no OpenTelemetry exporter.
 	JaegerAgentAddress string `mapstructure:"jaeger_agent_address"`
 	//jaegerAgentPort is jaeger tracing Data reporting port
 	JaegerAgentPort string `mapstructure:"jaeger_agent_port"`
+	// JaegerSamplerRate is the fraction of the traces sampled, in (0, 1]. 0 means 1, i.e. all of them.
+	JaegerSamplerRate float64 `mapstructure:"jaeger_sampler_rate"`
 }
 
 // ClientConfig is configuration specific to the client mode
@@ -157,6 +164,12 @@ type ClientConfig struct {
 	// NoHostUUID disables using the host's UUID and will force generation of a
 	// random UUID.
 	NoHostUUID bool `mapstructure:"no_host_uuid"`
+
+	// Notifications are webhooks notified of job lifecycle events
+	Notifications []*uconf.NotificationConfig `mapstructure:"notification"`
+
+	// MaxRunningTasks is the most tasks placed on the agent at a time. 0 means no limit.
+	MaxRunningTasks int `mapstructure:"max_running_tasks"`
 }
 
 // ServerConfig is configuration specific to the server mode
@@ -264,6 +277,7 @@ func DefaultConfig() *Config {
 		LogMaxSize:         1024,
 		LogMaxBackups:      100,
 		LogToStdout:        false,
+		LogFormat:          "text",
 		PprofSwitch:        false,
 		PprofTime:          0,
 		PidFile:            "/var/run/dtle/dtle.pid",
@@ -372,6 +386,9 @@ func (c *Config) Merge(b *Config) *Config {
 	if b.LogToStdout {
 		result.LogToStdout = b.LogToStdout
 	}
+	if b.LogFormat != "" {
+		result.LogFormat = b.LogFormat
+	}
 	if b.PidFile != "" {
 		result.PidFile = b.PidFile
 	}
@@ -399,6 +416,9 @@ func (c *Config) Merge(b *Config) *Config {
 	if b.JaegerAgentPort != "" {
 		result.JaegerAgentPort = b.JaegerAgentPort
 	}
+	if b.JaegerSamplerRate != 0 {
+		result.JaegerSamplerRate = b.JaegerSamplerRate
+	}
 
 	// Apply the metric config
 	if result.Metric == nil && b.Metric != nil {
@@ -625,10 +645,16 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.NoHostUUID {
 		result.NoHostUUID = b.NoHostUUID
 	}
+	if b.MaxRunningTasks != 0 {
+		result.MaxRunningTasks = b.MaxRunningTasks
+	}
 
 	// Add the servers
 	result.Servers = append(result.Servers, b.Servers...)
 
+	// Add the notifications
+	result.Notifications = append(result.Notifications, b.Notifications...)
+
 	return &result
 }
 
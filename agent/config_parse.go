@@ -88,6 +88,7 @@ func parseConfig(result *Config, list *ast.ObjectList) error {
 		"log_max_size",
 		"log_max_backups",
 		"log_to_stdout",
+		"log_format",
 		"log_file",
 		"pprof_switch",
 		"pprof_time",
@@ -108,6 +109,7 @@ func parseConfig(result *Config, list *ast.ObjectList) error {
 		"dtle_schema_name",
 		"jaeger_agent_address",
 		"jaeger_agent_port",
+		"jaeger_sampler_rate",
 	}
 	if err := checkHCLKeys(list, valid); err != nil {
 		return multierror.Prefix(err, "config:")
@@ -327,6 +329,8 @@ func parseClient(result **ClientConfig, list *ast.ObjectList) error {
 		"managers",
 		"stats",
 		"no_host_uuid",
+		"notification",
+		"max_running_tasks",
 	}
 	if err := checkHCLKeys(listVal, valid); err != nil {
 		return err
@@ -7,11 +7,12 @@
 package agent
 
 import (
-	"log"
 	"net"
 	"net/http"
 	"reflect"
 	"testing"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestHTTPServer_EvalsRequest(t *testing.T) {
@@ -19,7 +20,7 @@ func TestHTTPServer_EvalsRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -61,7 +62,7 @@ func TestHTTPServer_EvalSpecificRequest(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -103,7 +104,7 @@ func TestHTTPServer_evalAllocations(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -146,7 +147,7 @@ func TestHTTPServer_evalQuery(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
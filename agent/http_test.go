@@ -10,12 +10,14 @@ import (
 	"bytes"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	_ "net/http/pprof"
 	"reflect"
 	"testing"
 	"time"
-	log "github.com/actiontech/dtle/internal/logger"
+
 	umodel "github.com/actiontech/dtle/internal/models"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewHTTPServer(t *testing.T) {
@@ -84,7 +86,7 @@ func TestHTTPServer_Shutdown(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	tests := []struct {
@@ -112,7 +114,7 @@ func TestHTTPServer_registerHandlers(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	tests := []struct {
@@ -211,17 +213,17 @@ func TestHTTPServer_wrap(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
 		handler func(resp http.ResponseWriter, req *http.Request) (interface{}, error)
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   func(resp http.ResponseWriter, req *http.Request)
+		name     string
+		fields   fields
+		args     args
+		wantCode int
 	}{
 		// TODO: Add test cases.
 	}
@@ -234,8 +236,10 @@ func TestHTTPServer_wrap(t *testing.T) {
 				logger:   tt.fields.logger,
 				addr:     tt.fields.addr,
 			}
-			if got := s.wrap(tt.args.handler); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("HTTPServer.wrap() = %v, want %v", got, tt.want)
+			resp := httptest.NewRecorder()
+			s.wrap(tt.args.handler)(resp, httptest.NewRequest("GET", "/v1/jobs", nil))
+			if resp.Code != tt.wantCode {
+				t.Errorf("HTTPServer.wrap() code = %v, want %v", resp.Code, tt.wantCode)
 			}
 		})
 	}
@@ -415,7 +419,7 @@ func TestHTTPServer_parseRegion(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
@@ -448,7 +452,7 @@ func TestHTTPServer_parse(t *testing.T) {
 		agent    *Agent
 		mux      *http.ServeMux
 		listener net.Listener
-		logger   *log.Logger
+		logger   *logrus.Logger
 		addr     string
 	}
 	type args struct {
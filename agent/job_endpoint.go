@@ -7,11 +7,15 @@
 package agent
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/go-cleanhttp"
 	"github.com/mitchellh/mapstructure"
 
 	"github.com/actiontech/dtle/api"
@@ -127,6 +131,13 @@ func (s *HTTPServer) jobListRequest(resp http.ResponseWriter, req *http.Request)
 	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
 		return nil, nil
 	}
+	if filter := req.URL.Query().Get("tag"); filter != "" {
+		tags, err := models.ParseTagFilter(filter)
+		if err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+		args.Tags = tags
+	}
 
 	var out models.JobListResponse
 	if err := s.agent.RPC("Job.List", &args, &out); err != nil {
@@ -155,6 +166,12 @@ func (s *HTTPServer) JobSpecificRequest(resp http.ResponseWriter, req *http.Requ
 	case strings.HasSuffix(path, "/evaluations"):
 		jobName := strings.TrimSuffix(path, "/evaluations")
 		return s.jobEvaluations(resp, req, jobName)
+	case strings.HasSuffix(path, "/errors"):
+		jobName := strings.TrimSuffix(path, "/errors")
+		return s.jobErrors(resp, req, jobName)
+	case strings.HasSuffix(path, "/resync"):
+		jobName := strings.TrimSuffix(path, "/resync")
+		return s.jobResyncRequest(resp, req, jobName)
 	default:
 		return s.jobCRUD(resp, req, path)
 	}
@@ -217,6 +234,178 @@ func (s *HTTPServer) jobEvaluations(resp http.ResponseWriter, req *http.Request,
 	return out.Evaluations, nil
 }
 
+// jobErrors returns the recent errors of the running tasks of a job, oldest first.
+// Query parameter n limits the result to the last n errors.
+func (s *HTTPServer) jobErrors(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	n := 0
+	if nStr := req.URL.Query().Get("n"); nStr != "" {
+		var err error
+		if n, err = strconv.Atoi(nStr); err != nil || n < 0 {
+			return nil, CodedError(400, fmt.Sprintf("bad n: %v", nStr))
+		}
+	}
+	args := models.JobSpecificRequest{
+		JobID: jobName,
+	}
+	if args.Region == "" {
+		args.Region = s.agent.config.Region
+	}
+	if s.parse(resp, req, &args.Region, &args.QueryOptions) {
+		return nil, nil
+	}
+
+	var out models.JobAllocationsResponse
+	if err := s.agent.RPC("Job.Allocations", &args, &out); err != nil {
+		return nil, err
+	}
+
+	jobErrors := make([]*models.JobError, 0)
+	for _, alloc := range out.Allocations {
+		if alloc.ClientStatus != models.AllocClientStatusRunning {
+			continue
+		}
+		stats, err := s.allocStatsOnNode(alloc, args.Region)
+		if err != nil {
+			s.logger.Warnf("http: failed to get the stats of allocation %v: %v", alloc.ID, err)
+			continue
+		}
+		for task, taskStats := range stats.Tasks {
+			for _, record := range taskStats.RecentErrors {
+				jobErrors = append(jobErrors, &models.JobError{AllocID: alloc.ID, Task: task, ErrorRecord: *record})
+			}
+		}
+	}
+	sort.SliceStable(jobErrors, func(i, j int) bool {
+		return jobErrors[i].Time < jobErrors[j].Time
+	})
+	if n > 0 && len(jobErrors) > n {
+		jobErrors = jobErrors[len(jobErrors)-n:]
+	}
+	return jobErrors, nil
+}
+
+// jobResyncRequest copies a table of a job again, while the incremental copy of the other tables goes on.
+// It sets ResyncTable of the source task, which is taken by the running task.
+func (s *HTTPServer) jobResyncRequest(resp http.ResponseWriter, req *http.Request,
+	jobName string) (interface{}, error) {
+	if !(req.Method == "POST" || req.Method == "PUT") {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+	table := req.URL.Query().Get("table")
+	dot := strings.Index(table, ".")
+	if dot <= 0 || dot == len(table)-1 {
+		return nil, CodedError(400, fmt.Sprintf("bad table: %q. should be <schema>.<table>", table))
+	}
+	args := models.JobSpecificRequest{
+		JobID: jobName,
+	}
+	if args.Region == "" {
+		args.Region = s.agent.config.Region
+	}
+	s.parseRegion(req, &args.Region)
+
+	var out models.SingleJobResponse
+	if err := s.agent.RPC("Job.GetJob", &args, &out); err != nil {
+		return nil, err
+	}
+	if out.Job == nil {
+		return nil, CodedError(404, "job not found")
+	}
+
+	job := out.Job.Copy()
+	var srcTask *models.Task
+	for _, task := range job.Tasks {
+		if task.Driver == models.TaskDriverKafka {
+			return nil, CodedError(400, "resync is not supported for a job with a Kafka destination")
+		}
+		if task.Type == models.TaskTypeSrc && task.Driver == models.TaskDriverMySQL {
+			srcTask = task
+		}
+	}
+	if srcTask == nil {
+		return nil, CodedError(400, "resync requires a MySQL source task")
+	}
+	var driverConfig config.MySQLDriverConfig
+	if err := mapstructure.WeakDecode(srcTask.Config, &driverConfig); err != nil {
+		return nil, err
+	}
+	if !driverConfig.ApproveHeterogeneous {
+		return nil, CodedError(400, "resync requires ApproveHeterogeneous")
+	}
+
+	// a new map, as the config might be shared with the job in the state store
+	taskConfig := make(map[string]interface{}, len(srcTask.Config)+1)
+	for k, v := range srcTask.Config {
+		taskConfig[k] = v
+	}
+	taskConfig["ResyncTable"] = map[string]interface{}{
+		"ID":          models.GenerateUUID(),
+		"TableSchema": table[:dot],
+		"TableName":   table[dot+1:],
+	}
+	srcTask.Config = taskConfig
+
+	regReq := models.JobRegisterRequest{
+		Job:            job,
+		EnforceIndex:   true,
+		JobModifyIndex: out.Job.JobModifyIndex,
+		WriteRequest: models.WriteRequest{
+			Region: args.Region,
+		},
+	}
+	var regOut models.JobResponse
+	if err := s.agent.RPC("Job.Register", &regReq, &regOut); err != nil {
+		return nil, err
+	}
+	setIndex(resp, regOut.Index)
+	return regOut, nil
+}
+
+// allocStatsOnNode gets the stats of an allocation from the client running it, which might be this agent.
+func (s *HTTPServer) allocStatsOnNode(alloc *models.AllocListStub, region string) (*models.AllocStatistics, error) {
+	if s.agent.client != nil && s.agent.client.Node().ID == alloc.NodeID {
+		aStats, err := s.agent.client.StatsReporter().GetAllocStats(alloc.ID)
+		if err != nil {
+			return nil, err
+		}
+		return aStats.LatestAllocStats("")
+	}
+
+	args := models.NodeSpecificRequest{
+		NodeID: alloc.NodeID,
+	}
+	args.Region = region
+	var out models.SingleNodeResponse
+	if err := s.agent.RPC("Node.GetNode", &args, &out); err != nil {
+		return nil, err
+	}
+	if out.Node == nil {
+		return nil, fmt.Errorf("node %v not found", alloc.NodeID)
+	}
+	if out.Node.HTTPAddr == "" {
+		return nil, fmt.Errorf("http addr of node %v is not advertised", alloc.NodeID)
+	}
+	httpResp, err := cleanhttp.DefaultClient().Get(
+		fmt.Sprintf("http://%s/v1/agent/allocation/%s/stats", out.Node.HTTPAddr, alloc.ID))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != 200 {
+		body, _ := ioutil.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("node %v: %v %s", alloc.NodeID, httpResp.Status, body)
+	}
+	var stats models.AllocStatistics
+	if err := json.NewDecoder(httpResp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 func (s *HTTPServer) jobCRUD(resp http.ResponseWriter, req *http.Request,
 	jobName string) (interface{}, error) {
 	switch req.Method {
@@ -309,6 +498,9 @@ func (s *HTTPServer) jobUpdate(resp http.ResponseWriter, req *http.Request,
 	var out models.JobResponse
 
 	if err := s.agent.RPC("Job.Register", &regReq, &out); err != nil {
+		if strings.Contains(err.Error(), api.RegisterValidationErrPrefix) {
+			return nil, CodedError(400, err.Error())
+		}
 		return nil, err
 	}
 	setIndex(resp, out.Index)
@@ -443,6 +635,7 @@ func ApiJobToStructJob(job *api.Job, trafficLimit int) *models.Job {
 		Failover:          job.Failover,
 		Type:              *job.Type,
 		Datacenters:       job.Datacenters,
+		Tags:              job.Tags,
 		Status:            *job.Status,
 		StatusDescription: *job.StatusDescription,
 		CreateIndex:       *job.CreateIndex,
@@ -452,8 +645,17 @@ func ApiJobToStructJob(job *api.Job, trafficLimit int) *models.Job {
 
 	j.Tasks = make([]*models.Task, len(job.Tasks))
 	cfg := ""
+	destCount := 0
+	for _, task := range job.Tasks {
+		if models.IsDestTaskType(task.Type) {
+			destCount++
+		}
+	}
 	for _, task := range job.Tasks {
 		if task.Type == models.TaskTypeSrc {
+			if destCount > 1 {
+				task.Config["DestCount"] = destCount
+			}
 			task.Config["TrafficAgainstLimits"] = trafficLimit
 			if task.Config["Gtid"] != nil {
 				cfg = fmt.Sprintf("%s", task.Config["Gtid"])
@@ -467,6 +669,8 @@ func ApiJobToStructJob(job *api.Job, trafficLimit int) *models.Job {
 	for i, task := range job.Tasks {
 		if task.Type == models.TaskTypeDest {
 			task.Leader = true
+		}
+		if models.IsDestTaskType(task.Type) {
 			task.Config["Gtid"] = cfg
 		}
 		t := models.NewTask()
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package agent
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// allocLogsLines is the number of recent log lines kept for an allocation.
+	allocLogsLines = 1000
+	// allocLogsAllocs is the number of allocations of which the log lines are kept.
+	// Those of the oldest one are dropped first.
+	allocLogsAllocs = 64
+	// allocLogsFollowBuffer is the number of lines buffered for a follower. Lines are dropped
+	// while a follower is slower than that.
+	allocLogsFollowBuffer = 256
+)
+
+// allocLogs is a logrus hook keeping the log lines of the tasks run on the agent, i.e. the entries
+// with an "alloc" field, and passing new ones to the followers. See HTTPServer.allocLogs.
+type allocLogs struct {
+	formatter logrus.Formatter
+
+	lock      sync.Mutex
+	lines     map[string][][]byte
+	order     []string
+	followers map[string]map[chan []byte]struct{}
+}
+
+// newAllocLogs formats the lines as formatter does for a JSON one, and in plain text otherwise.
+func newAllocLogs(formatter logrus.Formatter) *allocLogs {
+	if _, ok := formatter.(*logrus.JSONFormatter); !ok {
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
+	}
+	return &allocLogs{
+		formatter: formatter,
+		lines:     make(map[string][][]byte),
+		followers: make(map[string]map[chan []byte]struct{}),
+	}
+}
+
+func (l *allocLogs) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (l *allocLogs) Fire(entry *logrus.Entry) error {
+	allocID, _ := entry.Data["alloc"].(string)
+	if allocID == "" {
+		return nil
+	}
+	line, err := l.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	// The formatter might reuse its buffer.
+	line = append([]byte(nil), line...)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	lines, ok := l.lines[allocID]
+	if !ok {
+		l.order = append(l.order, allocID)
+		if len(l.order) > allocLogsAllocs {
+			delete(l.lines, l.order[0])
+			l.order = l.order[1:]
+		}
+	}
+	if len(lines) >= allocLogsLines {
+		lines = lines[1:]
+	}
+	l.lines[allocID] = append(lines, line)
+
+	for ch := range l.followers[allocID] {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+	return nil
+}
+
+// follow returns the recent lines of the allocation. If follow is true, the new lines are sent
+// on the returned channel until cancel is called.
+func (l *allocLogs) follow(allocID string, follow bool) (lines [][]byte, ch chan []byte, cancel func()) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	lines = append(lines, l.lines[allocID]...)
+	if !follow {
+		return lines, nil, func() {}
+	}
+
+	ch = make(chan []byte, allocLogsFollowBuffer)
+	if l.followers[allocID] == nil {
+		l.followers[allocID] = make(map[chan []byte]struct{})
+	}
+	l.followers[allocID][ch] = struct{}{}
+	cancel = func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		delete(l.followers[allocID], ch)
+		if len(l.followers[allocID]) == 0 {
+			delete(l.followers, allocID)
+		}
+	}
+	return lines, ch, cancel
+}
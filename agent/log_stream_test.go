@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package agent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAllocLogs(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logs := newAllocLogs(logger.Formatter)
+	logger.AddHook(logs)
+
+	logger.Info("not of a task")
+	entry := logger.WithFields(logrus.Fields{"job": "job1", "alloc": "alloc1", "task": "Src"})
+	for i := 0; i < allocLogsLines+1; i++ {
+		entry.Infof("line %v", i)
+	}
+	lines, _, cancel := logs.follow("alloc1", false)
+	cancel()
+	if len(lines) != allocLogsLines {
+		t.Fatalf("got %v lines, want %v", len(lines), allocLogsLines)
+	}
+	for _, want := range []string{"line 1\"", "alloc=alloc1", "task=Src"} {
+		if !strings.Contains(string(lines[0]), want) {
+			t.Errorf("first line %q does not contain %q", lines[0], want)
+		}
+	}
+	if lines, _, _ := logs.follow("none", false); len(lines) != 0 {
+		t.Errorf("got lines %q of an unknown allocation", lines)
+	}
+
+	lines, ch, cancel := logs.follow("alloc1", true)
+	entry.Warn("a new line")
+	logger.WithField("alloc", "alloc2").Info("of another allocation")
+	select {
+	case line := <-ch:
+		if !strings.Contains(string(line), "a new line") {
+			t.Errorf("followed line %q, want the new one", line)
+		}
+	default:
+		t.Fatal("the new line is not followed")
+	}
+	select {
+	case line := <-ch:
+		t.Errorf("followed line %q of another allocation", line)
+	default:
+	}
+	cancel()
+	entry.Info("after cancel")
+	if len(logs.followers) != 0 {
+		t.Errorf("followers %v after cancel", logs.followers)
+	}
+
+	// the lines of the oldest allocations are dropped
+	for i := 0; i < allocLogsAllocs; i++ {
+		logger.WithField("alloc", fmt.Sprintf("other%v", i)).Info("a line")
+	}
+	if lines, _, _ := logs.follow("alloc1", false); len(lines) != 0 {
+		t.Errorf("got %v lines of the oldest allocation", len(lines))
+	}
+	if lines, _, _ := logs.follow("other0", false); len(lines) != 1 {
+		t.Errorf("got %v lines of a recent allocation, want 1", len(lines))
+	}
+}
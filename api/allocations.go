@@ -8,6 +8,7 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"time"
 )
@@ -73,6 +74,28 @@ func (a *Allocations) Stats(alloc *Allocation, q *QueryOptions) (*AllocStatistic
 	return &resp, err
 }
 
+// Logs returns the recent log lines of the tasks of an allocation, read from the node running it.
+// If follow is true, the new lines are streamed until the returned reader is closed. origin is
+// "start" to begin with the recent lines, or "end" to return only the new ones.
+func (a *Allocations) Logs(alloc *Allocation, follow bool, origin string, q *QueryOptions) (io.ReadCloser, error) {
+	node, _, err := a.client.Nodes().Info(alloc.NodeID, q)
+	if err != nil {
+		return nil, err
+	}
+	if node.Status == "down" {
+		return nil, NodeDownErr
+	}
+	if node.HTTPAddr == "" {
+		return nil, fmt.Errorf("http addr of the node where alloc %q is running is not advertised", alloc.ID)
+	}
+	client, err := NewClient(a.client.config.CopyConfig(node.HTTPAddr))
+	if err != nil {
+		return nil, err
+	}
+	return client.rawQuery("/v1/agent/allocation/"+alloc.ID+"/logs",
+		&QueryOptions{Params: map[string]string{"follow": fmt.Sprintf("%v", follow), "origin": origin}})
+}
+
 func (a *Allocations) GC(alloc *Allocation, q *QueryOptions) error {
 	node, _, err := a.client.Nodes().Info(alloc.NodeID, q)
 	if err != nil {
@@ -20,6 +20,10 @@ const (
 	// RegisterEnforceIndexErrPrefix is the prefix to use in errors caused by
 	// enforcing the job modify index during registers.
 	RegisterEnforceIndexErrPrefix = "Enforcing job modify index"
+
+	// RegisterValidationErrPrefix is the prefix of the errors of invalid job
+	// arguments during registers. The errors follow one per line.
+	RegisterValidationErrPrefix = "Job validation failed"
 )
 
 // Jobs is used to access the job-specific endpoints.
@@ -84,6 +88,12 @@ func (j *Jobs) List(q *QueryOptions) ([]*JobListStub, *QueryMeta, error) {
 	return resp, qm, nil
 }
 
+// TagList is used to list the jobs having all of the tags of filter,
+// of the form "k1:v1,k2:v2".
+func (j *Jobs) TagList(filter string) ([]*JobListStub, *QueryMeta, error) {
+	return j.List(&QueryOptions{Params: map[string]string{"tag": filter}})
+}
+
 // PrefixList is used to list all existing jobs that match the prefix.
 func (j *Jobs) PrefixList(prefix string) ([]*JobListStub, *QueryMeta, error) {
 	return j.List(&QueryOptions{Prefix: prefix})
@@ -152,6 +162,16 @@ func (j *Jobs) ForceEvaluate(jobID string, q *WriteOptions) (string, *WriteMeta,
 	return resp.EvalID, wm, nil
 }
 
+// Resync copies a table, as "<schema>.<table>", of a job again while the job goes on.
+func (j *Jobs) Resync(jobID string, table string, q *WriteOptions) (string, *WriteMeta, error) {
+	var resp registerJobResponse
+	wm, err := j.client.write("/v1/job/"+jobID+"/resync?table="+url.QueryEscape(table), nil, &resp, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.EvalID, wm, nil
+}
+
 func (j *Jobs) Plan(job *Job, diff bool, q *WriteOptions) (*JobPlanResponse, *WriteMeta, error) {
 	if job == nil {
 		return nil, nil, fmt.Errorf("must pass non-nil job")
@@ -188,6 +208,7 @@ type Job struct {
 	Failover          bool
 	Type              *string
 	Datacenters       []string
+	Tags              map[string]string
 	Tasks             []*Task
 	Status            *string
 	StatusDescription *string
@@ -236,6 +257,7 @@ type JobListStub struct {
 	ID                string
 	Name              string
 	Type              string
+	Tags              map[string]string
 	Status            string
 	StatusDescription string
 	JobSummary        *Job
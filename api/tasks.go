@@ -82,9 +82,39 @@ type Stats struct {
 	ThroughputStat *ThroughputStat
 }
 
+// TableProgress is the progress of a table in the full copy.
+type TableProgress struct {
+	TableSchema  string
+	TableName    string
+	RowsEstimate int64
+	RowsCopied   int64
+	Chunk        int64
+}
+
+// ErrorRecord is an error, or a recoverable one, of a task.
+type ErrorRecord struct {
+	Time    int64
+	Type    string
+	Gtid    string
+	Message string
+}
+
 type TaskStatistics struct {
 	Stats     *Stats
 	Timestamp int64
+
+	ExecMasterRowCount int64
+	ExecMasterTxCount  int64
+	ReadMasterRowCount int64
+	ReadMasterTxCount  int64
+	ProgressPct        string
+	ETA                string
+	Backlog            string
+	Stage              string
+	TableProgress      []*TableProgress
+	RecentErrors       []*ErrorRecord
+	// ReplicationLag is the lag of the incremental copy in seconds. It is set by the destination task.
+	ReplicationLag int64
 }
 
 type AllocStatistics struct {
@@ -8,6 +8,7 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -87,7 +88,8 @@ func (j *JobGetter) ApiJob(jpath string) (*api.Job, error) {
 	return jobStruct, nil
 }
 
-// Parse parses the job spec from the given io.Reader.
+// Parse parses the job spec from the given io.Reader. The spec is either HCL (or its JSON form,
+// with a "job" stanza), or a job in JSON as sent to the HTTP API.
 //
 // Due to current internal limitations, the entire contents of the
 // io.Reader will be copied into memory first before parsing.
@@ -98,6 +100,10 @@ func Parse(r io.Reader) (*api.Job, error) {
 		return nil, err
 	}
 
+	if job, ok, err := parseAPIJob(buf.Bytes()); ok {
+		return job, err
+	}
+
 	// Parse the buffer
 	root, err := hcl.Parse(buf.String())
 	if err != nil {
@@ -134,6 +140,26 @@ func Parse(r io.Reader) (*api.Job, error) {
 	return &job, nil
 }
 
+// parseAPIJob parses a job in JSON as sent to the HTTP API: a job object, or a {"Job": {...}} request.
+// It returns false if buf is not such a job, to be parsed as HCL.
+func parseAPIJob(buf []byte) (*api.Job, bool, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &root); err != nil {
+		return nil, false, nil
+	}
+	if _, ok := root["job"]; ok {
+		return nil, false, nil
+	}
+	if raw, ok := root["Job"]; ok {
+		buf = raw
+	}
+	var job api.Job
+	if err := json.Unmarshal(buf, &job); err != nil {
+		return nil, true, fmt.Errorf("error parsing: %s", err)
+	}
+	return &job, true, nil
+}
+
 // ParseFile parses the given path as a job spec.
 func ParseFile(path string) (*api.Job, error) {
 	path, err := filepath.Abs(path)
@@ -190,6 +216,7 @@ func parseJob(result *api.Job, list *ast.ObjectList) error {
 		"region",
 		"datacenters",
 		"name",
+		"tags",
 		"task",
 		"type",
 	}
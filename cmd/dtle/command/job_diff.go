@@ -0,0 +1,343 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+const (
+	ConfigChangeAdded   = "added"
+	ConfigChangeRemoved = "removed"
+	ConfigChangeChanged = "changed"
+
+	ConfigCategoryTask        = "task"
+	ConfigCategoryFilter      = "filter"
+	ConfigCategoryConnection  = "connection"
+	ConfigCategoryReplication = "replication"
+)
+
+// filterConfigKeys and connectionConfigKeys categorize the keys of Task.Config. Other keys are
+// replication parameters.
+var filterConfigKeys = []string{"ReplicateDoDb", "ReplicateIgnoreDb", "DestDoDb", "SqlFilter",
+	"ReplicateDML", "ReplicateDDL", "SkipCreateDbTable", "SkipIncrementalCopy", "SkipDropStatements"}
+var connectionConfigKeys = []string{"ConnectionConfig", "NatsAddr", "ServerId", "Brokers", "Topic"}
+
+// serverConfigKeys are set by the server on the registered job (e.g. the replicated Gtid). They are
+// compared only if the updated job sets them.
+var serverConfigKeys = []string{"Gtid", "BinlogFile", "BinlogPos", "DumpCheckpoint", "AutoGtid",
+	"TrafficAgainstLimits", "DestCount"}
+
+// ConfigChange is a difference of a task between the registered job and the updated one.
+type ConfigChange struct {
+	Task     string
+	Key      string // empty if the task is added or removed
+	Category string
+	Change   string
+	Old      interface{} `json:",omitempty"`
+	New      interface{} `json:",omitempty"`
+	// whether the tasks are restarted to take the change. Otherwise it is applied to the running task.
+	Restart bool
+}
+
+type JobDiffCommand struct {
+	Meta
+	JobGetter
+
+	// The fields below can be overwritten for tests
+	getJob func(jobID string) (*api.Job, error)
+}
+
+func (c *JobDiffCommand) Help() string {
+	helpText := `
+Usage: dtle job-diff [options] <path>
+
+  Compares the job specified at <path> with the registered job of the
+  same ID and lists the changed filters, connection info and replication
+  parameters of each task. Each change is flagged whether it restarts
+  the tasks or is applied to the running tasks when the job is updated.
+  Nothing is changed on the server.
+
+  If the supplied path is "-", the jobfile is read from stdin. Otherwise
+  it is read from the file at the supplied path or downloaded and
+  read from URL specified.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Diff Options:
+
+  -json
+    Output the changes in JSON format.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobDiffCommand) Synopsis() string {
+	return "Compare a job file with the registered job"
+}
+
+func (c *JobDiffCommand) Run(args []string) int {
+	var jsonOutput bool
+
+	flags := c.Meta.FlagSet("job-diff", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&jsonOutput, "json", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	job, err := c.JobGetter.ApiJob(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting job struct: %s", err))
+		return 1
+	}
+
+	getJob := c.getJob
+	if getJob == nil {
+		getJob = func(jobID string) (*api.Job, error) {
+			client, err := c.Meta.Client()
+			if err != nil {
+				return nil, fmt.Errorf("Error initializing client: %s", err)
+			}
+			job, _, err := client.Jobs().Info(jobID, nil)
+			if err != nil {
+				return nil, fmt.Errorf("Error querying job %q: %s", jobID, err)
+			}
+			return job, nil
+		}
+	}
+	registered, err := getJob(*job.ID)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	changes, err := diffJobTasks(registered, job)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error comparing jobs: %s", err))
+		return 1
+	}
+
+	if jsonOutput {
+		if changes == nil {
+			changes = []*ConfigChange{}
+		}
+		buf, err := json.MarshalIndent(changes, "", "    ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error converting changes: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(buf))
+		return 0
+	}
+
+	if len(changes) == 0 {
+		c.Ui.Output("No changes")
+		return 0
+	}
+	nRestart := 0
+	out := []string{"Task|Change|Category|Key|Old|New|Applied by"}
+	for _, change := range changes {
+		appliedBy := "live update"
+		if change.Restart {
+			appliedBy = "restart"
+			nRestart++
+		}
+		out = append(out, fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s", change.Task, change.Change, change.Category,
+			change.Key, formatConfigValue(change.Old), formatConfigValue(change.New), appliedBy))
+	}
+	c.Ui.Output(formatList(out))
+	if nRestart > 0 {
+		c.Ui.Output(fmt.Sprintf("\n%d changes, %d of them restart the tasks", len(changes), nRestart))
+	} else {
+		c.Ui.Output(fmt.Sprintf("\n%d changes, all applied to the running tasks", len(changes)))
+	}
+	return 0
+}
+
+// diffJobTasks lists the changes of the tasks from the registered job to the updated one, in the
+// order of the tasks and keys.
+func diffJobTasks(registered, updated *api.Job) ([]*ConfigChange, error) {
+	oldTasks := map[string]*api.Task{}
+	for _, task := range registered.Tasks {
+		oldTasks[task.Type] = task
+	}
+	newTasks := map[string]*api.Task{}
+	for _, task := range updated.Tasks {
+		newTasks[task.Type] = task
+	}
+
+	var changes []*ConfigChange
+	for _, task := range registered.Tasks {
+		if newTasks[task.Type] == nil {
+			changes = append(changes, &ConfigChange{Task: task.Type, Category: ConfigCategoryTask,
+				Change: ConfigChangeRemoved, Restart: true})
+		}
+	}
+	for _, task := range updated.Tasks {
+		oldTask := oldTasks[task.Type]
+		if oldTask == nil {
+			changes = append(changes, &ConfigChange{Task: task.Type, Category: ConfigCategoryTask,
+				Change: ConfigChangeAdded, Restart: true})
+			continue
+		}
+		oldDriver, newDriver := oldTask.Driver, task.Driver
+		if newDriver == "" {
+			// as the server does on registration
+			newDriver = models.TaskDriverMySQL
+		}
+		if oldDriver != newDriver {
+			changes = append(changes, &ConfigChange{Task: task.Type, Key: "Driver", Category: ConfigCategoryTask,
+				Change: ConfigChangeChanged, Old: oldDriver, New: newDriver, Restart: true})
+		}
+
+		taskChanges, err := diffTaskConfig(task.Type, oldTask.Config, task.Config)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, taskChanges...)
+	}
+	return changes, nil
+}
+
+func diffTaskConfig(taskType string, oldConfig, newConfig map[string]interface{}) ([]*ConfigChange, error) {
+	// The registered config has been through JSON. Do the same to the updated one to compare the values.
+	var err error
+	if oldConfig, err = normalizeConfig(oldConfig); err != nil {
+		return nil, err
+	}
+	if newConfig, err = normalizeConfig(newConfig); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for k := range oldConfig {
+		if _, ok := newConfig[k]; !ok && !containsString(serverConfigKeys, k) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range newConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []*ConfigChange
+	for _, k := range keys {
+		oldValue, hasOld := oldConfig[k]
+		newValue, hasNew := newConfig[k]
+		change := &ConfigChange{
+			Task:     taskType,
+			Key:      k,
+			Category: configCategory(k),
+			Old:      maskPassword(oldValue),
+			New:      maskPassword(newValue),
+			Restart:  !containsString(models.RuntimeConfigKeys, k),
+		}
+		switch {
+		case !hasOld:
+			change.Change = ConfigChangeAdded
+		case !hasNew:
+			change.Change = ConfigChangeRemoved
+		case !reflect.DeepEqual(oldValue, newValue):
+			change.Change = ConfigChangeChanged
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func normalizeConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	buf, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func configCategory(key string) string {
+	switch {
+	case containsString(filterConfigKeys, key):
+		return ConfigCategoryFilter
+	case containsString(connectionConfigKeys, key):
+		return ConfigCategoryConnection
+	default:
+		return ConfigCategoryReplication
+	}
+}
+
+// secretConfigKeys are the keys, at any depth of Task.Config, of which the values are hidden.
+// An EncryptionKey of the form "env:NAME" holds no key and is shown.
+var secretConfigKeys = []string{"Password", "AdminPassword", "Socks5Password", "EncryptionKey"}
+
+// maskPassword returns a copy of a normalized config value with passwords hidden.
+func maskPassword(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if s, ok := e.(string); ok && k == "EncryptionKey" && strings.HasPrefix(s, "env:") {
+				result[k] = e
+			} else if containsString(secretConfigKeys, k) && e != "" {
+				result[k] = "******"
+			} else {
+				result[k] = maskPassword(e)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, e := range v {
+			result[i] = maskPassword(e)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func formatConfigValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(buf)
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/actiontech/dtle/api"
+)
+
+// registeredJob returns the job as returned by the server after it is registered from jobfile.
+func registeredJob(t *testing.T, jobfile string) *api.Job {
+	job, err := Parse(strings.NewReader(jobfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range job.Tasks {
+		task.Driver = "MySQL"
+		task.Config["Gtid"] = "00000000-0000-0000-0000-000000000000:1-10"
+		if task.Type == "Src" {
+			task.Config["TrafficAgainstLimits"] = 0
+		}
+	}
+	buf, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result api.Job
+	if err := json.Unmarshal(buf, &result); err != nil {
+		t.Fatal(err)
+	}
+	return &result
+}
+
+func TestJobDiffCommand_Run(t *testing.T) {
+	registered := registeredJob(t, testPreviewJob)
+	getJob := func(jobID string) (*api.Job, error) {
+		if jobID != "job1" {
+			t.Fatalf("getJob(%q), want job1", jobID)
+		}
+		return registered, nil
+	}
+
+	// Nothing is changed but the fields set by the server.
+	ui := new(cli.MockUi)
+	c := &JobDiffCommand{
+		Meta:      Meta{Ui: ui},
+		JobGetter: JobGetter{testStdin: strings.NewReader(testPreviewJob)},
+		getJob:    getJob,
+	}
+	if code := c.Run([]string{"-"}); code != 0 {
+		t.Fatalf("Run() = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, "No changes") {
+		t.Errorf("output = %q, want no changes", out)
+	}
+
+	updated := strings.Replace(testPreviewJob, `TableSchema = "db1"`, `TableSchema = "db2"`, 1)
+	updated = strings.Replace(updated, `Password = "password"`, `Password = "password2"`, 1)
+	updated = strings.Replace(updated, `Port = "3307"`, `Port = "3307"
+      }
+      ApplyEventRateLimit = 100
+      Unused = {`, 1)
+	ui = new(cli.MockUi)
+	c = &JobDiffCommand{
+		Meta:      Meta{Ui: ui},
+		JobGetter: JobGetter{testStdin: strings.NewReader(updated)},
+		getJob:    getJob,
+	}
+	if code := c.Run([]string{"-json", "-"}); code != 0 {
+		t.Fatalf("Run(-json) = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	var changes []*ConfigChange
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &changes); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	type change struct {
+		task, key, category, change string
+		restart                     bool
+	}
+	want := []change{
+		{"Src", "ConnectionConfig", ConfigCategoryConnection, ConfigChangeChanged, true},
+		{"Src", "ReplicateDoDb", ConfigCategoryFilter, ConfigChangeChanged, false},
+		{"Dest", "ApplyEventRateLimit", ConfigCategoryReplication, ConfigChangeAdded, false},
+		{"Dest", "ConnectionConfig", ConfigCategoryConnection, ConfigChangeChanged, true},
+		{"Dest", "Unused", ConfigCategoryReplication, ConfigChangeAdded, true},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %s, want %v", ui.OutputWriter.String(), want)
+	}
+	for i, c := range changes {
+		if got := (change{c.Task, c.Key, c.Category, c.Change, c.Restart}); got != want[i] {
+			t.Errorf("changes[%v] = %v, want %v", i, got, want[i])
+		}
+	}
+	if out := ui.OutputWriter.String(); strings.Contains(out, "password") {
+		t.Errorf("output %q shows the password", out)
+	}
+
+	// human-readable
+	ui = new(cli.MockUi)
+	c = &JobDiffCommand{
+		Meta:      Meta{Ui: ui},
+		JobGetter: JobGetter{testStdin: strings.NewReader(updated)},
+		getJob:    getJob,
+	}
+	if code := c.Run([]string{"-"}); code != 0 {
+		t.Fatalf("Run() = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	for _, want := range []string{`[{"TableSchema":"db1"}]`, `[{"TableSchema":"db2"}]`, "live update",
+		"5 changes, 3 of them restart the tasks"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+}
+
+func TestJobDiffCommand_Run_tasks(t *testing.T) {
+	registered := registeredJob(t, testPreviewJob)
+	updated := strings.Replace(testPreviewJob, `task "Dest"`, `task "Dest_a"`, 1)
+	ui := new(cli.MockUi)
+	c := &JobDiffCommand{
+		Meta:      Meta{Ui: ui},
+		JobGetter: JobGetter{testStdin: strings.NewReader(updated)},
+		getJob: func(jobID string) (*api.Job, error) {
+			return registered, nil
+		},
+	}
+	if code := c.Run([]string{"-json", "-"}); code != 0 {
+		t.Fatalf("Run(-json) = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	var changes []*ConfigChange
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &changes); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(changes) != 2 ||
+		changes[0].Task != "Dest" || changes[0].Change != ConfigChangeRemoved || !changes[0].Restart ||
+		changes[1].Task != "Dest_a" || changes[1].Change != ConfigChangeAdded || !changes[1].Restart {
+		t.Errorf("changes = %s", ui.OutputWriter.String())
+	}
+}
+
+func TestMaskPassword(t *testing.T) {
+	value := map[string]interface{}{
+		"Host":          "127.0.0.1",
+		"Password":      "p1",
+		"AdminUser":     "admin",
+		"AdminPassword": "p2",
+		"EncryptionKey": "MDEyMzQ1Njc4OWFiY2RlZg==",
+		"Tunnel":        map[string]interface{}{"Socks5Password": "p3"},
+	}
+	got := maskPassword(value).(map[string]interface{})
+	for _, k := range []string{"Password", "AdminPassword", "EncryptionKey"} {
+		if got[k] != "******" {
+			t.Errorf("%v = %v, want it masked", k, got[k])
+		}
+	}
+	if tunnel := got["Tunnel"].(map[string]interface{}); tunnel["Socks5Password"] != "******" {
+		t.Errorf("Tunnel.Socks5Password = %v, want it masked", tunnel["Socks5Password"])
+	}
+	if got["Host"] != "127.0.0.1" || got["AdminUser"] != "admin" {
+		t.Errorf("maskPassword() = %v, want the other keys kept", got)
+	}
+	if value["AdminPassword"] != "p2" {
+		t.Errorf("maskPassword() modified its argument")
+	}
+	got = maskPassword(map[string]interface{}{"EncryptionKey": "env:DTLE_KEY"}).(map[string]interface{})
+	if got["EncryptionKey"] != "env:DTLE_KEY" {
+		t.Errorf("EncryptionKey = %v, want the reference to the environment variable kept", got["EncryptionKey"])
+	}
+}
@@ -0,0 +1,406 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+const (
+	// logsPollInterval is the interval of looking for new allocations of the job
+	// and new task events while following the logs.
+	logsPollInterval = 2 * time.Second
+	// logsMaxLineSize is the maximum size of a log line read from an agent.
+	logsMaxLineSize = 1024 * 1024
+)
+
+type JobLogsCommand struct {
+	Meta
+
+	// The fields below can be overwritten for tests
+	pollInterval time.Duration
+}
+
+func (c *JobLogsCommand) Help() string {
+	helpText := `
+Usage: dtle job-logs [options] <job>
+
+  Prints the logs of the tasks of a job, i.e. those of the extractor (Src)
+  and of the applier (Dest), read from the agents running them, along with
+  the events of the tasks. The lines are prefixed with the name of the task.
+  An agent keeps the last 1000 lines of an allocation.
+
+  With -f, the new lines and events are printed until interrupted or the job
+  is dead. When a task is rescheduled, the logs of its new allocation are
+  followed.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Logs Options:
+
+  -f, -follow
+    Print the new lines and events as they come.
+
+  -task <src|dest>
+    Only print the logs of the given task.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobLogsCommand) Synopsis() string {
+	return "Print or follow the logs of the tasks of a job"
+}
+
+func (c *JobLogsCommand) Run(args []string) int {
+	var follow bool
+	var task string
+
+	flags := c.Meta.FlagSet("job-logs", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&follow, "f", false, "")
+	flags.BoolVar(&follow, "follow", false, "")
+	flags.StringVar(&task, "task", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	switch strings.ToLower(task) {
+	case "":
+	case "src":
+		task = models.TaskTypeSrc
+	case "dest":
+		task = models.TaskTypeDest
+	default:
+		c.Ui.Error(fmt.Sprintf("Invalid task %q: must be src or dest", task))
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+	jobID := args[0]
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying job: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+
+	l := newJobLogs(c.Ui, client, jobs[0].ID, task)
+	if !follow {
+		return l.print()
+	}
+	pollInterval := c.pollInterval
+	if pollInterval == 0 {
+		pollInterval = logsPollInterval
+	}
+	return l.follow(pollInterval)
+}
+
+// jobLogs prints the logs of the latest allocation of each task of a job.
+type jobLogs struct {
+	ui     cli.Ui
+	client *api.Client
+	jobID  string
+	// the name of the task to print, or empty for all
+	task string
+
+	// serializes the output
+	lock sync.Mutex
+	// time of the last event printed, by allocation
+	lastEvents map[string]int64
+	// streams being followed, by task
+	streams map[string]*allocLogStream
+}
+
+// allocLogStream is the logs of an allocation being followed.
+type allocLogStream struct {
+	allocID string
+	// closed on the end of the stream, after err is set
+	done chan struct{}
+	err  error
+
+	lock   sync.Mutex
+	closer func()
+	closed bool
+}
+
+func newJobLogs(ui cli.Ui, client *api.Client, jobID string, task string) *jobLogs {
+	return &jobLogs{
+		ui:         ui,
+		client:     client,
+		jobID:      jobID,
+		task:       task,
+		lastEvents: make(map[string]int64),
+		streams:    make(map[string]*allocLogStream),
+	}
+}
+
+// latestAllocs returns the latest allocation of each task to print, by task.
+func (l *jobLogs) latestAllocs() (map[string]*api.AllocationListStub, error) {
+	return latestTaskAllocs(l.client, l.jobID, l.task)
+}
+
+// latestTaskAllocs returns the latest allocation of each task of a job, by task. If task is not empty,
+// only that of the given task is returned.
+func latestTaskAllocs(client *api.Client, jobID string, task string) (map[string]*api.AllocationListStub, error) {
+	allocs, _, err := client.Jobs().Allocations(jobID, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]*api.AllocationListStub)
+	for _, alloc := range allocs {
+		if task != "" && alloc.Task != task {
+			continue
+		}
+		if prev, ok := latest[alloc.Task]; !ok || alloc.CreateIndex > prev.CreateIndex {
+			latest[alloc.Task] = alloc
+		}
+	}
+	return latest, nil
+}
+
+func sortedTasks(allocs map[string]*api.AllocationListStub) []string {
+	var tasks []string
+	for task := range allocs {
+		tasks = append(tasks, task)
+	}
+	// Src before Dest
+	sort.Sort(sort.Reverse(sort.StringSlice(tasks)))
+	return tasks
+}
+
+func (l *jobLogs) output(task string, line string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.ui.Output(fmt.Sprintf("[%s] %s", task, line))
+}
+
+func (l *jobLogs) info(task string, msg string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.ui.Info(fmt.Sprintf("==> [%s] %s", task, msg))
+}
+
+// printEvents prints the task events of alloc not printed yet.
+func (l *jobLogs) printEvents(alloc *api.AllocationListStub) {
+	state, ok := alloc.TaskStates[alloc.Task]
+	if !ok {
+		return
+	}
+	last := l.lastEvents[alloc.ID]
+	for _, event := range state.Events {
+		if event.Time <= last {
+			continue
+		}
+		l.info(alloc.Task, fmt.Sprintf("%s %s: %s", formatUnixNanoTime(event.Time), event.Type,
+			taskEventMessage(event)))
+		l.lastEvents[alloc.ID] = event.Time
+	}
+}
+
+// taskEventMessage returns the description of event.
+func taskEventMessage(event *api.TaskEvent) string {
+	for _, msg := range []string{event.Message, event.DriverError, event.SetupError, event.KillError,
+		event.RestartReason, event.KillReason, event.ValidationError, event.DownloadError, event.DriverMessage} {
+		if msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// print prints the recent lines of the latest allocation of each task.
+func (l *jobLogs) print() int {
+	latest, err := l.latestAllocs()
+	if err != nil {
+		l.ui.Error(fmt.Sprintf("Error querying job allocations: %s", err))
+		return 1
+	}
+	if len(latest) == 0 {
+		l.ui.Error(fmt.Sprintf("No allocations of job %q found", l.jobID))
+		return 1
+	}
+	code := 0
+	for _, task := range sortedTasks(latest) {
+		alloc := latest[task]
+		l.printEvents(alloc)
+		if err := l.stream(alloc, false, "start", nil); err != nil {
+			l.ui.Error(fmt.Sprintf("Error reading the logs of allocation %q: %s", alloc.ID, err))
+			code = 1
+		}
+	}
+	return code
+}
+
+// follow prints the events and the lines of the latest allocations of the tasks as they come,
+// until the job is dead.
+func (l *jobLogs) follow(pollInterval time.Duration) int {
+	defer l.closeStreams()
+	for {
+		job, _, err := l.client.Jobs().Info(l.jobID, nil)
+		if err != nil {
+			l.ui.Error(fmt.Sprintf("Error querying job: %s", err))
+			return 1
+		}
+		latest, err := l.latestAllocs()
+		if err != nil {
+			l.ui.Error(fmt.Sprintf("Error querying job allocations: %s", err))
+			return 1
+		}
+		for _, task := range sortedTasks(latest) {
+			alloc := latest[task]
+			l.printEvents(alloc)
+			l.followAlloc(alloc)
+		}
+		if *job.Status == models.JobStatusDead {
+			l.lock.Lock()
+			l.ui.Info(fmt.Sprintf("==> Job %q is dead", l.jobID))
+			l.lock.Unlock()
+			return 0
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// followAlloc starts following the lines of alloc while it runs, instead of the previous allocation
+// of its task. A stream ended early, e.g. on a restart of the agent, is reconnected. The lines
+// logged meanwhile are missed.
+func (l *jobLogs) followAlloc(alloc *api.AllocationListStub) {
+	running := alloc.DesiredStatus == models.AllocDesiredStatusRun &&
+		alloc.ClientStatus == models.AllocClientStatusRunning
+	s := l.streams[alloc.Task]
+	if s != nil && s.allocID != alloc.ID {
+		l.info(alloc.Task, fmt.Sprintf("Allocation %q is replaced by %q", s.allocID, alloc.ID))
+		s.close()
+		delete(l.streams, alloc.Task)
+		s = nil
+	}
+	origin := "start"
+	if s != nil {
+		select {
+		case <-s.done:
+			if !running {
+				return
+			}
+			if s.err != nil {
+				l.info(alloc.Task, fmt.Sprintf("Lost the logs of allocation %q: %v", alloc.ID, s.err))
+			}
+			l.info(alloc.Task, fmt.Sprintf("Reconnecting to the logs of allocation %q", alloc.ID))
+			origin = "end"
+		default:
+			if !running {
+				s.close()
+			}
+			return
+		}
+	} else if !running {
+		return
+	}
+
+	s = &allocLogStream{allocID: alloc.ID, done: make(chan struct{})}
+	l.streams[alloc.Task] = s
+	go func() {
+		s.err = l.stream(alloc, true, origin, s)
+		close(s.done)
+	}()
+}
+
+// stream prints the lines of alloc, until the end of the stream if follow is set.
+// The stream can be closed by s.
+func (l *jobLogs) stream(alloc *api.AllocationListStub, follow bool, origin string,
+	s *allocLogStream) error {
+
+	full, _, err := l.client.Allocations().Info(alloc.ID, nil)
+	if err != nil {
+		return err
+	}
+	body, err := l.client.Allocations().Logs(full, follow, origin, nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if s != nil && !s.setCloser(func() { body.Close() }) {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, logsMaxLineSize)
+	for scanner.Scan() {
+		l.output(alloc.Task, scanner.Text())
+	}
+	if s != nil && s.isClosed() {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if follow {
+		return fmt.Errorf("the stream ended")
+	}
+	return nil
+}
+
+// setCloser sets the function to close the stream. It returns false if the stream is closed already.
+func (s *allocLogStream) setCloser(closer func()) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return false
+	}
+	s.closer = closer
+	return true
+}
+
+func (s *allocLogStream) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	if s.closer != nil {
+		s.closer()
+	}
+}
+
+func (s *allocLogStream) isClosed() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.closed
+}
+
+func (l *jobLogs) closeStreams() {
+	for _, s := range l.streams {
+		s.close()
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/actiontech/dtle/api"
+)
+
+// testLogsAgent serves the endpoints used by job-logs and job-metrics for a job "job1" on a single node.
+type testLogsAgent struct {
+	server *httptest.Server
+
+	lock      sync.Mutex
+	jobStatus string
+	allocs    []*api.AllocationListStub
+	// log lines by allocation
+	lines map[string][]string
+	// stats by allocation
+	stats map[string]*api.AllocStatistics
+	// closed on each new line
+	newLine chan struct{}
+}
+
+func newTestLogsAgent() *testLogsAgent {
+	a := &testLogsAgent{
+		jobStatus: "running",
+		lines:     make(map[string][]string),
+		stats:     make(map[string]*api.AllocStatistics),
+		newLine:   make(chan struct{}),
+	}
+	a.server = httptest.NewServer(http.HandlerFunc(a.serve))
+	return a
+}
+
+func (a *testLogsAgent) addAlloc(id string, task string, createIndex uint64, lines ...string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.allocs = append(a.allocs, &api.AllocationListStub{
+		ID:            id,
+		JobID:         "job1",
+		NodeID:        "node1",
+		Task:          task,
+		DesiredStatus: "run",
+		ClientStatus:  "running",
+		TaskStates: map[string]*api.TaskState{task: {
+			State:  "running",
+			Events: []*api.TaskEvent{{Type: "Started", Time: int64(createIndex), Message: "Task started by client"}},
+		}},
+		CreateIndex: createIndex,
+	})
+	a.lines[id] = lines
+}
+
+func (a *testLogsAgent) log(allocID string, line string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.lines[allocID] = append(a.lines[allocID], line)
+	close(a.newLine)
+	a.newLine = make(chan struct{})
+}
+
+func (a *testLogsAgent) update(f func()) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	f()
+}
+
+func (a *testLogsAgent) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Udup-Index", "1")
+	w.Header().Set("X-Udup-LastContact", "0")
+	a.lock.Lock()
+	var obj interface{}
+	switch path := r.URL.Path; {
+	case path == "/v1/jobs":
+		obj = []*api.JobListStub{{ID: "job1", Status: a.jobStatus}}
+	case path == "/v1/job/job1":
+		obj = &api.Job{ID: &[]string{"job1"}[0], Status: &[]string{a.jobStatus}[0]}
+	case path == "/v1/job/job1/allocations":
+		obj = a.allocs
+	case strings.HasPrefix(path, "/v1/allocation/"):
+		obj = &api.Allocation{ID: strings.TrimPrefix(path, "/v1/allocation/"), NodeID: "node1"}
+	case path == "/v1/node/node1":
+		obj = &api.Node{ID: "node1", Status: "ready", HTTPAddr: strings.TrimPrefix(a.server.URL, "http://")}
+	case strings.HasPrefix(path, "/v1/agent/allocation/") && strings.HasSuffix(path, "/stats"):
+		obj = a.stats[strings.TrimSuffix(strings.TrimPrefix(path, "/v1/agent/allocation/"), "/stats")]
+	case strings.HasPrefix(path, "/v1/agent/allocation/") && strings.HasSuffix(path, "/logs"):
+		a.lock.Unlock()
+		a.serveLogs(w, r, strings.TrimSuffix(strings.TrimPrefix(path, "/v1/agent/allocation/"), "/logs"))
+		return
+	default:
+		a.lock.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	a.lock.Unlock()
+	json.NewEncoder(w).Encode(obj)
+}
+
+func (a *testLogsAgent) serveLogs(w http.ResponseWriter, r *http.Request, allocID string) {
+	sent := 0
+	for {
+		a.lock.Lock()
+		lines, newLine := a.lines[allocID], a.newLine
+		a.lock.Unlock()
+		for ; sent < len(lines); sent++ {
+			fmt.Fprintln(w, lines[sent])
+		}
+		w.(http.Flusher).Flush()
+		if r.URL.Query().Get("follow") != "true" {
+			return
+		}
+		select {
+		case <-newLine:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func waitForOutput(t *testing.T, ui *cli.MockUi, want string) {
+	for i := 0; i < 500; i++ {
+		if strings.Contains(ui.OutputWriter.String(), want) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("output %q does not contain %q", ui.OutputWriter.String(), want)
+}
+
+func TestJobLogsCommand_Run(t *testing.T) {
+	a := newTestLogsAgent()
+	defer a.server.Close()
+	a.addAlloc("src1", "Src", 10, "extractor line")
+	a.addAlloc("dest1", "Dest", 11, "applier line")
+
+	ui := cli.NewMockUi()
+	cmd := &JobLogsCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + a.server.URL, "job1"}); code != 0 {
+		t.Fatalf("Run() = %v, error: %v", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	for _, want := range []string{"==> [Src] ", "Started: Task started by client", "[Src] extractor line",
+		"[Dest] applier line"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+	if strings.Index(out, "extractor line") > strings.Index(out, "applier line") {
+		t.Errorf("output %q: want Src before Dest", out)
+	}
+
+	ui = cli.NewMockUi()
+	cmd = &JobLogsCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + a.server.URL, "-task", "dest", "job1"}); code != 0 {
+		t.Fatalf("Run() = %v, error: %v", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); strings.Contains(out, "Src") || !strings.Contains(out, "[Dest] applier line") {
+		t.Errorf("output %q, want only the lines of Dest", out)
+	}
+
+	ui = cli.NewMockUi()
+	cmd = &JobLogsCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + a.server.URL, "-task", "other", "job1"}); code != 1 {
+		t.Errorf("Run() with an invalid task = %v, want 1", code)
+	}
+}
+
+// The logs of the Src task are followed while it is rescheduled to a new allocation.
+func TestJobLogsCommand_Run_follow(t *testing.T) {
+	a := newTestLogsAgent()
+	defer a.server.Close()
+	a.addAlloc("src1", "Src", 10, "line 1")
+
+	ui := cli.NewMockUi()
+	cmd := &JobLogsCommand{Meta: Meta{Ui: ui}, pollInterval: 10 * time.Millisecond}
+	codeCh := make(chan int)
+	go func() {
+		codeCh <- cmd.Run([]string{"-address=" + a.server.URL, "-f", "-task=src", "job1"})
+	}()
+
+	waitForOutput(t, ui, "[Src] line 1")
+	a.log("src1", "line 2")
+	waitForOutput(t, ui, "[Src] line 2")
+
+	a.update(func() {
+		alloc := a.allocs[0]
+		alloc.ClientStatus = "failed"
+		state := alloc.TaskStates["Src"]
+		state.Events = append(state.Events, &api.TaskEvent{Type: "Driver Failure", Time: 11, DriverError: "lost the source"})
+	})
+	waitForOutput(t, ui, "Driver Failure: lost the source")
+	a.addAlloc("src2", "Src", 20, "line 3")
+	waitForOutput(t, ui, `Allocation "src1" is replaced by "src2"`)
+	waitForOutput(t, ui, "[Src] line 3")
+	a.log("src2", "line 4")
+	waitForOutput(t, ui, "[Src] line 4")
+
+	a.update(func() { a.jobStatus = "dead" })
+	select {
+	case code := <-codeCh:
+		if code != 0 {
+			t.Errorf("Run() = %v, error: %v", code, ui.ErrorWriter.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() does not return after the job is dead")
+	}
+	if out := ui.OutputWriter.String(); strings.Count(out, "line 1") != 1 || strings.Count(out, "line 3") != 1 {
+		t.Errorf("output %q, want each line once", out)
+	}
+}
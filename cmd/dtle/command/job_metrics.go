@@ -0,0 +1,327 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// metricsSampleInterval is the interval between the two samples of the stats the rates of a single
+// snapshot are computed from. The agents collect the stats every metric.collection_interval, 1s by default.
+const metricsSampleInterval = 2 * time.Second
+
+type JobMetricsCommand struct {
+	Meta
+
+	// The fields below can be overwritten for tests
+	sampleInterval time.Duration
+	// the unit of -watch
+	watchUnit time.Duration
+}
+
+func (c *JobMetricsCommand) Help() string {
+	helpText := `
+Usage: dtle job-metrics [options] <job>
+
+  Prints a snapshot of the metrics of the tasks of a job, i.e. those of the
+  extractor (Src) and of the applier (Dest), read from the agents running
+  them:
+    Stage          - the stage of the task
+    Progress       - the progress of the full copy, or of the transactions
+                     estimated
+    Rows           - the rows of the full copy read (Src) or applied (Dest)
+    Transactions   - the transactions of the incremental copy extracted
+                     (Src) or applied (Dest)
+    Rows/s, Txs/s  - the rates of the two above over the last seconds
+    Lag            - the lag of the incremental copy in seconds, by the
+                     binlog timestamps (Dest only)
+    Backlog        - the queued messages of the task
+    Errors         - the number of recent errors of the task, the last of
+                     which is printed below the table
+  The progress of each table in the full copy follows, when available.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Metrics Options:
+
+  -watch <seconds>
+    Print a new snapshot every <seconds>, until interrupted or the job is
+    dead. The rates are computed since the previous snapshot.
+
+  -json
+    Output the metrics in JSON format, one object per snapshot.
+
+  -task <src|dest>
+    Only print the metrics of the given task.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobMetricsCommand) Synopsis() string {
+	return "Print a snapshot of the metrics of the tasks of a job"
+}
+
+func (c *JobMetricsCommand) Run(args []string) int {
+	var watch int
+	var jsonOutput bool
+	var task string
+
+	flags := c.Meta.FlagSet("job-metrics", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.IntVar(&watch, "watch", 0, "")
+	flags.BoolVar(&jsonOutput, "json", false, "")
+	flags.StringVar(&task, "task", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	if watch < 0 {
+		c.Ui.Error(fmt.Sprintf("Invalid watch %v: must not be negative", watch))
+		return 1
+	}
+	switch strings.ToLower(task) {
+	case "":
+	case "src":
+		task = models.TaskTypeSrc
+	case "dest":
+		task = models.TaskTypeDest
+	default:
+		c.Ui.Error(fmt.Sprintf("Invalid task %q: must be src or dest", task))
+		return 1
+	}
+
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+	jobID := args[0]
+	jobs, _, err := client.Jobs().PrefixList(jobID)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying job: %s", err))
+		return 1
+	}
+	if len(jobs) == 0 {
+		c.Ui.Error(fmt.Sprintf("No job(s) with prefix or id %q found", jobID))
+		return 1
+	}
+	if len(jobs) > 1 && strings.TrimSpace(jobID) != jobs[0].ID {
+		c.Ui.Error(fmt.Sprintf("Prefix matched multiple jobs\n\n%s", createStatusListOutput(jobs)))
+		return 1
+	}
+	jobID = jobs[0].ID
+
+	sampleInterval := c.sampleInterval
+	if sampleInterval == 0 {
+		sampleInterval = metricsSampleInterval
+	}
+	watchUnit := c.watchUnit
+	if watchUnit == 0 {
+		watchUnit = time.Second
+	}
+
+	var prev *jobMetrics
+	for {
+		job, _, err := client.Jobs().Info(jobID, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error querying job: %s", err))
+			return 1
+		}
+		if prev == nil {
+			// the first sample, only for the rates
+			if prev, err = sampleJobMetrics(client, jobID, task); err != nil {
+				c.Ui.Error(fmt.Sprintf("Error querying job allocations: %s", err))
+				return 1
+			}
+			time.Sleep(sampleInterval)
+		}
+		metrics, err := sampleJobMetrics(client, jobID, task)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error querying job allocations: %s", err))
+			return 1
+		}
+		if len(metrics.Tasks) == 0 {
+			c.Ui.Error(fmt.Sprintf("No allocations of job %q found", jobID))
+			return 1
+		}
+		metrics.computeRates(prev)
+		prev = metrics
+
+		if jsonOutput {
+			buf, err := json.MarshalIndent(metrics, "", "    ")
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error converting metrics: %s", err))
+				return 1
+			}
+			c.Ui.Output(string(buf))
+		} else {
+			c.outputJobMetrics(metrics)
+		}
+
+		if watch == 0 {
+			return 0
+		}
+		if *job.Status == models.JobStatusDead {
+			if !jsonOutput {
+				c.Ui.Info(fmt.Sprintf("==> Job %q is dead", jobID))
+			}
+			return 0
+		}
+		time.Sleep(time.Duration(watch) * watchUnit)
+	}
+}
+
+// jobMetrics is a snapshot of the metrics of the tasks of a job.
+type jobMetrics struct {
+	JobID string
+	// unix nano
+	Time  int64
+	Tasks []*taskMetrics
+}
+
+// taskMetrics is the metrics of the latest allocation of a task.
+type taskMetrics struct {
+	Task    string
+	AllocID string
+	// ClientStatus of the allocation. The stats are only read from a running allocation.
+	Status string
+	// the rates since the previous snapshot, or -1 if unknown
+	RowsPerSecond float64
+	TxsPerSecond  float64
+	Stats         *api.TaskStatistics
+	// the error reading the stats
+	Error string
+}
+
+// sampleJobMetrics reads the stats of the latest allocation of each task of a job, or only of the given task.
+func sampleJobMetrics(client *api.Client, jobID string, task string) (*jobMetrics, error) {
+	latest, err := latestTaskAllocs(client, jobID, task)
+	if err != nil {
+		return nil, err
+	}
+	metrics := &jobMetrics{JobID: jobID, Time: time.Now().UnixNano()}
+	for _, task := range sortedTasks(latest) {
+		alloc := latest[task]
+		m := &taskMetrics{
+			Task:          task,
+			AllocID:       alloc.ID,
+			Status:        alloc.ClientStatus,
+			RowsPerSecond: -1,
+			TxsPerSecond:  -1,
+		}
+		metrics.Tasks = append(metrics.Tasks, m)
+		if alloc.ClientStatus != models.AllocClientStatusRunning {
+			continue
+		}
+		m.Stats, err = allocTaskStats(client, alloc)
+		if err != nil {
+			m.Error = err.Error()
+		}
+	}
+	return metrics, nil
+}
+
+func allocTaskStats(client *api.Client, alloc *api.AllocationListStub) (*api.TaskStatistics, error) {
+	full, _, err := client.Allocations().Info(alloc.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := client.Allocations().Stats(full, nil)
+	if err != nil {
+		return nil, err
+	}
+	taskStats, ok := stats.Tasks[alloc.Task]
+	if !ok {
+		return nil, fmt.Errorf("no stats of task %v collected yet", alloc.Task)
+	}
+	return taskStats, nil
+}
+
+// computeRates sets the rates of the tasks since prev, for the allocations in both.
+func (m *jobMetrics) computeRates(prev *jobMetrics) {
+	for _, t := range m.Tasks {
+		for _, p := range prev.Tasks {
+			if p.AllocID != t.AllocID || p.Stats == nil || t.Stats == nil {
+				continue
+			}
+			// The stats are not collected again in between if it is 0.
+			seconds := float64(t.Stats.Timestamp-p.Stats.Timestamp) / float64(time.Second)
+			if seconds <= 0 {
+				continue
+			}
+			t.RowsPerSecond = float64(t.Stats.ExecMasterRowCount-p.Stats.ExecMasterRowCount) / seconds
+			t.TxsPerSecond = float64(t.Stats.ExecMasterTxCount-p.Stats.ExecMasterTxCount) / seconds
+		}
+	}
+}
+
+func formatRate(rate float64) string {
+	if rate < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", rate)
+}
+
+func (c *JobMetricsCommand) outputJobMetrics(m *jobMetrics) {
+	c.Ui.Output(fmt.Sprintf("==> Job %q at %s", m.JobID, formatUnixNanoTime(m.Time)))
+	out := []string{"Task|Alloc ID|Status|Stage|Progress|Rows|Rows/s|Transactions|Txs/s|Lag|Backlog|Errors"}
+	for _, t := range m.Tasks {
+		s := t.Stats
+		if s == nil {
+			out = append(out, fmt.Sprintf("%s|%s|%s|-|-|-|-|-|-|-|-|-", t.Task, t.AllocID, t.Status))
+			continue
+		}
+		lag := "-"
+		if t.Task == models.TaskTypeDest {
+			lag = fmt.Sprintf("%ds", s.ReplicationLag)
+		}
+		out = append(out, fmt.Sprintf("%s|%s|%s|%s|%s%%|%d|%s|%d|%s|%s|%s|%d",
+			t.Task, t.AllocID, t.Status, s.Stage, s.ProgressPct,
+			s.ExecMasterRowCount, formatRate(t.RowsPerSecond), s.ExecMasterTxCount, formatRate(t.TxsPerSecond),
+			lag, s.Backlog, len(s.RecentErrors)))
+	}
+	c.Ui.Output(formatList(out))
+
+	for _, t := range m.Tasks {
+		if t.Error != "" {
+			c.Ui.Error(fmt.Sprintf("[%s] Error reading the stats of allocation %q: %s", t.Task, t.AllocID, t.Error))
+		}
+		if t.Stats != nil && len(t.Stats.RecentErrors) > 0 {
+			last := t.Stats.RecentErrors[len(t.Stats.RecentErrors)-1]
+			c.Ui.Output(fmt.Sprintf("[%s] Last error at %s (%s): %s", t.Task, formatUnixNanoTime(last.Time),
+				last.Type, last.Message))
+		}
+	}
+
+	for _, t := range m.Tasks {
+		if t.Stats == nil || len(t.Stats.TableProgress) == 0 {
+			continue
+		}
+		tables := []string{"Table|Rows Copied|Rows (estimated)|Chunks"}
+		for _, p := range t.Stats.TableProgress {
+			tables = append(tables, fmt.Sprintf("%s.%s|%d|%d|%d",
+				p.TableSchema, p.TableName, p.RowsCopied, p.RowsEstimate, p.Chunk))
+		}
+		c.Ui.Output(fmt.Sprintf("\n[%s] Full copy by table", t.Task))
+		c.Ui.Output(formatList(tables))
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/actiontech/dtle/api"
+)
+
+func TestJobMetricsCommand_Run(t *testing.T) {
+	a := newTestLogsAgent()
+	defer a.server.Close()
+	a.addAlloc("src1", "Src", 10)
+	a.addAlloc("dest1", "Dest", 11)
+	a.stats["src1"] = &api.AllocStatistics{Tasks: map[string]*api.TaskStatistics{"Src": {
+		Timestamp:          1,
+		ExecMasterRowCount: 300,
+		ProgressPct:        "30.0",
+		Stage:              "Sending data",
+		TableProgress: []*api.TableProgress{
+			{TableSchema: "db1", TableName: "tb1", RowsEstimate: 1000, RowsCopied: 300, Chunk: 3},
+		},
+	}}}
+	a.stats["dest1"] = &api.AllocStatistics{Tasks: map[string]*api.TaskStatistics{"Dest": {
+		Timestamp:          1,
+		ExecMasterRowCount: 200,
+		ReplicationLag:     5,
+		RecentErrors:       []*api.ErrorRecord{{Time: 1, Type: "apply", Message: "duplicate entry"}},
+	}}}
+
+	ui := cli.NewMockUi()
+	cmd := &JobMetricsCommand{Meta: Meta{Ui: ui}, sampleInterval: time.Millisecond}
+	if code := cmd.Run([]string{"-address=" + a.server.URL, "job1"}); code != 0 {
+		t.Fatalf("Run() = %v, error: %v", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	for _, want := range []string{"Src   src1", "Sending data  30.0%     300", "Dest  dest1", "5s",
+		"[Dest] Last error at", "(apply): duplicate entry", "[Src] Full copy by table", "db1.tb1  300"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+
+	ui = cli.NewMockUi()
+	cmd = &JobMetricsCommand{Meta: Meta{Ui: ui}, sampleInterval: time.Millisecond}
+	if code := cmd.Run([]string{"-address=" + a.server.URL, "-json", "-task=dest", "job1"}); code != 0 {
+		t.Fatalf("Run() = %v, error: %v", code, ui.ErrorWriter.String())
+	}
+	var metrics jobMetrics
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &metrics); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(metrics.Tasks) != 1 || metrics.Tasks[0].Task != "Dest" || metrics.Tasks[0].Stats.ReplicationLag != 5 {
+		t.Errorf("metrics %+v, want only those of Dest", metrics)
+	}
+
+	ui = cli.NewMockUi()
+	cmd = &JobMetricsCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + a.server.URL, "-watch=-1", "job1"}); code != 1 {
+		t.Errorf("Run() with a negative watch = %v, want 1", code)
+	}
+}
+
+// Snapshots are printed until the job is dead.
+func TestJobMetricsCommand_Run_watch(t *testing.T) {
+	a := newTestLogsAgent()
+	defer a.server.Close()
+	a.addAlloc("src1", "Src", 10)
+	a.stats["src1"] = &api.AllocStatistics{Tasks: map[string]*api.TaskStatistics{"Src": {Timestamp: 1}}}
+
+	ui := cli.NewMockUi()
+	cmd := &JobMetricsCommand{Meta: Meta{Ui: ui}, sampleInterval: time.Millisecond, watchUnit: 10 * time.Millisecond}
+	codeCh := make(chan int)
+	go func() {
+		codeCh <- cmd.Run([]string{"-address=" + a.server.URL, "-watch=1", "job1"})
+	}()
+	waitForOutput(t, ui, "Src   src1")
+	a.update(func() { a.jobStatus = "dead" })
+	select {
+	case code := <-codeCh:
+		if code != 0 {
+			t.Errorf("Run() = %v, error: %v", code, ui.ErrorWriter.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() does not return after the job is dead")
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, `Job "job1" is dead`) {
+		t.Errorf("output %q, want the job is dead", out)
+	}
+}
+
+func TestJobMetrics_computeRates(t *testing.T) {
+	prev := &jobMetrics{Tasks: []*taskMetrics{
+		{AllocID: "a1", Stats: &api.TaskStatistics{Timestamp: int64(time.Second), ExecMasterRowCount: 100, ExecMasterTxCount: 10}},
+		{AllocID: "a2", Stats: &api.TaskStatistics{Timestamp: int64(time.Second)}},
+	}}
+	m := &jobMetrics{Tasks: []*taskMetrics{
+		{AllocID: "a1", RowsPerSecond: -1, TxsPerSecond: -1,
+			Stats: &api.TaskStatistics{Timestamp: int64(3 * time.Second), ExecMasterRowCount: 300, ExecMasterTxCount: 14}},
+		// not collected again
+		{AllocID: "a2", RowsPerSecond: -1, TxsPerSecond: -1, Stats: &api.TaskStatistics{Timestamp: int64(time.Second)}},
+		// a new allocation
+		{AllocID: "a3", RowsPerSecond: -1, TxsPerSecond: -1, Stats: &api.TaskStatistics{Timestamp: int64(3 * time.Second)}},
+	}}
+	m.computeRates(prev)
+	for i, want := range [][2]float64{{100, 2}, {-1, -1}, {-1, -1}} {
+		if got := m.Tasks[i]; got.RowsPerSecond != want[0] || got.TxsPerSecond != want[1] {
+			t.Errorf("%v: rates = %v, %v, want %v", got.AllocID, got.RowsPerSecond, got.TxsPerSecond, want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal/client/driver/mysql"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+type JobPreviewCommand struct {
+	Meta
+	JobGetter
+
+	// The fields below can be overwritten for tests
+	previewTables    func(cfg *config.MySQLDriverConfig) ([]*mysql.TablePreview, error)
+	checkColumnTypes func(srcCfg *config.MySQLDriverConfig, destCfg *config.MySQLDriverConfig) ([]*mysql.ColumnTypeCheck, error)
+}
+
+func (c *JobPreviewCommand) Help() string {
+	helpText := `
+Usage: dtle job-preview [options] <path>
+
+  Connects to the source of the job specified at <path> and lists the
+  tables to be replicated, as resolved from ReplicateDoDb and
+  ReplicateIgnoreDb of the Src task, with estimated numbers of rows.
+  The system schemas (mysql, sys, performance_schema and
+  information_schema) are excluded unless listed in
+  ReplicateSystemSchemas.
+  This helps to catch overly broad filters before starting the job.
+  Nothing is changed on the source.
+
+  If the supplied path is "-", the jobfile is read from stdin. Otherwise
+  it is read from the file at the supplied path or downloaded and
+  read from URL specified.
+
+Preview Options:
+
+  -json
+    Output the tables in JSON format.
+
+  -check-types
+    Also connect to the destination of the Dest task and compare the
+    columns of each table to be replicated with the ones on the
+    destination, by information_schema.COLUMNS. Each column which
+    differs is reported as:
+      safe  - the values are kept, e.g. INT to BIGINT
+      warn  - some values might be truncated, rounded or out of range,
+              e.g. BIGINT to INT or utf8mb4 to latin1
+      error - the values can not be written, e.g. DATETIME to INT, or
+              a column missing on the destination
+    ColumnDefault and ColumnTypeOverride of the Dest task are taken into
+    account. The exit code is 2 if there is an error.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobPreviewCommand) Synopsis() string {
+	return "List the tables to be replicated by a job"
+}
+
+func (c *JobPreviewCommand) Run(args []string) int {
+	var jsonOutput, checkTypes bool
+
+	flags := c.Meta.FlagSet("job-preview", FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&jsonOutput, "json", false, "")
+	flags.BoolVar(&checkTypes, "check-types", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	job, err := c.JobGetter.ApiJob(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting job struct: %s", err))
+		return 1
+	}
+	cfg, err := taskDriverConfig(job, models.TaskTypeSrc)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	var destCfg *config.MySQLDriverConfig
+	if checkTypes {
+		if destCfg, err = taskDriverConfig(job, models.TaskTypeDest); err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	previewTables := c.previewTables
+	if previewTables == nil {
+		previewTables = func(cfg *config.MySQLDriverConfig) ([]*mysql.TablePreview, error) {
+			logger := logrus.New()
+			logger.Out = ioutil.Discard
+			return mysql.PreviewTables(cfg, logger)
+		}
+	}
+	tables, err := previewTables(cfg)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error resolving tables on the source: %s", err))
+		return 1
+	}
+
+	var checks []*mysql.ColumnTypeCheck
+	if checkTypes {
+		checkColumnTypes := c.checkColumnTypes
+		if checkColumnTypes == nil {
+			checkColumnTypes = func(srcCfg *config.MySQLDriverConfig, destCfg *config.MySQLDriverConfig) ([]*mysql.ColumnTypeCheck, error) {
+				logger := logrus.New()
+				logger.Out = ioutil.Discard
+				return mysql.CheckColumnTypes(srcCfg, destCfg, logger)
+			}
+		}
+		if checks, err = checkColumnTypes(cfg, destCfg); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error checking column types: %s", err))
+			return 1
+		}
+	}
+
+	if jsonOutput {
+		if tables == nil {
+			tables = []*mysql.TablePreview{}
+		}
+		var out interface{} = tables
+		if checkTypes {
+			if checks == nil {
+				checks = []*mysql.ColumnTypeCheck{}
+			}
+			out = map[string]interface{}{"tables": tables, "column_types": checks}
+		}
+		buf, err := json.MarshalIndent(out, "", "    ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error converting tables: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(buf))
+		return columnTypeCheckExitCode(checks)
+	}
+
+	if len(tables) == 0 {
+		c.Ui.Output("No table to be replicated")
+		c.outputExcludedSystemSchemas(cfg)
+		return 0
+	}
+	var totalRows int64
+	out := make([]string, len(tables)+1)
+	out[0] = "Table|Rows (estimated)"
+	for i, tb := range tables {
+		out[i+1] = fmt.Sprintf("%s.%s|%d", tb.TableSchema, tb.TableName, tb.RowsEstimate)
+		totalRows += tb.RowsEstimate
+	}
+	c.Ui.Output(formatList(out))
+	c.Ui.Output(fmt.Sprintf("\n%d tables, %d rows (estimated)", len(tables), totalRows))
+	c.outputExcludedSystemSchemas(cfg)
+	if checkTypes {
+		c.outputColumnTypeChecks(checks)
+	}
+	return columnTypeCheckExitCode(checks)
+}
+
+func (c *JobPreviewCommand) outputColumnTypeChecks(checks []*mysql.ColumnTypeCheck) {
+	if len(checks) == 0 {
+		c.Ui.Output("\nAll columns are of the same types on the source and the destination")
+		return
+	}
+	counts := make(map[string]int)
+	out := make([]string, len(checks)+1)
+	out[0] = "Column|Source|Destination|Level|Reason"
+	for i, check := range checks {
+		out[i+1] = fmt.Sprintf("%s.%s.%s|%s|%s|%s|%s", check.TableSchema, check.TableName, check.ColumnName,
+			check.SourceType, check.DestType, check.Level, check.Reason)
+		counts[check.Level]++
+	}
+	c.Ui.Output("\n" + formatList(out))
+	c.Ui.Output(fmt.Sprintf("\n%d errors, %d warnings, %d safe", counts[mysql.TypeCheckError],
+		counts[mysql.TypeCheckWarn], counts[mysql.TypeCheckSafe]))
+}
+
+// columnTypeCheckExitCode returns 2 if a column can not be replicated.
+func columnTypeCheckExitCode(checks []*mysql.ColumnTypeCheck) int {
+	for _, check := range checks {
+		if check.Level == mysql.TypeCheckError {
+			return 2
+		}
+	}
+	return 0
+}
+
+func (c *JobPreviewCommand) outputExcludedSystemSchemas(cfg *config.MySQLDriverConfig) {
+	if schemas := cfg.ExcludedSystemSchemas(); len(schemas) > 0 {
+		c.Ui.Output(fmt.Sprintf("System schemas not replicated (see ReplicateSystemSchemas): %s",
+			strings.Join(schemas, ", ")))
+	}
+}
+
+// taskDriverConfig returns the config of the task of taskType of the job.
+func taskDriverConfig(job *api.Job, taskType string) (*config.MySQLDriverConfig, error) {
+	for _, task := range job.Tasks {
+		if task.Type != taskType {
+			continue
+		}
+		var cfg config.MySQLDriverConfig
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       flattenObjectHook,
+			WeaklyTypedInput: true,
+			Result:           &cfg,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := decoder.Decode(task.Config); err != nil {
+			return nil, fmt.Errorf("Error parsing the config of task %q: %s", task.Type, err)
+		}
+		if cfg.ConnectionConfig == nil {
+			return nil, fmt.Errorf("Task %q has no ConnectionConfig", task.Type)
+		}
+		return &cfg, nil
+	}
+	return nil, fmt.Errorf("Job has no %q task", taskType)
+}
+
+// flattenObjectHook decodes an HCL object (e.g. `ConnectionConfig = {...}`), which is parsed as
+// a list of one map, into a struct.
+func flattenObjectHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.Slice || to.Kind() != reflect.Struct {
+		return data, nil
+	}
+	if l, ok := data.([]map[string]interface{}); ok && len(l) == 1 {
+		return l[0], nil
+	}
+	return data, nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+const testPreviewJob = `
+job "job1" {
+  datacenters = ["dc1"]
+  type = "synchronous"
+
+  task "Src" {
+    driver = "MySQL"
+    config {
+      ReplicateDoDb = [{
+        TableSchema = "db1"
+      }]
+      ConnectionConfig = {
+        Host = "127.0.0.1"
+        Port = "3306"
+        User = "root"
+        Password = "password"
+      }
+    }
+  }
+
+  task "Dest" {
+    driver = "MySQL"
+    config {
+      ConnectionConfig = {
+        Host = "127.0.0.1"
+        Port = "3307"
+        User = "root"
+        Password = "password"
+      }
+    }
+  }
+}
+`
+
+func TestJobPreviewCommand_Run(t *testing.T) {
+	var gotCfg *config.MySQLDriverConfig
+	previewTables := func(cfg *config.MySQLDriverConfig) ([]*mysql.TablePreview, error) {
+		gotCfg = cfg
+		return []*mysql.TablePreview{
+			{TableSchema: "db1", TableName: "a", RowsEstimate: 10},
+			{TableSchema: "db1", TableName: "b", RowsEstimate: 5},
+		}, nil
+	}
+
+	ui := new(cli.MockUi)
+	c := &JobPreviewCommand{
+		Meta:          Meta{Ui: ui},
+		JobGetter:     JobGetter{testStdin: strings.NewReader(testPreviewJob)},
+		previewTables: previewTables,
+	}
+	if code := c.Run([]string{"-"}); code != 0 {
+		t.Fatalf("Run() = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	if gotCfg == nil || gotCfg.ConnectionConfig.Port != 3306 ||
+		len(gotCfg.ReplicateDoDb) != 1 || gotCfg.ReplicateDoDb[0].TableSchema != "db1" {
+		t.Fatalf("previewTables() got the config %+v, want the one of the src task", gotCfg)
+	}
+	out := ui.OutputWriter.String()
+	for _, want := range []string{"db1.a", "db1.b", "2 tables, 15 rows",
+		"not replicated (see ReplicateSystemSchemas): mysql, sys, performance_schema, information_schema"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+
+	ui = new(cli.MockUi)
+	c = &JobPreviewCommand{
+		Meta:          Meta{Ui: ui},
+		JobGetter:     JobGetter{testStdin: strings.NewReader(testPreviewJob)},
+		previewTables: previewTables,
+	}
+	if code := c.Run([]string{"-json", "-"}); code != 0 {
+		t.Fatalf("Run(-json) = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	var tables []*mysql.TablePreview
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &tables); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(tables) != 2 || tables[1].TableName != "b" || tables[1].RowsEstimate != 5 {
+		t.Errorf("tables = %+v", tables)
+	}
+}
+
+func TestJobPreviewCommand_Run_replicateSystemSchemas(t *testing.T) {
+	job := strings.Replace(testPreviewJob, `ReplicateDoDb = [{`,
+		`ReplicateSystemSchemas = ["mysql", "SYS"]
+      ReplicateDoDb = [{`, 1)
+	ui := new(cli.MockUi)
+	c := &JobPreviewCommand{
+		Meta:      Meta{Ui: ui},
+		JobGetter: JobGetter{testStdin: strings.NewReader(job)},
+		previewTables: func(cfg *config.MySQLDriverConfig) ([]*mysql.TablePreview, error) {
+			return nil, nil
+		},
+	}
+	if code := c.Run([]string{"-"}); code != 0 {
+		t.Fatalf("Run() = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	want := "not replicated (see ReplicateSystemSchemas): performance_schema, information_schema\n"
+	if out := ui.OutputWriter.String(); !strings.Contains(out, want) {
+		t.Errorf("output %q does not contain %q", out, want)
+	}
+}
+
+func TestJobPreviewCommand_Run_noSrcTask(t *testing.T) {
+	job := strings.Replace(testPreviewJob, `task "Src"`, `task "Other"`, 1)
+	ui := new(cli.MockUi)
+	c := &JobPreviewCommand{
+		Meta:      Meta{Ui: ui},
+		JobGetter: JobGetter{testStdin: strings.NewReader(job)},
+		previewTables: func(cfg *config.MySQLDriverConfig) ([]*mysql.TablePreview, error) {
+			t.Fatal("previewTables() should not be called")
+			return nil, nil
+		},
+	}
+	if code := c.Run([]string{"-"}); code != 1 {
+		t.Fatalf("Run() = %v, want 1", code)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), `no "Src" task`) {
+		t.Errorf("error = %q", ui.ErrorWriter.String())
+	}
+}
+
+func TestJobPreviewCommand_Run_checkTypes(t *testing.T) {
+	var gotDestCfg *config.MySQLDriverConfig
+	newCommand := func(ui cli.Ui, checks []*mysql.ColumnTypeCheck) *JobPreviewCommand {
+		return &JobPreviewCommand{
+			Meta:      Meta{Ui: ui},
+			JobGetter: JobGetter{testStdin: strings.NewReader(testPreviewJob)},
+			previewTables: func(cfg *config.MySQLDriverConfig) ([]*mysql.TablePreview, error) {
+				return []*mysql.TablePreview{{TableSchema: "db1", TableName: "a", RowsEstimate: 10}}, nil
+			},
+			checkColumnTypes: func(srcCfg *config.MySQLDriverConfig, destCfg *config.MySQLDriverConfig) ([]*mysql.ColumnTypeCheck, error) {
+				gotDestCfg = destCfg
+				return checks, nil
+			},
+		}
+	}
+	checks := []*mysql.ColumnTypeCheck{
+		{TableSchema: "db1", TableName: "a", ColumnName: "id", SourceType: "int(11)", DestType: "bigint(20)",
+			Level: mysql.TypeCheckSafe, Reason: "widened"},
+		{TableSchema: "db1", TableName: "a", ColumnName: "v", SourceType: "bigint(20)", DestType: "int(11)",
+			Level: mysql.TypeCheckWarn, Reason: "narrowed"},
+		{TableSchema: "db1", TableName: "a", ColumnName: "t", SourceType: "datetime", DestType: "int(11)",
+			Level: mysql.TypeCheckError, Reason: "datetime can not be converted to int"},
+	}
+
+	ui := new(cli.MockUi)
+	if code := newCommand(ui, checks).Run([]string{"-check-types", "-"}); code != 2 {
+		t.Fatalf("Run() = %v, want 2. error: %v", code, ui.ErrorWriter.String())
+	}
+	if gotDestCfg == nil || gotDestCfg.ConnectionConfig.Port != 3307 {
+		t.Fatalf("checkColumnTypes() got the dest config %+v, want the one of the dest task", gotDestCfg)
+	}
+	out := ui.OutputWriter.String()
+	for _, want := range []string{"db1.a.v", "bigint(20)", "narrowed", "db1.a.t", "1 errors, 1 warnings, 1 safe"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+
+	ui = new(cli.MockUi)
+	if code := newCommand(ui, checks[:2]).Run([]string{"-check-types", "-json", "-"}); code != 0 {
+		t.Fatalf("Run(-json) = %v, want 0. error: %v", code, ui.ErrorWriter.String())
+	}
+	var result struct {
+		Tables      []*mysql.TablePreview    `json:"tables"`
+		ColumnTypes []*mysql.ColumnTypeCheck `json:"column_types"`
+	}
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &result); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(result.Tables) != 1 || len(result.ColumnTypes) != 2 || result.ColumnTypes[1].Level != mysql.TypeCheckWarn {
+		t.Errorf("result = %+v", result)
+	}
+}
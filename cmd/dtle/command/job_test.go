@@ -9,8 +9,10 @@ package command
 import (
 	"io"
 	"reflect"
+	"strings"
 	"testing"
 	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal"
 
 	"github.com/hashicorp/hcl/hcl/ast"
 )
@@ -58,7 +60,27 @@ func TestParse(t *testing.T) {
 		want    *api.Job
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "tags",
+			args: args{strings.NewReader(`job "j1" {
+  datacenters = ["dc1"]
+  tags {
+    env = "prod"
+    team = "dba"
+  }
+}`)},
+			want: &api.Job{
+				ID:          internal.StringToPtr("j1"),
+				Name:        internal.StringToPtr("j1"),
+				Datacenters: []string{"dc1"},
+				Tags:        map[string]string{"env": "prod", "team": "dba"},
+			},
+		},
+		{
+			name:    "bad key",
+			args:    args{strings.NewReader(`job "j1" { label = "a" }`)},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
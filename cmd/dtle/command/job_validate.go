@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/actiontech/dtle/agent"
+	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal/client/driver/kafka3"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+	"github.com/actiontech/dtle/internal/server"
+)
+
+type JobValidateCommand struct {
+	Meta
+	JobGetter
+}
+
+func (c *JobValidateCommand) Help() string {
+	helpText := `
+Usage: dtle job-validate [options] <path>
+
+  Checks the job specified at <path> as it is checked when registered,
+  without connecting to the agent or to any database. The errors fail
+  the registration of the job. The warnings do not, but are likely
+  mistakes, e.g. a misspelled job argument, which is ignored.
+
+  The job is in HCL, or in JSON as sent to the HTTP API. If the
+  supplied path is "-", the jobfile is read from stdin. Otherwise it
+  is read from the file at the supplied path or downloaded and read
+  from URL specified.
+
+  The exit code is 1 if there is an error, and 0 otherwise.
+
+Validate Options:
+
+  -strict
+    Treat the warnings as errors, i.e. exit with 1 if there is any.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *JobValidateCommand) Synopsis() string {
+	return "Check a job file without registering it"
+}
+
+func (c *JobValidateCommand) Run(args []string) int {
+	var strict bool
+
+	flags := c.Meta.FlagSet("job-validate", FlagSetNone)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.BoolVar(&strict, "strict", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got exactly one argument
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	aj, err := c.JobGetter.ApiJob(args[0])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting job struct: %s", err))
+		return 1
+	}
+	for _, task := range aj.Tasks {
+		task.Config = flattenObjects(task.Config).(map[string]interface{})
+	}
+	// Before the conversion, which sets some job arguments.
+	warnings := jobWarnings(aj)
+	errs := server.JobValidationErrors(agent.ApiJobToStructJob(aj, 0))
+
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = "* " + err.Error()
+		}
+		c.Ui.Error(fmt.Sprintf("Job validation errors:\n%s", strings.Join(msgs, "\n")))
+	}
+	if len(warnings) > 0 {
+		c.Ui.Warn(fmt.Sprintf("Job validation warnings:\n* %s", strings.Join(warnings, "\n* ")))
+	}
+	if len(errs) > 0 || (strict && len(warnings) > 0) {
+		return 1
+	}
+	c.Ui.Output("Job validation successful")
+	return 0
+}
+
+// jobWarnings returns the problems of the job arguments which do not fail the registration of the job.
+func jobWarnings(job *api.Job) (warnings []string) {
+	for _, task := range job.Tasks {
+		var cfg interface{}
+		var mysqlCfg config.MySQLDriverConfig
+		switch task.Driver {
+		case "", models.TaskDriverMySQL:
+			cfg = &mysqlCfg
+		case models.TaskDriverKafka:
+			cfg = &kafka3.KafkaConfig{}
+		default:
+			continue
+		}
+		var md mapstructure.Metadata
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			WeaklyTypedInput: true,
+			Metadata:         &md,
+			Result:           cfg,
+		})
+		if err != nil {
+			continue
+		}
+		if err := decoder.Decode(task.Config); err != nil {
+			continue // reported by the validation
+		}
+		sort.Strings(md.Unused)
+		for _, key := range md.Unused {
+			warnings = append(warnings, fmt.Sprintf("task %v: unknown job argument %v. it is ignored", task.Type, key))
+		}
+		if len(mysqlCfg.TablePriority) > 0 && (mysqlCfg.DisableForeignKeyChecks == "" ||
+			mysqlCfg.DisableForeignKeyChecks == config.DisableForeignKeyChecksAll) {
+			warnings = append(warnings, fmt.Sprintf("task %v: TablePriority is ignored with DisableForeignKeyChecks=%v",
+				task.Type, config.DisableForeignKeyChecksAll))
+		}
+	}
+	return warnings
+}
+
+// flattenObjects replaces the HCL objects (e.g. `ConnectionConfig = {...}`) in v, which are parsed as
+// lists of one map, by the maps, as they are in a job in JSON. A list of one map is still decoded into
+// a slice (e.g. ReplicateDoDb) by the weak decoding of the drivers.
+func flattenObjects(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = flattenObjects(e)
+		}
+		return v
+	case []map[string]interface{}:
+		if len(v) == 1 {
+			return flattenObjects(v[0])
+		}
+		for i, e := range v {
+			v[i] = flattenObjects(e).(map[string]interface{})
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = flattenObjects(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
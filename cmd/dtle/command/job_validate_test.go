@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+const testValidateJSONJob = `{
+  "Name": "job1",
+  "Tasks": [{
+    "Type": "Src",
+    "Driver": "MySQL",
+    "Config": {
+      "ReplicateDoDb": [{"TableSchema": "db1"}],
+      "ConnectionConfig": {"Host": "127.0.0.1", "Port": 3306, "User": "root", "Password": "password"}
+    }
+  }, {
+    "Type": "Dest",
+    "Driver": "MySQL",
+    "Config": {
+      "ThrottleReplicas": ["127.0.0.1"],
+      "ConnectionConfig": {"Host": "127.0.0.1", "Port": 3307, "User": "root", "Password": "password"}
+    }
+  }]
+}`
+
+func TestJobValidateCommand_Run(t *testing.T) {
+	run := func(job string, args ...string) (int, *cli.MockUi) {
+		ui := new(cli.MockUi)
+		c := &JobValidateCommand{
+			Meta:      Meta{Ui: ui},
+			JobGetter: JobGetter{testStdin: strings.NewReader(job)},
+		}
+		return c.Run(append(args, "-")), ui
+	}
+
+	if code, ui := run(testPreviewJob); code != 0 || !strings.Contains(ui.OutputWriter.String(), "Job validation successful") {
+		t.Fatalf("Run() = %v, want 0. output: %q, error: %q", code, ui.OutputWriter.String(), ui.ErrorWriter.String())
+	}
+
+	// warnings
+	job := strings.Replace(testPreviewJob, "ReplicateDoDb", "ReplicatDoDb", 1)
+	job = strings.Replace(job, `config {
+      ConnectionConfig = {
+        Host = "127.0.0.1"
+        Port = "3307"`, `config {
+      TablePriority = { "db1.a" = 1 }
+      ConnectionConfig = {
+        Hots = "127.0.0.1"
+        Port = "3307"`, 1)
+	code, ui := run(job)
+	if code != 0 {
+		t.Fatalf("Run() with warnings = %v, want 0. error: %q", code, ui.ErrorWriter.String())
+	}
+	for _, want := range []string{"task Src: unknown job argument ReplicatDoDb",
+		"task Dest: unknown job argument ConnectionConfig.Hots",
+		"task Dest: TablePriority is ignored with DisableForeignKeyChecks=all"} {
+		if !strings.Contains(ui.ErrorWriter.String(), want) {
+			t.Errorf("warnings %q do not contain %q", ui.ErrorWriter.String(), want)
+		}
+	}
+	if code, _ := run(job, "-strict"); code != 1 {
+		t.Errorf("Run(-strict) with warnings = %v, want 1", code)
+	}
+
+	// errors, in a job in JSON as sent to the HTTP API
+	code, ui = run(testValidateJSONJob)
+	if code != 1 {
+		t.Fatalf("Run() with errors = %v, want 1. output: %q", code, ui.OutputWriter.String())
+	}
+	if want := "* task Dest: bad job argument: ThrottleReplicas: 127.0.0.1. should be host:port"; !strings.Contains(ui.ErrorWriter.String(), want) {
+		t.Errorf("errors %q do not contain %q", ui.ErrorWriter.String(), want)
+	}
+	if code, ui := run(`{"Job": ` + strings.Replace(testValidateJSONJob, `["127.0.0.1"]`, `["127.0.0.1:3308"]`, 1) + `}`); code != 0 {
+		t.Errorf("Run() of a job request = %v, want 0. error: %q", code, ui.ErrorWriter.String())
+	}
+}
@@ -22,6 +22,9 @@ import (
 var (
 	// enforceIndexRegex is a regular expression which extracts the enforcement error
 	enforceIndexRegex = regexp.MustCompile(`\((Enforcing job modify index.*)\)`)
+
+	// validationErrorsRegex is a regular expression which extracts the job validation errors
+	validationErrorsRegex = regexp.MustCompile(`(?s)\(Job validation failed:\n(.*)\)`)
 )
 
 type StartCommand struct {
@@ -198,6 +201,14 @@ func (c *StartCommand) Run(args []string) int {
 				return 1
 			}
 		}
+		if strings.Contains(err.Error(), api.RegisterValidationErrPrefix) {
+			matches := validationErrorsRegex.FindStringSubmatch(err.Error())
+			if len(matches) == 2 {
+				c.Ui.Error(c.Colorize().Color("[bold][red]Job validation errors:[reset]"))
+				c.Ui.Error(matches[1])
+				return 1
+			}
+		}
 
 		c.Ui.Error(fmt.Sprintf("Error submitting job: %s", err))
 		return 1
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/actiontech/dtle/api"
+	"github.com/actiontech/dtle/internal/models"
 )
 
 const (
@@ -50,6 +51,10 @@ Status Options:
 
   -verbose
     Display full information.
+
+  -filter tag=<key>:<value>[,<key>:<value>...]
+    In list mode, only list the jobs having all of the given tags,
+    e.g. -filter tag=env:prod.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -60,6 +65,7 @@ func (c *StatusCommand) Synopsis() string {
 
 func (c *StatusCommand) Run(args []string) int {
 	var short bool
+	var filter string
 
 	flags := c.Meta.FlagSet("status", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
@@ -67,6 +73,7 @@ func (c *StatusCommand) Run(args []string) int {
 	flags.BoolVar(&c.evals, "evals", false, "")
 	flags.BoolVar(&c.allAllocs, "all-allocs", false, "")
 	flags.BoolVar(&c.verbose, "verbose", false, "")
+	flags.StringVar(&filter, "filter", "", "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -91,7 +98,17 @@ func (c *StatusCommand) Run(args []string) int {
 
 	// Invoke list mode if no job ID.
 	if len(args) == 0 {
-		jobs, _, err := client.Jobs().List(nil)
+		tagFilter, err := parseTagFilterFlag(filter)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		var jobs []*api.JobListStub
+		if tagFilter != "" {
+			jobs, _, err = client.Jobs().TagList(tagFilter)
+		} else {
+			jobs, _, err = client.Jobs().List(nil)
+		}
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("Error querying jobs: %s", err))
 			return 1
@@ -134,6 +151,7 @@ func (c *StatusCommand) Run(args []string) int {
 		fmt.Sprintf("Name|%s", *job.Name),
 		fmt.Sprintf("Type|%s", *job.Type),
 		fmt.Sprintf("Datacenters|%s", strings.Join(job.Datacenters, ",")),
+		fmt.Sprintf("Tags|%s", formatTags(job.Tags)),
 		fmt.Sprintf("Status|%s", *job.Status),
 	}
 
@@ -304,12 +322,45 @@ func sortedTaskFromMetrics(groups map[string]*api.AllocationMetric) []string {
 // list general information about a list of jobs
 func createStatusListOutput(jobs []*api.JobListStub) string {
 	out := make([]string, len(jobs)+1)
-	out[0] = "ID|Type|Status"
+	out[0] = "ID|Type|Status|Tags"
 	for i, job := range jobs {
-		out[i+1] = fmt.Sprintf("%s|%s|%s",
+		out[i+1] = fmt.Sprintf("%s|%s|%s|%s",
 			job.ID,
 			job.Type,
-			job.Status)
+			job.Status,
+			formatTags(job.Tags))
 	}
 	return formatList(out)
 }
+
+// formatTags formats tags as "k1=v1,k2=v2", sorted by the keys.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, tags[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// parseTagFilterFlag takes the tag filter "k1:v1,k2:v2" of the -filter flag "tag=k1:v1,k2:v2".
+func parseTagFilterFlag(filter string) (string, error) {
+	if filter == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(filter, "tag=") {
+		return "", fmt.Errorf("Bad -filter %q: should be tag=<key>:<value>[,<key>:<value>...]", filter)
+	}
+	tags, err := models.ParseTagFilter(strings.TrimPrefix(filter, "tag="))
+	if err != nil {
+		return "", fmt.Errorf("Bad -filter %q: %v", filter, err)
+	}
+	if len(tags) == 0 {
+		return "", fmt.Errorf("Bad -filter %q: no tag", filter)
+	}
+	return strings.TrimPrefix(filter, "tag="), nil
+}
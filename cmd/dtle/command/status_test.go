@@ -242,13 +242,43 @@ func Test_createStatusListOutput(t *testing.T) {
 		args args
 		want string
 	}{
-		// TODO: Add test cases.
+		{
+			name: "tags",
+			args: args{[]*api.JobListStub{
+				{ID: "j1", Type: "synchronous", Status: "running", Tags: map[string]string{"team": "dba", "env": "prod"}},
+				{ID: "j2", Type: "synchronous", Status: "pause"},
+			}},
+			want: "ID  Type         Status   Tags\n" +
+				"j1  synchronous  running  env=prod,team=dba\n" +
+				"j2  synchronous  pause    <none>",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if got := createStatusListOutput(tt.args.jobs); got != tt.want {
-				t.Errorf("createStatusListOutput() = %v, want %v", got, tt.want)
+				t.Errorf("createStatusListOutput() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_parseTagFilterFlag(t *testing.T) {
+	tests := []struct {
+		filter  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"tag=env:prod", "env:prod", false},
+		{"tag=env:prod,team:dba", "env:prod,team:dba", false},
+		{"env:prod", "", true},
+		{"tag=env", "", true},
+		{"tag=", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseTagFilterFlag(tt.filter)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseTagFilterFlag(%q) = %q, %v, want %q, wantErr %v", tt.filter, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -105,11 +105,36 @@ func realMain() int {
 				Meta: meta,
 			}, nil
 		},*/
+		"job-diff": func() (cli.Command, error) {
+			return &command.JobDiffCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job-logs": func() (cli.Command, error) {
+			return &command.JobLogsCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job-metrics": func() (cli.Command, error) {
+			return &command.JobMetricsCommand{
+				Meta: meta,
+			}, nil
+		},
+		"job-preview": func() (cli.Command, error) {
+			return &command.JobPreviewCommand{
+				Meta: meta,
+			}, nil
+		},
 		"job-status": func() (cli.Command, error) {
 			return &command.StatusCommand{
 				Meta: meta,
 			}, nil
 		},
+		"job-validate": func() (cli.Command, error) {
+			return &command.JobValidateCommand{
+				Meta: meta,
+			}, nil
+		},
 		"version": func() (cli.Command, error) {
 			return &command.VersionCommand{
 				Version: Version,
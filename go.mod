@@ -5,7 +5,7 @@ go 1.12
 require (
 	github.com/DataDog/datadog-go v0.0.0-20180330214955-e67964b4021a // indirect
 	github.com/NYTimes/gziphandler v1.0.1
-	github.com/Shopify/sarama v1.17.0
+	github.com/Shopify/sarama v1.22.1
 	github.com/Shopify/toxiproxy v2.1.4+incompatible // indirect
 	github.com/araddon/dateparse v0.0.0-20180729174819-cfd92a431d0e // indirect
 	github.com/araddon/gou v0.0.0-20180803232539-d7d8174cb8b3 // indirect
@@ -87,7 +87,7 @@ require (
 	github.com/posener/complete v1.1.2 // indirect
 	github.com/prometheus/client_golang v0.9.4
 	github.com/rakyll/autopprof v0.1.0
-	github.com/rcrowley/go-metrics v0.0.0-20180503174638-e2704e165165 // indirect
+	github.com/rcrowley/go-metrics v0.0.0-20181016184325-3113b8401b8a // indirect
 	github.com/ryanuber/columnize v2.1.0+incompatible
 	github.com/satori/go.uuid v1.2.0
 	github.com/sean-/seed v0.0.0-20170313163322-e2103e2c3529 // indirect
@@ -102,6 +102,8 @@ require (
 	github.com/uber/jaeger-client-go v2.16.0+incompatible
 	github.com/ugorji/go/codec v0.0.0-20190204201341-e444a5086c43
 	github.com/ulikunitz/xz v0.5.4 // indirect
+	github.com/xdg/scram v0.0.0-20180814205039-7eeb5667e42c
+	golang.org/x/crypto v0.0.0-20190404164418-38d8ce5564a5
 	golang.org/x/net v0.0.0-20190620200207-3b0461eec859
 	golang.org/x/text v0.3.2
 	gopkg.in/ini.v1 v1.46.0 // indirect
@@ -109,7 +111,7 @@ require (
 	gopkg.in/vmihailenco/msgpack.v2 v2.9.1 // indirect
 )
 
-replace github.com/siddontang/go-mysql => github.com/ffffwh/go-mysql v0.0.0-20190808080311-dd15a3c6
+replace github.com/siddontang/go-mysql => ./third_party/go-mysql
 
 replace github.com/araddon/qlbridge => github.com/ffffwh/qlbridge v0.0.0-20181026023605-fc2d5205
 
@@ -27,6 +27,11 @@ type AllocStatsReporter interface {
 	LatestAllocStats(taskFilter string) (*models.AllocStatistics, error)
 }
 
+type AllocHealthChecker interface {
+	// CheckHealth returns the reason why a task of the allocation is not healthy, or nil.
+	CheckHealth() error
+}
+
 // Allocator is used to wrap an allocation and provide the execution context.
 type Allocator struct {
 	config  *config.ClientConfig
@@ -57,6 +62,9 @@ type Allocator struct {
 
 	// serialize saveAllocatorState calls
 	persistLock sync.Mutex
+
+	// notifier posts task events to the configured webhooks
+	notifier *notifier
 }
 
 // allocatorState is used to snapshot the store of the alloc runner
@@ -83,6 +91,7 @@ func NewAllocator(logger *logrus.Logger, config *config.ClientConfig, updater Al
 		workUpdates: workUpdates,
 		destroyCh:   make(chan struct{}),
 		waitCh:      make(chan struct{}),
+		notifier:    newNotifier(logger, config.Notifications),
 	}
 	return ar
 }
@@ -265,6 +274,7 @@ func (r *Allocator) setTaskState(taskName, state string, event *models.TaskEvent
 			taskState.Failed = true
 		}
 		r.appendTaskEvent(taskState, event)
+		r.notifier.Notify(r.alloc, taskName, event)
 
 		if event.Type == models.TaskKilled {
 			state = models.TaskStateStop
@@ -444,6 +454,7 @@ OUTER:
 				taskDestroyEvent = models.NewTaskEvent(models.TaskKilled)
 				break OUTER
 			}
+			r.updateWorkers(update)
 
 		case <-r.destroyCh:
 			r.logger.Debugf("*** taskDestroyEvent. destroy")
@@ -459,6 +470,20 @@ OUTER:
 	r.logger.Debugf("agent: Terminating runner for alloc '%s'", r.alloc.ID)
 }
 
+// updateWorkers passes the tasks of an in-place update to the task runners. See Worker.Update.
+func (r *Allocator) updateWorkers(update *models.Allocation) {
+	if update.Job == nil {
+		return
+	}
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+	for _, task := range update.Job.Tasks {
+		if tr, ok := r.tasks[task.Type]; ok {
+			tr.Update(task)
+		}
+	}
+}
+
 // destroyWorkers destroys the task runners, waits for them to terminate and
 // then saves store.
 func (r *Allocator) destroyWorkers(destroyEvent *models.TaskEvent) {
@@ -509,6 +534,20 @@ func (r *Allocator) StatsReporter() AllocStatsReporter {
 	return r
 }
 
+// CheckHealth implements AllocHealthChecker.
+func (r *Allocator) CheckHealth() error {
+	runners := r.getWorkers()
+	if len(runners) == 0 {
+		return fmt.Errorf("allocation %q has no task running", r.alloc.ID)
+	}
+	for _, tr := range runners {
+		if err := tr.CheckHealth(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // getWorkers is a helper that returns a copy of the task runners list using
 // the taskLock.
 func (r *Allocator) getWorkers() []*Worker {
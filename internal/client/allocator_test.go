@@ -8,16 +8,16 @@ package client
 
 import (
 	"reflect"
-	"sync"
 	"testing"
+
 	"github.com/actiontech/dtle/internal/config"
-	log "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/models"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewAllocator(t *testing.T) {
 	type args struct {
-		logger      *log.Logger
+		logger      *logrus.Logger
 		config      *config.ClientConfig
 		updater     AllocStateUpdater
 		alloc       *models.Allocation
@@ -43,24 +43,19 @@ func TestAllocator_stateFilePath(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -78,20 +73,15 @@ func TestAllocator_stateFilePath(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if got := r.stateFilePath(); got != tt.want {
 				t.Errorf("Allocator.stateFilePath() = %v, want %v", got, tt.want)
@@ -104,24 +94,19 @@ func TestAllocator_SaveState(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -139,20 +124,15 @@ func TestAllocator_SaveState(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if err := r.SaveState(); (err != nil) != tt.wantErr {
 				t.Errorf("Allocator.SaveState() error = %v, wantErr %v", err, tt.wantErr)
@@ -165,24 +145,19 @@ func TestAllocator_saveAllocatorState(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -200,20 +175,15 @@ func TestAllocator_saveAllocatorState(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if err := r.saveAllocatorState(); (err != nil) != tt.wantErr {
 				t.Errorf("Allocator.saveAllocatorState() error = %v, wantErr %v", err, tt.wantErr)
@@ -226,24 +196,19 @@ func TestAllocator_saveWorkerState(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		tr *Worker
@@ -265,20 +230,15 @@ func TestAllocator_saveWorkerState(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if err := r.saveWorkerState(tt.args.tr); (err != nil) != tt.wantErr {
 				t.Errorf("Allocator.saveWorkerState() error = %v, wantErr %v", err, tt.wantErr)
@@ -291,24 +251,19 @@ func TestAllocator_DestroyState(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -326,20 +281,15 @@ func TestAllocator_DestroyState(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if err := r.DestroyState(); (err != nil) != tt.wantErr {
 				t.Errorf("Allocator.DestroyState() error = %v, wantErr %v", err, tt.wantErr)
@@ -372,24 +322,19 @@ func TestAllocator_Alloc(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -407,20 +352,15 @@ func TestAllocator_Alloc(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if got := r.Alloc(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Allocator.Alloc() = %v, want %v", got, tt.want)
@@ -433,24 +373,19 @@ func TestAllocator_dirtySyncState(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -467,20 +402,15 @@ func TestAllocator_dirtySyncState(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.dirtySyncState()
 		})
@@ -491,24 +421,19 @@ func TestAllocator_syncStatus(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -526,20 +451,15 @@ func TestAllocator_syncStatus(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if err := r.syncStatus(); (err != nil) != tt.wantErr {
 				t.Errorf("Allocator.syncStatus() error = %v, wantErr %v", err, tt.wantErr)
@@ -552,24 +472,19 @@ func TestAllocator_setStatus(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		status string
@@ -591,20 +506,15 @@ func TestAllocator_setStatus(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.setStatus(tt.args.status, tt.args.desc)
 		})
@@ -615,24 +525,19 @@ func TestAllocator_setTaskState(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		taskName string
@@ -655,20 +560,15 @@ func TestAllocator_setTaskState(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.setTaskState(tt.args.taskName, tt.args.state, tt.args.event)
 		})
@@ -679,24 +579,19 @@ func TestAllocator_appendTaskEvent(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		state *models.TaskState
@@ -718,20 +613,15 @@ func TestAllocator_appendTaskEvent(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.appendTaskEvent(tt.args.state, tt.args.event)
 		})
@@ -742,24 +632,19 @@ func TestAllocator_Run(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -776,20 +661,15 @@ func TestAllocator_Run(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.Run()
 		})
@@ -800,24 +680,19 @@ func TestAllocator_destroyWorkers(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		destroyEvent *models.TaskEvent
@@ -838,20 +713,15 @@ func TestAllocator_destroyWorkers(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.destroyWorkers(tt.args.destroyEvent)
 		})
@@ -862,24 +732,19 @@ func TestAllocator_handleDestroy(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -896,20 +761,15 @@ func TestAllocator_handleDestroy(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.handleDestroy()
 		})
@@ -920,24 +780,19 @@ func TestAllocator_Update(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		update *models.Allocation
@@ -958,20 +813,15 @@ func TestAllocator_Update(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.Update(tt.args.update)
 		})
@@ -982,24 +832,19 @@ func TestAllocator_StatsReporter(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1017,20 +862,15 @@ func TestAllocator_StatsReporter(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if got := r.StatsReporter(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Allocator.StatsReporter() = %v, want %v", got, tt.want)
@@ -1043,24 +883,19 @@ func TestAllocator_getWorkers(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1078,20 +913,15 @@ func TestAllocator_getWorkers(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if got := r.getWorkers(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Allocator.getWorkers() = %v, want %v", got, tt.want)
@@ -1104,24 +934,19 @@ func TestAllocator_LatestAllocStats(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		taskFilter string
@@ -1144,20 +969,15 @@ func TestAllocator_LatestAllocStats(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			got, err := r.LatestAllocStats(tt.args.taskFilter)
 			if (err != nil) != tt.wantErr {
@@ -1175,24 +995,19 @@ func TestAllocator_shouldUpdate(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	type args struct {
 		serverIndex uint64
@@ -1214,20 +1029,15 @@ func TestAllocator_shouldUpdate(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if got := r.shouldUpdate(tt.args.serverIndex); got != tt.want {
 				t.Errorf("Allocator.shouldUpdate() = %v, want %v", got, tt.want)
@@ -1240,24 +1050,19 @@ func TestAllocator_Destroy(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1274,20 +1079,15 @@ func TestAllocator_Destroy(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			r.Destroy()
 		})
@@ -1298,24 +1098,19 @@ func TestAllocator_WaitCh(t *testing.T) {
 	type fields struct {
 		config                 *config.ClientConfig
 		updater                AllocStateUpdater
-		logger                 *log.Logger
+		logger                 *logrus.Logger
 		alloc                  *models.Allocation
 		allocClientStatus      string
 		allocClientDescription string
-		allocLock              sync.Mutex
 		dirtyCh                chan struct{}
 		tasks                  map[string]*Worker
 		taskStates             map[string]*models.TaskState
 		restored               map[string]struct{}
-		taskLock               sync.RWMutex
-		taskStatusLock         sync.RWMutex
 		updateCh               chan *models.Allocation
 		workUpdates            chan *models.TaskUpdate
 		destroy                bool
 		destroyCh              chan struct{}
-		destroyLock            sync.Mutex
 		waitCh                 chan struct{}
-		persistLock            sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1333,20 +1128,15 @@ func TestAllocator_WaitCh(t *testing.T) {
 				alloc:                  tt.fields.alloc,
 				allocClientStatus:      tt.fields.allocClientStatus,
 				allocClientDescription: tt.fields.allocClientDescription,
-				allocLock:              tt.fields.allocLock,
 				dirtyCh:                tt.fields.dirtyCh,
 				tasks:                  tt.fields.tasks,
 				taskStates:             tt.fields.taskStates,
 				restored:               tt.fields.restored,
-				taskLock:               tt.fields.taskLock,
-				taskStatusLock:         tt.fields.taskStatusLock,
 				updateCh:               tt.fields.updateCh,
 				workUpdates:            tt.fields.workUpdates,
 				destroy:                tt.fields.destroy,
 				destroyCh:              tt.fields.destroyCh,
-				destroyLock:            tt.fields.destroyLock,
 				waitCh:                 tt.fields.waitCh,
-				persistLock:            tt.fields.persistLock,
 			}
 			if got := r.WaitCh(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Allocator.WaitCh() = %v, want %v", got, tt.want)
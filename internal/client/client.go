@@ -382,6 +382,18 @@ func (c *Client) GetAllocStats(allocID string) (AllocStatsReporter, error) {
 	return ar.StatsReporter(), nil
 }
 
+// GetAllocHealth returns the AllocHealthChecker for the passed allocation.
+// If it does not exist an error is reported.
+func (c *Client) GetAllocHealth(allocID string) (AllocHealthChecker, error) {
+	c.allocLock.RLock()
+	defer c.allocLock.RUnlock()
+	ar, ok := c.allocs[allocID]
+	if !ok {
+		return nil, fmt.Errorf("unknown allocation ID %q", allocID)
+	}
+	return ar, nil
+}
+
 // GetClientAlloc returns the allocation from the client
 func (c *Client) GetClientAlloc(allocID string) (*models.Allocation, error) {
 	all := c.allAllocs()
@@ -1484,6 +1496,10 @@ func (c *Client) emitClientMetrics() {
 	metrics.SetGauge([]string{"client", "allocations", "pending", nodeID}, float32(pending))
 	metrics.SetGauge([]string{"client", "allocations", "running", nodeID}, float32(running))
 	metrics.SetGauge([]string{"client", "allocations", "terminal", nodeID}, float32(terminal))
+
+	// The tasks counted against MaxRunningTasks of the node
+	metrics.SetGauge([]string{"client", "tasks", "active", nodeID}, float32(pending+running))
+	metrics.SetGauge([]string{"client", "tasks", "max_running", nodeID}, float32(c.Node().MaxRunningTasks))
 }
 
 // allAllocs returns all the allocations managed by the client
@@ -9,15 +9,15 @@ package client
 import (
 	"net"
 	"reflect"
-	"sync"
 	"testing"
 	"time"
+
 	"github.com/actiontech/dtle/internal/config"
-	ulog "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/models"
 	"github.com/actiontech/dtle/internal/server"
 
 	stand "github.com/nats-io/nats-streaming-server/server"
+	"github.com/sirupsen/logrus"
 )
 
 func Test_newMigrateAllocCtrl(t *testing.T) {
@@ -45,7 +45,6 @@ func Test_migrateAllocCtrl_closeCh(t *testing.T) {
 		alloc  *models.Allocation
 		ch     chan struct{}
 		closed bool
-		chLock sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -59,7 +58,6 @@ func Test_migrateAllocCtrl_closeCh(t *testing.T) {
 				alloc:  tt.fields.alloc,
 				ch:     tt.fields.ch,
 				closed: tt.fields.closed,
-				chLock: tt.fields.chLock,
 			}
 			m.closeCh()
 		})
@@ -69,7 +67,7 @@ func Test_migrateAllocCtrl_closeCh(t *testing.T) {
 func TestNewClient(t *testing.T) {
 	type args struct {
 		cfg    *config.ClientConfig
-		logger *ulog.Logger
+		logger *logrus.Logger
 	}
 	tests := []struct {
 		name    string
@@ -98,27 +96,21 @@ func TestClient_init(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -133,27 +125,21 @@ func TestClient_init(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.init(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.init() error = %v, wantErr %v", err, tt.wantErr)
@@ -167,27 +153,21 @@ func TestClient_Leave(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -202,27 +182,21 @@ func TestClient_Leave(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.Leave(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.Leave() error = %v, wantErr %v", err, tt.wantErr)
@@ -236,27 +210,21 @@ func TestClient_Datacenter(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -271,27 +239,21 @@ func TestClient_Datacenter(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.Datacenter(); got != tt.want {
 				t.Errorf("Client.Datacenter() = %v, want %v", got, tt.want)
@@ -305,27 +267,21 @@ func TestClient_Region(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -340,27 +296,21 @@ func TestClient_Region(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.Region(); got != tt.want {
 				t.Errorf("Client.Region() = %v, want %v", got, tt.want)
@@ -374,27 +324,21 @@ func TestClient_Shutdown(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -409,27 +353,21 @@ func TestClient_Shutdown(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.Shutdown(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.Shutdown() error = %v, wantErr %v", err, tt.wantErr)
@@ -443,27 +381,21 @@ func TestClient_RPC(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		method string
@@ -484,27 +416,21 @@ func TestClient_RPC(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.RPC(tt.args.method, tt.args.args, tt.args.reply); (err != nil) != tt.wantErr {
 				t.Errorf("Client.RPC() error = %v, wantErr %v", err, tt.wantErr)
@@ -518,27 +444,21 @@ func TestClient_Stats(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -553,27 +473,21 @@ func TestClient_Stats(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.Stats(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.Stats() = %v, want %v", got, tt.want)
@@ -587,27 +501,21 @@ func TestClient_Node(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -622,27 +530,21 @@ func TestClient_Node(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.Node(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.Node() = %v, want %v", got, tt.want)
@@ -656,27 +558,21 @@ func TestClient_StatsReporter(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -691,27 +587,21 @@ func TestClient_StatsReporter(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.StatsReporter(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.StatsReporter() = %v, want %v", got, tt.want)
@@ -725,27 +615,21 @@ func TestClient_GetAllocStats(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		allocID string
@@ -765,27 +649,21 @@ func TestClient_GetAllocStats(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			got, err := c.GetAllocStats(tt.args.allocID)
 			if (err != nil) != tt.wantErr {
@@ -804,27 +682,21 @@ func TestClient_GetClientAlloc(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		allocID string
@@ -844,27 +716,21 @@ func TestClient_GetClientAlloc(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			got, err := c.GetClientAlloc(tt.args.allocID)
 			if (err != nil) != tt.wantErr {
@@ -883,27 +749,21 @@ func TestClient_GetServers(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -918,27 +778,21 @@ func TestClient_GetServers(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.GetServers(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.GetServers() = %v, want %v", got, tt.want)
@@ -952,27 +806,21 @@ func TestClient_SetServers(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		servers []string
@@ -991,27 +839,21 @@ func TestClient_SetServers(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.SetServers(tt.args.servers); (err != nil) != tt.wantErr {
 				t.Errorf("Client.SetServers() error = %v, wantErr %v", err, tt.wantErr)
@@ -1025,27 +867,21 @@ func TestClient_saveState(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1060,27 +896,21 @@ func TestClient_saveState(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.saveState(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.saveState() error = %v, wantErr %v", err, tt.wantErr)
@@ -1094,27 +924,21 @@ func TestClient_getAllocRunners(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1129,27 +953,21 @@ func TestClient_getAllocRunners(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.getAllocRunners(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.getAllocRunners() = %v, want %v", got, tt.want)
@@ -1163,27 +981,21 @@ func TestClient_nodeID(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1199,27 +1011,21 @@ func TestClient_nodeID(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			gotId, err := c.nodeID()
 			if (err != nil) != tt.wantErr {
@@ -1238,27 +1044,21 @@ func TestClient_setupNode(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1273,27 +1073,21 @@ func TestClient_setupNode(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.setupNode(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.setupNode() error = %v, wantErr %v", err, tt.wantErr)
@@ -1307,27 +1101,21 @@ func TestClient_setupNatsServer(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1342,27 +1130,21 @@ func TestClient_setupNatsServer(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.setupNatsServer(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.setupNatsServer() error = %v, wantErr %v", err, tt.wantErr)
@@ -1376,27 +1158,21 @@ func TestClient_setupDrivers(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1411,27 +1187,21 @@ func TestClient_setupDrivers(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.setupDrivers(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.setupDrivers() error = %v, wantErr %v", err, tt.wantErr)
@@ -1445,27 +1215,21 @@ func TestClient_retryIntv(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		base time.Duration
@@ -1484,27 +1248,21 @@ func TestClient_retryIntv(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.retryIntv(tt.args.base); got != tt.want {
 				t.Errorf("Client.retryIntv() = %v, want %v", got, tt.want)
@@ -1518,27 +1276,21 @@ func TestClient_registerAndHeartbeat(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1552,27 +1304,21 @@ func TestClient_registerAndHeartbeat(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.registerAndHeartbeat()
 		})
@@ -1584,27 +1330,21 @@ func TestClient_periodicSnapshot(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1618,27 +1358,21 @@ func TestClient_periodicSnapshot(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.periodicSnapshot()
 		})
@@ -1650,27 +1384,21 @@ func TestClient_run(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1684,27 +1412,21 @@ func TestClient_run(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.run()
 		})
@@ -1716,27 +1438,21 @@ func TestClient_hasNodeChanged(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		oldAttrHash uint64
@@ -1758,27 +1474,21 @@ func TestClient_hasNodeChanged(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			got, got1, got2 := c.hasNodeChanged(tt.args.oldAttrHash, tt.args.oldMetaHash)
 			if got != tt.want {
@@ -1799,27 +1509,21 @@ func TestClient_retryRegisterNode(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1833,27 +1537,21 @@ func TestClient_retryRegisterNode(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.retryRegisterNode()
 		})
@@ -1865,27 +1563,21 @@ func TestClient_registerNode(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1900,27 +1592,21 @@ func TestClient_registerNode(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.registerNode(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.registerNode() error = %v, wantErr %v", err, tt.wantErr)
@@ -1934,27 +1620,21 @@ func TestClient_updateNodeStatus(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -1969,27 +1649,21 @@ func TestClient_updateNodeStatus(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.updateNodeStatus(); (err != nil) != tt.wantErr {
 				t.Errorf("Client.updateNodeStatus() error = %v, wantErr %v", err, tt.wantErr)
@@ -2003,27 +1677,21 @@ func TestClient_updateAllocStatus(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		alloc *models.Allocation
@@ -2041,27 +1709,21 @@ func TestClient_updateAllocStatus(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.updateAllocStatus(tt.args.alloc)
 		})
@@ -2073,27 +1735,21 @@ func TestClient_allocSync(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -2107,27 +1763,21 @@ func TestClient_allocSync(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.allocSync()
 		})
@@ -2139,27 +1789,21 @@ func TestClient_watchAllocations(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		updates  chan *allocUpdates
@@ -2178,27 +1822,21 @@ func TestClient_watchAllocations(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.watchAllocations(tt.args.updates, tt.args.jUpdates)
 		})
@@ -2210,27 +1848,21 @@ func TestClient_watchNodeUpdates(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -2244,27 +1876,21 @@ func TestClient_watchNodeUpdates(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.watchNodeUpdates()
 		})
@@ -2276,27 +1902,21 @@ func TestClient_runAllocs(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		update *allocUpdates
@@ -2314,27 +1934,21 @@ func TestClient_runAllocs(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.runAllocs(tt.args.update)
 		})
@@ -2346,27 +1960,21 @@ func TestClient_blockForRemoteAlloc(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		alloc *models.Allocation
@@ -2384,27 +1992,21 @@ func TestClient_blockForRemoteAlloc(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.blockForRemoteAlloc(tt.args.alloc)
 		})
@@ -2416,27 +2018,21 @@ func TestClient_waitForAllocTerminal(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		allocID string
@@ -2457,27 +2053,21 @@ func TestClient_waitForAllocTerminal(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			got, err := c.waitForAllocTerminal(tt.args.allocID, tt.args.stopCh)
 			if (err != nil) != tt.wantErr {
@@ -2496,27 +2086,21 @@ func TestClient_getNode(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		nodeID string
@@ -2536,27 +2120,21 @@ func TestClient_getNode(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			got, err := c.getNode(tt.args.nodeID)
 			if (err != nil) != tt.wantErr {
@@ -2575,27 +2153,21 @@ func TestClient_removeAlloc(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		alloc *models.Allocation
@@ -2614,27 +2186,21 @@ func TestClient_removeAlloc(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.removeAlloc(tt.args.alloc); (err != nil) != tt.wantErr {
 				t.Errorf("Client.removeAlloc() error = %v, wantErr %v", err, tt.wantErr)
@@ -2648,27 +2214,21 @@ func TestClient_updateAlloc(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		exist  *models.Allocation
@@ -2688,27 +2248,21 @@ func TestClient_updateAlloc(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.updateAlloc(tt.args.exist, tt.args.update); (err != nil) != tt.wantErr {
 				t.Errorf("Client.updateAlloc() error = %v, wantErr %v", err, tt.wantErr)
@@ -2722,27 +2276,21 @@ func TestClient_resumeAlloc(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		alloc *models.Allocation
@@ -2761,27 +2309,21 @@ func TestClient_resumeAlloc(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.resumeAlloc(tt.args.alloc); (err != nil) != tt.wantErr {
 				t.Errorf("Client.resumeAlloc() error = %v, wantErr %v", err, tt.wantErr)
@@ -2795,27 +2337,21 @@ func TestClient_addAlloc(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	type args struct {
 		alloc *models.Allocation
@@ -2834,27 +2370,21 @@ func TestClient_addAlloc(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if err := c.addAlloc(tt.args.alloc); (err != nil) != tt.wantErr {
 				t.Errorf("Client.addAlloc() error = %v, wantErr %v", err, tt.wantErr)
@@ -2868,27 +2398,21 @@ func TestClient_triggerDiscovery(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -2902,27 +2426,21 @@ func TestClient_triggerDiscovery(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.triggerDiscovery()
 		})
@@ -2934,27 +2452,21 @@ func TestClient_emitClientMetrics(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -2968,27 +2480,21 @@ func TestClient_emitClientMetrics(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			c.emitClientMetrics()
 		})
@@ -3000,27 +2506,21 @@ func TestClient_allAllocs(t *testing.T) {
 		config              *config.ClientConfig
 		start               time.Time
 		configCopy          *config.ClientConfig
-		configLock          sync.RWMutex
-		logger              *ulog.Logger
+		logger              *logrus.Logger
 		connPool            *server.ConnPool
 		servers             *serverlist
 		lastHeartbeat       time.Time
 		heartbeatTTL        time.Duration
-		heartbeatLock       sync.Mutex
 		triggerDiscoveryCh  chan struct{}
 		serversDiscoveredCh chan struct{}
 		allocs              map[string]*Allocator
-		allocLock           sync.RWMutex
 		blockedAllocations  map[string]*models.Allocation
-		blockedAllocsLock   sync.RWMutex
 		migratingAllocs     map[string]*migrateAllocCtrl
-		migratingAllocsLock sync.Mutex
 		allocUpdates        chan *models.Allocation
 		workUpdates         chan *models.TaskUpdate
 		stand               *stand.StanServer
 		shutdown            bool
 		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -3035,27 +2535,21 @@ func TestClient_allAllocs(t *testing.T) {
 				config:              tt.fields.config,
 				start:               tt.fields.start,
 				configCopy:          tt.fields.configCopy,
-				configLock:          tt.fields.configLock,
 				logger:              tt.fields.logger,
 				connPool:            tt.fields.connPool,
 				servers:             tt.fields.servers,
 				lastHeartbeat:       tt.fields.lastHeartbeat,
 				heartbeatTTL:        tt.fields.heartbeatTTL,
-				heartbeatLock:       tt.fields.heartbeatLock,
 				triggerDiscoveryCh:  tt.fields.triggerDiscoveryCh,
 				serversDiscoveredCh: tt.fields.serversDiscoveredCh,
 				allocs:              tt.fields.allocs,
-				allocLock:           tt.fields.allocLock,
 				blockedAllocations:  tt.fields.blockedAllocations,
-				blockedAllocsLock:   tt.fields.blockedAllocsLock,
 				migratingAllocs:     tt.fields.migratingAllocs,
-				migratingAllocsLock: tt.fields.migratingAllocsLock,
 				allocUpdates:        tt.fields.allocUpdates,
 				workUpdates:         tt.fields.workUpdates,
 				stand:               tt.fields.stand,
 				shutdown:            tt.fields.shutdown,
 				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
 			}
 			if got := c.allAllocs(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Client.allAllocs() = %v, want %v", got, tt.want)
@@ -3108,8 +2602,7 @@ func Test_newServerList(t *testing.T) {
 
 func Test_serverlist_set(t *testing.T) {
 	type fields struct {
-		e  endpoints
-		mu sync.RWMutex
+		e endpoints
 	}
 	type args struct {
 		in endpoints
@@ -3124,8 +2617,7 @@ func Test_serverlist_set(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &serverlist{
-				e:  tt.fields.e,
-				mu: tt.fields.mu,
+				e: tt.fields.e,
 			}
 			s.set(tt.args.in)
 		})
@@ -3134,8 +2626,7 @@ func Test_serverlist_set(t *testing.T) {
 
 func Test_serverlist_all(t *testing.T) {
 	type fields struct {
-		e  endpoints
-		mu sync.RWMutex
+		e endpoints
 	}
 	tests := []struct {
 		name   string
@@ -3147,8 +2638,7 @@ func Test_serverlist_all(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &serverlist{
-				e:  tt.fields.e,
-				mu: tt.fields.mu,
+				e: tt.fields.e,
 			}
 			if got := s.all(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("serverlist.all() = %v, want %v", got, tt.want)
@@ -3159,8 +2649,7 @@ func Test_serverlist_all(t *testing.T) {
 
 func Test_serverlist_failed(t *testing.T) {
 	type fields struct {
-		e  endpoints
-		mu sync.RWMutex
+		e endpoints
 	}
 	type args struct {
 		e *endpoint
@@ -3175,8 +2664,7 @@ func Test_serverlist_failed(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &serverlist{
-				e:  tt.fields.e,
-				mu: tt.fields.mu,
+				e: tt.fields.e,
 			}
 			s.failed(tt.args.e)
 		})
@@ -3185,8 +2673,7 @@ func Test_serverlist_failed(t *testing.T) {
 
 func Test_serverlist_good(t *testing.T) {
 	type fields struct {
-		e  endpoints
-		mu sync.RWMutex
+		e endpoints
 	}
 	type args struct {
 		e *endpoint
@@ -3201,8 +2688,7 @@ func Test_serverlist_good(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &serverlist{
-				e:  tt.fields.e,
-				mu: tt.fields.mu,
+				e: tt.fields.e,
 			}
 			s.good(tt.args.e)
 		})
@@ -1,8 +1,48 @@
 package common
 
+import "github.com/sirupsen/logrus"
+
 type ExecContext struct {
 	Subject    string
 	Tp         string
 	MaxPayload int
 	StateDir   string
+	AllocID    string
+	TaskType   string
+	// EmitEvent records a driver message as a task event. Might be nil.
+	EmitEvent func(m string, args ...interface{})
+	// EmitTaskEvent records a task event of the given type. Might be nil.
+	EmitTaskEvent func(eventType string, m string, args ...interface{})
+	// IncrCounter increments a metric of the task. Might be nil.
+	IncrCounter func(key []string, val float32)
+}
+
+// Emit calls EmitEvent if it is set.
+func (e *ExecContext) Emit(m string, args ...interface{}) {
+	if e.EmitEvent != nil {
+		e.EmitEvent(m, args...)
+	}
+}
+
+// EmitTyped calls EmitTaskEvent if it is set.
+func (e *ExecContext) EmitTyped(eventType string, m string, args ...interface{}) {
+	if e.EmitTaskEvent != nil {
+		e.EmitTaskEvent(eventType, m, args...)
+	}
+}
+
+// LogFields returns the fields to correlate logs of a job.
+func (e *ExecContext) LogFields() logrus.Fields {
+	return logrus.Fields{
+		"job":   e.Subject,
+		"alloc": e.AllocID,
+		"task":  e.TaskType,
+	}
+}
+
+// Incr calls IncrCounter if it is set.
+func (e *ExecContext) Incr(key []string, val float32) {
+	if e.IncrCounter != nil {
+		e.IncrCounter(key, val)
+	}
 }
@@ -105,3 +105,24 @@ type DriverHandle interface {
 	// Stats returns aggregated stats of the driver
 	Stats() (*models.TaskStatistics, error)
 }
+
+// ConfigValidator is implemented by the drivers which check the job arguments of a task
+// without connecting to the databases. It is called when a job is registered.
+type ConfigValidator interface {
+	// ValidateConfig returns all errors of the config of the task, in a *multierror.Error if several.
+	ValidateConfig(task *models.Task) error
+}
+
+// ConfigUpdater is implemented by the handles which take the changes of models.RuntimeConfigKeys
+// without restarting the task.
+type ConfigUpdater interface {
+	// UpdateConfig is called with the new config of the running task.
+	UpdateConfig(config map[string]interface{}) error
+}
+
+// HealthChecker is implemented by the handles which check the health of their task, for
+// GET /v1/agent/allocation/<ID>/health. A handle without it is healthy while it runs.
+type HealthChecker interface {
+	// CheckHealth returns the reason why the task is not healthy, or nil.
+	CheckHealth() error
+}
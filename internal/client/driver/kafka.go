@@ -24,10 +24,10 @@ func (kd *KafkaDriver) Start(ctx *common.ExecContext, task *models.Task) (Driver
 		return nil, err
 	}
 
-	switch task.Type {
-	case models.TaskTypeSrc:
+	switch {
+	case task.Type == models.TaskTypeSrc:
 		return nil, fmt.Errorf("afka can only be used on 'Dest'")
-	case models.TaskTypeDest:
+	case models.IsDestTaskType(task.Type):
 		runner := kafka3.NewKafkaRunner(ctx, &driverConfig, kd.logger)
 		go runner.Run()
 		return runner, nil
@@ -42,6 +42,15 @@ func (kd *KafkaDriver) Validate(task *models.Task) (*models.TaskValidateResponse
 	return reply, nil
 }
 
+// ValidateConfig implements ConfigValidator.
+func (kd *KafkaDriver) ValidateConfig(task *models.Task) error {
+	var driverConfig kafka3.KafkaConfig
+	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
+		return err
+	}
+	return driverConfig.Validate()
+}
+
 func NewKafkaDriver(ctx *DriverContext) Driver {
 	return &KafkaDriver{DriverContext: *ctx}
 }
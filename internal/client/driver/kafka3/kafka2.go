@@ -8,9 +8,16 @@ package kafka3
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash"
+	"hash/fnv"
+	"io/ioutil"
 	"math/big"
 	"strings"
 
@@ -19,6 +26,9 @@ import (
 	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+
+	"github.com/actiontech/dtle/internal/config"
 )
 
 type SchemaType string
@@ -43,6 +53,33 @@ const (
 	RECORD_OP_UPDATE = "u"
 	RECORD_OP_DELETE = "d"
 	RECORD_OP_READ   = "r"
+
+	PARTITION_KEY_TABLE = "table"
+
+	// Values of KafkaConfig.EmitDeleteTombstone.
+	DELETE_TOMBSTONE_AFTER = "after" // the delete envelope, then the tombstone. The default.
+	DELETE_TOMBSTONE_ONLY  = "only"  // the tombstone instead of the delete envelope
+	DELETE_TOMBSTONE_NONE  = "none"  // the delete envelope only
+
+	// The header of a message carrying the GTID set produced so far. See KafkaConfig.ResumeFromKafka.
+	GTID_EXECUTED_HEADER = "dtle_gtid_executed"
+
+	// Values of KafkaConfig.MessageEnvelope.
+	MESSAGE_ENVELOPE_DTLE     = "dtle"     // the default
+	MESSAGE_ENVELOPE_DEBEZIUM = "debezium" // as the MySQL connector of Debezium. See DebeziumOutput.
+
+	// Values of KafkaConfig.EmitDDL.
+	DDL_MESSAGE_NONE   = "none"   // no DDL message. The default.
+	DDL_MESSAGE_TOPIC  = "topic"  // to KafkaConfig.DDLTopic
+	DDL_MESSAGE_INLINE = "inline" // to the topic of the table, between its rows
+
+	// The version of Debezium of which the messages are produced with MESSAGE_ENVELOPE_DEBEZIUM.
+	DEBEZIUM_VERSION = "1.9.7.Final"
+
+	// Values of KafkaSASLConfig.Mechanism.
+	SASL_MECHANISM_PLAIN         = "PLAIN"
+	SASL_MECHANISM_SCRAM_SHA_256 = "SCRAM-SHA-256"
+	SASL_MECHANISM_SCRAM_SHA_512 = "SCRAM-SHA-512"
 )
 
 type ColDefs []*Schema
@@ -53,10 +90,201 @@ type KafkaConfig struct {
 	Converter string
 	NatsAddr  string
 	Gtid      string // TODO remove?
+	// Columns to choose the partition by. Or PARTITION_KEY_TABLE for all rows of a table on one partition.
+	// Empty: by the message key (primary key).
+	PartitionKey []string
+	// Tables to be sent by this destination if the job has several. Empty: all tables. See MySQLDriverConfig.DestDoDb.
+	DestDoDb []*config.DataSource
+	// Whether to send a tombstone (the row key with a null value) for a DELETE, so that log-compacted topics
+	// drop the deleted key. One of DELETE_TOMBSTONE_*. Empty: DELETE_TOMBSTONE_AFTER.
+	EmitDeleteTombstone string
+	// Whether to record the GTID set of the source produced so far in the GTID_EXECUTED_HEADER of each message
+	// of the incremental copy, and on start to resume from the headers of the last messages of the topics,
+	// skipping the transactions which have been produced. The produced GTID set is also reported as the
+	// position of the task. Requires Kafka 0.11+. The messages are not produced in Kafka transactions, see
+	// resume.go.
+	ResumeFromKafka bool
+	// Connect to the brokers over TLS. nil: plaintext.
+	TLS *KafkaTLSConfig
+	// Authenticate to the brokers with SASL. nil: no authentication.
+	SASL *KafkaSASLConfig
+	// The shape of the messages. One of MESSAGE_ENVELOPE_*. Empty: MESSAGE_ENVELOPE_DTLE.
+	MessageEnvelope string
+	// Whether to send a message for each DDL statement of the incremental copy, so that the consumers can
+	// follow the schema changes. One of DDL_MESSAGE_*. Empty: DDL_MESSAGE_NONE. See DDLPayload.
+	EmitDDL string
+	// The topic of the DDL messages with DDL_MESSAGE_TOPIC, and with DDL_MESSAGE_INLINE of a DDL without
+	// a table (e.g. CREATE DATABASE). Empty: Topic, as the schema change topic of Debezium.
+	DDLTopic string
+}
+
+// KafkaTLSConfig is the TLS connection to the brokers. The files are on the node of the task.
+type KafkaTLSConfig struct {
+	// PEM file of the CA certificates to verify the brokers. Empty: the CAs of the system.
+	CaFile string
+	// PEM files of the client certificate and its key, if the brokers require client authentication.
+	CertFile string
+	KeyFile  string
+	// Do not verify the certificates of the brokers. For testing only.
+	InsecureSkipVerify bool
+}
+
+// KafkaSASLConfig is the SASL authentication to the brokers.
+type KafkaSASLConfig struct {
+	// One of SASL_MECHANISM_*. Empty: SASL_MECHANISM_PLAIN.
+	Mechanism string
+	User      string
+	Password  string
+}
+
+func (c *KafkaConfig) Validate() error {
+	switch c.EmitDeleteTombstone {
+	case "", DELETE_TOMBSTONE_AFTER, DELETE_TOMBSTONE_ONLY, DELETE_TOMBSTONE_NONE:
+	default:
+		return fmt.Errorf("bad EmitDeleteTombstone %v. expect one of %v, %v, %v", c.EmitDeleteTombstone,
+			DELETE_TOMBSTONE_AFTER, DELETE_TOMBSTONE_ONLY, DELETE_TOMBSTONE_NONE)
+	}
+	switch c.MessageEnvelope {
+	case "", MESSAGE_ENVELOPE_DTLE, MESSAGE_ENVELOPE_DEBEZIUM:
+	default:
+		return fmt.Errorf("bad MessageEnvelope %v. expect one of %v, %v", c.MessageEnvelope,
+			MESSAGE_ENVELOPE_DTLE, MESSAGE_ENVELOPE_DEBEZIUM)
+	}
+	switch c.EmitDDL {
+	case "", DDL_MESSAGE_NONE, DDL_MESSAGE_TOPIC, DDL_MESSAGE_INLINE:
+	default:
+		return fmt.Errorf("bad EmitDDL %v. expect one of %v, %v, %v", c.EmitDDL,
+			DDL_MESSAGE_NONE, DDL_MESSAGE_TOPIC, DDL_MESSAGE_INLINE)
+	}
+	if c.TLS != nil && (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("TLS: CertFile and KeyFile must be set together")
+	}
+	if c.SASL != nil {
+		switch c.SASL.Mechanism {
+		case "", SASL_MECHANISM_PLAIN, SASL_MECHANISM_SCRAM_SHA_256, SASL_MECHANISM_SCRAM_SHA_512:
+		default:
+			return fmt.Errorf("SASL: bad mechanism %v. expect one of %v, %v, %v", c.SASL.Mechanism,
+				SASL_MECHANISM_PLAIN, SASL_MECHANISM_SCRAM_SHA_256, SASL_MECHANISM_SCRAM_SHA_512)
+		}
+		if c.SASL.User == "" || c.SASL.Password == "" {
+			return fmt.Errorf("SASL: User and Password must be set")
+		}
+	}
+	return nil
+}
+
+// newTLSConfig loads the files of c.
+func newTLSConfig(c *KafkaTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CaFile != "" {
+		pem, err := ioutil.ReadFile(c.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("TLS: failed to read CaFile: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("TLS: no certificate found in CaFile %v", c.CaFile)
+		}
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("TLS: failed to load CertFile and KeyFile: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// scramClient is the sarama.SCRAMClient of SASL/SCRAM-SHA-256 and SASL/SCRAM-SHA-512.
+type scramClient struct {
+	hashGen      scram.HashGeneratorFcn
+	conversation *scram.ClientConversation
+}
+
+func newSCRAMClientGenerator(hashGen scram.HashGeneratorFcn) func() sarama.SCRAMClient {
+	return func() sarama.SCRAMClient {
+		return &scramClient{hashGen: hashGen}
+	}
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGen.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
+
+// newSaramaConfig returns the config of the producer and the consumer of kcfg.
+func newSaramaConfig(kcfg *KafkaConfig) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = NewPartitionKeyPartitioner
+	if kcfg.ResumeFromKafka {
+		// for the headers
+		config.Version = sarama.V0_11_0_0
+	}
+	if kcfg.TLS != nil {
+		tlsConfig, err := newTLSConfig(kcfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+	if kcfg.SASL != nil {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = kcfg.SASL.User
+		config.Net.SASL.Password = kcfg.SASL.Password
+		switch kcfg.SASL.Mechanism {
+		case SASL_MECHANISM_SCRAM_SHA_256:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = newSCRAMClientGenerator(sha256.New)
+		case SASL_MECHANISM_SCRAM_SHA_512:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			config.Net.SASL.SCRAMClientGeneratorFunc = newSCRAMClientGenerator(sha512.New)
+		default:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		}
+	}
+	return config, nil
+}
+
+// checkBrokers connects to the brokers until one succeeds, to tell a failure of TLS or of the
+// authentication. The producer only tells it has run out of brokers.
+// It fails only if every broker fails, with the errors of all of them.
+func checkBrokers(brokers []string, config *sarama.Config) error {
+	var errs []string
+	for _, addr := range brokers {
+		broker := sarama.NewBroker(addr)
+		err := broker.Open(config)
+		if err == nil {
+			_, err = broker.Connected()
+			broker.Close()
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("broker %v: %v", addr, err))
+	}
+	return fmt.Errorf("failed to connect to any broker: %v", strings.Join(errs, "; "))
 }
 
 type KafkaManager struct {
 	Cfg      *KafkaConfig
+	config   *sarama.Config
 	producer sarama.SyncProducer
 }
 
@@ -65,9 +293,17 @@ func NewKafkaManager(kcfg *KafkaConfig) (*KafkaManager, error) {
 	k := &KafkaManager{
 		Cfg: kcfg,
 	}
-	config := sarama.NewConfig()
-	config.Producer.Return.Successes = true
+	config, err := newSaramaConfig(kcfg)
+	if err != nil {
+		return nil, err
+	}
+	k.config = config
 
+	if kcfg.TLS != nil || kcfg.SASL != nil {
+		if err := checkBrokers(kcfg.Brokers, config); err != nil {
+			return nil, err
+		}
+	}
 	k.producer, err = sarama.NewSyncProducer(kcfg.Brokers, config)
 	if err != nil {
 		return nil, err
@@ -75,14 +311,27 @@ func NewKafkaManager(kcfg *KafkaConfig) (*KafkaManager, error) {
 	return k, nil
 }
 
-func (k *KafkaManager) Send(topic string, key []byte, value []byte) error {
+// Send a message. The partition is chosen by partitionKey if not nil, or by key.
+// See BuildPartitionKey. A nil value is sent as null, i.e. a tombstone.
+func (k *KafkaManager) Send(topic string, key []byte, value []byte, partitionKey []byte) error {
+	return k.SendMessage(NewProducerMessage(topic, key, value, partitionKey))
+}
+
+// NewProducerMessage builds a message to be sent by SendMessage. See Send.
+func NewProducerMessage(topic string, key []byte, value []byte, partitionKey []byte) *sarama.ProducerMessage {
 	msg := &sarama.ProducerMessage{
 		Topic:     topic,
 		Partition: int32(-1),
 		Key:       sarama.ByteEncoder(key),
-		Value:     sarama.ByteEncoder(value),
+		Metadata:  partitionKey,
+	}
+	if value != nil {
+		msg.Value = sarama.ByteEncoder(value)
 	}
+	return msg
+}
 
+func (k *KafkaManager) SendMessage(msg *sarama.ProducerMessage) error {
 	_, _, err := k.producer.SendMessage(msg)
 	if err != nil {
 		return err
@@ -92,6 +341,72 @@ func (k *KafkaManager) Send(topic string, key []byte, value []byte) error {
 	return nil
 }
 
+// partitionKeyPartitioner hashes the partition key passed as ProducerMessage.Metadata, so rows
+// with the same partition key go to the same partition while the message key stays the primary key.
+// Without a partition key it behaves as sarama.NewHashPartitioner.
+type partitionKeyPartitioner struct {
+	hasher   hash.Hash32
+	fallback sarama.Partitioner
+}
+
+func NewPartitionKeyPartitioner(topic string) sarama.Partitioner {
+	return &partitionKeyPartitioner{
+		hasher:   fnv.New32a(),
+		fallback: sarama.NewHashPartitioner(topic),
+	}
+}
+
+func (p *partitionKeyPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	partitionKey, ok := message.Metadata.([]byte)
+	if !ok || partitionKey == nil {
+		return p.fallback.Partition(message, numPartitions)
+	}
+	p.hasher.Reset()
+	if _, err := p.hasher.Write(partitionKey); err != nil {
+		return -1, err
+	}
+	partition := int32(p.hasher.Sum32()) % numPartitions
+	if partition < 0 {
+		partition = -partition
+	}
+	return partition, nil
+}
+
+func (p *partitionKeyPartitioner) RequiresConsistency() bool {
+	return true
+}
+
+// BuildPartitionKey returns the partition key of a row, given KafkaConfig.PartitionKey.
+// It returns nil if partitionKeyCols is empty. If the table does not have all the columns,
+// or partitionKeyCols is PARTITION_KEY_TABLE, the table name is used.
+func BuildPartitionKey(partitionKeyCols []string, schemaName string, tableName string,
+	colNames []string, values []interface{}) ([]byte, error) {
+
+	if len(partitionKeyCols) == 0 {
+		return nil, nil
+	}
+	tableKey := []byte(fmt.Sprintf("%v.%v", schemaName, tableName))
+	if len(partitionKeyCols) == 1 && partitionKeyCols[0] == PARTITION_KEY_TABLE {
+		return tableKey, nil
+	}
+
+	keyValues := make([]interface{}, 0, len(partitionKeyCols))
+	for _, keyCol := range partitionKeyCols {
+		found := false
+		for i := range colNames {
+			if strings.EqualFold(colNames[i], keyCol) {
+				keyValues = append(keyValues, values[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return tableKey, nil
+		}
+	}
+	return json.Marshal(keyValues)
+}
+
 var (
 	SourceSchema = &Schema{
 		Fields: []*Schema{
@@ -195,6 +510,182 @@ type SourcePayload struct {
 	Table    string      `json:"table"`
 }
 
+var (
+	// the source of Debezium 1.x
+	DebeziumSourceSchema = &Schema{
+		Fields: []*Schema{
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "version"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "connector"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "name"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, false, "ts_ms"),
+			{
+				Type:       SCHEMA_TYPE_STRING,
+				Optional:   true,
+				Name:       "io.debezium.data.Enum",
+				Version:    1,
+				Parameters: map[string]interface{}{"allowed": "true,last,false,incremental"},
+				Default:    "false",
+				Field:      "snapshot",
+			},
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "db"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, true, "sequence"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, true, "table"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, false, "server_id"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, true, "gtid"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "file"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, false, "pos"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT32, false, "row"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, true, "thread"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, true, "query"),
+		},
+		Optional: false,
+		Name:     "io.debezium.connector.mysql.Source",
+		Field:    "source",
+		Type:     SCHEMA_TYPE_STRUCT,
+	}
+	// the transaction metadata of Debezium, which is always null
+	DebeziumTransactionSchema = &Schema{
+		Fields: []*Schema{
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "id"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, false, "total_order"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, false, "data_collection_order"),
+		},
+		Optional: true,
+		Field:    "transaction",
+		Type:     SCHEMA_TYPE_STRUCT,
+	}
+)
+
+func NewDebeziumEnvelopeSchema(tableIdent string, colDefs ColDefs) *Schema {
+	before, after := NewBeforeAfter(tableIdent, colDefs)
+	return &Schema{
+		Type: SCHEMA_TYPE_STRUCT,
+		Fields: []*Schema{
+			before,
+			after,
+			DebeziumSourceSchema,
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "op"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, true, "ts_ms"),
+			DebeziumTransactionSchema,
+		},
+		Optional: false,
+		Name:     fmt.Sprintf("%v.Envelope", tableIdent),
+	}
+}
+
+type DebeziumValuePayload struct {
+	Before      *Row                   `json:"before"`
+	After       *Row                   `json:"after"`
+	Source      *DebeziumSourcePayload `json:"source"`
+	Op          string                 `json:"op"`
+	TsMs        int64                  `json:"ts_ms"`
+	Transaction interface{}            `json:"transaction"`
+}
+
+type DebeziumSourcePayload struct {
+	Version   string      `json:"version"`
+	Connector string      `json:"connector"`
+	Name      string      `json:"name"`
+	TsMs      int64       `json:"ts_ms"`
+	Snapshot  string      `json:"snapshot"`
+	Db        string      `json:"db"`
+	Sequence  interface{} `json:"sequence"`
+	Table     string      `json:"table"`
+	ServerID  int64       `json:"server_id"`
+	Gtid      interface{} `json:"gtid"`
+	File      string      `json:"file"`
+	Pos       int64       `json:"pos"`
+	Row       int         `json:"row"`
+	Thread    interface{} `json:"thread"`
+	Query     interface{} `json:"query"`
+}
+
+// DebeziumOutput returns the message value of payload as produced by the MySQL connector of Debezium.
+// The rows of the full copy are reads ("r") of a snapshot, as in Debezium. The last row of the snapshot is
+// not told apart ("last"), as the tables are sent one by one.
+func DebeziumOutput(tableIdent string, colDefs ColDefs, payload *ValuePayload) *DbzOutput {
+	source := payload.Source
+	op, snapshot := payload.Op, "false"
+	if source.Snapshot {
+		op, snapshot = RECORD_OP_READ, "true"
+	}
+	return &DbzOutput{
+		Schema: NewDebeziumEnvelopeSchema(tableIdent, colDefs),
+		Payload: &DebeziumValuePayload{
+			Before: payload.Before,
+			After:  payload.After,
+			Source: newDebeziumSourcePayload(source, snapshot),
+			Op:     op,
+			TsMs:   payload.TsMs,
+		},
+	}
+}
+
+func newDebeziumSourcePayload(source *SourcePayload, snapshot string) *DebeziumSourcePayload {
+	return &DebeziumSourcePayload{
+		Version:   DEBEZIUM_VERSION,
+		Connector: "mysql",
+		Name:      source.Name,
+		TsMs:      source.TsSec * 1000,
+		Snapshot:  snapshot,
+		Db:        source.Db,
+		Table:     source.Table,
+		ServerID:  int64(source.ServerID),
+		Gtid:      source.Gtid,
+		File:      source.File,
+		Pos:       source.Pos,
+		Row:       source.Row,
+		Thread:    source.Thread,
+		Query:     source.Query,
+	}
+}
+
+var (
+	// the key of a DDL message, as the schema change key of Debezium
+	DDLKeySchema = &Schema{
+		Type: SCHEMA_TYPE_STRUCT,
+		Fields: []*Schema{
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "databaseName"),
+		},
+		Optional: false,
+		Name:     "io.debezium.connector.mysql.SchemaChangeKey",
+	}
+)
+
+// NewDDLValueSchema returns the schema of DDLPayload, with the source of the message envelope.
+func NewDDLValueSchema(sourceSchema *Schema) *Schema {
+	return &Schema{
+		Type: SCHEMA_TYPE_STRUCT,
+		Fields: []*Schema{
+			sourceSchema,
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "databaseName"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, true, "tableName"),
+			NewSimpleSchemaField(SCHEMA_TYPE_STRING, false, "ddl"),
+			NewSimpleSchemaField(SCHEMA_TYPE_INT64, true, "ts_ms"),
+		},
+		Optional: false,
+		Name:     "io.debezium.connector.mysql.SchemaChangeValue",
+	}
+}
+
+// DDLKeyPayload is the key of a DDL message. The DDL of a database are in order on a partition.
+type DDLKeyPayload struct {
+	DatabaseName string `json:"databaseName"`
+}
+
+// DDLPayload is the value of a DDL message (see KafkaConfig.EmitDDL), as the schema change event of Debezium,
+// with the table of the statement.
+type DDLPayload struct {
+	// *SourcePayload or *DebeziumSourcePayload, as KafkaConfig.MessageEnvelope. It has the GTID of the DDL.
+	Source       interface{} `json:"source"`
+	DatabaseName string      `json:"databaseName"`
+	// empty for a DDL of a database
+	TableName string `json:"tableName"`
+	// the statement, with the schema and table mapping applied
+	DDL  string `json:"ddl"`
+	TsMs int64  `json:"ts_ms"`
+}
+
 type Schema struct {
 	Type       SchemaType             `json:"type"`
 	Optional   bool                   `json:"optional"`
@@ -412,7 +903,12 @@ func TimeValue(value string) int64 {
 		if err != nil {
 			return 0
 		}
-		microsec, err = strconv.ParseInt(ssms[1], 10, 64)
+		// the digits of the precision of the column, e.g. "123" of TIME(3)
+		frac := ssms[1]
+		if len(frac) > 6 {
+			return 0
+		}
+		microsec, err = strconv.ParseInt(frac+strings.Repeat("0", 6-len(frac)), 10, 64)
 		if err != nil {
 			return 0
 		}
@@ -449,6 +945,30 @@ func DateValue(date string) int64 {
 	}
 	return tm2.Unix() / 60 / 60 / 24
 }
+
+// NewBinaryField is a field of a BINARY, VARBINARY or BLOB column. See BinaryValue.
+func NewBinaryField(optional bool, field string, defaultValue interface{}) *Schema {
+	schema := NewSimpleSchemaWithDefaultField(SCHEMA_TYPE_BYTES, optional, field, defaultValue)
+	// a hint for consumers not aware of the Kafka Connect JSON encoding of bytes
+	schema.Parameters = map[string]interface{}{
+		"encoding": "base64",
+	}
+	return schema
+}
+
+// BinaryValue encodes a value of a BINARY, VARBINARY or BLOB column with base64,
+// as the raw bytes might not be valid UTF-8 and would be corrupted in JSON.
+func BinaryValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return base64.StdEncoding.EncodeToString([]byte(v))
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	default:
+		return value
+	}
+}
+
 func NewJsonField(optional bool, field string) *Schema {
 	return &Schema{
 		Field:    field,
@@ -543,19 +1063,14 @@ func NewYearField(theType SchemaType, optional bool, field string, defaultValue
 	}
 }
 
+// YearValue returns the year of the text of a YEAR value, which is of 4 digits, e.g. 2019. It is 0 for 0000,
+// as the year decoded from the binlog.
 func YearValue(year string) int {
-	int, err := strconv.Atoi(year)
+	value, err := strconv.Atoi(year)
 	if err != nil {
 		return 0
 	}
-	yearValue := int - 1900
-	if 0 < yearValue && yearValue <= 69 {
-		yearValue += 2000
-	} else if 70 <= yearValue && yearValue <= 99 {
-		yearValue += 1900
-	}
-	return yearValue
-
+	return value
 }
 
 func BinaryStringToBytes(s string) (bs []byte) {
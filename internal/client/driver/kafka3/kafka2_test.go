@@ -1,8 +1,27 @@
 package kafka3
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
 )
 
 func TestDecimalValueFromStringMysql(t *testing.T) {
@@ -20,4 +39,301 @@ func TestTimeValue(t *testing.T) {
 	}
 	test("01:02:03",1,2,3,0,false)
 	test("-800:02:03.100000",800,2,3,100000,true)
+	// TIME(3)
+	test("12:34:56.789",12,34,56,789000,false)
+	test("-00:00:01.5",0,0,1,500000,true)
+}
+
+func TestYearValue(t *testing.T) {
+	for value, want := range map[string]int{"2019": 2019, "1901": 1901, "2155": 2155, "0000": 0, "": 0} {
+		if got := YearValue(value); got != want {
+			t.Errorf("YearValue(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestPartitionKeyPartitioner(t *testing.T) {
+	colNames := []string{"id", "user_id", "amount"}
+	partitionOf := func(partitionKeyCols []string, key string, values []interface{}) int32 {
+		partitionKey, err := BuildPartitionKey(partitionKeyCols, "db1", "orders", colNames, values)
+		if err != nil {
+			t.Fatalf("BuildPartitionKey: %v", err)
+		}
+		msg := &sarama.ProducerMessage{
+			Key:      sarama.StringEncoder(key),
+			Metadata: partitionKey,
+		}
+		partition, err := NewPartitionKeyPartitioner("t").Partition(msg, 16)
+		if err != nil {
+			t.Fatalf("Partition: %v", err)
+		}
+		return partition
+	}
+
+	byUser := []string{"user_id"}
+	for i := int64(0); i < 100; i++ {
+		p1 := partitionOf(byUser, fmt.Sprintf("%d", i), []interface{}{i, i % 7, "1.00"})
+		p2 := partitionOf(byUser, fmt.Sprintf("%d", i+1000), []interface{}{i + 1000, i % 7, "2.00"})
+		if p1 != p2 {
+			t.Fatalf("rows with the same partition key go to different partitions: %v %v", p1, p2)
+		}
+	}
+
+	byTable := []string{PARTITION_KEY_TABLE}
+	missingCol := []string{"no_such_column"}
+	pTable := partitionOf(byTable, "1", []interface{}{int64(1), int64(1), "1.00"})
+	for i := int64(0); i < 100; i++ {
+		if p := partitionOf(byTable, fmt.Sprintf("%d", i), []interface{}{i, i, "1.00"}); p != pTable {
+			t.Fatalf("table partitioning: got partition %v, want %v", p, pTable)
+		}
+		// fall back to table name
+		if p := partitionOf(missingCol, fmt.Sprintf("%d", i), []interface{}{i, i, "1.00"}); p != pTable {
+			t.Fatalf("missing column: got partition %v, want %v", p, pTable)
+		}
+	}
+
+	// no PartitionKey: by the message key, as before.
+	partitionKey, err := BuildPartitionKey(nil, "db1", "orders", colNames, []interface{}{int64(1), int64(1), "1.00"})
+	if err != nil || partitionKey != nil {
+		t.Fatalf("BuildPartitionKey without PartitionKey: %v, %v", partitionKey, err)
+	}
+	msg := &sarama.ProducerMessage{Key: sarama.StringEncoder("k1")}
+	p1, _ := NewPartitionKeyPartitioner("t").Partition(msg, 16)
+	p2, _ := sarama.NewHashPartitioner("t").Partition(msg, 16)
+	if p1 != p2 {
+		t.Fatalf("without partition key: got partition %v, want %v", p1, p2)
+	}
+}
+
+// writeTestCert writes a self-signed certificate and its key to dir.
+func writeTestCert(t *testing.T, dir string) (certFile string, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dtle-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewSaramaConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dtle-kafka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestCert(t, dir)
+
+	config, err := newSaramaConfig(&KafkaConfig{})
+	if err != nil {
+		t.Fatalf("newSaramaConfig() error = %v", err)
+	}
+	if config.Net.TLS.Enable || config.Net.SASL.Enable {
+		t.Errorf("TLS or SASL enabled without the config")
+	}
+
+	config, err = newSaramaConfig(&KafkaConfig{
+		TLS:  &KafkaTLSConfig{CaFile: certFile, CertFile: certFile, KeyFile: keyFile},
+		SASL: &KafkaSASLConfig{User: "u", Password: "p"},
+	})
+	if err != nil {
+		t.Fatalf("newSaramaConfig() error = %v", err)
+	}
+	if !config.Net.TLS.Enable || config.Net.TLS.Config.RootCAs == nil || len(config.Net.TLS.Config.Certificates) != 1 {
+		t.Errorf("TLS config = %+v", config.Net.TLS)
+	}
+	if !config.Net.SASL.Enable || config.Net.SASL.User != "u" || config.Net.SASL.Password != "p" {
+		t.Errorf("SASL config = %+v", config.Net.SASL)
+	}
+
+	// the key is not a certificate
+	if _, err := newSaramaConfig(&KafkaConfig{TLS: &KafkaTLSConfig{CaFile: keyFile}}); err == nil {
+		t.Errorf("newSaramaConfig() with a bad CaFile want an error")
+	}
+	if _, err := newSaramaConfig(&KafkaConfig{TLS: &KafkaTLSConfig{CertFile: certFile, KeyFile: certFile}}); err == nil {
+		t.Errorf("newSaramaConfig() with a bad KeyFile want an error")
+	}
+}
+
+func TestNewSaramaConfig_sasl(t *testing.T) {
+	tests := []struct {
+		mechanism     string
+		wantMechanism sarama.SASLMechanism
+		hashGen       scram.HashGeneratorFcn
+	}{
+		{"", sarama.SASLTypePlaintext, nil},
+		{SASL_MECHANISM_PLAIN, sarama.SASLTypePlaintext, nil},
+		{SASL_MECHANISM_SCRAM_SHA_256, sarama.SASLTypeSCRAMSHA256, sha256.New},
+		{SASL_MECHANISM_SCRAM_SHA_512, sarama.SASLTypeSCRAMSHA512, sha512.New},
+	}
+	for _, tt := range tests {
+		config, err := newSaramaConfig(&KafkaConfig{
+			SASL: &KafkaSASLConfig{Mechanism: tt.mechanism, User: "u", Password: "p"},
+		})
+		if err != nil {
+			t.Fatalf("%q: newSaramaConfig() error = %v", tt.mechanism, err)
+		}
+		if err := config.Validate(); err != nil {
+			t.Errorf("%q: sarama config Validate() error = %v", tt.mechanism, err)
+		}
+		if config.Net.SASL.Mechanism != tt.wantMechanism {
+			t.Errorf("%q: Mechanism = %v, want %v", tt.mechanism, config.Net.SASL.Mechanism, tt.wantMechanism)
+		}
+		if tt.hashGen == nil {
+			if config.Net.SASL.SCRAMClientGeneratorFunc != nil {
+				t.Errorf("%q: SCRAMClientGeneratorFunc is set", tt.mechanism)
+			}
+			continue
+		}
+
+		// Run the exchange against a server knowing the password "p", and against one knowing another.
+		for _, password := range []string{"p", "wrong"} {
+			err := testSCRAMExchange(tt.hashGen, config.Net.SASL.SCRAMClientGeneratorFunc(), "u", password)
+			if (err != nil) != (password == "wrong") {
+				t.Errorf("%q: SCRAM exchange with password %q error = %v", tt.mechanism, password, err)
+			}
+		}
+	}
+}
+
+// testSCRAMExchange authenticates client as the user u with the password "p", to a server knowing u
+// with password.
+func testSCRAMExchange(hashGen scram.HashGeneratorFcn, client sarama.SCRAMClient, u string, password string) error {
+	credClient, err := hashGen.NewClient(u, password, "")
+	if err != nil {
+		return err
+	}
+	kf := scram.KeyFactors{Salt: "salt", Iters: 4096}
+	server, err := hashGen.NewServer(func(string) (scram.StoredCredentials, error) {
+		return credClient.GetStoredCredentials(kf), nil
+	})
+	if err != nil {
+		return err
+	}
+	conv := server.NewConversation()
+
+	if err := client.Begin(u, "p", ""); err != nil {
+		return err
+	}
+	challenge := ""
+	for !client.Done() {
+		response, err := client.Step(challenge)
+		if err != nil {
+			return err
+		}
+		if client.Done() {
+			break
+		}
+		if challenge, err = conv.Step(response); err != nil {
+			return err
+		}
+	}
+	if !conv.Valid() {
+		return fmt.Errorf("the server did not authenticate the client")
+	}
+	return nil
+}
+
+func TestNewKafkaManager_failFast(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	_, err = NewKafkaManager(&KafkaConfig{
+		Brokers: []string{addr},
+		SASL:    &KafkaSASLConfig{User: "u", Password: "p"},
+	})
+	if err == nil || !strings.Contains(err.Error(), addr) {
+		t.Errorf("NewKafkaManager() error = %v, want the broker %v", err, addr)
+	}
+}
+
+func TestCheckBrokers(t *testing.T) {
+	up, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer up.Close()
+	// the addresses of closed listeners
+	var downAddrs []string
+	for i := 0; i < 2; i++ {
+		down, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		downAddrs = append(downAddrs, down.Addr().String())
+		down.Close()
+	}
+
+	config := sarama.NewConfig()
+	config.Net.DialTimeout = time.Second
+	if err := checkBrokers([]string{downAddrs[0], up.Addr().String()}, config); err != nil {
+		t.Errorf("checkBrokers() error = %v, want the second broker to succeed", err)
+	}
+	err = checkBrokers(downAddrs, config)
+	if err == nil {
+		t.Fatalf("checkBrokers() expects an error")
+	}
+	for _, addr := range downAddrs {
+		if !strings.Contains(err.Error(), "broker "+addr) {
+			t.Errorf("checkBrokers() error = %v, want the error of broker %v", err, addr)
+		}
+	}
+}
+
+// Set DTLE_TEST_KAFKA_SASL_BROKER, DTLE_TEST_KAFKA_SASL_USER and DTLE_TEST_KAFKA_SASL_PASSWORD to run it
+// against a broker with a SASL listener. DTLE_TEST_KAFKA_SASL_MECHANISM is one of SASL_MECHANISM_*.
+func TestNewKafkaManager_sasl(t *testing.T) {
+	broker := os.Getenv("DTLE_TEST_KAFKA_SASL_BROKER")
+	if broker == "" {
+		t.Skip("DTLE_TEST_KAFKA_SASL_BROKER is not set")
+	}
+	cfg := &KafkaConfig{
+		Brokers: []string{broker},
+		SASL: &KafkaSASLConfig{
+			Mechanism: os.Getenv("DTLE_TEST_KAFKA_SASL_MECHANISM"),
+			User:      os.Getenv("DTLE_TEST_KAFKA_SASL_USER"),
+			Password:  os.Getenv("DTLE_TEST_KAFKA_SASL_PASSWORD"),
+		},
+	}
+	k, err := NewKafkaManager(cfg)
+	if err != nil {
+		t.Fatalf("NewKafkaManager() error = %v", err)
+	}
+	if err := k.Send("dtle-test-sasl", []byte("k"), []byte("v"), nil); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+	k.producer.Close()
+
+	cfg.SASL.Password += "-wrong"
+	if _, err := NewKafkaManager(cfg); err == nil {
+		t.Errorf("NewKafkaManager() with a wrong password want an error")
+	}
 }
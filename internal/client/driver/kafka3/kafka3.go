@@ -18,9 +18,11 @@ import (
 	mysqlDriver "github.com/actiontech/dtle/internal/client/driver/mysql"
 	"github.com/actiontech/dtle/internal/config/mysql"
 
+	"github.com/Shopify/sarama"
 	"github.com/golang/snappy"
 	gonats "github.com/nats-io/go-nats"
 	"github.com/satori/go.uuid"
+	gomysql "github.com/siddontang/go-mysql/mysql"
 
 	"encoding/base64"
 	"encoding/binary"
@@ -44,6 +46,7 @@ const (
 type KafkaRunner struct {
 	logger      *logrus.Entry
 	subject     string
+	taskType    string
 	subjectUUID uuid.UUID
 	natsConn    *gonats.Conn
 	waitCh      chan *models.WaitResult
@@ -55,19 +58,23 @@ type KafkaRunner struct {
 	kafkaMgr    *KafkaManager
 
 	tables map[string](map[string]*config.Table)
+	// the position in the incremental stream, for IncrAck
+	incrAcker *mysqlDriver.IncrAcker
+	// With ResumeFromKafka, the GTID set produced so far.
+	gtidExecuted *gomysql.MysqlGTIDSet
 }
 
 func NewKafkaRunner(execCtx *common.ExecContext, cfg *KafkaConfig, logger *logrus.Logger) *KafkaRunner {
-	entry := logger.WithFields(logrus.Fields{
-		"job": execCtx.Subject,
-	})
+	entry := logger.WithFields(execCtx.LogFields())
 	return &KafkaRunner{
 		subject:     execCtx.Subject,
+		taskType:    execCtx.TaskType,
 		kafkaConfig: cfg,
 		logger:      entry,
 		waitCh:      make(chan *models.WaitResult, 1),
 		shutdownCh:  make(chan struct{}),
 		tables:      make(map[string](map[string]*config.Table)),
+		incrAcker:   mysqlDriver.NewIncrAcker(execCtx.TaskType),
 	}
 }
 func (kr *KafkaRunner) ID() string {
@@ -77,12 +84,16 @@ func (kr *KafkaRunner) ID() string {
 			//ReplicateDoDb:     a.mysqlContext.ReplicateDoDb,
 			//ReplicateIgnoreDb: a.mysqlContext.ReplicateIgnoreDb,
 			//Gtid:              a.mysqlContext.Gtid,
-			//NatsAddr:          a.mysqlContext.NatsAddr,
+			NatsAddr: kr.kafkaConfig.NatsAddr,
 			//ParallelWorkers:   a.mysqlContext.ParallelWorkers,
 			//ConnectionConfig:  a.mysqlContext.ConnectionConfig,
 		},
 	}
 
+	if kr.kafkaConfig.ResumeFromKafka {
+		id.DriverConfig.Gtid = kr.kafkaConfig.Gtid
+	}
+
 	data, err := json.Marshal(id)
 	if err != nil {
 		kr.logger.Errorf("kafka: Failed to marshal ID to JSON: %s", err)
@@ -113,8 +124,8 @@ func (kr *KafkaRunner) Stats() (*models.TaskStatistics, error) {
 	return taskResUsage, nil
 }
 func (kr *KafkaRunner) initNatSubClient() (err error) {
-	natsAddr := fmt.Sprintf("nats://%s", kr.kafkaConfig.NatsAddr)
-	sc, err := gonats.Connect(natsAddr)
+	natsAddr := mysqlDriver.NatsURL(kr.kafkaConfig.NatsAddr)
+	sc, err := mysqlDriver.ConnectNats(kr.kafkaConfig.NatsAddr, nil, kr.logger)
 	if err != nil {
 		kr.logger.WithFields(logrus.Fields{
 			"err":        err,
@@ -133,7 +144,11 @@ func (kr *KafkaRunner) Run() {
 		"broker": kr.kafkaConfig.Brokers,
 	}).Debugf("kafka. broker: %v", kr.kafkaConfig.Brokers)
 
-	var err error
+	err := kr.kafkaConfig.Validate()
+	if err != nil {
+		kr.onError(TaskStateDead, err)
+		return
+	}
 	kr.kafkaMgr, err = NewKafkaManager(kr.kafkaConfig)
 	if err != nil {
 		kr.logger.WithFields(logrus.Fields{
@@ -142,6 +157,17 @@ func (kr *KafkaRunner) Run() {
 		kr.onError(TaskStateDead, err)
 		return
 	}
+	if kr.kafkaConfig.ResumeFromKafka {
+		produced, err := kr.kafkaMgr.ReadGtidExecuted()
+		if err != nil {
+			kr.onError(TaskStateDead, fmt.Errorf("kafka: failed to read the produced GTID set: %v", err))
+			return
+		}
+		if err := kr.initGtidExecuted(produced); err != nil {
+			kr.onError(TaskStateDead, err)
+			return
+		}
+	}
 
 	err = kr.initNatSubClient()
 	if err != nil {
@@ -198,11 +224,14 @@ func (kr *KafkaRunner) initiateStreaming() error {
 			return
 		}
 
-		if dumpData.DbSQL != "" || len(dumpData.TbSQL) > 0 {
+		if dumpData.TableSchema != "" &&
+			!config.MatchDestDoDb(kr.kafkaMgr.Cfg.DestDoDb, dumpData.TableSchema, dumpData.TableName) {
+			kr.logger.Debugf("kafka: skip %v.%v not in DestDoDb", dumpData.TableSchema, dumpData.TableName)
+		} else if dumpData.DbSQL != "" || len(dumpData.TbSQL) > 0 {
 			kr.logger.Debugf("kafka. a sql dumpEntry")
 		} else if dumpData.TableSchema == "" && dumpData.TableName == "" {
 			kr.logger.Debugf("kafka.  skip apply sqlMode and SystemVariablesStatement")
-			if err := kr.natsConn.Publish(m.Reply, nil); err != nil {
+			if err := mysqlDriver.PublishReply(kr.natsConn, m, kr.taskType); err != nil {
 				kr.onError(TaskStateDead, err)
 				return
 			}
@@ -230,7 +259,7 @@ func (kr *KafkaRunner) initiateStreaming() error {
 			}
 		}
 
-		if err := kr.natsConn.Publish(m.Reply, nil); err != nil {
+		if err := mysqlDriver.PublishReply(kr.natsConn, m, kr.taskType); err != nil {
 			kr.onError(TaskStateDead, err)
 			return
 		}
@@ -241,7 +270,7 @@ func (kr *KafkaRunner) initiateStreaming() error {
 	}
 
 	_, err = kr.natsConn.Subscribe(fmt.Sprintf("%s_full_complete", kr.subject), func(m *gonats.Msg) {
-		if err := kr.natsConn.Publish(m.Reply, nil); err != nil {
+		if err := mysqlDriver.PublishReply(kr.natsConn, m, kr.taskType); err != nil {
 			kr.onError(TaskStateDead, err)
 		}
 	})
@@ -255,20 +284,29 @@ func (kr *KafkaRunner) initiateStreaming() error {
 			kr.onError(TaskStateDead, err)
 		}
 
+		kr.incrAcker.Receive(&binlogEntries)
 		for _, binlogEntry := range binlogEntries.Entries {
-			err = kr.kafkaTransformDMLEventQuery(binlogEntry)
+			if err := kr.produceIncr(binlogEntry); err != nil {
+				kr.onError(TaskStateDead, err)
+				return
+			}
 		}
 
-		if err := kr.natsConn.Publish(m.Reply, nil); err != nil {
+		if err := mysqlDriver.PublishReply(kr.natsConn, m, kr.taskType); err != nil {
 			kr.onError(TaskStateDead, err)
 		}
 		kr.logger.WithFields(logrus.Fields{
 			"nEntries": len(binlogEntries.Entries),
 		}).Debugf("applier. incr. ack-recv. nEntries")
+		// the messages have been sent to kafka. See MySQLDriverConfig.InFlightWindow.
+		if err := mysqlDriver.PublishIncrAck(kr.natsConn, kr.subject, kr.incrAcker.Applied(len(binlogEntries.Entries))); err != nil {
+			kr.logger.Warnf("kafka: failed to ack %v transactions: %v", len(binlogEntries.Entries), err)
+		}
 	})
 	if err != nil {
 		return err
 	}
+	go mysqlDriver.ResendIncrAck(kr.natsConn, kr.subject, kr.incrAcker, kr.shutdownCh, kr.logger)
 
 	return nil
 }
@@ -317,6 +355,12 @@ func (kr *KafkaRunner) onError(state int, err error) {
 
 func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *mysqlDriver.DumpEntry) error {
 	var err error
+	for _, flag := range value.EncryptedColumns {
+		if flag != 0 {
+			return fmt.Errorf("%v.%v has EncryptColumns, which is not supported by a Kafka destination",
+				table.TableSchema, table.TableName)
+		}
+	}
 
 	tableIdent := fmt.Sprintf("%v.%v.%v", kr.kafkaMgr.Cfg.Topic, table.TableSchema, table.TableName)
 	kr.logger.WithFields(logrus.Fields{
@@ -328,7 +372,7 @@ func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *my
 		valuePayload.Source.Version = "0.0.1"
 		valuePayload.Source.Name = kr.kafkaMgr.Cfg.Topic
 		valuePayload.Source.ServerID = 0 // TODO
-		valuePayload.Source.TsSec = 0    // TODO the timestamp in seconds
+		valuePayload.Source.TsSec = time.Now().Unix()
 		valuePayload.Source.Gtid = nil
 		valuePayload.Source.File = ""
 		valuePayload.Source.Pos = 0
@@ -347,6 +391,7 @@ func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *my
 		columnList := table.OriginalTableColumns.ColumnList()
 		valueColDef, keyColDef := kafkaColumnListToColDefs(table.OriginalTableColumns)
 		keySchema := NewKeySchema(tableIdent, keyColDef)
+		rowColValues := make([]interface{}, len(columnList))
 
 		for i, _ := range columnList {
 			var value interface{}
@@ -422,6 +467,7 @@ func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *my
 			if columnList[i].IsPk() {
 				keyPayload.AddField(columnList[i].RawName, value)
 			}
+			rowColValues[i] = value
 
 			kr.logger.WithFields(logrus.Fields{
 				"rowvalue": value,
@@ -429,16 +475,11 @@ func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *my
 			valuePayload.After.AddField(columnList[i].RawName, value)
 		}
 
-		valueSchema := NewEnvelopeSchema(tableIdent, valueColDef)
-
 		k := DbzOutput{
 			Schema:  keySchema,
 			Payload: keyPayload,
 		}
-		v := DbzOutput{
-			Schema:  valueSchema,
-			Payload: valuePayload,
-		}
+		v := kr.newValueOutput(tableIdent, valueColDef, valuePayload)
 
 		kBs, err := json.Marshal(k)
 		if err != nil {
@@ -448,8 +489,13 @@ func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *my
 		if err != nil {
 			return fmt.Errorf("kafka: serialization error: %v", err)
 		}
+		partitionKey, err := BuildPartitionKey(kr.kafkaMgr.Cfg.PartitionKey, table.TableSchema, table.TableName,
+			table.OriginalTableColumns.Names(), rowColValues)
+		if err != nil {
+			return fmt.Errorf("kafka: serialization error: %v", err)
+		}
 		//vBs = []byte(strings.Replace(string(vBs), "\"field\":\"snapshot\"", "\"default\":false,\"field\":\"snapshot\"", -1))
-		err = kr.kafkaMgr.Send(tableIdent, kBs, vBs)
+		err = kr.kafkaMgr.Send(tableIdent, kBs, vBs, partitionKey)
 		if err != nil {
 			return err
 		}
@@ -459,16 +505,39 @@ func (kr *KafkaRunner) kafkaTransformSnapshotData(table *config.Table, value *my
 }
 
 func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry) (err error) {
+	var msgs []*sarama.ProducerMessage
+	// the row within the rows event, of which the rows have the same LogPos
+	row := 0
 	for i, _ := range dmlEvent.Events {
 		dataEvent := &dmlEvent.Events[i]
+		if len(dataEvent.EncryptedColumns) > 0 {
+			return fmt.Errorf("%v.%v has EncryptColumns, which is not supported by a Kafka destination",
+				dataEvent.DatabaseName, dataEvent.TableName)
+		}
+		if i > 0 && dataEvent.LogPos == dmlEvent.Events[i-1].LogPos {
+			row++
+		} else {
+			row = 0
+		}
+		if dataEvent.Savepoint {
+			// A ROLLBACK TO SAVEPOINT is not applied. See BinlogReader.handleTxQuery.
+			continue
+		}
+		if !config.MatchDestDoDb(kr.kafkaMgr.Cfg.DestDoDb, dataEvent.DatabaseName, dataEvent.TableName) {
+			continue
+		}
 		// this must be executed before skipping DDL
 		table, err := kr.getOrSetTable(dataEvent.DatabaseName, dataEvent.TableName, dataEvent.Table)
 		if err != nil {
 			return err
 		}
 
-		// skipping DDL
 		if dataEvent.DML == binlog.NotDML {
+			if ddlMsg, err := kr.newDDLMessage(dmlEvent, dataEvent); err != nil {
+				return err
+			} else if ddlMsg != nil {
+				msgs = append(msgs, ddlMsg)
+			}
 			continue
 		}
 
@@ -496,6 +565,7 @@ func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry)
 		keyPayload := NewRow()
 		colList := table.OriginalTableColumns.ColumnList()
 		colDefs, keyColDefs := kafkaColumnListToColDefs(table.OriginalTableColumns)
+		rowColValues := make([]interface{}, len(colList))
 
 		for i, _ := range colList {
 			colName := colList[i].RawName
@@ -550,13 +620,9 @@ func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry)
 				} else if afterValue != nil {
 					afterValue = DateValue(afterValue.(string))
 				}
-			case mysql.VarbinaryColumnType:
-				if beforeValue != nil {
-					beforeValue = beforeValue.(string)
-				}
-				if afterValue != nil {
-					afterValue = afterValue.(string)
-				}
+			case mysql.VarbinaryColumnType, mysql.BlobColumnType:
+				beforeValue = BinaryValue(beforeValue)
+				afterValue = BinaryValue(afterValue)
 			case mysql.BinaryColumnType:
 
 				if beforeValue != nil {
@@ -581,41 +647,43 @@ func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry)
 					afterValue = afterValue.(float32)
 				}
 			case mysql.EnumColumnType:
+				// already decoded to string by the reader if the column members are known
 				enums := strings.Split(colList[i].ColumnType[5:len(colList[i].ColumnType)-1], ",")
-				if beforeValue != nil {
-					beforeValue = strings.Replace(enums[beforeValue.(int64)-1], "'", "", -1)
+				if v, ok := beforeValue.(int64); ok {
+					beforeValue = strings.Replace(enums[v-1], "'", "", -1)
 				}
-				if afterValue != nil {
-					afterValue = strings.Replace(enums[afterValue.(int64)-1], "'", "", -1)
+				if v, ok := afterValue.(int64); ok {
+					afterValue = strings.Replace(enums[v-1], "'", "", -1)
 				}
 			case mysql.SetColumnType:
 				columnType := colList[i].ColumnType
-				if beforeValue != nil {
-					beforeValue = getSetValue(beforeValue.(int64), columnType)
+				if v, ok := beforeValue.(int64); ok {
+					beforeValue = getSetValue(v, columnType)
 				}
-				if afterValue != nil {
-					afterValue = getSetValue(afterValue.(int64), columnType)
+				if v, ok := afterValue.(int64); ok {
+					afterValue = getSetValue(v, columnType)
 				}
 
 			case mysql.BitColumnType:
 				if beforeValue != nil {
-					beforeValue = getBitValue(colList[i].ColumnType, beforeValue.(int64))
+					beforeValue = getBitValue(colList[i].ColumnType, beforeValue)
 				}
 				if afterValue != nil {
-					afterValue = getBitValue(colList[i].ColumnType, afterValue.(int64))
+					afterValue = getBitValue(colList[i].ColumnType, afterValue)
 				}
 			default:
 				// do nothing
 			}
 
+			if before != nil {
+				// update/delete: use before
+				rowColValues[i] = beforeValue
+			} else {
+				// insert: use after
+				rowColValues[i] = afterValue
+			}
 			if colList[i].IsPk() {
-				if before != nil {
-					// update/delete: use before
-					keyPayload.AddField(colName, beforeValue)
-				} else {
-					// insert: use after
-					keyPayload.AddField(colName, afterValue)
-				}
+				keyPayload.AddField(colName, rowColValues[i])
 			}
 
 			if before != nil {
@@ -638,12 +706,15 @@ func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry)
 
 		valuePayload.Source.Version = "0.0.1"
 		valuePayload.Source.Name = kr.kafkaMgr.Cfg.Topic
-		valuePayload.Source.ServerID = 1 // TODO
-		valuePayload.Source.TsSec = time.Now().Unix()
+		valuePayload.Source.ServerID = int(dmlEvent.Coordinates.ServerID)
+		valuePayload.Source.TsSec = dmlEvent.Coordinates.Timestamp
+		if valuePayload.Source.TsSec == 0 {
+			valuePayload.Source.TsSec = time.Now().Unix()
+		}
 		valuePayload.Source.Gtid = dmlEvent.Coordinates.GetGtidForThisTx()
 		valuePayload.Source.File = dmlEvent.Coordinates.LogFile
 		valuePayload.Source.Pos = dataEvent.LogPos
-		valuePayload.Source.Row = 0          // TODO "the row within the event (if there is more than one)".
+		valuePayload.Source.Row = row
 		valuePayload.Source.Snapshot = false // TODO "whether this event was part of a snapshot"
 
 		valuePayload.Source.Query = nil
@@ -654,17 +725,12 @@ func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry)
 		valuePayload.Op = op
 		valuePayload.TsMs = utils.CurrentTimeMillis()
 
-		valueSchema := NewEnvelopeSchema(tableIdent, colDefs)
-
 		keySchema := NewKeySchema(tableIdent, keyColDefs)
 		k := DbzOutput{
 			Schema:  keySchema,
 			Payload: keyPayload,
 		}
-		v := DbzOutput{
-			Schema:  valueSchema,
-			Payload: valuePayload,
-		}
+		v := kr.newValueOutput(tableIdent, colDefs, valuePayload)
 		kBs, err := json.Marshal(k)
 		if err != nil {
 			return err
@@ -673,32 +739,97 @@ func (kr *KafkaRunner) kafkaTransformDMLEventQuery(dmlEvent *binlog.BinlogEntry)
 		if err != nil {
 			return err
 		}
-		//	vBs = []byte(strings.Replace(string(vBs), "\"field\":\"snapshot\"", "\"default\":false,\"field\":\"snapshot\"", -1))
-		err = kr.kafkaMgr.Send(tableIdent, kBs, vBs)
+		partitionKey, err := BuildPartitionKey(kr.kafkaMgr.Cfg.PartitionKey, table.TableSchema, table.TableName,
+			table.OriginalTableColumns.Names(), rowColValues)
 		if err != nil {
 			return err
 		}
-		kr.logger.Debugf("kafka: sent one msg")
+		//	vBs = []byte(strings.Replace(string(vBs), "\"field\":\"snapshot\"", "\"default\":false,\"field\":\"snapshot\"", -1))
+		tombstoneMode := kr.kafkaMgr.Cfg.EmitDeleteTombstone
+		if dataEvent.DML != binlog.DeleteDML || tombstoneMode != DELETE_TOMBSTONE_ONLY {
+			msgs = append(msgs, NewProducerMessage(tableIdent, kBs, vBs, partitionKey))
+		}
 
-		// tombstone event for DELETE
-		if dataEvent.DML == binlog.DeleteDML {
-			v2 := DbzOutput{
-				Schema:  nil,
-				Payload: nil,
-			}
-			v2Bs, err := json.Marshal(v2)
-			if err != nil {
-				return err
-			}
-			err = kr.kafkaMgr.Send(tableIdent, kBs, v2Bs)
-			if err != nil {
-				return err
-			}
-			kr.logger.Debugf("kafka: sent one msg")
+		// tombstone event for DELETE: the key with a null value, for log compaction
+		if dataEvent.DML == binlog.DeleteDML && tombstoneMode != DELETE_TOMBSTONE_NONE {
+			msgs = append(msgs, NewProducerMessage(tableIdent, kBs, nil, partitionKey))
 		}
 	}
 
-	return nil
+	return kr.sendTx(dmlEvent, msgs)
+}
+
+// newDDLMessage returns the message of a DDL event, or nil if it is not sent. See KafkaConfig.EmitDDL.
+func (kr *KafkaRunner) newDDLMessage(dmlEvent *binlog.BinlogEntry, dataEvent *binlog.DataEvent) (*sarama.ProducerMessage, error) {
+	cfg := kr.kafkaMgr.Cfg
+	if cfg.EmitDDL == "" || cfg.EmitDDL == DDL_MESSAGE_NONE {
+		return nil, nil
+	}
+	topic := cfg.DDLTopic
+	if topic == "" {
+		topic = cfg.Topic
+	}
+	// With PARTITION_KEY_TABLE, an inline DDL is on the partition of the rows of the table, in order.
+	var partitionKey []byte
+	if cfg.EmitDDL == DDL_MESSAGE_INLINE && dataEvent.TableName != "" {
+		topic = fmt.Sprintf("%v.%v.%v", cfg.Topic, dataEvent.DatabaseName, dataEvent.TableName)
+		var err error
+		partitionKey, err = BuildPartitionKey(cfg.PartitionKey, dataEvent.DatabaseName, dataEvent.TableName, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	databaseName := utils.StringElse(dataEvent.DatabaseName, dataEvent.CurrentSchema)
+
+	source := &SourcePayload{
+		Version:  "0.0.1",
+		Name:     cfg.Topic,
+		ServerID: int(dmlEvent.Coordinates.ServerID),
+		TsSec:    dmlEvent.Coordinates.Timestamp,
+		Gtid:     dmlEvent.Coordinates.GetGtidForThisTx(),
+		File:     dmlEvent.Coordinates.LogFile,
+		Pos:      dataEvent.LogPos,
+		Query:    dataEvent.Query,
+		Db:       databaseName,
+		Table:    dataEvent.TableName,
+	}
+	if source.TsSec == 0 {
+		source.TsSec = time.Now().Unix()
+	}
+	payload := &DDLPayload{
+		Source:       source,
+		DatabaseName: databaseName,
+		TableName:    dataEvent.TableName,
+		DDL:          dataEvent.Query,
+		TsMs:         utils.CurrentTimeMillis(),
+	}
+	valueSchema := NewDDLValueSchema(SourceSchema)
+	if cfg.MessageEnvelope == MESSAGE_ENVELOPE_DEBEZIUM {
+		payload.Source = newDebeziumSourcePayload(source, "false")
+		valueSchema = NewDDLValueSchema(DebeziumSourceSchema)
+	}
+
+	kBs, err := json.Marshal(DbzOutput{Schema: DDLKeySchema, Payload: &DDLKeyPayload{DatabaseName: databaseName}})
+	if err != nil {
+		return nil, err
+	}
+	vBs, err := json.Marshal(DbzOutput{Schema: valueSchema, Payload: payload})
+	if err != nil {
+		return nil, err
+	}
+	kr.logger.Debugf("kafka: DDL message to %v: %v", topic, dataEvent.Query)
+	return NewProducerMessage(topic, kBs, vBs, partitionKey), nil
+}
+
+// newValueOutput returns the message value of a row, in the shape of KafkaConfig.MessageEnvelope.
+func (kr *KafkaRunner) newValueOutput(tableIdent string, colDefs ColDefs, payload *ValuePayload) *DbzOutput {
+	if kr.kafkaMgr.Cfg.MessageEnvelope == MESSAGE_ENVELOPE_DEBEZIUM {
+		return DebeziumOutput(tableIdent, colDefs, payload)
+	}
+	return &DbzOutput{
+		Schema:  NewEnvelopeSchema(tableIdent, colDefs),
+		Payload: payload,
+	}
 }
 
 func getSetValue(num int64, set string) string {
@@ -732,7 +863,16 @@ func getBinaryValue(binary string, value string) string {
 	}
 	return base64.StdEncoding.EncodeToString(buffer.Bytes())
 }
-func getBitValue(bit string, value int64) string {
+
+// getBitValue encodes a value of a BIT column, which is a uint64 (an int64 of older entries).
+func getBitValue(bit string, v interface{}) string {
+	var value uint64
+	switch v := v.(type) {
+	case uint64:
+		value = v
+	case int64:
+		value = uint64(v)
+	}
 	bitLen := bit[4 : len(bit)-1]
 	lens, _ := strconv.Atoi(bitLen)
 	bitNumber := lens / 8
@@ -740,7 +880,7 @@ func getBitValue(bit string, value int64) string {
 		bitNumber = bitNumber + 1
 	}
 	var buf = make([]byte, 8)
-	binary.BigEndian.PutUint64(buf, uint64(value))
+	binary.BigEndian.PutUint64(buf, value)
 
 	return base64.StdEncoding.EncodeToString(buf[8-bitNumber:])
 }
@@ -762,12 +902,8 @@ func kafkaColumnListToColDefs(colList *mysql.ColumnList) (valColDefs ColDefs, ke
 
 		case mysql.BitColumnType:
 			field = NewBitsField(optional, fieldName, cols[i].ColumnType[4:len(cols[i].ColumnType)-1], defaultValue)
-		case mysql.BlobColumnType:
-			field = NewSimpleSchemaWithDefaultField(SCHEMA_TYPE_BYTES, optional, fieldName, defaultValue)
-		case mysql.BinaryColumnType:
-			field = NewSimpleSchemaWithDefaultField(SCHEMA_TYPE_BYTES, optional, fieldName, defaultValue)
-		case mysql.VarbinaryColumnType:
-			field = NewSimpleSchemaWithDefaultField(SCHEMA_TYPE_BYTES, optional, fieldName, defaultValue)
+		case mysql.BlobColumnType, mysql.BinaryColumnType, mysql.VarbinaryColumnType:
+			field = NewBinaryField(optional, fieldName, defaultValue)
 		case mysql.TextColumnType:
 			fallthrough
 		case mysql.CharColumnType:
@@ -0,0 +1,620 @@
+package kafka3
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	mysqlDriver "github.com/actiontech/dtle/internal/client/driver/mysql"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// fakeSyncProducer records the messages instead of sending them
+type fakeSyncProducer struct {
+	msgs []*sarama.ProducerMessage
+	// if > 0, sending more messages than this fails, as if the task stopped.
+	limit int
+}
+
+func (p *fakeSyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	if p.limit > 0 && len(p.msgs) >= p.limit {
+		return 0, 0, errors.New("fakeSyncProducer: stopped")
+	}
+	p.msgs = append(p.msgs, msg)
+	return 0, int64(len(p.msgs)), nil
+}
+func (p *fakeSyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+func (p *fakeSyncProducer) Close() error {
+	return nil
+}
+
+func TestKafkaTransformDMLEventQuery_binary(t *testing.T) {
+	columns := mysql.NewColumns([]string{"id", "b", "vb"})
+	columns[0].Type = mysql.IntColumnType
+	columns[0].Key = "PRI"
+	columns[1].Type = mysql.BlobColumnType
+	columns[1].Nullable = true
+	columns[2].Type = mysql.VarbinaryColumnType
+	columns[2].Nullable = true
+	table := config.NewTable("db1", "t1")
+	table.OriginalTableColumns = mysql.NewColumnList(columns)
+
+	// not valid UTF-8, with bytes to be escaped in JSON
+	blobBefore := []byte{0xff, 0xfe, 0x00, '"', '\\', '\n'}
+	blobAfter := []byte{0xc3, 0x28, '}'}
+	varbinary := string([]byte{0x80, 0x81, '{'})
+	entry := &binlog.BinlogEntry{
+		Events: []binlog.DataEvent{{
+			DatabaseName:      "db1",
+			TableName:         "t1",
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: binlog.ToColumnValuesV2([]interface{}{int32(1), blobBefore, nil}, nil),
+			NewColumnValues:   binlog.ToColumnValuesV2([]interface{}{int32(1), blobAfter, varbinary}, nil),
+			Table:             table,
+		}},
+	}
+
+	producer := &fakeSyncProducer{}
+	kr := &KafkaRunner{
+		logger:   logrus.NewEntry(logrus.New()),
+		kafkaMgr: &KafkaManager{Cfg: &KafkaConfig{Topic: "dtle"}, producer: producer},
+		tables:   make(map[string](map[string]*config.Table)),
+	}
+	if err := kr.kafkaTransformDMLEventQuery(entry); err != nil {
+		t.Fatalf("kafkaTransformDMLEventQuery() error = %v", err)
+	}
+	if len(producer.msgs) != 1 {
+		t.Fatalf("sent %v messages, want 1", len(producer.msgs))
+	}
+	bs, err := producer.msgs[0].Value.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(bs) {
+		t.Fatalf("message is not valid JSON: %s", bs)
+	}
+
+	var msg struct {
+		Schema  Schema
+		Payload struct {
+			Before map[string]interface{}
+			After  map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(bs, &msg); err != nil {
+		t.Fatal(err)
+	}
+	decode := func(row map[string]interface{}, col string) []byte {
+		s, ok := row[col].(string)
+		if !ok {
+			t.Fatalf("%v = %v, want a base64 string", col, row[col])
+		}
+		v, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("%v: %v", col, err)
+		}
+		return v
+	}
+	if got := decode(msg.Payload.Before, "b"); !bytes.Equal(got, blobBefore) {
+		t.Errorf("before.b = %v, want %v", got, blobBefore)
+	}
+	if msg.Payload.Before["vb"] != nil {
+		t.Errorf("before.vb = %v, want null", msg.Payload.Before["vb"])
+	}
+	if got := decode(msg.Payload.After, "b"); !bytes.Equal(got, blobAfter) {
+		t.Errorf("after.b = %v, want %v", got, blobAfter)
+	}
+	if got := decode(msg.Payload.After, "vb"); string(got) != varbinary {
+		t.Errorf("after.vb = %v, want %v", got, []byte(varbinary))
+	}
+
+	// the envelope: before, after, ...
+	for _, field := range msg.Schema.Fields[0].Fields[1:] {
+		if field.Type != SCHEMA_TYPE_BYTES || field.Parameters["encoding"] != "base64" {
+			t.Errorf("schema of %v = %+v, want bytes with encoding base64", field.Field, field)
+		}
+	}
+}
+
+func TestKafkaTransformDMLEventQuery_deleteTombstone(t *testing.T) {
+	columns := mysql.NewColumns([]string{"id", "name"})
+	columns[0].Type = mysql.IntColumnType
+	columns[0].Key = "PRI"
+	columns[1].Nullable = true
+	table := config.NewTable("db1", "t1")
+	table.OriginalTableColumns = mysql.NewColumnList(columns)
+	entry := &binlog.BinlogEntry{
+		Events: []binlog.DataEvent{{
+			DatabaseName:      "db1",
+			TableName:         "t1",
+			DML:               binlog.DeleteDML,
+			WhereColumnValues: binlog.ToColumnValuesV2([]interface{}{int32(7), "a"}, nil),
+			Table:             table,
+		}},
+	}
+
+	tests := []struct {
+		mode          string
+		wantEnvelope  bool
+		wantTombstone bool
+	}{
+		{"", true, true},
+		{DELETE_TOMBSTONE_AFTER, true, true},
+		{DELETE_TOMBSTONE_ONLY, false, true},
+		{DELETE_TOMBSTONE_NONE, true, false},
+	}
+	for _, tt := range tests {
+		producer := &fakeSyncProducer{}
+		kr := &KafkaRunner{
+			logger: logrus.NewEntry(logrus.New()),
+			kafkaMgr: &KafkaManager{Cfg: &KafkaConfig{Topic: "dtle", EmitDeleteTombstone: tt.mode},
+				producer: producer},
+			tables: make(map[string](map[string]*config.Table)),
+		}
+		if err := kr.kafkaTransformDMLEventQuery(entry); err != nil {
+			t.Fatalf("%q: kafkaTransformDMLEventQuery() error = %v", tt.mode, err)
+		}
+
+		var envelopes, tombstones []*sarama.ProducerMessage
+		for _, msg := range producer.msgs {
+			if msg.Value == nil {
+				tombstones = append(tombstones, msg)
+			} else {
+				envelopes = append(envelopes, msg)
+			}
+		}
+		if (len(envelopes) == 1) != tt.wantEnvelope || (len(tombstones) == 1) != tt.wantTombstone ||
+			len(producer.msgs) != len(envelopes)+len(tombstones) {
+			t.Fatalf("%q: sent %v envelopes and %v tombstones, want envelope %v, tombstone %v",
+				tt.mode, len(envelopes), len(tombstones), tt.wantEnvelope, tt.wantTombstone)
+		}
+		if tt.wantEnvelope && tt.wantTombstone && producer.msgs[1].Value != nil {
+			t.Errorf("%q: the tombstone is sent before the envelope", tt.mode)
+		}
+		if !tt.wantTombstone {
+			continue
+		}
+
+		tombstone := tombstones[0]
+		if tombstone.Topic != "dtle.db1.t1" {
+			t.Errorf("%q: topic = %v, want dtle.db1.t1", tt.mode, tombstone.Topic)
+		}
+		bs, err := tombstone.Key.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var key struct {
+			Payload map[string]interface{}
+		}
+		if err := json.Unmarshal(bs, &key); err != nil {
+			t.Fatalf("%q: bad key %s: %v", tt.mode, bs, err)
+		}
+		if !reflect.DeepEqual(key.Payload, map[string]interface{}{"id": float64(7)}) {
+			t.Errorf("%q: key payload = %v, want id 7", tt.mode, key.Payload)
+		}
+		if tt.wantEnvelope {
+			envelopeKey, _ := envelopes[0].Key.Encode()
+			if !bytes.Equal(envelopeKey, bs) {
+				t.Errorf("%q: key of the tombstone %s differs from the envelope %s", tt.mode, bs, envelopeKey)
+			}
+		}
+	}
+}
+
+// A change with MessageEnvelope debezium is the same message as produced by Debezium.
+func TestKafkaTransformDMLEventQuery_debezium(t *testing.T) {
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "debezium_update.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want struct {
+		Key   map[string]interface{}
+		Value map[string]interface{}
+	}
+	if err := json.Unmarshal(golden, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	columns := mysql.NewColumns([]string{"id", "first_name", "last_name", "email"})
+	columns[0].Type = mysql.IntColumnType
+	columns[0].Key = "PRI"
+	columns[1].Type = mysql.VarcharColumnType
+	columns[2].Type = mysql.VarcharColumnType
+	columns[3].Type = mysql.VarcharColumnType
+	columns[3].Nullable = true
+	table := config.NewTable("inventory", "customers")
+	table.OriginalTableColumns = mysql.NewColumnList(columns)
+	sid, err := uuid.FromString("d4f1a2b3-0c5e-11e6-a06b-0242ac110002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{
+			LogFile:   "mysql-bin.000003",
+			SID:       sid,
+			GNO:       5,
+			Timestamp: 1465581029,
+			ServerID:  223344,
+		},
+		Events: []binlog.DataEvent{{
+			DatabaseName: "inventory",
+			TableName:    "customers",
+			DML:          binlog.UpdateDML,
+			LogPos:       484,
+			WhereColumnValues: binlog.ToColumnValuesV2(
+				[]interface{}{int32(1004), "Anne", "Kretchmar", "annek@noanswer.org"}, nil),
+			NewColumnValues: binlog.ToColumnValuesV2(
+				[]interface{}{int32(1004), "Anne Marie", "Kretchmar", "annek@noanswer.org"}, nil),
+			Table: table,
+		}},
+	}
+
+	producer := &fakeSyncProducer{}
+	kr := &KafkaRunner{
+		logger: logrus.NewEntry(logrus.New()),
+		kafkaMgr: &KafkaManager{Cfg: &KafkaConfig{Topic: "dbserver1", MessageEnvelope: MESSAGE_ENVELOPE_DEBEZIUM},
+			producer: producer},
+		tables: make(map[string](map[string]*config.Table)),
+	}
+	if err := kr.kafkaTransformDMLEventQuery(entry); err != nil {
+		t.Fatalf("kafkaTransformDMLEventQuery() error = %v", err)
+	}
+	if len(producer.msgs) != 1 {
+		t.Fatalf("sent %v messages, want 1", len(producer.msgs))
+	}
+	decode := func(encoder sarama.Encoder) map[string]interface{} {
+		bs, err := encoder.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(bs, &m); err != nil {
+			t.Fatalf("bad message %s: %v", bs, err)
+		}
+		return m
+	}
+	key, value := decode(producer.msgs[0].Key), decode(producer.msgs[0].Value)
+	// the time of the processing
+	value["payload"].(map[string]interface{})["ts_ms"] = want.Value["payload"].(map[string]interface{})["ts_ms"]
+	if !reflect.DeepEqual(key, want.Key) {
+		t.Errorf("key:\n%v\nwant:\n%v", key, want.Key)
+	}
+	if !reflect.DeepEqual(value["schema"], want.Value["schema"]) {
+		t.Errorf("value schema:\n%v\nwant:\n%v", value["schema"], want.Value["schema"])
+	}
+	if !reflect.DeepEqual(value["payload"], want.Value["payload"]) {
+		t.Errorf("value payload:\n%v\nwant:\n%v", value["payload"], want.Value["payload"])
+	}
+
+	// the full copy: reads of a snapshot
+	producer.msgs = nil
+	id, name, email := []byte("1001"), []byte("Sally"), []byte("sally@example.com")
+	dumpEntry := &mysqlDriver.DumpEntry{
+		TableSchema: "inventory",
+		TableName:   "customers",
+		ValuesX:     [][]*[]byte{{&id, &name, &name, &email}},
+	}
+	if err := kr.kafkaTransformSnapshotData(table, dumpEntry); err != nil {
+		t.Fatalf("kafkaTransformSnapshotData() error = %v", err)
+	}
+	if len(producer.msgs) != 1 {
+		t.Fatalf("sent %v messages of the full copy, want 1", len(producer.msgs))
+	}
+	payload := decode(producer.msgs[0].Value)["payload"].(map[string]interface{})
+	if payload["op"] != RECORD_OP_READ || payload["source"].(map[string]interface{})["snapshot"] != "true" {
+		t.Errorf("op of the full copy = %v, snapshot %v, want r and true",
+			payload["op"], payload["source"].(map[string]interface{})["snapshot"])
+	}
+}
+
+// A DDL is sent as a message with the statement, the schema and table, and the GTID. See KafkaConfig.EmitDDL.
+func TestKafkaTransformDMLEventQuery_ddl(t *testing.T) {
+	columns := mysql.NewColumns([]string{"id"})
+	columns[0].Type = mysql.IntColumnType
+	columns[0].Key = "PRI"
+	table := config.NewTable("db1", "t1")
+	table.OriginalTableColumns = mysql.NewColumnList(columns)
+	sid, err := uuid.FromString("d4f1a2b3-0c5e-11e6-a06b-0242ac110002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	insert := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: 4},
+		Events: []binlog.DataEvent{{
+			DatabaseName:    "db1",
+			TableName:       "t1",
+			DML:             binlog.InsertDML,
+			NewColumnValues: binlog.ToColumnValuesV2([]interface{}{int32(1)}, nil),
+			Table:           table,
+		}},
+	}
+	const ddl = "alter table t1 add column c int"
+	alter := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{LogFile: "mysql-bin.000002", SID: sid, GNO: 5, ServerID: 1},
+		Events: []binlog.DataEvent{
+			binlog.NewQueryEventAffectTable("db1", ddl, binlog.NotDML, binlog.SchemaTable{Schema: "db1", Table: "t1"}),
+		},
+	}
+
+	tests := []struct {
+		name             string
+		cfg              KafkaConfig
+		wantTopic        string
+		wantPartitionKey string
+	}{
+		{"none", KafkaConfig{Topic: "dtle"}, "", ""},
+		{"topic", KafkaConfig{Topic: "dtle", EmitDDL: DDL_MESSAGE_TOPIC}, "dtle", ""},
+		{"ddl topic", KafkaConfig{Topic: "dtle", EmitDDL: DDL_MESSAGE_TOPIC, DDLTopic: "dtle_ddl"}, "dtle_ddl", ""},
+		{"inline", KafkaConfig{Topic: "dtle", EmitDDL: DDL_MESSAGE_INLINE, PartitionKey: []string{PARTITION_KEY_TABLE}},
+			"dtle.db1.t1", "db1.t1"},
+		{"debezium", KafkaConfig{Topic: "dtle", EmitDDL: DDL_MESSAGE_TOPIC, MessageEnvelope: MESSAGE_ENVELOPE_DEBEZIUM},
+			"dtle", ""},
+	}
+	for _, tt := range tests {
+		producer := &fakeSyncProducer{}
+		cfg := tt.cfg
+		kr := &KafkaRunner{
+			logger:   logrus.NewEntry(logrus.New()),
+			kafkaMgr: &KafkaManager{Cfg: &cfg, producer: producer},
+			tables:   make(map[string](map[string]*config.Table)),
+		}
+		for _, entry := range []*binlog.BinlogEntry{insert, alter} {
+			if err := kr.kafkaTransformDMLEventQuery(entry); err != nil {
+				t.Fatalf("%v: kafkaTransformDMLEventQuery() error = %v", tt.name, err)
+			}
+		}
+		if tt.wantTopic == "" {
+			if len(producer.msgs) != 1 {
+				t.Errorf("%v: sent %v messages, want the row only", tt.name, len(producer.msgs))
+			}
+			continue
+		}
+		if len(producer.msgs) != 2 {
+			t.Fatalf("%v: sent %v messages, want the row and the DDL", tt.name, len(producer.msgs))
+		}
+
+		msg := producer.msgs[1]
+		if msg.Topic != tt.wantTopic {
+			t.Errorf("%v: topic = %v, want %v", tt.name, msg.Topic, tt.wantTopic)
+		}
+		partitionKey, _ := msg.Metadata.([]byte)
+		if string(partitionKey) != tt.wantPartitionKey {
+			t.Errorf("%v: partition key = %s, want %v", tt.name, partitionKey, tt.wantPartitionKey)
+		}
+		var key, value struct {
+			Schema  struct{ Name string }
+			Payload struct {
+				DatabaseName string
+				TableName    string
+				DDL          string
+				Source       struct {
+					Gtid string
+					File string
+					Db   string
+				}
+			}
+		}
+		for _, kv := range []struct {
+			encoder sarama.Encoder
+			v       interface{}
+		}{{msg.Key, &key}, {msg.Value, &value}} {
+			bs, err := kv.encoder.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(bs, kv.v); err != nil {
+				t.Fatalf("%v: bad message %s: %v", tt.name, bs, err)
+			}
+		}
+		if key.Payload.DatabaseName != "db1" {
+			t.Errorf("%v: key databaseName = %v, want db1", tt.name, key.Payload.DatabaseName)
+		}
+		if value.Schema.Name != "io.debezium.connector.mysql.SchemaChangeValue" {
+			t.Errorf("%v: schema name = %v", tt.name, value.Schema.Name)
+		}
+		payload := value.Payload
+		if payload.DDL != ddl || payload.DatabaseName != "db1" || payload.TableName != "t1" {
+			t.Errorf("%v: ddl = %q on %v.%v, want %q on db1.t1", tt.name,
+				payload.DDL, payload.DatabaseName, payload.TableName, ddl)
+		}
+		if payload.Source.Gtid != "d4f1a2b3-0c5e-11e6-a06b-0242ac110002:5" || payload.Source.File != "mysql-bin.000002" ||
+			payload.Source.Db != "db1" {
+			t.Errorf("%v: source = %+v, want the GTID and position of the DDL", tt.name, payload.Source)
+		}
+	}
+}
+
+func TestKafkaConfig_Validate(t *testing.T) {
+	for _, mode := range []string{"", DELETE_TOMBSTONE_AFTER, DELETE_TOMBSTONE_ONLY, DELETE_TOMBSTONE_NONE} {
+		if err := (&KafkaConfig{EmitDeleteTombstone: mode}).Validate(); err != nil {
+			t.Errorf("Validate() with %q error = %v", mode, err)
+		}
+	}
+	if err := (&KafkaConfig{EmitDeleteTombstone: "true"}).Validate(); err == nil {
+		t.Error("Validate() with \"true\" want an error")
+	}
+
+	tests := []struct {
+		name    string
+		cfg     KafkaConfig
+		wantErr bool
+	}{
+		{"tls", KafkaConfig{TLS: &KafkaTLSConfig{CaFile: "ca.pem"}}, false},
+		{"tls client cert", KafkaConfig{TLS: &KafkaTLSConfig{CertFile: "c.pem", KeyFile: "k.pem"}}, false},
+		{"tls cert without key", KafkaConfig{TLS: &KafkaTLSConfig{CertFile: "c.pem"}}, true},
+		{"sasl", KafkaConfig{SASL: &KafkaSASLConfig{User: "u", Password: "p"}}, false},
+		{"sasl plain", KafkaConfig{SASL: &KafkaSASLConfig{Mechanism: SASL_MECHANISM_PLAIN, User: "u", Password: "p"}}, false},
+		{"sasl scram-sha-256", KafkaConfig{SASL: &KafkaSASLConfig{Mechanism: SASL_MECHANISM_SCRAM_SHA_256, User: "u", Password: "p"}}, false},
+		{"sasl scram-sha-512", KafkaConfig{SASL: &KafkaSASLConfig{Mechanism: SASL_MECHANISM_SCRAM_SHA_512, User: "u", Password: "p"}}, false},
+		{"sasl scram without password", KafkaConfig{SASL: &KafkaSASLConfig{Mechanism: SASL_MECHANISM_SCRAM_SHA_512, User: "u"}}, true},
+		{"sasl bad mechanism", KafkaConfig{SASL: &KafkaSASLConfig{Mechanism: "GSSAPI", User: "u", Password: "p"}}, true},
+		{"sasl without password", KafkaConfig{SASL: &KafkaSASLConfig{User: "u"}}, true},
+		{"debezium envelope", KafkaConfig{MessageEnvelope: MESSAGE_ENVELOPE_DEBEZIUM}, false},
+		{"bad envelope", KafkaConfig{MessageEnvelope: "canal"}, true},
+		{"ddl inline", KafkaConfig{EmitDDL: DDL_MESSAGE_INLINE}, false},
+		{"bad ddl", KafkaConfig{EmitDDL: "true"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%v: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// The runner is restarted from a checkpoint behind what it has produced, after stopping in the middle
+// of a transaction. The transactions already produced are not produced again.
+func TestKafkaRunner_resumeFromKafka(t *testing.T) {
+	columns := mysql.NewColumns([]string{"id"})
+	columns[0].Type = mysql.IntColumnType
+	columns[0].Key = "PRI"
+	table := config.NewTable("db1", "t1")
+	table.OriginalTableColumns = mysql.NewColumnList(columns)
+	sid := uuid.Must(uuid.FromString("3e11fa47-71ca-11e1-9e33-c80aa9429562"))
+	tx := func(gno int64, ids ...int32) *binlog.BinlogEntry {
+		entry := &binlog.BinlogEntry{Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: gno}}
+		for _, id := range ids {
+			entry.Events = append(entry.Events, binlog.DataEvent{
+				DatabaseName:    "db1",
+				TableName:       "t1",
+				DML:             binlog.InsertDML,
+				NewColumnValues: binlog.ToColumnValuesV2([]interface{}{id}, nil),
+				Table:           table,
+			})
+		}
+		return entry
+	}
+	// a big transaction in two pieces
+	piece0, piece1 := tx(4, 5), tx(4, 6)
+	piece0.Partial, piece1.Index = true, 1
+	// sent again from the checkpoint of the job on each start
+	entries := []*binlog.BinlogEntry{tx(1, 1), tx(2, 2, 3), tx(3, 4), piece0, piece1}
+
+	// start makes a runner which has produced msgs, of which the last one is read from the topic.
+	start := func(produced []*sarama.ProducerMessage, limit int) (*KafkaRunner, *fakeSyncProducer) {
+		producer := &fakeSyncProducer{limit: limit}
+		cfg := &KafkaConfig{Topic: "dtle", ResumeFromKafka: true}
+		kr := &KafkaRunner{
+			logger:      logrus.NewEntry(logrus.New()),
+			kafkaConfig: cfg,
+			kafkaMgr:    &KafkaManager{Cfg: cfg, producer: producer},
+			tables:      make(map[string](map[string]*config.Table)),
+		}
+		var headers [][]*sarama.RecordHeader
+		if len(produced) > 0 {
+			last := produced[len(produced)-1]
+			headers = append(headers, nil)
+			for i := range last.Headers {
+				headers[0] = append(headers[0], &last.Headers[i])
+			}
+		}
+		gtidExecuted, err := gtidExecutedOfHeaders(headers)
+		if err != nil {
+			t.Fatalf("gtidExecutedOfHeaders() error = %v", err)
+		}
+		if err := kr.initGtidExecuted(gtidExecuted); err != nil {
+			t.Fatalf("initGtidExecuted() error = %v", err)
+		}
+		return kr, producer
+	}
+	produce := func(kr *KafkaRunner) error {
+		for _, entry := range entries {
+			if err := kr.produceIncr(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	ids := func(msgs []*sarama.ProducerMessage) (ids []float64) {
+		for _, msg := range msgs {
+			bs, _ := msg.Key.Encode()
+			var key struct {
+				Payload map[string]interface{}
+			}
+			if err := json.Unmarshal(bs, &key); err != nil {
+				t.Fatalf("bad key %s: %v", bs, err)
+			}
+			ids = append(ids, key.Payload["id"].(float64))
+		}
+		return ids
+	}
+
+	// stopped after the first row of gno 2
+	kr, producer := start(nil, 2)
+	if err := produce(kr); err == nil {
+		t.Fatalf("produce() error = nil, want the producer stopped")
+	}
+	if got, want := ids(producer.msgs), []float64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("produced %v, want %v", got, want)
+	}
+	produced := producer.msgs
+
+	// gno 1 is not sent again. gno 2 is sent again as a whole.
+	kr, producer = start(produced, 0)
+	if err := produce(kr); err != nil {
+		t.Fatalf("produce() error = %v", err)
+	}
+	if got, want := ids(producer.msgs), []float64{2, 3, 4, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("produced after restart %v, want %v", got, want)
+	}
+	wantHeaders := []string{sid.String() + ":1", sid.String() + ":1-2", sid.String() + ":1-3", sid.String() + ":1-3",
+		sid.String() + ":1-4"}
+	for i, msg := range producer.msgs {
+		if len(msg.Headers) != 1 || string(msg.Headers[0].Key) != GTID_EXECUTED_HEADER ||
+			string(msg.Headers[0].Value) != wantHeaders[i] {
+			t.Errorf("headers of message %v = %v, want %v", i, msg.Headers, wantHeaders[i])
+		}
+	}
+	if kr.kafkaConfig.Gtid != sid.String()+":1-4" {
+		t.Errorf("Gtid = %v, want %v:1-4", kr.kafkaConfig.Gtid, sid)
+	}
+	produced = append(produced, producer.msgs...)
+
+	// all produced
+	kr, producer = start(produced, 0)
+	if err := produce(kr); err != nil {
+		t.Fatalf("produce() error = %v", err)
+	}
+	if len(producer.msgs) != 0 {
+		t.Errorf("produced %v after all transactions, want none", ids(producer.msgs))
+	}
+}
+
+func TestGtidExecutedOfHeaders(t *testing.T) {
+	header := func(value string) *sarama.RecordHeader {
+		return &sarama.RecordHeader{Key: []byte(GTID_EXECUTED_HEADER), Value: []byte(value)}
+	}
+	set, err := gtidExecutedOfHeaders([][]*sarama.RecordHeader{
+		{header("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5")},
+		nil, // a message of the full copy
+		{{Key: []byte("other"), Value: []byte("x")}, header("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-7")},
+		{header("4e11fa47-71ca-11e1-9e33-c80aa9429562:1-2")},
+	})
+	if err != nil {
+		t.Fatalf("gtidExecutedOfHeaders() error = %v", err)
+	}
+	want, err := base.ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-7,4e11fa47-71ca-11e1-9e33-c80aa9429562:1-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.Equal(want) {
+		t.Errorf("gtidExecutedOfHeaders() = %v, want %v", set, want)
+	}
+	if _, err := gtidExecutedOfHeaders([][]*sarama.RecordHeader{{header("bad")}}); err == nil {
+		t.Errorf("gtidExecutedOfHeaders() with a bad header: want an error")
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package kafka3
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	gomysql "github.com/siddontang/go-mysql/mysql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+)
+
+// With ResumeFromKafka, each message of the incremental copy carries the GTID set produced so far
+// in GTID_EXECUTED_HEADER. The set includes a transaction only at its last message, so a restart
+// after a part of the messages of a transaction sends the whole transaction again. The resent
+// messages have the same keys, which makes a keyed (log-compacted) topic end up with the same rows.
+// The messages of a transaction are not produced in a Kafka transaction: sarama v1.22.1 has no transactional
+// producer (it arrives in v1.37, whose dependencies are far newer than this tree). So the first part of a
+// resent transaction stays visible to read_committed consumers, which are to deduplicate by the key or by
+// GTID_EXECUTED_HEADER. This is at-least-once delivery, not exactly-once.
+
+const readLastMessageTimeout = 10 * time.Second
+
+// ReadGtidExecuted reads the GTID set produced so far from the last messages of the topics of the job,
+// i.e. those named with the prefix "Topic.". It is empty if no message carries GTID_EXECUTED_HEADER.
+func (k *KafkaManager) ReadGtidExecuted() (*gomysql.MysqlGTIDSet, error) {
+	client, err := sarama.NewClient(k.Cfg.Brokers, k.config)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	topics, err := client.Topics()
+	if err != nil {
+		return nil, err
+	}
+	var headers [][]*sarama.RecordHeader
+	for _, topic := range topics {
+		if !strings.HasPrefix(topic, k.Cfg.Topic+".") {
+			continue
+		}
+		partitions, err := client.Partitions(topic)
+		if err != nil {
+			return nil, err
+		}
+		for _, partition := range partitions {
+			oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+			if err != nil {
+				return nil, err
+			}
+			newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return nil, err
+			}
+			if newest <= oldest {
+				continue // empty
+			}
+			msg, err := readMessage(consumer, topic, partition, newest-1)
+			if err != nil {
+				return nil, err
+			}
+			headers = append(headers, msg.Headers)
+		}
+	}
+	return gtidExecutedOfHeaders(headers)
+}
+
+func readMessage(consumer sarama.Consumer, topic string, partition int32, offset int64) (*sarama.ConsumerMessage, error) {
+	pc, err := consumer.ConsumePartition(topic, partition, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer pc.Close()
+	select {
+	case msg := <-pc.Messages():
+		return msg, nil
+	case err := <-pc.Errors():
+		return nil, err
+	case <-time.After(readLastMessageTimeout):
+		return nil, fmt.Errorf("timeout reading the message of %v/%v at offset %v", topic, partition, offset)
+	}
+}
+
+// gtidExecutedOfHeaders returns the union of the GTID sets in the headers of messages.
+func gtidExecutedOfHeaders(headers [][]*sarama.RecordHeader) (*gomysql.MysqlGTIDSet, error) {
+	result := &gomysql.MysqlGTIDSet{Sets: make(map[string]*gomysql.UUIDSet)}
+	for _, msgHeaders := range headers {
+		for _, header := range msgHeaders {
+			if header == nil || string(header.Key) != GTID_EXECUTED_HEADER {
+				continue
+			}
+			set, err := base.ParseGtidSet(string(header.Value))
+			if err != nil {
+				return nil, fmt.Errorf("bad %v header %v: %v", GTID_EXECUTED_HEADER, string(header.Value), err)
+			}
+			for _, uuidSet := range set.Sets {
+				result.AddSet(uuidSet.Clone())
+			}
+		}
+	}
+	return result, nil
+}
+
+// initGtidExecuted sets the GTID set produced so far, which is the union of Gtid of the task and produced,
+// read from Kafka.
+func (kr *KafkaRunner) initGtidExecuted(produced *gomysql.MysqlGTIDSet) error {
+	gtidExecuted, err := base.ParseGtidSet(kr.kafkaConfig.Gtid)
+	if err != nil {
+		return err
+	}
+	for _, uuidSet := range produced.Sets {
+		gtidExecuted.AddSet(uuidSet.Clone())
+	}
+	kr.gtidExecuted = gtidExecuted
+	kr.kafkaConfig.Gtid = base.GtidSetString(gtidExecuted)
+	kr.logger.WithFields(logrus.Fields{
+		"gtid": kr.kafkaConfig.Gtid,
+	}).Infof("kafka: resuming from the produced GTID set")
+	return nil
+}
+
+// isProduced tells whether all the messages of the transaction have been produced.
+func (kr *KafkaRunner) isProduced(entry *binlog.BinlogEntry) bool {
+	uuidSet, ok := kr.gtidExecuted.Sets[entry.Coordinates.SID.String()]
+	return ok && uuidSet.Contain(txUUIDSet(entry))
+}
+
+func txUUIDSet(entry *binlog.BinlogEntry) *gomysql.UUIDSet {
+	return &gomysql.UUIDSet{
+		SID:       entry.Coordinates.SID,
+		Intervals: gomysql.IntervalSlice{{Start: entry.Coordinates.GNO, Stop: entry.Coordinates.GNO + 1}},
+	}
+}
+
+// produceIncr sends the messages of a transaction of the incremental copy, unless it has been produced.
+func (kr *KafkaRunner) produceIncr(entry *binlog.BinlogEntry) error {
+	if entry.Resync != nil {
+		// See POST /v1/job/<ID>/resync, which refuses a job with a Kafka destination.
+		kr.logger.Warnf("kafka: skip a part of the resync of %v.%v", entry.Resync.TableSchema, entry.Resync.TableName)
+		return nil
+	}
+	if kr.kafkaMgr.Cfg.ResumeFromKafka && kr.isProduced(entry) {
+		kr.logger.Debugf("kafka: skip a produced tx: %v", entry.Coordinates.GetGtidForThisTx())
+		return nil
+	}
+	return kr.kafkaTransformDMLEventQuery(entry)
+}
+
+// sendTx sends the messages of a transaction (or a piece of it, see BinlogEntry.Partial).
+// With ResumeFromKafka, it adds GTID_EXECUTED_HEADER to the messages and marks the transaction as produced.
+func (kr *KafkaRunner) sendTx(entry *binlog.BinlogEntry, msgs []*sarama.ProducerMessage) error {
+	if !kr.kafkaMgr.Cfg.ResumeFromKafka {
+		for _, msg := range msgs {
+			if err := kr.kafkaMgr.SendMessage(msg); err != nil {
+				return err
+			}
+			kr.logger.Debugf("kafka: sent one msg")
+		}
+		return nil
+	}
+
+	before := base.GtidSetString(kr.gtidExecuted)
+	after := before
+	if !entry.Partial {
+		kr.gtidExecuted.AddSet(txUUIDSet(entry))
+		after = base.GtidSetString(kr.gtidExecuted)
+	}
+	for i, msg := range msgs {
+		value := before
+		if i == len(msgs)-1 {
+			value = after
+		}
+		msg.Headers = []sarama.RecordHeader{{Key: []byte(GTID_EXECUTED_HEADER), Value: []byte(value)}}
+		if err := kr.kafkaMgr.SendMessage(msg); err != nil {
+			return err
+		}
+		kr.logger.Debugf("kafka: sent one msg")
+	}
+	// reported as the position of the task. See ID.
+	kr.kafkaMgr.Cfg.Gtid = after
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/sirupsen/logrus"
 
 	"github.com/actiontech/dtle/internal/client/driver/mysql"
 	ubase "github.com/actiontech/dtle/internal/client/driver/mysql/base"
@@ -31,6 +32,15 @@ func NewMySQLDriver(ctx *DriverContext) Driver {
 	return &MySQLDriver{DriverContext: *ctx}
 }
 
+// ValidateConfig implements ConfigValidator.
+func (m *MySQLDriver) ValidateConfig(task *models.Task) error {
+	var driverConfig config.MySQLDriverConfig
+	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
+		return err
+	}
+	return mysql.ValidateConfig(task.Type, &driverConfig)
+}
+
 // Validate is used to validate the driver configuration
 func (m *MySQLDriver) Validate(task *models.Task) (*models.TaskValidateResponse, error) {
 	var driverConfig config.MySQLDriverConfig
@@ -38,6 +48,11 @@ func (m *MySQLDriver) Validate(task *models.Task) (*models.TaskValidateResponse,
 	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
 		return reply, err
 	}
+	tunnel, err := usql.NewTunnel(driverConfig.ConnectionConfig, logrus.NewEntry(m.logger))
+	if err != nil {
+		return reply, err
+	}
+	defer tunnel.Close()
 	uri := driverConfig.ConnectionConfig.GetDBUri()
 	db, err := usql.CreateDB(uri)
 	if err != nil {
@@ -125,6 +140,17 @@ func (m *MySQLDriver) Validate(task *models.Task) (*models.TaskValidateResponse,
 			reply.Binlog.Success = true
 		}
 
+		replicaStatus, err := ubase.GetReplicaStatus(db)
+		if err != nil {
+			reply.LogSlaveUpdates.Success = false
+			reply.LogSlaveUpdates.Error = err.Error()
+		} else if err := replicaStatus.ValidateAsBinlogSource(); err != nil {
+			reply.LogSlaveUpdates.Success = false
+			reply.LogSlaveUpdates.Error = err.Error()
+		} else {
+			reply.LogSlaveUpdates.Success = true
+		}
+
 		query = `show grants for current_user()`
 		foundAll := false
 		foundSuper := false
@@ -229,8 +255,8 @@ func (m *MySQLDriver) Start(ctx *common.ExecContext, task *models.Task) (DriverH
 		return nil, err
 	}
 
-	switch task.Type {
-	case models.TaskTypeSrc:
+	switch {
+	case task.Type == models.TaskTypeSrc:
 		{
 			m.logger.Debugf("NewExtractor ReplicateDoDb: %v", driverConfig.ReplicateDoDb)
 			// Create the extractor
@@ -241,7 +267,7 @@ func (m *MySQLDriver) Start(ctx *common.ExecContext, task *models.Task) (DriverH
 			go e.Run()
 			return e, nil
 		}
-	case models.TaskTypeDest:
+	case models.IsDestTaskType(task.Type):
 		{
 			m.logger.Debugf("NewApplier ReplicateDoDb: %v", driverConfig.ReplicateDoDb)
 			a, err := mysql.NewApplier(ctx, &driverConfig, m.logger)
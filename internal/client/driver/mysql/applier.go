@@ -37,6 +37,7 @@ import (
 	"context"
 	"encoding/hex"
 	"os"
+	"path"
 
 	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
@@ -54,6 +55,12 @@ import (
 const (
 	cleanupGtidExecutedLimit = 4096
 	pingInterval             = 10 * time.Second
+
+	// connections of the pool not held by workers. See MaxOpenConns.
+	minSpareConns = 2
+	// a worker connection idle for longer is pinged before use
+	connValidateIdleTime = 5 * time.Second
+	connPingTimeout      = 5 * time.Second
 )
 const (
 	TaskStateComplete int = iota
@@ -83,6 +90,23 @@ type applierTableItem struct {
 	psInsert []*gosql.Stmt
 	psDelete []*gosql.Stmt
 	psUpdate []*gosql.Stmt
+	// the connection of each worker on which its statements are prepared
+	conns []*gosql.Conn
+	// destination-only columns with their values in ColumnDefault. Not in columns.
+	columnExprs []*sql.ColumnExpr
+	// the destination table has no primary key. See MySQLDriverConfig.AllowKeylessTables.
+	keyless bool
+	// the source columns, as of the last definition received with the rows. Kept on Reset.
+	sourceColumns *umconf.ColumnList
+	// the index of the value of each of columns in the source rows, if not in order. See columnValueIndexes.
+	valueIndexes []int
+	// whether each of columns is a date one, if ZeroDatePolicy replaces invalid dates. See zeroDateColumns.
+	dateColumns []bool
+	// the rows are routed to the shard tables, with the index of the column in columns. See routeShardEvents.
+	shardBy     *config.ShardBy
+	shardColumn int
+	// the items of the shard tables, by shard. See shardItem.
+	shards []*applierTableItem
 }
 
 func newApplierTableItem(parallelWorkers int) *applierTableItem {
@@ -91,7 +115,22 @@ func newApplierTableItem(parallelWorkers int) *applierTableItem {
 		psInsert: make([]*gosql.Stmt, parallelWorkers),
 		psDelete: make([]*gosql.Stmt, parallelWorkers),
 		psUpdate: make([]*gosql.Stmt, parallelWorkers),
+		conns:    make([]*gosql.Conn, parallelWorkers),
+	}
+}
+
+// checkConn discards the statements of the worker if its connection has been replaced.
+func (ait *applierTableItem) checkConn(workerIdx int, conn *gosql.Conn) {
+	if ait.conns[workerIdx] == conn {
+		return
+	}
+	for _, stmts := range [][]*gosql.Stmt{ait.psInsert, ait.psDelete, ait.psUpdate} {
+		if stmts[workerIdx] != nil {
+			stmts[workerIdx].Close()
+			stmts[workerIdx] = nil
+		}
 	}
+	ait.conns[workerIdx] = conn
 }
 func (ait *applierTableItem) Reset() {
 	// TODO handle err of `.Close()`?
@@ -108,6 +147,12 @@ func (ait *applierTableItem) Reset() {
 	closeStmts(ait.psUpdate)
 
 	ait.columns = nil
+	ait.columnExprs = nil
+	ait.keyless = false
+	ait.valueIndexes = nil
+	for _, shard := range ait.shards {
+		shard.Reset()
+	}
 }
 
 type mapSchemaTableItems map[string](map[string](*applierTableItem))
@@ -208,8 +253,91 @@ func (mm *MtsManager) Executed(binlogEntry *binlog.BinlogEntry) {
 	mm.chExecuted <- binlogEntry.Coordinates.SeqenceNumber
 }
 
+// applyBatch groups source transactions into one destination transaction.
+// A batch always holds whole source transactions, so the gtid_executed rows
+// committed with it never describe a partially applied transaction.
+type applyBatch struct {
+	tx      applyTx
+	entries []*binlog.BinlogEntry
+	nRows   int
+	begin   time.Time
+	// when the last transaction of the worker ended. See validateConn.
+	idleSince time.Time
+	// the big transaction being applied in pieces, and the index of its next piece. See BigTxSplittingSize.
+	bigTxGtid string
+	nextPiece int
+}
+
+func (b *applyBatch) isPending() bool {
+	return b.tx != nil
+}
+
+// isFull returns true if the batch should be committed, that is, it holds at least
+// batchSize rows or has been open for at least timeout.
+func (b *applyBatch) isFull(batchSize int, timeout time.Duration, now time.Time) bool {
+	if !b.isPending() {
+		return false
+	}
+	return b.nRows >= batchSize || now.Sub(b.begin) >= timeout
+}
+
+// remaining returns the time left before the batch reaches timeout.
+func (b *applyBatch) remaining(timeout time.Duration, now time.Time) time.Duration {
+	d := timeout - now.Sub(b.begin)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+func (b *applyBatch) add(binlogEntry *binlog.BinlogEntry) {
+	b.entries = append(b.entries, binlogEntry)
+	for i := range binlogEntry.Events {
+		if binlogEntry.Events[i].DML != binlog.NotDML {
+			b.nRows += 1
+		}
+	}
+}
+
+func (b *applyBatch) reset() {
+	b.tx = nil
+	b.entries = nil
+	b.nRows = 0
+	b.idleSince = time.Now()
+	b.bigTxGtid = ""
+	b.nextPiece = 0
+}
+
+// inBigTx tells whether a big transaction has been applied in part, and the batch must not be committed.
+func (b *applyBatch) inBigTx() bool {
+	return b.bigTxGtid != ""
+}
+
+// checkPiece checks the order of the pieces of a big transaction. skip is true for a piece which has been
+// applied, e.g. of a message sent again.
+func (b *applyBatch) checkPiece(binlogEntry *binlog.BinlogEntry) (skip bool, err error) {
+	gtid := binlogEntry.Coordinates.GetGtidForThisTx()
+	if !b.inBigTx() {
+		if binlogEntry.Index > 0 {
+			return false, fmt.Errorf("got piece %v of big transaction %v without the previous ones", binlogEntry.Index, gtid)
+		}
+		return false, nil
+	}
+	switch {
+	case gtid != b.bigTxGtid:
+		return false, fmt.Errorf("got transaction %v while big transaction %v is incomplete", gtid, b.bigTxGtid)
+	case binlogEntry.Index < b.nextPiece:
+		return true, nil
+	case binlogEntry.Index > b.nextPiece:
+		return false, fmt.Errorf("got piece %v of big transaction %v. expect %v", binlogEntry.Index, gtid, b.nextPiece)
+	default:
+		return false, nil
+	}
+}
+
 type Applier struct {
 	logger             *logrus.Entry
+	execCtx            *common.ExecContext
 	subject            string
 	subjectUUID        uuid.UUID
 	mysqlContext       *config.MySQLDriverConfig
@@ -235,6 +363,12 @@ type Applier struct {
 	waitCh   chan *models.WaitResult
 	wg       sync.WaitGroup
 
+	// subscriptions of the full and incremental data, with the pending limits. See subscribeData.
+	natsDataSubs         []*gonats.Subscription
+	natsDataSubsLock     sync.Mutex
+	subPendingMsgsLimit  int
+	subPendingBytesLimit int
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -247,13 +381,54 @@ type Applier struct {
 	stubFullApplyDelay time.Duration
 
 	gtidSet *gomysql.MysqlGTIDSet
+	// protects gtidSet and the reported coordinates, which are updated by the committing worker.
+	gtidSetLock sync.Mutex
+
+	// one per worker. see applyBatch.
+	applyBatches      []*applyBatch
+	applyBatchTimeout time.Duration
+
+	// set if OnApplyError=deadletter
+	deadLetter *deadLetter
+
+	replicationLag replicationLag
+	// ApplyEventRateLimit. adjustable while running. See UpdateConfig.
+	eventRateLimiter *eventRateLimiter
+	// ThrottleReplicas. nil if not set.
+	replicaThrottler *replicaLagThrottler
+	// LogAppliedSQL. nil if not set.
+	appliedSQLLogger *appliedSQLLogger
+	// orders the received messages of the incremental copy
+	incrSequencer *incrSequencer
+	// holds the received transactions for ReplicationDelay. nil if it is not set.
+	delayQueue *delayQueue
+
+	recentErrors *recentErrors
+	// the error the task stops with. See CheckHealth.
+	failure latchedError
+	// of the destination. no value can be larger. see validateLargeRowSize.
+	maxAllowedPacket int
+	// LoadDataInfile is set and the destination allows it. see validateLoadDataInfile.
+	loadDataInfile bool
+	// updated atomically. see ackIncr.
+	incrAckStats models.IncrAckStats
+	// the position in the incremental stream, for IncrAck
+	incrAcker *IncrAcker
+	// DdlRewrite. nil if not set.
+	ddlRewriter ddlRewriter
+	// EncryptionKey. nil if not set.
+	cipher *binlog.ColumnCipher
+
+	// set while paused on the destination being out of space. See pauseOnDiskFull.
+	diskFull diskFullPause
+	// The fields below can be overwritten for tests. probeDiskSpace and diskRecoveryCheckInterval by default.
+	diskProbe            func() error
+	diskRecoveryInterval time.Duration
 }
 
 func NewApplier(ctx *common.ExecContext, cfg *config.MySQLDriverConfig, logger *logrus.Logger) (*Applier, error) {
 	cfg = cfg.SetDefault()
-	entry := logger.WithFields(logrus.Fields{
-		"job": ctx.Subject,
-	})
+	entry := logger.WithFields(ctx.LogFields())
 	subjectUUID, err := uuid.FromString(ctx.Subject)
 	if err != nil {
 		logger.Errorf("job id is not a valid UUID: %v", err.Error())
@@ -262,6 +437,7 @@ func NewApplier(ctx *common.ExecContext, cfg *config.MySQLDriverConfig, logger *
 
 	a := &Applier{
 		logger:                  entry,
+		execCtx:                 ctx,
 		subject:                 ctx.Subject,
 		subjectUUID:             subjectUUID,
 		mysqlContext:            cfg,
@@ -275,12 +451,33 @@ func NewApplier(ctx *common.ExecContext, cfg *config.MySQLDriverConfig, logger *
 		applyBinlogGroupTxQueue: make(chan []*binlog.BinlogTx, cfg.ReplChanBufferSize*2),
 		waitCh:                  make(chan *models.WaitResult, 1),
 		shutdownCh:              make(chan struct{}),
+		recentErrors:            getRecentErrors(ctx.Subject, ctx.TaskType, cfg.MaxRecentErrors),
+		incrSequencer:           newIncrSequencer(cfg.ReorderWindow),
+		incrAcker:               NewIncrAcker(ctx.TaskType),
 		printTps:                os.Getenv(g.ENV_PRINT_TPS) != "",
+		subPendingMsgsLimit:     gonats.DefaultSubPendingMsgsLimit,
+		subPendingBytesLimit:    gonats.DefaultSubPendingBytesLimit,
+	}
+	if err := validateApplyEventRateLimit(cfg.ApplyEventRateLimit); err != nil {
+		return nil, err
+	}
+	a.eventRateLimiter = newEventRateLimiter(cfg.ApplyEventRateLimit)
+	a.appliedSQLLogger = newAppliedSQLLogger(cfg, entry)
+	if a.ddlRewriter, err = newDdlRewriter(cfg.DdlRewrite); err != nil {
+		return nil, err
+	}
+	if a.cipher, err = binlog.NewColumnCipher(cfg.EncryptionKey); err != nil {
+		return nil, err
 	}
 	a.gtidSet, err = DtleParseMysqlGTIDSet(a.mysqlContext.Gtid)
 	if err != nil {
 		return nil, err
 	}
+	a.applyBatches = make([]*applyBatch, cfg.ParallelWorkers)
+	for i := range a.applyBatches {
+		a.applyBatches[i] = &applyBatch{}
+	}
+	a.applyBatchTimeout = time.Duration(cfg.ApplyBatchTimeout) * time.Millisecond
 	stubFullApplyDelayStr := os.Getenv(g.ENV_FULL_APPLY_DELAY)
 	if stubFullApplyDelayStr == "" {
 		a.stubFullApplyDelay = 0
@@ -300,14 +497,26 @@ func NewApplier(ctx *common.ExecContext, cfg *config.MySQLDriverConfig, logger *
 func (a *Applier) MtsWorker(workerIndex int) {
 	keepLoop := true
 
+	batch := a.applyBatches[workerIndex]
 	for keepLoop {
-		timer := time.NewTimer(pingInterval)
+		if batch.isPending() && len(a.applyBinlogMtsTxQueue) == 0 {
+			// Nothing more to group now. Commit rather than blocking transactions depending on this batch.
+			if err := a.commitApplyBatch(workerIndex, batch); err != nil {
+				a.onError(TaskStateDead, err)
+				break
+			}
+		}
+		timeout := pingInterval
+		if batch.isPending() {
+			timeout = batch.remaining(a.applyBatchTimeout, time.Now())
+		}
+		timer := time.NewTimer(timeout)
 		select {
 		case tx := <-a.applyBinlogMtsTxQueue:
 			a.logger.Debugf("mysql.applier: a binlogEntry MTS dequeue, worker: %v. GNO: %v",
 				workerIndex, tx.Coordinates.GNO)
-			if err := a.ApplyBinlogEvent(nil, workerIndex, tx); err != nil {
-				a.onError(TaskStateDead, err) // TODO coordinate with other goroutine
+			if err := a.ApplyBinlogEvent(nil, workerIndex, batch, tx); err != nil {
+				a.onApplyError(err) // TODO coordinate with other goroutine
 				keepLoop = false
 			} else {
 				// do nothing
@@ -317,6 +526,13 @@ func (a *Applier) MtsWorker(workerIndex int) {
 		case <-a.shutdownCh:
 			keepLoop = false
 		case <-timer.C:
+			if batch.isPending() {
+				if err := a.commitApplyBatch(workerIndex, batch); err != nil {
+					a.onError(TaskStateDead, err)
+					keepLoop = false
+				}
+				break
+			}
 			err := a.dbs[workerIndex].Db.PingContext(context.Background())
 			if err != nil {
 				a.logger.Errorf("mysql.applier. bad connection for mts worker. workerIndex: %v, err: %v",
@@ -345,12 +561,36 @@ func (a *Applier) Run() {
 		a.onError(TaskStateDead, err)
 		return
 	}
+	if err := a.initApplyErrorPolicy(); err != nil {
+		a.onError(TaskStateDead, err)
+		return
+	}
+	if a.mysqlContext.NonTransactionalApply {
+		a.logger.Warnf("mysql.applier: NonTransactionalApply: applying in autocommit mode." +
+			" a transaction failing in the middle is applied in part until the restart")
+	}
+	replicaThrottler, err := newReplicaLagThrottler(a.mysqlContext, a.logger)
+	if err != nil {
+		a.onError(TaskStateDead, err)
+		return
+	}
+	if replicaThrottler != nil {
+		a.logger.Infof("mysql.applier: ThrottleReplicas: %v, ThrottleReplicaMaxLag: %vs",
+			a.mysqlContext.ThrottleReplicas, a.mysqlContext.ThrottleReplicaMaxLag)
+		a.replicaThrottler = replicaThrottler
+		go replicaThrottler.run(a.shutdownCh)
+	}
 	if err := a.initNatSubClient(); err != nil {
 		a.onError(TaskStateDead, err)
 		return
 	}
 
-	if err := a.initiateStreaming(); err != nil {
+	if a.mysqlContext.ReplayFile != "" {
+		if err := a.initiateReplay(); err != nil {
+			a.onError(TaskStateDead, err)
+			return
+		}
+	} else if err := a.initiateStreaming(); err != nil {
 		a.onError(TaskStateDead, err)
 		return
 	}
@@ -358,6 +598,9 @@ func (a *Applier) Run() {
 	for i := 0; i < a.mysqlContext.ParallelWorkers; i++ {
 		go a.MtsWorker(i)
 	}
+	if a.mysqlContext.MaxLagBeforeStop > 0 {
+		go a.checkReplicationLag()
+	}
 
 	go a.executeWriteFuncs()
 }
@@ -420,7 +663,21 @@ func (a *Applier) onApplyTxStructWithSuper(dbApplier *sql.Conn, binlogTx *binlog
 // This is where the ghost table gets the data. The function fills the data single-threaded.
 // Both event backlog and rowcopy events are polled; the backlog events have precedence.
 func (a *Applier) executeWriteFuncs() {
+	// The full copy and PreDumpSQL/PostDumpSQL use the same connection,
+	// so that session variables set by PreDumpSQL take effect on the load.
+	var dumpConn *gosql.Conn
 	if a.mysqlContext.Gtid == "" {
+		var err error
+		dumpConn, err = a.db.Conn(context.Background())
+		if err != nil {
+			a.onError(TaskStateDead, err)
+			return
+		}
+		if err := a.execDumpSQL(dumpConn, "PreDumpSQL", a.mysqlContext.PreDumpSQL); err != nil {
+			a.onError(TaskStateDead, err)
+			return
+		}
+
 		go func() {
 			var stopLoop = false
 			for !stopLoop {
@@ -428,8 +685,15 @@ func (a *Applier) executeWriteFuncs() {
 				case copyRows := <-a.copyRowsQueue:
 					if nil != copyRows {
 						//time.Sleep(20 * time.Second) // #348 stub
-						if err := a.ApplyEventQueries(a.db, copyRows); err != nil {
+						if !a.replicaThrottler.wait(a.shutdownCh) {
+							stopLoop = true
+							continue
+						}
+						if err := a.ApplyEventQueries(dumpConn, copyRows); err != nil {
 							a.onError(TaskStateDead, err)
+						} else if len(copyRows.Checkpoint) > 0 {
+							// the chunk has been committed
+							a.mysqlContext.DumpCheckpoint = string(copyRows.Checkpoint)
 						}
 					}
 					if atomic.LoadInt64(&a.nDumpEntry) < 0 {
@@ -455,6 +719,20 @@ func (a *Applier) executeWriteFuncs() {
 			if atomic.LoadInt64(&a.rowCopyCompleteFlag) == 1 && a.mysqlContext.TotalRowsCopied == a.mysqlContext.TotalRowsReplay {
 				a.rowCopyComplete <- true
 				a.logger.Printf("mysql.applier: Rows copy complete.number of rows:%d", a.mysqlContext.TotalRowsReplay)
+				if err := a.execDumpSQL(dumpConn, "PostDumpSQL", a.mysqlContext.PostDumpSQL); err != nil {
+					a.onError(TaskStateDead, err)
+					break
+				}
+				// The connection is returned to the pool.
+				if a.mysqlContext.DisableForeignKeyChecks != config.DisableForeignKeyChecksNone {
+					query := "SET @@session.foreign_key_checks = @@global.foreign_key_checks"
+					if _, err := dumpConn.ExecContext(context.Background(), query); err != nil {
+						a.onError(TaskStateDead, err)
+						break
+					}
+				}
+				dumpConn.Close()
+				a.execCtx.EmitTyped(models.TaskFullCopyComplete, "rows copy complete. number of rows: %d", a.mysqlContext.TotalRowsReplay)
 				a.mysqlContext.Gtid = a.currentCoordinates.RetrievedGtidSet
 				var err error
 				a.gtidSet, err = DtleParseMysqlGTIDSet(a.mysqlContext.Gtid)
@@ -463,6 +741,7 @@ func (a *Applier) executeWriteFuncs() {
 				}
 				a.mysqlContext.BinlogFile = a.currentCoordinates.File
 				a.mysqlContext.BinlogPos = a.currentCoordinates.Position
+				a.mysqlContext.DumpCheckpoint = ""
 				break
 			}
 			if a.shutdown {
@@ -509,8 +788,8 @@ func (a *Applier) executeWriteFuncs() {
 }
 
 func (a *Applier) initNatSubClient() (err error) {
-	natsAddr := fmt.Sprintf("nats://%s", a.mysqlContext.NatsAddr)
-	sc, err := gonats.Connect(natsAddr)
+	natsAddr := NatsURL(a.mysqlContext.NatsAddr)
+	sc, err := ConnectNats(a.mysqlContext.NatsAddr, a.execCtx, a.logger, gonats.ErrorHandler(a.natsErrorHandler))
 	if err != nil {
 		a.logger.Errorf("mysql.applier: Can't connect nats server %v. make sure a nats streaming server is running.%v", natsAddr, err)
 		return err
@@ -520,7 +799,7 @@ func (a *Applier) initNatSubClient() (err error) {
 	return nil
 }
 func DecodeDumpEntry(data []byte) (entry *DumpEntry, err error) {
-	msg, err := snappy.Decode(nil, data)
+	msg, err := decompressDumpMsg(data)
 	if err != nil {
 		return nil, err
 	}
@@ -555,28 +834,103 @@ func (a *Applier) setTableItemForBinlogEntry(binlogEntry *binlog.BinlogEntry) er
 		case binlog.NotDML:
 			// do nothing
 		default:
+			// before the values are reordered, which the indexes of the encrypted columns are not
+			if err := a.cipher.DecryptEvent(dmlEvent); err != nil {
+				return err
+			}
 			tableItem := a.getTableItem(dmlEvent.DatabaseName, dmlEvent.TableName)
+			reindex := false
+			if dmlEvent.Table != nil {
+				// the first event of the table since the start or a change of its definition
+				a.checkColumnTypes(dmlEvent.Table, dmlEvent.DatabaseName, dmlEvent.TableName)
+				tableItem.sourceColumns = dmlEvent.Table.OriginalTableColumns
+				reindex = true
+			}
 			if tableItem.columns == nil {
+				reindex = true
 				a.logger.Debugf("mysql.applier: get tableColumns %v.%v", dmlEvent.DatabaseName, dmlEvent.TableName)
-				tableItem.columns, err = base.GetTableColumns(a.db, dmlEvent.DatabaseName, dmlEvent.TableName)
+				tableItem.columns, tableItem.columnExprs, err = a.getTableColumns(dmlEvent.DatabaseName, dmlEvent.TableName)
 				if err != nil {
-					a.logger.Errorf("mysql.applier. GetTableColumns error. err: %v", err)
+					a.recordError(models.ErrorTypeSchema, binlogEntry.Coordinates.GetGtidForThisTx(), err)
 					return err
 				}
-				err = base.ApplyColumnTypes(a.db, dmlEvent.DatabaseName, dmlEvent.TableName, tableItem.columns)
-				if err != nil {
-					a.logger.Errorf("mysql.applier. ApplyColumnTypes error. err: %v", err)
-					return err
+				tableItem.keyless = !sql.HasPrimaryKey(tableItem.columns)
+				if tableItem.keyless && a.mysqlContext.AllowKeylessTables {
+					a.logger.Warnf("mysql.applier: %v.%v has no primary key. UPDATE and DELETE will match rows by all columns."+
+						" This is slow, and a row may be missed (e.g. FLOAT columns) or a duplicate one changed instead",
+						dmlEvent.DatabaseName, dmlEvent.TableName)
 				}
 			} else {
 				a.logger.Debugf("mysql.applier: reuse tableColumns %v.%v", dmlEvent.DatabaseName, dmlEvent.TableName)
 			}
+			if reindex {
+				tableItem.valueIndexes = columnValueIndexes(tableItem.sourceColumns, tableItem.columns)
+				if tableItem.valueIndexes != nil {
+					a.logger.Infof("mysql.applier: %v.%v: the columns differ from the source in order or number."+
+						" values are matched by column name", dmlEvent.DatabaseName, dmlEvent.TableName)
+				}
+				tableItem.dateColumns = nil
+				if a.zeroDatePolicyApplies() {
+					tableItem.dateColumns = zeroDateColumns(tableItem.columns)
+				}
+				if err := a.setShardBy(tableItem, dmlEvent.DatabaseName, dmlEvent.TableName); err != nil {
+					a.recordError(models.ErrorTypeSchema, binlogEntry.Coordinates.GetGtidForThisTx(), err)
+					return err
+				}
+			}
+			if tableItem.valueIndexes != nil {
+				sourceLen := len(tableItem.sourceColumns.Columns)
+				dmlEvent.WhereColumnValues = reorderColumnValues(dmlEvent.WhereColumnValues, tableItem.valueIndexes, sourceLen)
+				dmlEvent.NewColumnValues = reorderColumnValues(dmlEvent.NewColumnValues, tableItem.valueIndexes, sourceLen)
+			}
+			if tableItem.dateColumns != nil {
+				// the rows to be matched were written with the replaced values too
+				a.replaceZeroDates(tableItem.columns, tableItem.dateColumns, dmlEvent.WhereColumnValues)
+				a.replaceZeroDates(tableItem.columns, tableItem.dateColumns, dmlEvent.NewColumnValues)
+			}
+			if tableItem.keyless && dmlEvent.DML != binlog.InsertDML && !a.mysqlContext.AllowKeylessTables {
+				err := fmt.Errorf("%v.%v has no primary key and cannot be applied %v. set AllowKeylessTables to match rows by all columns",
+					dmlEvent.DatabaseName, dmlEvent.TableName, dmlEvent.DML)
+				a.recordError(models.ErrorTypeSchema, binlogEntry.Coordinates.GetGtidForThisTx(), err)
+				return err
+			}
 			dmlEvent.TableItem = tableItem
 		}
 	}
+	if len(a.mysqlContext.ShardBy) > 0 {
+		binlogEntry.Events, err = routeShardEvents(binlogEntry.Events)
+		if err != nil {
+			a.recordError(models.ErrorTypeSchema, binlogEntry.Coordinates.GetGtidForThisTx(), err)
+			return err
+		}
+	}
 	return nil
 }
 
+// checkExecuted tells whether binlogEntry has been applied, so a transaction redelivered
+// after a crash (e.g. the extractor resumes from an older checkpoint) is skipped.
+// The GTID of each transaction is recorded into gtid_executed_v3 in the same destination
+// transaction as its data (see ApplyBinlogEvent), so after a restart the set loaded from the
+// table contains exactly the committed transactions.
+// Also returns the item of the source uuid, to which the caller adds the transaction.
+func (a *Applier) checkExecuted(binlogEntry *binlog.BinlogEntry) (bool, *base.GtidExecutedItem, error) {
+	if a.gtidExecuted == nil {
+		// udup crash recovery or never executed
+		var err error
+		a.gtidExecuted, err = base.SelectAllGtidExecuted(a.db, a.subjectUUID)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	gtidSetItem, hasSid := a.gtidExecuted[binlogEntry.Coordinates.SID]
+	if !hasSid {
+		gtidSetItem = &base.GtidExecutedItem{}
+		a.gtidExecuted[binlogEntry.Coordinates.SID] = gtidSetItem
+	}
+	return base.IntervalSlicesContainOne(gtidSetItem.Intervals, binlogEntry.Coordinates.GNO), gtidSetItem, nil
+}
+
 func (a *Applier) cleanGtidExecuted(sid uuid.UUID, intervalStr string) error {
 	a.logger.Debugf("mysql.applier. incr. cleanup before WaitForExecution")
 	if !a.mtsManager.WaitForAllCommitted() {
@@ -614,13 +968,9 @@ func (a *Applier) cleanGtidExecuted(sid uuid.UUID, intervalStr string) error {
 	return nil
 }
 
+// DtleParseMysqlGTIDSet parses Gtid of a job, which is of MySQL or of MariaDB. See base.ParseGtidSet.
 func DtleParseMysqlGTIDSet(gtidSetStr string) (*gomysql.MysqlGTIDSet, error) {
-	set0, err := gomysql.ParseMysqlGTIDSet(gtidSetStr)
-	if err != nil {
-		return nil, err
-	}
-
-	return set0.(*gomysql.MysqlGTIDSet), nil
+	return base.ParseGtidSet(gtidSetStr)
 }
 
 func (a *Applier) updateGtidSet(sidStr string, sid uuid.UUID, txGno int64) {
@@ -641,49 +991,107 @@ func (a *Applier) updateGtidSet(sidStr string, sid uuid.UUID, txGno int64) {
 }
 
 func (a *Applier) heterogeneousReplay() {
-	var err error
 	stopSomeLoop := false
 	prevDDL := false
 	var ctx context.Context
+	// for MySQL 5.6 (non-mts) transactions and big transactions in pieces (see BigTxSplittingSize),
+	// applied by this goroutine on worker 0.
+	serialBatch := &applyBatch{}
 	for !stopSomeLoop {
+		if serialBatch.isPending() && !serialBatch.inBigTx() && len(a.applyDataEntryQueue) == 0 {
+			if err := a.commitApplyBatch(0, serialBatch); err != nil {
+				a.onError(TaskStateDead, err)
+				return
+			}
+		}
 		select {
 		case binlogEntry := <-a.applyDataEntryQueue:
 			if nil == binlogEntry {
 				continue
 			}
 			spanContext := binlogEntry.SpanContext
+			if txSpanContext := binlog.TxSpanContext(binlogEntry); txSpanContext != nil {
+				// in the trace of the transaction on the source
+				spanContext = txSpanContext
+			}
 			span := opentracing.GlobalTracer().StartSpan("dest use binlogEntry  ", opentracing.FollowsFrom(spanContext))
+			span.SetTag(binlog.TxSpanTagGtid, binlogEntry.Coordinates.GetGtidForThisTx())
 			ctx = opentracing.ContextWithSpan(ctx, span)
 			a.logger.Debugf("mysql.applier: a binlogEntry. remaining: %v. gno: %v, lc: %v, seq: %v",
 				len(a.applyDataEntryQueue), binlogEntry.Coordinates.GNO,
 				binlogEntry.Coordinates.LastCommitted, binlogEntry.Coordinates.SeqenceNumber)
 
+			if binlogEntry.Resync != nil {
+				if err := a.applyResyncPart(ctx, serialBatch, binlogEntry); err != nil {
+					a.onError(TaskStateDead, err)
+					return
+				}
+				span.Finish()
+				continue
+			}
+
 			if binlogEntry.Coordinates.OSID == a.mysqlContext.MySQLServerUuid {
 				a.logger.Debugf("mysql.applier: skipping a dtle tx. osid: %v", binlogEntry.Coordinates.OSID)
+				if serialBatch.bigTxGtid == binlogEntry.Coordinates.GetGtidForThisTx() {
+					// The origin is found in a later piece of a big transaction. Discard the applied pieces.
+					if err := a.rollbackApplyBatch(0, serialBatch); err != nil {
+						a.onError(TaskStateDead, err)
+						return
+					}
+					// enqueued by WaitForExecution with its first piece
+					a.mtsManager.Executed(binlogEntry)
+				}
+				a.ackIncrSkipped(binlogEntry)
 				continue
 			}
-			// region TestIfExecuted
-			if a.gtidExecuted == nil {
-				// udup crash recovery or never executed
-				a.gtidExecuted, err = base.SelectAllGtidExecuted(a.db, a.subjectUUID)
+			var gtidSetItem *base.GtidExecutedItem
+			var err error
+			// The gtid of a big transaction is taken as executed with its first piece.
+			if serialBatch.bigTxGtid != binlogEntry.Coordinates.GetGtidForThisTx() {
+				var executed bool
+				executed, gtidSetItem, err = a.checkExecuted(binlogEntry)
 				if err != nil {
 					a.onError(TaskStateDead, err)
 					return
 				}
+				if executed {
+					a.logger.Debugf("mysql.applier: skip an executed tx: %v:%v",
+						binlogEntry.Coordinates.GetSid(), binlogEntry.Coordinates.GNO)
+					a.ackIncrSkipped(binlogEntry)
+					continue
+				}
 			}
-			txSid := binlogEntry.Coordinates.GetSid()
-
-			gtidSetItem, hasSid := a.gtidExecuted[binlogEntry.Coordinates.SID]
-			if !hasSid {
-				gtidSetItem = &base.GtidExecutedItem{}
-				a.gtidExecuted[binlogEntry.Coordinates.SID] = gtidSetItem
+			if skip, err := serialBatch.checkPiece(binlogEntry); err != nil {
+				a.onError(TaskStateDead, err)
+				return
+			} else if skip {
+				a.logger.Debugf("mysql.applier: skip an applied piece %v of tx: %v:%v", binlogEntry.Index,
+					binlogEntry.Coordinates.GetSid(), binlogEntry.Coordinates.GNO)
+				a.ackIncrSkipped(binlogEntry)
+				continue
+			}
+			a.filterDestDoDb(binlogEntry)
+			// Meanwhile the queue fills up and the extractor is held back.
+			if !a.eventRateLimiter.wait(a.shutdownCh) || !a.replicaThrottler.wait(a.shutdownCh) {
+				return // shutdown
 			}
-			if base.IntervalSlicesContainOne(gtidSetItem.Intervals, binlogEntry.Coordinates.GNO) {
-				// entry executed
-				a.logger.Debugf("mysql.applier: skip an executed tx: %v:%v", txSid, binlogEntry.Coordinates.GNO)
+			if binlogEntry.Index > 0 {
+				// a later piece of a big transaction, which has been set up with its first piece.
+				if err := a.applySerially(ctx, serialBatch, binlogEntry); err != nil {
+					a.onApplyError(err)
+					return
+				}
+				if !binlogEntry.Partial {
+					// the last piece. With MTS, the next transactions wait for the commit.
+					if err := a.commitApplyBatch(0, serialBatch); err != nil {
+						a.onError(TaskStateDead, err)
+						return
+					}
+				}
+				span.Finish()
 				continue
 			}
-			// endregion
+			a.replicationLag.received(binlogEntry.Coordinates.Timestamp)
 			// this must be after duplication check
 			var rotated bool
 			if a.currentCoordinates.File == binlogEntry.Coordinates.LogFile {
@@ -695,6 +1103,11 @@ func (a *Applier) heterogeneousReplay() {
 
 			a.logger.Debugf("mysql.applier. gtidSetItem.NRow: %v", gtidSetItem.NRow)
 			if gtidSetItem.NRow >= cleanupGtidExecutedLimit {
+				// the pending batch holds rows of gtid_executed.
+				if err := a.commitApplyBatch(0, serialBatch); err != nil {
+					a.onError(TaskStateDead, err)
+					return
+				}
 				err = a.cleanGtidExecuted(binlogEntry.Coordinates.SID, base.StringInterval(gtidSetItem.Intervals))
 				if err != nil {
 					a.onError(TaskStateDead, err)
@@ -710,15 +1123,28 @@ func (a *Applier) heterogeneousReplay() {
 			newInterval := append(gtidSetItem.Intervals, thisInterval).Normalize()
 			// TODO this is assigned before real execution
 			gtidSetItem.Intervals = newInterval
+			if p := binlogEntry.XAPrepare; p != nil {
+				// the XA PREPARE of the transaction, executed with it
+				prepareItem, ok := a.gtidExecuted[p.SID]
+				if !ok {
+					prepareItem = &base.GtidExecutedItem{}
+					a.gtidExecuted[p.SID] = prepareItem
+				}
+				prepareItem.NRow += 1
+				prepareItem.Intervals = append(prepareItem.Intervals,
+					gomysql.Interval{Start: p.GNO, Stop: p.GNO + 1}).Normalize()
+			}
 			if binlogEntry.Coordinates.SeqenceNumber == 0 {
 				// MySQL 5.6: non mts
-				err := a.setTableItemForBinlogEntry(binlogEntry)
-				if err != nil {
-					a.onError(TaskStateDead, err)
-					return
+				if binlogEntry.Partial {
+					// The big transaction is applied alone, so that it can be rolled back by OSID.
+					if err := a.commitApplyBatch(0, serialBatch); err != nil {
+						a.onError(TaskStateDead, err)
+						return
+					}
 				}
-				if err := a.ApplyBinlogEvent(ctx, 0, binlogEntry); err != nil {
-					a.onError(TaskStateDead, err)
+				if err := a.applySerially(ctx, serialBatch, binlogEntry); err != nil {
+					a.onApplyError(err)
 					return
 				}
 			} else {
@@ -738,17 +1164,7 @@ func (a *Applier) heterogeneousReplay() {
 					a.mtsManager.lastEnqueue += 1
 					a.mtsManager.chExecuted <- a.mtsManager.lastEnqueue
 				}
-				hasDDL := func() bool {
-					for i := range binlogEntry.Events {
-						dmlEvent := &binlogEntry.Events[i]
-						switch dmlEvent.DML {
-						case binlog.NotDML:
-							return true
-						default:
-						}
-					}
-					return false
-				}()
+				hasDDL := binlogEntry.HasDDL()
 				// DDL must be executed separatedly
 				if hasDDL || prevDDL {
 					a.logger.Debugf("mysql.applier: gno: %v MTS found DDL(%v,%v). WaitForAllCommitted",
@@ -763,9 +1179,27 @@ func (a *Applier) heterogeneousReplay() {
 					prevDDL = false
 				}
 
+				if binlogEntry.Partial {
+					// The pieces of a big transaction are applied in order on worker 0, after all the
+					// previous transactions. The next transactions wait for it as for a DDL.
+					a.logger.Debugf("mysql.applier: gno: %v MTS found a big transaction. WaitForAllCommitted",
+						binlogEntry.Coordinates.GNO)
+					if !a.mtsManager.WaitForAllCommitted() {
+						return // shutdown
+					}
+					prevDDL = true
+				}
 				if !a.mtsManager.WaitForExecution(binlogEntry) {
 					return // shutdown
 				}
+				if binlogEntry.Partial {
+					if err := a.applySerially(ctx, serialBatch, binlogEntry); err != nil {
+						a.onApplyError(err)
+						return
+					}
+					span.Finish()
+					continue
+				}
 				a.logger.Debugf("mysql.applier: a binlogEntry MTS enqueue. gno: %v", binlogEntry.Coordinates.GNO)
 				err = a.setTableItemForBinlogEntry(binlogEntry)
 				if err != nil {
@@ -776,15 +1210,7 @@ func (a *Applier) heterogeneousReplay() {
 				a.applyBinlogMtsTxQueue <- binlogEntry
 			}
 			span.Finish()
-			if !a.shutdown {
-				a.updateGtidSet(txSid, binlogEntry.Coordinates.SID, binlogEntry.Coordinates.GNO)
-				a.updateGtidString()
-				if a.mysqlContext.BinlogFile != binlogEntry.Coordinates.LogFile {
-					a.mysqlContext.BinlogFile = binlogEntry.Coordinates.LogFile
-					a.publishProgress()
-				}
-				a.mysqlContext.BinlogPos = binlogEntry.Coordinates.LogPos
-			}
+			// the reported gtid set is updated when the batch is committed. See onApplyBatchCommitted.
 		case <-time.After(10 * time.Second):
 			a.logger.Debugf("mysql.applier: no binlogEntry for 10s")
 		case <-a.shutdownCh:
@@ -847,7 +1273,7 @@ func (a *Applier) initiateStreaming() error {
 	a.mysqlContext.MarkRowCopyStartTime()
 	a.logger.Debugf("mysql.applier: nats subscribe")
 	tracer := opentracing.GlobalTracer()
-	_, err := a.natsConn.Subscribe(fmt.Sprintf("%s_full", a.subject), func(m *gonats.Msg) {
+	err := a.subscribeData(fmt.Sprintf("%s_full", a.subject), func(m *gonats.Msg) {
 		a.logger.Debugf("mysql.applier: full. recv a msg. copyRowsQueue: %v", len(a.copyRowsQueue))
 		t := not.NewTraceMsg(m)
 		// Extract the span context from the request message.
@@ -864,6 +1290,14 @@ func (a *Applier) initiateStreaming() error {
 			a.onError(TaskStateDead, err)
 			// TODO return?
 		}
+		if dumpData.TableSchema != "" &&
+			!config.MatchDestDoDb(a.mysqlContext.DestDoDb, dumpData.TableSchema, dumpData.TableName) {
+			a.logger.Debugf("mysql.applier: full. skip %v.%v not in DestDoDb", dumpData.TableSchema, dumpData.TableName)
+			if err := PublishReply(a.natsConn, m, a.execCtx.TaskType); err != nil {
+				a.onError(TaskStateDead, err)
+			}
+			return
+		}
 
 		timer := time.NewTimer(DefaultConnectWait / 2)
 		atomic.AddInt64(&a.nDumpEntry, 1) // this must be increased before enqueuing
@@ -872,7 +1306,7 @@ func (a *Applier) initiateStreaming() error {
 			a.logger.Debugf("mysql.applier: full. enqueue")
 			timer.Stop()
 			a.mysqlContext.Stage = models.StageSlaveWaitingForWorkersToProcessQueue
-			if err := a.natsConn.Publish(m.Reply, nil); err != nil {
+			if err := PublishReply(a.natsConn, m, a.execCtx.TaskType); err != nil {
 				a.onError(TaskStateDead, err)
 			}
 			a.logger.Debugf("mysql.applier. full. after publish nats reply")
@@ -884,9 +1318,9 @@ func (a *Applier) initiateStreaming() error {
 			a.mysqlContext.Stage = models.StageSlaveWaitingForWorkersToProcessQueue
 		}
 	})
-	/*if err := sub.SetPendingLimits(a.mysqlContext.MsgsLimit, a.mysqlContext.BytesLimit); err != nil {
+	if err != nil {
 		return err
-	}*/
+	}
 
 	_, err = a.natsConn.Subscribe(fmt.Sprintf("%s_full_complete", a.subject), func(m *gonats.Msg) {
 		dumpData := &dumpStatResult{}
@@ -903,33 +1337,56 @@ func (a *Applier) initiateStreaming() error {
 		if err := Decode(t.Bytes(), dumpData); err != nil {
 			a.onError(TaskStateDead, err)
 		}
-		a.currentCoordinates.RetrievedGtidSet = dumpData.Gtid
-		a.currentCoordinates.File = dumpData.LogFile
-		a.currentCoordinates.Position = dumpData.LogPos
-
-		a.mysqlContext.Stage = models.StageSlaveWaitingForWorkersToProcessQueue
-
-		for atomic.LoadInt64(&a.nDumpEntry) != 0 {
-			a.logger.Debugf("mysql.applier. nDumpEntry is not zero, waiting. %v", a.nDumpEntry)
-			time.Sleep(1 * time.Second)
-			if a.shutdown {
-				return
-			}
+		if !a.onFullComplete(dumpData) {
+			return
 		}
 
 		a.logger.Debugf("mysql.applier. ack full_complete")
-		if err := a.natsConn.Publish(m.Reply, nil); err != nil {
+		if err := PublishReply(a.natsConn, m, a.execCtx.TaskType); err != nil {
 			a.onError(TaskStateDead, err)
 		}
-		atomic.AddInt64(&a.mysqlContext.TotalRowsCopied, dumpData.TotalCount)
-		atomic.StoreInt64(&a.rowCopyCompleteFlag, 1)
 	})
 	if err != nil {
 		return err
 	}
 
+	// The extractor resumes the full copy from the checkpoint of the last applied chunk.
+	_, err = a.natsConn.Subscribe(fmt.Sprintf("%s_dump_checkpoint", a.subject), func(m *gonats.Msg) {
+		if err := a.natsConn.Publish(m.Reply, []byte(a.mysqlContext.DumpCheckpoint)); err != nil {
+			a.logger.Errorf("mysql.applier: failed to reply the dump checkpoint. err: %v", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// The source has purged the binlog to be extracted and OnPurgedGtid=restart-dump.
+	// Forget the coordinates so the restarted job begins with a full dump.
+	_, err = a.natsConn.Subscribe(fmt.Sprintf("%s_restart_dump", a.subject), func(m *gonats.Msg) {
+		a.logger.Warnf("mysql.applier: the source requires a full dump. restarting")
+		a.mysqlContext.Gtid = ""
+		a.mysqlContext.BinlogFile = ""
+		a.mysqlContext.BinlogPos = 0
+		a.mysqlContext.DumpCheckpoint = ""
+		a.onError(TaskStateRestart, fmt.Errorf("restart-dump"))
+	})
+	if err != nil {
+		return err
+	}
+
+	if a.mysqlContext.ApproveHeterogeneous && a.mysqlContext.ReplicationDelay > 0 {
+		// before the transactions are received
+		a.delayQueue, err = newDelayQueue(time.Duration(a.mysqlContext.ReplicationDelay)*time.Second,
+			path.Join(a.execCtx.StateDir, "delay", a.subject))
+		if err != nil {
+			return err
+		}
+		a.logger.Infof("mysql.applier: ReplicationDelay: transactions are applied %v seconds after they are"+
+			" committed on the source", a.mysqlContext.ReplicationDelay)
+		go a.releaseDelayed()
+	}
 	if a.mysqlContext.ApproveHeterogeneous {
-		_, err := a.natsConn.Subscribe(fmt.Sprintf("%s_incr_hete", a.subject), func(m *gonats.Msg) {
+		err := a.subscribeData(fmt.Sprintf("%s_incr_hete", a.subject), func(m *gonats.Msg) {
 			var binlogEntries binlog.BinlogEntries
 			t := not.NewTraceMsg(m)
 			// Extract the span context from the request message.
@@ -944,47 +1401,16 @@ func (a *Applier) initiateStreaming() error {
 			if err := Decode(t.Bytes(), &binlogEntries); err != nil {
 				a.onError(TaskStateDead, err)
 			}
-
-			nEntries := len(binlogEntries.Entries)
-
-			handled := false
-			for i := 0; !handled && (i < DefaultConnectWaitSecond/2); i++ {
-				vacancy := cap(a.applyDataEntryQueue) - len(a.applyDataEntryQueue)
-				a.logger.Debugf("applier. incr. nEntries: %v, vacancy: %v", nEntries, vacancy)
-				if vacancy < nEntries {
-					a.logger.Debugf("applier. incr. wait 1s for applyDataEntryQueue")
-					time.Sleep(1 * time.Second) // It will wait an second at the end, but seems no hurt.
-				} else {
-					a.logger.Debugf("applier. incr. applyDataEntryQueue enqueue")
-					for _, binlogEntry := range binlogEntries.Entries {
-						binlogEntry.SpanContext = replySpan.Context()
-						a.applyDataEntryQueue <- binlogEntry
-						a.currentCoordinates.RetrievedGtidSet = binlogEntry.Coordinates.GetGtidForThisTx()
-						atomic.AddInt64(&a.mysqlContext.DeltaEstimate, 1)
-					}
-					a.mysqlContext.Stage = models.StageWaitingForMasterToSendEvent
-
-					if err := a.natsConn.Publish(m.Reply, nil); err != nil {
-						a.onError(TaskStateDead, err)
-					}
-					a.logger.Debugf("applier. incr. ack-recv. nEntries: %v", nEntries)
-
-					handled = true
-				}
-			}
-			if !handled {
-				// discard these entries
-				a.logger.Debugf("applier. incr. discarding entries")
-				a.mysqlContext.Stage = models.StageWaitingForMasterToSendEvent
-			}
+			a.handleIncrEntries(m, &binlogEntries, replySpan.Context())
 		})
 		if err != nil {
 			return err
 		}
 
 		go a.heterogeneousReplay()
+		go ResendIncrAck(a.natsConn, a.subject, a.incrAcker, a.shutdownCh, a.logger)
 	} else {
-		_, err := a.natsConn.Subscribe(fmt.Sprintf("%s_incr", a.subject), func(m *gonats.Msg) {
+		err := a.subscribeData(fmt.Sprintf("%s_incr", a.subject), func(m *gonats.Msg) {
 			var binlogTx []*binlog.BinlogTx
 			t := not.NewTraceMsg(m)
 			// Extract the span context from the request message.
@@ -1002,22 +1428,97 @@ func (a *Applier) initiateStreaming() error {
 			for _, tx := range binlogTx {
 				a.applyBinlogTxQueue <- tx
 			}
-			if err := a.natsConn.Publish(m.Reply, nil); err != nil {
+			if err := PublishReply(a.natsConn, m, a.execCtx.TaskType); err != nil {
 				a.onError(TaskStateDead, err)
 			}
 		})
 		if err != nil {
 			return err
 		}
-		/*if err := sub.SetPendingLimits(a.mysqlContext.MsgsLimit, a.mysqlContext.BytesLimit); err != nil {
-			return err
-		}*/
 		go a.homogeneousReplay()
 	}
 
 	return nil
 }
 
+// handleIncrEntries enqueues the received messages of the incremental copy in order (see incrSequencer), and
+// acknowledges m when they are all enqueued. Otherwise m is not acknowledged and is resent by the extractor.
+func (a *Applier) handleIncrEntries(m *gonats.Msg, binlogEntries *binlog.BinlogEntries, spanContext opentracing.SpanContext) {
+	if err := a.incrSequencer.add(binlogEntries); err != nil {
+		// Some messages are lost. The task resumes from the checkpoint, which has not gone past them.
+		a.logger.Errorf("mysql.applier: %v. restarting", err)
+		a.recordError(models.ErrorTypeNats, "", err)
+		go a.onError(TaskStateRestart, err)
+		return
+	}
+
+	handled := true
+	for entries := a.incrSequencer.peek(); handled && entries != nil; entries = a.incrSequencer.peek() {
+		nEntries := len(entries.Entries)
+		handled = false
+		for i := 0; !handled && (i < DefaultConnectWaitSecond/2); i++ {
+			vacancy := cap(a.applyDataEntryQueue) - len(a.applyDataEntryQueue)
+			a.logger.Debugf("applier. incr. seq: %v, nEntries: %v, vacancy: %v", entries.Seq, nEntries, vacancy)
+			if vacancy < nEntries && a.delayQueue == nil {
+				a.logger.Debugf("applier. incr. wait 1s for applyDataEntryQueue")
+				time.Sleep(1 * time.Second) // It will wait an second at the end, but seems no hurt.
+			} else {
+				a.logger.Debugf("applier. incr. applyDataEntryQueue enqueue")
+				a.incrAcker.Receive(entries)
+				for _, binlogEntry := range entries.Entries {
+					binlogEntry.SpanContext = spanContext
+					if a.delayQueue != nil {
+						if err := a.delayQueue.push(binlogEntry); err != nil {
+							a.logger.Errorf("mysql.applier: %v. restarting", err)
+							go a.onError(TaskStateRestart, err)
+							return
+						}
+					} else {
+						a.applyDataEntryQueue <- binlogEntry
+					}
+					if binlogEntry.Resync == nil {
+						a.currentCoordinates.RetrievedGtidSet = binlogEntry.Coordinates.GetGtidForThisTx()
+					}
+					atomic.AddInt64(&a.mysqlContext.DeltaEstimate, 1)
+				}
+				a.incrSequencer.pop()
+				handled = true
+			}
+		}
+	}
+	a.mysqlContext.Stage = models.StageWaitingForMasterToSendEvent
+	if !handled {
+		// the entries are held by incrSequencer, and enqueued with the message resent
+		a.logger.Debugf("applier. incr. no vacancy. the message is not acknowledged")
+		return
+	}
+	if err := PublishReply(a.natsConn, m, a.execCtx.TaskType); err != nil {
+		a.onError(TaskStateDead, err)
+	}
+	a.logger.Debugf("applier. incr. ack-recv. seq: %v, nEntries: %v", binlogEntries.Seq, len(binlogEntries.Entries))
+}
+
+// onFullComplete waits for the received chunks of the full copy to be applied, and then starts the incremental copy.
+// It returns false on shutdown.
+func (a *Applier) onFullComplete(dumpData *dumpStatResult) bool {
+	a.currentCoordinates.RetrievedGtidSet = dumpData.Gtid
+	a.currentCoordinates.File = dumpData.LogFile
+	a.currentCoordinates.Position = dumpData.LogPos
+
+	a.mysqlContext.Stage = models.StageSlaveWaitingForWorkersToProcessQueue
+
+	for atomic.LoadInt64(&a.nDumpEntry) != 0 {
+		a.logger.Debugf("mysql.applier. nDumpEntry is not zero, waiting. %v", atomic.LoadInt64(&a.nDumpEntry))
+		time.Sleep(1 * time.Second)
+		if a.shutdown {
+			return false
+		}
+	}
+	atomic.AddInt64(&a.mysqlContext.TotalRowsCopied, dumpData.TotalCount)
+	atomic.StoreInt64(&a.rowCopyCompleteFlag, 1)
+	return true
+}
+
 func (a *Applier) publishProgress() {
 	retry := 0
 	keep := true
@@ -1046,15 +1547,50 @@ func (a *Applier) publishProgress() {
 }
 
 func (a *Applier) initDBConnections() (err error) {
-	applierUri := a.mysqlContext.ConnectionConfig.GetDBUri()
-	if a.db, err = sql.CreateDB(applierUri); err != nil {
+	if _, err := a.mysqlContext.TimeZoneLocation(); err != nil {
+		return err
+	}
+	switch a.mysqlContext.DisableForeignKeyChecks {
+	case config.DisableForeignKeyChecksAll, config.DisableForeignKeyChecksDump, config.DisableForeignKeyChecksNone:
+		a.logger.Infof("mysql.applier: DisableForeignKeyChecks: %v", a.mysqlContext.DisableForeignKeyChecks)
+	default:
+		return fmt.Errorf("bad job argument: DisableForeignKeyChecks=%v. should be one of %v, %v, %v",
+			a.mysqlContext.DisableForeignKeyChecks, config.DisableForeignKeyChecksAll,
+			config.DisableForeignKeyChecksDump, config.DisableForeignKeyChecksNone)
+	}
+	if len(a.mysqlContext.TablePriority) > 0 && a.disableForeignKeyChecksIncr() {
+		a.logger.Warnf("mysql.applier: TablePriority is ignored with DisableForeignKeyChecks=%v",
+			a.mysqlContext.DisableForeignKeyChecks)
+	}
+	applierUri := sql.UriWithTimeZone(a.mysqlContext.ConnectionConfig.GetDBUri(), a.mysqlContext.TimeZone)
+	if applierUri, err = sql.UriWithSessionVars(applierUri, a.mysqlContext.DestSessionVars); err != nil {
+		return fmt.Errorf("bad job argument: DestSessionVars: %v", err)
+	}
+	if len(a.mysqlContext.SessionInit) > 0 {
+		a.logger.Infof("mysql.applier: SessionInit: %v", a.mysqlContext.SessionInit)
+	}
+	if a.db, err = sql.CreateDBWithSessionInit(applierUri, a.mysqlContext.SessionInit); err != nil {
 		return err
 	}
-	a.db.SetMaxOpenConns(10 + a.mysqlContext.ParallelWorkers)
+	if len(a.mysqlContext.DestSessionVars) > 0 {
+		if err := a.db.Ping(); err != nil {
+			return fmt.Errorf("DestSessionVars: %v", err)
+		}
+	}
+	// Each worker holds a connection. The others are for the full copy and queries.
+	if a.mysqlContext.MaxOpenConns < a.mysqlContext.ParallelWorkers+minSpareConns {
+		return fmt.Errorf("bad job argument: MaxOpenConns=%v. should be at least ParallelWorkers+%v",
+			a.mysqlContext.MaxOpenConns, minSpareConns)
+	}
+	a.db.SetMaxOpenConns(a.mysqlContext.MaxOpenConns)
+	a.db.SetMaxIdleConns(a.mysqlContext.MaxIdleConns)
 
-	if a.dbs, err = sql.CreateConns(a.db, a.mysqlContext.ParallelWorkers); err != nil {
+	if a.dbs, err = sql.CreateConns(a.db, a.mysqlContext.ParallelWorkers, a.disableForeignKeyChecksIncr()); err != nil {
 		return err
 	}
+	for _, conn := range a.dbs {
+		a.setMaxExecutionTime(conn)
+	}
 
 	if err := a.validateConnection(a.db); err != nil {
 		return err
@@ -1071,6 +1607,12 @@ func (a *Applier) initDBConnections() (err error) {
 		return err
 	}
 	a.logger.Debugf("mysql.applier. after validateAndReadTimeZone")
+	if err := a.validateLargeRowSize(); err != nil {
+		return err
+	}
+	if err := a.validateLoadDataInfile(); err != nil {
+		return err
+	}
 
 	if a.mysqlContext.ApproveHeterogeneous {
 		if err := a.createTableGtidExecutedV3(); err != nil {
@@ -1079,20 +1621,9 @@ func (a *Applier) initDBConnections() (err error) {
 		a.logger.Debugf("mysql.applier. after createTableGtidExecutedV2")
 
 		for i := range a.dbs {
-			a.dbs[i].PsDeleteExecutedGtid, err = a.dbs[i].Db.PrepareContext(context.Background(), fmt.Sprintf("delete from %v.%v where job_uuid = unhex('%s') and source_uuid = ?",
-				g.DtleSchemaName, g.GtidExecutedTableV3, hex.EncodeToString(a.subjectUUID.Bytes())))
-			if err != nil {
-				return err
-			}
-			a.dbs[i].PsInsertExecutedGtid, err = a.dbs[i].Db.PrepareContext(context.Background(), fmt.Sprintf("replace into %v.%v "+
-				"(job_uuid,source_uuid,interval_gtid) "+
-				"values (unhex('%s'), ?, ?)",
-				g.DtleSchemaName, g.GtidExecutedTableV3,
-				hex.EncodeToString(a.subjectUUID.Bytes())))
-			if err != nil {
+			if err := a.prepareExecutedGtidStmts(a.dbs[i]); err != nil {
 				return err
 			}
-
 		}
 		a.logger.Debugf("mysql.applier. after prepare stmt for gtid_executed table")
 	}
@@ -1101,15 +1632,94 @@ func (a *Applier) initDBConnections() (err error) {
 	return nil
 }
 
-func (a *Applier) validateServerUUID() error {
-	query := `SELECT @@SERVER_UUID`
-	if err := a.db.QueryRow(query).Scan(&a.mysqlContext.MySQLServerUuid); err != nil {
+func (a *Applier) prepareExecutedGtidStmts(conn *sql.Conn) (err error) {
+	conn.PsDeleteExecutedGtid, err = conn.Db.PrepareContext(context.Background(), fmt.Sprintf("delete from %v.%v where job_uuid = unhex('%s') and source_uuid = ?",
+		g.DtleSchemaName, g.GtidExecutedTableV3, hex.EncodeToString(a.subjectUUID.Bytes())))
+	if err != nil {
 		return err
 	}
-	return nil
+	conn.PsInsertExecutedGtid, err = conn.Db.PrepareContext(context.Background(), fmt.Sprintf("replace into %v.%v "+
+		"(job_uuid,source_uuid,interval_gtid) "+
+		"values (unhex('%s'), ?, ?)",
+		g.DtleSchemaName, g.GtidExecutedTableV3,
+		hex.EncodeToString(a.subjectUUID.Bytes())))
+	return err
 }
 
-// validateConnection issues a simple can-connect to MySQL
+// validateConn pings the connection of a worker if it has been idle for connValidateIdleTime,
+// and replaces a broken one, e.g. after a restart of the destination. The caller holds its DbMutex.
+func (a *Applier) validateConn(workerIdx int, idleSince time.Time) error {
+	if time.Since(idleSince) < connValidateIdleTime {
+		return nil
+	}
+	conn := a.dbs[workerIdx]
+	ctx, cancel := context.WithTimeout(context.Background(), connPingTimeout)
+	err := conn.Db.PingContext(ctx)
+	cancel()
+	if err == nil {
+		return nil
+	}
+
+	a.logger.Warnf("mysql.applier: connection of worker %v is broken: %v. reconnecting", workerIdx, err)
+	conn.Db.Close()
+	conns, err := sql.CreateConns(a.db, 1, a.disableForeignKeyChecksIncr())
+	if err != nil {
+		return err
+	}
+	conn.Db = conns[0].Db
+	conn.Fde = ""
+	a.setMaxExecutionTime(conn)
+	if a.mysqlContext.ApproveHeterogeneous {
+		return a.prepareExecutedGtidStmts(conn)
+	}
+	return nil
+}
+
+// statementContext returns the context of a statement applying a transaction of the incremental copy, which
+// is cancelled after ApplyStatementTimeout. The connection of a cancelled statement is closed, which rolls back
+// its transaction. It is not used for a commit.
+func (a *Applier) statementContext() (context.Context, context.CancelFunc) {
+	if a.mysqlContext.ApplyStatementTimeout <= 0 {
+		return context.Background(), func() {}
+	}
+	return context.WithTimeout(context.Background(), time.Duration(a.mysqlContext.ApplyStatementTimeout)*time.Second)
+}
+
+// setMaxExecutionTime sets max_execution_time of a worker connection by ApplyStatementTimeout, so that the
+// destination itself interrupts a query (e.g. of ConflictResolutionColumn) running for too long. It is not
+// supported by MariaDB and MySQL before 5.7.8, where statementContext still applies.
+func (a *Applier) setMaxExecutionTime(conn *sql.Conn) {
+	if a.mysqlContext.ApplyStatementTimeout <= 0 {
+		return
+	}
+	query := fmt.Sprintf("SET @@session.max_execution_time = %d", a.mysqlContext.ApplyStatementTimeout*1000)
+	if _, err := conn.Db.ExecContext(context.Background(), query); err != nil {
+		a.logger.Warnf("mysql.applier: ApplyStatementTimeout: cannot set max_execution_time: %v", err)
+	}
+}
+
+// onApplyError stops the task on an error applying the incremental copy. On a statement timeout, the task
+// restarts from its checkpoint, after the delay of the restart policy, rather than failing.
+func (a *Applier) onApplyError(err error) {
+	if !sql.IsStatementTimeout(err) {
+		a.onError(TaskStateDead, err)
+		return
+	}
+	if err == context.DeadlineExceeded {
+		err = fmt.Errorf("a statement is interrupted by ApplyStatementTimeout=%v", a.mysqlContext.ApplyStatementTimeout)
+	}
+	a.onError(TaskStateRestart, fmt.Errorf("%v. restarting", err))
+}
+
+func (a *Applier) validateServerUUID() error {
+	query := `SELECT @@SERVER_UUID`
+	if err := a.db.QueryRow(query).Scan(&a.mysqlContext.MySQLServerUuid); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateConnection issues a simple can-connect to MySQL
 func (a *Applier) validateConnection(db *gosql.DB) error {
 	query := `select @@global.version`
 	if err := db.QueryRow(query).Scan(&a.mysqlContext.MySQLVersion); err != nil {
@@ -1176,14 +1786,15 @@ func (a *Applier) validateGrants() error {
 	return nil
 }
 
-// validateAndReadTimeZone potentially reads server time-zone
+// validateAndReadTimeZone reads the session time_zone, which is TimeZone if set.
 func (a *Applier) validateAndReadTimeZone() error {
-	query := `select @@global.time_zone`
-	if err := a.db.QueryRow(query).Scan(&a.mysqlContext.TimeZone); err != nil {
+	var timeZone string
+	query := `select @@session.time_zone`
+	if err := a.db.QueryRow(query).Scan(&timeZone); err != nil {
 		return err
 	}
 
-	a.logger.Printf("mysql.applier: Will use time_zone='%s' on applier", a.mysqlContext.TimeZone)
+	a.logger.Printf("mysql.applier: Will use time_zone='%s' on applier", timeZone)
 	return nil
 }
 func (a *Applier) migrateGtidExecutedV2toV3() error {
@@ -1302,6 +1913,7 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 	var tableColumns = tableItem.columns
 	span := opentracing.GlobalTracer().StartSpan("desc  buildDMLEventQuery ", opentracing.FollowsFrom(spanContext))
 	defer span.Finish()
+	tableItem.checkConn(workerIdx, a.dbs[workerIdx].Db)
 	doPrepareIfNil := func(stmts []*gosql.Stmt, query string) (*gosql.Stmt, error) {
 		var err error
 		if stmts[workerIdx] == nil {
@@ -1314,6 +1926,10 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 		return stmts[workerIdx], err
 	}
 
+	if tableItem.keyless && dmlEvent.DML != binlog.InsertDML {
+		return a.buildKeylessDMLEventQuery(dmlEvent, tableColumns)
+	}
+
 	switch dmlEvent.DML {
 	case binlog.DeleteDML:
 		{
@@ -1326,7 +1942,7 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 				if err != nil {
 					return nil, "", nil, -1, err
 				}
-				return stmt, "", uniqueKeyArgs, -1, nil
+				return stmt, query, uniqueKeyArgs, -1, nil
 			} else {
 				return nil, query, uniqueKeyArgs, -1, nil
 			}
@@ -1334,7 +1950,7 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 	case binlog.InsertDML:
 		{
 			// TODO no need to generate query string every time
-			query, sharedArgs, err := sql.BuildDMLInsertQuery(dmlEvent.DatabaseName, dmlEvent.TableName, tableColumns, tableColumns, tableColumns, dmlEvent.NewColumnValues.GetAbstractValues())
+			query, sharedArgs, err := sql.BuildDMLInsertQueryWithExprs(dmlEvent.DatabaseName, dmlEvent.TableName, tableColumns, tableColumns, tableColumns, dmlEvent.NewColumnValues.GetAbstractValues(), tableItem.columnExprs)
 			if err != nil {
 				return nil, "", nil, -1, err
 			}
@@ -1342,7 +1958,7 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 			if err != nil {
 				return nil, "", nil, -1, err
 			}
-			return stmt, "", sharedArgs, 1, err
+			return stmt, query, sharedArgs, 1, err
 		}
 	case binlog.UpdateDML:
 		{
@@ -1359,7 +1975,7 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 					return nil, "", nil, -1, err
 				}
 
-				return stmt, "", args, 0, err
+				return stmt, query, args, 0, err
 			} else {
 				return nil, query, args, 0, err
 			}
@@ -1368,12 +1984,172 @@ func (a *Applier) buildDMLEventQuery(dmlEvent binlog.DataEvent, workerIdx int, s
 	return nil, "", args, 0, fmt.Errorf("Unknown dml event type: %+v", dmlEvent.DML)
 }
 
+// buildKeylessDMLEventQuery builds the query of buildDMLEventQuery for a table without a primary key.
+// The query is not prepared, as the comparison to NULL differs by row.
+func (a *Applier) buildKeylessDMLEventQuery(dmlEvent binlog.DataEvent, tableColumns *umconf.ColumnList) (stmt *gosql.Stmt, query string, args []interface{}, rowsDelta int64, err error) {
+	limitOne := a.mysqlContext.KeylessLimitOne == nil || *a.mysqlContext.KeylessLimitOne
+	switch dmlEvent.DML {
+	case binlog.DeleteDML:
+		query, args, err = sql.BuildDMLKeylessDeleteQuery(dmlEvent.DatabaseName, dmlEvent.TableName, tableColumns,
+			dmlEvent.WhereColumnValues.GetAbstractValues(), limitOne)
+		if err != nil {
+			return nil, "", nil, -1, err
+		}
+		return nil, query, args, -1, nil
+	case binlog.UpdateDML:
+		query, args, err = sql.BuildDMLKeylessUpdateQuery(dmlEvent.DatabaseName, dmlEvent.TableName, tableColumns,
+			dmlEvent.NewColumnValues.GetAbstractValues(), dmlEvent.WhereColumnValues.GetAbstractValues(), limitOne)
+		if err != nil {
+			return nil, "", nil, -1, err
+		}
+		return nil, query, args, 0, nil
+	}
+	return nil, "", nil, 0, fmt.Errorf("unexpected dml event type on a table without primary key: %+v", dmlEvent.DML)
+}
+
+// deleteBatchEnd returns the end (exclusive) of the run of deletes starting at events[start]
+// which can be merged into one `delete ... where pk in (...)`.
+// Only deletes on the same table with a single-column primary key are merged.
+// The run never exceeds the given events, so a batch never crosses a transaction.
+func deleteBatchEnd(events []binlog.DataEvent, start int, maxBatchSize int) int {
+	end := start + 1
+	first := &events[start]
+	if first.DML != binlog.DeleteDML || maxBatchSize <= 1 {
+		return end
+	}
+	tableItem, ok := first.TableItem.(*applierTableItem)
+	if !ok || sql.GetSingleColumnPrimaryKey(tableItem.columns) == nil {
+		return end
+	}
+	for end < len(events) && end-start < maxBatchSize {
+		event := &events[end]
+		if event.DML != binlog.DeleteDML || event.TableItem != first.TableItem ||
+			event.DatabaseName != first.DatabaseName || event.TableName != first.TableName {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+func (a *Applier) applyBatchDelete(workerIdx int, gtid string, events []binlog.DataEvent) (rowsDelta int64, err error) {
+	first := &events[0]
+	tableItem := first.TableItem.(*applierTableItem)
+
+	argsList := make([][]*interface{}, len(events))
+	for i := range events {
+		argsList[i] = events[i].WhereColumnValues.GetAbstractValues()
+	}
+	query, args, err := sql.BuildDMLBatchDeleteQuery(first.DatabaseName, first.TableName, tableItem.columns, argsList)
+	if err != nil {
+		return 0, err
+	}
+
+	a.logger.Debugf("mysql.applier: batch delete %v rows on %v.%v", len(events), first.DatabaseName, first.TableName)
+	if a.appliedSQLLogger != nil {
+		rows := make([]*umconf.ColumnValues, len(events))
+		for i := range events {
+			rows[i] = events[i].WhereColumnValues
+		}
+		a.appliedSQLLogger.logStatement(gtid, first.DatabaseName, first.TableName, tableItem.columns, rows, query, args)
+	}
+	stmtCtx, cancel := a.statementContext()
+	defer cancel()
+	_, err = a.dbs[workerIdx].Db.ExecContext(stmtCtx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return -int64(len(events)), nil
+}
+
+// applySerially applies a transaction, or a piece of a big one, on worker 0 with batch.
+func (a *Applier) applySerially(ctx context.Context, batch *applyBatch, binlogEntry *binlog.BinlogEntry) error {
+	if err := a.setTableItemForBinlogEntry(binlogEntry); err != nil {
+		return err
+	}
+	return a.ApplyBinlogEvent(ctx, 0, batch, binlogEntry)
+}
+
+// rollbackApplyBatch discards the pending transaction of batch, if any.
+func (a *Applier) rollbackApplyBatch(workerIdx int, batch *applyBatch) error {
+	if !batch.isPending() {
+		return nil
+	}
+	err := batch.tx.Rollback()
+	batch.reset()
+	a.dbs[workerIdx].DbMutex.Unlock()
+	return err
+}
+
+// commitApplyBatch commits the pending transaction of batch, if any, and then
+// reports its source transactions as executed.
+func (a *Applier) commitApplyBatch(workerIdx int, batch *applyBatch) error {
+	if !batch.isPending() {
+		return nil
+	}
+	entries := batch.entries
+	err := batch.tx.Commit()
+	batch.reset()
+	a.dbs[workerIdx].DbMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	a.logger.Debugf("mysql.applier: worker %v committed %v transactions", workerIdx, len(entries))
+	a.replicationLag.committed(len(entries))
+	a.ackIncr(len(entries))
+
+	for _, binlogEntry := range entries {
+		a.mtsManager.Executed(binlogEntry)
+		if a.printTps {
+			atomic.AddUint32(&a.txLastNSeconds, 1)
+		}
+	}
+	a.onApplyBatchCommitted(entries)
+	return nil
+}
+
+// onApplyBatchCommitted advances the reported gtid set and binlog position.
+// It must only be called with committed transactions.
+func (a *Applier) onApplyBatchCommitted(entries []*binlog.BinlogEntry) {
+	if a.shutdown {
+		return
+	}
+	a.gtidSetLock.Lock()
+	defer a.gtidSetLock.Unlock()
+
+	for _, binlogEntry := range entries {
+		if p := binlogEntry.XAPrepare; p != nil {
+			a.updateGtidSet(p.GetSid(), p.SID, p.GNO)
+		}
+		a.updateGtidSet(binlogEntry.Coordinates.GetSid(), binlogEntry.Coordinates.SID, binlogEntry.Coordinates.GNO)
+		if a.mysqlContext.BinlogFile != binlogEntry.Coordinates.LogFile {
+			a.mysqlContext.BinlogFile = binlogEntry.Coordinates.LogFile
+			a.publishProgress()
+		}
+		a.mysqlContext.BinlogPos = binlogEntry.Coordinates.LogPos
+	}
+	a.updateGtidString()
+}
+
+// applyTruncate empties the table of a TRUNCATE TABLE event. If the destination refuses to truncate it
+// as it is referenced by a foreign key, the rows are deleted instead.
+func (a *Applier) applyTruncate(tx applyTx, event *binlog.DataEvent) error {
+	_, err := tx.Exec(event.Query)
+	if sql.IsTruncateIllegalFk(err) {
+		query := fmt.Sprintf("DELETE FROM %s.%s", a.quoteName(event.DatabaseName), a.quoteName(event.TableName))
+		a.logger.Warnf("mysql.applier: %v. executing %v instead", err, query)
+		_, err = tx.Exec(query)
+	}
+	return err
+}
+
 // ApplyEventQueries applies multiple DML queries onto the dest table
-func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEntry *binlog.BinlogEntry) error {
+// The transaction is added to batch, which is committed when it reaches ApplyBatchSize rows
+// or ApplyBatchTimeout. A transaction containing DDL is always committed on its own.
+func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, batch *applyBatch, binlogEntry *binlog.BinlogEntry) (err error) {
 	dbApplier := a.dbs[workerIdx]
 
 	var totalDelta int64
-	var err error
 	var spanContext opentracing.SpanContext
 	var span opentracing.Span
 	if ctx != nil {
@@ -1391,36 +2167,115 @@ func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEnt
 	}
 	txSid := binlogEntry.Coordinates.GetSid()
 
-	dbApplier.DbMutex.Lock()
-	tx, err := dbApplier.Db.BeginTx(context.Background(), &gosql.TxOptions{})
-	if err != nil {
-		return err
+	hasDDL := binlogEntry.HasDDL()
+	if hasDDL {
+		// DDL causes an implicit commit.
+		if err := a.commitApplyBatch(workerIdx, batch); err != nil {
+			return err
+		}
 	}
+	if !batch.isPending() {
+		dbApplier.DbMutex.Lock()
+		if err = a.validateConn(workerIdx, batch.idleSince); err != nil {
+			dbApplier.DbMutex.Unlock()
+			return err
+		}
+		batch.tx, err = a.beginApplyTx(dbApplier.Db)
+		if err != nil {
+			batch.reset()
+			dbApplier.DbMutex.Unlock()
+			return err
+		}
+		batch.begin = time.Now()
+	}
+	tx := batch.tx
 	defer func() {
-		span.SetTag("begin commit sql ", time.Now().UnixNano()/1e6)
-		if err := tx.Commit(); err != nil {
-			a.onError(TaskStateDead, err)
-		} else {
-			a.mtsManager.Executed(binlogEntry)
+		if err != nil {
+			// Never commit a partially applied transaction. Rolling back also discards the
+			// previous transactions in the batch, together with their gtid_executed rows.
+			if rbErr := tx.Rollback(); rbErr != nil {
+				a.logger.Warnf("mysql.applier: rollback error: %v", rbErr)
+			}
+			batch.reset()
+			dbApplier.DbMutex.Unlock()
+			return
 		}
-		if a.printTps {
-			atomic.AddUint32(&a.txLastNSeconds, 1)
+		if binlogEntry.Resync != nil {
+			// committed by applyResyncPart
+			return
+		}
+		if binlogEntry.Partial {
+			// The big transaction is committed with its last piece.
+			batch.bigTxGtid = binlogEntry.Coordinates.GetGtidForThisTx()
+			batch.nextPiece = binlogEntry.Index + 1
+			a.ackIncrPiece()
+			return
+		}
+		batch.bigTxGtid = ""
+		batch.nextPiece = 0
+		batch.add(binlogEntry)
+		if hasDDL || batch.isFull(a.mysqlContext.ApplyBatchSize, a.applyBatchTimeout, time.Now()) {
+			span.SetTag("begin commit sql ", time.Now().UnixNano()/1e6)
+			err = a.commitApplyBatch(workerIdx, batch)
+			span.SetTag("after  commit sql ", time.Now().UnixNano()/1e6)
 		}
-		span.SetTag("after  commit sql ", time.Now().UnixNano()/1e6)
-
-		dbApplier.DbMutex.Unlock()
 	}()
 	span.SetTag("begin transform binlogEvent to sql time  ", time.Now().UnixNano()/1e6)
+	if !a.disableForeignKeyChecksIncr() {
+		binlogEntry.Events = orderByTablePriority(binlogEntry.Events, a.mysqlContext.TablePriority)
+	}
+	batchEnd := 0
+	// set if a batched delete failed and OnApplyError is not halt. The rows are then deleted one by one.
+	noBatchDelete := false
 	for i, event := range binlogEntry.Events {
+		if i < batchEnd {
+			// already applied in a batched delete
+			continue
+		}
 		a.logger.Debugf("mysql.applier: ApplyBinlogEvent. gno: %v, event: %v",
 			binlogEntry.Coordinates.GNO, i)
+		if event.DML == binlog.DeleteDML && !noBatchDelete {
+			batchEnd = deleteBatchEnd(binlogEntry.Events, i, a.mysqlContext.DeleteBatchSize)
+			if batchEnd-i > 1 {
+				rowDelta, err := a.applyBatchDelete(workerIdx, binlogEntry.Coordinates.GetGtidForThisTx(),
+					binlogEntry.Events[i:batchEnd])
+				if err != nil {
+					a.logger.Errorf("mysql.applier: gtid: %s:%d, error: %v", txSid, binlogEntry.Coordinates.GNO, err)
+					a.recordError(models.ErrorTypeApply, binlogEntry.Coordinates.GetGtidForThisTx(), err)
+					if a.mysqlContext.OnApplyError == config.OnApplyErrorHalt || !sql.IsRowError(err) {
+						return err
+					}
+					// find the failing rows
+					noBatchDelete = true
+					batchEnd = i
+				} else {
+					totalDelta += rowDelta
+					continue
+				}
+			}
+		}
 		switch event.DML {
 		case binlog.NotDML:
 			var err error
 			a.logger.Debugf("mysql.applier: ApplyBinlogEvent: not dml: %v", event.Query)
 
+			if event.Savepoint {
+				if a.mysqlContext.NonTransactionalApply {
+					continue
+				}
+				// in the transaction, unlike a DDL
+				stmtCtx, cancel := a.statementContext()
+				_, err := tx.ExecContext(stmtCtx, event.Query)
+				cancel()
+				if err != nil {
+					a.logger.Errorf("mysql.applier: gtid: %s:%d, savepoint error: %v", txSid, binlogEntry.Coordinates.GNO, err)
+					return err
+				}
+				continue
+			}
+
 			if event.CurrentSchema != "" {
-				query := fmt.Sprintf("USE %s", umconf.EscapeName(event.CurrentSchema))
+				query := fmt.Sprintf("USE %s", a.quoteName(event.CurrentSchema))
 				a.logger.Debugf("mysql.applier: query: %v", query)
 				_, err = tx.Exec(query)
 				if err != nil {
@@ -1454,8 +2309,18 @@ func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEnt
 				}
 			}
 
-			_, err = tx.Exec(event.Query)
+			event.Query = a.rewriteDdl(event.Query)
+			a.appliedSQLLogger.logStatement(binlogEntry.Coordinates.GetGtidForThisTx(), event.DatabaseName,
+				event.TableName, nil, nil, event.Query, nil)
+			if event.Truncate {
+				err = a.applyTruncate(tx, &event)
+			} else {
+				_, err = tx.Exec(event.Query)
+			}
 			if err != nil {
+				// e.g. the table has been changed on the destination
+				a.recordError(models.ErrorTypeSchema, binlogEntry.Coordinates.GetGtidForThisTx(),
+					fmt.Errorf("%v. query: %v", err, utils.StrLim(event.Query, 256)))
 				if !sql.IgnoreError(err) {
 					a.logger.Errorf("mysql.applier: Exec sql error: %v", err)
 					return err
@@ -1466,6 +2331,18 @@ func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEnt
 			a.logger.Debugf("mysql.applier: Exec [%s]", event.Query)
 		default:
 			a.logger.Debugf("mysql.applier: ApplyBinlogEvent: a dml event")
+			if a.mysqlContext.ConflictResolutionColumn != "" {
+				newer, err := a.isNewerRow(tx, &event, txSid)
+				if err != nil {
+					a.logger.Errorf("mysql.applier: gtid: %s:%d, conflict check error: %v", txSid, binlogEntry.Coordinates.GNO, err)
+					return err
+				}
+				if !newer {
+					a.logger.Debugf("mysql.applier: gtid: %s:%d, skipping event %v. the destination row is newer by %v",
+						txSid, binlogEntry.Coordinates.GNO, i, a.mysqlContext.ConflictResolutionColumn)
+					continue
+				}
+			}
 			stmt, query, args, rowDelta, err := a.buildDMLEventQuery(event, workerIdx, spanContext)
 			if err != nil {
 				a.logger.Errorf("mysql.applier: Build dml query error: %v", err)
@@ -1474,16 +2351,35 @@ func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEnt
 
 			a.logger.Debugf("ApplyBinlogEvent. args: %v", args)
 
+			tableColumns := event.TableItem.(*applierTableItem).columns
+			a.appliedSQLLogger.logStatement(binlogEntry.Coordinates.GetGtidForThisTx(), event.DatabaseName, event.TableName,
+				tableColumns, []*umconf.ColumnValues{event.WhereColumnValues, event.NewColumnValues}, query, args)
+			var largeValues []*largeValue
+			if a.mysqlContext.LargeRowSize > 0 && event.DML != binlog.DeleteDML && tableColumns.Len() <= len(args) {
+				// args of an update: the new values and then the unique key ones
+				largeValues = splitLargeRow(tableColumns, args, a.mysqlContext.LargeRowSize)
+			}
+
 			var r gosql.Result
+			stmtCtx, cancel := a.statementContext()
 			if stmt != nil {
-				r, err = stmt.Exec(args...)
+				r, err = stmt.ExecContext(stmtCtx, args...)
 			} else {
-				r, err = a.dbs[workerIdx].Db.ExecContext(context.Background(), query, args...)
+				r, err = a.dbs[workerIdx].Db.ExecContext(stmtCtx, query, args...)
+			}
+			cancel()
+			if err == nil && len(largeValues) > 0 {
+				err = a.appendLargeValues(a.dbs[workerIdx].Db, event.DatabaseName, event.TableName, tableColumns,
+					args[:tableColumns.Len()], largeValues)
 			}
 
 			if err != nil {
 				a.logger.Errorf("mysql.applier: gtid: %s:%d, error: %v", txSid, binlogEntry.Coordinates.GNO, err)
-				return err
+				a.recordError(models.ErrorTypeApply, binlogEntry.Coordinates.GetGtidForThisTx(), err)
+				if err := a.handleApplyError(tx, binlogEntry, binlogEntry.Events[i:i+1], err); err != nil {
+					return err
+				}
+				continue
 			}
 			nr, err := r.RowsAffected()
 			if err != nil {
@@ -1495,10 +2391,30 @@ func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEnt
 		}
 	}
 	span.SetTag("after  transform  binlogEvent to sql  ", time.Now().UnixNano()/1e6)
+	if binlogEntry.Partial {
+		// a piece of a big transaction. The gtid is recorded with the last piece.
+		return nil
+	}
+	if binlogEntry.Resync != nil {
+		// not a transaction of the source
+		return nil
+	}
 	a.logger.Debugf("ApplyBinlogEvent. insert gno: %v", binlogEntry.Coordinates.GNO)
-	_, err = dbApplier.PsInsertExecutedGtid.Exec(binlogEntry.Coordinates.SID.Bytes(), binlogEntry.Coordinates.GNO)
+	stmtCtx, cancel := a.statementContext()
+	defer cancel()
+	_, err = dbApplier.PsInsertExecutedGtid.ExecContext(stmtCtx, binlogEntry.Coordinates.SID.Bytes(), binlogEntry.Coordinates.GNO)
 	if err != nil {
-		return err
+		if err = a.recordExecutedGtidError(binlogEntry.Coordinates.GetGtidForThisTx(), err); err != nil {
+			return err
+		}
+	}
+	if p := binlogEntry.XAPrepare; p != nil {
+		_, err = dbApplier.PsInsertExecutedGtid.ExecContext(stmtCtx, p.SID.Bytes(), p.GNO)
+		if err != nil {
+			if err = a.recordExecutedGtidError(p.GetGtidForThisTx(), err); err != nil {
+				return err
+			}
+		}
 	}
 
 	// no error
@@ -1507,13 +2423,66 @@ func (a *Applier) ApplyBinlogEvent(ctx context.Context, workerIdx int, binlogEnt
 	return nil
 }
 
-func (a *Applier) ApplyEventQueries(db *gosql.DB, entry *DumpEntry) error {
+// txBeginner is a *gosql.DB or a *gosql.Conn
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *gosql.TxOptions) (*gosql.Tx, error)
+}
+
+// execDumpSQL executes PreDumpSQL or PostDumpSQL in order.
+func (a *Applier) execDumpSQL(conn *gosql.Conn, name string, stmts []*config.DumpSQL) error {
+	for i, stmt := range stmts {
+		a.logger.Infof("mysql.applier: exec %v[%v]: %v", name, i, stmt.SQL)
+		if _, err := conn.ExecContext(context.Background(), stmt.SQL); err != nil {
+			if stmt.IgnoreError {
+				a.logger.Warnf("mysql.applier: ignore error of %v[%v]: %v", name, i, err)
+				continue
+			}
+			return fmt.Errorf("%v[%v] %v: %v", name, i, stmt.SQL, err)
+		}
+	}
+	return nil
+}
+
+// decryptRows decrypts the values of the columns flagged in entry.EncryptedColumns. It is done once: the flags
+// are cleared.
+func (a *Applier) decryptRows(entry *DumpEntry) error {
+	encrypted := false
+	for _, flag := range entry.EncryptedColumns {
+		encrypted = encrypted || flag != 0
+	}
+	if !encrypted {
+		return nil
+	}
+	if a.cipher == nil {
+		return fmt.Errorf("%v.%v has encrypted columns, and EncryptionKey is not set on the destination",
+			entry.TableSchema, entry.TableName)
+	}
+	for _, row := range entry.ValuesX {
+		for j, flag := range entry.EncryptedColumns {
+			if flag == 0 || j >= len(row) {
+				continue
+			}
+			var err error
+			if row[j], err = a.cipher.DecryptText(row[j]); err != nil {
+				return fmt.Errorf("%v.%v: %v", entry.TableSchema, entry.TableName, err)
+			}
+		}
+	}
+	entry.EncryptedColumns = nil
+	return nil
+}
+
+func (a *Applier) ApplyEventQueries(db txBeginner, entry *DumpEntry) (err error) {
 	if a.stubFullApplyDelay != 0 {
 		a.logger.Debugf("mysql.applier: stubFullApplyDelay start sleep")
 		time.Sleep(a.stubFullApplyDelay)
 		a.logger.Debugf("mysql.applier: stubFullApplyDelay end sleep")
 	}
 
+	if err := a.decryptRows(entry); err != nil {
+		return err
+	}
+
 	if entry.SystemVariablesStatement != "" {
 		for i := range a.dbs {
 			a.logger.Debugf("mysql.applier: exec sysvar query: %v", entry.SystemVariablesStatement)
@@ -1524,10 +2493,11 @@ func (a *Applier) ApplyEventQueries(db *gosql.DB, entry *DumpEntry) error {
 			}
 		}
 	}
-	if entry.SqlMode != "" {
+	sqlMode := a.sqlModeStatement(entry.SqlMode)
+	if sqlMode != "" {
 		for i := range a.dbs {
-			a.logger.Debugf("mysql.applier: exec sqlmode query: %v", entry.SqlMode)
-			_, err := a.dbs[i].Db.ExecContext(context.Background(), entry.SqlMode)
+			a.logger.Debugf("mysql.applier: exec sqlmode query: %v", sqlMode)
+			_, err := a.dbs[i].Db.ExecContext(context.Background(), sqlMode)
 			if err != nil {
 				a.logger.Errorf("mysql.applier: err exec sysvar query. err: %v", err)
 				return err
@@ -1536,21 +2506,29 @@ func (a *Applier) ApplyEventQueries(db *gosql.DB, entry *DumpEntry) error {
 	}
 
 	queries := []string{}
-	queries = append(queries, entry.SystemVariablesStatement, entry.SqlMode, entry.DbSQL)
-	queries = append(queries, entry.TbSQL...)
-	tx, err := db.Begin()
+	queries = append(queries, entry.SystemVariablesStatement, sqlMode)
+	if entry.DbSQL != "" || len(entry.TbSQL) > 0 {
+		queries = append(queries, a.adaptCreateStatements(append([]string{entry.DbSQL}, entry.TbSQL...))...)
+	}
+	tx, err := a.beginApplyTx(db)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		if err := tx.Commit(); err != nil {
-			a.onError(TaskStateDead, err)
+		if errCommit := tx.Commit(); errCommit != nil {
+			a.onError(TaskStateDead, errCommit)
+			if err == nil {
+				err = errCommit
+			}
 		}
 		atomic.AddInt64(&a.mysqlContext.TotalRowsReplay, entry.RowsCount)
 	}()
-	sessionQuery := `SET @@session.foreign_key_checks = 0`
-	if _, err := tx.Exec(sessionQuery); err != nil {
-		return err
+	if a.mysqlContext.DisableForeignKeyChecks != config.DisableForeignKeyChecksNone {
+		// Tables are loaded regardless of the references among them.
+		sessionQuery := `SET @@session.foreign_key_checks = 0`
+		if _, err := tx.Exec(sessionQuery); err != nil {
+			return err
+		}
 	}
 	execQuery := func(query string) error {
 		a.logger.Debugf("mysql.applier: Exec [%s]", utils.StrLim(query, 256))
@@ -1576,15 +2554,125 @@ func (a *Applier) ApplyEventQueries(db *gosql.DB, entry *DumpEntry) error {
 			return err
 		}
 	}
+	if len(entry.TbSQL) > 0 {
+		if err := a.checkCreatedCollations(tx, entry.TbSQL); err != nil {
+			return err
+		}
+	}
+	shardBy := config.FindShardBy(a.mysqlContext.ShardBy, entry.TableSchema, entry.TableName)
+	if shardBy != nil && len(entry.TbSQL) > 0 {
+		if err := a.createShardTables(execQuery, shardBy); err != nil {
+			return err
+		}
+	}
+
+	a.checkDumpColumnTypes(entry)
+	if err := a.replaceDumpZeroDates(entry); err != nil {
+		return err
+	}
+	// the types which the values are cast to, in the order of the values. See ColumnTypeOverride.
+	var castTypes []string
+	if len(entry.ValuesX) > 0 &&
+		config.FindColumnTypeOverride(a.mysqlContext.ColumnTypeOverride, entry.TableSchema, entry.TableName) != nil {
+		columns, _, err := a.getTableColumns(entry.TableSchema, entry.TableName)
+		if err != nil {
+			return err
+		}
+		castTypes = make([]string, columns.Len())
+		for j := range columns.Columns {
+			castTypes[j] = columns.Columns[j].CastType
+		}
+	}
+
+	if shardBy != nil && len(entry.ValuesX) > 0 {
+		shardRows, err := a.shardDumpRows(entry, shardBy)
+		if err != nil {
+			return err
+		}
+		for shard, rows := range shardRows {
+			if len(rows) == 0 {
+				continue
+			}
+			shardEntry := *entry
+			shardEntry.ValuesX = rows
+			err := a.writeDumpRows(tx, &shardEntry, shardBy.ShardTableName(shard), castTypes, execQuery)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return a.writeDumpRows(tx, entry, entry.TableName, castTypes, execQuery)
+}
+
+// writeDumpRows writes the rows of entry into tableName, which is entry.TableName, or a shard table of it.
+// The other options of the table are looked up by entry.TableName.
+func (a *Applier) writeDumpRows(tx applyTx, entry *DumpEntry, tableName string, castTypes []string,
+	execQuery func(string) error) (err error) {
+
+	// LOAD DATA does not cast the values.
+	if a.loadDataInfile && len(entry.ValuesX) > 0 && castTypes == nil {
+		loaded, err := a.loadDumpRows(tx, entry, tableName)
+		if err != nil {
+			return err
+		}
+		if loaded {
+			return nil
+		}
+	}
+
+	insertVerb := a.dumpInsertVerb(entry.TableSchema, entry.TableName)
+	insertPrefix := fmt.Sprintf(`%s into %s.%s values (`,
+		insertVerb, a.quoteName(entry.TableSchema), a.quoteName(tableName))
+	var columnExprs []*sql.ColumnExpr
+	if len(entry.ValuesX) > 0 &&
+		config.FindColumnDefault(a.mysqlContext.ColumnDefault, entry.TableSchema, entry.TableName) != nil {
+		// Name the columns, as the destination table has more than the source one.
+		var columns *umconf.ColumnList
+		if columns, columnExprs, err = a.getTableColumns(entry.TableSchema, entry.TableName); err != nil {
+			return err
+		}
+		names := columns.EscapedNames()
+		for _, expr := range columnExprs {
+			names = append(names, expr.EscapedName)
+		}
+		insertPrefix = fmt.Sprintf(`%s into %s.%s (%s) values (`,
+			insertVerb, a.quoteName(entry.TableSchema), a.quoteName(tableName), strings.Join(names, ","))
+	}
 
 	var buf bytes.Buffer
 	BufSizeLimit := 1 * 1024 * 1024 // 1MB. TODO parameterize it
 	BufSizeLimitDelta := 1024
 	buf.Grow(BufSizeLimit + BufSizeLimitDelta)
+	// columns of the table, got on the first large row
+	var largeRowColumns *umconf.ColumnList
 	for i, _ := range entry.ValuesX {
+		// The values are escaped in the query, which might double the size.
+		if a.mysqlContext.LargeRowSize > 0 && 2*dumpRowSize(entry.ValuesX[i]) > a.mysqlContext.LargeRowSize {
+			if buf.Len() > 0 {
+				err := execQuery(buf.String())
+				buf.Reset()
+				if err != nil {
+					return err
+				}
+			}
+			if largeRowColumns == nil {
+				if largeRowColumns, _, err = a.getTableColumns(entry.TableSchema, entry.TableName); err != nil {
+					return err
+				}
+			}
+			written, err := a.writeLargeDumpRow(tx, entry.TableSchema, tableName, largeRowColumns,
+				insertPrefix, columnExprs, entry.ValuesX[i])
+			if err != nil {
+				return err
+			}
+			if written {
+				continue
+			}
+		}
+
 		if buf.Len() == 0 {
-			buf.WriteString(fmt.Sprintf(`replace into %s.%s values (`,
-				umconf.EscapeName(entry.TableSchema), umconf.EscapeName(entry.TableName)))
+			buf.WriteString(insertPrefix)
 		} else {
 			buf.WriteString(",(")
 		}
@@ -1598,7 +2686,13 @@ func (a *Applier) ApplyEventQueries(db *gosql.DB, entry *DumpEntry) error {
 			}
 
 			colData := entry.ValuesX[i][j]
-			if colData != nil {
+			if colData != nil && j < len(castTypes) && castTypes[j] != "" {
+				buf.WriteString("cast('")
+				buf.WriteString(sql.EscapeValue(string(*colData)))
+				buf.WriteString("' as ")
+				buf.WriteString(castTypes[j])
+				buf.WriteByte(')')
+			} else if colData != nil {
 				buf.WriteByte('\'')
 				buf.WriteString(sql.EscapeValue(string(*colData)))
 				buf.WriteByte('\'')
@@ -1606,6 +2700,10 @@ func (a *Applier) ApplyEventQueries(db *gosql.DB, entry *DumpEntry) error {
 				buf.WriteString("NULL")
 			}
 		}
+		for _, expr := range columnExprs {
+			buf.WriteByte(',')
+			buf.WriteString(expr.Expr)
+		}
 		buf.WriteByte(')')
 
 		needInsert := (i == len(entry.ValuesX)-1) || (buf.Len() >= BufSizeLimit)
@@ -1639,6 +2737,9 @@ func (a *Applier) Stats() (*models.TaskStatistics, error) {
 			// Done copying rows. The totalRowsCopied value is the de-facto number of rows,
 			// and there is no further need to keep updating the value.
 			backlog = fmt.Sprintf("%d/%d", len(a.applyDataEntryQueue), cap(a.applyDataEntryQueue))
+			if a.delayQueue != nil {
+				backlog = fmt.Sprintf("%v, delayed: %d", backlog, a.delayQueue.len())
+			}
 		} else {
 			backlog = fmt.Sprintf("%d/%d", len(a.copyRowsQueue), cap(a.copyRowsQueue))
 		}
@@ -1678,10 +2779,30 @@ func (a *Applier) Stats() (*models.TaskStatistics, error) {
 			ApplierTxQueueSize:      len(a.applyBinlogTxQueue),
 			ApplierGroupTxQueueSize: len(a.applyBinlogGroupTxQueue),
 		},
-		Timestamp: time.Now().UTC().UnixNano(),
+		Timestamp:      time.Now().UTC().UnixNano(),
+		RecentErrors:   a.recentErrors.list(),
+		ReplicationLag: int64(a.replicationLag.get(time.Now()) / time.Second),
+		IncrAckStats: &models.IncrAckStats{
+			Transactions: atomic.LoadInt64(&a.incrAckStats.Transactions),
+			Pieces:       atomic.LoadInt64(&a.incrAckStats.Pieces),
+		},
 	}
 	if a.natsConn != nil {
 		taskResUsage.MsgStat = a.natsConn.Statistics
+		taskResUsage.NatsSubStats = a.natsSubStats()
+	}
+	if a.db != nil {
+		stats := a.db.Stats()
+		taskResUsage.ConnPoolStats = &models.ConnPoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     int64(stats.WaitDuration / time.Millisecond),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
 	}
 
 	return &taskResUsage, nil
@@ -1693,6 +2814,7 @@ func (a *Applier) ID() string {
 			ReplicateDoDb:     a.mysqlContext.ReplicateDoDb,
 			ReplicateIgnoreDb: a.mysqlContext.ReplicateIgnoreDb,
 			Gtid:              a.mysqlContext.Gtid,
+			DumpCheckpoint:    a.mysqlContext.DumpCheckpoint,
 			BinlogPos:         a.mysqlContext.BinlogPos,
 			BinlogFile:        a.mysqlContext.BinlogFile,
 			NatsAddr:          a.mysqlContext.NatsAddr,
@@ -1708,8 +2830,13 @@ func (a *Applier) ID() string {
 	return string(data)
 }
 
+// disableForeignKeyChecksIncr is true if the workers of the incremental replication run with foreign_key_checks=0.
+func (a *Applier) disableForeignKeyChecksIncr() bool {
+	return a.mysqlContext.DisableForeignKeyChecks == config.DisableForeignKeyChecksAll
+}
+
 func (a *Applier) updateGtidString() {
-	a.mysqlContext.Gtid = a.gtidSet.String()
+	a.mysqlContext.Gtid = base.GtidSetString(a.gtidSet)
 	a.logger.Debugf("applier updateGtidString %v", a.mysqlContext.Gtid)
 }
 
@@ -1717,6 +2844,20 @@ func (a *Applier) onError(state int, err error) {
 	if a.shutdown {
 		return
 	}
+	if state == TaskStateDead && sql.IsDiskFull(err) {
+		a.pauseOnDiskFull(err)
+		return
+	}
+	if pauseErr := a.diskFull.get(); pauseErr != nil {
+		a.logger.Warnf("mysql.applier: paused on: %v. ignoring: %v", pauseErr, err)
+		return
+	}
+	if err != nil {
+		a.recordError(models.ErrorTypeTask, a.mysqlContext.Gtid, err)
+		if state == TaskStateDead {
+			a.failure.set(err)
+		}
+	}
 	switch state {
 	case TaskStateComplete:
 		a.logger.Printf("mysql.applier: Done migrating")
@@ -1762,6 +2903,14 @@ func (a *Applier) Shutdown() error {
 	if err := sql.CloseConns(a.dbs...); err != nil {
 		return err
 	}
+	if err := a.deadLetter.Close(); err != nil {
+		return err
+	}
+	if a.delayQueue != nil {
+		if err := a.delayQueue.close(); err != nil {
+			return err
+		}
+	}
 
 	//close(a.applyBinlogTxQueue)
 	//close(a.applyBinlogGroupTxQueue)
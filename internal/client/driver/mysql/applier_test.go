@@ -7,36 +7,55 @@
 package mysql
 
 import (
+	"context"
 	gosql "database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
 	"reflect"
-	"sync"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
 	"github.com/actiontech/dtle/internal/config"
-	log "github.com/actiontech/dtle/internal/logger"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
 	"github.com/actiontech/dtle/internal/models"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
 	gonats "github.com/nats-io/go-nats"
+	"github.com/opentracing/opentracing-go"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewApplier(t *testing.T) {
 	type args struct {
-		subject string
-		tp      string
-		cfg     *config.MySQLDriverConfig
-		logger  *log.Logger
+		ctx    *common.ExecContext
+		cfg    *config.MySQLDriverConfig
+		logger *logrus.Logger
 	}
 	tests := []struct {
-		name string
-		args args
-		want *Applier
+		name    string
+		args    args
+		want    *Applier
+		wantErr bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewApplier(tt.args.subject, tt.args.tp, tt.args.cfg, tt.args.logger); !reflect.DeepEqual(got, tt.want) {
+			got, err := NewApplier(tt.args.ctx, tt.args.cfg, tt.args.logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewApplier() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewApplier() = %v, want %v", got, tt.want)
 			}
 		})
@@ -57,28 +76,6 @@ func TestApplier_Run(t *testing.T) {
 	}
 }
 
-func TestApplier_onApplyTxStruct(t *testing.T) {
-	type args struct {
-		dbApplier *sql.DB
-		binlogTx  *binlog.BinlogTx
-	}
-	tests := []struct {
-		name    string
-		a       *Applier
-		args    args
-		wantErr bool
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.a.onApplyTxStruct(tt.args.dbApplier, tt.args.binlogTx); (err != nil) != tt.wantErr {
-				t.Errorf("Applier.onApplyTxStruct() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
 func TestApplier_executeWriteFuncs(t *testing.T) {
 	tests := []struct {
 		name string
@@ -167,16 +164,16 @@ func TestApplier_initDBConnections(t *testing.T) {
 
 func TestApplier_validateServerUUID(t *testing.T) {
 	tests := []struct {
-		name string
-		a    *Applier
-		want string
+		name    string
+		a       *Applier
+		wantErr bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.a.validateServerUUID(); got != tt.want {
-				t.Errorf("Applier.validateServerUUID() = %v, want %v", got, tt.want)
+			if err := tt.a.validateServerUUID(); (err != nil) != tt.wantErr {
+				t.Errorf("Applier.validateServerUUID() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -222,7 +219,9 @@ func TestApplier_validateAndReadTimeZone(t *testing.T) {
 
 func TestApplier_buildDMLEventQuery(t *testing.T) {
 	type args struct {
-		dmlEvent binlog.DataEvent
+		dmlEvent    binlog.DataEvent
+		workerIdx   int
+		spanContext opentracing.SpanContext
 	}
 	tests := []struct {
 		name          string
@@ -237,7 +236,7 @@ func TestApplier_buildDMLEventQuery(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotQuery, gotArgs, gotRowsDelta, err := tt.a.buildDMLEventQuery(tt.args.dmlEvent)
+			_, gotQuery, gotArgs, gotRowsDelta, err := tt.a.buildDMLEventQuery(tt.args.dmlEvent, tt.args.workerIdx, tt.args.spanContext)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Applier.buildDMLEventQuery() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -257,8 +256,10 @@ func TestApplier_buildDMLEventQuery(t *testing.T) {
 
 func TestApplier_ApplyBinlogEvent(t *testing.T) {
 	type args struct {
-		db     *gosql.DB
-		events [](binlog.DataEvent)
+		ctx         context.Context
+		workerIdx   int
+		batch       *applyBatch
+		binlogEntry *binlog.BinlogEntry
 	}
 	tests := []struct {
 		name    string
@@ -270,7 +271,7 @@ func TestApplier_ApplyBinlogEvent(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.a.ApplyBinlogEvent(tt.args.db, tt.args.events); (err != nil) != tt.wantErr {
+			if err := tt.a.ApplyBinlogEvent(tt.args.ctx, tt.args.workerIdx, tt.args.batch, tt.args.binlogEntry); (err != nil) != tt.wantErr {
 				t.Errorf("Applier.ApplyBinlogEvent() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -341,7 +342,8 @@ func TestApplier_ID(t *testing.T) {
 
 func TestApplier_onError(t *testing.T) {
 	type args struct {
-		err error
+		state int
+		err   error
 	}
 	tests := []struct {
 		name string
@@ -352,7 +354,7 @@ func TestApplier_onError(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.a.onError(tt.args.err)
+			tt.a.onError(tt.args.state, tt.args.err)
 		})
 	}
 }
@@ -391,16 +393,13 @@ func TestApplier_Shutdown(t *testing.T) {
 	}
 }
 
-func TestApplier_onApplyTxStructWithSetGtid(t *testing.T) {
+func TestApplier_validateGrants(t *testing.T) {
 	type fields struct {
-		logger                  *log.Entry
+		logger                  *logrus.Entry
 		subject                 string
-		tp                      string
 		mysqlContext            *config.MySQLDriverConfig
-		dbs                     []*sql.DB
-		singletonDB             *gosql.DB
-		totalRowCount           int
-		applyRowCount           int
+		dbs                     []*sql.Conn
+		db                      *gosql.DB
 		rowCopyComplete         chan bool
 		rowCopyCompleteFlag     int64
 		copyRowsQueue           chan *DumpEntry
@@ -410,19 +409,12 @@ func TestApplier_onApplyTxStructWithSetGtid(t *testing.T) {
 		lastAppliedBinlogTx     *binlog.BinlogTx
 		natsConn                *gonats.Conn
 		waitCh                  chan *models.WaitResult
-		wg                      sync.WaitGroup
 		shutdown                bool
 		shutdownCh              chan struct{}
-		shutdownLock            sync.Mutex
-	}
-	type args struct {
-		dbApplier *sql.DB
-		binlogTx  *binlog.BinlogTx
 	}
 	tests := []struct {
 		name    string
 		fields  fields
-		args    args
 		wantErr bool
 	}{
 		// TODO: Add test cases.
@@ -432,12 +424,9 @@ func TestApplier_onApplyTxStructWithSetGtid(t *testing.T) {
 			a := &Applier{
 				logger:                  tt.fields.logger,
 				subject:                 tt.fields.subject,
-				tp:                      tt.fields.tp,
 				mysqlContext:            tt.fields.mysqlContext,
 				dbs:                     tt.fields.dbs,
-				singletonDB:             tt.fields.singletonDB,
-				totalRowCount:           tt.fields.totalRowCount,
-				applyRowCount:           tt.fields.applyRowCount,
+				db:                      tt.fields.db,
 				rowCopyComplete:         tt.fields.rowCopyComplete,
 				rowCopyCompleteFlag:     tt.fields.rowCopyCompleteFlag,
 				copyRowsQueue:           tt.fields.copyRowsQueue,
@@ -447,113 +436,904 @@ func TestApplier_onApplyTxStructWithSetGtid(t *testing.T) {
 				lastAppliedBinlogTx:     tt.fields.lastAppliedBinlogTx,
 				natsConn:                tt.fields.natsConn,
 				waitCh:                  tt.fields.waitCh,
-				wg:                      tt.fields.wg,
 				shutdown:                tt.fields.shutdown,
 				shutdownCh:              tt.fields.shutdownCh,
-				shutdownLock:            tt.fields.shutdownLock,
 			}
-			if err := a.onApplyTxStructWithSetGtid(tt.args.dbApplier, tt.args.binlogTx); (err != nil) != tt.wantErr {
-				t.Errorf("Applier.onApplyTxStructWithSetGtid() error = %v, wantErr %v", err, tt.wantErr)
+			if err := a.validateGrants(); (err != nil) != tt.wantErr {
+				t.Errorf("Applier.validateGrants() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestApplier_validateGrants(t *testing.T) {
-	type fields struct {
-		logger                  *log.Entry
-		subject                 string
-		tp                      string
-		mysqlContext            *config.MySQLDriverConfig
-		dbs                     []*sql.DB
-		singletonDB             *gosql.DB
-		totalRowCount           int
-		applyRowCount           int
-		rowCopyComplete         chan bool
-		rowCopyCompleteFlag     int64
-		copyRowsQueue           chan *DumpEntry
-		applyDataEntryQueue     chan *binlog.BinlogEntry
-		applyBinlogTxQueue      chan *binlog.BinlogTx
-		applyBinlogGroupTxQueue chan []*binlog.BinlogTx
-		lastAppliedBinlogTx     *binlog.BinlogTx
-		natsConn                *gonats.Conn
-		waitCh                  chan *models.WaitResult
-		wg                      sync.WaitGroup
-		shutdown                bool
-		shutdownCh              chan struct{}
-		shutdownLock            sync.Mutex
+func TestApplier_onApplyTxStructWithSuper(t *testing.T) {
+	type args struct {
+		dbApplier *sql.Conn
+		binlogTx  *binlog.BinlogTx
 	}
 	tests := []struct {
 		name    string
-		fields  fields
+		a       *Applier
+		args    args
 		wantErr bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			a := &Applier{
-				logger:                  tt.fields.logger,
-				subject:                 tt.fields.subject,
-				tp:                      tt.fields.tp,
-				mysqlContext:            tt.fields.mysqlContext,
-				dbs:                     tt.fields.dbs,
-				singletonDB:             tt.fields.singletonDB,
-				totalRowCount:           tt.fields.totalRowCount,
-				applyRowCount:           tt.fields.applyRowCount,
-				rowCopyComplete:         tt.fields.rowCopyComplete,
-				rowCopyCompleteFlag:     tt.fields.rowCopyCompleteFlag,
-				copyRowsQueue:           tt.fields.copyRowsQueue,
-				applyDataEntryQueue:     tt.fields.applyDataEntryQueue,
-				applyBinlogTxQueue:      tt.fields.applyBinlogTxQueue,
-				applyBinlogGroupTxQueue: tt.fields.applyBinlogGroupTxQueue,
-				lastAppliedBinlogTx:     tt.fields.lastAppliedBinlogTx,
-				natsConn:                tt.fields.natsConn,
-				waitCh:                  tt.fields.waitCh,
-				wg:                      tt.fields.wg,
-				shutdown:                tt.fields.shutdown,
-				shutdownCh:              tt.fields.shutdownCh,
-				shutdownLock:            tt.fields.shutdownLock,
-			}
-			if err := a.validateGrants(); (err != nil) != tt.wantErr {
-				t.Errorf("Applier.validateGrants() error = %v, wantErr %v", err, tt.wantErr)
+			if err := tt.a.onApplyTxStructWithSuper(tt.args.dbApplier, tt.args.binlogTx); (err != nil) != tt.wantErr {
+				t.Errorf("Applier.onApplyTxStructWithSuper() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestApplier_onApplyTxStructWithSuper(t *testing.T) {
-	type args struct {
-		dbApplier *sql.DB
-		binlogTx  *binlog.BinlogTx
+func TestApplyBatch_isFull(t *testing.T) {
+	begin := time.Unix(1500000000, 0)
+	newEntry := func(nRows int) *binlog.BinlogEntry {
+		entry := &binlog.BinlogEntry{}
+		for i := 0; i < nRows; i++ {
+			entry.Events = append(entry.Events, binlog.DataEvent{DML: binlog.InsertDML})
+		}
+		return entry
 	}
 	tests := []struct {
-		name    string
-		a       *Applier
-		args    args
-		wantErr bool
+		name      string
+		entries   []*binlog.BinlogEntry
+		batchSize int
+		timeout   time.Duration
+		elapsed   time.Duration
+		want      bool
 	}{
-		// TODO: Add test cases.
+		{"empty", nil, 1, 100 * time.Millisecond, 0, false},
+		{"default size commits each tx", []*binlog.BinlogEntry{newEntry(1)}, 1, 100 * time.Millisecond, 0, true},
+		{"below size and timeout", []*binlog.BinlogEntry{newEntry(3), newEntry(4)}, 10, 100 * time.Millisecond, 50 * time.Millisecond, false},
+		{"size reached", []*binlog.BinlogEntry{newEntry(3), newEntry(4), newEntry(3)}, 10, 100 * time.Millisecond, 0, true},
+		{"size exceeded by a whole tx", []*binlog.BinlogEntry{newEntry(8), newEntry(5)}, 10, 100 * time.Millisecond, 0, true},
+		{"timeout reached", []*binlog.BinlogEntry{newEntry(1)}, 10, 100 * time.Millisecond, 100 * time.Millisecond, true},
+		{"ddl is not counted as a row", []*binlog.BinlogEntry{{Events: []binlog.DataEvent{{DML: binlog.NotDML}}}}, 1, 100 * time.Millisecond, 0, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.a.onApplyTxStructWithSuper(tt.args.dbApplier, tt.args.binlogTx); (err != nil) != tt.wantErr {
-				t.Errorf("Applier.onApplyTxStructWithSuper() error = %v, wantErr %v", err, tt.wantErr)
+			b := &applyBatch{}
+			if len(tt.entries) > 0 {
+				b.tx = &gosql.Tx{}
+				b.begin = begin
+			}
+			for _, entry := range tt.entries {
+				b.add(entry)
+			}
+			if got := b.isFull(tt.batchSize, tt.timeout, begin.Add(tt.elapsed)); got != tt.want {
+				t.Errorf("applyBatch.isFull() = %v, want %v", got, tt.want)
+			}
+			if got, want := len(b.entries), len(tt.entries); got != want {
+				t.Errorf("len(applyBatch.entries) = %v, want %v", got, want)
 			}
 		})
 	}
 }
 
-func TestApplier_Test008ProvidesFlowControlToThrottleOverSending(t *testing.T) {
+func TestApplyBatch_remaining(t *testing.T) {
+	begin := time.Unix(1500000000, 0)
+	b := &applyBatch{tx: &gosql.Tx{}, begin: begin}
+	if got := b.remaining(100*time.Millisecond, begin.Add(30*time.Millisecond)); got != 70*time.Millisecond {
+		t.Errorf("applyBatch.remaining() = %v, want %v", got, 70*time.Millisecond)
+	}
+	if got := b.remaining(100*time.Millisecond, begin.Add(time.Second)); got != 0 {
+		t.Errorf("applyBatch.remaining() = %v, want 0", got)
+	}
+	b.reset()
+	if b.isPending() || b.nRows != 0 || len(b.entries) != 0 {
+		t.Errorf("applyBatch.reset() did not clear the batch")
+	}
+}
+
+// TestApplier_CrashAfterCommitBeforeAck simulates a crash after a transaction is committed on the
+// destination but before it is acked or checkpointed. The redelivered transaction must be
+// recognized as executed by a new applier, while an uncommitted one must not.
+func TestApplier_CrashAfterCommitBeforeAck(t *testing.T) {
+	skipWithoutMySQL(t)
+	jobId := uuid.NewV4().String()
+	sid := uuid.NewV4()
+	newApplier := func() *Applier {
+		cfg := &config.MySQLDriverConfig{
+			ConnectionConfig: &umconf.ConnectionConfig{
+				Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+			ApplyBatchSize: 10,
+		}
+		a, err := NewApplier(&common.ExecContext{Subject: jobId}, cfg, logrus.New())
+		if err != nil {
+			t.Fatalf("NewApplier() error = %v", err)
+		}
+		if err := a.initDBConnections(); err != nil {
+			t.Skipf("no mysql available: %v", err)
+		}
+		return a
+	}
+	newEntry := func(gno int64) *binlog.BinlogEntry {
+		var id interface{} = gno
+		return &binlog.BinlogEntry{
+			Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: gno},
+			Events: []binlog.DataEvent{{
+				DatabaseName:    "dtle_test",
+				TableName:       "t_crash",
+				DML:             binlog.InsertDML,
+				NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&id}},
+			}},
+		}
+	}
+	apply := func(a *Applier, entry *binlog.BinlogEntry) {
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			t.Fatalf("ApplyBinlogEvent() error = %v", err)
+		}
+	}
+
+	a1 := newApplier()
+	if _, err := a1.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_crash; create table dtle_test.t_crash (id bigint primary key)"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+	apply(a1, newEntry(1))
+	if err := a1.commitApplyBatch(0, a1.applyBatches[0]); err != nil {
+		t.Fatalf("commitApplyBatch() error = %v", err)
+	}
+	// gno 2 is still in the pending batch when the applier crashes.
+	apply(a1, newEntry(2))
+	a1.applyBatches[0].tx.Rollback()
+
+	a2 := newApplier()
+	for _, tt := range []struct {
+		gno  int64
+		want bool
+	}{{1, true}, {2, false}} {
+		executed, _, err := a2.checkExecuted(newEntry(tt.gno))
+		if err != nil {
+			t.Fatalf("checkExecuted() error = %v", err)
+		}
+		if executed != tt.want {
+			t.Errorf("checkExecuted(gno %v) = %v, want %v", tt.gno, executed, tt.want)
+		}
+	}
+	var count int
+	if err := a2.db.QueryRow("select count(*) from dtle_test.t_crash").Scan(&count); err != nil {
+		t.Fatalf("count error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("rows = %v, want 1", count)
+	}
+}
+
+func TestApplier_onApplyError(t *testing.T) {
 	tests := []struct {
 		name string
-		a    *Applier
+		err  error
+		want int
 	}{
-		// TODO: Add test cases.
+		{"ApplyStatementTimeout", context.DeadlineExceeded, TaskStateRestart},
+		{"lock wait timeout", &mysqldriver.MySQLError{Number: sql.ErrLockWaitTimeout}, TaskStateRestart},
+		{"max_execution_time", &mysqldriver.MySQLError{Number: sql.ErrQueryTimeout}, TaskStateRestart},
+		{"row error", &mysqldriver.MySQLError{Number: sql.ErrDupEntry}, TaskStateDead},
+		{"other error", fmt.Errorf("invalid connection"), TaskStateDead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()},
+				&config.MySQLDriverConfig{ApplyStatementTimeout: 3}, logrus.New())
+			if err != nil {
+				t.Fatalf("NewApplier() error = %v", err)
+			}
+			a.onApplyError(tt.err)
+			result := <-a.waitCh
+			if result.ExitCode != tt.want || result.Err == nil {
+				t.Errorf("wait result = %+v, want exit code %v", result, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplier_ApplyStatementTimeout applies a row locked by another session. The statement is interrupted by
+// ApplyStatementTimeout, long before innodb_lock_wait_timeout. Nothing is committed, and the transaction is
+// applied again once the task has restarted.
+func TestApplier_ApplyStatementTimeout(t *testing.T) {
+	skipWithoutMySQL(t)
+	jobId := uuid.NewV4().String()
+	sid := uuid.NewV4()
+	newApplier := func() *Applier {
+		cfg := &config.MySQLDriverConfig{
+			ConnectionConfig: &umconf.ConnectionConfig{
+				Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+			ApplyBatchSize:        1,
+			ApplyStatementTimeout: 1,
+		}
+		a, err := NewApplier(&common.ExecContext{Subject: jobId}, cfg, logrus.New())
+		if err != nil {
+			t.Fatalf("NewApplier() error = %v", err)
+		}
+		if err := a.initDBConnections(); err != nil {
+			t.Skipf("no mysql available: %v", err)
+		}
+		return a
+	}
+	var id interface{} = int64(1)
+	entry := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: 1},
+		Events: []binlog.DataEvent{{
+			DatabaseName:    "dtle_test",
+			TableName:       "t_timeout",
+			DML:             binlog.InsertDML,
+			NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&id}},
+		}},
+	}
+	apply := func(a *Applier) error {
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+		}
+		return a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry)
+	}
+
+	a1 := newApplier()
+	if _, err := a1.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_timeout; create table dtle_test.t_timeout (id bigint primary key)"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+	// another session holds the lock of the row
+	lockDB, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lockDB.Close()
+	lockTx, err := lockDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockTx.Exec("insert into dtle_test.t_timeout values (1)"); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = apply(a1)
+	if !sql.IsStatementTimeout(err) {
+		t.Fatalf("ApplyBinlogEvent() error = %v, want a statement timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the statement is interrupted after %v, want about 1s", elapsed)
+	}
+	a1.onApplyError(err)
+	if result := <-a1.waitCh; result.ExitCode != TaskStateRestart || !result.ShouldRestart() {
+		t.Fatalf("wait result = %+v, want a restart", result)
+	}
+
+	if err := lockTx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	a2 := newApplier()
+	defer a2.Shutdown()
+	if executed, _, err := a2.checkExecuted(entry); err != nil || executed {
+		t.Fatalf("checkExecuted() = %v, %v. want the interrupted transaction not executed", executed, err)
+	}
+	if err := apply(a2); err != nil {
+		t.Fatalf("ApplyBinlogEvent() after the restart error = %v", err)
+	}
+	if a2.applyBatches[0].isPending() {
+		t.Errorf("the transaction is not committed with ApplyBatchSize 1")
+	}
+	var count int
+	if err := a2.db.QueryRow("select count(*) from dtle_test.t_timeout").Scan(&count); err != nil {
+		t.Fatalf("count error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("rows = %v, want 1", count)
+	}
+}
+
+func TestApplier_execDumpSQL(t *testing.T) {
+	skipWithoutMySQL(t)
+	// fail fast if the server is unreachable
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=1s&readTimeout=2s&writeTimeout=2s")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.PingContext(ctx); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+
+	a := &Applier{logger: logrus.NewEntry(logrus.New())}
+	// Statements run in order on the same session.
+	err = a.execDumpSQL(conn, "PreDumpSQL", []*config.DumpSQL{
+		{SQL: "set @dtle_test = 1"},
+		{SQL: "no such statement", IgnoreError: true},
+		{SQL: "set @dtle_test = @dtle_test * 10 + 2"},
+	})
+	if err != nil {
+		t.Fatalf("Applier.execDumpSQL() error = %v", err)
+	}
+	var got int
+	if err := conn.QueryRowContext(context.Background(), "select @dtle_test").Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != 12 {
+		t.Errorf("@dtle_test = %v, want 12", got)
+	}
+
+	err = a.execDumpSQL(conn, "PostDumpSQL", []*config.DumpSQL{
+		{SQL: "no such statement"},
+		{SQL: "set @dtle_test = 0"},
+	})
+	if err == nil {
+		t.Errorf("Applier.execDumpSQL() expects an error")
+	}
+	if err := conn.QueryRowContext(context.Background(), "select @dtle_test").Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != 12 {
+		t.Errorf("@dtle_test = %v after a failed statement, want 12", got)
+	}
+}
+
+func TestApplier_initDBConnections_badDisableForeignKeyChecks(t *testing.T) {
+	a := &Applier{
+		logger:       logrus.NewEntry(logrus.New()),
+		mysqlContext: &config.MySQLDriverConfig{DisableForeignKeyChecks: "always"},
+	}
+	err := a.initDBConnections()
+	if err == nil || !strings.Contains(err.Error(), "DisableForeignKeyChecks=always") {
+		t.Errorf("Applier.initDBConnections() error = %v, want a bad job argument", err)
+	}
+}
+
+func TestCreateConns_disableForeignKeyChecks(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	var global int
+	if err := db.QueryRow("select @@global.foreign_key_checks").Scan(&global); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+
+	// The connections are returned to the pool afterwards. Those with foreign_key_checks=0 go last.
+	for _, disable := range []bool{false, true} {
+		conns, err := sql.CreateConns(db, 2, disable)
+		if err != nil {
+			t.Fatalf("CreateConns(%v) error = %v", disable, err)
+		}
+		want := global
+		if disable {
+			want = 0
+		}
+		for i, conn := range conns {
+			var got int
+			err := conn.Db.QueryRowContext(context.Background(), "select @@session.foreign_key_checks").Scan(&got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("CreateConns(%v): foreign_key_checks of conn %v = %v, want %v", disable, i, got, want)
+			}
+			conn.Db.Close()
+		}
+	}
+}
+
+func TestApplier_applyTruncate(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	_, err = db.Exec("drop database if exists dtle_test_truncate;" +
+		"create database dtle_test_truncate;" +
+		"create table dtle_test_truncate.parent (id int primary key);" +
+		"create table dtle_test_truncate.child (id int primary key, pid int," +
+		"  foreign key (pid) references dtle_test_truncate.parent (id));" +
+		"insert into dtle_test_truncate.parent values (1), (2);" +
+		"insert into dtle_test_truncate.child values (1, 1), (2, 2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("drop database if exists dtle_test_truncate")
+
+	a := &Applier{logger: logrus.NewEntry(logrus.New())}
+	// The child table is truncated. The parent one is referenced by a foreign key, and its rows are deleted.
+	for _, table := range []string{"child", "parent"} {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(context.Background(), "set @@session.foreign_key_checks = 1"); err != nil {
+			t.Fatal(err)
+		}
+		tx, err := conn.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := binlog.NewTruncateEvent("dtle_test_truncate", "dtle_test_truncate", table)
+		if err := a.applyTruncate(tx, &event); err != nil {
+			t.Fatalf("applyTruncate(%v) error = %v", table, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+
+		var count int
+		if err := db.QueryRow("select count(*) from dtle_test_truncate." + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%v has %v rows after applyTruncate(), want 0", table, count)
+		}
+	}
+}
+
+func TestApplier_handleApplyError(t *testing.T) {
+	sid := uuid.NewV4()
+	entry := &binlog.BinlogEntry{Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: 42}}
+	v1, v2 := interface{}(int64(1)), interface{}("a")
+	events := []binlog.DataEvent{{
+		DatabaseName:    "db1",
+		TableName:       "tb1",
+		DML:             binlog.InsertDML,
+		NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2, nil}},
+	}}
+	rowErr := &mysqldriver.MySQLError{Number: sql.ErrDupEntry, Message: "Duplicate entry '1' for key 'PRIMARY'"}
+	deadlockErr := &mysqldriver.MySQLError{Number: sql.ErrLockDeadlock, Message: "Deadlock found"}
+
+	dir, err := ioutil.TempDir("", "deadletter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		policy   string
+		applyErr error
+		wantErr  bool
+		wantRows int
+	}{
+		{"halt", config.OnApplyErrorHalt, rowErr, true, 0},
+		{"skip", config.OnApplyErrorSkip, rowErr, false, 0},
+		{"deadletter", config.OnApplyErrorDeadLetter, rowErr, false, 1},
+		{"deadletter on deadlock", config.OnApplyErrorDeadLetter, deadlockErr, true, 0},
+		{"deadletter on connection error", config.OnApplyErrorDeadLetter, mysqldriver.ErrInvalidConn, true, 0},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.a.Test008ProvidesFlowControlToThrottleOverSending()
+			a := &Applier{
+				logger:  logrus.NewEntry(logrus.New()),
+				execCtx: &common.ExecContext{StateDir: dir},
+				subject: "job1",
+				mysqlContext: &config.MySQLDriverConfig{
+					OnApplyError:   tt.policy,
+					DeadLetterFile: path.Join(dir, tt.name+".jsonl"),
+				},
+			}
+			if err := a.initApplyErrorPolicy(); err != nil {
+				t.Fatalf("Applier.initApplyErrorPolicy() error = %v", err)
+			}
+			err := a.handleApplyError(nil, entry, events, tt.applyErr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Applier.handleApplyError() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := a.deadLetter.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			bs, _ := ioutil.ReadFile(path.Join(dir, tt.name+".jsonl"))
+			var rows []*DeadLetterRow
+			for _, line := range strings.Split(strings.TrimSpace(string(bs)), "\n") {
+				if line == "" {
+					continue
+				}
+				row := &DeadLetterRow{}
+				if err := json.Unmarshal([]byte(line), row); err != nil {
+					t.Fatalf("json.Unmarshal() error = %v", err)
+				}
+				rows = append(rows, row)
+			}
+			if len(rows) != tt.wantRows {
+				t.Fatalf("got %v dead letter rows, want %v", len(rows), tt.wantRows)
+			}
+			for _, row := range rows {
+				if row.JobID != "job1" || row.Gtid != sid.String()+":42" || row.Schema != "db1" || row.Table != "tb1" ||
+					row.DML != binlog.InsertDML || row.Error != rowErr.Error() {
+					t.Errorf("dead letter row = %+v", row)
+				}
+				if want := []interface{}{float64(1), "a", nil}; !reflect.DeepEqual(row.Values, want) {
+					t.Errorf("dead letter row values = %v, want %v", row.Values, want)
+				}
+			}
 		})
 	}
+
+	a := &Applier{logger: logrus.NewEntry(logrus.New()), mysqlContext: &config.MySQLDriverConfig{OnApplyError: "ignore"}}
+	if err := a.initApplyErrorPolicy(); err == nil {
+		t.Errorf("Applier.initApplyErrorPolicy() expects an error for a bad policy")
+	}
+}
+
+func TestApplier_validateConn(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		ParallelWorkers: 2,
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer a.Shutdown()
+
+	connectionID := func() (id int64) {
+		if err := a.dbs[0].Db.QueryRowContext(context.Background(), "select connection_id()").Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	id := connectionID()
+	// Simulates a restart of the destination.
+	if _, err := a.db.Exec(fmt.Sprintf("kill %v", id)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recently used connections are not validated.
+	if err := a.validateConn(0, time.Now()); err != nil {
+		t.Fatalf("Applier.validateConn() error = %v", err)
+	}
+	if err := a.validateConn(0, time.Now().Add(-connValidateIdleTime)); err != nil {
+		t.Fatalf("Applier.validateConn() error = %v", err)
+	}
+	if newID := connectionID(); newID == id {
+		t.Errorf("connection is not replaced")
+	}
+	if _, err := a.dbs[0].PsDeleteExecutedGtid.Exec(uuid.NewV4().Bytes()); err != nil {
+		t.Errorf("statements are not prepared on the new connection: %v", err)
+	}
+	if stats, _ := a.Stats(); stats.ConnPoolStats == nil || stats.ConnPoolStats.MaxOpenConnections != 12 {
+		t.Errorf("ConnPoolStats = %+v, want MaxOpenConnections 12", stats.ConnPoolStats)
+	}
+}
+
+func TestApplierTableItem_checkConn(t *testing.T) {
+	conn1, conn2 := &gosql.Conn{}, &gosql.Conn{}
+	item := newApplierTableItem(2)
+	item.checkConn(0, conn1)
+	item.checkConn(1, conn1)
+	stmt := &gosql.Stmt{}
+	item.psInsert[1] = stmt
+
+	// the connection of worker 0 is replaced
+	item.checkConn(0, conn2)
+	item.checkConn(1, conn1)
+	if item.conns[0] != conn2 || item.conns[1] != conn1 {
+		t.Errorf("applierTableItem.conns = %v", item.conns)
+	}
+	if item.psInsert[1] != stmt {
+		t.Errorf("statements of another worker are discarded")
+	}
+}
+
+func TestApplier_TimeZone(t *testing.T) {
+	skipWithoutMySQL(t)
+	connectionConfig := &umconf.ConnectionConfig{
+		Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"}
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: connectionConfig,
+		ParallelWorkers:  1,
+		MaxOpenConns:     4,
+		MaxIdleConns:     4,
+		TimeZone:         "+08:00",
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer a.Shutdown()
+
+	// The source session is in another zone than the job.
+	source, err := sql.CreateDB(sql.UriWithTimeZone(connectionConfig.GetDBUri(), "-05:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	_, err = source.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_tz_src, dtle_test.t_tz_dst;" +
+		"create table dtle_test.t_tz_src (id int primary key, ts timestamp null);" +
+		"create table dtle_test.t_tz_dst (id int primary key, ts timestamp null);" +
+		"insert into dtle_test.t_tz_src values (1, '2020-01-01 00:00:00')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want int64
+	if err := source.QueryRow("select unix_timestamp(ts) from dtle_test.t_tz_src").Scan(&want); err != nil {
+		t.Fatal(err)
+	}
+
+	// full copy: the value is dumped in TimeZone.
+	dumpDB, err := sql.CreateDB(sql.UriWithTimeZone(connectionConfig.GetSingletonDBUri(), cfg.TimeZone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dumpDB.Close()
+	var dumped string
+	if err := dumpDB.QueryRow("select cast(ts as char) from dtle_test.t_tz_src").Scan(&dumped); err != nil {
+		t.Fatal(err)
+	}
+	if dumped != "2020-01-01 13:00:00" {
+		t.Errorf("dumped value = %v, want 2020-01-01 13:00:00", dumped)
+	}
+	// incremental copy: the binlog value (seconds since epoch) is formatted in TimeZone.
+	loc, err := cfg.TimeZoneLocation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := time.Unix(want, 0).In(loc).Format("2006-01-02 15:04:05")
+
+	for id, value := range []string{dumped, replayed} {
+		if _, err := a.db.Exec("insert into dtle_test.t_tz_dst values (?, ?)", id, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := source.Query("select id, unix_timestamp(ts) from dtle_test.t_tz_dst order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, got int64
+		if err := rows.Scan(&id, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("row %v: ts = %v on the destination, want %v", id, time.Unix(got, 0).UTC(), time.Unix(want, 0).UTC())
+		}
+	}
+}
+
+func TestApplier_keylessTable(t *testing.T) {
+	skipWithoutMySQL(t)
+	newApplier := func(allowKeyless bool, limitOne bool) *Applier {
+		cfg := &config.MySQLDriverConfig{
+			ConnectionConfig: &umconf.ConnectionConfig{
+				Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+			AllowKeylessTables: allowKeyless,
+			KeylessLimitOne:    &limitOne,
+		}
+		a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+		if err != nil {
+			t.Fatalf("NewApplier() error = %v", err)
+		}
+		if err := a.initDBConnections(); err != nil {
+			t.Skipf("no mysql available: %v", err)
+		}
+		return a
+	}
+	row := func(id int64, name interface{}) *umconf.ColumnValues {
+		var v1, v2 interface{} = id, name
+		return &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2}}
+	}
+	sid := uuid.NewV4()
+	gno := int64(0)
+	newEntry := func(event binlog.DataEvent) *binlog.BinlogEntry {
+		gno++
+		event.DatabaseName = "dtle_test"
+		event.TableName = "t_keyless"
+		return &binlog.BinlogEntry{
+			Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: gno},
+			Events:      []binlog.DataEvent{event},
+		}
+	}
+	apply := func(a *Applier, entry *binlog.BinlogEntry) error {
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			return err
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			return err
+		}
+		return a.commitApplyBatch(0, a.applyBatches[0])
+	}
+	rows := func(a *Applier) (got []string) {
+		rs, err := a.db.Query("select id, ifnull(name, 'NULL') from dtle_test.t_keyless order by id, name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rs.Close()
+		for rs.Next() {
+			var id int
+			var name string
+			if err := rs.Scan(&id, &name); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, fmt.Sprintf("%v:%v", id, name))
+		}
+		return got
+	}
+	reset := func(a *Applier) {
+		if _, err := a.db.Exec("create database if not exists dtle_test;" +
+			"drop table if exists dtle_test.t_keyless; create table dtle_test.t_keyless (id bigint, name varchar(20));" +
+			"insert into dtle_test.t_keyless values (1, 'a'), (1, 'a'), (2, null), (3, 'c')"); err != nil {
+			t.Fatalf("create table error = %v", err)
+		}
+	}
+
+	// Without AllowKeylessTables, inserts are applied while updates and deletes are refused.
+	a := newApplier(false, true)
+	reset(a)
+	if err := apply(a, newEntry(binlog.DataEvent{DML: binlog.InsertDML, NewColumnValues: row(4, "d")})); err != nil {
+		t.Fatalf("insert error = %v", err)
+	}
+	if err := apply(a, newEntry(binlog.DataEvent{DML: binlog.DeleteDML, WhereColumnValues: row(3, "c")})); err == nil {
+		t.Error("delete on a keyless table without AllowKeylessTables, want an error")
+	}
+
+	for _, tt := range []struct {
+		limitOne bool
+		want     []string
+	}{
+		{true, []string{"1:a", "1:b", "2:x"}},
+		{false, []string{"1:b", "1:b", "2:x"}},
+	} {
+		a := newApplier(true, tt.limitOne)
+		reset(a)
+		for _, event := range []binlog.DataEvent{
+			{DML: binlog.UpdateDML, WhereColumnValues: row(1, "a"), NewColumnValues: row(1, "b")},
+			{DML: binlog.UpdateDML, WhereColumnValues: row(2, nil), NewColumnValues: row(2, "x")},
+			{DML: binlog.DeleteDML, WhereColumnValues: row(3, "c")},
+		} {
+			if err := apply(a, newEntry(event)); err != nil {
+				t.Fatalf("KeylessLimitOne %v: %v error = %v", tt.limitOne, event.DML, err)
+			}
+		}
+		if got := rows(a); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("KeylessLimitOne %v: rows = %v, want %v", tt.limitOne, got, tt.want)
+		}
+	}
+}
+
+func TestApplier_destSessionVars(t *testing.T) {
+	skipWithoutMySQL(t)
+	newApplier := func(vars map[string]string) (*Applier, error) {
+		cfg := &config.MySQLDriverConfig{
+			ConnectionConfig: &umconf.ConnectionConfig{
+				Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+			DestSessionVars: vars,
+		}
+		a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+		if err != nil {
+			t.Fatalf("NewApplier() error = %v", err)
+		}
+		return a, a.initDBConnections()
+	}
+	if _, err := newApplier(nil); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+
+	if _, err := newApplier(map[string]string{"no_such_var": "1"}); err == nil ||
+		!strings.Contains(err.Error(), "DestSessionVars") {
+		t.Errorf("initDBConnections() with an unknown variable, error = %v", err)
+	}
+
+	var gno int64
+	insert := func(a *Applier, name string) error {
+		gno++
+		var v1, v2 interface{} = gno, name
+		entry := &binlog.BinlogEntry{
+			Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: gno},
+			Events: []binlog.DataEvent{{
+				DML:             binlog.InsertDML,
+				DatabaseName:    "dtle_test",
+				TableName:       "t_session_vars",
+				NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2}},
+			}},
+		}
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			return err
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			return err
+		}
+		return a.commitApplyBatch(0, a.applyBatches[0])
+	}
+
+	for _, tt := range []struct {
+		sqlMode string
+		wantErr bool
+	}{
+		{"STRICT_ALL_TABLES", true},
+		{"", false},
+	} {
+		a, err := newApplier(map[string]string{"sql_mode": tt.sqlMode, "wait_timeout": "3600"})
+		if err != nil {
+			t.Fatalf("sql_mode %q: initDBConnections() error = %v", tt.sqlMode, err)
+		}
+		if _, err := a.db.Exec("create database if not exists dtle_test;" +
+			"drop table if exists dtle_test.t_session_vars;" +
+			"create table dtle_test.t_session_vars (id bigint primary key, name varchar(2))"); err != nil {
+			t.Fatalf("create table error = %v", err)
+		}
+		// The value is too long for the column.
+		if err := insert(a, "abc"); (err != nil) != tt.wantErr {
+			t.Errorf("sql_mode %q: insert error = %v, wantErr %v", tt.sqlMode, err, tt.wantErr)
+		}
+	}
+}
+
+// BIT(1) and BIT(64) values, as decoded by the binlog reader, are inserted and then matched by an update.
+func TestApplier_bitColumns(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	if _, err := a.db.Exec("create database if not exists dtle_test; drop table if exists dtle_test.t_bit;" +
+		"create table dtle_test.t_bit (flag bit(1), mask bit(64))"); err != nil {
+		t.Fatalf("prepare error = %v", err)
+	}
+
+	var flag, mask interface{} = uint64(1), uint64(0xFFFFFFFFFFFFFFFF)
+	var newFlag, newMask interface{} = uint64(0), uint64(0x8000000000000001)
+	entry := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: 1},
+		Events: []binlog.DataEvent{{
+			DatabaseName:    "dtle_test",
+			TableName:       "t_bit",
+			DML:             binlog.InsertDML,
+			NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&flag, &mask}},
+		}, {
+			DatabaseName:      "dtle_test",
+			TableName:         "t_bit",
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&flag, &mask}},
+			NewColumnValues:   &umconf.ColumnValues{AbstractValues: []*interface{}{&newFlag, &newMask}},
+		}},
+	}
+	if err := a.setTableItemForBinlogEntry(entry); err != nil {
+		t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+	}
+	if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+		t.Fatalf("ApplyBinlogEvent() error = %v", err)
+	}
+	if err := a.commitApplyBatch(0, a.applyBatches[0]); err != nil {
+		t.Fatalf("commitApplyBatch() error = %v", err)
+	}
+
+	var gotFlag, gotMask string
+	if err := a.db.QueryRow("select hex(flag), hex(mask) from dtle_test.t_bit").Scan(&gotFlag, &gotMask); err != nil {
+		t.Fatalf("select error = %v", err)
+	}
+	if gotFlag != "0" || gotMask != "8000000000000001" {
+		t.Errorf("the row = %v, %v, want 0, 8000000000000001", gotFlag, gotMask)
+	}
 }
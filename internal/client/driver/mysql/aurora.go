@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+
+	usql "github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+)
+
+// Aurora MySQL is told by @@aurora_version. It differs from MySQL as a source in that:
+//   - the binlog and GTID are set in the DB cluster parameter group (binlog_format, gtid-mode), and only
+//     the writer instance has the binlog. The reader instances are innodb_read_only.
+//   - no user has SUPER, so FLUSH TABLES WITH READ LOCK is not allowed. The snapshot of the full copy must be
+//     taken without lock, which requires gtid_mode=ON.
+//   - privileges may be granted by roles (e.g. rds_superuser_role of Aurora MySQL 3), which are not listed by
+//     SHOW GRANTS for the privilege check.
+// Nothing else differs for dtle: it never writes to the source (e.g. RESET MASTER or heartbeat tables), and the
+// binlog heartbeat is an event of the replication protocol, sent by Aurora as well.
+
+// readAuroraVersion sets AuroraVersion if the source is Aurora MySQL.
+func (i *Inspector) readAuroraVersion() error {
+	err := i.db.QueryRow("select @@aurora_version").Scan(&i.mysqlContext.AuroraVersion)
+	if usql.IsUnknownSystemVariable(err) {
+		i.mysqlContext.AuroraVersion = ""
+		return nil
+	} else if err != nil {
+		return err
+	}
+	i.logger.Infof("mysql.inspector: the source is Aurora MySQL %v (MySQL %v)",
+		i.mysqlContext.AuroraVersion, i.mysqlContext.MySQLVersion)
+	return nil
+}
+
+// auroraHint returns how a setting of the source is changed on Aurora, to be appended to an error about it.
+func (i *Inspector) auroraHint(hint string) string {
+	if i.mysqlContext.AuroraVersion == "" {
+		return ""
+	}
+	return ". On Aurora MySQL, " + hint
+}
+
+// isAurora tells whether the source is Aurora MySQL. See Inspector.readAuroraVersion.
+func (e *Extractor) isAurora() bool {
+	return e.mysqlContext.AuroraVersion != ""
+}
+
+// validateAurora checks that the source is the writer instance of an Aurora cluster, which has the binlog.
+func (e *Extractor) validateAurora() error {
+	if !e.isAurora() {
+		return nil
+	}
+	var innodbReadOnly bool
+	if err := e.db.QueryRow("select @@global.innodb_read_only").Scan(&innodbReadOnly); err != nil {
+		return err
+	}
+	if innodbReadOnly {
+		return fmt.Errorf("the source %v:%v is a reader instance of Aurora MySQL, which has no binlog."+
+			" connect to the cluster endpoint or the writer instance",
+			e.mysqlContext.ConnectionConfig.Host, e.mysqlContext.ConnectionConfig.Port)
+	}
+	return nil
+}
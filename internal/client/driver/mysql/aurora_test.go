@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// Set DTLE_TEST_AURORA_ADDR (host:port of the cluster endpoint), DTLE_TEST_AURORA_USER and
+// DTLE_TEST_AURORA_PASSWORD to run it against an Aurora MySQL cluster with binlog_format=ROW and gtid-mode=ON.
+func TestExtractor_aurora(t *testing.T) {
+	addr := os.Getenv("DTLE_TEST_AURORA_ADDR")
+	if addr == "" {
+		t.Skip("DTLE_TEST_AURORA_ADDR is not set")
+	}
+	host, port, err := parseHostPort("DTLE_TEST_AURORA_ADDR", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := (&config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host:     host,
+			Port:     port,
+			User:     os.Getenv("DTLE_TEST_AURORA_USER"),
+			Password: os.Getenv("DTLE_TEST_AURORA_PASSWORD"),
+		},
+	}).SetDefault()
+	logger := logrus.NewEntry(logrus.New())
+
+	// the startup validation
+	i := NewInspector(cfg, logger)
+	if err := i.InitDBConnections(); err != nil {
+		t.Fatalf("InitDBConnections() error = %v", err)
+	}
+	if cfg.AuroraVersion == "" {
+		t.Fatalf("AuroraVersion is not set. @@version: %v", cfg.MySQLVersion)
+	}
+	e := &Extractor{logger: logger, mysqlContext: cfg}
+	if err := e.initDBConnections(); err != nil {
+		t.Fatalf("initDBConnections() error = %v", err)
+	}
+	defer e.db.Close()
+	defer e.singletonDB.Close()
+
+	// the snapshot of the full copy, without lock
+	tx, err := e.startConsistentSnapshot()
+	if err != nil {
+		t.Fatalf("startConsistentSnapshot() error = %v", err)
+	}
+	defer tx.Rollback()
+	if e.initialBinlogCoordinates.GtidSet == "" {
+		t.Errorf("the snapshot has no GTID set: %+v", e.initialBinlogCoordinates)
+	}
+}
@@ -34,6 +34,10 @@ type BinlogCoordinateTx struct {
 	GNO           int64
 	LastCommitted int64
 	SeqenceNumber int64
+	// unix seconds when the transaction was executed on the source
+	Timestamp int64
+	// server_id of the server which executed the transaction
+	ServerID uint32
 }
 
 // Do not call this frequently. Cache your result.
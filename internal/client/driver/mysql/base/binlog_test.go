@@ -49,57 +49,29 @@ func TestBinlogCoordinatesAsKey(t *testing.T) {
 	test.S(t).ExpectEquals(len(m), 3)
 }
 
-func TestBinlogCoordinates_DisplayString(t *testing.T) {
-	type fields struct {
-		LogFile string
-		LogPos  int64
-		GtidSet string
-		Type    BinlogType
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		want   string
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogCoordinateTx{
-				LogFile: tt.fields.LogFile,
-				LogPos:  tt.fields.LogPos,
-				GtidSet: tt.fields.GtidSet,
-			}
-			if got := b.DisplayString(); got != tt.want {
-				t.Errorf("BinlogCoordinates.DisplayString() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestBinlogCoordinates_String(t *testing.T) {
 	type fields struct {
 		LogFile string
 		LogPos  int64
 		GtidSet string
-		Type    BinlogType
 	}
 	tests := []struct {
 		name   string
 		fields fields
 		want   string
 	}{
-		// TODO: Add test cases.
+		{"gtid", fields{LogFile: "mysql-bin.000001", LogPos: 4, GtidSet: "00000000-0000-0000-0000-000000000001:1-5"},
+			"00000000-0000-0000-0000-000000000001:1-5"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := BinlogCoordinateTx{
+			b := BinlogCoordinatesX{
 				LogFile: tt.fields.LogFile,
 				LogPos:  tt.fields.LogPos,
 				GtidSet: tt.fields.GtidSet,
 			}
 			if got := b.String(); got != tt.want {
-				t.Errorf("BinlogCoordinates.String() = %v, want %v", got, tt.want)
+				t.Errorf("BinlogCoordinatesX.String() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -109,8 +81,6 @@ func TestBinlogCoordinates_Equals(t *testing.T) {
 	type fields struct {
 		LogFile string
 		LogPos  int64
-		GtidSet string
-		Type    BinlogType
 	}
 	type args struct {
 		other *BinlogCoordinateTx
@@ -128,7 +98,6 @@ func TestBinlogCoordinates_Equals(t *testing.T) {
 			b := &BinlogCoordinateTx{
 				LogFile: tt.fields.LogFile,
 				LogPos:  tt.fields.LogPos,
-				GtidSet: tt.fields.GtidSet,
 			}
 			if got := b.Equals(tt.args.other); got != tt.want {
 				t.Errorf("BinlogCoordinates.Equals() = %v, want %v", got, tt.want)
@@ -142,24 +111,25 @@ func TestBinlogCoordinates_IsEmpty(t *testing.T) {
 		LogFile string
 		LogPos  int64
 		GtidSet string
-		Type    BinlogType
 	}
 	tests := []struct {
 		name   string
 		fields fields
 		want   bool
 	}{
-		// TODO: Add test cases.
+		{"empty", fields{}, true},
+		{"file", fields{LogFile: "mysql-bin.000001", LogPos: 4}, false},
+		{"gtid", fields{GtidSet: "00000000-0000-0000-0000-000000000001:1-5"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogCoordinateTx{
+			b := &BinlogCoordinatesX{
 				LogFile: tt.fields.LogFile,
 				LogPos:  tt.fields.LogPos,
 				GtidSet: tt.fields.GtidSet,
 			}
 			if got := b.IsEmpty(); got != tt.want {
-				t.Errorf("BinlogCoordinates.IsEmpty() = %v, want %v", got, tt.want)
+				t.Errorf("BinlogCoordinatesX.IsEmpty() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -169,8 +139,6 @@ func TestBinlogCoordinates_SmallerThan(t *testing.T) {
 	type fields struct {
 		LogFile string
 		LogPos  int64
-		GtidSet string
-		Type    BinlogType
 	}
 	type args struct {
 		other *BinlogCoordinateTx
@@ -188,7 +156,6 @@ func TestBinlogCoordinates_SmallerThan(t *testing.T) {
 			b := &BinlogCoordinateTx{
 				LogFile: tt.fields.LogFile,
 				LogPos:  tt.fields.LogPos,
-				GtidSet: tt.fields.GtidSet,
 			}
 			if got := b.SmallerThan(tt.args.other); got != tt.want {
 				t.Errorf("BinlogCoordinates.SmallerThan() = %v, want %v", got, tt.want)
@@ -201,8 +168,6 @@ func TestBinlogCoordinates_SmallerThanOrEquals(t *testing.T) {
 	type fields struct {
 		LogFile string
 		LogPos  int64
-		GtidSet string
-		Type    BinlogType
 	}
 	type args struct {
 		other *BinlogCoordinateTx
@@ -220,7 +185,6 @@ func TestBinlogCoordinates_SmallerThanOrEquals(t *testing.T) {
 			b := &BinlogCoordinateTx{
 				LogFile: tt.fields.LogFile,
 				LogPos:  tt.fields.LogPos,
-				GtidSet: tt.fields.GtidSet,
 			}
 			if got := b.SmallerThanOrEquals(tt.args.other); got != tt.want {
 				t.Errorf("BinlogCoordinates.SmallerThanOrEquals() = %v, want %v", got, tt.want)
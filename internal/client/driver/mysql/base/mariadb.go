@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package base
+
+import (
+	gosql "database/sql"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/satori/go.uuid"
+	gomysql "github.com/siddontang/go-mysql/mysql"
+)
+
+// A MariaDB GTID is domain-server-sequence, and the sequence is increasing in a domain.
+// Inside dtle, e.g. in BinlogCoordinateTx and in gtid_executed_v3 of the destination, a MariaDB GTID is
+// kept as a MySQL GTID, whose uuid holds the domain and the server (see MariadbGtidSID) and whose GNO is
+// the sequence. Gtid of a job (the checkpoint) is in the form of MariaDB, e.g. "0-1-100,1-2-50".
+
+// mariadbGtidSIDMark fills the last 8 bytes of the uuid of a MariaDB GTID.
+var mariadbGtidSIDMark = []byte("mariadb!")
+
+// IsMariaDB tells whether a version string (@@version) is of MariaDB, e.g. "10.3.22-MariaDB-log".
+func IsMariaDB(version string) bool {
+	return strings.Contains(strings.ToLower(version), "mariadb")
+}
+
+// Flavor returns the flavor for go-mysql of a version string.
+func Flavor(version string) string {
+	if IsMariaDB(version) {
+		return gomysql.MariaDBFlavor
+	}
+	return gomysql.MySQLFlavor
+}
+
+// MariadbGtidSID returns the uuid of the MariaDB GTIDs of a domain and a server.
+func MariadbGtidSID(domainID uint32, serverID uint32) uuid.UUID {
+	var sid uuid.UUID
+	binary.BigEndian.PutUint32(sid[0:4], domainID)
+	binary.BigEndian.PutUint32(sid[4:8], serverID)
+	copy(sid[8:], mariadbGtidSIDMark)
+	return sid
+}
+
+// ParseMariadbGtidSID returns the domain and the server of a uuid made by MariadbGtidSID.
+// ok is false for a uuid of MySQL.
+func ParseMariadbGtidSID(sid uuid.UUID) (domainID uint32, serverID uint32, ok bool) {
+	if string(sid[8:]) != string(mariadbGtidSIDMark) {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint32(sid[0:4]), binary.BigEndian.Uint32(sid[4:8]), true
+}
+
+// IsMariadbGtidSet tells whether a GTID set is in the form of MariaDB. An empty set is not.
+func IsMariadbGtidSet(gtidSet string) bool {
+	gtidSet = strings.TrimSpace(gtidSet)
+	return gtidSet != "" && !strings.Contains(gtidSet, ":")
+}
+
+// ParseGtidSet parses a GTID set of MySQL or of MariaDB.
+// A MariaDB position d-s-n means that all transactions of domain d up to sequence n are done,
+// which becomes the interval 1-n of MariadbGtidSID(d, s).
+func ParseGtidSet(gtidSet string) (*gomysql.MysqlGTIDSet, error) {
+	gtidSet = strings.Replace(gtidSet, "\n", "", -1)
+	if !IsMariadbGtidSet(gtidSet) {
+		return parseMysqlGTIDSet(gtidSet)
+	}
+
+	mariadbSet, err := gomysql.ParseMariadbGTIDSet(strings.Replace(gtidSet, " ", "", -1))
+	if err != nil {
+		return nil, err
+	}
+	set := &gomysql.MysqlGTIDSet{Sets: make(map[string]*gomysql.UUIDSet)}
+	for _, gtid := range mariadbSet.(*gomysql.MariadbGTIDSet).Sets {
+		if gtid.SequenceNumber == 0 {
+			continue
+		}
+		set.AddSet(gomysql.NewUUIDSet(MariadbGtidSID(gtid.DomainID, gtid.ServerID),
+			gomysql.Interval{Start: 1, Stop: int64(gtid.SequenceNumber) + 1}))
+	}
+	return set, nil
+}
+
+// GtidSetString formats a set made by ParseGtidSet in the form it was parsed from.
+// For MariaDB, the position of a domain is the end of the first interval of its servers, up to which
+// all transactions are surely done, e.g. 1-98:100 (99 being applied) is 98.
+func GtidSetString(set *gomysql.MysqlGTIDSet) string {
+	positions := make(map[uint32]*gomysql.MariadbGTID)
+	for _, uuidSet := range set.Sets {
+		domainID, serverID, ok := ParseMariadbGtidSID(uuidSet.SID)
+		if !ok {
+			return set.String()
+		}
+		if len(uuidSet.Intervals) == 0 {
+			continue
+		}
+		seq := uint64(uuidSet.Intervals[0].Stop - 1)
+		if p, ok := positions[domainID]; !ok || seq > p.SequenceNumber {
+			positions[domainID] = &gomysql.MariadbGTID{DomainID: domainID, ServerID: serverID, SequenceNumber: seq}
+		}
+	}
+	return mariadbGtidPositionsString(positions)
+}
+
+func mariadbGtidPositionsString(positions map[uint32]*gomysql.MariadbGTID) string {
+	var gtids []string
+	for _, gtid := range positions {
+		gtids = append(gtids, gtid.String())
+	}
+	sort.Strings(gtids)
+	return strings.Join(gtids, ",")
+}
+
+// mariadbGtidSetIntersect returns the position of each domain in both MariaDB positions set1 and set2,
+// which is the lower one of the two.
+func mariadbGtidSetIntersect(set1 string, set2 string) (string, error) {
+	g1, err := gomysql.ParseMariadbGTIDSet(strings.Replace(set1, " ", "", -1))
+	if err != nil {
+		return "", err
+	}
+	g2, err := gomysql.ParseMariadbGTIDSet(strings.Replace(set2, " ", "", -1))
+	if err != nil {
+		return "", err
+	}
+
+	positions := make(map[uint32]*gomysql.MariadbGTID)
+	for domainID, gtid1 := range g1.(*gomysql.MariadbGTIDSet).Sets {
+		gtid2, ok := g2.(*gomysql.MariadbGTIDSet).Sets[domainID]
+		if !ok {
+			continue
+		}
+		if gtid1.SequenceNumber <= gtid2.SequenceNumber {
+			positions[domainID] = gtid1
+		} else {
+			positions[domainID] = gtid2
+		}
+	}
+	return mariadbGtidPositionsString(positions), nil
+}
+
+// GetMariadbGtidBinlogPos returns the GTID position of the last transaction in binlog of MariaDB,
+// which is its counterpart of Executed_Gtid_Set of `show master status`.
+func GetMariadbGtidBinlogPos(db *gosql.DB) (string, error) {
+	var pos string
+	if err := db.QueryRow(`select @@global.gtid_binlog_pos`).Scan(&pos); err != nil {
+		return "", fmt.Errorf("get gtid_binlog_pos: %v", err)
+	}
+	return pos, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package base
+
+import (
+	"testing"
+
+	gomysql "github.com/siddontang/go-mysql/mysql"
+)
+
+func TestIsMariaDB(t *testing.T) {
+	for version, want := range map[string]bool{
+		"10.3.22-MariaDB-log":             true,
+		"5.5.5-10.4.12-MariaDB-1:10.4.12": true,
+		"5.7.25-log":                      false,
+		"8.0.19":                          false,
+	} {
+		if got := IsMariaDB(version); got != want {
+			t.Errorf("IsMariaDB(%v) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestMariadbGtidSID(t *testing.T) {
+	sid := MariadbGtidSID(1, 4294967295)
+	domainID, serverID, ok := ParseMariadbGtidSID(sid)
+	if !ok || domainID != 1 || serverID != 4294967295 {
+		t.Errorf("ParseMariadbGtidSID(%v) = %v, %v, %v", sid, domainID, serverID, ok)
+	}
+
+	mysqlSet, err := ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uuidSet := range mysqlSet.Sets {
+		if _, _, ok := ParseMariadbGtidSID(uuidSet.SID); ok {
+			t.Errorf("ParseMariadbGtidSID(%v) is ok for a MySQL uuid", uuidSet.SID)
+		}
+	}
+}
+
+func TestParseGtidSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		gtidSet string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"mysql", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10:12",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10:12", false},
+		{"mariadb", "0-1-100", "0-1-100", false},
+		{"mariadb domains", "1-2-50, 0-1-100\n", "0-1-100,1-2-50", false},
+		{"mariadb sequence 0", "0-1-0,1-2-5", "1-2-5", false},
+		{"mariadb bad", "0-1", "", true},
+		{"mysql bad", "3e11fa47:1-10", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseGtidSet(tt.gtidSet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGtidSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := GtidSetString(set); got != tt.want {
+				t.Errorf("GtidSetString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The applier adds the applied transactions to the set and reports it as the checkpoint.
+func TestGtidSetString_mariadbApplied(t *testing.T) {
+	set, err := ParseGtidSet("0-1-100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(domainID uint32, serverID uint32, seq int64) {
+		set.AddSet(gomysql.NewUUIDSet(MariadbGtidSID(domainID, serverID), gomysql.Interval{Start: seq, Stop: seq + 1}))
+	}
+
+	add(0, 1, 101)
+	add(0, 1, 103) // 102 is being applied
+	if got, want := GtidSetString(set), "0-1-101"; got != want {
+		t.Errorf("GtidSetString() = %v, want %v", got, want)
+	}
+	add(0, 1, 102)
+	// failover to server 2 in the same domain
+	add(0, 2, 104)
+	add(0, 2, 105)
+	add(3, 2, 7)
+	if got, want := GtidSetString(set), "0-2-105,3-2-7"; got != want {
+		t.Errorf("GtidSetString() = %v, want %v", got, want)
+	}
+
+	// The checkpoint covers the applied transactions. A MariaDB position is compared by domain.
+	checkpoint, err := gomysql.ParseMariadbGTIDSet(GtidSetString(set))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gtid := range []string{"0-1-100", "0-2-105", "3-2-1"} {
+		applied, _ := gomysql.ParseMariadbGTIDSet(gtid)
+		if !checkpoint.Contain(applied) {
+			t.Errorf("%v does not contain %v", checkpoint, gtid)
+		}
+	}
+	for _, gtid := range []string{"0-2-106", "1-2-1", "3-2-8"} {
+		notApplied, _ := gomysql.ParseMariadbGTIDSet(gtid)
+		if checkpoint.Contain(notApplied) {
+			t.Errorf("%v contains %v", checkpoint, gtid)
+		}
+	}
+}
+
+func TestGtidSetIntersect_mariadb(t *testing.T) {
+	tests := []struct {
+		name string
+		set1 string
+		set2 string
+		want string
+	}{
+		{"same", "0-1-100", "0-1-100", "0-1-100"},
+		{"lower", "0-1-100,1-2-5", "0-1-90,1-2-7", "0-1-90,1-2-5"},
+		{"servers", "0-2-110", "0-1-100", "0-1-100"},
+		{"domains", "0-1-100,1-2-5", "0-1-100", "0-1-100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GtidSetIntersect(tt.set1, tt.set2)
+			if err != nil {
+				t.Errorf("GtidSetIntersect() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GtidSetIntersect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package base
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The coordinate recorded by mysqldump at the head of a dump, with --set-gtid-purged (the default with gtid_mode=ON):
+//
+//	SET @@GLOBAL.GTID_PURGED='3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5';
+//	SET @@GLOBAL.GTID_PURGED=/*!80000 '+'*/ '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5'; (MySQL 8.0)
+//
+// with --master-data (commented out if --master-data=2):
+//
+//	CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000003', MASTER_LOG_POS=154;
+//	CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='mysql-bin.000003', SOURCE_LOG_POS=154; (MySQL 8.0.23)
+//
+// and with --master-data --gtid of MariaDB:
+//
+//	SET GLOBAL gtid_slave_pos='0-1-100';
+var (
+	mysqldumpGtidPurgedRegexp = regexp.MustCompile(
+		`(?i)SET\s+@@GLOBAL\.GTID_PURGED\s*=\s*(?:/\*!\d+\s*'\+'\s*\*/\s*)?'([^']*)'`)
+	mysqldumpMariadbGtidRegexp = regexp.MustCompile(
+		`(?i)SET\s+GLOBAL\s+gtid_slave_pos\s*=\s*'([^']*)'`)
+	mysqldumpChangeMasterRegexp = regexp.MustCompile(
+		`(?i)CHANGE\s+(?:MASTER|REPLICATION\s+SOURCE)\s+TO\s+(?:MASTER|SOURCE)_LOG_FILE\s*=\s*'([^']+)'\s*,\s*(?:MASTER|SOURCE)_LOG_POS\s*=\s*(\d+)`)
+)
+
+// ParseMysqldumpCoordinate returns the binlog coordinate of the source at which a dump was taken, from the
+// statements written by mysqldump (see mysqldumpGtidPurgedRegexp). The GTID set is preferred, and the
+// file and position are also returned if present. It fails if there is neither of them.
+func ParseMysqldumpCoordinate(statements string) (*BinlogCoordinatesX, error) {
+	coord := &BinlogCoordinatesX{}
+	if m := mysqldumpGtidPurgedRegexp.FindStringSubmatch(statements); m != nil {
+		coord.GtidSet = strings.Join(strings.Fields(m[1]), "")
+	} else if m := mysqldumpMariadbGtidRegexp.FindStringSubmatch(statements); m != nil {
+		coord.GtidSet = strings.Join(strings.Fields(m[1]), "")
+	}
+	if coord.GtidSet != "" {
+		if _, err := ParseGtidSet(coord.GtidSet); err != nil {
+			return nil, fmt.Errorf("bad GTID set %v of the dump: %v", coord.GtidSet, err)
+		}
+	}
+	if m := mysqldumpChangeMasterRegexp.FindStringSubmatch(statements); m != nil {
+		pos, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad binlog position %v of the dump: %v", m[2], err)
+		}
+		coord.LogFile, coord.LogPos = m[1], pos
+	}
+	if coord.GtidSet == "" && coord.LogFile == "" {
+		return nil, fmt.Errorf("neither SET @@GLOBAL.GTID_PURGED nor CHANGE MASTER TO is found." +
+			" dump the source with --set-gtid-purged=ON or --master-data")
+	}
+	return coord, nil
+}
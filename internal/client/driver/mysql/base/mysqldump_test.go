@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package base
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMysqldumpCoordinate(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements string
+		want       *BinlogCoordinatesX
+		wantErr    string
+	}{
+		{"gtid purged", `
+SET @@SESSION.SQL_LOG_BIN= 0;
+
+--
+-- GTID state at the beginning of the backup 
+--
+
+SET @@GLOBAL.GTID_PURGED='3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,
+4e11fa47-71ca-11e1-9e33-c80aa9429562:1-100:102';
+`, &BinlogCoordinatesX{
+			GtidSet: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,4e11fa47-71ca-11e1-9e33-c80aa9429562:1-100:102",
+		}, ""},
+		{"gtid purged of mysql 8.0 and master data", `
+-- CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000003', MASTER_LOG_POS=154;
+SET @@GLOBAL.GTID_PURGED=/*!80000 '+'*/ '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5';
+`, &BinlogCoordinatesX{
+			GtidSet: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+			LogFile: "mysql-bin.000003",
+			LogPos:  154,
+		}, ""},
+		{"master data", `CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='binlog.000012', SOURCE_LOG_POS=4;`,
+			&BinlogCoordinatesX{LogFile: "binlog.000012", LogPos: 4}, ""},
+		{"mariadb", `
+-- CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000002', MASTER_LOG_POS=642;
+-- SET GLOBAL gtid_slave_pos='0-1-100';
+`, &BinlogCoordinatesX{GtidSet: "0-1-100", LogFile: "mysql-bin.000002", LogPos: 642}, ""},
+		{"bad gtid", `SET @@GLOBAL.GTID_PURGED='3e11fa47:1-5';`, nil, "bad GTID set 3e11fa47:1-5"},
+		{"none", `SET @@GLOBAL.GTID_PURGED='';`, nil, "neither SET @@GLOBAL.GTID_PURGED nor CHANGE MASTER TO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMysqldumpCoordinate(tt.statements)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseMysqldumpCoordinate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMysqldumpCoordinate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -73,6 +73,85 @@ func GetSelfBinlogCoordinates(db *gosql.DB) (selfBinlogCoordinates *BinlogCoordi
 	return selfBinlogCoordinates, err
 }
 
+// ReplicaStatus describes a source server which might be a replica of another MySQL.
+type ReplicaStatus struct {
+	ServerUUID      string
+	ReadOnly        bool
+	SuperReadOnly   bool
+	LogSlaveUpdates bool
+	// non-empty if `show slave status` returns a row.
+	MasterUUID string
+	// gtid_executed of the server.
+	GtidSet string
+}
+
+// IsReplica returns true if the server replicates from a primary or is set read-only.
+func (s *ReplicaStatus) IsReplica() bool {
+	return s.MasterUUID != "" || s.ReadOnly || s.SuperReadOnly
+}
+
+// ValidateAsBinlogSource checks that binlog of the server contains all changes to be extracted.
+// A replica writes changes from its primary into its own binlog only with log_slave_updates=ON.
+func (s *ReplicaStatus) ValidateAsBinlogSource() error {
+	if s.IsReplica() && !s.LogSlaveUpdates {
+		return fmt.Errorf("the source is a replica (read_only=%v, super_read_only=%v, master_uuid=%v)"+
+			" but log_slave_updates is OFF. Changes replicated from the primary are not written to its binlog."+
+			" Set log_slave_updates=ON on the replica, or connect to the primary",
+			s.ReadOnly, s.SuperReadOnly, s.MasterUUID)
+	}
+	return nil
+}
+
+// HasLocalTransactions returns true if gtid_executed of a replica contains its own server_uuid,
+// i.e. some transactions were executed directly on the replica rather than replicated from the primary.
+func (s *ReplicaStatus) HasLocalTransactions() bool {
+	if s.ServerUUID == "" || s.GtidSet == "" {
+		return false
+	}
+	gtidSet, err := gomysql.ParseMysqlGTIDSet(s.GtidSet)
+	if err != nil {
+		return false
+	}
+	_, ok := gtidSet.(*gomysql.MysqlGTIDSet).Sets[strings.ToLower(s.ServerUUID)]
+	return ok
+}
+
+// GetReplicaStatus reads the replication related variables of a server.
+// super_read_only is not available before MySQL 5.7.8 and is treated as OFF.
+func GetReplicaStatus(db *gosql.DB) (*ReplicaStatus, error) {
+	status := &ReplicaStatus{}
+	query := `show global variables where Variable_name in
+		('server_uuid', 'read_only', 'super_read_only', 'log_slave_updates', 'gtid_executed')`
+	err := usql.QueryRowsMap(db, query, func(m usql.RowMap) error {
+		value := m.GetString("Value")
+		switch m.GetString("Variable_name") {
+		case "server_uuid":
+			status.ServerUUID = value
+		case "read_only":
+			status.ReadOnly = value == "ON"
+		case "super_read_only":
+			status.SuperReadOnly = value == "ON"
+		case "log_slave_updates":
+			status.LogSlaveUpdates = value == "ON"
+		case "gtid_executed":
+			status.GtidSet = strings.Replace(value, "\n", "", -1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = usql.QueryRowsMap(db, `show slave status`, func(m usql.RowMap) error {
+		status.MasterUUID = m.GetString("Master_UUID")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
 func ParseBinlogCoordinatesFromRows(rows *sql.Rows) (selfBinlogCoordinates *BinlogCoordinatesX, err error) {
 	err = usql.ScanRowsToMaps(rows, func(m usql.RowMap) error {
 		selfBinlogCoordinates = &BinlogCoordinatesX{
@@ -101,6 +180,7 @@ func GetTableColumns(db usql.QueryAble, databaseName, tableName string) (*umconf
 			Default:    rowMap.GetString("Default"),
 			Key:        strings.ToUpper(rowMap.GetString("Key")),
 			Nullable:   strings.ToUpper(rowMap.GetString("Null")) == "YES",
+			OnUpdate:   strings.Contains(strings.ToUpper(rowMap.GetString("Extra")), "ON UPDATE"),
 		}
 		aColumn.EscapedName = umconf.EscapeName(aColumn.RawName)
 		columns = append(columns, aColumn)
@@ -132,13 +212,21 @@ func ShowCreateTable(db *gosql.DB, databaseName, tableName string, dropTableIfEx
 	return statement, err
 }
 
+// ShowCreateSchema returns `CREATE DATABASE IF NOT EXISTS` of a schema, with its default charset and collation.
+func ShowCreateSchema(db *gosql.DB, databaseName string) (createSchemaStatement string, err error) {
+	var dummy string
+	query := fmt.Sprintf(`show create database if not exists %s`, umconf.EscapeName(databaseName))
+	err = db.QueryRow(query).Scan(&dummy, &createSchemaStatement)
+	return createSchemaStatement, err
+}
+
 func ShowCreateView(db *gosql.DB, databaseName, tableName string, dropTableIfExists bool) (createTableStatement string, err error) {
 	var dummy, character_set_client, collation_connection string
 	query := fmt.Sprintf(`show create table %s.%s`, umconf.EscapeName(databaseName), umconf.EscapeName(tableName))
 	err = db.QueryRow(query).Scan(&dummy, &createTableStatement, &character_set_client, &collation_connection)
 	statement := fmt.Sprintf("USE %s", umconf.EscapeName(databaseName))
 	if dropTableIfExists {
-		statement = fmt.Sprintf("%s;DROP TABLE IF EXISTS `%s`", statement, tableName)
+		statement = fmt.Sprintf("%s;DROP TABLE IF EXISTS %s", statement, umconf.EscapeName(tableName))
 	}
 	return fmt.Sprintf("%s;%s", statement, createTableStatement), err
 }
@@ -272,6 +360,7 @@ func ApplyColumnTypes(db usql.QueryAble, databaseName, tableName string, columns
 			for _, columnsList := range columnsLists {
 				columnsList.GetColumn(columnName).Type = umconf.EnumColumnType
 				columnsList.GetColumn(columnName).ColumnType = columnType
+				columnsList.GetColumn(columnName).EnumValues = umconf.ParseEnumValues(columnType)
 			}
 		}
 		if strings.HasPrefix(columnType, "binary") {
@@ -339,6 +428,7 @@ func ApplyColumnTypes(db usql.QueryAble, databaseName, tableName string, columns
 		if strings.HasPrefix(columnType, "bit") {
 			for _, columnsList := range columnsLists {
 				columnsList.GetColumn(columnName).Type = umconf.BitColumnType
+				columnsList.GetColumn(columnName).ColumnType = columnType
 			}
 		}
 		if strings.HasPrefix(columnType, "int") {
@@ -390,6 +480,7 @@ func ApplyColumnTypes(db usql.QueryAble, databaseName, tableName string, columns
 			for _, columnsList := range columnsLists {
 				columnsList.GetColumn(columnName).Type = umconf.SetColumnType
 				columnsList.GetColumn(columnName).ColumnType = columnType
+				columnsList.GetColumn(columnName).EnumValues = umconf.ParseEnumValues(columnType)
 			}
 		}
 		// TODO return err on unknown type?
@@ -459,6 +550,123 @@ func GtidSetDiff(set1 string, set2 string) (string, error) {
 	return gExecuted.String(), nil
 }
 
+func parseMysqlGTIDSet(gtidSet string) (*gomysql.MysqlGTIDSet, error) {
+	helper, err := gomysql.ParseMysqlGTIDSet(strings.Replace(gtidSet, "\n", "", -1))
+	if err != nil {
+		return nil, err
+	}
+	set, ok := helper.(*gomysql.MysqlGTIDSet)
+	if !ok {
+		return nil, fmt.Errorf("internal error: cannot cast MysqlGTIDSet")
+	}
+	return set, nil
+}
+
+// GtidSetSubtract returns GTIDs in set1 but not in set2.
+func GtidSetSubtract(set1 string, set2 string) (string, error) {
+	g1, err := parseMysqlGTIDSet(set1)
+	if err != nil {
+		return "", err
+	}
+	g2, err := parseMysqlGTIDSet(set2)
+	if err != nil {
+		return "", err
+	}
+
+	result := &gomysql.MysqlGTIDSet{Sets: make(map[string]*gomysql.UUIDSet)}
+	for sid, uuidSet := range g1.Sets {
+		intervals := uuidSet.Intervals.Normalize()
+		if other, ok := g2.Sets[sid]; ok {
+			intervals = subtractIntervals(intervals, other.Intervals.Normalize())
+		}
+		if len(intervals) > 0 {
+			result.Sets[sid] = &gomysql.UUIDSet{SID: uuidSet.SID, Intervals: intervals}
+		}
+	}
+	return result.String(), nil
+}
+
+// GtidSetIntersect returns GTIDs in both set1 and set2.
+// For MariaDB, it returns the lower position of each domain.
+func GtidSetIntersect(set1 string, set2 string) (string, error) {
+	if IsMariadbGtidSet(set1) || IsMariadbGtidSet(set2) {
+		return mariadbGtidSetIntersect(set1, set2)
+	}
+	onlyIn1, err := GtidSetSubtract(set1, set2)
+	if err != nil {
+		return "", err
+	}
+	return GtidSetSubtract(set1, onlyIn1)
+}
+
+// subtractIntervals requires both a and b to be normalized.
+func subtractIntervals(a, b gomysql.IntervalSlice) gomysql.IntervalSlice {
+	var result gomysql.IntervalSlice
+	for _, i := range a {
+		start := i.Start
+		for _, j := range b {
+			if j.Stop <= start || j.Start >= i.Stop {
+				continue
+			}
+			if j.Start > start {
+				result = append(result, gomysql.Interval{Start: start, Stop: j.Start})
+			}
+			start = j.Stop
+			if start >= i.Stop {
+				break
+			}
+		}
+		if start < i.Stop {
+			result = append(result, gomysql.Interval{Start: start, Stop: i.Stop})
+		}
+	}
+	return result
+}
+
+// GetEarliestBinlogCoordinates returns the coordinates to stream all binlog still available on the server.
+// With GTID (executedGtidSet is not empty), the coordinates are executedGtidSet plus gtid_purged,
+// and lostGtidSet are the purged transactions which have not been executed.
+// Otherwise it is the beginning of the first binlog file, and lost transactions are unknown.
+func GetEarliestBinlogCoordinates(db *gosql.DB, executedGtidSet string) (coordinates *BinlogCoordinatesX, lostGtidSet string, err error) {
+	if executedGtidSet == "" {
+		err = usql.QueryRowsMap(db, `show binary logs`, func(m usql.RowMap) error {
+			if coordinates == nil {
+				coordinates = &BinlogCoordinatesX{
+					LogFile: m.GetString("Log_name"),
+					LogPos:  4,
+				}
+			}
+			return nil
+		})
+		if err == nil && coordinates == nil {
+			err = fmt.Errorf("no binary log on the server")
+		}
+		return coordinates, "", err
+	}
+
+	var purged string
+	if err = db.QueryRow(`select @@global.gtid_purged`).Scan(&purged); err != nil {
+		return nil, "", err
+	}
+	lostGtidSet, err = GtidSetSubtract(purged, executedGtidSet)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gExecuted, err := parseMysqlGTIDSet(executedGtidSet)
+	if err != nil {
+		return nil, "", err
+	}
+	gPurged, err := parseMysqlGTIDSet(purged)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, uuidSet := range gPurged.Sets {
+		gExecuted.AddSet(uuidSet)
+	}
+	return &BinlogCoordinatesX{GtidSet: gExecuted.String()}, lostGtidSet, nil
+}
+
 func GetTableColumnsSqle(sqleContext *sqle.Context, schema string, table string) (*umconf.ColumnList, error) {
 	tableInfo, exists := sqleContext.GetTable(schema, table)
 	if !exists {
@@ -533,8 +741,10 @@ func GetTableColumnsSqle(sqleContext *sqle.Context, schema string, table string)
 			newColumn.Type = umconf.JSONColumnType
 		case parsermysql.TypeEnum:
 			newColumn.Type = umconf.EnumColumnType
+			newColumn.EnumValues = col.Tp.Elems
 		case parsermysql.TypeSet:
 			newColumn.Type = umconf.SetColumnType
+			newColumn.EnumValues = col.Tp.Elems
 		case parsermysql.TypeTinyBlob:
 			newColumn.Type = umconf.BlobColumnType
 		case parsermysql.TypeMediumBlob:
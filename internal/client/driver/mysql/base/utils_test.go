@@ -8,13 +8,13 @@ package base
 
 import (
 	gosql "database/sql"
+	"os"
 	"reflect"
 	"testing"
 	"time"
-	umconf "github.com/actiontech/dtle/internal/config/mysql"
 
-	"fmt"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
 
 	test "github.com/outbrain/golib/tests"
 	gomysql "github.com/siddontang/go-mysql/mysql"
@@ -53,33 +53,28 @@ func TestPrettifyDurationOutput(t *testing.T) {
 }
 
 func TestGetSelfBinlogCoordinates(t *testing.T) {
-	type args struct {
-		db *gosql.DB
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&tls=false&autocommit=true&charset=utf8mb4,utf8,latin1&multiStatements=true")
+	if err != nil {
+		t.Fatal(err)
 	}
-	db, err := sql.CreateDB(fmt.Sprintf("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&tls=false&autocommit=true&charset=utf8mb4,utf8,latin1&multiStatements=true"))
+	defer db.Close()
+
+	gotSelfBinlogCoordinates, err := GetSelfBinlogCoordinates(db)
 	if err != nil {
-		return
+		t.Fatalf("GetSelfBinlogCoordinates() error = %v", err)
 	}
-	tests := []struct {
-		name                      string
-		args                      args
-		wantSelfBinlogCoordinates *BinlogCoordinateTx
-		wantErr                   bool
-	}{
-		// TODO: Add test cases.
-		{"T1", args{db}, nil, false},
+	// binlog is enabled on the test server
+	if gotSelfBinlogCoordinates == nil || gotSelfBinlogCoordinates.LogFile == "" {
+		t.Errorf("GetSelfBinlogCoordinates() = %+v, want the current binlog file", gotSelfBinlogCoordinates)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			gotSelfBinlogCoordinates, err := GetSelfBinlogCoordinates(tt.args.db)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetSelfBinlogCoordinates() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(gotSelfBinlogCoordinates, tt.wantSelfBinlogCoordinates) {
-				t.Errorf("GetSelfBinlogCoordinates() = %v, want %v", gotSelfBinlogCoordinates, tt.wantSelfBinlogCoordinates)
-			}
-		})
+}
+
+// skipWithoutMySQL skips a test which needs the MySQL server at 192.168.99.100:13307 (root/rootroot),
+// unless DTLE_TEST_MYSQL is set.
+func skipWithoutMySQL(t testing.TB) {
+	if os.Getenv("DTLE_TEST_MYSQL") == "" {
+		t.Skip("DTLE_TEST_MYSQL is not set")
 	}
 }
 
@@ -146,23 +141,24 @@ func TestShowCreateTable(t *testing.T) {
 		databaseName      string
 		tableName         string
 		dropTableIfExists bool
+		addUse            bool
 	}
 	tests := []struct {
 		name                     string
 		args                     args
-		wantCreateTableStatement string
+		wantCreateTableStatement []string
 		wantErr                  bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotCreateTableStatement, err := ShowCreateTable(tt.args.db, tt.args.databaseName, tt.args.tableName, tt.args.dropTableIfExists)
+			gotCreateTableStatement, err := ShowCreateTable(tt.args.db, tt.args.databaseName, tt.args.tableName, tt.args.dropTableIfExists, tt.args.addUse)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ShowCreateTable() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if gotCreateTableStatement != tt.wantCreateTableStatement {
+			if !reflect.DeepEqual(gotCreateTableStatement, tt.wantCreateTableStatement) {
 				t.Errorf("ShowCreateTable() = %v, want %v", gotCreateTableStatement, tt.wantCreateTableStatement)
 			}
 		})
@@ -180,7 +176,7 @@ func Test_parseInterval(t *testing.T) {
 		wantErr bool
 	}{
 		// TODO: Add test cases.
-		{"t1", args{"36671-36677"}, gomysql.Interval{}, false},
+		{"t1", args{"36671-36677"}, gomysql.Interval{Start: 36671, Stop: 36678}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -253,3 +249,107 @@ func Test_stringInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestReplicaStatus_ValidateAsBinlogSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  ReplicaStatus
+		replica bool
+		wantErr bool
+	}{
+		{"primary", ReplicaStatus{}, false, false},
+		{"replica with log_slave_updates", ReplicaStatus{MasterUUID: "a", ReadOnly: true, LogSlaveUpdates: true}, true, false},
+		{"replica without log_slave_updates", ReplicaStatus{MasterUUID: "a"}, true, true},
+		{"read_only without log_slave_updates", ReplicaStatus{ReadOnly: true}, true, true},
+		{"super_read_only without log_slave_updates", ReplicaStatus{SuperReadOnly: true}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.IsReplica(); got != tt.replica {
+				t.Errorf("ReplicaStatus.IsReplica() = %v, want %v", got, tt.replica)
+			}
+			if err := tt.status.ValidateAsBinlogSource(); (err != nil) != tt.wantErr {
+				t.Errorf("ReplicaStatus.ValidateAsBinlogSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplicaStatus_HasLocalTransactions(t *testing.T) {
+	primaryUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	replicaUUID := "4a2d0a47-71ca-11e1-9e33-c80aa9429562"
+	status := ReplicaStatus{ServerUUID: replicaUUID, MasterUUID: primaryUUID,
+		GtidSet: primaryUUID + ":1-100"}
+	test.S(t).ExpectFalse(status.HasLocalTransactions())
+
+	status.GtidSet = primaryUUID + ":1-100," + replicaUUID + ":1-2"
+	test.S(t).ExpectTrue(status.HasLocalTransactions())
+}
+
+func TestGtidSetSubtract(t *testing.T) {
+	tests := []struct {
+		name string
+		set1 string
+		set2 string
+		want string
+	}{
+		{"disjoint", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10", "4a2d0a47-71ca-11e1-9e33-c80aa9429562:1-5",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10"},
+		{"contained", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-20", ""},
+		{"partial", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10", "3e11fa47-71ca-11e1-9e33-c80aa9429562:3-5:8",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-2:6-7:9-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GtidSetSubtract(tt.set1, tt.set2)
+			if err != nil {
+				t.Errorf("GtidSetSubtract() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GtidSetSubtract() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGtidSetIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		set1 string
+		set2 string
+		want string
+	}{
+		{"disjoint", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10", "4a2d0a47-71ca-11e1-9e33-c80aa9429562:1-5", ""},
+		{"contained", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-20",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10"},
+		{"partial", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10,4a2d0a47-71ca-11e1-9e33-c80aa9429562:1-5",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:3-5:8-12,4a2d0a47-71ca-11e1-9e33-c80aa9429562:1-3",
+			"3e11fa47-71ca-11e1-9e33-c80aa9429562:3-5:8-10,4a2d0a47-71ca-11e1-9e33-c80aa9429562:1-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GtidSetIntersect(tt.set1, tt.set2)
+			if err != nil {
+				t.Errorf("GtidSetIntersect() error = %v", err)
+				return
+			}
+			if !gtidSetEqual(t, got, tt.want) {
+				t.Errorf("GtidSetIntersect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// gtidSetEqual compares two GTID sets regardless of the order of UUIDs.
+func gtidSetEqual(t *testing.T, set1, set2 string) bool {
+	g1, err := parseMysqlGTIDSet(set1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g2, err := parseMysqlGTIDSet(set2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g1.Equal(g2)
+}
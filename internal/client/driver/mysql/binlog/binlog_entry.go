@@ -15,6 +15,14 @@ import (
 
 type BinlogEntries struct {
 	Entries []*BinlogEntry
+	// The messages of the incremental copy are numbered by Seq from 1 in each Stream, i.e. each run of the
+	// extractor, for the destinations to apply them in order. AckedSeq is the last one acknowledged by all
+	// the destinations when this one is sent. Seq is 0 if the message is not numbered.
+	Stream   string
+	Seq      int64
+	AckedSeq int64
+	// the number of the entries sent in Stream before this message. See IncrAck.
+	EntrySeq int64
 }
 
 // BinlogEntry describes an entry in the binary log
@@ -22,8 +30,39 @@ type BinlogEntry struct {
 	hasBeginQuery bool
 	Coordinates   base.BinlogCoordinateTx
 	SpanContext   opentracing.SpanContext
-	Events        []DataEvent
-	OriginalSize  int // size of binlog entry
+	// the span context of the transaction, carried to the destinations. See StartTxSpan.
+	TraceContext []byte
+	Events       []DataEvent
+	OriginalSize int // size of binlog entry
+	// A transaction of more rows than BigTxSplittingSize is sent in pieces of the same Coordinates.
+	// Index is the number of the piece from 0. Partial is set on all the pieces but the last one.
+	Index   int
+	Partial bool
+	// set on an entry of the resync of a table instead of a transaction. It has no Coordinates.
+	Resync *ResyncPart
+	// The XA PREPARE of an XA transaction is held back until its XA COMMIT or XA ROLLBACK, which is sent
+	// with the events of the prepared transaction. XAPrepare is the coordinates of the XA PREPARE,
+	// executed with this entry.
+	XAPrepare *base.BinlogCoordinateTx
+	// the xid of an XA transaction being read
+	xid string
+	// set if the rows of the transaction are dropped by IgnoreSourceUsers
+	ignored bool
+}
+
+// ResyncPart is a part of the resync of a table, see MySQLDriverConfig.ResyncTable. The parts are, in order:
+// the TRUNCATE TABLE in Events, the chunks of the dump in Chunk, and the last one (Done) with the events
+// of the table held back meanwhile in Events. A destination applies them serially and does not record
+// them as executed transactions.
+type ResyncPart struct {
+	// of the request
+	ID string
+	// names on the destination
+	TableSchema string
+	TableName   string
+	// an encoded DumpEntry of rows of the table
+	Chunk []byte
+	Done  bool
 }
 
 // NewBinlogEntry creates an empty, ready to go BinlogEntry object
@@ -36,7 +75,25 @@ func NewBinlogEntryAt(coordinates base.BinlogCoordinateTx) *BinlogEntry {
 	return binlogEntry
 }
 
+// NextPiece returns an empty entry for the next piece of a big transaction, after b has been sent.
+func (b *BinlogEntry) NextPiece() *BinlogEntry {
+	next := NewBinlogEntryAt(b.Coordinates)
+	next.hasBeginQuery = b.hasBeginQuery
+	next.Index = b.Index + 1
+	return next
+}
+
 // Duplicate creates and returns a new binlog entry, with some of the attributes pre-assigned
 func (b *BinlogEntry) String() string {
 	return fmt.Sprintf("[BinlogEntry at %+v]", b.Coordinates)
 }
+
+// HasDDL returns true if the entry contains a non-DML event, other than a savepoint.
+func (b *BinlogEntry) HasDDL() bool {
+	for i := range b.Events {
+		if b.Events[i].DML == NotDML && !b.Events[i].Savepoint {
+			return true
+		}
+	}
+	return false
+}
@@ -87,6 +87,13 @@ type DataEvent struct {
 	Table             *config.Table // TODO tmp solution
 	LogPos            int64         // for kafka. The pos of WRITE_ROW_EVENT
 	TableItem         interface{}
+	// A TRUNCATE TABLE of DatabaseName.TableName. DML is NotDML, as it is applied like a DDL.
+	Truncate bool
+	// A SAVEPOINT or a ROLLBACK TO SAVEPOINT in Query. DML is NotDML, but it is applied in the
+	// transaction, between the rows before and after it.
+	Savepoint bool
+	// the indexes of the row values which are encrypted. See ColumnCipher.
+	EncryptedColumns []int
 }
 
 func NewDataEvent(databaseName, tableName string, dml EventDML, columnCount int) DataEvent {
@@ -118,6 +125,25 @@ func NewQueryEventAffectTable(currentSchema, query string, dml EventDML, affecte
 	return event
 }
 
+// NewTruncateEvent returns an event of TRUNCATE TABLE schemaName.tableName, with names after mapping.
+func NewTruncateEvent(currentSchema, schemaName, tableName string) DataEvent {
+	event := NewQueryEventAffectTable(
+		currentSchema,
+		fmt.Sprintf("TRUNCATE TABLE %s.%s", mysql.EscapeName(schemaName), mysql.EscapeName(tableName)),
+		NotDML,
+		SchemaTable{Schema: schemaName, Table: tableName},
+	)
+	event.Truncate = true
+	return event
+}
+
+// NewSavepointEvent returns an event of a SAVEPOINT or a ROLLBACK TO SAVEPOINT query in a transaction.
+func NewSavepointEvent(query string) DataEvent {
+	event := NewQueryEvent("", query, NotDML)
+	event.Savepoint = true
+	return event
+}
+
 func (b *DataEvent) String() string {
 	return fmt.Sprintf("[%+v on %s:%s]", b.DML, b.DatabaseName, b.TableName)
 }
@@ -25,7 +25,6 @@ import (
 	//"encoding/hex"
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -44,7 +43,6 @@ import (
 	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
 	sqle "github.com/actiontech/dtle/internal/client/driver/mysql/sqle/inspector"
-	"github.com/actiontech/dtle/internal/client/driver/mysql/util"
 	"github.com/actiontech/dtle/internal/config"
 	"github.com/actiontech/dtle/internal/config/mysql"
 
@@ -78,6 +76,8 @@ type BinlogReader struct {
 	mysqlContext *config.MySQLDriverConfig
 	// dynamic config, include all tables (implicitly assigned or dynamically created)
 	tables map[string](map[string]*config.TableContext)
+	// held while an event is handled. See LockTables.
+	tablesLock sync.Mutex
 
 	currentTx          *BinlogTx
 	currentBinlogEntry *BinlogEntry
@@ -87,6 +87,8 @@ type BinlogReader struct {
 	currentSqlB64      *bytes.Buffer
 	appendB64SqlBs     []byte
 	ReMap              map[string]*regexp.Regexp
+	// XA transactions prepared but not committed yet, by xid
+	preparedXA map[string]*BinlogEntry
 
 	wg           sync.WaitGroup
 	shutdown     bool
@@ -96,6 +98,28 @@ type BinlogReader struct {
 	sqlFilter *SqlFilter
 
 	context *sqle.Context
+	// nil if EncryptionKey is not set
+	cipher *ColumnCipher
+
+	// the GTID set of the transactions read to their end, from which the stream can be resumed, e.g. on another
+	// server after a failover of the source. nil if not streaming by GTID. See updateExtractedGtidSet.
+	extractedGtidSet      gomysql.GTIDSet
+	extractedGtidSetMutex sync.Mutex
+
+	// nil if IgnoreSourceUsers is not set
+	sourceUsers *sourceUserFilter
+
+	// the unsigned columns of the last TABLE_MAP event of a table id, if the source writes the signedness.
+	// See tableMapUnsigned.
+	unsignedColumns map[uint64][]bool
+	// the checksum algorithm of the last FORMAT_DESCRIPTION_EVENT, which begins each binlog file and dump
+	checksumAlgorithm byte
+
+	// nil if SemiSync is not set
+	semiSync *SemiSyncTracker
+
+	// whether serverId is registered, which is done once the binlog connection succeeds
+	serverIdRegistered bool
 }
 
 type SqlFilter struct {
@@ -156,11 +180,23 @@ func parseSqlFilter(strs []string) (*SqlFilter, error) {
 	return s, nil
 }
 
+// ValidateSqlFilter checks the items of MySQLDriverConfig.SqlFilter.
+func ValidateSqlFilter(strs []string) error {
+	_, err := parseSqlFilter(strs)
+	return err
+}
+
 func NewMySQLReader(execCtx *common.ExecContext, cfg *config.MySQLDriverConfig, logger *logrus.Entry, replicateDoDb []*config.DataSource, sqleContext *sqle.Context) (binlogReader *BinlogReader, err error) {
 	sqlFilter, err := parseSqlFilter(cfg.SqlFilter)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.ReplicateDML != nil && !*cfg.ReplicateDML {
+		sqlFilter.NoDML = true
+	}
+	if cfg.ReplicateDDL != nil && !*cfg.ReplicateDDL {
+		sqlFilter.NoDDL = true
+	}
 
 	binlogReader = &BinlogReader{
 		execCtx:                 execCtx,
@@ -185,34 +221,40 @@ func NewMySQLReader(execCtx *common.ExecContext, cfg *config.MySQLDriverConfig,
 		}
 	}
 
-	uri := cfg.ConnectionConfig.GetDBUri()
-	if binlogReader.db, err = sql.CreateDB(uri); err != nil {
+	if binlogReader.cipher, err = NewColumnCipher(cfg.EncryptionKey); err != nil {
 		return nil, err
 	}
 
-	id, err := util.NewIdWorker(2, 3, util.SnsEpoch)
+	uri, err := sql.UriWithSessionVars(cfg.ConnectionConfig.GetDBUri(), cfg.SrcSessionVars)
 	if err != nil {
 		return nil, err
 	}
-	sid, err := id.NextId()
-	if err != nil {
+	if binlogReader.db, err = sql.CreateDB(uri); err != nil {
 		return nil, err
 	}
-	bid := []byte(strconv.FormatUint(uint64(sid), 10))
-	binlogReader.serverId, err = strconv.ParseUint(string(bid), 10, 32)
-	if err != nil {
-		return nil, err
+	if len(cfg.IgnoreSourceUsers) > 0 {
+		if binlogReader.sourceUsers, err = newSourceUserFilter(binlogReader.db, cfg.IgnoreSourceUsers, logger); err != nil {
+			return nil, fmt.Errorf("IgnoreSourceUsers: %v", err)
+		}
+		logger.Infof("mysql.reader: IgnoreSourceUsers: %v", cfg.IgnoreSourceUsers)
 	}
-	logger.Debug("job.start: debug server id is :", binlogReader.serverId)
-	// support regex
-	binlogReader.genRegexMap()
+
+	if cfg.ServerId == 0 {
+		cfg.ServerId = randomServerId()
+	}
+	binlogReader.serverId = uint64(cfg.ServerId)
+	logger.Infof("mysql.reader: ServerId of the binlog connection: %v", cfg.ServerId)
 
 	if binlogReader.mysqlContext.BinlogRelay {
 		// init when connecting
 	} else {
+		loc, err := cfg.TimeZoneLocation()
+		if err != nil {
+			return nil, err
+		}
 		binlogSyncerConfig := replication.BinlogSyncerConfig{
 			ServerID:       uint32(binlogReader.serverId),
-			Flavor:         "mysql",
+			Flavor:         base.Flavor(cfg.MySQLVersion),
 			Host:           cfg.ConnectionConfig.Host,
 			Port:           uint16(cfg.ConnectionConfig.Port),
 			User:           cfg.ConnectionConfig.User,
@@ -220,9 +262,18 @@ func NewMySQLReader(execCtx *common.ExecContext, cfg *config.MySQLDriverConfig,
 			RawModeEnabled: false,
 			UseDecimal:     true,
 
+			SemiSyncEnabled:   cfg.SemiSync,
+			SemiSyncManualACK: true,
+
 			MaxReconnectAttempts: 3,
-			HeartbeatPeriod:      3 * time.Second,
-			ReadTimeout:          6 * time.Second,
+			HeartbeatPeriod:      time.Duration(cfg.HeartbeatPeriod) * time.Second,
+			ReadTimeout:          2 * time.Duration(cfg.HeartbeatPeriod) * time.Second,
+
+			// TIMESTAMP values are formatted in TimeZone, which the applier uses as its session time_zone.
+			TimestampStringLocation: loc,
+
+			// nil (a direct connection) if there is no tunnel
+			Dialer: sql.Dialer(cfg.ConnectionConfig),
 		}
 		binlogReader.binlogSyncer = replication.NewBinlogSyncer(binlogSyncerConfig)
 	}
@@ -251,14 +302,66 @@ func (b *BinlogReader) addTableToTableMap(tableMap map[string]*config.TableConte
 		return err
 	}
 
-	tableMap[table.TableName] = config.NewTableContext(table, whereCtx)
+	tableCtx := config.NewTableContext(table, whereCtx)
+	if tableCtx.SampleKey, err = config.SampleKeyIndexes(table); err != nil {
+		return err
+	}
+	tableCtx.EncryptedColumns = config.EncryptedColumnIndexes(table)
+	tableMap[table.TableName] = tableCtx
 	return nil
 }
 
+// LockTables holds DataStreamEvents between two events until UnlockTables, so that the replicated tables are
+// changed by AddTable and RemoveTable at a definite position of the binlog.
+func (b *BinlogReader) LockTables() {
+	b.tablesLock.Lock()
+}
+
+func (b *BinlogReader) UnlockTables() {
+	b.tablesLock.Unlock()
+}
+
+// AddTable starts replicating table, which has been inspected by the extractor. Call it with LockTables.
+func (b *BinlogReader) AddTable(table *config.Table) error {
+	if err := b.addTableToTableMap(b.getDbTableMap(table.TableSchema), table); err != nil {
+		return err
+	}
+	b.mysqlContext.ReplicateDoDb = config.WithTable(b.mysqlContext.ReplicateDoDb, table)
+	return nil
+}
+
+// RemoveTable stops replicating a table. Call it with LockTables.
+func (b *BinlogReader) RemoveTable(schemaName string, tableName string) {
+	if tableMap, ok := b.tables[schemaName]; ok {
+		for name := range tableMap {
+			if b.matchString(name, tableName) {
+				delete(tableMap, name)
+			}
+		}
+		if len(tableMap) == 0 {
+			// an empty map would mean all the tables of the schema
+			delete(b.tables, schemaName)
+		}
+	}
+	b.mysqlContext.ReplicateDoDb = config.WithoutTable(b.mysqlContext.ReplicateDoDb, schemaName, tableName,
+		b.mysqlContext.LowerCaseTableNames != 0)
+}
+
 func (b *BinlogReader) getBinlogDir() string {
 	return path.Join(b.execCtx.StateDir, "binlog", b.execCtx.Subject)
 }
 
+// IsBinlogPurgedError tells whether err is returned by the source on a binlog dump request
+// because the requested GTIDs or binlog file have been purged.
+func IsBinlogPurgedError(err error) bool {
+	myErr, ok := err.(*gomysql.MyError)
+	if !ok || myErr.Code != gomysql.ER_MASTER_FATAL_ERROR_READING_BINLOG {
+		return false
+	}
+	msg := strings.ToLower(myErr.Message)
+	return strings.Contains(msg, "purged") || strings.Contains(msg, "could not find first log file")
+}
+
 // ConnectBinlogStreamer
 func (b *BinlogReader) ConnectBinlogStreamer(coordinates base.BinlogCoordinatesX) (err error) {
 	if coordinates.IsEmpty() {
@@ -322,7 +425,13 @@ func (b *BinlogReader) ConnectBinlogStreamer(coordinates base.BinlogCoordinatesX
 
 		go b.relay.Process(ctx, ch)
 
-		loc, err := time.LoadLocation("Local") // TODO
+		loc, err := b.mysqlContext.TimeZoneLocation()
+		if err != nil {
+			return err
+		}
+		if loc == nil {
+			loc = time.Local
+		}
 
 		brConfig := &streamer.BinlogReaderConfig{
 			RelayDir: b.getBinlogDir(),
@@ -370,24 +479,59 @@ func (b *BinlogReader) ConnectBinlogStreamer(coordinates base.BinlogCoordinatesX
 			b.binlogStreamer, err = b.binlogSyncer.StartSync(
 				gomysql.Position{Name:coordinates.LogFile,Pos:uint32(coordinates.LogPos)})
 		} else {
-			gtidSet, err := gomysql.ParseMysqlGTIDSet(coordinates.GtidSet)
+			var gtidSet gomysql.GTIDSet
+			if base.IsMariadbGtidSet(coordinates.GtidSet) {
+				gtidSet, err = gomysql.ParseMariadbGTIDSet(coordinates.GtidSet)
+			} else {
+				gtidSet, err = gomysql.ParseMysqlGTIDSet(coordinates.GtidSet)
+			}
 			if err != nil {
 				b.logger.Errorf("mysql.reader: err: %v", err)
 				return err
 			}
 
 			b.binlogStreamer, err = b.binlogSyncer.StartSyncGTID(gtidSet)
+			if err == nil {
+				b.setExtractedGtidSet(gtidSet.Clone())
+			}
 		}
 		if err != nil {
 			b.logger.Debugf("mysql.reader: err at StartSyncGTID: %v", err)
 			return err
 		}
+		b.startSemiSync()
+	}
+	if !b.serverIdRegistered {
+		if usedBy := registerServerId(uint32(b.serverId), b.execCtx.Subject); usedBy != "" {
+			b.logger.Warnf("mysql.reader: ServerId %v is also used by job %v on this agent."+
+				" the source will drop one of the binlog connections if both read from it", b.serverId, usedBy)
+		}
+		b.serverIdRegistered = true
 	}
 	b.mysqlContext.Stage = models.StageRequestingBinlogDump
 
 	return nil
 }
 
+// SetSemiSync sets the tracker of the position to ACK with SemiSync, before ConnectBinlogStreamer.
+func (b *BinlogReader) SetSemiSync(semiSync *SemiSyncTracker) {
+	b.semiSync = semiSync
+}
+
+// startSemiSync ACKs the positions of semiSync, if the source has semisync enabled.
+func (b *BinlogReader) startSemiSync() {
+	if b.semiSync == nil {
+		return
+	}
+	if !b.binlogSyncer.SemiSyncEnabled() {
+		b.logger.Warnf("mysql.reader: SemiSync: rpl_semi_sync_master_enabled is not ON on the source." +
+			" reading the binlog asynchronously")
+		return
+	}
+	b.logger.Infof("mysql.reader: SemiSync: reading the binlog as a semisync replica")
+	b.semiSync.setACK(b.binlogSyncer.SetSemiSyncACKPos)
+}
+
 func (b *BinlogReader) GetCurrentBinlogCoordinates() *base.BinlogCoordinateTx {
 	b.currentCoordinatesMutex.Lock()
 	defer b.currentCoordinatesMutex.Unlock()
@@ -420,6 +564,11 @@ func ToColumnValuesV2(abstractValues []interface{}, table *config.TableContext)
 					abstractValues[i] = uint64(v)
 				}
 			}
+			if i < len(columns) && (columns[i].Type == mysql.EnumColumnType || columns[i].Type == mysql.SetColumnType) {
+				// The binlog carries the index (bitmap for set). Use the string value,
+				// in case the member order differs on the destination.
+				abstractValues[i] = columns[i].DecodeEnumValue(abstractValues[i])
+			}
 		}
 		result.AbstractValues[i] = &abstractValues[i]
 	}
@@ -427,6 +576,65 @@ func ToColumnValuesV2(abstractValues []interface{}, table *config.TableContext)
 	return result
 }
 
+// decodeBitValues turns the values of BIT columns, which go-mysql decodes as int64, into uint64 of
+// the bit length in the TABLE_MAP metadata. Otherwise a BIT(64) value with the highest bit set is negative.
+func decodeBitValues(tableMap *replication.TableMapEvent, row []interface{}) {
+	for i, columnType := range tableMap.ColumnType {
+		if columnType != gomysql.MYSQL_TYPE_BIT || i >= len(row) || i >= len(tableMap.ColumnMeta) {
+			continue
+		}
+		v, ok := row[i].(int64)
+		if !ok {
+			continue
+		}
+		meta := tableMap.ColumnMeta[i]
+		nbits := uint((meta>>8)*8 + meta&0xFF)
+		value := uint64(v)
+		if nbits < 64 {
+			value &= (1 << nbits) - 1
+		}
+		row[i] = value
+	}
+}
+
+// decodeDecimalValues puts the values of DECIMAL columns into the text which MySQL returns for them, as the full copy
+// reads them. With UseDecimal, go-mysql decodes them exactly as strings rather than float64, but with the zeros of
+// the whole digit groups leading the integral part (e.g. 000000000012.340) and a trailing dot for a scale of 0.
+func decodeDecimalValues(tableMap *replication.TableMapEvent, row []interface{}) {
+	for i, columnType := range tableMap.ColumnType {
+		if columnType != gomysql.MYSQL_TYPE_NEWDECIMAL || i >= len(row) {
+			continue
+		}
+		if v, ok := row[i].(string); ok {
+			row[i] = normalizeDecimal(v)
+		}
+	}
+}
+
+// normalizeDecimal trims the leading zeros of the integral part of a decimal, and the dot if there is no fraction.
+func normalizeDecimal(v string) string {
+	sign := ""
+	if strings.HasPrefix(v, "-") {
+		sign, v = "-", v[1:]
+	}
+	integral, fraction := v, ""
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		integral, fraction = v[:i], v[i+1:]
+	}
+	integral = strings.TrimLeft(integral, "0")
+	if integral == "" {
+		integral = "0"
+		if strings.Trim(fraction, "0") == "" {
+			// no negative zero
+			sign = ""
+		}
+	}
+	if fraction == "" {
+		return sign + integral
+	}
+	return sign + integral + "." + fraction
+}
+
 // If isDDL, a sql correspond to a table item, aka len(tables) == len(sqls).
 type parseDDLResult struct {
 	isDDL  bool
@@ -458,6 +666,21 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 		b.currentCoordinates.GNO = evt.GNO
 		b.currentCoordinates.LastCommitted = evt.LastCommitted
 		b.currentCoordinates.SeqenceNumber = evt.SequenceNumber
+		b.currentCoordinates.Timestamp = int64(ev.Header.Timestamp)
+		b.currentCoordinates.ServerID = ev.Header.ServerID
+		b.currentBinlogEntry = NewBinlogEntryAt(b.currentCoordinates)
+	case replication.MARIADB_GTID_EVENT:
+		// A MariaDB transaction has no BEGIN query event. A DDL is a standalone one, as in MySQL.
+		evt := ev.Event.(*replication.MariadbGTIDEvent)
+		b.currentCoordinatesMutex.Lock()
+		defer b.currentCoordinatesMutex.Unlock()
+		b.currentCoordinates.SID = base.MariadbGtidSID(evt.GTID.DomainID, evt.GTID.ServerID)
+		b.currentCoordinates.GNO = int64(evt.GTID.SequenceNumber)
+		// There is no logical clock as in MySQL 5.7. The transactions are applied one by one.
+		b.currentCoordinates.LastCommitted = 0
+		b.currentCoordinates.SeqenceNumber = 0
+		b.currentCoordinates.Timestamp = int64(ev.Header.Timestamp)
+		b.currentCoordinates.ServerID = ev.Header.ServerID
 		b.currentBinlogEntry = NewBinlogEntryAt(b.currentCoordinates)
 	case replication.QUERY_EVENT:
 		evt := ev.Event.(*replication.QueryEvent)
@@ -472,8 +695,15 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 
 		if strings.ToUpper(query) == "BEGIN" {
 			b.currentBinlogEntry.hasBeginQuery = true
+			if b.sourceUsers != nil && b.sourceUsers.ignore(ev.Header.ServerID, evt.SlaveProxyID) {
+				b.logger.Debugf("mysql.reader: IgnoreSourceUsers: dropping the rows of gno %v by thread %v",
+					b.currentCoordinates.GNO, evt.SlaveProxyID)
+				b.currentBinlogEntry.ignored = true
+			}
+		} else if b.handleTxQuery(ev, query, span, entriesChannel) {
+			// a statement of the transaction itself
 		} else {
-			if strings.ToUpper(query) == "COMMIT" || !b.currentBinlogEntry.hasBeginQuery {
+			if !b.currentBinlogEntry.hasBeginQuery {
 				currentSchema := string(evt.Schema)
 				if b.mysqlContext.SkipCreateDbTable {
 					if skipCreateDbTable(query) {
@@ -508,6 +738,7 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 					b.currentBinlogEntry.SpanContext = span.Context()
 					b.currentBinlogEntry.OriginalSize += len(ev.RawData)
 					entriesChannel <- b.currentBinlogEntry
+					b.semiSync.forward()
 					b.LastAppliedRowsEventHint = b.currentCoordinates
 					return nil
 				} else {
@@ -543,10 +774,10 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 					var table *config.Table
 					var schema *config.DataSource
 					for i := range b.mysqlContext.ReplicateDoDb {
-						if b.mysqlContext.ReplicateDoDb[i].TableSchema == realSchema {
+						if b.matchString(b.mysqlContext.ReplicateDoDb[i].TableSchema, realSchema) {
 							schema = b.mysqlContext.ReplicateDoDb[i]
 							for j := range b.mysqlContext.ReplicateDoDb[i].Tables {
-								if b.mysqlContext.ReplicateDoDb[i].Tables[j].TableName == tableName {
+								if b.matchString(b.mysqlContext.ReplicateDoDb[i].Tables[j].TableName, tableName) {
 									table = b.mysqlContext.ReplicateDoDb[i].Tables[j]
 								}
 							}
@@ -556,8 +787,16 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 					switch realAst := ddlInfo.ast.(type) {
 					case *ast.CreateDatabaseStmt:
 						b.context.LoadTables(ddlInfo.tables[i].Schema, nil)
+					case *ast.DropDatabaseStmt:
+						if b.mysqlContext.SkipDropStatements {
+							skipEvent = true
+							b.skipDropStatement(sql)
+						}
 					case *ast.CreateTableStmt:
 						b.logger.Debugf("mysql.reader: ddl is create table")
+						if table == nil && schema != nil {
+							table = b.addTableFromPatterns(schema, tableName)
+						}
 						err := b.updateTableMeta(table, realSchema, tableName)
 						if err != nil {
 							return err
@@ -569,6 +808,16 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 					case *ast.DropTableStmt:
 						if b.sqlFilter.NoDDLDropTable {
 							skipEvent = true
+						} else if b.mysqlContext.SkipDropStatements {
+							skipEvent = true
+							b.skipDropStatement(sql)
+						}
+					case *ast.TruncateTableStmt:
+						// It removes rows rather than changes the schema.
+						skipEvent = b.sqlFilter.NoDML || b.sqlFilter.NoDMLDelete
+						if !skipEvent && b.mysqlContext.SkipDropStatements {
+							skipEvent = true
+							b.skipDropStatement(sql)
 						}
 					case *ast.AlterTableStmt:
 						b.logger.Debugf("mysql.reader: ddl is alter table. specs: %v", realAst.Specs)
@@ -638,6 +887,14 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 
 					if skipEvent {
 						b.logger.Debugf("mysql.reader. skipped a ddl event. query: %v", query)
+					} else if _, ok := ddlInfo.ast.(*ast.TruncateTableStmt); ok {
+						// The statement is rebuilt with the mapped names, which also qualifies an unqualified table.
+						event := NewTruncateEvent(
+							currentSchema,
+							utils.StringElse(ddlInfo.tables[i].Schema, currentSchema),
+							ddlInfo.tables[i].Table,
+						)
+						b.currentBinlogEntry.Events = append(b.currentBinlogEntry.Events, event)
 					} else {
 						event := NewQueryEventAffectTable(
 							currentSchema,
@@ -651,19 +908,30 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 				b.currentBinlogEntry.SpanContext = span.Context()
 				b.currentBinlogEntry.OriginalSize += len(ev.RawData)
 				entriesChannel <- b.currentBinlogEntry
+				b.semiSync.forward()
 				b.LastAppliedRowsEventHint = b.currentCoordinates
 			}
 		}
 	case replication.XID_EVENT:
-		b.currentBinlogEntry.SpanContext = span.Context()
-		b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
-		// TODO is the pos the start or the end of a event?
-		// pos if which event should be use? Do we need +1?
-		b.currentBinlogEntry.Coordinates.LogPos = b.currentCoordinates.LogPos
-		entriesChannel <- b.currentBinlogEntry
-		b.LastAppliedRowsEventHint = b.currentCoordinates
+		b.sendTx(ev, span, entriesChannel)
+	case xaPrepareLogEvent:
+		return b.onXAPrepare(ev, span, entriesChannel)
+	case replication.TABLE_MAP_EVENT:
+		evt := ev.Event.(*replication.TableMapEvent)
+		if unsigned := tableMapUnsigned(ev, b.checksumAlgorithm); unsigned != nil {
+			if b.unsignedColumns == nil {
+				b.unsignedColumns = make(map[uint64][]bool)
+			}
+			b.unsignedColumns[evt.TableID] = unsigned
+		} else {
+			delete(b.unsignedColumns, evt.TableID)
+		}
 	default:
 		if rowsEvent, ok := ev.Event.(*replication.RowsEvent); ok {
+			if b.currentBinlogEntry.ignored {
+				// The transaction is still sent, to keep track of the GTID.
+				return nil
+			}
 			dml := ToEventDML(ev.Header.EventType)
 			skip, table := b.skipRowEvent(rowsEvent, dml)
 			if skip {
@@ -708,6 +976,12 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 			// It is hard to calculate exact row size. We use estimation.
 			avgRowSize := len(ev.RawData) / len(rowsEvent.Rows)
 
+			unsigned := b.unsignedColumns[rowsEvent.TableID]
+			for _, row := range rowsEvent.Rows {
+				decodeUnsignedValues(rowsEvent.Table, unsigned, row)
+				decodeBitValues(rowsEvent.Table, row)
+				decodeDecimalValues(rowsEvent.Table, row)
+			}
 			for i, row := range rowsEvent.Rows {
 				b.logger.Debugf("mysql.reader: row values: %v", row[:mathutil.Min(len(row), g.LONG_LOG_LIMIT)])
 				if dml == UpdateDML && i%2 == 1 {
@@ -764,6 +1038,9 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 						}
 					}
 				}
+				if whereTrue && table != nil && table.SampleKey != nil {
+					whereTrue = SampleRowChange(table, &dmlEvent, dml)
+				}
 				if table != nil && table.Table.TableRename != "" {
 					if dmlEvent.Table != nil {
 						dmlEvent.Table.TableName = table.Table.TableName
@@ -815,7 +1092,29 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 							dmlEvent.WhereColumnValues.AbstractValues = newRow
 						}
 					}
+					if table != nil && len(table.EncryptedColumns) > 0 {
+						if b.cipher == nil {
+							return fmt.Errorf("%v.%v has EncryptColumns, and EncryptionKey is not set",
+								schemaName, tableName)
+						}
+						dmlEvent.EncryptedColumns = table.EncryptedColumns
+						if err := b.cipher.EncryptEvent(&dmlEvent); err != nil {
+							return err
+						}
+					}
 					b.currentBinlogEntry.Events = append(b.currentBinlogEntry.Events, dmlEvent)
+					// An XA transaction is not split, as it might be held back until its commit.
+					if b.mysqlContext.BigTxSplittingSize > 0 && b.currentBinlogEntry.xid == "" &&
+						len(b.currentBinlogEntry.Events) >= b.mysqlContext.BigTxSplittingSize {
+						// send a piece of the big transaction. The last piece is sent on commit.
+						b.currentBinlogEntry.Partial = true
+						b.currentBinlogEntry.SpanContext = span.Context()
+						b.logger.Debugf("mysql.reader: sending piece %v of a big transaction. gno: %v",
+							b.currentBinlogEntry.Index, b.currentBinlogEntry.Coordinates.GNO)
+						entriesChannel <- b.currentBinlogEntry
+						b.semiSync.forward()
+						b.currentBinlogEntry = b.currentBinlogEntry.NextPiece()
+					}
 				} else {
 					b.logger.Debugf("event has not passed 'where'")
 				}
@@ -826,6 +1125,153 @@ func (b *BinlogReader) handleEvent(ev *replication.BinlogEvent, entriesChannel c
 	return nil
 }
 
+// SampleRowChange tells whether a row change of a table with SampleRate is replicated. An update which moves
+// the row into or out of the sample, i.e. changes its primary key, is replicated as an insert or a delete.
+// dmlEvent is reused for the rows of an event, so its DML is reset to dml.
+func SampleRowChange(table *config.TableContext, dmlEvent *DataEvent, dml EventDML) bool {
+	dmlEvent.DML = dml
+	switch dml {
+	case InsertDML:
+		in, _ := table.SampledIn(dmlEvent.NewColumnValues)
+		return in
+	case DeleteDML:
+		in, _ := table.SampledIn(dmlEvent.WhereColumnValues)
+		return in
+	case UpdateDML:
+		before, _ := table.SampledIn(dmlEvent.WhereColumnValues)
+		after, ok := table.SampledIn(dmlEvent.NewColumnValues)
+		if !ok {
+			// the primary key is not changed
+			after = before
+		}
+		switch {
+		case before && !after:
+			dmlEvent.DML = DeleteDML
+			dmlEvent.NewColumnValues = nil
+		case !before && after:
+			dmlEvent.DML = InsertDML
+			dmlEvent.WhereColumnValues = nil
+		}
+		return before || after
+	}
+	return true
+}
+
+// xaPrepareLogEvent is XA_PREPARE_LOG_EVENT of MySQL 5.7, which go-mysql decodes as a GenericEvent.
+// The first byte of its body is one_phase.
+const xaPrepareLogEvent replication.EventType = 38
+
+// sendTx sends the transaction being read, on its end.
+func (b *BinlogReader) sendTx(ev *replication.BinlogEvent, span opentracing.Span, entriesChannel chan<- *BinlogEntry) {
+	b.currentBinlogEntry.SpanContext = span.Context()
+	b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+	// TODO is the pos the start or the end of a event?
+	// pos if which event should be use? Do we need +1?
+	b.currentBinlogEntry.Coordinates.LogPos = b.currentCoordinates.LogPos
+	entriesChannel <- b.currentBinlogEntry
+	b.semiSync.forward()
+	b.LastAppliedRowsEventHint = b.currentCoordinates
+}
+
+// handleTxQuery handles a query controlling the transaction, and returns false for other queries.
+//   - COMMIT ends a transaction on non-transactional tables, as an XID event does for InnoDB.
+//   - SAVEPOINT and ROLLBACK TO SAVEPOINT are applied in the transaction, in order with the rows. MySQL removes
+//     the rolled back rows from the binlog, unless the transaction changes non-transactional tables.
+//     Then the rows are kept with a ROLLBACK TO, which rolls back only the transactional ones.
+//   - XA START begins an XA transaction. Its XA END is ignored, as other queries in a transaction.
+//     XA COMMIT and XA ROLLBACK of a prepared one are separate transactions. See onXAPrepare.
+func (b *BinlogReader) handleTxQuery(ev *replication.BinlogEvent, query string, span opentracing.Span,
+	entriesChannel chan<- *BinlogEntry) bool {
+
+	entry := b.currentBinlogEntry
+	upperQuery := strings.ToUpper(query)
+	switch {
+	case entry.hasBeginQuery && upperQuery == "COMMIT":
+		b.sendTx(ev, span, entriesChannel)
+	case entry.hasBeginQuery && isSavepointQuery(upperQuery):
+		entry.Events = append(entry.Events, NewSavepointEvent(query))
+		entry.OriginalSize += len(ev.RawData)
+	case entry.xid != "" && strings.HasPrefix(upperQuery, "XA COMMIT ") && strings.HasSuffix(upperQuery, " ONE PHASE"):
+		b.sendTx(ev, span, entriesChannel)
+	case entry.hasBeginQuery:
+		return false
+	case strings.HasPrefix(upperQuery, "XA START "):
+		entry.hasBeginQuery = true
+		entry.xid = xaQueryXid(query, "XA START ")
+	case strings.HasPrefix(upperQuery, "XA COMMIT "):
+		b.onXAEnd(ev, xaQueryXid(query, "XA COMMIT "), true, span, entriesChannel)
+	case strings.HasPrefix(upperQuery, "XA ROLLBACK "):
+		b.onXAEnd(ev, xaQueryXid(query, "XA ROLLBACK "), false, span, entriesChannel)
+	default:
+		return false
+	}
+	return true
+}
+
+func isSavepointQuery(upperQuery string) bool {
+	for _, prefix := range []string{"SAVEPOINT ", "ROLLBACK TO ", "RELEASE SAVEPOINT "} {
+		if strings.HasPrefix(upperQuery, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// xaQueryXid returns the xid in an XA statement as logged by MySQL, e.g. X'7831',X'7832',1.
+func xaQueryXid(query string, prefix string) string {
+	return strings.TrimSpace(query[len(prefix):])
+}
+
+// onXAPrepare handles the XA PREPARE of the XA transaction being read. As the changes are not visible on
+// the source until the XA COMMIT, the transaction is held back until then. Its gtid is not sent meanwhile,
+// so that it is read again after a restart of the job. An XA COMMIT ONE PHASE is sent as a usual transaction.
+func (b *BinlogReader) onXAPrepare(ev *replication.BinlogEvent, span opentracing.Span,
+	entriesChannel chan<- *BinlogEntry) error {
+
+	evt, ok := ev.Event.(*replication.GenericEvent)
+	if !ok || len(evt.Data) == 0 {
+		return fmt.Errorf("unexpected XA_PREPARE_LOG_EVENT %T at %v", ev.Event, b.currentCoordinates)
+	}
+	if evt.Data[0] != 0 {
+		b.sendTx(ev, span, entriesChannel)
+		return nil
+	}
+	entry := b.currentBinlogEntry
+	if entry.xid == "" {
+		return fmt.Errorf("XA PREPARE without XA START at %v", b.currentCoordinates)
+	}
+	b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+	entry.Coordinates.LogPos = b.currentCoordinates.LogPos
+	if b.preparedXA == nil {
+		b.preparedXA = make(map[string]*BinlogEntry)
+	}
+	b.preparedXA[entry.xid] = entry
+	b.LastAppliedRowsEventHint = b.currentCoordinates
+	b.logger.Debugf("mysql.reader: holding back XA transaction %v until its commit. gno: %v",
+		entry.xid, entry.Coordinates.GNO)
+	return nil
+}
+
+// onXAEnd sends the XA COMMIT or XA ROLLBACK of a prepared XA transaction, with its events if committed.
+func (b *BinlogReader) onXAEnd(ev *replication.BinlogEvent, xid string, commit bool, span opentracing.Span,
+	entriesChannel chan<- *BinlogEntry) {
+
+	entry := b.currentBinlogEntry
+	if prepared, ok := b.preparedXA[xid]; ok {
+		delete(b.preparedXA, xid)
+		coordinates := prepared.Coordinates
+		entry.XAPrepare = &coordinates
+		if commit {
+			entry.Events = prepared.Events
+			entry.OriginalSize += prepared.OriginalSize
+		}
+	} else if commit {
+		b.logger.Warnf("mysql.reader: XA COMMIT %v of a transaction prepared before the start of the job."+
+			" Its changes are not replicated", xid)
+	}
+	b.sendTx(ev, span, entriesChannel)
+}
+
 func loadMapping(sql, beforeName, afterName, mappingType, currentSchema string) string {
 	sqlType := strings.Split(sql, " ")[1]
 	newSql := ""
@@ -885,28 +1331,24 @@ func (b *BinlogReader) DataStreamEvents(entriesChannel chan<- *BinlogEntry) erro
 		}
 		//ev.Dump(os.Stdout)
 
-		func() {
-			b.currentCoordinatesMutex.Lock()
-			defer b.currentCoordinatesMutex.Unlock()
-			b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
-		}()
-
-		if ev.Header.EventType == replication.ROTATE_EVENT {
-			if rotateEvent, ok := ev.Event.(*replication.RotateEvent); ok {
-				func() {
-					b.currentCoordinatesMutex.Lock()
-					defer b.currentCoordinatesMutex.Unlock()
-					b.currentCoordinates.LogFile = string(rotateEvent.NextLogName)
-				}()
+		if fde, ok := ev.Event.(*replication.FormatDescriptionEvent); ok {
+			b.checksumAlgorithm = fde.ChecksumAlgorithm
+		}
+		if b.updateCurrentCoordinates(ev) {
+			if !isArtificialEvent(ev) {
 				b.mysqlContext.Stage = models.StageFinishedReadingOneBinlogSwitchingToNextBinlog
-				b.logger.Printf("mysql.reader: Rotate to next log name: %s", rotateEvent.NextLogName)
-			} else {
-				b.logger.Warnf("mysql.reader: fake rotate_event.")
 			}
 		} else {
-			if err := b.handleEvent(ev, entriesChannel); err != nil {
+			b.tablesLock.Lock()
+			err := b.handleEvent(ev, entriesChannel)
+			b.tablesLock.Unlock()
+			if err != nil {
 				return err
 			}
+			b.updateExtractedGtidSet(ev)
+			if b.semiSync != nil && !isArtificialEvent(ev) {
+				b.semiSync.read(gomysql.Position{Name: b.GetCurrentBinlogCoordinates().LogFile, Pos: ev.Header.LogPos})
+			}
 		}
 		span.Finish()
 	}
@@ -914,6 +1356,47 @@ func (b *BinlogReader) DataStreamEvents(entriesChannel chan<- *BinlogEntry) erro
 	return nil
 }
 
+// isArtificialEvent tells whether ev is generated by the source for the binlog dump rather than read from a binlog
+// file, e.g. the ROTATE_EVENT telling the file to start with and the FORMAT_DESCRIPTION_EVENT of the file, sent when a
+// dump starts (also on reconnecting after the source restarted) or when the dump moves to a file which does not begin
+// with a real ROTATE_EVENT.
+func isArtificialEvent(ev *replication.BinlogEvent) bool {
+	return ev.Header.Flags&replication.LOG_EVENT_ARTIFICIAL_F != 0 || ev.Header.LogPos == 0
+}
+
+// updateCurrentCoordinates sets currentCoordinates to the position after ev. It returns true if ev is a ROTATE_EVENT.
+//
+// The header LogPos of a real ROTATE_EVENT is the end of the previous file, and that of an artificial one is 0.
+// Both are ignored: the file and position of the next event are in the event body. Other artificial events
+// have LogPos 0 and leave the position unchanged. In GTID mode the position is only informational.
+func (b *BinlogReader) updateCurrentCoordinates(ev *replication.BinlogEvent) (rotate bool) {
+	b.currentCoordinatesMutex.Lock()
+	defer b.currentCoordinatesMutex.Unlock()
+
+	if ev.Header.EventType != replication.ROTATE_EVENT {
+		if !isArtificialEvent(ev) {
+			b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+		}
+		return false
+	}
+
+	rotateEvent := ev.Event.(*replication.RotateEvent)
+	nextLogName := string(rotateEvent.NextLogName)
+	if isArtificialEvent(ev) {
+		if nextLogName == b.currentCoordinates.LogFile && int64(rotateEvent.Position) == b.currentCoordinates.LogPos {
+			b.logger.Debugf("mysql.reader: fake rotate event at the current position %v:%v", nextLogName, rotateEvent.Position)
+		} else {
+			b.logger.Infof("mysql.reader: fake rotate event. continue from %v:%v (was %v:%v)",
+				nextLogName, rotateEvent.Position, b.currentCoordinates.LogFile, b.currentCoordinates.LogPos)
+		}
+	} else {
+		b.logger.Printf("mysql.reader: Rotate to next log name: %s", nextLogName)
+	}
+	b.currentCoordinates.LogFile = nextLogName
+	b.currentCoordinates.LogPos = int64(rotateEvent.Position)
+	return true
+}
+
 func (b *BinlogReader) BinlogStreamEvents(txChannel chan<- *BinlogTx) error {
 	for {
 		// Check for shutdown
@@ -975,32 +1458,54 @@ func (b *BinlogReader) BinlogStreamEvents(txChannel chan<- *BinlogTx) error {
 		}*/
 		//--------------------------------
 
-		func() {
-			b.currentCoordinatesMutex.Lock()
-			defer b.currentCoordinatesMutex.Unlock()
-			b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
-		}()
-		if ev.Header.EventType == replication.ROTATE_EVENT {
-			if rotateEvent, ok := ev.Event.(*replication.RotateEvent); ok {
-				func() {
-					b.currentCoordinatesMutex.Lock()
-					defer b.currentCoordinatesMutex.Unlock()
-					b.currentCoordinates.LogFile = string(rotateEvent.NextLogName)
-				}()
-				b.logger.Printf("mysql.reader: Rotate to next log name: %s", rotateEvent.NextLogName)
-			} else {
-				b.logger.Warnf("mysql.reader: fake rotate_event.")
-			}
+		if b.updateCurrentCoordinates(ev) {
+			// nothing else to do with a rotate event
 		} else {
 			if err := b.handleBinlogRowsEvent(ev, txChannel); err != nil {
 				return err
 			}
+			b.updateExtractedGtidSet(ev)
 		}
 	}
 
 	return nil
 }
 
+// updateExtractedGtidSet records the GTID set after ev if ev ends a transaction: an XID_EVENT, or the COMMIT
+// of a transaction on non-transactional tables. go-mysql passes the set with these events. Other transactions,
+// e.g. DDL, are recorded with the next one. They are read again on resuming, and skipped by the destination.
+func (b *BinlogReader) updateExtractedGtidSet(ev *replication.BinlogEvent) {
+	var gset gomysql.GTIDSet
+	switch evt := ev.Event.(type) {
+	case *replication.XIDEvent:
+		gset = evt.GSet
+	case *replication.QueryEvent:
+		if strings.ToUpper(strings.TrimSpace(string(evt.Query))) == "COMMIT" {
+			gset = evt.GSet
+		}
+	}
+	if gset != nil {
+		b.setExtractedGtidSet(gset)
+	}
+}
+
+func (b *BinlogReader) setExtractedGtidSet(gset gomysql.GTIDSet) {
+	b.extractedGtidSetMutex.Lock()
+	defer b.extractedGtidSetMutex.Unlock()
+	b.extractedGtidSet = gset
+}
+
+// GetExtractedGtidSet returns the GTID set of the transactions read to their end. It is empty if the binlog
+// is not streamed by GTID.
+func (b *BinlogReader) GetExtractedGtidSet() string {
+	b.extractedGtidSetMutex.Lock()
+	defer b.extractedGtidSetMutex.Unlock()
+	if b.extractedGtidSet == nil {
+		return ""
+	}
+	return b.extractedGtidSet.String()
+}
+
 func (b *BinlogReader) handleBinlogRowsEvent(ev *replication.BinlogEvent, txChannel chan<- *BinlogTx) error {
 	//bp.logger.Printf("[TEST] currentCoordinates: %v,LastAppliedRowsEventHint: %v",bp.currentCoordinates,bp.LastAppliedRowsEventHint)
 	if b.currentCoordinates.SmallerThanOrEquals(&b.LastAppliedRowsEventHint) {
@@ -1309,9 +1814,9 @@ func resolveDDLSQL(sql string) (result parseDDLResult, err error) {
 		for _, t := range v.Tables {
 			var db string
 			if t.Schema.O != "" {
-				db = fmt.Sprintf("%s.", t.Schema.O)
+				db = fmt.Sprintf("%s.", mysql.EscapeName(t.Schema.O))
 			}
-			s := fmt.Sprintf("drop table %s %s`%s`", ex, db, t.Name.O)
+			s := fmt.Sprintf("drop table %s %s%s", ex, db, mysql.EscapeName(t.Name.O))
 			appendSql(s, t.Schema.O, t.Name.O)
 		}
 	case *ast.CreateUserStmt, *ast.GrantStmt:
@@ -1323,15 +1828,21 @@ func resolveDDLSQL(sql string) (result parseDDLResult, err error) {
 	return result, nil
 }
 
+// skipDropStatement reports a DROP DATABASE, DROP TABLE or TRUNCATE skipped by SkipDropStatements.
+func (b *BinlogReader) skipDropStatement(sql string) {
+	gtid := b.currentCoordinates.GetGtidForThisTx()
+	b.logger.Warnf("mysql.reader: SkipDropStatements: skipped %v. gtid: %v", sql, gtid)
+	if b.execCtx != nil {
+		b.execCtx.Emit("SkipDropStatements: skipped %v (gtid %v). it is not applied on the destination", sql, gtid)
+	}
+}
+
 func (b *BinlogReader) skipQueryDDL(sql string, schema string, tableName string) bool {
+	if b.mysqlContext.IsExcludedSystemSchema(schema) {
+		return !(strings.ToLower(schema) == "mysql" && b.mysqlContext.ExpandSyntaxSupport)
+	}
 	switch strings.ToLower(schema) {
-	case "mysql":
-		if b.mysqlContext.ExpandSyntaxSupport {
-			return false
-		} else {
-			return true
-		}
-	case "sys", "information_schema", "performance_schema", g.DtleSchemaName:
+	case g.DtleSchemaName:
 		return true
 	default:
 		if len(b.mysqlContext.ReplicateDoDb) > 0 {
@@ -1409,11 +1920,10 @@ func (b *BinlogReader) skipEvent(schema string, table string) bool {
 		return true
 	default:
 		if len(b.mysqlContext.ReplicateDoDb) > 0 {
-			table = strings.ToLower(table)
 			//if table in tartget Table, do this event
 			for _, d := range b.mysqlContext.ReplicateDoDb {
 				if b.matchString(d.TableSchema, schema) || d.TableSchema == "" {
-					if len(d.Tables) == 0 {
+					if len(d.Tables) == 0 && len(d.TablePatterns) == 0 {
 						return false
 					}
 					for _, dt := range d.Tables {
@@ -1421,12 +1931,16 @@ func (b *BinlogReader) skipEvent(schema string, table string) bool {
 							return false
 						}
 					}
+					for _, dt := range d.TablePatterns {
+						if b.matchString(dt.TableName, table) {
+							return false
+						}
+					}
 				}
 			}
 			return true
 		}
 		if len(b.mysqlContext.ReplicateIgnoreDb) > 0 {
-			table = strings.ToLower(table)
 			//if table in tartget Table, do this event
 			for _, d := range b.mysqlContext.ReplicateIgnoreDb {
 				if b.matchString(d.TableSchema, schema) || d.TableSchema == "" {
@@ -1458,7 +1972,14 @@ func skipMysqlSchemaEvent(tableLower string) bool {
 func (b *BinlogReader) skipRowEvent(rowsEvent *replication.RowsEvent, dml EventDML) (bool, *config.TableContext) {
 	tableOrigin := string(rowsEvent.Table.Table)
 	tableLower := strings.ToLower(tableOrigin)
-	switch strings.ToLower(string(rowsEvent.Table.Schema)) {
+	schemaLower := strings.ToLower(string(rowsEvent.Table.Schema))
+	if b.mysqlContext.IsExcludedSystemSchema(schemaLower) {
+		if schemaLower == "mysql" && b.mysqlContext.ExpandSyntaxSupport {
+			return skipMysqlSchemaEvent(tableLower), nil
+		}
+		return true, nil
+	}
+	switch schemaLower {
 	case g.DtleSchemaName:
 		if strings.ToLower(string(rowsEvent.Table.Table)) == g.GtidExecutedTableV2 ||
 			strings.ToLower(string(rowsEvent.Table.Table)) == g.GtidExecutedTableV3 {
@@ -1486,20 +2007,12 @@ func (b *BinlogReader) skipRowEvent(rowsEvent *replication.RowsEvent, dml EventD
 			}
 		}
 		return true, nil
-	case "mysql":
-		if b.mysqlContext.ExpandSyntaxSupport {
-			return skipMysqlSchemaEvent(tableLower), nil
-		} else {
-			return true, nil
-		}
-	case "sys", "information_schema", "performance_schema":
-		return true, nil
 	default:
 		if len(b.tables) > 0 {
 			//if table in tartget Table, do this event
 			for schemaName, tableMap := range b.tables {
 				if b.matchString(schemaName, string(rowsEvent.Table.Schema)) || schemaName == "" {
-					if len(tableMap) == 0 {
+					if len(tableMap) == 0 && !b.hasTablePatterns(schemaName) {
 						return false, nil // TODO not skipping but TableContext
 					}
 					for tableName, tableCtx := range tableMap {
@@ -1512,7 +2025,7 @@ func (b *BinlogReader) skipRowEvent(rowsEvent *replication.RowsEvent, dml EventD
 			return true, nil
 		}
 		if len(b.mysqlContext.ReplicateIgnoreDb) > 0 {
-			table := strings.ToLower(string(rowsEvent.Table.Table))
+			table := string(rowsEvent.Table.Table)
 			//if table in tartget Table, do this event
 			for _, d := range b.mysqlContext.ReplicateIgnoreDb {
 				if b.matchString(d.TableSchema, string(rowsEvent.Table.Schema)) || d.TableSchema == "" {
@@ -1532,11 +2045,34 @@ func (b *BinlogReader) skipRowEvent(rowsEvent *replication.RowsEvent, dml EventD
 	return false, nil
 }
 
+// matchString matches a schema or table name. See config.MatchTableName.
 func (b *BinlogReader) matchString(pattern string, t string) bool {
 	/*if re, ok := b.ReMap[pattern]; ok {
 		return re.MatchString(t)
 	}*/
-	return pattern == t
+	return config.MatchTableName(pattern, t, b.mysqlContext.LowerCaseTableNames != 0)
+}
+
+func (b *BinlogReader) hasTablePatterns(schemaName string) bool {
+	for _, d := range b.mysqlContext.ReplicateDoDb {
+		if d.TableSchema == schemaName && len(d.TablePatterns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// addTableFromPatterns adds a new table of schema to its tables if the name matches any of its table patterns.
+func (b *BinlogReader) addTableFromPatterns(schema *config.DataSource, tableName string) *config.Table {
+	for _, pattern := range schema.TablePatterns {
+		if b.matchString(pattern.TableName, tableName) {
+			table := config.NewTableFromPattern(pattern, tableName, b.mysqlContext.LowerCaseTableNames != 0)
+			schema.Tables = append(schema.Tables, table)
+			b.logger.Infof("mysql.reader: new table %v.%v matches %v", schema.TableSchema, tableName, pattern.TableName)
+			return table
+		}
+	}
+	return nil
 }
 
 func (b *BinlogReader) matchDB(patternDBS []*config.DataSource, a string) bool {
@@ -1550,9 +2086,14 @@ func (b *BinlogReader) matchDB(patternDBS []*config.DataSource, a string) bool {
 
 func (b *BinlogReader) matchTable(patternTBS []*config.DataSource, schemaName string, tableName string) bool {
 	for _, pdb := range patternTBS {
-		if pdb.TableSchemaScope == "schema" && pdb.TableSchema == schemaName {
+		if pdb.TableSchemaScope == "schema" && b.matchString(pdb.TableSchema, schemaName) {
 			return true
 		}
+		for _, ptb := range pdb.TablePatterns {
+			if b.matchString(pdb.TableSchema, schemaName) && b.matchString(ptb.TableName, tableName) {
+				return true
+			}
+		}
 		if pdb.TableSchemaScope == "schemas" {
 			reg := regexp.MustCompile(pdb.TableSchemaRegex)
 			if reg.MatchString(schemaName) {
@@ -1581,11 +2122,11 @@ func (b *BinlogReader) matchTable(patternTBS []*config.DataSource, schemaName st
 
 			//create database or drop database
 			if tableName == "" {
-				if schemaName == pdb.TableSchema {
+				if b.matchString(pdb.TableSchema, schemaName) {
 					return true
 				}
 			}
-			if ptb.TableSchema == schemaName && ptb.TableName == tableName {
+			if b.matchString(utils.StringElse(ptb.TableSchema, pdb.TableSchema), schemaName) && b.matchString(ptb.TableName, tableName) {
 				return true
 			}
 			if pdb.TableSchemaScope == "tables" {
@@ -1600,30 +2141,6 @@ func (b *BinlogReader) matchTable(patternTBS []*config.DataSource, schemaName st
 	return false
 }
 
-func (b *BinlogReader) genRegexMap() {
-	for _, db := range b.mysqlContext.ReplicateDoDb {
-		if db.TableSchemaScope != "tables" || db.TableSchemaScope != "schemas" {
-			continue
-		}
-		if _, ok := b.ReMap[db.TableSchema]; !ok {
-			b.ReMap[db.TableSchema] = regexp.MustCompile(db.TableSchema[1:])
-		}
-
-		for _, tb := range db.Tables {
-			/*if tb.TableName[0] == '~' {*/
-			if _, ok := b.ReMap[tb.TableName]; !ok {
-				b.ReMap[tb.TableName] = regexp.MustCompile(tb.TableName[1:])
-			}
-			/*}*/
-			/*if tb.TableSchema[0] == '~' {*/
-			if _, ok := b.ReMap[tb.TableSchema]; !ok {
-				b.ReMap[tb.TableSchema] = regexp.MustCompile(tb.TableSchema[1:])
-			}
-			/*	}*/
-		}
-	}
-}
-
 func (b *BinlogReader) Close() error {
 	b.logger.Debugf("*** BinlogReader.Close")
 	b.shutdownLock.Lock()
@@ -1636,6 +2153,9 @@ func (b *BinlogReader) Close() error {
 	close(b.shutdownCh)
 
 	b.wg.Wait()
+	if b.serverIdRegistered {
+		unregisterServerId(uint32(b.serverId), b.execCtx.Subject)
+	}
 	if err := sql.CloseDB(b.db); err != nil {
 		return err
 	}
@@ -1676,7 +2196,7 @@ func (b *BinlogReader) updateTableMeta(table *config.Table, realSchema string, t
 	tableMap := b.getDbTableMap(realSchema)
 	err = b.addTableToTableMap(tableMap, table)
 	if err != nil {
-		b.logger.Error("failed to make table context: %v", err)
+		b.logger.Errorf("failed to make table context: %v", err)
 		return err
 	}
 
@@ -1721,7 +2241,6 @@ func (b *BinlogReader) checkObjectFitRegexp(patternTBS []*config.DataSource, sch
 					table.TableRename = string(reg.ExpandString(nil, ptb.TableRenameRegex, tableName, match))
 					b.mysqlContext.ReplicateDoDb[i].Tables = append(b.mysqlContext.ReplicateDoDb[i].Tables, table)
 				}
-				//b.genRegexMap()
 				break
 			}
 		}
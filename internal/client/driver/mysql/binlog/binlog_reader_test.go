@@ -8,18 +8,24 @@ package binlog
 
 import (
 	"bytes"
-	gosql "database/sql"
+	"encoding/binary"
+	"fmt"
 	"reflect"
-	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+
+	"github.com/actiontech/dtle/internal"
+	"github.com/actiontech/dtle/internal/client/driver/common"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	sqle "github.com/actiontech/dtle/internal/client/driver/mysql/sqle/inspector"
 	"github.com/actiontech/dtle/internal/config"
 	"github.com/actiontech/dtle/internal/config/mysql"
-	log "github.com/actiontech/dtle/internal/logger"
 
 	gomysql "github.com/siddontang/go-mysql/mysql"
 	"github.com/siddontang/go-mysql/replication"
+	"github.com/sirupsen/logrus"
 )
 
 func TestParseMysqlGTIDSet(t *testing.T) {
@@ -56,8 +62,11 @@ func TestParseMysqlGTIDSet(t *testing.T) {
 
 func TestNewMySQLReader(t *testing.T) {
 	type args struct {
-		cfg    *config.MySQLDriverConfig
-		logger *log.Entry
+		execCtx       *common.ExecContext
+		cfg           *config.MySQLDriverConfig
+		logger        *logrus.Entry
+		replicateDoDb []*config.DataSource
+		sqleContext   *sqle.Context
 	}
 	tests := []struct {
 		name             string
@@ -69,7 +78,7 @@ func TestNewMySQLReader(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotBinlogReader, err := NewMySQLReader(tt.args.cfg, tt.args.logger)
+			gotBinlogReader, err := NewMySQLReader(tt.args.execCtx, tt.args.cfg, tt.args.logger, tt.args.replicateDoDb, tt.args.sqleContext)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewMySQLReader() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -82,35 +91,12 @@ func TestNewMySQLReader(t *testing.T) {
 }
 
 func TestBinlogReader_ConnectBinlogStreamer(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
-		coordinates base.BinlogCoordinateTx
+		coordinates base.BinlogCoordinatesX
 	}
 	tests := []struct {
 		name    string
-		fields  fields
+		b       *BinlogReader
 		args    args
 		wantErr bool
 	}{
@@ -118,30 +104,7 @@ func TestBinlogReader_ConnectBinlogStreamer(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if err := b.ConnectBinlogStreamer(tt.args.coordinates); (err != nil) != tt.wantErr {
+			if err := tt.b.ConnectBinlogStreamer(tt.args.coordinates); (err != nil) != tt.wantErr {
 				t.Errorf("BinlogReader.ConnectBinlogStreamer() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -149,62 +112,16 @@ func TestBinlogReader_ConnectBinlogStreamer(t *testing.T) {
 }
 
 func TestBinlogReader_GetCurrentBinlogCoordinates(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   *base.BinlogCoordinateTx
+		name string
+		b    *BinlogReader
+		want *base.BinlogCoordinateTx
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if got := b.GetCurrentBinlogCoordinates(); !reflect.DeepEqual(got, tt.want) {
+			if got := tt.b.GetCurrentBinlogCoordinates(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("BinlogReader.GetCurrentBinlogCoordinates() = %v, want %v", got, tt.want)
 			}
 		})
@@ -212,36 +129,13 @@ func TestBinlogReader_GetCurrentBinlogCoordinates(t *testing.T) {
 }
 
 func TestBinlogReader_handleRowsEvent(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		ev             *replication.BinlogEvent
 		entriesChannel chan<- *BinlogEntry
 	}
 	tests := []struct {
 		name    string
-		fields  fields
+		b       *BinlogReader
 		args    args
 		wantErr bool
 	}{
@@ -249,30 +143,7 @@ func TestBinlogReader_handleRowsEvent(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if err := b.handleEvent(tt.args.ev, tt.args.entriesChannel); (err != nil) != tt.wantErr {
+			if err := tt.b.handleEvent(tt.args.ev, tt.args.entriesChannel); (err != nil) != tt.wantErr {
 				t.Errorf("BinlogReader.handleRowsEvent() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -280,35 +151,12 @@ func TestBinlogReader_handleRowsEvent(t *testing.T) {
 }
 
 func TestBinlogReader_DataStreamEvents(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		entriesChannel chan<- *BinlogEntry
 	}
 	tests := []struct {
 		name    string
-		fields  fields
+		b       *BinlogReader
 		args    args
 		wantErr bool
 	}{
@@ -316,30 +164,7 @@ func TestBinlogReader_DataStreamEvents(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if err := b.DataStreamEvents(tt.args.entriesChannel); (err != nil) != tt.wantErr {
+			if err := tt.b.DataStreamEvents(tt.args.entriesChannel); (err != nil) != tt.wantErr {
 				t.Errorf("BinlogReader.DataStreamEvents() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -347,35 +172,12 @@ func TestBinlogReader_DataStreamEvents(t *testing.T) {
 }
 
 func TestBinlogReader_BinlogStreamEvents(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		txChannel chan<- *BinlogTx
 	}
 	tests := []struct {
 		name    string
-		fields  fields
+		b       *BinlogReader
 		args    args
 		wantErr bool
 	}{
@@ -383,30 +185,7 @@ func TestBinlogReader_BinlogStreamEvents(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if err := b.BinlogStreamEvents(tt.args.txChannel); (err != nil) != tt.wantErr {
+			if err := tt.b.BinlogStreamEvents(tt.args.txChannel); (err != nil) != tt.wantErr {
 				t.Errorf("BinlogReader.BinlogStreamEvents() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -414,36 +193,13 @@ func TestBinlogReader_BinlogStreamEvents(t *testing.T) {
 }
 
 func TestBinlogReader_handleBinlogRowsEvent(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		ev        *replication.BinlogEvent
 		txChannel chan<- *BinlogTx
 	}
 	tests := []struct {
 		name    string
-		fields  fields
+		b       *BinlogReader
 		args    args
 		wantErr bool
 	}{
@@ -451,30 +207,7 @@ func TestBinlogReader_handleBinlogRowsEvent(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if err := b.handleBinlogRowsEvent(tt.args.ev, tt.args.txChannel); (err != nil) != tt.wantErr {
+			if err := tt.b.handleBinlogRowsEvent(tt.args.ev, tt.args.txChannel); (err != nil) != tt.wantErr {
 				t.Errorf("BinlogReader.handleBinlogRowsEvent() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -482,65 +215,19 @@ func TestBinlogReader_handleBinlogRowsEvent(t *testing.T) {
 }
 
 func TestBinlogReader_appendQuery(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		query string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
+		name string
+		b    *BinlogReader
+		args args
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			b.appendQuery(tt.args.query)
+			tt.b.appendQuery(tt.args.query)
 		})
 	}
 }
@@ -566,198 +253,61 @@ func Test_newTxWithoutGTIDError(t *testing.T) {
 }
 
 func TestBinlogReader_clearB64Sql(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	tests := []struct {
-		name   string
-		fields fields
+		name string
+		b    *BinlogReader
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			b.clearB64Sql()
+			tt.b.clearB64Sql()
 		})
 	}
 }
 
 func TestBinlogReader_appendB64Sql(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		event *BinlogEvent
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
+		name string
+		b    *BinlogReader
+		args args
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			b.appendB64Sql(tt.args.event)
+			tt.b.appendB64Sql(tt.args.event)
 		})
 	}
 }
 
 func TestBinlogReader_onCommit(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		lastEvent *BinlogEvent
 		txChannel chan<- *BinlogTx
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
+		name string
+		b    *BinlogReader
+		args args
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			b.onCommit(tt.args.lastEvent, tt.args.txChannel)
+			tt.b.onCommit(tt.args.lastEvent, tt.args.txChannel)
 		})
 	}
 }
 
 func TestGenDDLSQL(t *testing.T) {
 	type args struct {
-		sql    string
-		schema string
+		sql       string
+		schema    string
+		tableName string
 	}
 	tests := []struct {
 		name    string
@@ -786,94 +336,59 @@ func Test_resolveDDLSQL(t *testing.T) {
 		sql string
 	}
 	tests := []struct {
-		name     string
-		args     args
-		wantSqls []string
-		wantOk   bool
-		wantErr  bool
+		name       string
+		args       args
+		wantSqls   []string
+		wantTables []SchemaTable
+		wantIsDDL  bool
+		wantErr    bool
 	}{
-		// TODO: Add test cases.
-		{"t1", args{"alter TABLE aly_test ADD COLUMN (name5 CHAR(5) ,name6 char(6));"}, []string{}, true, false},
+		{"alter", args{"alter TABLE aly_test ADD COLUMN (name5 CHAR(5) ,name6 char(6));"},
+			[]string{"alter TABLE aly_test ADD COLUMN (name5 CHAR(5) ,name6 char(6));"},
+			[]SchemaTable{{Table: "aly_test"}}, true, false},
+		{"drop tables", args{"drop table if exists db1.t1, t2"},
+			[]string{"drop table if exists `db1`.`t1`", "drop table if exists `t2`"},
+			[]SchemaTable{{Schema: "db1", Table: "t1"}, {Table: "t2"}}, true, false},
+		{"not ddl", args{"insert into t1 values (1)"}, []string{"insert into t1 values (1)"}, nil, false, false},
+		{"bad sql", args{"alter tablex t1"}, []string{"alter tablex t1"}, nil, false, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotSqls, gotOk, err := resolveDDLSQL(tt.args.sql)
+			got, err := resolveDDLSQL(tt.args.sql)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("resolveDDLSQL() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(gotSqls, tt.wantSqls) {
-				t.Errorf("resolveDDLSQL() gotSqls = %v, want %v", gotSqls, tt.wantSqls)
+			if !reflect.DeepEqual(got.sqls, tt.wantSqls) {
+				t.Errorf("resolveDDLSQL() sqls = %v, want %v", got.sqls, tt.wantSqls)
 			}
-			if gotOk != tt.wantOk {
-				t.Errorf("resolveDDLSQL() gotOk = %v, want %v", gotOk, tt.wantOk)
+			if !reflect.DeepEqual(got.tables, tt.wantTables) {
+				t.Errorf("resolveDDLSQL() tables = %v, want %v", got.tables, tt.wantTables)
+			}
+			if got.isDDL != tt.wantIsDDL {
+				t.Errorf("resolveDDLSQL() isDDL = %v, want %v", got.isDDL, tt.wantIsDDL)
 			}
 		})
 	}
 }
 
 func TestBinlogReader_skipQueryDDL(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
-		sql    string
-		schema string
+		sql       string
+		schema    string
+		tableName string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
+		name string
+		b    *BinlogReader
+		args args
+		want bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if got := b.skipQueryDDL(tt.args.sql, tt.args.schema); got != tt.want {
+			if got := tt.b.skipQueryDDL(tt.args.sql, tt.args.schema, tt.args.tableName); got != tt.want {
 				t.Errorf("BinlogReader.skipQueryDDL() = %v, want %v", got, tt.want)
 			}
 		})
@@ -901,67 +416,21 @@ func Test_skipQueryEvent(t *testing.T) {
 }
 
 func TestBinlogReader_skipRowEvent(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		schema string
 		table  string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
+		name string
+		b    *BinlogReader
+		args args
+		want bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if got := b.skipEvent(tt.args.schema, tt.args.table); got != tt.want {
+			if got := tt.b.skipEvent(tt.args.schema, tt.args.table); got != tt.want {
 				t.Errorf("BinlogReader.skipRowEvent() = %v, want %v", got, tt.want)
 			}
 		})
@@ -969,67 +438,21 @@ func TestBinlogReader_skipRowEvent(t *testing.T) {
 }
 
 func TestBinlogReader_matchString(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		pattern string
 		t       string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
+		name string
+		b    *BinlogReader
+		args args
+		want bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if got := b.matchString(tt.args.pattern, tt.args.t); got != tt.want {
+			if got := tt.b.matchString(tt.args.pattern, tt.args.t); got != tt.want {
 				t.Errorf("BinlogReader.matchString() = %v, want %v", got, tt.want)
 			}
 		})
@@ -1037,67 +460,21 @@ func TestBinlogReader_matchString(t *testing.T) {
 }
 
 func TestBinlogReader_matchDB(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		patternDBS []*config.DataSource
 		a          string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
+		name string
+		b    *BinlogReader
+		args args
+		want bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if got := b.matchDB(tt.args.patternDBS, tt.args.a); got != tt.want {
+			if got := tt.b.matchDB(tt.args.patternDBS, tt.args.a); got != tt.want {
 				t.Errorf("BinlogReader.matchDB() = %v, want %v", got, tt.want)
 			}
 		})
@@ -1105,62 +482,16 @@ func TestBinlogReader_matchDB(t *testing.T) {
 }
 
 func TestBinlogReader_Close(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	tests := []struct {
 		name    string
-		fields  fields
+		b       *BinlogReader
 		wantErr bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if err := b.Close(); (err != nil) != tt.wantErr {
+			if err := tt.b.Close(); (err != nil) != tt.wantErr {
 				t.Errorf("BinlogReader.Close() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -1168,69 +499,1033 @@ func TestBinlogReader_Close(t *testing.T) {
 }
 
 func TestBinlogReader_matchTable(t *testing.T) {
-	type fields struct {
-		logger                   *log.Entry
-		connectionConfig         *mysql.ConnectionConfig
-		db                       *gosql.DB
-		binlogSyncer             *replication.BinlogSyncer
-		binlogStreamer           *replication.BinlogStreamer
-		currentCoordinates       base.BinlogCoordinateTx
-		currentCoordinatesMutex  *sync.Mutex
-		LastAppliedRowsEventHint base.BinlogCoordinateTx
-		MysqlContext             *config.MySQLDriverConfig
-		currentTx                *BinlogTx
-		currentBinlogEntry       *BinlogEntry
-		txCount                  int
-		currentFde               string
-		currentQuery             *bytes.Buffer
-		currentSqlB64            *bytes.Buffer
-		appendB64SqlBs           []byte
-		ReMap                    map[string]*regexp.Regexp
-		wg                       sync.WaitGroup
-		shutdown                 bool
-		shutdownCh               chan struct{}
-		shutdownLock             sync.Mutex
-	}
 	type args struct {
 		patternTBS []*config.DataSource
-		t          config.DataSource
+		schemaName string
+		tableName  string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
+		name string
+		b    *BinlogReader
+		args args
+		want bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := &BinlogReader{
-				logger:                   tt.fields.logger,
-				connectionConfig:         tt.fields.connectionConfig,
-				db:                       tt.fields.db,
-				binlogSyncer:             tt.fields.binlogSyncer,
-				binlogStreamer:           tt.fields.binlogStreamer,
-				currentCoordinates:       tt.fields.currentCoordinates,
-				currentCoordinatesMutex:  tt.fields.currentCoordinatesMutex,
-				LastAppliedRowsEventHint: tt.fields.LastAppliedRowsEventHint,
-				MysqlContext:             tt.fields.MysqlContext,
-				currentTx:                tt.fields.currentTx,
-				currentBinlogEntry:       tt.fields.currentBinlogEntry,
-				txCount:                  tt.fields.txCount,
-				currentFde:               tt.fields.currentFde,
-				currentQuery:             tt.fields.currentQuery,
-				currentSqlB64:            tt.fields.currentSqlB64,
-				appendB64SqlBs:           tt.fields.appendB64SqlBs,
-				ReMap:                    tt.fields.ReMap,
-				wg:                       tt.fields.wg,
-				shutdown:                 tt.fields.shutdown,
-				shutdownCh:               tt.fields.shutdownCh,
-				shutdownLock:             tt.fields.shutdownLock,
-			}
-			if got := b.matchTable(tt.args.patternTBS, config.Table{}); got != tt.want {
+			if got := tt.b.matchTable(tt.args.patternTBS, tt.args.schemaName, tt.args.tableName); got != tt.want {
 				t.Errorf("BinlogReader.matchTable() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestToColumnValuesV2_Enum(t *testing.T) {
+	// The member order on the destination is different: enum('c','b','a').
+	// The string value is sent, so the destination stores the same member.
+	columns := mysql.NewColumnList([]mysql.Column{
+		{RawName: "e", Type: mysql.EnumColumnType, ColumnType: "enum('a','b','c')",
+			EnumValues: mysql.ParseEnumValues("enum('a','b','c')")},
+		{RawName: "s", Type: mysql.SetColumnType, ColumnType: "set('a','b','c')",
+			EnumValues: mysql.ParseEnumValues("set('a','b','c')")},
+	})
+	table := &config.TableContext{Table: &config.Table{OriginalTableColumns: columns}}
+
+	got := ToColumnValuesV2([]interface{}{int64(1), int64(6)}, table)
+	want := []interface{}{"a", "b,c"}
+	for i := range want {
+		if *got.AbstractValues[i] != want[i] {
+			t.Errorf("ToColumnValuesV2() [%v] = %v, want %v", i, *got.AbstractValues[i], want[i])
+		}
+	}
+}
+
+func TestIsBinlogPurgedError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"gtid purged", &gomysql.MyError{Code: gomysql.ER_MASTER_FATAL_ERROR_READING_BINLOG,
+			Message: "The slave is connecting using CHANGE MASTER TO MASTER_AUTO_POSITION = 1," +
+				" but the master has purged binary logs containing GTIDs that the slave requires."}, true},
+		{"file purged", &gomysql.MyError{Code: gomysql.ER_MASTER_FATAL_ERROR_READING_BINLOG,
+			Message: "Could not find first log file name in binary log index file"}, true},
+		{"other 1236", &gomysql.MyError{Code: gomysql.ER_MASTER_FATAL_ERROR_READING_BINLOG,
+			Message: "binlog truncated in the middle of event"}, false},
+		{"other code", &gomysql.MyError{Code: gomysql.ER_ACCESS_DENIED_ERROR, Message: "purged"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinlogPurgedError(tt.err); got != tt.want {
+				t.Errorf("IsBinlogPurgedError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBinlogReader_ReplicateDMLDDL(t *testing.T) {
+	sid := []byte("0123456789abcdef")
+	gtidEvent := func(gno int64) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.GTID_EVENT},
+			Event:  &replication.GTIDEvent{SID: sid, GNO: gno},
+		}
+	}
+	queryEvent := func(query string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+			Event:  &replication.QueryEvent{Schema: []byte("db1"), Query: []byte(query)},
+		}
+	}
+	// A mixed workload: a DDL transaction and a DML transaction.
+	events := []*replication.BinlogEvent{
+		gtidEvent(1),
+		queryEvent("create database db1"),
+		gtidEvent(2),
+		queryEvent("BEGIN"),
+		{
+			Header: &replication.EventHeader{EventType: replication.WRITE_ROWS_EVENTv2},
+			Event: &replication.RowsEvent{
+				Table:       &replication.TableMapEvent{Schema: []byte("db1"), Table: []byte("tb1")},
+				ColumnCount: 1,
+				Rows:        [][]interface{}{{int64(1)}},
+			},
+			RawData: make([]byte, 16),
+		},
+		{
+			Header: &replication.EventHeader{EventType: replication.XID_EVENT},
+			Event:  &replication.XIDEvent{},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		replicateDML bool
+		replicateDDL bool
+		wantDML      int
+		wantDDL      int
+	}{
+		{"both", true, true, 1, 1},
+		{"dml only", true, false, 1, 0},
+		{"ddl only", false, true, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.MySQLDriverConfig{
+				ReplicateDML: internal.BoolToPtr(tt.replicateDML),
+				ReplicateDDL: internal.BoolToPtr(tt.replicateDDL),
+
+				ConnectionConfig: &mysql.ConnectionConfig{},
+			}
+			b, err := NewMySQLReader(&common.ExecContext{}, cfg, logrus.NewEntry(logrus.New()), nil, sqle.NewContext(nil))
+			if err != nil {
+				t.Fatalf("NewMySQLReader() error = %v", err)
+			}
+
+			entriesChannel := make(chan *BinlogEntry, 10)
+			for i, ev := range events {
+				// as done by the streamer loop
+				ev.Header.LogPos = uint32(100 * (i + 1))
+				b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+				if err := b.handleEvent(ev, entriesChannel); err != nil {
+					t.Fatalf("handleEvent() error = %v", err)
+				}
+			}
+			close(entriesChannel)
+
+			nEntries, nDML, nDDL := 0, 0, 0
+			for entry := range entriesChannel {
+				nEntries++
+				for _, event := range entry.Events {
+					if event.DML == NotDML {
+						nDDL++
+					} else {
+						nDML++
+					}
+				}
+			}
+			// Filtered transactions are still sent to keep track of the GTID.
+			if nEntries != 2 {
+				t.Errorf("got %v entries, want 2", nEntries)
+			}
+			if nDML != tt.wantDML || nDDL != tt.wantDDL {
+				t.Errorf("got %v DML and %v DDL events, want %v and %v", nDML, nDDL, tt.wantDML, tt.wantDDL)
+			}
+		})
+	}
+}
+
+func TestBinlogReader_systemSchemas(t *testing.T) {
+	sid := []byte("0123456789abcdef")
+	rowsEvent := func(schema string, table string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.WRITE_ROWS_EVENTv2},
+			Event: &replication.RowsEvent{
+				Table:       &replication.TableMapEvent{Schema: []byte(schema), Table: []byte(table)},
+				ColumnCount: 1,
+				Rows:        [][]interface{}{{int64(1)}},
+			},
+			RawData: make([]byte, 16),
+		}
+	}
+	// One transaction changing a system table and a user table.
+	events := []*replication.BinlogEvent{
+		{
+			Header: &replication.EventHeader{EventType: replication.GTID_EVENT},
+			Event:  &replication.GTIDEvent{SID: sid, GNO: 1},
+		},
+		{
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+			Event:  &replication.QueryEvent{Schema: []byte("db1"), Query: []byte("BEGIN")},
+		},
+		rowsEvent("mysql", "user"),
+		rowsEvent("sys", "sys_config"),
+		rowsEvent("db1", "tb1"),
+		{
+			Header: &replication.EventHeader{EventType: replication.XID_EVENT},
+			Event:  &replication.XIDEvent{},
+		},
+	}
+
+	tests := []struct {
+		name                   string
+		replicateSystemSchemas []string
+		want                   []string
+	}{
+		{"default", nil, []string{"db1"}},
+		{"mysql enabled", []string{"mysql"}, []string{"mysql", "db1"}},
+		{"all enabled", []string{"MySQL", "sys"}, []string{"mysql", "sys", "db1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.MySQLDriverConfig{
+				ReplicateSystemSchemas: tt.replicateSystemSchemas,
+
+				ConnectionConfig: &mysql.ConnectionConfig{},
+			}
+			b, err := NewMySQLReader(&common.ExecContext{}, cfg, logrus.NewEntry(logrus.New()), nil, sqle.NewContext(nil))
+			if err != nil {
+				t.Fatalf("NewMySQLReader() error = %v", err)
+			}
+
+			entriesChannel := make(chan *BinlogEntry, 10)
+			for i, ev := range events {
+				ev.Header.LogPos = uint32(100 * (i + 1))
+				b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+				if err := b.handleEvent(ev, entriesChannel); err != nil {
+					t.Fatalf("handleEvent() error = %v", err)
+				}
+			}
+			close(entriesChannel)
+
+			var got []string
+			for entry := range entriesChannel {
+				for _, event := range entry.Events {
+					got = append(got, event.DatabaseName)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events on %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBinlogReader_TableNameRegex(t *testing.T) {
+	// As set by the extractor: log_202512 matched the pattern at start.
+	doDb := func() []*config.DataSource {
+		return []*config.DataSource{{
+			TableSchema:      "db1",
+			TableSchemaScope: "table",
+			Tables: []*config.Table{
+				{TableSchema: "db1", TableName: "Orders", Where: "true"},
+				{TableSchema: "db1", TableName: "log_202512", Where: "true"},
+			},
+			TablePatterns: []*config.Table{
+				{TableSchema: "db1", TableName: "regex:^log_[0-9]{6}$", Where: "true"},
+			},
+		}}
+	}
+	ignoreDb := func() []*config.DataSource {
+		return []*config.DataSource{{
+			TableSchema: "db1",
+			Tables:      []*config.Table{{TableName: "regex:^log_"}},
+		}}
+	}
+	newReader := func(t *testing.T, lowerCaseTableNames int, doDb, ignoreDb []*config.DataSource) *BinlogReader {
+		cfg := &config.MySQLDriverConfig{
+			ReplicateDoDb:       doDb,
+			ReplicateIgnoreDb:   ignoreDb,
+			LowerCaseTableNames: lowerCaseTableNames,
+			ConnectionConfig:    &mysql.ConnectionConfig{},
+		}
+		b, err := NewMySQLReader(&common.ExecContext{}, cfg, logrus.NewEntry(logrus.New()), doDb, sqle.NewContext(nil))
+		if err != nil {
+			t.Fatalf("NewMySQLReader() error = %v", err)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name                string
+		lowerCaseTableNames int
+		ignore              bool
+		schema              string
+		table               string
+		wantSkip            bool
+	}{
+		{"literal", 0, false, "db1", "Orders", false},
+		{"literal in another case", 0, false, "db1", "orders", true},
+		{"literal in another case, lower_case_table_names=1", 1, false, "db1", "orders", false},
+		{"matched at start", 0, false, "db1", "log_202512", false},
+		{"not matched", 0, false, "db1", "log_2025", true},
+		{"another schema", 0, false, "db2", "log_202512", true},
+		{"ignored regex", 0, true, "db1", "log_202512", true},
+		{"ignored regex in another case", 0, true, "db1", "LOG_202512", false},
+		{"ignored regex in another case, lower_case_table_names=1", 1, true, "db1", "LOG_202512", true},
+		{"not ignored", 0, true, "db1", "orders", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b *BinlogReader
+			if tt.ignore {
+				b = newReader(t, tt.lowerCaseTableNames, nil, ignoreDb())
+			} else {
+				b = newReader(t, tt.lowerCaseTableNames, doDb(), nil)
+			}
+			rowsEvent := &replication.RowsEvent{
+				Table: &replication.TableMapEvent{Schema: []byte(tt.schema), Table: []byte(tt.table)},
+			}
+			if got, _ := b.skipRowEvent(rowsEvent, InsertDML); got != tt.wantSkip {
+				t.Errorf("BinlogReader.skipRowEvent() = %v, want %v", got, tt.wantSkip)
+			}
+			if got := b.skipQueryDDL("alter table x add column c int", tt.schema, tt.table); got != tt.wantSkip {
+				t.Errorf("BinlogReader.skipQueryDDL() = %v, want %v", got, tt.wantSkip)
+			}
+		})
+	}
+
+	t.Run("table created after start", func(t *testing.T) {
+		doDb := doDb()
+		b := newReader(t, 0, doDb, nil)
+		queries := []string{
+			"create database db1",
+			"create table db1.log_202601 (id int)",
+			"create table db1.tmp_202601 (id int)",
+		}
+		entriesChannel := make(chan *BinlogEntry, 10)
+		for i, query := range queries {
+			for _, ev := range []*replication.BinlogEvent{{
+				Header: &replication.EventHeader{EventType: replication.GTID_EVENT},
+				Event:  &replication.GTIDEvent{SID: []byte("0123456789abcdef"), GNO: int64(i + 1)},
+			}, {
+				Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+				Event:  &replication.QueryEvent{Schema: []byte("db1"), Query: []byte(query)},
+			}} {
+				ev.Header.LogPos = uint32(100 * (i + 1))
+				b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+				if err := b.handleEvent(ev, entriesChannel); err != nil {
+					t.Fatalf("handleEvent() error = %v", err)
+				}
+			}
+		}
+
+		if len(doDb[0].Tables) != 3 || doDb[0].Tables[2].TableName != "log_202601" {
+			t.Fatalf("tables after create table: %v", doDb[0].Tables)
+		}
+		for table, wantSkip := range map[string]bool{"log_202601": false, "tmp_202601": true} {
+			rowsEvent := &replication.RowsEvent{
+				Table: &replication.TableMapEvent{Schema: []byte("db1"), Table: []byte(table)},
+			}
+			if got, _ := b.skipRowEvent(rowsEvent, InsertDML); got != wantSkip {
+				t.Errorf("BinlogReader.skipRowEvent() for %v = %v, want %v", table, got, wantSkip)
+			}
+		}
+	})
+}
+
+func TestBinlogReader_truncateTable(t *testing.T) {
+	sid := []byte("0123456789abcdef")
+	truncateTx := func(gno int64, query string) []*replication.BinlogEvent {
+		return []*replication.BinlogEvent{{
+			Header: &replication.EventHeader{EventType: replication.GTID_EVENT},
+			Event:  &replication.GTIDEvent{SID: sid, GNO: gno},
+		}, {
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+			Event:  &replication.QueryEvent{Schema: []byte("db1"), Query: []byte(query)},
+		}}
+	}
+	var events []*replication.BinlogEvent
+	events = append(events, truncateTx(1, "truncate tb1")...)
+	events = append(events, truncateTx(2, "TRUNCATE TABLE `db1`.`tb1`")...)
+	events = append(events, truncateTx(3, "truncate table db1.tb2")...) // not replicated
+
+	tests := []struct {
+		name         string
+		replicateDML bool
+		replicateDDL bool
+		want         []string
+	}{
+		{"both", true, true, []string{"TRUNCATE TABLE `db1_new`.`tb1_new`", "TRUNCATE TABLE `db1_new`.`tb1_new`"}},
+		// TRUNCATE is replicated as a change of data.
+		{"dml only", true, false, []string{"TRUNCATE TABLE `db1_new`.`tb1_new`", "TRUNCATE TABLE `db1_new`.`tb1_new`"}},
+		{"ddl only", false, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doDb := []*config.DataSource{{
+				TableSchema:       "db1",
+				TableSchemaRename: "db1_new",
+				Tables:            []*config.Table{{TableSchema: "db1", TableName: "tb1", TableRename: "tb1_new"}},
+			}}
+			cfg := &config.MySQLDriverConfig{
+				ReplicateDoDb: doDb,
+				ReplicateDML:  internal.BoolToPtr(tt.replicateDML),
+				ReplicateDDL:  internal.BoolToPtr(tt.replicateDDL),
+
+				ConnectionConfig: &mysql.ConnectionConfig{},
+			}
+			b, err := NewMySQLReader(&common.ExecContext{}, cfg, logrus.NewEntry(logrus.New()), doDb, sqle.NewContext(nil))
+			if err != nil {
+				t.Fatalf("NewMySQLReader() error = %v", err)
+			}
+
+			entriesChannel := make(chan *BinlogEntry, 10)
+			for i, ev := range events {
+				ev.Header.LogPos = uint32(100 * (i + 1))
+				b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+				if err := b.handleEvent(ev, entriesChannel); err != nil {
+					t.Fatalf("handleEvent() error = %v", err)
+				}
+			}
+			close(entriesChannel)
+
+			var got []string
+			for entry := range entriesChannel {
+				for _, event := range entry.Events {
+					if !event.Truncate || event.DatabaseName != "db1_new" || event.TableName != "tb1_new" {
+						t.Errorf("unexpected event %+v", event)
+					}
+					got = append(got, event.Query)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// With SkipDropStatements, the table dropped on the source is kept on the destination: the statement is not sent.
+func TestBinlogReader_skipDropStatements(t *testing.T) {
+	sid := []byte("0123456789abcdef")
+	var events []*replication.BinlogEvent
+	for i, query := range []string{"drop table tb1", "truncate table db1.tb1", "create index i1 on tb1 (c)",
+		"drop database db1", "drop table db1.tb2"} {
+		events = append(events, &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.GTID_EVENT},
+			Event:  &replication.GTIDEvent{SID: sid, GNO: int64(i + 1)},
+		}, &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+			Event:  &replication.QueryEvent{Schema: []byte("db1"), Query: []byte(query)},
+		})
+	}
+
+	tests := []struct {
+		name               string
+		skipDropStatements bool
+		want               []string
+		wantEvents         int
+	}{
+		{"replicated", false, []string{"drop table  `tb1`", "TRUNCATE TABLE `db1`.`tb1`", "create index i1 on tb1 (c)",
+			"drop database db1"}, 0},
+		{"skipped", true, []string{"create index i1 on tb1 (c)"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doDb := []*config.DataSource{{TableSchema: "db1", Tables: []*config.Table{{TableSchema: "db1", TableName: "tb1"}}}}
+			cfg := &config.MySQLDriverConfig{
+				ReplicateDoDb:      doDb,
+				SkipDropStatements: tt.skipDropStatements,
+
+				ConnectionConfig: &mysql.ConnectionConfig{},
+			}
+			var taskEvents []string
+			execCtx := &common.ExecContext{EmitEvent: func(m string, args ...interface{}) {
+				taskEvents = append(taskEvents, fmt.Sprintf(m, args...))
+			}}
+			b, err := NewMySQLReader(execCtx, cfg, logrus.NewEntry(logrus.New()), doDb, sqle.NewContext(nil))
+			if err != nil {
+				t.Fatalf("NewMySQLReader() error = %v", err)
+			}
+
+			entriesChannel := make(chan *BinlogEntry, 10)
+			for i, ev := range events {
+				ev.Header.LogPos = uint32(100 * (i + 1))
+				b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+				if err := b.handleEvent(ev, entriesChannel); err != nil {
+					t.Fatalf("handleEvent() error = %v", err)
+				}
+			}
+			close(entriesChannel)
+
+			var got []string
+			for entry := range entriesChannel {
+				for _, event := range entry.Events {
+					got = append(got, event.Query)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if len(taskEvents) != tt.wantEvents {
+				t.Errorf("task events = %q, want %v", taskEvents, tt.wantEvents)
+			}
+			for _, m := range taskEvents {
+				if !strings.HasPrefix(m, "SkipDropStatements: skipped ") {
+					t.Errorf("task event = %q", m)
+				}
+			}
+		})
+	}
+}
+
+func TestBinlogReader_updateCurrentCoordinates(t *testing.T) {
+	event := func(tp replication.EventType, logPos uint32, flags uint16) *replication.BinlogEvent {
+		return &replication.BinlogEvent{Header: &replication.EventHeader{EventType: tp, LogPos: logPos, Flags: flags}}
+	}
+	rotate := func(logPos uint32, flags uint16, next string, position uint64) *replication.BinlogEvent {
+		ev := event(replication.ROTATE_EVENT, logPos, flags)
+		ev.Event = &replication.RotateEvent{NextLogName: []byte(next), Position: position}
+		return ev
+	}
+	const artificial = replication.LOG_EVENT_ARTIFICIAL_F
+
+	// Connected at bin.000001:1000 in file/pos mode. The source is restarted and begins bin.000002.
+	steps := []struct {
+		ev         *replication.BinlogEvent
+		wantRotate bool
+		wantFile   string
+		wantPos    int64
+	}{
+		{rotate(0, artificial, "bin.000001", 1000), true, "bin.000001", 1000},
+		{event(replication.FORMAT_DESCRIPTION_EVENT, 0, 0), false, "bin.000001", 1000},
+		{event(replication.GTID_EVENT, 1065, 0), false, "bin.000001", 1065},
+		{event(replication.XID_EVENT, 1171, 0), false, "bin.000001", 1171},
+		{event(replication.STOP_EVENT, 1194, 0), false, "bin.000001", 1194},
+		// reconnected after the restart
+		{rotate(0, artificial, "bin.000001", 1194), true, "bin.000001", 1194},
+		{event(replication.FORMAT_DESCRIPTION_EVENT, 0, 0), false, "bin.000001", 1194},
+		// bin.000001 has no real rotate event at its end
+		{rotate(0, artificial, "bin.000002", 4), true, "bin.000002", 4},
+		{event(replication.FORMAT_DESCRIPTION_EVENT, 123, 0), false, "bin.000002", 123},
+		{event(replication.PREVIOUS_GTIDS_EVENT, 154, 0), false, "bin.000002", 154},
+		{event(replication.XID_EVENT, 500, 0), false, "bin.000002", 500},
+		// a real rotate event. its LogPos is in bin.000002
+		{rotate(547, 0, "bin.000003", 4), true, "bin.000003", 4},
+		{event(replication.FORMAT_DESCRIPTION_EVENT, 123, 0), false, "bin.000003", 123},
+	}
+
+	b := &BinlogReader{
+		logger:                  logrus.NewEntry(logrus.New()),
+		currentCoordinatesMutex: &sync.Mutex{},
+		currentCoordinates:      base.BinlogCoordinateTx{LogFile: "bin.000001", LogPos: 1000},
+	}
+	for i, step := range steps {
+		if got := b.updateCurrentCoordinates(step.ev); got != step.wantRotate {
+			t.Errorf("step %v: updateCurrentCoordinates() = %v, want %v", i, got, step.wantRotate)
+		}
+		if b.currentCoordinates.LogFile != step.wantFile || b.currentCoordinates.LogPos != step.wantPos {
+			t.Errorf("step %v: coordinates = %v:%v, want %v:%v", i,
+				b.currentCoordinates.LogFile, b.currentCoordinates.LogPos, step.wantFile, step.wantPos)
+		}
+	}
+}
+
+func Test_decodeBitValues(t *testing.T) {
+	tableMap := &replication.TableMapEvent{
+		ColumnType: []byte{gomysql.MYSQL_TYPE_LONG, gomysql.MYSQL_TYPE_BIT, gomysql.MYSQL_TYPE_BIT, gomysql.MYSQL_TYPE_BIT},
+		// BIT(1), BIT(12) and BIT(64): the bytes in the high byte and the remaining bits in the low one
+		ColumnMeta: []uint16{0, 1, 1<<8 | 4, 8 << 8},
+	}
+	row := []interface{}{int32(-1), int64(1), int64(0xFFF), int64(-1)}
+	decodeBitValues(tableMap, row)
+	want := []interface{}{int32(-1), uint64(1), uint64(0xFFF), uint64(0xFFFFFFFFFFFFFFFF)}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("decodeBitValues() = %v, want %v", row, want)
+	}
+
+	row = []interface{}{nil, nil, int64(0), int64(0x7FFFFFFFFFFFFFFF)}
+	decodeBitValues(tableMap, row)
+	want = []interface{}{nil, nil, uint64(0), uint64(0x7FFFFFFFFFFFFFFF)}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("decodeBitValues() = %v, want %v", row, want)
+	}
+}
+
+// encodeDecimal encodes v, with at most scale digits after the point, as MySQL writes a DECIMAL(precision, scale)
+// into the binlog: groups of 9 digits in 4 bytes, and the remaining leading integral and trailing fractional digits
+// in fewer bytes. The bytes of a negative value are inverted, and then the sign bit is flipped.
+func encodeDecimal(v string, precision int, scale int) []byte {
+	neg := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(v, "-")
+	integral, fraction := v, ""
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		integral, fraction = v[:i], v[i+1:]
+	}
+	intg := precision - scale
+	integral = strings.Repeat("0", intg-len(integral)) + integral
+	fraction += strings.Repeat("0", scale-len(fraction))
+
+	digitBytes := []int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+	var buf []byte
+	put := func(digits string) {
+		n, _ := strconv.ParseUint(digits, 10, 32)
+		for i := digitBytes[len(digits)] - 1; i >= 0; i-- {
+			buf = append(buf, byte(n>>(8*uint(i))))
+		}
+	}
+	put(integral[:intg%9])
+	for i := intg % 9; i < intg; i += 9 {
+		put(integral[i : i+9])
+	}
+	for i := 0; i+9 <= scale; i += 9 {
+		put(fraction[i : i+9])
+	}
+	put(fraction[scale-scale%9:])
+	if neg {
+		for i := range buf {
+			buf[i] ^= 0xFF
+		}
+	}
+	buf[0] ^= 0x80
+	return buf
+}
+
+// binlogEventBytes returns an event of the body, without a checksum.
+func binlogEventBytes(eventType replication.EventType, body []byte) []byte {
+	header := make([]byte, replication.EventHeaderSize)
+	header[4] = byte(eventType)
+	binary.LittleEndian.PutUint32(header[9:], uint32(len(header)+len(body)))
+	return append(header, body...)
+}
+
+// The DECIMAL values of a row are decoded from the packed format of the binlog as go-mysql does for the reader,
+// and must round-trip exactly.
+func Test_decodeDecimalValues(t *testing.T) {
+	types := [][2]int{{65, 30}, {65, 30}, {65, 30}, {65, 30}, {65, 30}, {18, 9}, {10, 0}}
+	rows := [][]string{
+		{"12345678901234567890123456789012345.123456789012345678901234567890",
+			"-99999999999999999999999999999999999.999999999999999999999999999999",
+			"0.000000000000000000000000000001", "-0.000000000000000000000000000001",
+			"0.000000000000000000000000000000", "-123456789.000000001", "-1234567890"},
+		{"1.500000000000000000000000000000", "-1.000000000000000000000000000000",
+			"99999999999999999999999999999999999.999999999999999999999999999999",
+			"100000000000000000000000000000000.000000000000000000000000000000",
+			"-0.100000000000000000000000000000", "0.000000000", "0"},
+	}
+
+	parser := replication.NewBinlogParser()
+	parser.SetUseDecimal(true)
+	// a server without binlog checksums
+	fde := []byte{4, 0}
+	fde = append(fde, []byte("5.5.62")...)
+	fde = append(fde, make([]byte, 50-len("5.5.62")+4)...)
+	fde = append(fde, byte(replication.EventHeaderSize))
+	fde = append(fde, bytes.Repeat([]byte{8}, 40)...)
+
+	tableMap := []byte{1, 0, 0, 0, 0, 0, 0, 0, 3, 'd', 'b', '1', 0, 3, 't', 'b', '1', 0, byte(len(types))}
+	var meta []byte
+	for range types {
+		tableMap = append(tableMap, gomysql.MYSQL_TYPE_NEWDECIMAL)
+	}
+	for _, tp := range types {
+		meta = append(meta, byte(tp[0]), byte(tp[1]))
+	}
+	tableMap = append(tableMap, byte(len(meta)))
+	tableMap = append(tableMap, meta...)
+	tableMap = append(tableMap, 0) // null bitmap
+
+	rowsEvent := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, byte(len(types)), 0xFF}
+	for _, row := range rows {
+		rowsEvent = append(rowsEvent, 0) // null bitmap
+		for i, v := range row {
+			rowsEvent = append(rowsEvent, encodeDecimal(v, types[i][0], types[i][1])...)
+		}
+	}
+
+	var ev *replication.BinlogEvent
+	for _, e := range [][]byte{
+		binlogEventBytes(replication.FORMAT_DESCRIPTION_EVENT, fde),
+		binlogEventBytes(replication.TABLE_MAP_EVENT, tableMap),
+		binlogEventBytes(replication.WRITE_ROWS_EVENTv2, rowsEvent),
+	} {
+		var err error
+		if ev, err = parser.Parse(e); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+	}
+	decoded := ev.Event.(*replication.RowsEvent)
+	if len(decoded.Rows) != len(rows) {
+		t.Fatalf("got %v rows, want %v", len(decoded.Rows), len(rows))
+	}
+	for i, row := range decoded.Rows {
+		decodeDecimalValues(decoded.Table, row)
+		want := make([]interface{}, len(rows[i]))
+		for j := range rows[i] {
+			want[j] = rows[i][j]
+		}
+		if !reflect.DeepEqual(row, want) {
+			t.Errorf("row %v = %q, want %q", i, row, want)
+		}
+	}
+}
+
+// encodeDatetime2 returns a DATETIME(dec) value in the binlog format.
+func encodeDatetime2(year, month, day, hour, minute, second, microsecond int64, dec int) []byte {
+	ymd := (year*13+month)<<5 | day
+	hms := hour<<12 | minute<<6 | second
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(ymd<<17|hms+replication.DATETIMEF_INT_OFS))
+	return append(buf[3:], encodeFrac(microsecond, dec)...)
+}
+
+func encodeFrac(microsecond int64, dec int) []byte {
+	switch dec {
+	case 1, 2:
+		return []byte{byte(int8(microsecond / 10000))}
+	case 3, 4:
+		return []byte{byte(uint16(int16(microsecond/100)) >> 8), byte(int16(microsecond / 100))}
+	case 5, 6:
+		return []byte{byte(microsecond >> 16), byte(microsecond >> 8), byte(microsecond)}
+	}
+	return nil
+}
+
+// encodeTime2 returns a TIME(dec) value in the binlog format, as MySQL packs it (my_time_packed_to_binary).
+func encodeTime2(neg bool, hour, minute, second, microsecond int64, dec int) []byte {
+	packed := (hour<<12|minute<<6|second)<<24 + microsecond
+	if neg {
+		packed = -packed
+	}
+	buf := make([]byte, 8)
+	if dec == 5 || dec == 6 {
+		binary.BigEndian.PutUint64(buf, uint64(packed+replication.TIMEF_OFS))
+		return buf[2:]
+	}
+	binary.BigEndian.PutUint64(buf, uint64(packed>>24+replication.TIMEF_INT_OFS))
+	// the fractional part keeps the sign, as the remainder in C
+	return append(buf[5:], encodeFrac(packed%(1<<24), dec)...)
+}
+
+// TIME, DATETIME and YEAR values are decoded from the binlog as go-mysql does for the reader, in the text of
+// MySQL, with the fractional digits of the precision of the column.
+func Test_decodeTemporalValues(t *testing.T) {
+	columns := []struct {
+		name  string
+		tp    byte
+		meta  []byte
+		value []byte
+		want  interface{}
+	}{
+		{"DATETIME(6)", gomysql.MYSQL_TYPE_DATETIME2, []byte{6},
+			encodeDatetime2(2019, 1, 2, 3, 4, 5, 123456, 6), "2019-01-02 03:04:05.123456"},
+		{"DATETIME(6) ending in zeros", gomysql.MYSQL_TYPE_DATETIME2, []byte{6},
+			encodeDatetime2(2019, 12, 31, 23, 59, 59, 500000, 6), "2019-12-31 23:59:59.500000"},
+		{"zero DATETIME(6)", gomysql.MYSQL_TYPE_DATETIME2, []byte{6},
+			encodeDatetime2(0, 0, 0, 0, 0, 0, 0, 6), "0000-00-00 00:00:00.000000"},
+		{"DATETIME(3)", gomysql.MYSQL_TYPE_DATETIME2, []byte{3},
+			encodeDatetime2(2000, 2, 29, 12, 0, 0, 7000, 3), "2000-02-29 12:00:00.007"},
+		{"TIME(3)", gomysql.MYSQL_TYPE_TIME2, []byte{3},
+			encodeTime2(false, 12, 34, 56, 789000, 3), "12:34:56.789"},
+		{"negative TIME(3)", gomysql.MYSQL_TYPE_TIME2, []byte{3},
+			encodeTime2(true, 1, 0, 0, 250000, 3), "-01:00:00.250"},
+		{"zero TIME(3)", gomysql.MYSQL_TYPE_TIME2, []byte{3},
+			encodeTime2(false, 0, 0, 0, 0, 3), "00:00:00.000"},
+		{"TIME(1)", gomysql.MYSQL_TYPE_TIME2, []byte{1},
+			encodeTime2(false, 0, 0, 0, 500000, 1), "00:00:00.5"},
+		{"negative TIME(2)", gomysql.MYSQL_TYPE_TIME2, []byte{2},
+			encodeTime2(true, 0, 0, 1, 100000, 2), "-00:00:01.10"},
+		{"TIME(6)", gomysql.MYSQL_TYPE_TIME2, []byte{6},
+			encodeTime2(false, 838, 59, 59, 0, 6), "838:59:59.000000"},
+		{"negative TIME(6)", gomysql.MYSQL_TYPE_TIME2, []byte{6},
+			encodeTime2(true, 12, 34, 56, 1, 6), "-12:34:56.000001"},
+		{"TIME", gomysql.MYSQL_TYPE_TIME2, []byte{0},
+			encodeTime2(true, 838, 59, 59, 0, 0), "-838:59:59"},
+		{"YEAR", gomysql.MYSQL_TYPE_YEAR, nil, []byte{2019 - 1900}, 2019},
+		{"YEAR 1901", gomysql.MYSQL_TYPE_YEAR, nil, []byte{1}, 1901},
+		{"YEAR 0000", gomysql.MYSQL_TYPE_YEAR, nil, []byte{0}, 0},
+	}
+
+	parser := replication.NewBinlogParser()
+	// a server without binlog checksums
+	fde := []byte{4, 0}
+	fde = append(fde, []byte("5.7.26")...)
+	fde = append(fde, make([]byte, 50-len("5.7.26")+4)...)
+	fde = append(fde, byte(replication.EventHeaderSize))
+	fde = append(fde, bytes.Repeat([]byte{8}, 40)...)
+
+	tableMap := []byte{1, 0, 0, 0, 0, 0, 0, 0, 3, 'd', 'b', '1', 0, 3, 't', 'b', '1', 0, byte(len(columns))}
+	var meta []byte
+	for _, c := range columns {
+		tableMap = append(tableMap, c.tp)
+		meta = append(meta, c.meta...)
+	}
+	tableMap = append(tableMap, byte(len(meta)))
+	tableMap = append(tableMap, meta...)
+	tableMap = append(tableMap, 0, 0) // null bitmap
+
+	rowsEvent := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, byte(len(columns)), 0xFF, 0xFF}
+	rowsEvent = append(rowsEvent, 0, 0) // null bitmap
+	for _, c := range columns {
+		rowsEvent = append(rowsEvent, c.value...)
+	}
+
+	var ev *replication.BinlogEvent
+	for _, e := range [][]byte{
+		binlogEventBytes(replication.FORMAT_DESCRIPTION_EVENT, fde),
+		binlogEventBytes(replication.TABLE_MAP_EVENT, tableMap),
+		binlogEventBytes(replication.WRITE_ROWS_EVENTv2, rowsEvent),
+	} {
+		var err error
+		if ev, err = parser.Parse(e); err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+	}
+	decoded := ev.Event.(*replication.RowsEvent)
+	if len(decoded.Rows) != 1 || len(decoded.Rows[0]) != len(columns) {
+		t.Fatalf("rows = %v", decoded.Rows)
+	}
+	for i, c := range columns {
+		if got := decoded.Rows[0][i]; got != c.want {
+			t.Errorf("%v = %#v, want %#v", c.name, got, c.want)
+		}
+	}
+}
+
+// txEventsBuilder builds the binlog events of transactions on db1.tb1, with a row of an int per rows event.
+type txEventsBuilder struct {
+	events []*replication.BinlogEvent
+}
+
+func (e *txEventsBuilder) gtid(gno int64) *txEventsBuilder {
+	e.events = append(e.events, &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.GTID_EVENT},
+		Event:  &replication.GTIDEvent{SID: []byte("0123456789abcdef"), GNO: gno},
+	})
+	return e
+}
+
+func (e *txEventsBuilder) query(query string) *txEventsBuilder {
+	e.events = append(e.events, &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.QUERY_EVENT},
+		Event:  &replication.QueryEvent{Schema: []byte("db1"), Query: []byte(query)},
+	})
+	return e
+}
+
+// beginBy is the BEGIN of a transaction by the thread of threadID on the server of serverID.
+func (e *txEventsBuilder) beginBy(serverID uint32, threadID uint32) *txEventsBuilder {
+	e.events = append(e.events, &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, ServerID: serverID},
+		Event:  &replication.QueryEvent{SlaveProxyID: threadID, Schema: []byte("db1"), Query: []byte("BEGIN")},
+	})
+	return e
+}
+
+func (e *txEventsBuilder) rows(values ...int64) *txEventsBuilder {
+	var rows [][]interface{}
+	for _, v := range values {
+		rows = append(rows, []interface{}{v})
+	}
+	e.events = append(e.events, &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.WRITE_ROWS_EVENTv2},
+		Event: &replication.RowsEvent{
+			Table:       &replication.TableMapEvent{Schema: []byte("db1"), Table: []byte("tb1")},
+			ColumnCount: 1,
+			Rows:        rows,
+		},
+		RawData: make([]byte, 16),
+	})
+	return e
+}
+
+func (e *txEventsBuilder) xid() *txEventsBuilder {
+	e.events = append(e.events, &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.XID_EVENT},
+		Event:  &replication.XIDEvent{},
+	})
+	return e
+}
+
+func (e *txEventsBuilder) xaPrepare(onePhase bool) *txEventsBuilder {
+	data := make([]byte, 9)
+	if onePhase {
+		data[0] = 1
+	}
+	e.events = append(e.events, &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: xaPrepareLogEvent},
+		Event:  &replication.GenericEvent{Data: data},
+	})
+	return e
+}
+
+// readTxEvents returns the entries sent by a reader for the events, each as its gno and a description
+// of its events.
+func readTxEvents(t *testing.T, cfg *config.MySQLDriverConfig, events []*replication.BinlogEvent) (
+	entries []*BinlogEntry, got []string) {
+
+	cfg.ConnectionConfig = &mysql.ConnectionConfig{}
+	b, err := NewMySQLReader(&common.ExecContext{}, cfg, logrus.NewEntry(logrus.New()), nil, sqle.NewContext(nil))
+	if err != nil {
+		t.Fatalf("NewMySQLReader() error = %v", err)
+	}
+	return readTxEventsBy(t, b, events)
+}
+
+// readTxEventsBy is readTxEvents with a reader set up by the test.
+func readTxEventsBy(t *testing.T, b *BinlogReader, events []*replication.BinlogEvent) (
+	entries []*BinlogEntry, got []string) {
+
+	entriesChannel := make(chan *BinlogEntry, 20)
+	for i, ev := range events {
+		ev.Header.LogPos = uint32(100 * (i + 1))
+		b.currentCoordinates.LogPos = int64(ev.Header.LogPos)
+		if err := b.handleEvent(ev, entriesChannel); err != nil {
+			t.Fatalf("handleEvent() error = %v", err)
+		}
+	}
+	close(entriesChannel)
+
+	for entry := range entriesChannel {
+		entries = append(entries, entry)
+		var descs []string
+		for _, event := range entry.Events {
+			switch {
+			case event.Savepoint:
+				descs = append(descs, event.Query)
+			case event.DML == InsertDML:
+				descs = append(descs, fmt.Sprintf("insert %v", *event.NewColumnValues.AbstractValues[0]))
+			default:
+				descs = append(descs, fmt.Sprintf("%v %v", event.DML, event.Query))
+			}
+		}
+		got = append(got, fmt.Sprintf("%v: %v", entry.Coordinates.GNO, strings.Join(descs, ", ")))
+	}
+	return entries, got
+}
+
+// The rows rolled back to a savepoint stay in the binlog of a transaction changing a non-transactional table.
+func TestBinlogReader_savepoint(t *testing.T) {
+	events := new(txEventsBuilder).
+		gtid(1).query("BEGIN").rows(1).query("SAVEPOINT `sp1`").rows(2).query("ROLLBACK TO `sp1`").
+		rows(3).query("RELEASE SAVEPOINT `sp1`").xid().
+		// only non-transactional tables, ended by a COMMIT query
+		gtid(2).query("BEGIN").rows(4).query("SAVEPOINT sp2").query("COMMIT").
+		events
+
+	entries, got := readTxEvents(t, &config.MySQLDriverConfig{}, events)
+	want := []string{
+		"1: insert 1, SAVEPOINT `sp1`, insert 2, ROLLBACK TO `sp1`, insert 3, RELEASE SAVEPOINT `sp1`",
+		"2: insert 4, SAVEPOINT sp2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got entries %q, want %q", got, want)
+	}
+	for _, entry := range entries {
+		if entry.HasDDL() {
+			t.Errorf("entry %v: HasDDL() = true, want false", entry.Coordinates.GNO)
+		}
+		if event := entry.Events[1]; event.DML != NotDML || event.CurrentSchema != "" {
+			t.Errorf("entry %v: savepoint event %+v, want a NotDML one without a schema",
+				entry.Coordinates.GNO, event)
+		}
+	}
+	if want := int64(1400); entries[1].Coordinates.LogPos != want {
+		t.Errorf("the COMMIT query is at %v, want %v", entries[1].Coordinates.LogPos, want)
+	}
+}
+
+// A prepared XA transaction is sent on its XA COMMIT, in the entry of the XA COMMIT.
+func TestBinlogReader_xa(t *testing.T) {
+	events := new(txEventsBuilder).
+		gtid(1).query("XA START X'7831',X'',1").rows(1, 2).rows(3).query("XA END X'7831',X'',1").xaPrepare(false).
+		gtid(2).query("BEGIN").rows(4).xid().
+		gtid(3).query("XA COMMIT X'7831',X'',1").
+		gtid(4).query("XA START X'7832',X'',1").rows(5).query("XA END X'7832',X'',1").xaPrepare(false).
+		gtid(5).query("XA ROLLBACK X'7832',X'',1").
+		gtid(6).query("XA START X'7833',X'',1").rows(6).query("XA END X'7833',X'',1").xaPrepare(true).
+		// prepared before the start of the job
+		gtid(7).query("XA COMMIT X'7834',X'',1").
+		events
+
+	// A big transaction of 2 rows events is split, unless it is an XA one.
+	entries, got := readTxEvents(t, &config.MySQLDriverConfig{BigTxSplittingSize: 2}, events)
+	want := []string{
+		"2: insert 4",
+		"3: insert 1, insert 2, insert 3",
+		"5: ",
+		"6: insert 6",
+		"7: ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got entries %q, want %q", got, want)
+	}
+	for i, wantPrepare := range []int64{0, 1, 4, 0, 0} {
+		entry := entries[i]
+		if entry.Partial {
+			t.Errorf("entry %v is partial", entry.Coordinates.GNO)
+		}
+		switch {
+		case wantPrepare == 0 && entry.XAPrepare != nil:
+			t.Errorf("entry %v: XAPrepare = %v, want none", entry.Coordinates.GNO, entry.XAPrepare.GNO)
+		case wantPrepare != 0 && (entry.XAPrepare == nil || entry.XAPrepare.GNO != wantPrepare):
+			t.Errorf("entry %v: XAPrepare = %+v, want gno %v", entry.Coordinates.GNO, entry.XAPrepare, wantPrepare)
+		}
+	}
+	if entries[1].XAPrepare.LogPos != 600 {
+		t.Errorf("XAPrepare at %v, want the XA_PREPARE_LOG_EVENT at 600", entries[1].XAPrepare.LogPos)
+	}
+}
+
+// The transactions of the threads of IgnoreSourceUsers on the source are sent without their rows.
+func TestBinlogReader_ignoreSourceUsers(t *testing.T) {
+	events := new(txEventsBuilder).
+		gtid(1).beginBy(1, 10).rows(1).xid().
+		gtid(2).beginBy(1, 11).rows(2).rows(3).xid().
+		// not connected any more
+		gtid(3).beginBy(1, 12).rows(4).xid().
+		// replicated from another server, whose thread 10 is not of the source
+		gtid(4).beginBy(2, 10).rows(5).xid().
+		gtid(5).beginBy(1, 11).rows(6).xid().
+		events
+
+	b, err := NewMySQLReader(&common.ExecContext{}, &config.MySQLDriverConfig{ConnectionConfig: &mysql.ConnectionConfig{}},
+		logrus.NewEntry(logrus.New()), nil, sqle.NewContext(nil))
+	if err != nil {
+		t.Fatalf("NewMySQLReader() error = %v", err)
+	}
+	var lookups []uint32
+	b.sourceUsers = &sourceUserFilter{
+		logger:   b.logger,
+		serverID: 1,
+		users:    map[string]bool{"archiver": true},
+		threads:  make(map[uint32]string),
+		lookup: func(threadID uint32) (string, bool, error) {
+			lookups = append(lookups, threadID)
+			user, found := map[uint32]string{10: "app", 11: "archiver"}[threadID]
+			return user, found, nil
+		},
+	}
+
+	_, got := readTxEventsBy(t, b, events)
+	want := []string{"1: insert 1", "2: ", "3: insert 4", "4: insert 5", "5: "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got entries %q, want %q", got, want)
+	}
+	if want := []uint32{10, 11, 12}; !reflect.DeepEqual(lookups, want) {
+		t.Errorf("looked up threads %v, want %v once each", lookups, want)
+	}
+}
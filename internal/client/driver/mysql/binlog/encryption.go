@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/config/mysql"
+)
+
+const encryptionKeyEnvPrefix = "env:"
+
+// ColumnCipher encrypts and decrypts the values of the EncryptColumns (see config.EncryptedColumnIndexes) with
+// AES-GCM. Each value is sealed with a random nonce, which is prepended to it.
+type ColumnCipher struct {
+	aead cipher.AEAD
+}
+
+// ParseEncryptionKey returns the key of MySQLDriverConfig.EncryptionKey. A key in the environment is only read
+// if readEnv, and checked otherwise.
+func ParseEncryptionKey(encryptionKey string, readEnv bool) ([]byte, error) {
+	s := encryptionKey
+	if strings.HasPrefix(s, encryptionKeyEnvPrefix) {
+		name := strings.TrimPrefix(s, encryptionKeyEnvPrefix)
+		if name == "" {
+			return nil, fmt.Errorf("bad job argument: EncryptionKey=%v. no environment variable", encryptionKey)
+		}
+		if !readEnv {
+			return nil, nil
+		}
+		var ok bool
+		if s, ok = os.LookupEnv(name); !ok {
+			return nil, fmt.Errorf("bad job argument: EncryptionKey=%v. environment variable %v is not set",
+				encryptionKey, name)
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("bad job argument: EncryptionKey. should be base64: %v", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("bad job argument: EncryptionKey is of %v bytes. should be 16, 24 or 32", len(key))
+	}
+	return key, nil
+}
+
+// NewColumnCipher returns the cipher of MySQLDriverConfig.EncryptionKey, or nil if it is not set.
+func NewColumnCipher(encryptionKey string) (*ColumnCipher, error) {
+	if encryptionKey == "" {
+		return nil, nil
+	}
+	key, err := ParseEncryptionKey(encryptionKey, true)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &ColumnCipher{aead: aead}, nil
+}
+
+func (c *ColumnCipher) seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *ColumnCipher) open(ciphertext []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, fmt.Errorf("decrypting a column: the value is too short")
+	}
+	plaintext, err := c.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting a column: %v. is EncryptionKey the same as on the source?", err)
+	}
+	return plaintext, nil
+}
+
+// encryptedValue carries a binlog value of any type through gob.
+type encryptedValue struct {
+	V interface{}
+}
+
+func eventRows(event *DataEvent) (rows [][]*interface{}) {
+	for _, values := range []*mysql.ColumnValues{event.WhereColumnValues, event.NewColumnValues} {
+		if values != nil {
+			rows = append(rows, values.AbstractValues)
+		}
+	}
+	return rows
+}
+
+// EncryptEvent encrypts the EncryptedColumns of the row values of event in place.
+func (c *ColumnCipher) EncryptEvent(event *DataEvent) error {
+	for _, values := range eventRows(event) {
+		for _, idx := range event.EncryptedColumns {
+			if idx >= len(values) || values[idx] == nil {
+				continue
+			}
+			buf := new(bytes.Buffer)
+			if err := gob.NewEncoder(buf).Encode(&encryptedValue{V: *values[idx]}); err != nil {
+				return err
+			}
+			bs, err := c.seal(buf.Bytes())
+			if err != nil {
+				return err
+			}
+			var v interface{} = bs
+			values[idx] = &v
+		}
+	}
+	return nil
+}
+
+// DecryptEvent decrypts the EncryptedColumns of the row values of event in place. It is done once: the
+// EncryptedColumns are cleared.
+func (c *ColumnCipher) DecryptEvent(event *DataEvent) error {
+	if len(event.EncryptedColumns) == 0 {
+		return nil
+	}
+	if c == nil {
+		return fmt.Errorf("%v.%v has encrypted columns, and EncryptionKey is not set on the destination",
+			event.DatabaseName, event.TableName)
+	}
+	for _, values := range eventRows(event) {
+		for _, idx := range event.EncryptedColumns {
+			if idx >= len(values) || values[idx] == nil {
+				continue
+			}
+			bs, ok := (*values[idx]).([]byte)
+			if !ok {
+				return fmt.Errorf("decrypting a column of %v.%v: not encrypted", event.DatabaseName, event.TableName)
+			}
+			plaintext, err := c.open(bs)
+			if err != nil {
+				return err
+			}
+			var ev encryptedValue
+			if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&ev); err != nil {
+				return err
+			}
+			values[idx] = &ev.V
+		}
+	}
+	event.EncryptedColumns = nil
+	return nil
+}
+
+// EncryptText encrypts a value of the full copy, read as text. nil (NULL) is encrypted too.
+func (c *ColumnCipher) EncryptText(value *[]byte) (*[]byte, error) {
+	plaintext := []byte{0}
+	if value != nil {
+		plaintext = append([]byte{1}, *value...)
+	}
+	bs, err := c.seal(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &bs, nil
+}
+
+// DecryptText decrypts a value encrypted by EncryptText.
+func (c *ColumnCipher) DecryptText(value *[]byte) (*[]byte, error) {
+	if value == nil {
+		return nil, fmt.Errorf("decrypting a column: not encrypted")
+	}
+	plaintext, err := c.open(*value)
+	if err != nil {
+		return nil, err
+	}
+	if len(plaintext) == 0 || plaintext[0] == 0 {
+		return nil, nil
+	}
+	bs := plaintext[1:]
+	return &bs, nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"sync"
+
+	gomysql "github.com/siddontang/go-mysql/mysql"
+)
+
+// SemiSyncTracker finds the binlog position which the reader ACKs to the source with SemiSync: the end of the
+// events read whose transactions have been applied by all the destinations, i.e. are in the checkpoints
+// committed there, or were not sent to them.
+//
+// The reader counts the entries it forwards to the extractor, and records the position after each event with
+// the number forwarded by then. The extractor records how many entries it has sent for those forwarded, which
+// differ on a resync. The destinations ack the number of the entries sent which they have applied, see IncrAck.
+// A tracker outlives the readers of a task, e.g. on a failover of the source.
+type SemiSyncTracker struct {
+	lock  sync.Mutex
+	nDest int
+	// the positions read and not ACKed yet, ascending
+	reads []semiSyncRead
+	// the entries sent and not applied by all the destinations yet, ascending
+	sends []semiSyncSent
+	// the number of entries forwarded by the reader
+	forwarded int64
+	// the numbers of entries forwarded and sent, as recorded by the extractor
+	nForwarded int64
+	nSent      int64
+	// the number of entries applied, by destination
+	applied map[string]int64
+	// the number of entries forwarded of which all the entries sent are applied
+	appliedForwarded int64
+	// called with each new position to ACK
+	ack func(gomysql.Position)
+}
+
+type semiSyncRead struct {
+	forwarded int64
+	pos       gomysql.Position
+}
+
+type semiSyncSent struct {
+	forwarded int64
+	sent      int64
+}
+
+func NewSemiSyncTracker(nDest int) *SemiSyncTracker {
+	if nDest < 1 {
+		nDest = 1
+	}
+	return &SemiSyncTracker{
+		nDest:   nDest,
+		applied: make(map[string]int64),
+	}
+}
+
+// setACK sets the function called with each new position to ACK, i.e. the BinlogSyncer.SetSemiSyncACKPos of
+// a new reader. The positions read by the previous reader are dropped.
+func (t *SemiSyncTracker) setACK(ack func(gomysql.Position)) {
+	t.lock.Lock()
+	t.ack = ack
+	t.reads = nil
+	t.lock.Unlock()
+}
+
+// forward counts an entry forwarded by the reader.
+func (t *SemiSyncTracker) forward() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	t.forwarded++
+	t.lock.Unlock()
+}
+
+// read records the position after an event read.
+func (t *SemiSyncTracker) read(pos gomysql.Position) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if n := len(t.reads); n > 0 && t.reads[n-1].forwarded == t.forwarded {
+		t.reads[n-1].pos = pos
+	} else {
+		t.reads = append(t.reads, semiSyncRead{forwarded: t.forwarded, pos: pos})
+	}
+	t.advance()
+}
+
+// Sent records that the extractor has sent sent entries for forwarded entries of the reader, since the last call.
+func (t *SemiSyncTracker) Sent(forwarded int, sent int) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.nForwarded += int64(forwarded)
+	t.nSent += int64(sent)
+	t.sends = append(t.sends, semiSyncSent{forwarded: t.nForwarded, sent: t.nSent})
+	t.advance()
+}
+
+// Applied records that the destination dest has applied applied entries of those sent. A lower number than
+// the last one, i.e. an ack resent, is ignored.
+func (t *SemiSyncTracker) Applied(dest string, applied int64) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if applied <= t.applied[dest] {
+		return
+	}
+	t.applied[dest] = applied
+	t.advance()
+}
+
+// advance ACKs the last position read whose entries forwarded are all applied. Called with lock held.
+func (t *SemiSyncTracker) advance() {
+	if len(t.applied) >= t.nDest {
+		var minApplied int64
+		first := true
+		for _, n := range t.applied {
+			if first || n < minApplied {
+				minApplied = n
+				first = false
+			}
+		}
+		i := 0
+		for ; i < len(t.sends) && t.sends[i].sent <= minApplied; i++ {
+			t.appliedForwarded = t.sends[i].forwarded
+		}
+		t.sends = t.sends[i:]
+	}
+
+	i := 0
+	var pos gomysql.Position
+	for ; i < len(t.reads) && t.reads[i].forwarded <= t.appliedForwarded; i++ {
+		pos = t.reads[i].pos
+	}
+	if i == 0 {
+		return
+	}
+	t.reads = t.reads[i:]
+	if t.ack != nil {
+		t.ack(pos)
+	}
+}
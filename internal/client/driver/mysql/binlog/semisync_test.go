@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"testing"
+
+	gomysql "github.com/siddontang/go-mysql/mysql"
+)
+
+func TestSemiSyncTracker(t *testing.T) {
+	var acked []uint32
+	tracker := NewSemiSyncTracker(2)
+	tracker.setACK(func(pos gomysql.Position) {
+		acked = append(acked, pos.Pos)
+	})
+	pos := func(p uint32) gomysql.Position {
+		return gomysql.Position{Name: "bin.000001", Pos: p}
+	}
+	wantACKed := func(want ...uint32) {
+		t.Helper()
+		if len(acked) != len(want) {
+			t.Fatalf("ACKed %v, want %v", acked, want)
+		}
+		for i := range want {
+			if acked[i] != want[i] {
+				t.Fatalf("ACKed %v, want %v", acked, want)
+			}
+		}
+	}
+
+	// a transaction not forwarded is ACKed at once
+	tracker.read(pos(100))
+	wantACKed(100)
+
+	// two transactions forwarded, and one not forwarded after them
+	tracker.forward()
+	tracker.read(pos(200))
+	tracker.forward()
+	tracker.read(pos(300))
+	tracker.read(pos(400))
+	wantACKed(100)
+	// sent with an extra entry, e.g. of a resync
+	tracker.Sent(2, 3)
+	tracker.Applied("Dest", 3)
+	wantACKed(100)
+	// by one of the two destinations
+	tracker.Applied("Dest_kafka", 2)
+	wantACKed(100)
+	// an ack resent is ignored
+	tracker.Applied("Dest_kafka", 1)
+	wantACKed(100)
+	tracker.Applied("Dest_kafka", 3)
+	wantACKed(100, 400)
+
+	// a new reader, e.g. on a failover, does not ACK the positions of the last one
+	tracker.forward()
+	tracker.read(pos(500))
+	tracker.setACK(func(pos gomysql.Position) {
+		acked = append(acked, pos.Pos)
+	})
+	tracker.Sent(1, 1)
+	tracker.Applied("Dest", 4)
+	tracker.Applied("Dest_kafka", 4)
+	wantACKed(100, 400)
+	tracker.read(pos(10))
+	wantACKed(100, 400, 10)
+
+	// nil-safe
+	var nilTracker *SemiSyncTracker
+	nilTracker.forward()
+	nilTracker.read(pos(100))
+	nilTracker.Sent(1, 1)
+	nilTracker.Applied("Dest", 1)
+}
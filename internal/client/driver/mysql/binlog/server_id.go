@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// A random ServerId is taken from the upper half of the range, as servers are usually numbered from 1.
+const (
+	serverIdRandomMin = 1 << 31
+	serverIdRandomMax = 1<<32 - 1
+)
+
+func randomServerId() uint32 {
+	return uint32(serverIdRandomMin + rand.Int63n(serverIdRandomMax-serverIdRandomMin+1))
+}
+
+// The source cannot be asked whether a server id is in use, but the binlog readers of this agent
+// are known. A server id is mapped to the subjects (job IDs) of the readers using it.
+var (
+	serverIdsLock sync.Mutex
+	serverIds     = make(map[uint32][]string)
+)
+
+// registerServerId records that the job of subject uses id. It returns another job using id, if any.
+func registerServerId(id uint32, subject string) (usedBy string) {
+	serverIdsLock.Lock()
+	defer serverIdsLock.Unlock()
+	for _, s := range serverIds[id] {
+		if s != subject {
+			usedBy = s
+		}
+	}
+	serverIds[id] = append(serverIds[id], subject)
+	return usedBy
+}
+
+func unregisterServerId(id uint32, subject string) {
+	serverIdsLock.Lock()
+	defer serverIdsLock.Unlock()
+	subjects := serverIds[id]
+	for i, s := range subjects {
+		if s == subject {
+			subjects = append(subjects[:i], subjects[i+1:]...)
+			break
+		}
+	}
+	if len(subjects) == 0 {
+		delete(serverIds, id)
+	} else {
+		serverIds[id] = subjects
+	}
+}
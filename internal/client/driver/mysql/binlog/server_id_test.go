@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	sqle "github.com/actiontech/dtle/internal/client/driver/mysql/sqle/inspector"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func Test_randomServerId(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		if id := randomServerId(); id < serverIdRandomMin {
+			t.Fatalf("randomServerId() = %v, want at least %v", id, serverIdRandomMin)
+		}
+	}
+}
+
+func Test_registerServerId(t *testing.T) {
+	if usedBy := registerServerId(1001, "job1"); usedBy != "" {
+		t.Errorf("registerServerId() = %v, want none", usedBy)
+	}
+	if usedBy := registerServerId(1002, "job2"); usedBy != "" {
+		t.Errorf("registerServerId() = %v, want none", usedBy)
+	}
+	// a restarted job has not unregistered yet
+	if usedBy := registerServerId(1001, "job1"); usedBy != "" {
+		t.Errorf("registerServerId() = %v for the same job, want none", usedBy)
+	}
+	unregisterServerId(1001, "job1")
+	if usedBy := registerServerId(1001, "job3"); usedBy != "job1" {
+		t.Errorf("registerServerId() = %v, want job1", usedBy)
+	}
+	unregisterServerId(1001, "job1")
+	unregisterServerId(1001, "job3")
+	unregisterServerId(1002, "job2")
+	for _, id := range []uint32{1001, 1002} {
+		if subjects := registeredServerId(id); subjects != nil {
+			t.Errorf("ServerId %v is used by %v after unregistering, want none", id, subjects)
+		}
+	}
+}
+
+// registeredServerId returns the subjects using id.
+func registeredServerId(id uint32) []string {
+	serverIdsLock.Lock()
+	defer serverIdsLock.Unlock()
+	return serverIds[id]
+}
+
+// The server id is registered only once the binlog connection succeeds.
+func TestNewMySQLReader_serverIdNotConnected(t *testing.T) {
+	cfg := &config.MySQLDriverConfig{
+		ServerId: 1003,
+		ConnectionConfig: &mysql.ConnectionConfig{
+			Host:     "127.0.0.1",
+			Port:     1,
+			User:     "root",
+			Password: "rootroot",
+		},
+	}
+	b, err := NewMySQLReader(&common.ExecContext{Subject: "job1"}, cfg, logrus.NewEntry(logrus.New()),
+		nil, sqle.NewContext(nil))
+	if err == nil {
+		defer b.Close()
+		if subjects := registeredServerId(1003); subjects != nil {
+			t.Errorf("ServerId 1003 is used by %v before connecting, want none", subjects)
+		}
+		err = b.ConnectBinlogStreamer(base.BinlogCoordinatesX{LogFile: "bin.000001", LogPos: 4})
+		if err == nil {
+			t.Fatalf("ConnectBinlogStreamer() expects an error")
+		}
+	}
+	if subjects := registeredServerId(1003); subjects != nil {
+		t.Errorf("ServerId 1003 is used by %v after failing to connect, want none", subjects)
+	}
+}
+
+func TestNewMySQLReader_serverId(t *testing.T) {
+	skipWithoutMySQL(t)
+	newReader := func(subject string, serverId uint32) *BinlogReader {
+		cfg := &config.MySQLDriverConfig{
+			ServerId: serverId,
+			ConnectionConfig: &mysql.ConnectionConfig{
+				Host:     "192.168.99.100",
+				Port:     13307,
+				User:     "root",
+				Password: "rootroot",
+			},
+		}
+		b, err := NewMySQLReader(&common.ExecContext{Subject: subject}, cfg, logrus.NewEntry(logrus.New()),
+			nil, sqle.NewContext(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	b1 := newReader("job1", 0)
+	defer b1.Close()
+	if b1.serverId < serverIdRandomMin {
+		t.Errorf("serverId = %v, want a random one", b1.serverId)
+	}
+	b2 := newReader("job2", 1234)
+	defer b2.Close()
+	if b2.serverId != 1234 {
+		t.Errorf("serverId = %v, want 1234", b2.serverId)
+	}
+
+	// Both read from the source at the same time.
+	var file string
+	var pos int64
+	var ignored interface{}
+	err := b1.db.QueryRow("show master status").Scan(&file, &pos, &ignored, &ignored, &ignored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*BinlogReader{b1, b2} {
+		if err := b.ConnectBinlogStreamer(base.BinlogCoordinatesX{LogFile: file, LogPos: pos}); err != nil {
+			t.Fatalf("ConnectBinlogStreamer() error = %v with ServerId %v", err, b.serverId)
+		}
+	}
+	if _, err := b1.db.Exec("flush logs"); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*BinlogReader{b1, b2} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := b.binlogStreamer.GetEvent(ctx)
+		cancel()
+		if err != nil {
+			t.Errorf("GetEvent() error = %v with ServerId %v", err, b.serverId)
+		}
+	}
+}
+
+// skipWithoutMySQL skips a test which needs the MySQL server at 192.168.99.100:13307 (root/rootroot),
+// unless DTLE_TEST_MYSQL is set.
+func skipWithoutMySQL(t testing.TB) {
+	if os.Getenv("DTLE_TEST_MYSQL") == "" {
+		t.Skip("DTLE_TEST_MYSQL is not set")
+	}
+}
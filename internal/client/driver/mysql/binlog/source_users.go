@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	gosql "database/sql"
+
+	"github.com/sirupsen/logrus"
+)
+
+// the number of threads whose users are kept by a sourceUserFilter. It forgets them all when reached.
+const sourceUserCacheSize = 4096
+
+// sourceUserFilter tells whether a transaction is of one of IgnoreSourceUsers, to be dropped.
+//
+// The binlog does not record the user of a transaction. Nor do the ROWS_QUERY events written with
+// binlog_rows_query_log_events, which only have the text of the statements. The BEGIN of a transaction
+// has the id of the thread which executed it, whose user is looked up in the processlist of the source
+// when the BEGIN is read. It is best-effort:
+//   - a thread which has disconnected is not found, e.g. a short connection which commits and quits before
+//     its transaction is read from the binlog. Its transactions are replicated.
+//   - the threads of the other users are only seen with the PROCESS privilege.
+//   - a transaction replicated to the source from another server, i.e. of another server_id, is not looked up,
+//     as its thread is not of the source.
+//   - a DDL, and a transaction without a BEGIN, e.g. of MariaDB, is not looked up.
+type sourceUserFilter struct {
+	logger *logrus.Entry
+	// server_id of the source
+	serverID uint32
+	users    map[string]bool
+	// looks up the user of a thread of the source. found is false if the thread is not connected.
+	lookup func(threadID uint32) (user string, found bool, err error)
+
+	// users of the threads looked up, by thread id. "" if not found.
+	threads map[uint32]string
+}
+
+func newSourceUserFilter(db *gosql.DB, users []string, logger *logrus.Entry) (*sourceUserFilter, error) {
+	f := &sourceUserFilter{
+		logger:  logger,
+		users:   make(map[string]bool),
+		threads: make(map[uint32]string),
+		lookup: func(threadID uint32) (string, bool, error) {
+			var user string
+			err := db.QueryRow("select user from information_schema.processlist where id = ?", threadID).Scan(&user)
+			if err == gosql.ErrNoRows {
+				return "", false, nil
+			}
+			return user, err == nil, err
+		},
+	}
+	for _, user := range users {
+		f.users[user] = true
+	}
+	if err := db.QueryRow("select @@global.server_id").Scan(&f.serverID); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ignore tells whether the transaction begun by the thread of threadID on the server of serverID is dropped.
+func (f *sourceUserFilter) ignore(serverID uint32, threadID uint32) bool {
+	if serverID != f.serverID {
+		return false
+	}
+	user, ok := f.threads[threadID]
+	if !ok {
+		var found bool
+		var err error
+		user, found, err = f.lookup(threadID)
+		if err != nil {
+			f.logger.Warnf("mysql.reader: IgnoreSourceUsers: failed to look up the user of thread %v: %v",
+				threadID, err)
+			return false
+		}
+		if !found {
+			f.logger.Debugf("mysql.reader: IgnoreSourceUsers: thread %v is not connected. replicating its transaction",
+				threadID)
+		}
+		if len(f.threads) >= sourceUserCacheSize {
+			f.threads = make(map[uint32]string)
+		}
+		f.threads[threadID] = user
+	}
+	return f.users[user]
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	gomysql "github.com/siddontang/go-mysql/mysql"
+	"github.com/siddontang/go-mysql/replication"
+)
+
+const (
+	// the size of the table id in a TABLE_MAP event of MySQL 5.6 and later
+	tableMapTableIDSize = 6
+	// the type of the optional metadata of a TABLE_MAP event with the signedness of the numeric columns.
+	// See Table_map_log_event::Optional_metadata_field_type of MySQL 8.0.
+	tableMapOptionalSignedness = 1
+)
+
+// isNumericType tells whether a column of the type has a bit in the signedness metadata of a TABLE_MAP event.
+func isNumericType(columnType byte) bool {
+	switch columnType {
+	case gomysql.MYSQL_TYPE_TINY, gomysql.MYSQL_TYPE_SHORT, gomysql.MYSQL_TYPE_INT24, gomysql.MYSQL_TYPE_LONG,
+		gomysql.MYSQL_TYPE_LONGLONG, gomysql.MYSQL_TYPE_NEWDECIMAL, gomysql.MYSQL_TYPE_FLOAT, gomysql.MYSQL_TYPE_DOUBLE:
+		return true
+	default:
+		return false
+	}
+}
+
+// tableMapUnsigned returns which columns of a TABLE_MAP event are unsigned, by the signedness metadata a source
+// of MySQL 8.0.1 or later writes (binlog_row_metadata MINIMAL or FULL). go-mysql does not decode the optional
+// metadata, so it is read from the raw event. checksumAlgorithm is that of the last FORMAT_DESCRIPTION_EVENT,
+// which tells whether the raw event ends with a checksum. It returns nil if the event has no signedness metadata.
+func tableMapUnsigned(ev *replication.BinlogEvent, checksumAlgorithm byte) []bool {
+	tableMap, ok := ev.Event.(*replication.TableMapEvent)
+	if !ok || len(ev.RawData) < replication.EventHeaderSize {
+		return nil
+	}
+	data := ev.RawData[replication.EventHeaderSize:]
+	if checksumAlgorithm == replication.BINLOG_CHECKSUM_ALG_CRC32 {
+		if len(data) < replication.BinlogChecksumLength {
+			return nil
+		}
+		data = data[:len(data)-replication.BinlogChecksumLength]
+	}
+
+	// skip the fields decoded by go-mysql
+	pos := tableMapTableIDSize + 2
+	if pos >= len(data) || int(data[pos]) != len(tableMap.Schema) {
+		return nil
+	}
+	pos += 1 + len(tableMap.Schema) + 1
+	if pos >= len(data) || int(data[pos]) != len(tableMap.Table) {
+		return nil
+	}
+	pos += 1 + len(tableMap.Table) + 1
+	if pos >= len(data) {
+		return nil
+	}
+	_, _, n := gomysql.LengthEncodedInt(data[pos:])
+	pos += n + int(tableMap.ColumnCount)
+	if pos >= len(data) {
+		return nil
+	}
+	metaLength, _, n := gomysql.LengthEncodedInt(data[pos:])
+	pos += n + int(metaLength) + int(tableMap.ColumnCount+7)/8
+
+	// the optional metadata: fields of a type, a length and a value
+	for pos < len(data) {
+		fieldType := data[pos]
+		pos++
+		if pos >= len(data) {
+			return nil
+		}
+		length, _, n := gomysql.LengthEncodedInt(data[pos:])
+		pos += n
+		if pos+int(length) > len(data) {
+			return nil
+		}
+		value := data[pos : pos+int(length)]
+		pos += int(length)
+		if fieldType != tableMapOptionalSignedness {
+			continue
+		}
+
+		// a bit for each numeric column in order, the most significant bit first
+		unsigned := make([]bool, len(tableMap.ColumnType))
+		numeric := 0
+		for i, columnType := range tableMap.ColumnType {
+			if !isNumericType(columnType) {
+				continue
+			}
+			if numeric/8 < len(value) && value[numeric/8]&(0x80>>uint(numeric%8)) != 0 {
+				unsigned[i] = true
+			}
+			numeric++
+		}
+		return unsigned
+	}
+	return nil
+}
+
+// decodeUnsignedValues turns the values of the unsigned integer columns, which go-mysql decodes as signed,
+// into unsigned ones. Otherwise a BIGINT UNSIGNED value above 2^63-1 is negative.
+func decodeUnsignedValues(tableMap *replication.TableMapEvent, unsigned []bool, row []interface{}) {
+	for i, isUnsigned := range unsigned {
+		if !isUnsigned || i >= len(row) || i >= len(tableMap.ColumnType) {
+			continue
+		}
+		switch v := row[i].(type) {
+		case int8:
+			row[i] = uint8(v)
+		case int16:
+			row[i] = uint16(v)
+		case int32:
+			if tableMap.ColumnType[i] == gomysql.MYSQL_TYPE_INT24 {
+				row[i] = uint32(v) & 0x00FFFFFF
+			} else {
+				row[i] = uint32(v)
+			}
+		case int64:
+			row[i] = uint64(v)
+		}
+	}
+}
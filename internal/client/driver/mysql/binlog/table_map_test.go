@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"reflect"
+	"testing"
+
+	gomysql "github.com/siddontang/go-mysql/mysql"
+	"github.com/siddontang/go-mysql/replication"
+)
+
+// newTableMapEvent makes a TABLE_MAP event of db1.t1 with the columns of columnTypes, as the source writes it,
+// with the optional metadata if not empty, and a checksum if checksum is set.
+func newTableMapEvent(columnTypes []byte, columnMeta []byte, optional []byte, checksum bool) *replication.BinlogEvent {
+	data := make([]byte, replication.EventHeaderSize)
+	data = append(data, 1, 0, 0, 0, 0, 0) // table id
+	data = append(data, 1, 0)             // flags
+	data = append(data, 3)
+	data = append(data, "db1"...)
+	data = append(data, 0, 2)
+	data = append(data, "t1"...)
+	data = append(data, 0, byte(len(columnTypes)))
+	data = append(data, columnTypes...)
+	data = append(data, byte(len(columnMeta)))
+	data = append(data, columnMeta...)
+	data = append(data, make([]byte, (len(columnTypes)+7)/8)...) // null bitmap
+	data = append(data, optional...)
+	if checksum {
+		data = append(data, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(data[:len(data)-4]))
+	}
+	return &replication.BinlogEvent{
+		RawData: data,
+		Header:  &replication.EventHeader{EventType: replication.TABLE_MAP_EVENT, EventSize: uint32(len(data))},
+		Event: &replication.TableMapEvent{
+			TableID:     1,
+			Schema:      []byte("db1"),
+			Table:       []byte("t1"),
+			ColumnCount: uint64(len(columnTypes)),
+			ColumnType:  columnTypes,
+		},
+	}
+}
+
+func Test_tableMapUnsigned(t *testing.T) {
+	// id BIGINT UNSIGNED, name VARCHAR(10), n INT UNSIGNED, m MEDIUMINT UNSIGNED, s SMALLINT
+	columnTypes := []byte{gomysql.MYSQL_TYPE_LONGLONG, gomysql.MYSQL_TYPE_VARCHAR, gomysql.MYSQL_TYPE_LONG,
+		gomysql.MYSQL_TYPE_INT24, gomysql.MYSQL_TYPE_SHORT}
+	columnMeta := []byte{40, 0} // of the VARCHAR
+	// signedness of the 4 numeric columns: 1110, and a DEFAULT_CHARSET field to be skipped
+	optional := []byte{2, 1, 45, tableMapOptionalSignedness, 1, 0xE0}
+	want := []bool{true, false, true, true, false}
+
+	// a checksum which is not the CRC32 of the event, e.g. as the event is not verified: the checksum is told by
+	// the FORMAT_DESCRIPTION_EVENT, not by the data.
+	unverified := newTableMapEvent(columnTypes, columnMeta, optional, true)
+	copy(unverified.RawData[len(unverified.RawData)-replication.BinlogChecksumLength:], []byte{1, 2, 3, 4})
+
+	const crc32Alg = replication.BINLOG_CHECKSUM_ALG_CRC32
+	tests := []struct {
+		name              string
+		ev                *replication.BinlogEvent
+		checksumAlgorithm byte
+		want              []bool
+	}{
+		{"signedness", newTableMapEvent(columnTypes, columnMeta, optional, false), replication.BINLOG_CHECKSUM_ALG_OFF, want},
+		{"checksum undefined", newTableMapEvent(columnTypes, columnMeta, optional, false), replication.BINLOG_CHECKSUM_ALG_UNDEF, want},
+		{"signedness with checksum", newTableMapEvent(columnTypes, columnMeta, optional, true), crc32Alg, want},
+		{"checksum not verified", unverified, crc32Alg, want},
+		{"no optional metadata", newTableMapEvent(columnTypes, columnMeta, nil, true), crc32Alg, nil},
+		{"truncated", newTableMapEvent(columnTypes, columnMeta, optional[:5], false), replication.BINLOG_CHECKSUM_ALG_OFF, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tableMapUnsigned(tt.ev, tt.checksumAlgorithm); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tableMapUnsigned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeUnsignedValues(t *testing.T) {
+	tableMap := &replication.TableMapEvent{
+		ColumnType: []byte{gomysql.MYSQL_TYPE_LONGLONG, gomysql.MYSQL_TYPE_LONG, gomysql.MYSQL_TYPE_INT24,
+			gomysql.MYSQL_TYPE_TINY, gomysql.MYSQL_TYPE_LONGLONG},
+	}
+	unsigned := []bool{true, true, true, true, false}
+
+	// 2^64-2, 2^32-1, 2^24-1 and 255 as decoded by go-mysql, and a signed -1
+	row := []interface{}{int64(-2), int32(-1), int32(-1), int8(-1), int64(-1)}
+	decodeUnsignedValues(tableMap, unsigned, row)
+	want := []interface{}{uint64(18446744073709551614), uint32(4294967295), uint32(16777215), uint8(255), int64(-1)}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("decodeUnsignedValues() = %v, want %v", row, want)
+	}
+
+	row = []interface{}{int64(9223372036854775807), int32(2147483647), nil, int8(1), nil}
+	decodeUnsignedValues(tableMap, unsigned, row)
+	want = []interface{}{uint64(9223372036854775807), uint32(2147483647), nil, uint8(1), nil}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("decodeUnsignedValues() = %v, want %v", row, want)
+	}
+
+	// no signedness in the TABLE_MAP event
+	row = []interface{}{int64(-2)}
+	decodeUnsignedValues(tableMap, nil, row)
+	if !reflect.DeepEqual(row, []interface{}{int64(-2)}) {
+		t.Errorf("decodeUnsignedValues() = %v, want it unchanged", row)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"bytes"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// The spans of the transactions are OpenTracing spans of the global tracer, i.e. the Jaeger tracer of the agent
+// (jaeger_agent_address), which the rest of dtle traces with. OpenTelemetry is not used: it needs a Go version
+// and dependencies far newer than those of this tree. For the same reason the span context is carried in the
+// transaction (BinlogEntry.TraceContext), as the NATS client and server of dtle have no message headers.
+
+const (
+	// the operation name of the span of a transaction
+	TxSpanName = "transaction"
+	// the tag of the span of a transaction with its gtid
+	TxSpanTagGtid = "gtid"
+)
+
+// StartTxSpan starts the span of the transaction (or a piece of it) of entry, following parent, and carries its
+// span context in entry.TraceContext to the destinations, so that the spans of a transaction on the source
+// and the destinations are in a trace. The caller finishes the span once entry is sent.
+func StartTxSpan(entry *BinlogEntry, parent opentracing.SpanContext) opentracing.Span {
+	tracer := opentracing.GlobalTracer()
+	var opts []opentracing.StartSpanOption
+	if parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	}
+	opts = append(opts, ext.SpanKindProducer)
+	span := tracer.StartSpan(TxSpanName, opts...)
+	span.SetTag(TxSpanTagGtid, entry.Coordinates.GetGtidForThisTx())
+	if entry.Index > 0 || entry.Partial {
+		span.SetTag("index", entry.Index)
+	}
+
+	var carrier bytes.Buffer
+	if err := tracer.Inject(span.Context(), opentracing.Binary, &carrier); err == nil {
+		entry.TraceContext = carrier.Bytes()
+	}
+	return span
+}
+
+// TxSpanContext returns the span context of the transaction of entry, received from the source,
+// or nil if it has none. See StartTxSpan.
+func TxSpanContext(entry *BinlogEntry) opentracing.SpanContext {
+	if len(entry.TraceContext) == 0 {
+		return nil
+	}
+	sc, err := opentracing.GlobalTracer().Extract(opentracing.Binary, bytes.NewReader(entry.TraceContext))
+	if err != nil {
+		return nil
+	}
+	return sc
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package binlog
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	opentracing "github.com/opentracing/opentracing-go"
+	uuid "github.com/satori/go.uuid"
+	jaeger "github.com/uber/jaeger-client-go"
+)
+
+func TestTxSpanContext(t *testing.T) {
+	reporter := jaeger.NewInMemoryReporter()
+	tracer, closer := jaeger.NewTracer("dtle", jaeger.NewConstSampler(true), reporter)
+	defer closer.Close()
+	prevTracer := opentracing.GlobalTracer()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(prevTracer)
+
+	// on the source
+	readSpan := tracer.StartSpan("read")
+	entry := NewBinlogEntryAt(base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: 12})
+	entry.SpanContext = readSpan.Context()
+	txSpan := StartTxSpan(entry, entry.SpanContext)
+	entry.SpanContext = nil
+	if len(entry.TraceContext) == 0 {
+		t.Fatalf("StartTxSpan() did not set TraceContext")
+	}
+
+	// the message to the destinations
+	var msg bytes.Buffer
+	if err := gob.NewEncoder(&msg).Encode(BinlogEntries{Entries: []*BinlogEntry{entry}}); err != nil {
+		t.Fatal(err)
+	}
+	txSpan.Finish()
+	readSpan.Finish()
+
+	// on a destination
+	var received BinlogEntries
+	if err := gob.NewDecoder(&msg).Decode(&received); err != nil {
+		t.Fatal(err)
+	}
+	sc := TxSpanContext(received.Entries[0])
+	if sc == nil {
+		t.Fatalf("TxSpanContext() = nil")
+	}
+	applySpan := tracer.StartSpan("apply", opentracing.ChildOf(sc))
+	applySpan.Finish()
+
+	txContext := txSpan.Context().(jaeger.SpanContext)
+	applyContext := applySpan.Context().(jaeger.SpanContext)
+	if applyContext.TraceID() != readSpan.Context().(jaeger.SpanContext).TraceID() {
+		t.Errorf("the span on the destination is in trace %v, want %v", applyContext.TraceID(),
+			readSpan.Context().(jaeger.SpanContext).TraceID())
+	}
+	if applyContext.ParentID() != txContext.SpanID() {
+		t.Errorf("the span on the destination has parent %v, want the transaction %v", applyContext.ParentID(),
+			txContext.SpanID())
+	}
+	if txContext.ParentID() != readSpan.Context().(jaeger.SpanContext).SpanID() {
+		t.Errorf("the transaction has parent %v, want %v", txContext.ParentID(),
+			readSpan.Context().(jaeger.SpanContext).SpanID())
+	}
+	if n := reporter.SpansSubmitted(); n != 3 {
+		t.Errorf("%v spans reported, want 3", n)
+	}
+
+	if sc := TxSpanContext(NewBinlogEntryAt(base.BinlogCoordinateTx{})); sc != nil {
+		t.Errorf("TxSpanContext() of an entry without TraceContext = %v, want nil", sc)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// columnDefaultFunctions are the values of config.ColumnDefault taken as functions rather than literals.
+var columnDefaultFunctions = []string{"NOW()", "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP()", "CURRENT_DATE",
+	"CURRENT_DATE()", "CURDATE()", "CURRENT_TIME", "CURRENT_TIME()", "CURTIME()", "UTC_TIMESTAMP()", "UUID()"}
+
+// columnDefaultExpr returns the SQL expression of a value of config.ColumnDefault.
+func columnDefaultExpr(value string) string {
+	for _, f := range columnDefaultFunctions {
+		if strings.EqualFold(value, f) {
+			return f
+		}
+	}
+	return fmt.Sprintf("'%s'", sql.EscapeValue(value))
+}
+
+// applyColumnDefault removes the columns of columnDefault from the destination columns of a table,
+// so that the rest are matched with the source columns in order. The removed ones are returned
+// with their values to be inserted. Columns of columnDefault not on the destination are ignored.
+func applyColumnDefault(columns *umconf.ColumnList, columnDefault *config.ColumnDefault) (
+	*umconf.ColumnList, []*sql.ColumnExpr) {
+
+	if columnDefault == nil || len(columnDefault.Columns) == 0 {
+		return columns, nil
+	}
+	var rest []umconf.Column
+	var exprs []*sql.ColumnExpr
+	for _, column := range columns.ColumnList() {
+		if value, ok := columnDefault.Columns[column.RawName]; ok {
+			exprs = append(exprs, &sql.ColumnExpr{EscapedName: column.EscapedName, Expr: columnDefaultExpr(value)})
+		} else {
+			rest = append(rest, column)
+		}
+	}
+	result := umconf.NewColumnList(rest)
+	result.SetQuote(columns.Quote())
+	return result, exprs
+}
+
+// getTableColumns reads the columns of a destination table, without those of ColumnDefault.
+// The columns of ColumnTypeOverride have CastType set.
+func (a *Applier) getTableColumns(schemaName string, tableName string) (*umconf.ColumnList, []*sql.ColumnExpr, error) {
+	columns, err := base.GetTableColumns(a.db, schemaName, tableName)
+	if err != nil {
+		a.logger.Errorf("mysql.applier. GetTableColumns error. err: %v", err)
+		return nil, nil, err
+	}
+	err = base.ApplyColumnTypes(a.db, schemaName, tableName, columns)
+	if err != nil {
+		a.logger.Errorf("mysql.applier. ApplyColumnTypes error. err: %v", err)
+		return nil, nil, err
+	}
+	columns.SetQuote(a.identifierQuote())
+	columnDefault := config.FindColumnDefault(a.mysqlContext.ColumnDefault, schemaName, tableName)
+	columns, exprs := applyColumnDefault(columns, columnDefault)
+	if len(exprs) > 0 {
+		a.logger.Debugf("mysql.applier: %v.%v. columns with ColumnDefault: %v", schemaName, tableName, len(exprs))
+	}
+	applyColumnTypeOverride(columns, config.FindColumnTypeOverride(a.mysqlContext.ColumnTypeOverride, schemaName, tableName))
+	return columns, exprs, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func Test_columnDefaultExpr(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"now()", "NOW()"},
+		{"CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{"uuid()", "UUID()"},
+		{"0", "'0'"},
+		{"it's", `'it\'s'`},
+		{"NOW() + 1", "'NOW() + 1'"},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := columnDefaultExpr(tt.value); got != tt.want {
+			t.Errorf("columnDefaultExpr(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func Test_applyColumnDefault(t *testing.T) {
+	columns := umconf.NewColumnList(umconf.ParseColumns("id,c_extra,name,c_time"))
+	columnDefault := &config.ColumnDefault{
+		TableSchema: "db1",
+		TableName:   "tb1",
+		Columns:     map[string]string{"c_extra": "unknown", "c_time": "now()", "c_missing": "1"},
+	}
+	rest, exprs := applyColumnDefault(columns, columnDefault)
+	if got := rest.Names(); !reflect.DeepEqual(got, []string{"id", "name"}) {
+		t.Errorf("columns = %v, want [id name]", got)
+	}
+	if rest.Ordinals["name"] != 1 {
+		t.Errorf("ordinal of name = %v, want 1", rest.Ordinals["name"])
+	}
+	want := []*sql.ColumnExpr{{EscapedName: "`c_extra`", Expr: "'unknown'"}, {EscapedName: "`c_time`", Expr: "NOW()"}}
+	if !reflect.DeepEqual(exprs, want) {
+		t.Errorf("exprs = %+v, want %+v", exprs, want)
+	}
+
+	if rest, exprs := applyColumnDefault(columns, nil); rest != columns || exprs != nil {
+		t.Errorf("applyColumnDefault() without ColumnDefault = %v, %v", rest, exprs)
+	}
+}
+
+// The destination table has NOT NULL columns without defaults, which the source does not have.
+func TestApplier_columnDefault(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true" +
+		"&sql_mode='STRICT_ALL_TABLES'")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	_, err = db.Exec("drop database if exists dtle_test_coldef;" +
+		"create database dtle_test_coldef;" +
+		"create table dtle_test_coldef.tb1 (id int primary key, c_extra varchar(20) not null," +
+		"  name varchar(20), c_time datetime not null)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("drop database if exists dtle_test_coldef")
+
+	a := &Applier{
+		logger: logrus.NewEntry(logrus.New()),
+		db:     db,
+		mysqlContext: &config.MySQLDriverConfig{
+			ColumnDefault: []*config.ColumnDefault{{
+				TableSchema: "dtle_test_coldef",
+				TableName:   "tb1",
+				Columns:     map[string]string{"c_extra": "unknown", "c_time": "NOW()"},
+			}},
+		},
+	}
+
+	// full copy
+	v1, v2 := []byte("1"), []byte("a")
+	entry := &DumpEntry{
+		TableSchema: "dtle_test_coldef",
+		TableName:   "tb1",
+		ValuesX:     [][]*[]byte{{&v1, &v2}},
+		RowsCount:   1,
+	}
+	if err := a.ApplyEventQueries(db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+
+	// incremental
+	columns, exprs, err := a.getTableColumns("dtle_test_coldef", "tb1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, name := interface{}(int64(2)), interface{}("b")
+	query, args, err := sql.BuildDMLInsertQueryWithExprs("dtle_test_coldef", "tb1", columns, columns, columns,
+		[]*interface{}{&id, &name}, exprs)
+	if err != nil {
+		t.Fatalf("BuildDMLInsertQueryWithExprs() error = %v", err)
+	}
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("insert error = %v. query: %v", err, query)
+	}
+
+	rows, err := db.Query("select id, c_extra, name, c_time > now() - interval 1 hour from dtle_test_coldef.tb1 order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var id int
+		var extra, name string
+		var recent bool
+		if err := rows.Scan(&id, &extra, &name, &recent); err != nil {
+			t.Fatal(err)
+		}
+		if !recent {
+			t.Errorf("c_time of row %v is not NOW()", id)
+		}
+		got = append(got, extra+"/"+name)
+	}
+	if want := []string{"unknown/a", "unknown/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+}
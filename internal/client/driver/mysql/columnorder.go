@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// columnNameIndexes returns, for each name of destNames, the index of the same name (case-insensitive)
+// in sourceNames. It returns nil if a name is missing, or if the names are the same in the same order.
+func columnNameIndexes(sourceNames []string, destNames []string) []int {
+	sourceIndexes := make(map[string]int, len(sourceNames))
+	for i, name := range sourceNames {
+		sourceIndexes[strings.ToLower(name)] = i
+	}
+	indexes := make([]int, len(destNames))
+	inOrder := len(destNames) == len(sourceNames)
+	for i, name := range destNames {
+		j, ok := sourceIndexes[strings.ToLower(name)]
+		if !ok {
+			return nil
+		}
+		indexes[i] = j
+		inOrder = inOrder && i == j
+	}
+	if inOrder {
+		return nil
+	}
+	return indexes
+}
+
+// columnValueIndexes returns, for each column of a destination table, the index of its value in the rows
+// of the source table, e.g. after the source has added a column in the middle of the table while the
+// destination has it at the end. It returns nil to match the values in order, as usual: if the columns are
+// the same in the same order, if a destination column is not on the source (e.g. renamed), or if the
+// source columns are unknown.
+func columnValueIndexes(sourceColumns *umconf.ColumnList, destColumns *umconf.ColumnList) []int {
+	if sourceColumns == nil || destColumns == nil {
+		return nil
+	}
+	return columnNameIndexes(sourceColumns.Names(), destColumns.Names())
+}
+
+// reorderColumnValues returns the values at indexes, in the order of the destination columns.
+// Values of another row length are returned as is.
+func reorderColumnValues(values *umconf.ColumnValues, indexes []int, sourceLen int) *umconf.ColumnValues {
+	if values == nil || len(values.AbstractValues) != sourceLen {
+		return values
+	}
+	reordered := make([]*interface{}, len(indexes))
+	for i, j := range indexes {
+		reordered[i] = values.AbstractValues[j]
+	}
+	return &umconf.ColumnValues{AbstractValues: reordered}
+}
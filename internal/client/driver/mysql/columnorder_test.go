@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pingcap/parser"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	sqle "github.com/actiontech/dtle/internal/client/driver/mysql/sqle/inspector"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func Test_columnNameIndexes(t *testing.T) {
+	tests := []struct {
+		name   string
+		source []string
+		dest   []string
+		want   []int
+	}{
+		{"same", []string{"id", "a", "b"}, []string{"id", "a", "b"}, nil},
+		{"reordered", []string{"id", "a", "c", "b"}, []string{"id", "a", "b", "c"}, []int{0, 1, 3, 2}},
+		{"case-insensitive", []string{"ID", "B", "A"}, []string{"id", "a", "b"}, []int{0, 2, 1}},
+		{"fewer on the destination", []string{"id", "a", "c", "b"}, []string{"id", "a", "b"}, []int{0, 1, 3}},
+		{"prefix on the destination", []string{"id", "a", "b"}, []string{"id", "a"}, []int{0, 1}},
+		{"renamed on the destination", []string{"id", "a", "b"}, []string{"id", "b", "x"}, nil},
+	}
+	for _, tt := range tests {
+		if got := columnNameIndexes(tt.source, tt.dest); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: columnNameIndexes() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// The source adds a column in the middle of a table. The destination, which has not applied the ALTER in the
+// same way, has it at the end. A row inserted after the ALTER is written to the right columns.
+func TestColumnOrder_addColumnInTheMiddle(t *testing.T) {
+	context := sqle.NewContext(nil)
+	context.LoadSchemas([]string{"db1"})
+	context.LoadTables("db1", nil)
+	for _, query := range []string{
+		"create table db1.t1 (id int primary key, a int, b varchar(10))",
+		"alter table db1.t1 add column c int after a",
+		"alter table db1.t1 add column d int first",
+		"alter table db1.t1 modify column b varchar(20) after d",
+	} {
+		stmt, err := parser.New().ParseOneStmt(query, "", "")
+		if err != nil {
+			t.Fatalf("%v: %v", query, err)
+		}
+		context.UpdateContext(stmt, "mysql")
+	}
+	sourceColumns, err := base.GetTableColumnsSqle(context, "db1", "t1")
+	if err != nil {
+		t.Fatalf("GetTableColumnsSqle() error = %v", err)
+	}
+	if got, want := sourceColumns.Names(), []string{"d", "b", "id", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("source columns %v, want %v", got, want)
+	}
+
+	destColumns := umconf.NewColumnList(umconf.NewColumns([]string{"id", "a", "b", "c", "d"}))
+	indexes := columnValueIndexes(sourceColumns, destColumns)
+	var row []*interface{}
+	for _, v := range []interface{}{int64(4), "two", int64(1), int64(10), int64(3)} {
+		v := v
+		row = append(row, &v)
+	}
+	values := reorderColumnValues(&umconf.ColumnValues{AbstractValues: row}, indexes, len(sourceColumns.Columns))
+	query, args, err := sql.BuildDMLInsertQuery("db1", "t1", destColumns, destColumns, destColumns,
+		values.GetAbstractValues())
+	if err != nil {
+		t.Fatalf("BuildDMLInsertQuery() error = %v", err)
+	}
+	if want := []interface{}{int64(1), int64(10), "two", int64(3), int64(4)}; !reflect.DeepEqual(args, want) {
+		t.Errorf("args of %v = %v, want %v", query, args, want)
+	}
+
+	// a row of another length (unknown layout) is left as is
+	short := &umconf.ColumnValues{AbstractValues: row[:3]}
+	if got := reorderColumnValues(short, indexes, len(sourceColumns.Columns)); got != short {
+		t.Errorf("reorderColumnValues() of a short row = %v, want it as is", got)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// castTypeRegex matches the types of CAST, which are the values of ColumnTypeOverride.
+var castTypeRegex = regexp.MustCompile(
+	`(?i)^(BINARY|CHAR|NCHAR|DATE|DATETIME|TIME|DECIMAL|SIGNED|UNSIGNED|JSON|DOUBLE|FLOAT|REAL|YEAR)` +
+		`(\s*\(\s*\d+\s*(,\s*\d+\s*)?\))?(\s+INTEGER)?$`)
+
+func validateColumnTypeOverrides(overrides []*config.ColumnTypeOverride) (errs []error) {
+	for i, o := range overrides {
+		for column, castType := range o.Columns {
+			if !castTypeRegex.MatchString(strings.TrimSpace(castType)) {
+				errs = append(errs, fmt.Errorf("bad job argument: ColumnTypeOverride[%v].Columns[%v]=%v. should be a type of CAST,"+
+					" e.g. SIGNED, UNSIGNED, DECIMAL(20,4), CHAR(10), DATETIME(6)", i, column, castType))
+			}
+		}
+	}
+	return errs
+}
+
+// applyColumnTypeOverride sets CastType of the columns of a destination table in override.
+func applyColumnTypeOverride(columns *umconf.ColumnList, override *config.ColumnTypeOverride) {
+	if override == nil {
+		return
+	}
+	for i := range columns.Columns {
+		if castType, ok := override.Columns[columns.Columns[i].RawName]; ok {
+			columns.Columns[i].CastType = strings.TrimSpace(castType)
+		}
+	}
+}
+
+// columnTypeRegex takes the name, the length or precision, and the scale of a column type,
+// like "int(11) unsigned" in information_schema.COLUMNS, or "DECIMAL(10,2)" of a source table.
+var columnTypeRegex = regexp.MustCompile(`^(\w+)(?:\s*\(\s*(\d+)(?:\s*,\s*(\d+))?\s*\))?`)
+
+type columnTypeInfo struct {
+	name     string
+	length   int // the length of a string type, the precision of DECIMAL or the fsp of a temporal type
+	scale    int
+	unsigned bool
+}
+
+func parseColumnType(columnType string) (t columnTypeInfo) {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	m := columnTypeRegex.FindStringSubmatch(columnType)
+	if m == nil {
+		return t
+	}
+	t.name = m[1]
+	t.length, _ = strconv.Atoi(m[2])
+	t.scale, _ = strconv.Atoi(m[3])
+	t.unsigned = strings.Contains(columnType[len(m[0]):], "unsigned")
+	return t
+}
+
+var (
+	integerTypeBytes = map[string]int{"tinyint": 1, "smallint": 2, "mediumint": 3, "int": 4, "integer": 4, "bigint": 8}
+	lobTypeRanks     = map[string]int{"tinytext": 1, "text": 2, "mediumtext": 3, "longtext": 4,
+		"tinyblob": 1, "blob": 2, "mediumblob": 3, "longblob": 4}
+	stringTypes   = map[string]bool{"char": true, "varchar": true, "binary": true, "varbinary": true}
+	temporalTypes = map[string]bool{"datetime": true, "timestamp": true, "time": true}
+)
+
+// isLossyNarrowing tells whether writing the values of sourceType to a column of destType may lose data,
+// e.g. BIGINT to INT, or VARCHAR(100) to VARCHAR(10). Types of different kinds are not compared.
+func isLossyNarrowing(sourceType string, destType string) bool {
+	src, dest := parseColumnType(sourceType), parseColumnType(destType)
+	if src.name == "" || dest.name == "" {
+		return false
+	}
+	if srcBytes, ok := integerTypeBytes[src.name]; ok {
+		destBytes, ok := integerTypeBytes[dest.name]
+		switch {
+		case !ok:
+			return false
+		case dest.unsigned && !src.unsigned:
+			return true // negative values
+		case src.unsigned && !dest.unsigned:
+			return destBytes <= srcBytes
+		default:
+			return destBytes < srcBytes
+		}
+	}
+	switch {
+	case stringTypes[src.name] && stringTypes[dest.name]:
+		return dest.length < src.length
+	case lobTypeRanks[src.name] > 0 && lobTypeRanks[dest.name] > 0:
+		return lobTypeRanks[dest.name] < lobTypeRanks[src.name]
+	case (src.name == "decimal" || src.name == "numeric") && (dest.name == "decimal" || dest.name == "numeric"):
+		return dest.length-dest.scale < src.length-src.scale || dest.scale < src.scale ||
+			(dest.unsigned && !src.unsigned)
+	case src.name == "double" && dest.name == "float":
+		return true
+	case temporalTypes[src.name] && temporalTypes[dest.name]:
+		return dest.length < src.length
+	case src.name == "datetime" && dest.name == "date":
+		return true
+	}
+	return false
+}
+
+// columnTypeNarrowings returns a message for each column of the source narrowed on the destination.
+// destTypes are the COLUMN_TYPE of the destination columns. They are matched with the source columns
+// as the values are, see columnValueIndexes.
+func columnTypeNarrowings(sourceColumns *umconf.ColumnList, destNames []string, destTypes []string) (msgs []string) {
+	indexes := columnNameIndexes(sourceColumns.Names(), destNames)
+	for i := range destTypes {
+		j := i
+		if indexes != nil {
+			j = indexes[i]
+		} else if i >= len(sourceColumns.Columns) {
+			break
+		}
+		if source := &sourceColumns.Columns[j]; isLossyNarrowing(source.ColumnType, destTypes[i]) {
+			msgs = append(msgs, fmt.Sprintf("%v %v -> %v %v", source.RawName, source.ColumnType, destNames[i], destTypes[i]))
+		}
+	}
+	return msgs
+}
+
+// checkColumnTypes warns about the columns narrowed on the destination table, by comparing the source table,
+// which the applier gets with the first rows of it, with information_schema.COLUMNS of the destination.
+func (a *Applier) checkColumnTypes(sourceTable *config.Table, schemaName string, tableName string) {
+	if sourceTable == nil || sourceTable.OriginalTableColumns == nil {
+		return
+	}
+	query := `select COLUMN_NAME, COLUMN_TYPE from information_schema.columns
+		where table_schema = ? and table_name = ? order by ORDINAL_POSITION`
+	columnDefault := config.FindColumnDefault(a.mysqlContext.ColumnDefault, schemaName, tableName)
+	var destNames, destTypes []string
+	err := sql.QueryRowsMap(a.db, query, func(m sql.RowMap) error {
+		name := m.GetString("COLUMN_NAME")
+		if columnDefault != nil {
+			if _, ok := columnDefault.Columns[name]; ok {
+				return nil
+			}
+		}
+		destNames = append(destNames, name)
+		destTypes = append(destTypes, m.GetString("COLUMN_TYPE"))
+		return nil
+	}, schemaName, tableName)
+	if err != nil {
+		a.logger.Warnf("mysql.applier: failed to check the column types of %v.%v. err: %v", schemaName, tableName, err)
+		return
+	}
+	override := config.FindColumnTypeOverride(a.mysqlContext.ColumnTypeOverride, schemaName, tableName)
+	for _, msg := range columnTypeNarrowings(sourceTable.OriginalTableColumns, destNames, destTypes) {
+		a.logger.Warnf("mysql.applier: %v.%v: a column is narrower on the destination and its values might be"+
+			" truncated or fail: %v. ColumnTypeOverride of the table: %v", schemaName, tableName, msg, override != nil)
+	}
+}
+
+// checkDumpColumnTypes is checkColumnTypes for a DumpEntry, which carries the source table with its first rows.
+func (a *Applier) checkDumpColumnTypes(entry *DumpEntry) {
+	if len(entry.Table) == 0 {
+		return
+	}
+	table := &config.Table{}
+	if err := gob.NewDecoder(bytes.NewBuffer(entry.Table)).Decode(table); err != nil {
+		a.logger.Warnf("mysql.applier: failed to decode the table %v.%v. err: %v", entry.TableSchema, entry.TableName, err)
+		return
+	}
+	a.checkColumnTypes(table, entry.TableSchema, entry.TableName)
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func Test_isLossyNarrowing(t *testing.T) {
+	tests := []struct {
+		source string
+		dest   string
+		want   bool
+	}{
+		{"INT(11)", "bigint(20)", false}, // widening
+		{"bigint(20)", "int(11)", true},
+		{"int(11)", "int", false},
+		{"int(10) UNSIGNED", "int(11)", true},
+		{"int(10) UNSIGNED", "bigint(20)", false},
+		{"int(11)", "bigint(20) unsigned", true},
+		{"varchar(100)", "varchar(10)", true},
+		{"char(10)", "varchar(20)", false},
+		{"mediumtext", "text", true},
+		{"decimal(10,2)", "decimal(12,2)", false},
+		{"decimal(10,2)", "decimal(10,4)", true},
+		{"decimal(10,4)", "decimal(10,2)", true},
+		{"double", "float", true},
+		{"datetime(6)", "datetime", true},
+		{"datetime", "date", true},
+		{"int(11)", "varchar(10)", false}, // different kinds are not compared
+		{"", "int(11)", false},
+	}
+	for _, tt := range tests {
+		if got := isLossyNarrowing(tt.source, tt.dest); got != tt.want {
+			t.Errorf("isLossyNarrowing(%v, %v) = %v, want %v", tt.source, tt.dest, got, tt.want)
+		}
+	}
+}
+
+func Test_columnTypeNarrowings(t *testing.T) {
+	source := umconf.NewColumns([]string{"id", "v"})
+	source[0].ColumnType = "int(11)"
+	source[1].ColumnType = "bigint(20)"
+	got := columnTypeNarrowings(umconf.NewColumnList(source), []string{"id", "v"}, []string{"bigint(20)", "int(11)"})
+	if want := []string{"v bigint(20) -> v int(11)"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("columnTypeNarrowings() = %v, want %v", got, want)
+	}
+}
+
+func Test_applyColumnTypeOverride(t *testing.T) {
+	columns := umconf.NewColumnList(umconf.NewColumns([]string{"id", "v"}))
+	columns.Columns[0].Key = "PRI"
+	applyColumnTypeOverride(columns, &config.ColumnTypeOverride{Columns: map[string]string{"id": " SIGNED "}})
+	if columns.Columns[0].CastType != "SIGNED" || columns.Columns[1].CastType != "" {
+		t.Fatalf("CastType = %q, %q", columns.Columns[0].CastType, columns.Columns[1].CastType)
+	}
+
+	var id, v interface{} = int32(1), "a"
+	args := []*interface{}{&id, &v}
+	query, _, err := sql.BuildDMLInsertQuery("db1", "t1", columns, columns, columns, args)
+	if err != nil {
+		t.Fatalf("BuildDMLInsertQuery() error = %v", err)
+	}
+	if !strings.Contains(query, "(cast(? as SIGNED), ?)") {
+		t.Errorf("BuildDMLInsertQuery() = %v, want the id cast", query)
+	}
+	query, _, _, _, err = sql.BuildDMLUpdateQuery("db1", "t1", columns, columns, columns, columns, args, args)
+	if err != nil {
+		t.Fatalf("BuildDMLUpdateQuery() error = %v", err)
+	}
+	if !strings.Contains(query, "`id`=cast(? as SIGNED), `v`=?") {
+		t.Errorf("BuildDMLUpdateQuery() = %v, want the id cast", query)
+	}
+
+	errs := validateColumnTypeOverrides([]*config.ColumnTypeOverride{{Columns: map[string]string{
+		"a": "SIGNED", "b": "decimal(20, 4)", "c": "CHAR(10)", "d": "SIGNED INTEGER", "e": "BIGINT"}}})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Columns[e]=BIGINT") {
+		t.Errorf("validateColumnTypeOverrides() = %v, want an error of e", errs)
+	}
+}
+
+type testWarningHook struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (h *testWarningHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel}
+}
+
+func (h *testWarningHook) Fire(entry *logrus.Entry) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+// The source has id INT, which the destination widened to BIGINT, and v BIGINT narrowed to INT.
+// The values of id are cast to SIGNED, and the narrowing of v is warned about.
+func TestApplier_columnTypeOverride(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		ColumnTypeOverride: []*config.ColumnTypeOverride{
+			{TableSchema: "dtle_test", TableName: "t_cast", Columns: map[string]string{"id": "SIGNED"}},
+		},
+	}
+	logger := logrus.New()
+	hook := &testWarningHook{}
+	logger.AddHook(hook)
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logger)
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	if _, err := a.db.Exec("create database if not exists dtle_test; drop table if exists dtle_test.t_cast;" +
+		"create table dtle_test.t_cast (id bigint primary key, v int)"); err != nil {
+		t.Fatalf("prepare error = %v", err)
+	}
+	sourceColumns := umconf.NewColumns([]string{"id", "v"})
+	sourceColumns[0].ColumnType = "int(11)"
+	sourceColumns[1].ColumnType = "bigint(20)"
+	table := config.NewTable("dtle_test", "t_cast")
+	table.OriginalTableColumns = umconf.NewColumnList(sourceColumns)
+	tableBs, err := GobEncode(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the full copy
+	one, two := []byte("1"), []byte("2")
+	entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_cast", Table: tableBs, RowsCount: 1,
+		ValuesX: [][]*[]byte{{&one, &two}}}
+	if err := a.ApplyEventQueries(a.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+	if len(hook.messages) != 1 || !strings.Contains(hook.messages[0], "v bigint(20) -> v int(11)") {
+		t.Errorf("warnings = %v, want one of v", hook.messages)
+	}
+
+	// the incremental copy
+	var id, v interface{} = int32(2147483647), int64(3)
+	binlogEntry := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: 1},
+		Events: []binlog.DataEvent{{
+			DatabaseName:    "dtle_test",
+			TableName:       "t_cast",
+			DML:             binlog.InsertDML,
+			NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&id, &v}},
+			Table:           table,
+		}},
+	}
+	if err := a.setTableItemForBinlogEntry(binlogEntry); err != nil {
+		t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+	}
+	if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], binlogEntry); err != nil {
+		t.Fatalf("ApplyBinlogEvent() error = %v", err)
+	}
+	if err := a.commitApplyBatch(0, a.applyBatches[0]); err != nil {
+		t.Fatalf("commitApplyBatch() error = %v", err)
+	}
+	if len(hook.messages) != 2 {
+		t.Errorf("warnings = %v, want one more of v", hook.messages)
+	}
+
+	var sum int64
+	if err := a.db.QueryRow("select sum(id) + sum(v) from dtle_test.t_cast").Scan(&sum); err != nil {
+		t.Fatalf("select error = %v", err)
+	}
+	if want := int64(1 + 2 + 2147483647 + 3); sum != want {
+		t.Errorf("sum of the rows = %v, want %v", sum, want)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/snappy"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// A message of the full copy is compressed by DumpMsgCompression, and the incremental copy always by snappy.
+// The receiver tells the method of each message by its first bytes, so that the messages sent before a change
+// of DumpMsgCompression, or recorded in an EventSinkFile, can still be read:
+//   - a gzip stream begins with 0x1f 0x8b.
+//   - a snappy block begins with the varint of its decoded length and then a literal, of which the tag has
+//     the low 2 bits unset. 0x8b is the tag of a copy, which is invalid in the first place. So a snappy
+//     block never begins as a gzip stream.
+
+// dumpCompressor compresses a message of the full copy.
+type dumpCompressor func(msg []byte) ([]byte, error)
+
+func snappyCompress(msg []byte) ([]byte, error) {
+	return snappy.Encode(nil, msg), nil
+}
+
+// newDumpCompressor returns the compressor of the method and level of DumpMsgCompression.
+func newDumpCompressor(method string, level int) (dumpCompressor, error) {
+	switch method {
+	case "", config.DumpMsgCompressionSnappy:
+		return snappyCompress, nil
+	case config.DumpMsgCompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		} else if level < gzip.BestSpeed || level > gzip.BestCompression {
+			return nil, fmt.Errorf("bad job argument: DumpMsgCompressionLevel=%v. should be %v to %v for gzip",
+				level, gzip.BestSpeed, gzip.BestCompression)
+		}
+		// a gzip writer allocates a lot. They are reused, as a message is compressed for each chunk of rows.
+		writers := &sync.Pool{New: func() interface{} {
+			w, _ := gzip.NewWriterLevel(nil, level)
+			return w
+		}}
+		return func(msg []byte) ([]byte, error) {
+			buf := bytes.NewBuffer(make([]byte, 0, len(msg)/4))
+			w := writers.Get().(*gzip.Writer)
+			defer writers.Put(w)
+			w.Reset(buf)
+			if _, err := w.Write(msg); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("bad job argument: DumpMsgCompression=%v. should be one of %v, %v",
+			method, config.DumpMsgCompressionSnappy, config.DumpMsgCompressionGzip)
+	}
+}
+
+var gzipReaders sync.Pool
+
+// decompressDumpMsg decompresses a message of the full copy, of any method.
+func decompressDumpMsg(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return snappy.Decode(nil, data)
+	}
+	var r *gzip.Reader
+	if v := gzipReaders.Get(); v != nil {
+		r = v.(*gzip.Reader)
+		if err := r.Reset(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if r, err = gzip.NewReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	defer gzipReaders.Put(r)
+	return ioutil.ReadAll(r)
+}
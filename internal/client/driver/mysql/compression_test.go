@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/golang/snappy"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// testDumpEntry returns a chunk of the full copy of rows of a typical table: an id, a name, an email, an amount,
+// a datetime, a status and a comment of some words, some NULL.
+func testDumpEntry(nRows int) *DumpEntry {
+	rnd := rand.New(rand.NewSource(1))
+	words := []string{"order", "shipped", "customer", "refund", "pending", "the", "of", "to", "please", "contact",
+		"address", "changed", "item", "delayed", "warehouse", "payment", "received", "cancelled", "new", "for"}
+	statuses := []string{"NEW", "PAID", "SHIPPED", "DONE", "CANCELLED"}
+	entry := &DumpEntry{TableSchema: "db1", TableName: "orders", RowsCount: int64(nRows), TotalCount: int64(nRows)}
+	for i := 0; i < nRows; i++ {
+		var comment []byte
+		for j := rnd.Intn(30); j > 0; j-- {
+			comment = append(comment, words[rnd.Intn(len(words))]...)
+			comment = append(comment, ' ')
+		}
+		values := [][]byte{
+			[]byte(fmt.Sprintf("%d", 100000+i)),
+			[]byte(fmt.Sprintf("user%06d", rnd.Intn(50000))),
+			[]byte(fmt.Sprintf("user%06d@example.com", rnd.Intn(50000))),
+			[]byte(fmt.Sprintf("%d.%02d", rnd.Intn(10000), rnd.Intn(100))),
+			[]byte(fmt.Sprintf("2019-%02d-%02d %02d:%02d:%02d", 1+rnd.Intn(12), 1+rnd.Intn(28), rnd.Intn(24),
+				rnd.Intn(60), rnd.Intn(60))),
+			[]byte(statuses[rnd.Intn(len(statuses))]),
+			comment,
+		}
+		row := make([]*[]byte, len(values))
+		for j := range values {
+			if j == len(values)-1 && rnd.Intn(4) == 0 {
+				continue // NULL
+			}
+			row[j] = &values[j]
+		}
+		entry.ValuesX = append(entry.ValuesX, row)
+	}
+	return entry
+}
+
+func TestDumpMsgCompression(t *testing.T) {
+	entry := testDumpEntry(100)
+	bs, err := entry.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		method string
+		level  int
+	}{
+		{"", 0},
+		{config.DumpMsgCompressionSnappy, 0},
+		{config.DumpMsgCompressionGzip, 0},
+		{config.DumpMsgCompressionGzip, 1},
+		{config.DumpMsgCompressionGzip, 9},
+	} {
+		compress, err := newDumpCompressor(tt.method, tt.level)
+		if err != nil {
+			t.Fatalf("newDumpCompressor(%q, %v) error = %v", tt.method, tt.level, err)
+		}
+		// twice, with a reused writer and reader
+		for i := 0; i < 2; i++ {
+			msg, err := compress(bs)
+			if err != nil {
+				t.Fatalf("%v-%v: compress error = %v", tt.method, tt.level, err)
+			}
+			got, err := DecodeDumpEntry(msg)
+			if err != nil {
+				t.Fatalf("%v-%v: DecodeDumpEntry() error = %v", tt.method, tt.level, err)
+			}
+			if !reflect.DeepEqual(got, entry) {
+				t.Fatalf("%v-%v: DecodeDumpEntry() = %+v, want %+v", tt.method, tt.level, got, entry)
+			}
+		}
+	}
+
+	// snappy messages of any length, e.g. of an older source task, are not taken for gzip ones
+	for n := 0; n < 300; n++ {
+		msg := make([]byte, n)
+		for i := range msg {
+			msg[i] = 0x8b
+		}
+		got, err := decompressDumpMsg(snappy.Encode(nil, msg))
+		if err != nil || !bytes.Equal(got, msg) {
+			t.Fatalf("decompressDumpMsg() of %v bytes = %v, %v", n, len(got), err)
+		}
+	}
+
+	for _, tt := range []struct {
+		method string
+		level  int
+	}{
+		{"zstd", 0},
+		{config.DumpMsgCompressionGzip, 10},
+		{config.DumpMsgCompressionGzip, -2},
+	} {
+		if _, err := newDumpCompressor(tt.method, tt.level); err == nil {
+			t.Errorf("newDumpCompressor(%q, %v): want an error", tt.method, tt.level)
+		}
+	}
+}
+
+// BenchmarkDumpMsgCompression compresses and decompresses a chunk of the default ChunkSize.
+// The size of the compressed chunk is logged.
+func BenchmarkDumpMsgCompression(b *testing.B) {
+	bs, err := testDumpEntry(2000).Marshal(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, method := range []struct {
+		name   string
+		method string
+		level  int
+	}{
+		{"snappy", config.DumpMsgCompressionSnappy, 0},
+		{"gzip-1", config.DumpMsgCompressionGzip, 1},
+		{"gzip-6", config.DumpMsgCompressionGzip, 6},
+		{"gzip-9", config.DumpMsgCompressionGzip, 9},
+	} {
+		compress, err := newDumpCompressor(method.method, method.level)
+		if err != nil {
+			b.Fatal(err)
+		}
+		msg, err := compress(bs)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Logf("%v: %v bytes to %v (%.1f%%)", method.name, len(bs), len(msg), float64(len(msg))*100/float64(len(bs)))
+		b.Run(method.name+"/compress", func(b *testing.B) {
+			b.SetBytes(int64(len(bs)))
+			for i := 0; i < b.N; i++ {
+				if _, err := compress(bs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(method.name+"/decompress", func(b *testing.B) {
+			b.SetBytes(int64(len(bs)))
+			for i := 0; i < b.N; i++ {
+				if _, err := decompressDumpMsg(msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+)
+
+// With ConflictResolutionColumn, a row changed on both sides of active-active replication keeps the last
+// writer: an incoming insert or update is compared with the existing row and dropped if it is not newer.
+// The changes applied by dtle are not replicated back, see OSID of the gtid_executed table.
+
+// isNewerRow tells whether a row event of the tx from server txSid is to be applied by ConflictResolutionColumn.
+// It is true if the table has no such column or no primary key, or the row does not exist on the destination.
+func (a *Applier) isNewerRow(tx applyTx, event *binlog.DataEvent, txSid string) (bool, error) {
+	if event.DML != binlog.InsertDML && event.DML != binlog.UpdateDML {
+		return true, nil
+	}
+	tableColumns := event.TableItem.(*applierTableItem).columns
+	resolutionColumn := tableColumns.GetColumn(a.mysqlContext.ConflictResolutionColumn)
+	if resolutionColumn == nil || !sql.HasPrimaryKey(tableColumns) {
+		return true, nil
+	}
+
+	newValues := event.NewColumnValues.GetAbstractValues()
+	keyValues := newValues
+	if event.DML == binlog.UpdateDML {
+		// the row before the update
+		keyValues = event.WhereColumnValues.GetAbstractValues()
+	}
+	resolutionValue := *newValues[tableColumns.Ordinals[resolutionColumn.RawName]]
+	// On a tie, both servers pick the change from the same one.
+	tieWins := txSid > a.mysqlContext.MySQLServerUuid
+	query, args, err := sql.BuildConflictCheckQuery(event.DatabaseName, event.TableName, tableColumns,
+		resolutionColumn, resolutionValue, keyValues, tieWins)
+	if err != nil {
+		return false, err
+	}
+
+	var newer bool
+	err = tx.QueryRowContext(context.Background(), query, args...).Scan(&newer)
+	if err == gosql.ErrNoRows {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return newer, nil
+}
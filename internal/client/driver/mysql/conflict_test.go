@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func TestApplier_conflictResolution(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		ConflictResolutionColumn: "ver",
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	if _, err := a.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_conflict;" +
+		"create table dtle_test.t_conflict (id bigint primary key, val varchar(16), ver bigint)"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+
+	// the destination and two other servers, one on each side of it in the tie
+	a.mysqlContext.MySQLServerUuid = "50000000-0000-0000-0000-000000000000"
+	lower := uuid.Must(uuid.FromString("40000000-0000-0000-0000-000000000000"))
+	greater := uuid.Must(uuid.FromString("60000000-0000-0000-0000-000000000000"))
+	var gno int64
+	apply := func(sid uuid.UUID, event binlog.DataEvent) {
+		gno++
+		event.DatabaseName = "dtle_test"
+		event.TableName = "t_conflict"
+		entry := &binlog.BinlogEntry{
+			Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: gno},
+			Events:      []binlog.DataEvent{event},
+		}
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			t.Fatalf("ApplyBinlogEvent() error = %v", err)
+		}
+		if err := a.commitApplyBatch(0, a.applyBatches[0]); err != nil {
+			t.Fatalf("commitApplyBatch() error = %v", err)
+		}
+	}
+	row := func(id int64, val string, ver interface{}) *umconf.ColumnValues {
+		var v1, v2, v3 interface{} = id, val, ver
+		return &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2, &v3}}
+	}
+	insert := func(id int64, val string, ver interface{}) binlog.DataEvent {
+		return binlog.DataEvent{DML: binlog.InsertDML, NewColumnValues: row(id, val, ver)}
+	}
+	// the row before the update does not matter but its key
+	update := func(id int64, val string, ver interface{}) binlog.DataEvent {
+		return binlog.DataEvent{DML: binlog.UpdateDML, WhereColumnValues: row(id, "", nil), NewColumnValues: row(id, val, ver)}
+	}
+	check := func(name string, id int64, wantVal string, wantVer gosql.NullInt64) {
+		var val string
+		var ver gosql.NullInt64
+		if err := a.db.QueryRow("select val, ver from dtle_test.t_conflict where id = ?", id).Scan(&val, &ver); err != nil {
+			t.Fatalf("%v: select row %v error = %v", name, id, err)
+		}
+		if val != wantVal || ver != wantVer {
+			t.Errorf("%v: row %v = %v, %v, want %v, %v", name, id, val, ver, wantVal, wantVer)
+		}
+	}
+	ver := func(v int64) gosql.NullInt64 { return gosql.NullInt64{Int64: v, Valid: true} }
+
+	apply(lower, insert(1, "a", int64(5)))
+	check("insert", 1, "a", ver(5))
+	apply(lower, update(1, "older", int64(4)))
+	check("older update", 1, "a", ver(5))
+	apply(lower, insert(1, "older", int64(3)))
+	check("older insert of a duplicate key", 1, "a", ver(5))
+	apply(lower, update(1, "null", nil))
+	check("update to NULL", 1, "a", ver(5))
+	apply(lower, update(1, "newer", int64(6)))
+	check("newer update", 1, "newer", ver(6))
+	apply(lower, insert(1, "newest", int64(7)))
+	check("newer insert of a duplicate key", 1, "newest", ver(7))
+
+	apply(lower, insert(2, "null", nil))
+	apply(lower, update(2, "null again", nil))
+	check("tie of NULL from the lower server", 2, "null", gosql.NullInt64{})
+	apply(greater, update(2, "not null", int64(1)))
+	check("update of NULL", 2, "not null", ver(1))
+
+	// Conflicting updates of the same version from two servers, received in either order,
+	// leave the row of the greater server.
+	apply(lower, insert(3, "lower", int64(1)))
+	apply(greater, update(3, "greater", int64(1)))
+	check("tie, lower first", 3, "greater", ver(1))
+	apply(greater, insert(4, "greater", int64(1)))
+	apply(lower, update(4, "lower", int64(1)))
+	check("tie, greater first", 4, "greater", ver(1))
+
+	// the same updates of different versions
+	apply(lower, insert(5, "lower", int64(2)))
+	apply(greater, update(5, "greater", int64(1)))
+	check("lower newer, lower first", 5, "lower", ver(2))
+	apply(greater, insert(6, "greater", int64(1)))
+	apply(lower, update(6, "lower", int64(2)))
+	check("lower newer, greater first", 6, "lower", ver(2))
+}
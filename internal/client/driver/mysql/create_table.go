@@ -0,0 +1,258 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+	"github.com/actiontech/dtle/utils"
+)
+
+// incompatibleOption is a clause of SHOW CREATE TABLE / DATABASE which servers before minVersion do not support.
+type incompatibleOption struct {
+	name       string
+	minVersion int
+	regex      *regexp.Regexp
+}
+
+var incompatibleOptions = []incompatibleOption{
+	// The default collation of utf8mb4 since 8.0. Without it, an older server uses its own default.
+	{name: "utf8mb4_0900 collations", minVersion: 80000,
+		regex: regexp.MustCompile(`(?i)\s+COLLATE\s*=?\s*utf8mb4_0900_\w+`)},
+	{name: "COMPRESSION", minVersion: 50708,
+		regex: regexp.MustCompile(`(?i)\s+COMPRESSION\s*=\s*'[^']*'`)},
+	{name: "ENCRYPTION", minVersion: 50711,
+		regex: regexp.MustCompile(`(?i)\s+ENCRYPTION\s*=\s*'[^']*'`)},
+}
+
+var (
+	tableEngineRegex  = regexp.MustCompile(`(?i)\bENGINE\s*=\s*\w+`)
+	tableCharsetRegex = regexp.MustCompile(`(?i)\bDEFAULT CHARSET=\w+( COLLATE=\w+)?`)
+
+	// the collations of utf8mb4 since 8.0, e.g. utf8mb4_0900_ai_ci and utf8mb4_zh_0900_as_cs
+	collation0900Regex = regexp.MustCompile(`(?i)\butf8mb4_(\w+_)?0900_(ai_ci|as_ci|as_cs|bin)\b`)
+
+	useSchemaRegex       = regexp.MustCompile("(?i)^\\s*USE\\s+(`(?:[^`]|``)+`|\\S+)")
+	createTableNameRegex = regexp.MustCompile("(?i)^\\s*CREATE TABLE\\s+(?:IF NOT EXISTS\\s+)?(`(?:[^`]|``)+`|[^\\s(]+)")
+	columnCollationRegex = regexp.MustCompile("^\\s+`((?:[^`]|``)+)`\\s.*\\bCOLLATE\\s+(\\w+)")
+	tableCollationRegex  = regexp.MustCompile(`(?i)\bCOLLATE\s*=\s*(\w+)`)
+)
+
+// stripIncompatibleOptions removes the clauses a destination of mysqlVersion does not support from a statement
+// of SHOW CREATE TABLE / DATABASE on the source. It returns the names of the removed clauses.
+func stripIncompatibleOptions(stmt string, mysqlVersion string) (string, []string) {
+	version := utils.MysqlVersionInDigit(mysqlVersion)
+	if version == 0 {
+		// unknown version. keep the statement as it is.
+		return stmt, nil
+	}
+	var stripped []string
+	for _, opt := range incompatibleOptions {
+		if version >= opt.minVersion || !opt.regex.MatchString(stmt) {
+			continue
+		}
+		stmt = opt.regex.ReplaceAllString(stmt, "")
+		stripped = append(stripped, opt.name)
+	}
+	return stmt, stripped
+}
+
+// replaceUnsupportedCollations replaces the collations of utf8mb4 since 8.0 in a statement of SHOW CREATE TABLE /
+// DATABASE with the closest ones a destination of mysqlVersion supports: the accent insensitive or case insensitive
+// ones with utf8mb4_unicode_520_ci, the others with utf8mb4_bin. It returns the replacements as "from to".
+func replaceUnsupportedCollations(stmt string, mysqlVersion string) (string, []string) {
+	version := utils.MysqlVersionInDigit(mysqlVersion)
+	if version == 0 || version >= 80000 {
+		return stmt, nil
+	}
+	var replaced []string
+	seen := make(map[string]bool)
+	stmt = collation0900Regex.ReplaceAllStringFunc(stmt, func(collation string) string {
+		equivalent := "utf8mb4_bin"
+		if strings.HasSuffix(strings.ToLower(collation), "_ci") {
+			equivalent = "utf8mb4_unicode_520_ci"
+		}
+		if replacement := fmt.Sprintf("%v to %v", collation, equivalent); !seen[replacement] {
+			seen[replacement] = true
+			replaced = append(replaced, replacement)
+		}
+		return equivalent
+	})
+	return stmt, replaced
+}
+
+// overrideTableOptions replaces ENGINE (also of partitions) and the default charset (with its collation) of
+// a statement of SHOW CREATE TABLE, if engine / charset is not empty. The charsets and collations of columns are kept.
+func overrideTableOptions(stmt string, engine string, charset string) string {
+	if !isCreateTable(stmt) {
+		return stmt
+	}
+	// The table options are on the line of the closing parenthesis. Partitions (if any) follow on other lines.
+	begin := strings.LastIndex(stmt, "\n) ")
+	if begin < 0 {
+		return stmt
+	}
+	columns, options := stmt[:begin], stmt[begin:]
+	if charset != "" {
+		end := len(options)
+		if i := strings.Index(options[1:], "\n"); i >= 0 {
+			end = i + 1
+		}
+		options = tableCharsetRegex.ReplaceAllString(options[:end], "DEFAULT CHARSET="+charset) + options[end:]
+	}
+	if engine != "" {
+		options = tableEngineRegex.ReplaceAllString(options, "ENGINE="+engine)
+	}
+	return columns + options
+}
+
+func isCreateTable(stmt string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(stmt)), "CREATE TABLE")
+}
+
+// intendedCreateStatement returns stmt with the table options and the collations configured for the destination,
+// but with the clauses it does not support. See adaptCreateStatements.
+func (a *Applier) intendedCreateStatement(stmt string) (string, []string) {
+	stmt = overrideTableOptions(stmt, a.mysqlContext.CreateTableEngine, a.mysqlContext.CreateTableCharset)
+	if a.mysqlContext.CollationMismatch != config.CollationMismatchEquivalent {
+		return stmt, nil
+	}
+	return replaceUnsupportedCollations(stmt, a.mysqlContext.MySQLVersion)
+}
+
+// adaptCreateStatements adapts the statements of a full copy which create schemas and tables to the destination.
+// See overrideTableOptions, replaceUnsupportedCollations, stripIncompatibleOptions and DdlRewrite.
+func (a *Applier) adaptCreateStatements(stmts []string) []string {
+	result := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		stmt, replaced := a.intendedCreateStatement(stmt)
+		if len(replaced) > 0 {
+			a.logger.Warnf("mysql.applier: replaced collations unsupported by the destination (version %v): %v",
+				a.mysqlContext.MySQLVersion, strings.Join(replaced, ", "))
+		}
+		stmt, stripped := stripIncompatibleOptions(stmt, a.mysqlContext.MySQLVersion)
+		if len(stripped) > 0 {
+			a.logger.Warnf("mysql.applier: stripped %v unsupported by the destination (version %v) from: %v",
+				strings.Join(stripped, ", "), a.mysqlContext.MySQLVersion, stmt)
+		}
+		result[i] = a.rewriteDdl(stmt)
+	}
+	return result
+}
+
+// unquoteCreateName returns a name as in the statements of SHOW CREATE TABLE, quoted or not.
+func unquoteCreateName(name string) string {
+	if len(name) >= 2 && strings.HasPrefix(name, "`") && strings.HasSuffix(name, "`") {
+		return strings.Replace(name[1:len(name)-1], "``", "`", -1)
+	}
+	return name
+}
+
+// columnCollation is the collation of a column, see requestedCollations.
+type columnCollation struct {
+	column    string
+	collation string
+}
+
+// requestedCollations returns the collations given in a statement of SHOW CREATE TABLE: of the table, or "" if
+// it is not given, and of the columns with a COLLATE clause, in order.
+func requestedCollations(stmt string) (table string, columns []columnCollation) {
+	begin := strings.LastIndex(stmt, "\n) ")
+	if begin < 0 {
+		return "", nil
+	}
+	for _, line := range strings.Split(stmt[:begin], "\n") {
+		if m := columnCollationRegex.FindStringSubmatch(line); m != nil {
+			columns = append(columns, columnCollation{column: strings.Replace(m[1], "``", "`", -1), collation: m[2]})
+		}
+	}
+	options := stmt[begin+1:]
+	if i := strings.Index(options, "\n"); i >= 0 {
+		options = options[:i]
+	}
+	if m := tableCollationRegex.FindStringSubmatch(options); m != nil {
+		table = m[1]
+	}
+	return table, columns
+}
+
+// collationMismatches reads back the collations of a table created by stmt, and returns how they differ from
+// those requested by stmt.
+func collationMismatches(tx applyTx, schemaName string, tableName string, stmt string) ([]string, error) {
+	requestedTable, requestedColumns := requestedCollations(stmt)
+	var mismatches []string
+	if requestedTable != "" {
+		var collation gosql.NullString
+		err := tx.QueryRowContext(context.Background(), "select TABLE_COLLATION from information_schema.TABLES"+
+			" where TABLE_SCHEMA = ? and TABLE_NAME = ?", schemaName, tableName).Scan(&collation)
+		if err == gosql.ErrNoRows {
+			// not created, e.g. filtered out by DdlRewrite
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(collation.String, requestedTable) {
+			mismatches = append(mismatches, fmt.Sprintf("the table has %v instead of %v", collation.String, requestedTable))
+		}
+	}
+	for _, requested := range requestedColumns {
+		var collation gosql.NullString
+		err := tx.QueryRowContext(context.Background(), "select COLLATION_NAME from information_schema.COLUMNS"+
+			" where TABLE_SCHEMA = ? and TABLE_NAME = ? and COLUMN_NAME = ?",
+			schemaName, tableName, requested.column).Scan(&collation)
+		if err == gosql.ErrNoRows {
+			// an existing table without the column
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(collation.String, requested.collation) {
+			mismatches = append(mismatches, fmt.Sprintf("column %v has %v instead of %v",
+				requested.column, collation.String, requested.collation))
+		}
+	}
+	return mismatches, nil
+}
+
+// checkCreatedCollations checks the collations of the tables created by stmts, the statements of a full copy.
+// A mismatch fails the copy with CollationMismatchError, and is logged and recorded otherwise.
+func (a *Applier) checkCreatedCollations(tx applyTx, stmts []string) error {
+	schemaName := ""
+	for _, stmt := range stmts {
+		if m := useSchemaRegex.FindStringSubmatch(stmt); m != nil {
+			schemaName = unquoteCreateName(m[1])
+			continue
+		}
+		m := createTableNameRegex.FindStringSubmatch(stmt)
+		if m == nil || schemaName == "" {
+			continue
+		}
+		tableName := unquoteCreateName(m[1])
+		intended, _ := a.intendedCreateStatement(stmt)
+		mismatches, err := collationMismatches(tx, schemaName, tableName, a.rewriteDdl(intended))
+		if err != nil {
+			return err
+		}
+		if len(mismatches) == 0 {
+			continue
+		}
+		err = fmt.Errorf("collations of %v.%v differ from the source: %v", schemaName, tableName,
+			strings.Join(mismatches, "; "))
+		if a.mysqlContext.CollationMismatch == config.CollationMismatchError {
+			return err
+		}
+		a.logger.Warnf("mysql.applier: %v", err)
+		a.recordError(models.ErrorTypeSchema, "", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+const testCreateTable80 = "CREATE TABLE `t1` (\n" +
+	"  `id` int NOT NULL,\n" +
+	"  `c` varchar(10) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMPRESSION='zlib'"
+
+func Test_stripIncompatibleOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		stmt         string
+		version      string
+		want         string
+		wantStripped []string
+	}{
+		{
+			name:    "supported",
+			stmt:    testCreateTable80,
+			version: "8.0.16",
+			want:    testCreateTable80,
+		},
+		{
+			name:    "5.7",
+			stmt:    testCreateTable80,
+			version: "5.7.25-log",
+			want: "CREATE TABLE `t1` (\n" +
+				"  `id` int NOT NULL,\n" +
+				"  `c` varchar(10) CHARACTER SET utf8mb4 DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMPRESSION='zlib'",
+			wantStripped: []string{"utf8mb4_0900 collations"},
+		},
+		{
+			name:    "5.6",
+			stmt:    testCreateTable80,
+			version: "5.6.40",
+			want: "CREATE TABLE `t1` (\n" +
+				"  `id` int NOT NULL,\n" +
+				"  `c` varchar(10) CHARACTER SET utf8mb4 DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+			wantStripped: []string{"utf8mb4_0900 collations", "COMPRESSION"},
+		},
+		{
+			name:         "schema",
+			stmt:         "CREATE DATABASE IF NOT EXISTS `db1` /*!40100 DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci */",
+			version:      "5.7.25",
+			want:         "CREATE DATABASE IF NOT EXISTS `db1` /*!40100 DEFAULT CHARACTER SET utf8mb4 */",
+			wantStripped: []string{"utf8mb4_0900 collations"},
+		},
+		{
+			name:    "unknown version",
+			stmt:    testCreateTable80,
+			version: "",
+			want:    testCreateTable80,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, stripped := stripIncompatibleOptions(tt.stmt, tt.version)
+			if got != tt.want {
+				t.Errorf("stripIncompatibleOptions() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(stripped, tt.wantStripped) {
+				t.Errorf("stripIncompatibleOptions() stripped = %v, want %v", stripped, tt.wantStripped)
+			}
+		})
+	}
+}
+
+func Test_overrideTableOptions(t *testing.T) {
+	partitioned := "CREATE TABLE `t2` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `c` varchar(10) CHARACTER SET latin1 DEFAULT NULL COMMENT 'ENGINE=x DEFAULT CHARSET=y',\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=gbk COLLATE=gbk_bin ROW_FORMAT=COMPACT\n" +
+		"/*!50100 PARTITION BY HASH (`id`)\n" +
+		"(PARTITION p0 ENGINE = InnoDB,\n" +
+		" PARTITION p1 ENGINE = InnoDB) */"
+	tests := []struct {
+		name    string
+		stmt    string
+		engine  string
+		charset string
+		want    string
+	}{
+		{
+			name: "no override",
+			stmt: partitioned,
+			want: partitioned,
+		},
+		{
+			name:    "engine and charset",
+			stmt:    partitioned,
+			engine:  "MyISAM",
+			charset: "utf8mb4",
+			want: "CREATE TABLE `t2` (\n" +
+				"  `id` int(11) NOT NULL,\n" +
+				"  `c` varchar(10) CHARACTER SET latin1 DEFAULT NULL COMMENT 'ENGINE=x DEFAULT CHARSET=y',\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=MyISAM DEFAULT CHARSET=utf8mb4 ROW_FORMAT=COMPACT\n" +
+				"/*!50100 PARTITION BY HASH (`id`)\n" +
+				"(PARTITION p0 ENGINE=MyISAM,\n" +
+				" PARTITION p1 ENGINE=MyISAM) */",
+		},
+		{
+			name:    "not a table",
+			stmt:    "USE `db1`",
+			engine:  "MyISAM",
+			charset: "utf8mb4",
+			want:    "USE `db1`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overrideTableOptions(tt.stmt, tt.engine, tt.charset); got != tt.want {
+				t.Errorf("overrideTableOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplier_adaptCreateStatements_roundTrip(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	var version string
+	if err := db.QueryRow("select @@global.version").Scan(&version); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+
+	_, err = db.Exec("drop database if exists dtle_test_charset;" +
+		"create database dtle_test_charset character set latin1 collate latin1_swedish_ci;" +
+		"create table dtle_test_charset.t1 (" +
+		"  id int primary key," +
+		"  title varchar(100) character set utf8mb4 collate utf8mb4_bin," +
+		"  body text," +
+		"  fulltext key ft_body (title, body)" +
+		") engine=InnoDB default charset=gbk collate=gbk_bin row_format=dynamic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec("drop database if exists dtle_test_charset")
+
+	schemaSQL, err := base.ShowCreateSchema(db, "dtle_test_charset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbSQL, err := base.ShowCreateTable(db, "dtle_test_charset", "t1", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("drop database dtle_test_charset"); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Applier{
+		logger:       logrus.NewEntry(logrus.New()),
+		mysqlContext: &config.MySQLDriverConfig{MySQLVersion: version},
+	}
+	for _, stmt := range a.adaptCreateStatements(append([]string{schemaSQL}, tbSQL...)) {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %v: %v", stmt, err)
+		}
+	}
+
+	gotSchemaSQL, err := base.ShowCreateSchema(db, "dtle_test_charset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSchemaSQL != schemaSQL {
+		t.Errorf("schema = %v, want %v", gotSchemaSQL, schemaSQL)
+	}
+	gotTbSQL, err := base.ShowCreateTable(db, "dtle_test_charset", "t1", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotTbSQL, tbSQL) {
+		t.Errorf("table = %v, want %v", gotTbSQL, tbSQL)
+	}
+}
+
+func Test_replaceUnsupportedCollations(t *testing.T) {
+	tests := []struct {
+		name         string
+		stmt         string
+		mysqlVersion string
+		want         string
+		wantReplaced []string
+	}{
+		{
+			name:         "5.7",
+			stmt:         testCreateTable80,
+			mysqlVersion: "5.7.30-log",
+			want: "CREATE TABLE `t1` (\n" +
+				"  `id` int NOT NULL,\n" +
+				"  `c` varchar(10) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_520_ci DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_520_ci COMPRESSION='zlib'",
+			wantReplaced: []string{"utf8mb4_0900_ai_ci to utf8mb4_unicode_520_ci"},
+		},
+		{
+			name: "case sensitive",
+			stmt: "CREATE TABLE `t1` (\n" +
+				"  `c` varchar(10) COLLATE utf8mb4_ja_0900_as_cs DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_bin",
+			mysqlVersion: "5.6.40",
+			want: "CREATE TABLE `t1` (\n" +
+				"  `c` varchar(10) COLLATE utf8mb4_bin DEFAULT NULL\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+			wantReplaced: []string{"utf8mb4_ja_0900_as_cs to utf8mb4_bin", "utf8mb4_0900_bin to utf8mb4_bin"},
+		},
+		{
+			name:         "8.0",
+			stmt:         testCreateTable80,
+			mysqlVersion: "8.0.19",
+			want:         testCreateTable80,
+		},
+		{
+			name:         "unknown version",
+			stmt:         testCreateTable80,
+			mysqlVersion: "",
+			want:         testCreateTable80,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotReplaced := replaceUnsupportedCollations(tt.stmt, tt.mysqlVersion)
+			if got != tt.want {
+				t.Errorf("replaceUnsupportedCollations() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotReplaced, tt.wantReplaced) {
+				t.Errorf("replaceUnsupportedCollations() gotReplaced = %v, want %v", gotReplaced, tt.wantReplaced)
+			}
+		})
+	}
+}
+
+func Test_requestedCollations(t *testing.T) {
+	gotTable, gotColumns := requestedCollations(testCreateTable80)
+	if gotTable != "utf8mb4_0900_ai_ci" {
+		t.Errorf("requestedCollations() table = %v, want utf8mb4_0900_ai_ci", gotTable)
+	}
+	wantColumns := []columnCollation{{column: "c", collation: "utf8mb4_0900_ai_ci"}}
+	if !reflect.DeepEqual(gotColumns, wantColumns) {
+		t.Errorf("requestedCollations() columns = %v, want %v", gotColumns, wantColumns)
+	}
+
+	gotTable, gotColumns = requestedCollations("CREATE TABLE `t``2` (\n" +
+		"  `a``b` char(1) COLLATE latin1_bin DEFAULT NULL COMMENT 'COLLATE=x'\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=latin1")
+	if gotTable != "" {
+		t.Errorf("requestedCollations() table = %v, want none", gotTable)
+	}
+	wantColumns = []columnCollation{{column: "a`b", collation: "latin1_bin"}}
+	if !reflect.DeepEqual(gotColumns, wantColumns) {
+		t.Errorf("requestedCollations() columns = %v, want %v", gotColumns, wantColumns)
+	}
+}
+
+func TestApplier_checkCreatedCollations(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Exec("drop database if exists dtle_test_collation")
+
+	// the source is 8.0 while the destination is taken as 5.7, which does not support utf8mb4_0900_ai_ci
+	stmts := []string{"USE `dtle_test_collation`", testCreateTable80[:len(testCreateTable80)-len(" COMPRESSION='zlib'")]}
+	tests := []struct {
+		name              string
+		collationMismatch string
+		// create the table as the destination would, unaware of the collations of the source
+		downgrade bool
+		wantErr   bool
+	}{
+		{name: "equivalent", collationMismatch: config.CollationMismatchEquivalent},
+		{name: "downgraded", collationMismatch: config.CollationMismatchError, downgrade: true, wantErr: true},
+		{name: "downgraded warn", collationMismatch: config.CollationMismatchWarn, downgrade: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Applier{
+				logger: logrus.NewEntry(logrus.New()),
+				mysqlContext: &config.MySQLDriverConfig{
+					MySQLVersion:      "5.7.30",
+					CollationMismatch: tt.collationMismatch,
+				},
+			}
+			_, err := db.Exec("drop database if exists dtle_test_collation; create database dtle_test_collation")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, stmt := range a.adaptCreateStatements(stmts) {
+				if tt.downgrade {
+					stmt, _ = replaceUnsupportedCollations(stmt, a.mysqlContext.MySQLVersion)
+				}
+				if _, err := db.Exec(stmt); err != nil {
+					t.Fatalf("exec %v: %v", stmt, err)
+				}
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+			if err := a.checkCreatedCollations(tx, stmts); (err != nil) != tt.wantErr {
+				t.Errorf("checkCreatedCollations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
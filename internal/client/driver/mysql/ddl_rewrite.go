@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+type ddlRewriteRule struct {
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// ddlRewriter applies config.MySQLDriverConfig.DdlRewrite. A nil ddlRewriter keeps the statements as they are.
+type ddlRewriter []ddlRewriteRule
+
+func newDdlRewriter(rules []*config.DdlRewriteRule) (ddlRewriter, error) {
+	var r ddlRewriter
+	for i, rule := range rules {
+		if rule == nil || rule.Pattern == "" {
+			return nil, fmt.Errorf("bad job argument: DdlRewrite[%v]: Pattern is empty", i)
+		}
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad job argument: DdlRewrite[%v].Pattern=%v: %v", i, rule.Pattern, err)
+		}
+		r = append(r, ddlRewriteRule{regex: regex, replacement: rule.Replacement})
+	}
+	return r, nil
+}
+
+func (r ddlRewriter) rewrite(stmt string) string {
+	for _, rule := range r {
+		stmt = rule.regex.ReplaceAllString(stmt, rule.replacement)
+	}
+	return stmt
+}
+
+// rewriteDdl rewrites a DDL statement to be executed on the destination by DdlRewrite.
+func (a *Applier) rewriteDdl(stmt string) string {
+	rewritten := a.ddlRewriter.rewrite(stmt)
+	if rewritten != stmt {
+		a.logger.Infof("mysql.applier: DdlRewrite: %v => %v", stmt, rewritten)
+	}
+	return rewritten
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+var testDdlRewriteInnoDB = []*config.DdlRewriteRule{
+	{Pattern: `(?i)\bENGINE\s*=\s*MyISAM\b`, Replacement: "ENGINE=InnoDB"},
+	{Pattern: `(?i)\s+ROW_FORMAT\s*=\s*FIXED\b`, Replacement: ""},
+}
+
+func Test_ddlRewriter(t *testing.T) {
+	r, err := newDdlRewriter(testDdlRewriteInnoDB)
+	if err != nil {
+		t.Fatalf("newDdlRewriter() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		stmt string
+		want string
+	}{
+		{"create table", "CREATE TABLE `t` (`id` int) ENGINE = MyISAM ROW_FORMAT=FIXED",
+			"CREATE TABLE `t` (`id` int) ENGINE=InnoDB"},
+		{"alter table", "alter table t engine=myisam", "alter table t ENGINE=InnoDB"},
+		{"no match", "CREATE TABLE `t` (`id` int) ENGINE=MyISAMx", "CREATE TABLE `t` (`id` int) ENGINE=MyISAMx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.rewrite(tt.stmt); got != tt.want {
+				t.Errorf("rewrite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var none ddlRewriter
+	if got := none.rewrite("alter table t engine=myisam"); got != "alter table t engine=myisam" {
+		t.Errorf("rewrite() without rules = %v", got)
+	}
+	groups, err := newDdlRewriter([]*config.DdlRewriteRule{{Pattern: `(?i)\bCHARSET=(\w+)`, Replacement: "CHARSET=${1}_x"}})
+	if err != nil {
+		t.Fatalf("newDdlRewriter() error = %v", err)
+	}
+	if got := groups.rewrite("create table t (id int) CHARSET=gbk"); got != "create table t (id int) CHARSET=gbk_x" {
+		t.Errorf("rewrite() with a group = %v", got)
+	}
+	if _, err := newDdlRewriter([]*config.DdlRewriteRule{{Pattern: ""}}); err == nil {
+		t.Errorf("newDdlRewriter() with an empty Pattern: want an error")
+	}
+}
+
+// A CREATE TABLE ... ENGINE=MyISAM of both the full copy and the incremental copy is applied as InnoDB.
+func TestApplier_ddlRewrite(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		DdlRewrite: testDdlRewriteInnoDB,
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	if _, err := a.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_ddl_full, dtle_test.t_ddl_incr"); err != nil {
+		t.Fatalf("prepare error = %v", err)
+	}
+	engine := func(table string) string {
+		var engine string
+		if err := a.db.QueryRow("select engine from information_schema.tables where table_schema = 'dtle_test'"+
+			" and table_name = ?", table).Scan(&engine); err != nil {
+			t.Fatalf("select engine of %v error = %v", table, err)
+		}
+		return engine
+	}
+
+	// the full copy
+	entry := &DumpEntry{
+		TbSQL: []string{"USE `dtle_test`", "CREATE TABLE `t_ddl_full` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n" +
+			") ENGINE=MyISAM DEFAULT CHARSET=utf8mb4 ROW_FORMAT=FIXED"},
+	}
+	if err := a.ApplyEventQueries(a.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+	if got := engine("t_ddl_full"); !strings.EqualFold(got, "InnoDB") {
+		t.Errorf("engine of the full copy = %v, want InnoDB", got)
+	}
+
+	// the incremental copy
+	binlogEntry := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: 1},
+		Events: []binlog.DataEvent{{
+			DML:           binlog.NotDML,
+			CurrentSchema: "dtle_test",
+			DatabaseName:  "dtle_test",
+			TableName:     "t_ddl_incr",
+			Query:         "create table t_ddl_incr (id int primary key) engine=MyISAM row_format=fixed",
+		}},
+	}
+	if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], binlogEntry); err != nil {
+		t.Fatalf("ApplyBinlogEvent() error = %v", err)
+	}
+	if err := a.commitApplyBatch(0, a.applyBatches[0]); err != nil {
+		t.Fatalf("commitApplyBatch() error = %v", err)
+	}
+	if got := engine("t_ddl_incr"); !strings.EqualFold(got, "InnoDB") {
+		t.Errorf("engine of the incremental copy = %v, want InnoDB", got)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/actiontech/dtle/internal/g"
+)
+
+// DeadLetterRow is a row which failed to apply, recorded with OnApplyError=deadletter.
+// Gtid, Schema and Table identify the source transaction and table, so that the row can be reprocessed.
+type DeadLetterRow struct {
+	JobID  string `json:"job_id"`
+	Gtid   string `json:"gtid"`
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	DML    string `json:"dml"`
+	// column values of the row before the change (update and delete)
+	Where []interface{} `json:"where,omitempty"`
+	// column values of the row after the change (insert and update)
+	Values []interface{} `json:"values,omitempty"`
+	Error  string        `json:"error"`
+	Time   time.Time     `json:"time"`
+}
+
+func newDeadLetterRow(jobID string, binlogEntry *binlog.BinlogEntry, event *binlog.DataEvent, err error) *DeadLetterRow {
+	return &DeadLetterRow{
+		JobID:  jobID,
+		Gtid:   fmt.Sprintf("%s:%d", binlogEntry.Coordinates.GetSid(), binlogEntry.Coordinates.GNO),
+		Schema: event.DatabaseName,
+		Table:  event.TableName,
+		DML:    string(event.DML),
+		Where:  deadLetterValues(event.WhereColumnValues),
+		Values: deadLetterValues(event.NewColumnValues),
+		Error:  err.Error(),
+		Time:   time.Now(),
+	}
+}
+
+func deadLetterValues(values *umconf.ColumnValues) []interface{} {
+	if values == nil {
+		return nil
+	}
+	result := make([]interface{}, len(values.AbstractValues))
+	for i, v := range values.AbstractValues {
+		if v != nil {
+			result[i] = *v
+		}
+	}
+	return result
+}
+
+// deadLetter records rows to a table on the destination or to a local file.
+type deadLetter struct {
+	// schema and table on the destination, if set
+	schema string
+	table  string
+
+	fileName string
+	fileLock sync.Mutex
+	file     *os.File
+}
+
+func newDeadLetter(cfg *config.MySQLDriverConfig, stateDir string, subject string) *deadLetter {
+	d := &deadLetter{}
+	if cfg.DeadLetterTable != "" {
+		d.schema, d.table = g.DtleSchemaName, cfg.DeadLetterTable
+		if i := strings.Index(cfg.DeadLetterTable, "."); i >= 0 {
+			d.schema, d.table = cfg.DeadLetterTable[:i], cfg.DeadLetterTable[i+1:]
+		}
+	} else if cfg.DeadLetterFile != "" {
+		d.fileName = cfg.DeadLetterFile
+	} else {
+		d.fileName = path.Join(stateDir, "deadletter", subject+".jsonl")
+	}
+	return d
+}
+
+func (d *deadLetter) String() string {
+	if d.table != "" {
+		return fmt.Sprintf("table %v.%v", d.schema, d.table)
+	}
+	return fmt.Sprintf("file %v", d.fileName)
+}
+
+// open creates the table or opens the file for appending.
+func (d *deadLetter) open(db *gosql.DB) (err error) {
+	if d.table != "" {
+		if _, err := db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %v", umconf.EscapeName(d.schema))); err != nil {
+			return err
+		}
+		_, err = db.Exec(fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %v.%v (
+				id bigint unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+				job_id varchar(64) NOT NULL,
+				gtid varchar(64) NOT NULL COMMENT 'source transaction of the row',
+				table_schema varchar(64) NOT NULL,
+				table_name varchar(64) NOT NULL,
+				dml varchar(16) NOT NULL,
+				row_where longtext COMMENT 'json array of column values before the change',
+				row_values longtext COMMENT 'json array of column values after the change',
+				error text NOT NULL,
+				created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`, umconf.EscapeName(d.schema), umconf.EscapeName(d.table)))
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(d.fileName), 0755); err != nil {
+		return err
+	}
+	d.file, err = os.OpenFile(d.fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	return err
+}
+
+// write records the row. A row recorded to the table is committed with the transaction of tx.
+func (d *deadLetter) write(tx applyTx, row *DeadLetterRow) error {
+	if d.table != "" {
+		where, err := json.Marshal(row.Where)
+		if err != nil {
+			return err
+		}
+		values, err := json.Marshal(row.Values)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO %v.%v (job_id, gtid, table_schema, table_name, dml, row_where, row_values, error)"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?)", umconf.EscapeName(d.schema), umconf.EscapeName(d.table)),
+			row.JobID, row.Gtid, row.Schema, row.Table, row.DML, string(where), string(values), row.Error)
+		return err
+	}
+
+	bs, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	d.fileLock.Lock()
+	defer d.fileLock.Unlock()
+	_, err = d.file.Write(append(bs, '\n'))
+	return err
+}
+
+func (d *deadLetter) Close() error {
+	if d == nil || d.file == nil {
+		return nil
+	}
+	d.fileLock.Lock()
+	defer d.fileLock.Unlock()
+	return d.file.Close()
+}
+
+// initApplyErrorPolicy validates OnApplyError and prepares the dead letter, if used.
+func (a *Applier) initApplyErrorPolicy() error {
+	switch a.mysqlContext.OnApplyError {
+	case config.OnApplyErrorHalt, config.OnApplyErrorSkip:
+		a.logger.Infof("mysql.applier: OnApplyError: %v", a.mysqlContext.OnApplyError)
+		return nil
+	case config.OnApplyErrorDeadLetter:
+		a.deadLetter = newDeadLetter(a.mysqlContext, a.execCtx.StateDir, a.subject)
+		a.logger.Infof("mysql.applier: OnApplyError: %v. writing to %v", a.mysqlContext.OnApplyError, a.deadLetter)
+		return a.deadLetter.open(a.db)
+	default:
+		return fmt.Errorf("bad job argument: OnApplyError=%v. should be one of %v, %v, %v",
+			a.mysqlContext.OnApplyError,
+			config.OnApplyErrorHalt, config.OnApplyErrorSkip, config.OnApplyErrorDeadLetter)
+	}
+}
+
+// handleApplyError applies OnApplyError to the rows of events which failed to apply with applyErr.
+// It returns nil if the transaction should continue without the rows.
+func (a *Applier) handleApplyError(tx applyTx, binlogEntry *binlog.BinlogEntry, events []binlog.DataEvent, applyErr error) error {
+	if a.mysqlContext.OnApplyError == config.OnApplyErrorHalt || !sql.IsRowError(applyErr) {
+		return applyErr
+	}
+	for i := range events {
+		row := newDeadLetterRow(a.subject, binlogEntry, &events[i], applyErr)
+		if a.deadLetter == nil {
+			a.logger.Warnf("mysql.applier: OnApplyError=skip. skipping a row. gtid: %v, table: %v.%v, dml: %v, error: %v",
+				row.Gtid, row.Schema, row.Table, row.DML, row.Error)
+			continue
+		}
+		if err := a.deadLetter.write(tx, row); err != nil {
+			return fmt.Errorf("failed to write dead letter to %v: %v. apply error: %v", a.deadLetter, err, applyErr)
+		}
+		a.logger.Warnf("mysql.applier: OnApplyError=deadletter. dead-lettered a row. gtid: %v, table: %v.%v, dml: %v, error: %v",
+			row.Gtid, row.Schema, row.Table, row.DML, row.Error)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+)
+
+// the number of transactions a delayQueue keeps in memory, and spills to a file at a time.
+var delayQueueMemLimit = 4096
+
+// delayQueue holds the transactions of the incremental copy received by the applier until they are
+// ReplicationDelay old by their binlog timestamps, in the order received. Up to delayQueueMemLimit of them are
+// in memory at the head of the queue. The later ones are spilled to files, delayQueueMemLimit transactions each,
+// which are read back in order as the head empties. The files are not kept across restarts: the transactions
+// not applied yet are resent by the extractor from the checkpoint on.
+type delayQueue struct {
+	delay time.Duration
+	dir   string
+
+	lock sync.Mutex
+	// the transactions to be popped first
+	head []*binlog.BinlogEntry
+	// spilled, after head
+	files []string
+	// received after files, to be spilled when full
+	tail    []*binlog.BinlogEntry
+	fileSeq int
+	// signaled on push
+	pushed chan struct{}
+	// the clock, replaced in tests
+	now func() time.Time
+}
+
+func newDelayQueue(delay time.Duration, dir string) (*delayQueue, error) {
+	// spilled by a previous run
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &delayQueue{
+		delay:  delay,
+		dir:    dir,
+		pushed: make(chan struct{}, 1),
+		now:    time.Now,
+	}, nil
+}
+
+// push appends a transaction to the queue.
+func (q *delayQueue) push(entry *binlog.BinlogEntry) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.files) == 0 && len(q.tail) == 0 && len(q.head) < delayQueueMemLimit {
+		q.head = append(q.head, entry)
+	} else {
+		q.tail = append(q.tail, entry)
+		if len(q.tail) >= delayQueueMemLimit {
+			if err := q.spill(); err != nil {
+				return err
+			}
+		}
+	}
+	select {
+	case q.pushed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// spill writes tail to a file.
+func (q *delayQueue) spill() error {
+	for _, entry := range q.tail {
+		// not to be encoded. It is for tracing only.
+		entry.SpanContext = nil
+	}
+	data, err := Encode(&binlog.BinlogEntries{Entries: q.tail})
+	if err != nil {
+		return err
+	}
+	q.fileSeq++
+	name := path.Join(q.dir, fmt.Sprintf("%08d", q.fileSeq))
+	if err := ioutil.WriteFile(name, data, 0644); err != nil {
+		return fmt.Errorf("ReplicationDelay: spilling transactions: %v", err)
+	}
+	q.files = append(q.files, name)
+	q.tail = nil
+	return nil
+}
+
+// peek returns the first transaction, and how long it is to be held yet. It is nil if the queue is empty.
+func (q *delayQueue) peek() (entry *binlog.BinlogEntry, wait time.Duration, err error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.head) == 0 {
+		if len(q.files) > 0 {
+			data, err := ioutil.ReadFile(q.files[0])
+			if err != nil {
+				return nil, 0, fmt.Errorf("ReplicationDelay: reading spilled transactions: %v", err)
+			}
+			var entries binlog.BinlogEntries
+			if err := Decode(data, &entries); err != nil {
+				return nil, 0, err
+			}
+			if err := os.Remove(q.files[0]); err != nil {
+				return nil, 0, err
+			}
+			q.head, q.files = entries.Entries, q.files[1:]
+		} else {
+			q.head, q.tail = q.tail, nil
+		}
+	}
+	if len(q.head) == 0 {
+		return nil, 0, nil
+	}
+	entry = q.head[0]
+	// e.g. a part of a resync, which has no timestamp, follows the transactions before it.
+	if entry.Coordinates.Timestamp == 0 {
+		return entry, 0, nil
+	}
+	return entry, time.Unix(entry.Coordinates.Timestamp, 0).Add(q.delay).Sub(q.now()), nil
+}
+
+// pop returns the first transaction when it has been held for the delay. It returns nil on shutdown.
+func (q *delayQueue) pop(shutdownCh chan struct{}) (*binlog.BinlogEntry, error) {
+	for {
+		entry, wait, err := q.peek()
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && wait <= 0 {
+			q.lock.Lock()
+			q.head = q.head[1:]
+			q.lock.Unlock()
+			return entry, nil
+		}
+		if entry != nil {
+			// The transactions pushed meanwhile are due later.
+			timer := time.NewTimer(wait)
+			select {
+			case <-shutdownCh:
+				timer.Stop()
+				return nil, nil
+			case <-timer.C:
+			}
+		} else {
+			select {
+			case <-shutdownCh:
+				return nil, nil
+			case <-q.pushed:
+			}
+		}
+	}
+}
+
+// len returns the number of transactions held.
+func (q *delayQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.head) + len(q.files)*delayQueueMemLimit + len(q.tail)
+}
+
+// close removes the spilled files.
+func (q *delayQueue) close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.head, q.files, q.tail = nil, nil, nil
+	return os.RemoveAll(q.dir)
+}
+
+// releaseDelayed moves the transactions held for ReplicationDelay to applyDataEntryQueue when they are due.
+func (a *Applier) releaseDelayed() {
+	for {
+		binlogEntry, err := a.delayQueue.pop(a.shutdownCh)
+		if err != nil {
+			a.logger.Errorf("mysql.applier: %v. restarting", err)
+			a.onError(TaskStateRestart, err)
+			return
+		}
+		if binlogEntry == nil {
+			return // shutdown
+		}
+		select {
+		case <-a.shutdownCh:
+			return
+		case a.applyDataEntryQueue <- binlogEntry:
+		}
+	}
+}
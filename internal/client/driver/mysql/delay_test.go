@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+)
+
+func newDelayTestEntry(gno int64, timestamp int64) *binlog.BinlogEntry {
+	return &binlog.BinlogEntry{Coordinates: base.BinlogCoordinateTx{GNO: gno, Timestamp: timestamp}}
+}
+
+func TestDelayQueue_pop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	q, err := newDelayQueue(2*time.Second, path.Join(dir, "job1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.close()
+
+	now := time.Now()
+	// committed 3s ago, at the start of the current second, and 1s later
+	timestamps := []int64{now.Unix() - 3, now.Unix(), now.Unix() + 1}
+	for i, ts := range timestamps {
+		if err := q.push(newDelayTestEntry(int64(i+1), ts)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shutdownCh := make(chan struct{})
+	for i, ts := range timestamps {
+		entry, err := q.pop(shutdownCh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Coordinates.GNO != int64(i+1) {
+			t.Fatalf("pop() = gno %v, want %v", entry.Coordinates.GNO, i+1)
+		}
+		due := time.Unix(ts, 0).Add(2 * time.Second)
+		if released := time.Now(); released.Before(due) {
+			t.Errorf("gno %v is released at %v, before %v", i+1, released, due)
+		}
+	}
+	if elapsed := time.Since(now); elapsed > 4*time.Second {
+		t.Errorf("held for %v", elapsed)
+	}
+
+	close(shutdownCh)
+	if entry, err := q.pop(shutdownCh); entry != nil || err != nil {
+		t.Errorf("pop() on shutdown = %v, %v", entry, err)
+	}
+}
+
+func TestDelayQueue_spill(t *testing.T) {
+	defer func(limit int) { delayQueueMemLimit = limit }(delayQueueMemLimit)
+	delayQueueMemLimit = 3
+
+	dir, err := ioutil.TempDir("", "delay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	q, err := newDelayQueue(time.Hour, path.Join(dir, "job1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000000, 0)
+	q.now = func() time.Time { return now }
+
+	for gno := int64(1); gno <= 11; gno++ {
+		if err := q.push(newDelayTestEntry(gno, now.Unix())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 3 in memory, 2 files of 3, and 2 to be spilled
+	files, _ := ioutil.ReadDir(q.dir)
+	if len(q.head) != 3 || len(files) != 2 || len(q.tail) != 2 || q.len() != 11 {
+		t.Fatalf("head %v, files %v, tail %v, len %v", len(q.head), len(files), len(q.tail), q.len())
+	}
+
+	if entry, wait, err := q.peek(); err != nil || entry.Coordinates.GNO != 1 || wait != time.Hour {
+		t.Fatalf("peek() = %v, %v, %v", entry, wait, err)
+	}
+	now = now.Add(time.Hour)
+	shutdownCh := make(chan struct{})
+	for gno := int64(1); gno <= 11; gno++ {
+		entry, err := q.pop(shutdownCh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Coordinates.GNO != gno {
+			t.Fatalf("pop() = gno %v, want %v", entry.Coordinates.GNO, gno)
+		}
+	}
+	if files, _ := ioutil.ReadDir(q.dir); len(files) != 0 || q.len() != 0 {
+		t.Errorf("files %v, len %v after all are popped", len(files), q.len())
+	}
+
+	if err := q.close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(q.dir); !os.IsNotExist(err) {
+		t.Errorf("%v is not removed: %v", q.dir, err)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/actiontech/dtle/internal/g"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+const (
+	// the interval of checking whether a destination out of space can be written to again
+	diskRecoveryCheckInterval = 30 * time.Second
+	// the table in the dtle schema written to by probeDiskSpace, a row per job
+	diskProbeTable = "disk_probe"
+)
+
+// diskFullPause holds the disk-full error the applier is paused on, if any.
+type diskFullPause struct {
+	lock sync.Mutex
+	err  error
+}
+
+// pause returns false if it is paused already.
+func (p *diskFullPause) pause(err error) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.err != nil {
+		return false
+	}
+	p.err = err
+	return true
+}
+
+func (p *diskFullPause) resume() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.err = nil
+}
+
+func (p *diskFullPause) get() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err
+}
+
+// pauseOnDiskFull pauses the applier on an error of the destination being out of space, instead of failing the
+// task, which would be restarted and fail again until space is freed. Nothing is applied while paused, and the
+// other errors are ignored. With AutoResumeOnRecover, the task restarts from its checkpoint once the destination
+// can be written to again.
+func (a *Applier) pauseOnDiskFull(err error) {
+	if !a.diskFull.pause(err) {
+		return
+	}
+	a.recordError(models.ErrorTypeApply, a.mysqlContext.Gtid, err)
+	a.mysqlContext.Stage = models.StagePausedOnDiskFull
+	if a.mysqlContext.AutoResumeOnRecover {
+		a.logger.Errorf("mysql.applier: the destination is out of space: %v. paused until it can be written to", err)
+		a.execCtx.EmitTyped(models.TaskDestinationDiskFull,
+			"the destination is out of space: %v. paused until it can be written to", err)
+		go a.waitDiskRecovery(err)
+	} else {
+		a.logger.Errorf("mysql.applier: the destination is out of space: %v. paused. restart the job after freeing space", err)
+		a.execCtx.EmitTyped(models.TaskDestinationDiskFull,
+			"the destination is out of space: %v. paused. restart the job after freeing space", err)
+	}
+}
+
+// waitDiskRecovery restarts the task paused on diskErr once the destination can be written to.
+func (a *Applier) waitDiskRecovery(diskErr error) {
+	probe := a.diskProbe
+	if probe == nil {
+		probe = a.probeDiskSpace
+	}
+	interval := a.diskRecoveryInterval
+	if interval == 0 {
+		interval = diskRecoveryCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.shutdownCh:
+			return
+		case <-ticker.C:
+			if err := probe(); err != nil {
+				a.logger.Debugf("mysql.applier: the destination is still not writable: %v", err)
+				continue
+			}
+			a.logger.Infof("mysql.applier: the destination can be written to again. resuming from the checkpoint")
+			a.execCtx.EmitTyped(models.TaskDestinationRecovered,
+				"the destination can be written to again after: %v. resuming from the checkpoint", diskErr)
+			a.diskFull.resume()
+			a.onError(TaskStateRestart, fmt.Errorf("resuming after the destination is out of space: %v", diskErr))
+			return
+		}
+	}
+}
+
+// probeDiskSpace commits a write on the destination, which fails while it is out of space.
+func (a *Applier) probeDiskSpace() error {
+	if a.db == nil {
+		return fmt.Errorf("not connected to the destination")
+	}
+	if _, err := a.db.Exec(fmt.Sprintf(`
+			CREATE TABLE IF NOT EXISTS %v.%v (
+				job_uuid binary(16) NOT NULL PRIMARY KEY COMMENT 'unique identifier of job',
+				probed_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`, g.DtleSchemaName, diskProbeTable)); err != nil {
+		return err
+	}
+	_, err := a.db.Exec(fmt.Sprintf("replace into %v.%v (job_uuid) values (unhex('%s'))",
+		g.DtleSchemaName, diskProbeTable, hex.EncodeToString(a.subjectUUID.Bytes())))
+	return err
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func TestIsDiskFull(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"disk full", &mysqldriver.MySQLError{Number: sql.ErrDiskFull,
+			Message: "Disk full (./db1/t1.ibd); waiting for someone to free some space..."}, true},
+		{"table full", &mysqldriver.MySQLError{Number: sql.ErrRecordFileFull, Message: "The table 't1' is full"}, true},
+		{"binlog cache", &mysqldriver.MySQLError{Number: sql.ErrEEWrite,
+			Message: "Error writing file '/tmp/MLfd=41' (Errcode: 28 - No space left on device)"}, true},
+		{"engine", &mysqldriver.MySQLError{Number: sql.ErrGetErrno, Message: "Got error 28 from storage engine"}, true},
+		{"other engine error", &mysqldriver.MySQLError{Number: sql.ErrGetErrno, Message: "Got error 1 from storage engine"}, false},
+		{"row error", &mysqldriver.MySQLError{Number: sql.ErrDupEntry, Message: "Duplicate entry '28'"}, false},
+		{"other error", fmt.Errorf("invalid connection"), false},
+	}
+	for _, tt := range tests {
+		if got := sql.IsDiskFull(tt.err); got != tt.want {
+			t.Errorf("%v: IsDiskFull() = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.want && sql.IsRowError(tt.err) {
+			t.Errorf("%v: IsRowError() = true, want false, so that OnApplyError does not skip the row", tt.name)
+		}
+	}
+}
+
+func newDiskFullTestApplier(t *testing.T, autoResume bool) (*Applier, chan string) {
+	events := make(chan string, 10)
+	ctx := &common.ExecContext{
+		Subject: uuid.NewV4().String(),
+		EmitTaskEvent: func(eventType string, m string, args ...interface{}) {
+			events <- eventType
+		},
+	}
+	a, err := NewApplier(ctx, &config.MySQLDriverConfig{AutoResumeOnRecover: autoResume}, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	a.diskRecoveryInterval = 10 * time.Millisecond
+	return a, events
+}
+
+var diskFullTestError = &mysqldriver.MySQLError{Number: sql.ErrDiskFull,
+	Message: "Disk full (./db1/t1.ibd); waiting for someone to free some space..."}
+
+// The task is paused on a disk-full error rather than restarted, and resumes once the destination is writable.
+func TestApplier_pauseOnDiskFull(t *testing.T) {
+	a, events := newDiskFullTestApplier(t, true)
+	probes := make(chan struct{}, 10)
+	a.diskProbe = func() error {
+		probes <- struct{}{}
+		if len(probes) < 3 {
+			return diskFullTestError
+		}
+		return nil
+	}
+
+	a.onApplyError(diskFullTestError)
+	// ignored while paused
+	a.onError(TaskStateDead, fmt.Errorf("invalid connection"))
+	if event := <-events; event != models.TaskDestinationDiskFull {
+		t.Errorf("event %q, want %q", event, models.TaskDestinationDiskFull)
+	}
+	if err := a.CheckHealth(); err == nil || !strings.Contains(err.Error(), "out of space") {
+		t.Errorf("CheckHealth() = %v, want paused", err)
+	}
+	if a.mysqlContext.Stage != models.StagePausedOnDiskFull {
+		t.Errorf("Stage = %q, want %q", a.mysqlContext.Stage, models.StagePausedOnDiskFull)
+	}
+
+	select {
+	case result := <-a.waitCh:
+		if result.ExitCode != TaskStateRestart {
+			t.Errorf("wait result = %+v, want a restart", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task does not resume")
+	}
+	if len(probes) != 3 {
+		t.Errorf("probed %v times, want 3", len(probes))
+	}
+	if event := <-events; event != models.TaskDestinationRecovered {
+		t.Errorf("event %q, want %q", event, models.TaskDestinationRecovered)
+	}
+	if len(a.recentErrors.list()) == 0 {
+		t.Errorf("the disk-full error is not recorded")
+	}
+}
+
+// Without AutoResumeOnRecover, the task stays paused.
+func TestApplier_pauseOnDiskFull_noAutoResume(t *testing.T) {
+	a, events := newDiskFullTestApplier(t, false)
+	a.diskProbe = func() error {
+		t.Error("probed without AutoResumeOnRecover")
+		return nil
+	}
+
+	a.onApplyError(diskFullTestError)
+	if event := <-events; event != models.TaskDestinationDiskFull {
+		t.Errorf("event %q, want %q", event, models.TaskDestinationDiskFull)
+	}
+	select {
+	case result := <-a.waitCh:
+		t.Errorf("wait result = %+v, want the task paused", result)
+	case <-time.After(100 * time.Millisecond):
+	}
+	a.Shutdown()
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// dumpDeadline returns whether the full copy started at start has run for MaxDumpDuration.
+// It returns nil if there is no limit.
+func (e *Extractor) dumpDeadline(start time.Time) func() bool {
+	if e.mysqlContext.MaxDumpDuration <= 0 {
+		return nil
+	}
+	deadline := start.Add(time.Duration(e.mysqlContext.MaxDumpDuration) * time.Second)
+	return func() bool {
+		return time.Now().After(deadline)
+	}
+}
+
+// onDumpIncomplete reports the tables (as "schema.table") not fully copied as the full copy stopped at
+// MaxDumpDuration. The incremental copy starts from the snapshot as usual, and each table can be copied
+// again by ResyncTable. The rows of a table copied in part are kept until then.
+func (e *Extractor) onDumpIncomplete(tables []string) {
+	e.incompleteTablesLock.Lock()
+	e.incompleteTables = append([]string(nil), tables...)
+	e.incompleteTablesLock.Unlock()
+
+	// The applier completes the full copy once it has applied as many rows as sent.
+	atomic.StoreInt64(&e.mysqlContext.RowsEstimate, e.mysqlContext.GetTotalRowsCopied())
+
+	e.logger.Warnf("mysql.extractor: the full copy stopped after MaxDumpDuration %vs. %v tables are not fully copied: %v."+
+		" resync them with ResyncTable", e.mysqlContext.MaxDumpDuration, len(tables), strings.Join(tables, ", "))
+	e.execCtx.EmitTyped(models.TaskFullCopyIncomplete,
+		"the full copy stopped after MaxDumpDuration %vs. tables not fully copied: %v",
+		e.mysqlContext.MaxDumpDuration, strings.Join(tables, ", "))
+}
+
+// getIncompleteTables returns the tables reported by onDumpIncomplete which have not been resynced since.
+func (e *Extractor) getIncompleteTables() []string {
+	e.incompleteTablesLock.Lock()
+	defer e.incompleteTablesLock.Unlock()
+	return append([]string(nil), e.incompleteTables...)
+}
+
+// onTableResynced removes the table from the incomplete tables once its chunks have been sent.
+func (e *Extractor) onTableResynced(schemaName string, tableName string) {
+	name := fmt.Sprintf("%s.%s", schemaName, tableName)
+	e.incompleteTablesLock.Lock()
+	defer e.incompleteTablesLock.Unlock()
+	for i, t := range e.incompleteTables {
+		if t == name {
+			e.incompleteTables = append(e.incompleteTables[:i], e.incompleteTables[i+1:]...)
+			e.logger.Infof("mysql.extractor: table %v copied by the resync. %v tables not fully copied",
+				name, len(e.incompleteTables))
+			return
+		}
+	}
+}
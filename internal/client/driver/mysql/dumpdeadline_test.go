@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	usql "github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func TestExtractor_dumpDeadline(t *testing.T) {
+	e := &Extractor{mysqlContext: &config.MySQLDriverConfig{}}
+	if e.dumpDeadline(time.Now()) != nil {
+		t.Error("dumpDeadline() without MaxDumpDuration, want nil")
+	}
+	e.mysqlContext.MaxDumpDuration = 60
+	if pastDeadline := e.dumpDeadline(time.Now()); pastDeadline == nil || pastDeadline() {
+		t.Error("dumpDeadline() of a copy just started, want not past")
+	}
+	if pastDeadline := e.dumpDeadline(time.Now().Add(-time.Minute)); pastDeadline == nil || !pastDeadline() {
+		t.Error("dumpDeadline() of a copy started a minute ago, want past")
+	}
+}
+
+// The tables not fully copied are reported, and the full copy is complete with the rows sent.
+func TestExtractor_onDumpIncomplete(t *testing.T) {
+	events := make(chan string, 1)
+	e := &Extractor{
+		logger: logrus.NewEntry(logrus.New()),
+		execCtx: &common.ExecContext{
+			EmitTaskEvent: func(eventType string, m string, args ...interface{}) {
+				events <- eventType
+			},
+		},
+		mysqlContext: &config.MySQLDriverConfig{MaxDumpDuration: 10, RowsEstimate: 100, TotalRowsCopied: 40},
+	}
+	e.onDumpIncomplete([]string{"db1.t2", "db1.t3"})
+
+	if event := <-events; event != models.TaskFullCopyIncomplete {
+		t.Errorf("event %q, want %q", event, models.TaskFullCopyIncomplete)
+	}
+	stats, err := e.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ProgressPct != "100.0" {
+		t.Errorf("ProgressPct = %v, want 100.0", stats.ProgressPct)
+	}
+	if !reflect.DeepEqual(stats.IncompleteTables, []string{"db1.t2", "db1.t3"}) {
+		t.Errorf("IncompleteTables = %v", stats.IncompleteTables)
+	}
+
+	e.onTableResynced("db1", "t2")
+	if tables := e.getIncompleteTables(); !reflect.DeepEqual(tables, []string{"db1.t3"}) {
+		t.Errorf("incomplete tables after the resync of db1.t2 = %v, want db1.t3", tables)
+	}
+}
+
+// The dump stops at a chunk boundary once past the deadline.
+func Test_dumper_pastDeadline(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := usql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	query := "create database if not exists dtle_test;" +
+		" drop table if exists dtle_test.t_deadline;" +
+		" create table dtle_test.t_deadline (id int primary key);" +
+		" insert into dtle_test.t_deadline values (1), (2), (3), (4), (5), (6), (7), (8), (9), (10);"
+	if _, err := db.Exec(query); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// the number of chunks before the deadline. -1: no deadline.
+		chunks  int
+		wantIDs int
+	}{
+		{"no deadline", -1, 10},
+		{"past", 0, 0},
+		{"after 2 chunks", 2, 4},
+	}
+	for _, tt := range tests {
+		table := config.NewTable("dtle_test", "t_deadline")
+		table.Where = "true"
+		if table.OriginalTableColumns, err = base.GetTableColumns(db, "dtle_test", "t_deadline"); err != nil {
+			t.Fatal(err)
+		}
+		table.UseUniqueKey = &umconf.UniqueKey{
+			Name:        "PRIMARY",
+			Columns:     *umconf.NewColumnList(umconf.NewColumns([]string{"id"})),
+			LastMaxVals: make([]string, 1),
+		}
+		d := NewDumper(db, table, 2, logrus.NewEntry(logrus.New()))
+		if tt.chunks == 0 {
+			// a tight MaxDumpDuration, already exceeded
+			e := &Extractor{mysqlContext: &config.MySQLDriverConfig{MaxDumpDuration: 1}}
+			d.pastDeadline = e.dumpDeadline(time.Now().Add(-time.Second))
+		} else if tt.chunks > 0 {
+			checks := 0
+			d.pastDeadline = func() bool {
+				checks++
+				return checks > tt.chunks
+			}
+		}
+		if err := d.Dump(); err != nil {
+			t.Fatalf("%v: dumper.Dump() error = %v", tt.name, err)
+		}
+		var ids []string
+		for entry := range d.resultsChannel {
+			if entry.Err != "" {
+				t.Fatalf("%v: error = %v", tt.name, entry.Err)
+			}
+			for _, row := range entry.ValuesX {
+				ids = append(ids, string(*row[0]))
+			}
+		}
+		var want []string
+		for i := 1; i <= tt.wantIDs; i++ {
+			want = append(want, fmt.Sprint(i))
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("%v: copied ids %v, want %v", tt.name, ids, want)
+		}
+		if d.cutAtDeadline != (tt.chunks >= 0) {
+			t.Errorf("%v: cutAtDeadline = %v", tt.name, d.cutAtDeadline)
+		}
+	}
+}
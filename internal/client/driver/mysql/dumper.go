@@ -7,21 +7,33 @@
 package mysql
 
 import (
+	gosql "database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/actiontech/dtle/internal/g"
 
 	"time"
 
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
 	usql "github.com/actiontech/dtle/internal/client/driver/mysql/sql"
 	"github.com/actiontech/dtle/internal/config"
 	umconf "github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/actiontech/dtle/internal/models"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// the rows estimate of a table is checked every this many chunks
+	dumpEstimateCheckChunks = 4
+	// interval of logging the progress of the table being copied
+	dumpProgressLogInterval = 30 * time.Second
+)
+
 type dumper struct {
 	logger             *logrus.Entry
 	chunkSize          int64
@@ -39,12 +51,157 @@ type dumper struct {
 	// DB is safe for using in goroutines
 	// http://golang.org/src/database/sql/sql.go?s=5574:6362#L201
 	db usql.QueryAble
+	// where the checksum is read. It is db by default.
+	checksumDB usql.QueryAble
+
+	// nil if the full copy is not throttled. See resourceThrottler.
+	throttler *resourceThrottler
+	// ChunkSize. chunkSize is less while throttled.
+	fullChunkSize int64
+	// bytes. If positive, chunkSize is estimated from the size of the rows, and a chunk read on the unique key
+	// is cut when its rows reach it. See ChunkSizeBytes.
+	chunkSizeBytes int64
 
 	// 0: don't checksum; 1: checksum once; 2: checksum every time
 	doChecksum int
 	oldWayDump bool
 
 	sentTableDef bool
+	// the indexes of the primary key columns if the table is sampled. See config.SampleKeyIndexes.
+	sampleKey []int
+	// the indexes of the EncryptColumns, encrypted by cipher. See config.EncryptedColumnIndexes.
+	encryptedColumns []int
+	cipher           *binlog.ColumnCipher
+
+	// progress of the table. Accessed atomically.
+	rowsEstimate int64
+	rowsCopied   int64
+	chunk        int64
+
+	// If not nil, each chunk carries a copy of it with the boundary of the chunk. See config.DumpCheckpoint.
+	// It must not be changed until the dump finishes.
+	checkpoint *config.DumpCheckpoint
+
+	// If not nil, the dump stops before the next chunk once it returns true. See Extractor.dumpDeadline.
+	pastDeadline func() bool
+	// whether the dump has stopped at the deadline. Read it after resultsChannel is closed.
+	cutAtDeadline bool
+}
+
+// refreshRowsEstimate reads the estimated number of rows of the table from information_schema.
+// The estimate is at least the rows read so far.
+func (d *dumper) refreshRowsEstimate(rowsRead int64) error {
+	var value gosql.NullInt64
+	query := `select TABLE_ROWS from information_schema.TABLES where TABLE_SCHEMA = ? and TABLE_NAME = ?`
+	if err := d.db.QueryRow(query, d.TableSchema, d.TableName).Scan(&value); err != nil {
+		return err
+	}
+	estimate := value.Int64
+	if estimate < rowsRead {
+		estimate = rowsRead
+	}
+	atomic.StoreInt64(&d.rowsEstimate, estimate)
+	return nil
+}
+
+// checkRowsEstimate refreshes the estimate if it is found too small after some chunks.
+// It must be called in the dumping goroutine, as the db (a transaction) is not shared.
+func (d *dumper) checkRowsEstimate(rowsRead int64) {
+	chunk := atomic.LoadInt64(&d.chunk)
+	if chunk%dumpEstimateCheckChunks != 0 || rowsRead <= atomic.LoadInt64(&d.rowsEstimate) {
+		return
+	}
+	if err := d.refreshRowsEstimate(rowsRead); err != nil {
+		d.logger.Warnf("mysql.dumper: failed to refresh rows estimate of %v.%v. err: %v",
+			d.TableSchema, d.TableName, err)
+		return
+	}
+	d.logger.Debugf("mysql.dumper: refreshed rows estimate of %v.%v: %v",
+		d.TableSchema, d.TableName, atomic.LoadInt64(&d.rowsEstimate))
+}
+
+// chunkRowsForBytes returns the number of rows of rowSize bytes in a chunk of chunkSizeBytes, at least 1.
+func chunkRowsForBytes(chunkSizeBytes int64, rowSize int64) int64 {
+	if rowSize < 1 {
+		rowSize = 1
+	}
+	if rows := chunkSizeBytes / rowSize; rows > 1 {
+		return rows
+	}
+	return 1
+}
+
+// setChunkSizeByBytes sets the chunk size in rows for rows of rowSize bytes on average.
+func (d *dumper) setChunkSizeByBytes(rowSize int64) {
+	d.fullChunkSize = chunkRowsForBytes(d.chunkSizeBytes, rowSize)
+	d.chunkSize = d.fullChunkSize
+}
+
+// estimateChunkSizeByBytes sets the chunk size of the first chunk by the average row length of the table
+// in information_schema. The chunk size of the old way, with OFFSET, is not changed later.
+func (d *dumper) estimateChunkSizeByBytes() {
+	var avgRowLength gosql.NullInt64
+	query := `select AVG_ROW_LENGTH from information_schema.TABLES where TABLE_SCHEMA = ? and TABLE_NAME = ?`
+	if err := d.db.QueryRow(query, d.TableSchema, d.TableName).Scan(&avgRowLength); err != nil {
+		d.logger.Warnf("mysql.dumper: failed to read avg row length of %v.%v. using ChunkSize %v. err: %v",
+			d.TableSchema, d.TableName, d.chunkSize, err)
+		return
+	}
+	if avgRowLength.Int64 > 0 {
+		d.setChunkSizeByBytes(avgRowLength.Int64)
+	}
+	d.logger.Debugf("mysql.dumper: avg row length of %v.%v: %v. chunk size: %v rows",
+		d.TableSchema, d.TableName, avgRowLength.Int64, d.chunkSize)
+}
+
+// onRowsCopied is called when rows of the table has been sent.
+func (d *dumper) onRowsCopied(nRows int64) {
+	atomic.AddInt64(&d.rowsCopied, nRows)
+}
+
+// finishProgress fixes the estimate to the exact number after the table has been copied.
+func (d *dumper) finishProgress() {
+	atomic.StoreInt64(&d.rowsEstimate, atomic.LoadInt64(&d.rowsCopied))
+}
+
+func (d *dumper) progress() *models.TableProgress {
+	return &models.TableProgress{
+		TableSchema:  d.TableSchema,
+		TableName:    d.TableName,
+		RowsEstimate: atomic.LoadInt64(&d.rowsEstimate),
+		RowsCopied:   atomic.LoadInt64(&d.rowsCopied),
+		Chunk:        atomic.LoadInt64(&d.chunk),
+	}
+}
+
+// getAutoIncrement reads the AUTO_INCREMENT counter of the table.
+// ok is false if the table has no auto_increment column.
+func (d *dumper) getAutoIncrement() (autoIncrement int64, ok bool, err error) {
+	var value gosql.NullInt64
+	query := `select AUTO_INCREMENT from information_schema.TABLES where TABLE_SCHEMA = ? and TABLE_NAME = ?`
+	if err := d.db.QueryRow(query, d.TableSchema, d.TableName).Scan(&value); err != nil {
+		return 0, false, err
+	}
+	return value.Int64, value.Valid, nil
+}
+
+// autoIncrementEntry returns an entry setting AUTO_INCREMENT of the destination table.
+// It is sent after all rows of the table, as inserting rows might increase the counter.
+func (d *dumper) autoIncrementEntry(autoIncrement int64) *DumpEntry {
+	tableSchema := d.TableSchema
+	if d.table.TableSchemaRename != "" {
+		tableSchema = d.table.TableSchemaRename
+	}
+	tableName := d.TableName
+	if d.table.TableRename != "" {
+		tableName = d.table.TableRename
+	}
+	return &DumpEntry{
+		TbSQL: []string{fmt.Sprintf("ALTER TABLE %s.%s AUTO_INCREMENT = %d",
+			umconf.EscapeName(tableSchema), umconf.EscapeName(tableName), autoIncrement)},
+		TotalCount: 1,
+		RowsCount:  1,
+	}
 }
 
 func NewDumper(db usql.QueryAble, table *config.Table, chunkSize int64,
@@ -53,6 +210,7 @@ func NewDumper(db usql.QueryAble, table *config.Table, chunkSize int64,
 	dumper := &dumper{
 		logger:             logger,
 		db:                 db,
+		checksumDB:         db,
 		TableSchema:        table.TableSchema,
 		EscapedTableSchema: umconf.EscapeName(table.TableSchema),
 		TableName:          table.TableName,
@@ -60,6 +218,7 @@ func NewDumper(db usql.QueryAble, table *config.Table, chunkSize int64,
 		table:              table,
 		resultsChannel:     make(chan *DumpEntry, 24),
 		chunkSize:          chunkSize,
+		fullChunkSize:      chunkSize,
 		shutdownCh:         make(chan struct{}),
 		sentTableDef:       false,
 	}
@@ -113,10 +272,13 @@ func (d *dumper) prepareForDumping() error {
 	for _, col := range d.table.OriginalTableColumns.Columns {
 		switch col.Type {
 		case umconf.FloatColumnType, umconf.DoubleColumnType,
-			umconf.MediumIntColumnType, umconf.BigIntColumnType,
-			umconf.DecimalColumnType:
+			umconf.MediumIntColumnType, umconf.BigIntColumnType:
 			columns = append(columns, fmt.Sprintf("%s+0", col.EscapedName))
 			needPm = true
+		case umconf.DecimalColumnType:
+			// exactly as text. DECIMAL(65,30)+0 would overflow the precision.
+			columns = append(columns, fmt.Sprintf("cast(%s as char)", col.EscapedName))
+			needPm = true
 		default:
 			columns = append(columns, col.EscapedName)
 		}
@@ -127,9 +289,60 @@ func (d *dumper) prepareForDumping() error {
 		d.columns = "*"
 	}
 
+	var err error
+	if d.sampleKey, err = config.SampleKeyIndexes(d.table); err != nil {
+		return err
+	}
+	if d.encryptedColumns = config.EncryptedColumnIndexes(d.table); len(d.encryptedColumns) > 0 && d.cipher == nil {
+		return fmt.Errorf("%v.%v has EncryptColumns, and EncryptionKey is not set", d.table.TableSchema, d.table.TableName)
+	}
+	return nil
+}
+
+// encryptRows encrypts the values of the EncryptColumns of entry, and flags them in EncryptedColumns.
+func (d *dumper) encryptRows(entry *DumpEntry) error {
+	if len(entry.ValuesX) == 0 {
+		return nil
+	}
+	entry.EncryptedColumns = make([]byte, len(entry.ValuesX[0]))
+	for _, idx := range d.encryptedColumns {
+		if idx < len(entry.EncryptedColumns) {
+			entry.EncryptedColumns[idx] = 1
+		}
+	}
+	for _, row := range entry.ValuesX {
+		for _, idx := range d.encryptedColumns {
+			if idx >= len(row) {
+				continue
+			}
+			var err error
+			if row[idx], err = d.cipher.EncryptText(row[idx]); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// sampleRows removes the rows which are not in the sample of SampleRate from entry.
+// RowsCount is kept as the rows read, for the progress of the table.
+func (d *dumper) sampleRows(entry *DumpEntry) {
+	sampled := entry.ValuesX[:0]
+	key := make([][]byte, len(d.sampleKey))
+	for _, row := range entry.ValuesX {
+		for i, idx := range d.sampleKey {
+			key[i] = nil
+			if idx < len(row) && row[idx] != nil {
+				key[i] = *row[idx]
+			}
+		}
+		if config.SampledIn(d.table.SampleRate, key) {
+			sampled = append(sampled, row)
+		}
+	}
+	entry.ValuesX = sampled
+}
+
 func (d *dumper) buildQueryOldWay() string {
 	return fmt.Sprintf(`SELECT %s FROM %s.%s where (%s) LIMIT %d OFFSET %d`,
 		d.columns,
@@ -141,6 +354,62 @@ func (d *dumper) buildQueryOldWay() string {
 	)
 }
 
+// buildRangeAfterLastMaxVals returns the condition of the rows after the last chunk on the unique key.
+func buildRangeAfterLastMaxVals(uniqueKey *umconf.UniqueKey) string {
+	nCol := len(uniqueKey.Columns.Columns)
+	rangeItems := make([]string, nCol)
+
+	// The form like: (A > a) or (A = a and B > b) or (A = a and B = b and C > c) or ...
+	for x := 0; x < nCol; x++ {
+		innerItems := make([]string, x+1)
+
+		for y := 0; y < x; y++ {
+			colName := uniqueKey.Columns.Columns[y].EscapedName
+			innerItems[y] = fmt.Sprintf("(%s = %s)", colName, uniqueKey.LastMaxVals[y])
+		}
+
+		colName := uniqueKey.Columns.Columns[x].EscapedName
+		innerItems[x] = fmt.Sprintf("(%s > %s)", colName, uniqueKey.LastMaxVals[x])
+
+		rangeItems[x] = fmt.Sprintf("(%s)", strings.Join(innerItems, " and "))
+	}
+
+	return strings.Join(rangeItems, " or ")
+}
+
+// resumeTableFromCheckpoint sets the table to continue from the last chunk in the checkpoint.
+// It returns false if the checkpoint is not for the table or the chunks are not on the same unique key,
+// in which case the table is copied from the beginning.
+func resumeTableFromCheckpoint(table *config.Table, checkpoint *config.DumpCheckpoint) bool {
+	if !checkpoint.IsCurrentTable(table.TableSchema, table.TableName) || checkpoint.Iteration == 0 {
+		return false
+	}
+	if os.Getenv(g.ENV_DUMP_OLDWAY) != "" || table.UseUniqueKey == nil ||
+		table.UseUniqueKey.Name != checkpoint.UniqueKey ||
+		len(table.UseUniqueKey.Columns.Columns) != len(checkpoint.LastMaxVals) {
+		return false
+	}
+	table.Iteration = checkpoint.Iteration
+	copy(table.UseUniqueKey.LastMaxVals, checkpoint.LastMaxVals)
+	return true
+}
+
+// chunkCheckpoint returns the checkpoint after the last chunk.
+func (d *dumper) chunkCheckpoint() ([]byte, error) {
+	c := *d.checkpoint
+	c.TableSchema = d.TableSchema
+	c.TableName = d.TableName
+	c.Iteration = d.table.Iteration
+	c.UniqueKey = ""
+	c.LastMaxVals = nil
+	if d.table.UseUniqueKey != nil && !d.oldWayDump {
+		c.UniqueKey = d.table.UseUniqueKey.Name
+		c.LastMaxVals = make([]string, len(d.table.UseUniqueKey.LastMaxVals))
+		copy(c.LastMaxVals, d.table.UseUniqueKey.LastMaxVals)
+	}
+	return json.Marshal(&c)
+}
+
 func (d *dumper) buildQueryOnUniqueKey() string {
 	nCol := len(d.table.UseUniqueKey.Columns.Columns)
 	uniqueKeyColumnAscending := make([]string, nCol, nCol)
@@ -160,24 +429,7 @@ func (d *dumper) buildQueryOnUniqueKey() string {
 	if d.table.Iteration == 0 {
 		rangeStr = "true"
 	} else {
-		rangeItems := make([]string, nCol)
-
-		// The form like: (A > a) or (A = a and B > b) or (A = a and B = b and C > c) or ...
-		for x := 0; x < nCol; x++ {
-			innerItems := make([]string, x+1)
-
-			for y := 0; y < x; y++ {
-				colName := d.table.UseUniqueKey.Columns.Columns[y].EscapedName
-				innerItems[y] = fmt.Sprintf("(%s = %s)", colName, d.table.UseUniqueKey.LastMaxVals[y])
-			}
-
-			colName := d.table.UseUniqueKey.Columns.Columns[x].EscapedName
-			innerItems[x] = fmt.Sprintf("(%s > %s)", colName, d.table.UseUniqueKey.LastMaxVals[x])
-
-			rangeItems[x] = fmt.Sprintf("(%s)", strings.Join(innerItems, " and "))
-		}
-
-		rangeStr = strings.Join(rangeItems, " or ")
+		rangeStr = buildRangeAfterLastMaxVals(d.table.UseUniqueKey)
 	}
 
 	return fmt.Sprintf(`SELECT %s FROM %s.%s where (%s) and (%s) order by %s LIMIT %d`,
@@ -231,16 +483,19 @@ func (d *dumper) getChunkData() (nRows int64, err error) {
 	}()
 
 	query := ""
+	// whether the chunk is cut by chunkSizeBytes. The old way can not, as the next chunk is by OFFSET.
+	byBytes := false
 	if d.oldWayDump || d.table.UseUniqueKey == nil {
 		query = d.buildQueryOldWay()
 	} else {
 		query = d.buildQueryOnUniqueKey()
+		byBytes = d.chunkSizeBytes > 0
 	}
 	d.logger.Debugf("getChunkData. query: %s", query)
 
 	if d.doChecksum != 0 {
 		if d.doChecksum == 2 || (d.doChecksum == 1 && d.table.Iteration == 0) {
-			row := d.db.QueryRow(fmt.Sprintf("checksum table %v.%v", d.TableSchema, d.TableName))
+			row := d.checksumDB.QueryRow(fmt.Sprintf("checksum table %v.%v", umconf.EscapeName(d.TableSchema), umconf.EscapeName(d.TableName)))
 			var table string
 			var cs int64
 			err := row.Scan(&table, &cs)
@@ -256,7 +511,7 @@ func (d *dumper) getChunkData() (nRows int64, err error) {
 	d.table.Iteration += 1
 	rows, err := d.db.Query(query)
 	if err != nil {
-		d.logger.Debugf("mysql.dumper. error at select chunk. query: ", query)
+		d.logger.Debugf("mysql.dumper. error at select chunk. query: %v", query)
 		newErr := fmt.Errorf("mysql.dumper. error at select chunk. err: %v", err)
 		d.logger.Errorf(newErr.Error())
 		return 0, err
@@ -269,6 +524,7 @@ func (d *dumper) getChunkData() (nRows int64, err error) {
 
 	scanArgs := make([]interface{}, len(columns)) // tmp use, for casting `values` to `[]interface{}`
 
+	var chunkBytes int64
 	for rows.Next() {
 		rowValuesRaw := make([]*[]byte, len(columns))
 		for i := range rowValuesRaw {
@@ -280,12 +536,26 @@ func (d *dumper) getChunkData() (nRows int64, err error) {
 			return 0, err
 		}
 
+		if byBytes {
+			rowSize := int64(dumpRowSize(rowValuesRaw))
+			if entry.RowsCount > 0 && chunkBytes+rowSize > d.chunkSizeBytes {
+				// The row and the rest are read by the next chunk, after the last row on the unique key.
+				// A row larger than chunkSizeBytes is a chunk on its own.
+				rows.Close()
+				break
+			}
+			chunkBytes += rowSize
+		}
+
 		entry.ValuesX = append(entry.ValuesX, rowValuesRaw)
 
 		entry.incrementCounter()
 	}
 
 	d.logger.Debugf("getChunkData. n_row: %d", entry.RowsCount)
+	if byBytes && entry.RowsCount > 0 {
+		d.setChunkSizeByBytes(chunkBytes / entry.RowsCount)
+	}
 
 	if entry.RowsCount > 0 {
 		var lastVals []string
@@ -307,6 +577,14 @@ func (d *dumper) getChunkData() (nRows int64, err error) {
 			}
 			d.logger.Debugf("GetLastMaxVal: got %v", d.table.UseUniqueKey.LastMaxVals)
 		}
+		if d.checkpoint != nil {
+			if entry.Checkpoint, err = d.chunkCheckpoint(); err != nil {
+				return entry.RowsCount, err
+			}
+		}
+	}
+	if d.sampleKey != nil {
+		d.sampleRows(entry)
 	}
 	if d.table.TableRename != "" {
 		entry.TableName = d.table.TableRename
@@ -324,6 +602,11 @@ func (d *dumper) getChunkData() (nRows int64, err error) {
 			entry.ValuesX[i] = row
 		}
 	}
+	if len(d.encryptedColumns) > 0 {
+		if err := d.encryptRows(entry); err != nil {
+			return entry.RowsCount, err
+		}
+	}
 	// ValuesX[i]: n-th row
 	// ValuesX[i][j]: j-th col of n-th row
 	// Values[i]: i-th chunk of rows
@@ -337,14 +620,31 @@ func (d *dumper) Dump() error {
 	if err != nil {
 		return err
 	}
+	if err := d.refreshRowsEstimate(0); err != nil {
+		// Only for the progress. Not fatal.
+		d.logger.Warnf("mysql.dumper: failed to estimate rows of %v.%v. err: %v", d.TableSchema, d.TableName, err)
+	}
+	if d.chunkSizeBytes > 0 {
+		d.estimateChunkSizeByBytes()
+	}
 
 	go func() {
+		var rowsRead int64
 		for {
 			select {
 			case <-d.shutdownCh:
 				return
 			default:
 			}
+			if d.pastDeadline != nil && d.pastDeadline() {
+				d.logger.Warnf("mysql.dumper: MaxDumpDuration exceeded. stopping the dump of %v.%v after %v chunks",
+					d.TableSchema, d.TableName, atomic.LoadInt64(&d.chunk))
+				d.cutAtDeadline = true
+				break
+			}
+			if !d.throttle() {
+				return
+			}
 
 			nRows, err := d.getChunkData()
 			if err != nil {
@@ -352,7 +652,13 @@ func (d *dumper) Dump() error {
 				break
 			}
 
-			if nRows < d.chunkSize {
+			if nRows > 0 {
+				atomic.AddInt64(&d.chunk, 1)
+				rowsRead += nRows
+				d.checkRowsEstimate(rowsRead)
+			}
+
+			if nRows < d.chunkSize && d.chunkSizeBytes == 0 {
 				// If nRows < d.chunkSize while there are still more rows, it is a possible mysql bug.
 				d.logger.Infof("mysql.dumper: nRows < d.chunkSize. %v %v", nRows, d.chunkSize)
 			}
@@ -1,27 +1,29 @@
 package mysql
 
 import (
+	"database/sql"
+	"testing"
+	"time"
+
 	"github.com/actiontech/dtle/helper/u"
 	"github.com/actiontech/dtle/internal/config"
 	umconf "github.com/actiontech/dtle/internal/config/mysql"
-	"github.com/actiontech/dtle/internal/logger"
-	"os"
-	"testing"
-	"database/sql"
-	"time"
+	"github.com/sirupsen/logrus"
 )
 
+// It dumps tpcc1.order_line with a slow consumer. The table should have been loaded into the test server.
 func TestDumper2000(t *testing.T) {
-	logger := logger.NewEntry(logger.New(os.Stdout, logger.DebugLevel))
+	skipWithoutMySQL(t)
+	logger := logrus.NewEntry(logrus.New())
 	var err error
 
 	mysqlCtx := &config.MySQLDriverConfig{
 		ConnectionConfig: &umconf.ConnectionConfig{
-			Host: "127.0.0.1",
-			Port: 3307,
-			User: "root",
-			Password: "password",
-			Charset: "utf8mb4",
+			Host:     "192.168.99.100",
+			Port:     13307,
+			User:     "root",
+			Password: "rootroot",
+			Charset:  "utf8mb4",
 		},
 	}
 	mysqlCtx.SetDefault()
@@ -29,27 +31,26 @@ func TestDumper2000(t *testing.T) {
 	i := NewInspector(mysqlCtx, logger)
 	u.PanicIfErr(i.InitDBConnections())
 	table := config.NewTable("tpcc1", "order_line")
-	i.ValidateOriginalTable("tpcc1", "order_line", table)
+	if err := i.ValidateOriginalTable("tpcc1", "order_line", table); err != nil {
+		t.Skipf("tpcc1.order_line is not usable: %v", err)
+	}
 
 	logger.Infof("unique_key: %v", table.UseUniqueKey)
 
-	db, err := sql.Open("mysql", "root:password@tcp(127.0.0.1:3307)/")
+	db, err := sql.Open("mysql", "root:rootroot@tcp(192.168.99.100:13307)/")
 	u.PanicIfErr(err)
+	defer db.Close()
 	tx, err := db.Begin()
 	u.PanicIfErr(err)
+	defer tx.Rollback()
 	_, err = tx.Exec("start transaction with consistent snapshot")
 	u.PanicIfErr(err)
 
 	d := NewDumper(tx, table, 2000, logger)
-
-	go func() {
-		for range d.resultsChannel {
-			time.Sleep(50 * time.Millisecond)
-		}
-	}()
-
-	d.Dump()
-
-	end := make(chan struct{})
-	<-end
+	if err := d.Dump(); err != nil {
+		t.Fatal(err)
+	}
+	for range d.resultsChannel {
+		time.Sleep(50 * time.Millisecond)
+	}
 }
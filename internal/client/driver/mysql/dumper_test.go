@@ -8,18 +8,25 @@ package mysql
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	usql "github.com/actiontech/dtle/internal/client/driver/mysql/sql"
 	"github.com/actiontech/dtle/internal/config"
-	log "github.com/actiontech/dtle/internal/logger"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/actiontech/dtle/internal/models"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewDumper(t *testing.T) {
 	type args struct {
-		db        *sql.Tx
-		dbName    string
-		tableName string
-		logger    *log.Entry
+		db        usql.QueryAble
+		table     *config.Table
+		chunkSize int64
+		logger    *logrus.Entry
 	}
 	tests := []struct {
 		name string
@@ -30,7 +37,7 @@ func TestNewDumper(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewDumper(tt.args.db, tt.args.dbName, tt.args.tableName, tt.args.logger); !reflect.DeepEqual(got, tt.want) {
+			if got := NewDumper(tt.args.db, tt.args.table, tt.args.chunkSize, tt.args.logger); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewDumper() = %v, want %v", got, tt.want)
 			}
 		})
@@ -52,27 +59,23 @@ func Test_dumpEntry_incrementCounter(t *testing.T) {
 }
 
 func Test_dumper_Dump(t *testing.T) {
-	type args struct {
-		w int
-	}
 	tests := []struct {
 		name    string
 		d       *dumper
-		args    args
 		wantErr bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.d.Dump(tt.args.w); (err != nil) != tt.wantErr {
+			if err := tt.d.Dump(); (err != nil) != tt.wantErr {
 				t.Errorf("dumper.Dump() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func Test_showDatabases(t *testing.T) {
+func TestShowDatabases(t *testing.T) {
 	type args struct {
 		db *sql.DB
 	}
@@ -86,22 +89,23 @@ func Test_showDatabases(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := showDatabases(tt.args.db)
+			got, err := usql.ShowDatabases(tt.args.db)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("showDatabases() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("ShowDatabases() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("showDatabases() = %v, want %v", got, tt.want)
+				t.Errorf("ShowDatabases() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_showTables(t *testing.T) {
+func TestShowTables(t *testing.T) {
 	type args struct {
-		db     *sql.DB
-		dbName string
+		db       *sql.DB
+		dbName   string
+		showType bool
 	}
 	tests := []struct {
 		name       string
@@ -113,13 +117,13 @@ func Test_showTables(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotTables, err := showTables(tt.args.db, tt.args.dbName)
+			gotTables, err := usql.ShowTables(tt.args.db, tt.args.dbName, tt.args.showType)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("showTables() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("ShowTables() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(gotTables, tt.wantTables) {
-				t.Errorf("showTables() = %v, want %v", gotTables, tt.wantTables)
+				t.Errorf("ShowTables() = %v, want %v", gotTables, tt.wantTables)
 			}
 		})
 	}
@@ -141,3 +145,376 @@ func Test_dumper_Close(t *testing.T) {
 		})
 	}
 }
+
+func Test_dumper_autoIncrementEntry(t *testing.T) {
+	table := config.NewTable("db1", "t1")
+	table.TableSchemaRename = "db2"
+	d := NewDumper(nil, table, 100, nil)
+	entry := d.autoIncrementEntry(1001)
+	want := []string{"ALTER TABLE `db2`.`t1` AUTO_INCREMENT = 1001"}
+	if !reflect.DeepEqual(entry.TbSQL, want) {
+		t.Errorf("dumper.autoIncrementEntry() = %v, want %v", entry.TbSQL, want)
+	}
+}
+
+func Test_dumper_getAutoIncrement(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := usql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("create database if not exists dtle_test;" +
+		" drop table if exists dtle_test.t_ai; create table dtle_test.t_ai (id int auto_increment primary key)"); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	// MySQL 8.0 only
+	db.Exec("set @@session.information_schema_stats_expiry = 0")
+	if _, err := db.Exec("insert into dtle_test.t_ai values (), (), (), (100)"); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDumper(db, config.NewTable("dtle_test", "t_ai"), 100, nil)
+	autoIncrement, ok, err := d.getAutoIncrement()
+	if err != nil || !ok {
+		t.Fatalf("dumper.getAutoIncrement() = %v, %v, %v", autoIncrement, ok, err)
+	}
+	// The counter is at least max(id)+1. A small gap is allowed for innodb_autoinc_lock_mode.
+	if autoIncrement < 101 || autoIncrement > 110 {
+		t.Errorf("dumper.getAutoIncrement() = %v, want around 101", autoIncrement)
+	}
+}
+
+func Test_dumper_progress(t *testing.T) {
+	d := NewDumper(nil, config.NewTable("db1", "tb1"), 10, nil)
+	d.rowsEstimate = 100
+	d.chunk = dumpEstimateCheckChunks
+	// The estimate is not too small. db is not used.
+	d.checkRowsEstimate(40)
+	d.onRowsCopied(10)
+	d.onRowsCopied(10)
+
+	want := &models.TableProgress{TableSchema: "db1", TableName: "tb1", RowsEstimate: 100, RowsCopied: 20,
+		Chunk: dumpEstimateCheckChunks}
+	if got := d.progress(); !reflect.DeepEqual(got, want) {
+		t.Errorf("dumper.progress() = %+v, want %+v", got, want)
+	}
+
+	d.finishProgress()
+	if got := d.progress().RowsEstimate; got != 20 {
+		t.Errorf("dumper.progress().RowsEstimate = %v after finishProgress(), want 20", got)
+	}
+}
+
+func Test_dumper_refreshRowsEstimate(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := usql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("create database if not exists dtle_test;" +
+		" drop table if exists dtle_test.t_est; create table dtle_test.t_est (id int primary key)"); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+
+	d := NewDumper(db, config.NewTable("dtle_test", "t_est"), 100, nil)
+	// The statistics of an empty table says 0 rows. It is too small if 1000 rows has been read.
+	if err := d.refreshRowsEstimate(1000); err != nil {
+		t.Fatalf("dumper.refreshRowsEstimate() error = %v", err)
+	}
+	if got := d.progress().RowsEstimate; got != 1000 {
+		t.Errorf("dumper.progress().RowsEstimate = %v, want 1000", got)
+	}
+}
+
+// newResumeTestTable returns dtle_test.t_resume (id int primary key, v varchar) with the primary key to copy by.
+func newResumeTestTable() *config.Table {
+	table := config.NewTable("dtle_test", "t_resume")
+	table.Where = "true"
+	table.OriginalTableColumns = umconf.NewColumnList(umconf.NewColumns([]string{"id", "v"}))
+	table.UseUniqueKey = &umconf.UniqueKey{
+		Name:        "PRIMARY",
+		Columns:     *umconf.NewColumnList(umconf.NewColumns([]string{"id"})),
+		LastMaxVals: make([]string, 1),
+	}
+	return table
+}
+
+func Test_resumeTableFromCheckpoint(t *testing.T) {
+	checkpoint := &config.DumpCheckpoint{
+		Gtid:        "00000000-0000-0000-0000-000000000001:1-10",
+		DoneTables:  []string{"dtle_test.t0"},
+		TableSchema: "dtle_test",
+		TableName:   "t_resume",
+		UniqueKey:   "PRIMARY",
+		Iteration:   2,
+		LastMaxVals: []string{"'6'"},
+	}
+
+	table := newResumeTestTable()
+	if !resumeTableFromCheckpoint(table, checkpoint) {
+		t.Fatalf("resumeTableFromCheckpoint() = false, want true")
+	}
+	if table.Iteration != 2 || table.UseUniqueKey.LastMaxVals[0] != "'6'" {
+		t.Errorf("Iteration = %v, LastMaxVals = %v", table.Iteration, table.UseUniqueKey.LastMaxVals)
+	}
+	d := NewDumper(nil, table, 3, logrus.NewEntry(logrus.New()))
+	d.columns = "*"
+	want := "SELECT * FROM `dtle_test`.`t_resume` where (((`id` > '6'))) and (true) order by `id` asc LIMIT 3"
+	if got := d.buildQueryOnUniqueKey(); got != want {
+		t.Errorf("buildQueryOnUniqueKey() = %v, want %v", got, want)
+	}
+
+	// copied from the beginning
+	table = config.NewTable("dtle_test", "t_other")
+	table.UseUniqueKey = newResumeTestTable().UseUniqueKey
+	if resumeTableFromCheckpoint(table, checkpoint) {
+		t.Errorf("resumeTableFromCheckpoint() = true for another table")
+	}
+	table = newResumeTestTable()
+	table.UseUniqueKey.Name = "uk"
+	if resumeTableFromCheckpoint(table, checkpoint) || table.Iteration != 0 {
+		t.Errorf("resumeTableFromCheckpoint() resumed on another unique key")
+	}
+}
+
+func Test_dumper_resume(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := usql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("create database if not exists dtle_test;" +
+		" drop table if exists dtle_test.t_resume;" +
+		" create table dtle_test.t_resume (id int primary key, v varchar(10));" +
+		" insert into dtle_test.t_resume values (1,'a'),(2,'b'),(3,'c'),(4,'d'),(5,'e')," +
+		" (6,'f'),(7,'g'),(8,'h'),(9,'i'),(10,'j')"); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	logger := logrus.NewEntry(logrus.New())
+	start := &config.DumpCheckpoint{
+		Gtid:       "00000000-0000-0000-0000-000000000001:1-10",
+		DoneTables: []string{"dtle_test.t0"},
+	}
+
+	// The dumper is killed after 2 chunks have been applied.
+	d := NewDumper(db, newResumeTestTable(), 3, logger)
+	d.checkpoint = start
+	if err := d.Dump(); err != nil {
+		t.Fatalf("dumper.Dump() error = %v", err)
+	}
+	var checkpoint config.DumpCheckpoint
+	for i := 0; i < 2; i++ {
+		entry := <-d.resultsChannel
+		if entry.Err != "" {
+			t.Fatalf("chunk %v error = %v", i, entry.Err)
+		}
+		if err := json.Unmarshal(entry.Checkpoint, &checkpoint); err != nil {
+			t.Fatalf("chunk %v checkpoint error = %v", i, err)
+		}
+	}
+	d.Close()
+	if checkpoint.Gtid != start.Gtid || !checkpoint.IsTableDone("dtle_test", "t0") ||
+		!checkpoint.IsCurrentTable("dtle_test", "t_resume") || checkpoint.Iteration != 2 ||
+		!reflect.DeepEqual(checkpoint.LastMaxVals, []string{"'6'"}) {
+		t.Fatalf("checkpoint = %+v", checkpoint)
+	}
+
+	// The restarted dumper resumes after the chunks applied, and copies only the rest rows.
+	table := newResumeTestTable()
+	if !resumeTableFromCheckpoint(table, &checkpoint) {
+		t.Fatalf("resumeTableFromCheckpoint() = false, want true")
+	}
+	d = NewDumper(db, table, 3, logger)
+	d.checkpoint = start
+	if err := d.Dump(); err != nil {
+		t.Fatalf("dumper.Dump() error = %v", err)
+	}
+	var ids []string
+	for entry := range d.resultsChannel {
+		if entry.Err != "" {
+			t.Fatalf("error = %v", entry.Err)
+		}
+		for _, row := range entry.ValuesX {
+			ids = append(ids, string(*row[0]))
+		}
+	}
+	if want := []string{"7", "8", "9", "10"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("resumed dump copied ids %v, want %v", ids, want)
+	}
+}
+
+// DECIMAL(65,30) values are read as text by the full copy and written back exactly.
+func Test_dumper_decimal(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := usql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	values := []string{
+		"12345678901234567890123456789012345.123456789012345678901234567890",
+		"-99999999999999999999999999999999999.999999999999999999999999999999",
+		"0.000000000000000000000000000001",
+		"-1.500000000000000000000000000000",
+		"0.000000000000000000000000000000",
+	}
+	query := "create database if not exists dtle_test;" +
+		" drop table if exists dtle_test.t_decimal, dtle_test.t_decimal_dest;" +
+		" create table dtle_test.t_decimal (id int primary key, c decimal(65,30));" +
+		" create table dtle_test.t_decimal_dest like dtle_test.t_decimal;"
+	for i, v := range values {
+		query += fmt.Sprintf(" insert into dtle_test.t_decimal values (%v, '%v');", i, v)
+	}
+	if _, err := db.Exec(query); err != nil {
+		t.Fatal(err)
+	}
+
+	table := config.NewTable("dtle_test", "t_decimal")
+	if table.OriginalTableColumns, err = base.GetTableColumns(db, "dtle_test", "t_decimal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.ApplyColumnTypes(db, "dtle_test", "t_decimal", table.OriginalTableColumns); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDumper(db, table, 100, logrus.NewEntry(logrus.New()))
+	if err := d.prepareForDumping(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.getChunkData(); err != nil {
+		t.Fatalf("dumper.getChunkData() error = %v", err)
+	}
+	entry := <-d.resultsChannel
+	var got []string
+	for _, row := range entry.ValuesX {
+		got = append(got, string(*row[1]))
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("dumped values = %q, want %q", got, values)
+	}
+
+	a := &Applier{
+		logger:       logrus.NewEntry(logrus.New()),
+		db:           db,
+		mysqlContext: (&config.MySQLDriverConfig{}).SetDefault(),
+	}
+	entry.TableName = "t_decimal_dest"
+	if err := a.ApplyEventQueries(db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+	rows, err := db.Query("select cast(c as char) from dtle_test.t_decimal_dest order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	got = nil
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("applied values = %q, want %q", got, values)
+	}
+}
+
+func Test_chunkRowsForBytes(t *testing.T) {
+	tests := []struct {
+		chunkSizeBytes int64
+		rowSize        int64
+		want           int64
+	}{
+		{1024, 100, 10},
+		{1024, 0, 1024},
+		{1024, 4096, 1},
+	}
+	for _, tt := range tests {
+		if got := chunkRowsForBytes(tt.chunkSizeBytes, tt.rowSize); got != tt.want {
+			t.Errorf("chunkRowsForBytes(%v, %v) = %v, want %v", tt.chunkSizeBytes, tt.rowSize, got, tt.want)
+		}
+	}
+}
+
+// With ChunkSizeBytes, no chunk of a table with rows of mixed sizes is too large for the destination,
+// and all rows are copied in the order of the primary key.
+func Test_dumper_chunkSizeBytes(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := usql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	// max_allowed_packet of the destination. The values might be doubled by escaping in the statements.
+	const maxAllowedPacket = 1024 * 1024
+	const chunkSizeBytes = maxAllowedPacket / 2
+	query := "create database if not exists dtle_test;" +
+		" drop table if exists dtle_test.t_chunk_bytes;" +
+		" create table dtle_test.t_chunk_bytes (id int primary key, b mediumblob);"
+	nRows := 40
+	for i := 1; i <= nRows; i++ {
+		size := 100 + 10*i
+		if i%5 == 0 {
+			size = 200 * 1024
+		}
+		query += fmt.Sprintf(" insert into dtle_test.t_chunk_bytes values (%v, repeat('x', %v));", i, size)
+	}
+	if _, err := db.Exec(query); err != nil {
+		t.Fatal(err)
+	}
+
+	table := config.NewTable("dtle_test", "t_chunk_bytes")
+	table.Where = "true"
+	if table.OriginalTableColumns, err = base.GetTableColumns(db, "dtle_test", "t_chunk_bytes"); err != nil {
+		t.Fatal(err)
+	}
+	table.UseUniqueKey = &umconf.UniqueKey{
+		Name:        "PRIMARY",
+		Columns:     *umconf.NewColumnList(umconf.NewColumns([]string{"id"})),
+		LastMaxVals: make([]string, 1),
+	}
+	// ChunkSize alone would copy the table in a chunk of 8MB.
+	d := NewDumper(db, table, 2000, logrus.NewEntry(logrus.New()))
+	d.chunkSizeBytes = chunkSizeBytes
+	if err := d.Dump(); err != nil {
+		t.Fatalf("dumper.Dump() error = %v", err)
+	}
+	var ids []string
+	chunks := 0
+	for entry := range d.resultsChannel {
+		if entry.Err != "" {
+			t.Fatalf("chunk %v error = %v", chunks, entry.Err)
+		}
+		chunks++
+		size := 0
+		for _, row := range entry.ValuesX {
+			size += dumpRowSize(row)
+			ids = append(ids, string(*row[0]))
+		}
+		if 2*size > maxAllowedPacket {
+			t.Errorf("chunk %v of %v rows has %v bytes, more than ChunkSizeBytes %v", chunks, len(entry.ValuesX),
+				size, chunkSizeBytes)
+		}
+	}
+	var want []string
+	for i := 1; i <= nRows; i++ {
+		want = append(want, fmt.Sprint(i))
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("copied ids %v, want %v", ids, want)
+	}
+	if chunks < 4 {
+		t.Errorf("copied in %v chunks, want them cut by ChunkSizeBytes", chunks)
+	}
+}
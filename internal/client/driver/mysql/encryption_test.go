@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func testColumnCipher(t *testing.T, key byte) *binlog.ColumnCipher {
+	c, err := binlog.NewColumnCipher(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{key}, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// The encrypted columns cannot be read in the messages sent to the destinations, and are applied as they were.
+func TestEncryptColumns_binlogEntry(t *testing.T) {
+	c := testColumnCipher(t, 1)
+	var id interface{} = int64(1)
+	var card interface{} = []byte("card-4111111111111111")
+	var name interface{} = "plain-name-alice"
+	var oldCard interface{} = "card-5500000000000004"
+	var null interface{}
+	entries := &binlog.BinlogEntries{Entries: []*binlog.BinlogEntry{{
+		Events: []binlog.DataEvent{{
+			DatabaseName:      "db1",
+			TableName:         "t1",
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&id, &oldCard, &name, &null}},
+			NewColumnValues:   &umconf.ColumnValues{AbstractValues: []*interface{}{&id, &card, &name, &null}},
+			EncryptedColumns:  []int{1, 3},
+		}},
+	}}}
+	want := []interface{}{int64(1), []byte("card-4111111111111111"), "plain-name-alice"}
+	wantWhere := []interface{}{int64(1), "card-5500000000000004", "plain-name-alice"}
+	if err := c.EncryptEvent(&entries.Entries[0].Events[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := Encode(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := snappy.Decode(nil, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"4111111111111111", "5500000000000004"} {
+		if bytes.Contains(msg, []byte(secret)) {
+			t.Errorf("the message contains the encrypted value %v", secret)
+		}
+	}
+	if !bytes.Contains(msg, []byte("plain-name-alice")) {
+		t.Errorf("the message does not contain the value of the column which is not encrypted")
+	}
+
+	got := &binlog.BinlogEntries{}
+	if err := Decode(payload, got); err != nil {
+		t.Fatal(err)
+	}
+	event := &got.Entries[0].Events[0]
+	if err := (&Applier{}).cipher.DecryptEvent(event); err == nil ||
+		!strings.Contains(err.Error(), "EncryptionKey is not set on the destination") {
+		t.Errorf("DecryptEvent() without a key error = %v", err)
+	}
+	if err := testColumnCipher(t, 2).DecryptEvent(event); err == nil {
+		t.Errorf("DecryptEvent() with another key: no error")
+	}
+	if err := c.DecryptEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	if event.EncryptedColumns != nil {
+		t.Errorf("EncryptedColumns = %v, want them cleared", event.EncryptedColumns)
+	}
+	for i, values := range [][]interface{}{wantWhere, want} {
+		row := []*umconf.ColumnValues{event.WhereColumnValues, event.NewColumnValues}[i].AbstractValues
+		for j, v := range values {
+			if !reflect.DeepEqual(*row[j], v) {
+				t.Errorf("row %v column %v = %#v, want %#v", i, j, *row[j], v)
+			}
+		}
+		if *row[3] != nil {
+			t.Errorf("row %v column 3 = %#v, want NULL", i, *row[3])
+		}
+	}
+}
+
+func TestEncryptColumns_dumpEntry(t *testing.T) {
+	c := testColumnCipher(t, 1)
+	value := func(s string) *[]byte {
+		bs := []byte(s)
+		return &bs
+	}
+	d := &dumper{cipher: c, encryptedColumns: []int{1}}
+	entry := &DumpEntry{
+		TableSchema: "db1",
+		TableName:   "t1",
+		ValuesX: [][]*[]byte{
+			{value("1"), value("card-4111111111111111"), value("plain-name-alice")},
+			{value("2"), nil, value("plain-name-bob")},
+			{value("3"), value(""), nil},
+		},
+	}
+	want := [][]*[]byte{
+		{value("1"), value("card-4111111111111111"), value("plain-name-alice")},
+		{value("2"), nil, value("plain-name-bob")},
+		{value("3"), value(""), nil},
+	}
+	if err := d.encryptRows(entry); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entry.EncryptedColumns, []byte{0, 1, 0}) {
+		t.Errorf("EncryptedColumns = %v", entry.EncryptedColumns)
+	}
+
+	payload, err := entry.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(payload, []byte("4111111111111111")) {
+		t.Errorf("the message contains the encrypted value")
+	}
+	if !bytes.Contains(payload, []byte("plain-name-alice")) {
+		t.Errorf("the message does not contain the value of the column which is not encrypted")
+	}
+
+	got, err := DecodeDumpEntry(snappy.Encode(nil, payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Applier{}).decryptRows(got); err == nil {
+		t.Errorf("decryptRows() without a key: no error")
+	}
+	if err := (&Applier{cipher: c}).decryptRows(got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.ValuesX, want) {
+		t.Errorf("ValuesX = %v, want %v", got.ValuesX, want)
+	}
+	if got.EncryptedColumns != nil {
+		t.Errorf("EncryptedColumns = %v, want them cleared", got.EncryptedColumns)
+	}
+}
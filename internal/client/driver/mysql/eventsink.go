@@ -0,0 +1,288 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// An event sink file (EventSinkFile) keeps what a source task sends to the destination tasks, i.e. the chunks
+// of the full copy and the transactions of the incremental copy with their GTIDs, so that a destination task
+// can apply them again without the source (ReplayFile).
+//
+// The file begins with a header of 12 bytes: the magic "DTLESINK" and the format version, a big-endian uint32.
+// A file of another version is refused. In version 1, records follow the header, each of
+//   type     1 byte. see eventSinkFull etc.
+//   length   the length of payload, a big-endian uint32
+//   payload  the message as sent over nats, without the tracing data
+// A record of the incremental copy holds whole transactions.
+
+const (
+	eventSinkMagic   = "DTLESINK"
+	eventSinkVersion = uint32(1)
+)
+
+// record types of an event sink file
+const (
+	eventSinkFull         byte = 'F' // a chunk of the full copy, which is a DumpEntry
+	eventSinkFullComplete byte = 'C' // the end of the full copy, which is a dumpStatResult
+	eventSinkIncr         byte = 'I' // transactions of the incremental copy, which is a binlog.BinlogEntries
+)
+
+// eventSinkRecordType returns the record type of the messages sent to a nats subject.
+// ok is false if they are not kept.
+func eventSinkRecordType(subject string) (recordType byte, ok bool) {
+	switch {
+	case strings.HasSuffix(subject, "_full"):
+		return eventSinkFull, true
+	case strings.HasSuffix(subject, "_full_complete"):
+		return eventSinkFullComplete, true
+	case strings.HasSuffix(subject, "_incr_hete"):
+		return eventSinkIncr, true
+	default:
+		return 0, false
+	}
+}
+
+type eventSink struct {
+	fileName string
+	lock     sync.Mutex
+	file     *os.File
+}
+
+// openEventSink opens an event sink file for appending, which is created if it does not exist.
+// A partial record at the end, e.g. after a crash, is truncated.
+func openEventSink(fileName string) (s *eventSink, err error) {
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var end int64
+	if info.Size() == 0 {
+		header := make([]byte, len(eventSinkMagic)+4)
+		copy(header, eventSinkMagic)
+		binary.BigEndian.PutUint32(header[len(eventSinkMagic):], eventSinkVersion)
+		if _, err := file.Write(header); err != nil {
+			return nil, err
+		}
+		end = int64(len(header))
+	} else {
+		r, err := newEventSinkReader(file)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			_, _, err := r.next()
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		end = r.offset
+		if end < info.Size() {
+			if err := file.Truncate(end); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &eventSink{fileName: fileName, file: file}, nil
+}
+
+// write appends a record. It is safe for concurrent use.
+func (s *eventSink) write(recordType byte, payload []byte) error {
+	buf := make([]byte, 5+len(payload))
+	buf[0] = recordType
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(payload)))
+	copy(buf[5:], payload)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err := s.file.Write(buf)
+	return err
+}
+
+func (s *eventSink) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
+
+type eventSinkReader struct {
+	r *bufio.Reader
+	// the end of the last complete record
+	offset int64
+}
+
+// newEventSinkReader reads and checks the header of an event sink file.
+func newEventSinkReader(r io.Reader) (*eventSinkReader, error) {
+	header := make([]byte, len(eventSinkMagic)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("not an event sink file: %v", err)
+	}
+	if string(header[:len(eventSinkMagic)]) != eventSinkMagic {
+		return nil, fmt.Errorf("not an event sink file")
+	}
+	if version := binary.BigEndian.Uint32(header[len(eventSinkMagic):]); version != eventSinkVersion {
+		return nil, fmt.Errorf("unsupported event sink file version %v. expect %v", version, eventSinkVersion)
+	}
+	return &eventSinkReader{r: bufio.NewReader(r), offset: int64(len(header))}, nil
+}
+
+// next returns the next record. err is io.EOF at the end of the file,
+// and io.ErrUnexpectedEOF if the last record is partial.
+func (r *eventSinkReader) next() (recordType byte, payload []byte, err error) {
+	head := make([]byte, 5)
+	if n, err := io.ReadFull(r.r, head); err != nil {
+		if err == io.EOF && n == 0 {
+			return 0, nil, io.EOF
+		}
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	payload = make([]byte, binary.BigEndian.Uint32(head[1:5]))
+	if _, err := io.ReadFull(r.r, payload); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	switch head[0] {
+	case eventSinkFull, eventSinkFullComplete, eventSinkIncr:
+	default:
+		return 0, nil, fmt.Errorf("unknown record type %q at offset %v", head[0], r.offset)
+	}
+	r.offset += int64(len(head) + len(payload))
+	return head[0], payload, nil
+}
+
+// writeEventSink keeps a message sent to the destinations in EventSinkFile, if set.
+func (e *Extractor) writeEventSink(subject string, txMsg []byte) error {
+	if e.eventSink == nil {
+		return nil
+	}
+	recordType, ok := eventSinkRecordType(subject)
+	if !ok {
+		return nil
+	}
+	if err := e.eventSink.write(recordType, txMsg); err != nil {
+		return fmt.Errorf("write to EventSinkFile %v: %v", e.eventSink.fileName, err)
+	}
+	return nil
+}
+
+// initiateReplay applies the data in ReplayFile instead of receiving it from the source task.
+func (a *Applier) initiateReplay() error {
+	file, err := os.Open(a.mysqlContext.ReplayFile)
+	if err != nil {
+		return fmt.Errorf("bad job argument: ReplayFile: %v", err)
+	}
+	r, err := newEventSinkReader(file)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("bad job argument: ReplayFile %v: %v", a.mysqlContext.ReplayFile, err)
+	}
+	a.logger.Infof("mysql.applier: replaying %v", a.mysqlContext.ReplayFile)
+	a.mysqlContext.MarkRowCopyStartTime()
+
+	go a.heterogeneousReplay()
+	go func() {
+		defer file.Close()
+		n, err := a.replayEvents(r)
+		if err != nil {
+			a.onError(TaskStateDead, fmt.Errorf("replay %v: %v", a.mysqlContext.ReplayFile, err))
+			return
+		}
+		a.logger.Infof("mysql.applier: replayed %v records of %v", n, a.mysqlContext.ReplayFile)
+	}()
+	return nil
+}
+
+// replayEvents queues the records of an event sink file as if they were received from the source task,
+// until the end of the file or shutdown. n is the number of records read.
+func (a *Applier) replayEvents(r *eventSinkReader) (n int, err error) {
+	span := opentracing.GlobalTracer().StartSpan("dest replay an event sink file")
+	defer span.Finish()
+
+	for ; ; n++ {
+		recordType, payload, err := r.next()
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+
+		switch recordType {
+		case eventSinkFull:
+			dumpData, err := DecodeDumpEntry(payload)
+			if err != nil {
+				return n, err
+			}
+			if dumpData.TableSchema != "" &&
+				!config.MatchDestDoDb(a.mysqlContext.DestDoDb, dumpData.TableSchema, dumpData.TableName) {
+				continue
+			}
+			atomic.AddInt64(&a.nDumpEntry, 1) // this must be increased before enqueuing
+			select {
+			case a.copyRowsQueue <- dumpData:
+				a.mysqlContext.Stage = models.StageSlaveWaitingForWorkersToProcessQueue
+				atomic.AddInt64(&a.mysqlContext.RowsEstimate, dumpData.TotalCount)
+			case <-a.shutdownCh:
+				return n, nil
+			}
+		case eventSinkFullComplete:
+			dumpData := &dumpStatResult{}
+			if err := Decode(payload, dumpData); err != nil {
+				return n, err
+			}
+			if !a.onFullComplete(dumpData) {
+				return n, nil
+			}
+		case eventSinkIncr:
+			var binlogEntries binlog.BinlogEntries
+			if err := Decode(payload, &binlogEntries); err != nil {
+				return n, err
+			}
+			for _, binlogEntry := range binlogEntries.Entries {
+				binlogEntry.SpanContext = span.Context()
+				select {
+				case a.applyDataEntryQueue <- binlogEntry:
+					a.currentCoordinates.RetrievedGtidSet = binlogEntry.Coordinates.GetGtidForThisTx()
+					atomic.AddInt64(&a.mysqlContext.DeltaEstimate, 1)
+				case <-a.shutdownCh:
+					return n, nil
+				}
+			}
+			a.mysqlContext.Stage = models.StageWaitingForMasterToSendEvent
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func readEventSinkFile(t *testing.T, fileName string) (recordTypes string, payloads []string) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	r, err := newEventSinkReader(file)
+	if err != nil {
+		t.Fatalf("newEventSinkReader() error = %v", err)
+	}
+	for {
+		recordType, payload, err := r.next()
+		if err == io.EOF {
+			return recordTypes, payloads
+		} else if err != nil {
+			t.Fatalf("next() error = %v", err)
+		}
+		recordTypes += string(recordType)
+		payloads = append(payloads, string(payload))
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := path.Join(dir, "job.sink")
+
+	s, err := openEventSink(fileName)
+	if err != nil {
+		t.Fatalf("openEventSink() error = %v", err)
+	}
+	e := &Extractor{eventSink: s}
+	for _, msg := range []struct{ subject, payload string }{
+		{"job_full", "chunk1"},
+		{"job_progress", "not kept"},
+		{"job_full_complete", "complete"},
+	} {
+		if err := e.writeEventSink(msg.subject, []byte(msg.payload)); err != nil {
+			t.Fatalf("writeEventSink() error = %v", err)
+		}
+	}
+	s.Close()
+
+	// A restarted task appends to the file. A partial record is dropped.
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte{eventSinkIncr, 0, 0, 0, 100, 'x'})
+	file.Close()
+	if s, err = openEventSink(fileName); err != nil {
+		t.Fatalf("openEventSink() error = %v", err)
+	}
+	if err := s.write(eventSinkIncr, []byte("tx1")); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	s.Close()
+
+	recordTypes, payloads := readEventSinkFile(t, fileName)
+	if want := "FCI"; recordTypes != want {
+		t.Errorf("record types = %v, want %v", recordTypes, want)
+	}
+	if want := "chunk1,complete,tx1"; strings.Join(payloads, ",") != want {
+		t.Errorf("payloads = %v, want %v", payloads, want)
+	}
+
+	header := []byte(eventSinkMagic + "\x00\x00\x00\x00")
+	binary.BigEndian.PutUint32(header[len(eventSinkMagic):], eventSinkVersion+1)
+	if _, err := newEventSinkReader(bytes.NewReader(header)); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("newEventSinkReader() of another version, error = %v", err)
+	}
+	if _, err := newEventSinkReader(strings.NewReader("not a sink file")); err == nil {
+		t.Error("newEventSinkReader() of another file, want an error")
+	}
+}
+
+func TestApplier_replayEvents(t *testing.T) {
+	var buf bytes.Buffer
+	header := []byte(eventSinkMagic + "\x00\x00\x00\x00")
+	binary.BigEndian.PutUint32(header[len(eventSinkMagic):], eventSinkVersion)
+	buf.Write(header)
+	writeRecord := func(recordType byte, payload []byte) {
+		head := []byte{recordType, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(head[1:], uint32(len(payload)))
+		buf.Write(head)
+		buf.Write(payload)
+	}
+
+	for _, table := range []string{"t1", "ignored"} {
+		bs, err := (&DumpEntry{TableSchema: "db1", TableName: table, TotalCount: 2}).Marshal(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeRecord(eventSinkFull, snappy.Encode(nil, bs))
+	}
+	complete, err := Encode(&dumpStatResult{Gtid: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10", TotalCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeRecord(eventSinkFullComplete, complete)
+	sid := uuid.NewV4()
+	for _, gnos := range [][]int64{{11, 12}, {13}} {
+		var entries binlog.BinlogEntries
+		for _, gno := range gnos {
+			entries.Entries = append(entries.Entries, &binlog.BinlogEntry{Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: gno}})
+		}
+		txMsg, err := Encode(&entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeRecord(eventSinkIncr, txMsg)
+	}
+
+	cfg := &config.MySQLDriverConfig{
+		DestDoDb: []*config.DataSource{{TableSchema: "db1", Tables: []*config.Table{{TableName: "t1"}}}},
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	r, err := newEventSinkReader(&buf)
+	if err != nil {
+		t.Fatalf("newEventSinkReader() error = %v", err)
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := a.replayEvents(r)
+		done <- result{n, err}
+	}()
+
+	select {
+	case entry := <-a.copyRowsQueue:
+		if entry.TableName != "t1" {
+			t.Errorf("full copy of %v.%v, want db1.t1", entry.TableSchema, entry.TableName)
+		}
+		// applied
+		atomic.AddInt64(&a.nDumpEntry, -1)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the full copy")
+	}
+	var gnos []int64
+	for len(gnos) < 3 {
+		select {
+		case entry := <-a.applyDataEntryQueue:
+			if atomic.LoadInt64(&a.rowCopyCompleteFlag) != 1 {
+				t.Error("a transaction is queued before the full copy completes")
+			}
+			gnos = append(gnos, entry.Coordinates.GNO)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout waiting for the transactions. got %v", gnos)
+		}
+	}
+	if gnos[0] != 11 || gnos[1] != 12 || gnos[2] != 13 {
+		t.Errorf("transactions = %v, want 11, 12, 13", gnos)
+	}
+	if got := <-done; got.err != nil || got.n != 5 {
+		t.Errorf("replayEvents() = %v, %v, want 5 records", got.n, got.err)
+	}
+	if got := a.currentCoordinates.RetrievedGtidSet; got != sid.String()+":13" {
+		t.Errorf("RetrievedGtidSet = %v", got)
+	}
+	if got := len(a.copyRowsQueue); got != 0 {
+		t.Errorf("%v more chunks of the full copy are queued, want the one not in DestDoDb skipped", got)
+	}
+}
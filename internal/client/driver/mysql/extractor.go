@@ -19,6 +19,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
 
 	//"math"
 	"bytes"
@@ -33,7 +34,6 @@ import (
 	"github.com/golang/snappy"
 	gonats "github.com/nats-io/go-nats"
 	"github.com/nats-io/not.go"
-	gomysql "github.com/siddontang/go-mysql/mysql"
 
 	"os"
 
@@ -74,8 +74,12 @@ type Extractor struct {
 
 	mysqlVersionDigit int
 	db                *gosql.DB
-	singletonDB       *gosql.DB
-	dumpers           []*dumper
+	// by AdminUser, for schema queries and checksum. It is db if AdminUser is not set.
+	adminDB     *gosql.DB
+	singletonDB *gosql.DB
+	tunnel      *sql.Tunnel
+	dumpers     []*dumper
+	dumpersLock sync.Mutex
 	// db.tb exists when creating the job, for full-copy.
 	// vs e.mysqlContext.ReplicateDoDb: all user assigned db.tb
 	replicateDoDb            []*config.DataSource
@@ -91,6 +95,45 @@ type Extractor struct {
 
 	sendByTimeoutCounter  int
 	sendBySizeFullCounter int
+	// the BinlogEntries.Stream of this run
+	incrStream string
+	// nil if InFlightWindow is not set
+	inFlight *inFlightWindow
+	// nil if SemiSync is not set
+	semiSync *binlog.SemiSyncTracker
+	// nil if neither ThrottleMemoryPct nor ThrottleCPUPct is set
+	throttler *resourceThrottler
+	// by DumpMsgCompression
+	compressDump dumpCompressor
+	// by EncryptionKey. nil if it is not set
+	cipher *binlog.ColumnCipher
+
+	recentErrors *recentErrors
+	// the error the task stops with. See CheckHealth.
+	failure latchedError
+	// nil if EventSinkFile is not set
+	eventSink *eventSink
+	// server_uuid of the source. See handleSourceFailover.
+	sourceUUID string
+
+	// ReplicateDoDb as configured, in JSON, to tell its changes. See updateReplicateDoDb.
+	replicateDoDbConfig []byte
+	// protects replicateDoDb once the incremental copy has started
+	replicateDoDbLock sync.Mutex
+	// set while tables added to ReplicateDoDb are being copied
+	addingTables int32
+	// the ID of the last ResyncTable taken. See UpdateConfig.
+	resyncID string
+	// 1 while a table is being resynced
+	resyncing int32
+	// passes a started resync to sendIncrEntries
+	resyncCh chan *tableResync
+	// 1 once sendIncrEntries runs
+	incrStarted int32
+	// "schema.table" of the tables not fully copied as the full copy stopped at MaxDumpDuration.
+	// See onDumpIncomplete.
+	incompleteTables     []string
+	incompleteTablesLock sync.Mutex
 
 	natsConn *gonats.Conn
 	waitCh   chan *models.WaitResult
@@ -112,9 +155,7 @@ type Extractor struct {
 func NewExtractor(execCtx *common.ExecContext, cfg *config.MySQLDriverConfig, logger *logrus.Logger) (*Extractor, error) {
 
 	cfg = cfg.SetDefault()
-	entry := logger.WithFields(logrus.Fields{
-		"job": execCtx.Subject,
-	})
+	entry := logger.WithFields(execCtx.LogFields())
 	e := &Extractor{
 
 		logger:          entry,
@@ -131,8 +172,30 @@ func NewExtractor(execCtx *common.ExecContext, cfg *config.MySQLDriverConfig, lo
 		gotCoordinateCh: make(chan struct{}),
 		streamerReadyCh: make(chan error),
 		fullCopyDone:    make(chan struct{}),
+		incrStream:      uuid.NewV4().String(),
+		inFlight:        newInFlightWindow(cfg.InFlightWindow, cfg.DestCount),
+		throttler:       newResourceThrottler(cfg, cgroupRoot, entry),
+		recentErrors:    getRecentErrors(execCtx.Subject, execCtx.TaskType, cfg.MaxRecentErrors),
+		resyncCh:        make(chan *tableResync),
+	}
+	var err error
+	if e.compressDump, err = newDumpCompressor(cfg.DumpMsgCompression, cfg.DumpMsgCompressionLevel); err != nil {
+		return nil, err
+	}
+	if e.cipher, err = binlog.NewColumnCipher(cfg.EncryptionKey); err != nil {
+		return nil, err
 	}
 	e.context.LoadSchemas(nil)
+	if e.replicateDoDbConfig, err = json.Marshal(cfg.ReplicateDoDb); err != nil {
+		return nil, err
+	}
+	if cfg.SemiSync {
+		e.semiSync = binlog.NewSemiSyncTracker(cfg.DestCount)
+	}
+	if cfg.ResyncTable != nil {
+		// requested before the start of the task, which might have been restarted since.
+		e.resyncID = cfg.ResyncTable.ID
+	}
 
 	if delay, err := strconv.ParseInt(os.Getenv(g.ENV_TESTSTUB1_DELAY), 10, 64); err == nil {
 		e.logger.Infof("%v = %v", g.ENV_TESTSTUB1_DELAY, delay)
@@ -189,8 +252,38 @@ func (e *Extractor) Run() {
 				fmt.Errorf("conflicting job argument: SkipCreateDbTable=true and DropTableIfExists=true"))
 			return
 		}
+		switch e.mysqlContext.OnPurgedGtid {
+		case config.OnPurgedGtidError, config.OnPurgedGtidEarliest, config.OnPurgedGtidRestartDump:
+			e.logger.Infof("mysql.extractor: OnPurgedGtid: %v", e.mysqlContext.OnPurgedGtid)
+		default:
+			e.onError(TaskStateDead, fmt.Errorf("bad job argument: OnPurgedGtid=%v. should be one of %v, %v, %v",
+				e.mysqlContext.OnPurgedGtid,
+				config.OnPurgedGtidError, config.OnPurgedGtidEarliest, config.OnPurgedGtidRestartDump))
+			return
+		}
+		if !*e.mysqlContext.ReplicateDML && !*e.mysqlContext.ReplicateDDL {
+			e.onError(TaskStateDead,
+				fmt.Errorf("bad job argument: at least one of ReplicateDML and ReplicateDDL should be true"))
+			return
+		}
+		if e.mysqlContext.DestCount > 1 && e.mysqlContext.BinlogRelay {
+			e.onError(TaskStateDead,
+				fmt.Errorf("conflicting job argument: BinlogRelay=true and %v destination tasks", e.mysqlContext.DestCount))
+			return
+		}
+	}
+
+	if e.throttler != nil {
+		go e.throttler.run(e.shutdownCh)
 	}
 
+	tunnel, err := sql.NewTunnel(e.mysqlContext.ConnectionConfig, e.logger)
+	if err != nil {
+		e.onError(TaskStateDead, fmt.Errorf("failed to establish the tunnel to the source: %v", err))
+		return
+	}
+	e.tunnel = tunnel
+
 	if err := e.initiateInspector(); err != nil {
 		e.onError(TaskStateDead, err)
 		return
@@ -199,6 +292,13 @@ func (e *Extractor) Run() {
 		e.onError(TaskStateDead, err)
 		return
 	}
+	if e.mysqlContext.EventSinkFile != "" {
+		if e.eventSink, err = openEventSink(e.mysqlContext.EventSinkFile); err != nil {
+			e.onError(TaskStateDead, fmt.Errorf("EventSinkFile %v: %v", e.mysqlContext.EventSinkFile, err))
+			return
+		}
+		e.logger.Infof("mysql.extractor: writing the data sent to the destinations to %v", e.mysqlContext.EventSinkFile)
+	}
 	if err := e.initDBConnections(); err != nil {
 		e.onError(TaskStateDead, err)
 		return
@@ -206,9 +306,21 @@ func (e *Extractor) Run() {
 
 	fullCopy := true
 
+	if e.mysqlContext.Gtid == "" && e.mysqlContext.MysqldumpCoordinate != "" {
+		if err := e.useMysqldumpCoordinate(); err != nil {
+			e.onError(TaskStateDead, err)
+			return
+		}
+	}
+
 	if e.mysqlContext.Gtid == "" {
-		if e.mysqlContext.AutoGtid {
-			coord, err := base.GetSelfBinlogCoordinates(e.db)
+		// A DDL-only job does not copy data. Start from the current position.
+		ddlOnly := !*e.mysqlContext.ReplicateDML
+		if ddlOnly {
+			e.logger.Infof("mysql.extractor: ReplicateDML=false. skip full copy")
+		}
+		if e.mysqlContext.AutoGtid || ddlOnly {
+			coord, err := e.getSelfBinlogCoordinates()
 			if err != nil {
 				e.onError(TaskStateDead, err)
 				return
@@ -355,6 +467,9 @@ func (e *Extractor) initiateInspector() (err error) {
 }
 
 func (e *Extractor) inspectTables() (err error) {
+	if err := e.validateTableNameRegexes(); err != nil {
+		return err
+	}
 	// Creates a MYSQL Dump based on the options supplied through the dumper.
 	if len(e.mysqlContext.ReplicateDoDb) > 0 {
 		var doDbs []*config.DataSource
@@ -366,7 +481,7 @@ func (e *Extractor) inspectTables() (err error) {
 			var regex string
 			if doDb.TableSchemaRegex != "" && doDb.TableSchemaRename != "" && doDb.TableSchema == "" {
 				regex = doDb.TableSchemaRegex
-				dbs, err := sql.ShowDatabases(e.db)
+				dbs, err := sql.ShowDatabasesExcept(e.adminDB, e.mysqlContext.ExcludedSystemSchemas())
 				if err != nil {
 					return err
 				}
@@ -392,6 +507,11 @@ func (e *Extractor) inspectTables() (err error) {
 					return fmt.Errorf("src schmea  was nil")
 				}
 			} else if doDb.TableSchemaRegex == "" {
+				if e.mysqlContext.IsExcludedSystemSchema(doDb.TableSchema) {
+					e.logger.Warnf("mysql.extractor: system schema %v is not replicated. add it to ReplicateSystemSchemas to replicate it",
+						doDb.TableSchema)
+					continue
+				}
 				doDb.TableSchemaScope = SCHEMA
 				doDbs = append(doDbs, doDb)
 			} else {
@@ -408,7 +528,7 @@ func (e *Extractor) inspectTables() (err error) {
 				TableSchemaRenameRegex: doDb.TableSchemaRenameRegex,
 			}
 			if len(doDb.Tables) == 0 { // replicate all tables
-				tbs, err := sql.ShowTables(e.db, doDb.TableSchema, e.mysqlContext.ExpandSyntaxSupport)
+				tbs, err := sql.ShowTables(e.adminDB, doDb.TableSchema, e.mysqlContext.ExpandSyntaxSupport)
 				if err != nil {
 					return err
 				}
@@ -429,11 +549,24 @@ func (e *Extractor) inspectTables() (err error) {
 						doTb.Where = "true"
 					}
 
+					if config.IsTableNameRegex(doTb.TableName) {
+						tables, err := e.expandTablePattern(doDb.TableSchema, doTb)
+						if err != nil {
+							return err
+						}
+						e.logger.Infof("mysql.extractor: table %v.%v matches %v tables",
+							doDb.TableSchema, doTb.TableName, len(tables))
+						db.Tables = append(db.Tables, tables...)
+						db.TablePatterns = append(db.TablePatterns, doTb)
+						db.TableSchemaScope = TABLE
+						continue
+					}
+
 					var regex string
 					if doTb.TableRegex != "" && doTb.TableName == "" && doTb.TableRename != "" {
 						regex = doTb.TableRegex
 						db.TableSchemaScope = TABLES
-						tables, err := sql.ShowTables(e.db, doDb.TableSchema, e.mysqlContext.ExpandSyntaxSupport)
+						tables, err := sql.ShowTables(e.adminDB, doDb.TableSchema, e.mysqlContext.ExpandSyntaxSupport)
 						if err != nil {
 							return err
 						}
@@ -486,7 +619,7 @@ func (e *Extractor) inspectTables() (err error) {
 		}
 		e.mysqlContext.ReplicateDoDb = e.replicateDoDb
 	} else { // empty DoDB. replicate all db/tb
-		dbs, err := sql.ShowDatabases(e.db)
+		dbs, err := sql.ShowDatabasesExcept(e.adminDB, e.mysqlContext.ExcludedSystemSchemas())
 		if err != nil {
 			return err
 		}
@@ -499,7 +632,7 @@ func (e *Extractor) inspectTables() (err error) {
 				continue
 			}
 
-			tbs, err := sql.ShowTables(e.db, dbName, e.mysqlContext.ExpandSyntaxSupport)
+			tbs, err := sql.ShowTables(e.adminDB, dbName, e.mysqlContext.ExpandSyntaxSupport)
 			if err != nil {
 				return err
 			}
@@ -543,8 +676,9 @@ func (e *Extractor) inspectTables() (err error) {
 }
 
 func (e *Extractor) ignoreDb(dbName string) bool {
+	caseInsensitive := e.mysqlContext.LowerCaseTableNames != 0
 	for _, ignoreDb := range e.mysqlContext.ReplicateIgnoreDb {
-		if ignoreDb.TableSchema == dbName && len(ignoreDb.Tables) == 0 {
+		if config.MatchTableName(ignoreDb.TableSchema, dbName, caseInsensitive) && len(ignoreDb.Tables) == 0 {
 			return true
 		}
 	}
@@ -552,10 +686,11 @@ func (e *Extractor) ignoreDb(dbName string) bool {
 }
 
 func (e *Extractor) ignoreTb(dbName, tbName string) bool {
+	caseInsensitive := e.mysqlContext.LowerCaseTableNames != 0
 	for _, ignoreDb := range e.mysqlContext.ReplicateIgnoreDb {
-		if ignoreDb.TableSchema == dbName {
+		if config.MatchTableName(ignoreDb.TableSchema, dbName, caseInsensitive) {
 			for _, ignoreTb := range ignoreDb.Tables {
-				if ignoreTb.TableName == tbName {
+				if config.MatchTableName(ignoreTb.TableName, tbName, caseInsensitive) {
 					return true
 				}
 			}
@@ -564,6 +699,37 @@ func (e *Extractor) ignoreTb(dbName, tbName string) bool {
 	return false
 }
 
+// validateTableNameRegexes checks the table names with config.TableNameRegexPrefix in ReplicateDoDb and ReplicateIgnoreDb.
+func (e *Extractor) validateTableNameRegexes() error {
+	errs := validateTableNameRegexes(e.mysqlContext.LowerCaseTableNames != 0,
+		e.mysqlContext.ReplicateDoDb, e.mysqlContext.ReplicateIgnoreDb)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// expandTablePattern returns the tables in schema matching pattern, a table with a regex name.
+func (e *Extractor) expandTablePattern(schema string, pattern *config.Table) (tables []*config.Table, err error) {
+	tbs, err := sql.ShowTables(e.adminDB, schema, e.mysqlContext.ExpandSyntaxSupport)
+	if err != nil {
+		return nil, err
+	}
+	caseInsensitive := e.mysqlContext.LowerCaseTableNames != 0
+	for _, tb := range tbs {
+		if !config.MatchTableName(pattern.TableName, tb.TableName, caseInsensitive) {
+			continue
+		}
+		table := config.NewTableFromPattern(pattern, tb.TableName, caseInsensitive)
+		if err := e.inspector.ValidateOriginalTable(schema, table.TableName, table); err != nil {
+			e.logger.Warnf("mysql.extractor: %v", err)
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
 // readTableColumns reads table columns on applier
 func (e *Extractor) readTableColumns() (err error) {
 	e.logger.Printf("mysql.extractor: Examining table structure on extractor")
@@ -581,8 +747,8 @@ func (e *Extractor) readTableColumns() (err error) {
 }
 
 func (e *Extractor) initNatsPubClient() (err error) {
-	natsAddr := fmt.Sprintf("nats://%s", e.mysqlContext.NatsAddr)
-	sc, err := gonats.Connect(natsAddr)
+	natsAddr := NatsURL(e.mysqlContext.NatsAddr)
+	sc, err := ConnectNats(e.mysqlContext.NatsAddr, e.execCtx, e.logger)
 	if err != nil {
 		e.logger.Errorf("mysql.extractor: Can't connect nats server %v. make sure a nats streaming server is running.%v", natsAddr, err)
 		return err
@@ -619,24 +785,55 @@ func (e *Extractor) initiateStreaming() error {
 		if err != nil {
 			e.onError(TaskStateDead, err)
 		}
+
+		if e.inFlight != nil || e.semiSync != nil {
+			if err := e.subscribeIncrAck(); err != nil {
+				e.onError(TaskStateDead, err)
+			}
+		}
 	}()
 	return nil
 }
 
 //--EventsStreamer--
 func (e *Extractor) initDBConnections() (err error) {
-	eventsStreamerUri := e.mysqlContext.ConnectionConfig.GetDBUri()
+	if _, err := e.mysqlContext.TimeZoneLocation(); err != nil {
+		return err
+	}
+	eventsStreamerUri := sql.UriWithTimeZone(e.mysqlContext.ConnectionConfig.GetDBUri(), e.mysqlContext.TimeZone)
+	if eventsStreamerUri, err = sql.UriWithSessionVars(eventsStreamerUri, e.mysqlContext.SrcSessionVars); err != nil {
+		return fmt.Errorf("bad job argument: SrcSessionVars: %v", err)
+	}
 	if e.db, err = sql.CreateDB(eventsStreamerUri); err != nil {
 		return err
 	}
+	if len(e.mysqlContext.SrcSessionVars) > 0 {
+		if err := e.db.Ping(); err != nil {
+			return fmt.Errorf("SrcSessionVars: %v", err)
+		}
+	}
 
 	if err := e.validateConnectionAndGetVersion(); err != nil {
 		return err
 	}
+	if err := e.initAdminDB(); err != nil {
+		return err
+	}
+	if err := e.validateMariadb(); err != nil {
+		return err
+	}
+	if err := e.validateAurora(); err != nil {
+		return err
+	}
+	if err := e.validateReplicaSource(); err != nil {
+		return err
+	}
 
 	{
 		getTxIsolationVarName := func(mysqlVersionDigit int) string {
-			if mysqlVersionDigit >= 50720 {
+			if e.isMariaDB() {
+				return "tx_isolation" // transaction_isolation is an alias since MariaDB 11.1
+			} else if mysqlVersionDigit >= 50720 {
 				return "transaction_isolation"
 			} else {
 				return "tx_isolation" // deprecated and removed in MySQL 8.0
@@ -645,6 +842,10 @@ func (e *Extractor) initDBConnections() (err error) {
 		// https://github.com/go-sql-driver/mysql#system-variables
 		dumpUri := fmt.Sprintf("%s&%s='REPEATABLE-READ'", e.mysqlContext.ConnectionConfig.GetSingletonDBUri(),
 			getTxIsolationVarName(e.mysqlVersionDigit))
+		dumpUri = sql.UriWithTimeZone(dumpUri, e.mysqlContext.TimeZone)
+		if dumpUri, err = sql.UriWithSessionVars(dumpUri, e.mysqlContext.SrcSessionVars); err != nil {
+			return err
+		}
 		if e.singletonDB, err = sql.CreateDB(dumpUri); err != nil {
 			return err
 		}
@@ -657,6 +858,35 @@ func (e *Extractor) initDBConnections() (err error) {
 	return nil
 }
 
+// initAdminDB connects by AdminUser if it is set, and checks the grants of User for replicating,
+// which are not checked by the inspector then.
+func (e *Extractor) initAdminDB() (err error) {
+	if e.mysqlContext.ConnectionConfig.AdminUser == "" {
+		e.adminDB = e.db
+		return nil
+	}
+	if !e.mysqlContext.SkipPrivilegeCheck {
+		grants, err := readSourceGrants(e.db)
+		if err != nil {
+			return err
+		}
+		e.mysqlContext.HasSuperPrivilege = grants.super
+		if err := grants.validateReplication(e.logger); err != nil {
+			return err
+		}
+	}
+	adminUri := sql.UriWithTimeZone(e.mysqlContext.ConnectionConfig.AdminConfig().GetDBUri(), e.mysqlContext.TimeZone)
+	if adminUri, err = sql.UriWithSessionVars(adminUri, e.mysqlContext.SrcSessionVars); err != nil {
+		return err
+	}
+	if e.adminDB, err = sql.CreateDB(adminUri); err != nil {
+		return err
+	}
+	e.logger.Infof("mysql.extractor: using AdminUser %v for schema queries and checksum",
+		e.mysqlContext.ConnectionConfig.AdminUser)
+	return nil
+}
+
 func (e *Extractor) getSchemaTablesAndMeta() error {
 	if err := e.inspectTables(); err != nil {
 		return err
@@ -677,7 +907,7 @@ func (e *Extractor) getSchemaTablesAndMeta() error {
 				continue
 			}
 
-			stmts, err := base.ShowCreateTable(e.db, db.TableSchema, tb.TableName, false, false)
+			stmts, err := base.ShowCreateTable(e.adminDB, db.TableSchema, tb.TableName, false, false)
 			if err != nil {
 				e.logger.Errorf("error at ShowCreateTable. err: %v", err)
 				return err
@@ -706,7 +936,7 @@ func (e *Extractor) getSchemaTablesAndMeta() error {
 // initBinlogReader creates and connects the reader: we hook up to a MySQL server as a replica
 // Cooperate with `initiateStreaming()` using `e.streamerReadyCh`. Any err will be sent thru the chan.
 func (e *Extractor) initBinlogReader(binlogCoordinates *base.BinlogCoordinatesX) {
-	binlogReader, err := binlog.NewMySQLReader(e.execCtx, e.mysqlContext, e.logger, e.replicateDoDb, e.context)
+	binlogReader, err := e.newBinlogReader()
 	if err != nil {
 		e.logger.Debugf("mysql.extractor: err at initBinlogReader: NewMySQLReader: %v", err.Error())
 		e.streamerReadyCh <- err
@@ -725,6 +955,100 @@ func (e *Extractor) initBinlogReader(binlogCoordinates *base.BinlogCoordinatesX)
 	}()
 }
 
+// newBinlogReader creates a binlog reader of the source.
+func (e *Extractor) newBinlogReader() (*binlog.BinlogReader, error) {
+	binlogReader, err := binlog.NewMySQLReader(e.execCtx, e.mysqlContext, e.logger, e.replicateDoDb, e.context)
+	if err != nil {
+		return nil, err
+	}
+	if e.semiSync != nil {
+		binlogReader.SetSemiSync(e.semiSync)
+	}
+	return binlogReader, nil
+}
+
+// handleBinlogPurged applies OnPurgedGtid if the source refuses the binlog dump request
+// because the requested binlog has been purged. Other errors are returned as is.
+// It returns nil if streaming should go on with the reconnected binlog reader.
+func (e *Extractor) handleBinlogPurged(streamErr error) error {
+	if !binlog.IsBinlogPurgedError(streamErr) {
+		return streamErr
+	}
+	policy := e.mysqlContext.OnPurgedGtid
+	e.logger.Errorf("mysql.extractor: required binlog has been purged on the source. OnPurgedGtid: %v. err: %v",
+		policy, streamErr)
+
+	switch policy {
+	case config.OnPurgedGtidEarliest:
+		coordinates, lost, err := base.GetEarliestBinlogCoordinates(e.db, e.initialBinlogCoordinates.GtidSet)
+		if err != nil {
+			return err
+		}
+		e.logger.Warnf("mysql.extractor: OnPurgedGtid=%v. resuming from the earliest available binlog"+
+			" (file %v pos %v gtid %v). DATA LOSS: purged transactions are skipped: %v",
+			policy, coordinates.LogFile, coordinates.LogPos, coordinates.GtidSet, lost)
+		e.execCtx.Emit("binlog purged on the source. resuming from the earliest available binlog."+
+			" skipped (lost) transactions: %v", lost)
+
+		e.binlogReader.Close()
+		binlogReader, err := e.newBinlogReader()
+		if err != nil {
+			return err
+		}
+		e.binlogReader = binlogReader
+		e.initialBinlogCoordinates = coordinates
+		return binlogReader.ConnectBinlogStreamer(*coordinates)
+	case config.OnPurgedGtidRestartDump:
+		e.logger.Warnf("mysql.extractor: OnPurgedGtid=%v. restarting the job with a full dump", policy)
+		e.execCtx.Emit("binlog purged on the source. restarting the job with a full dump")
+
+		e.mysqlContext.Gtid = ""
+		e.mysqlContext.BinlogFile = ""
+		e.mysqlContext.BinlogPos = 0
+		if err := e.natsConn.Publish(fmt.Sprintf("%s_restart_dump", e.subject), nil); err != nil {
+			return err
+		}
+		if err := e.natsConn.Flush(); err != nil {
+			return err
+		}
+		e.onError(TaskStateRestart, fmt.Errorf("restart-dump: %v", streamErr))
+		return nil
+	default:
+		e.execCtx.Emit("binlog purged on the source: %v", streamErr)
+		return streamErr
+	}
+}
+
+// createSchemaSQL returns the statement to create a schema on the destination,
+// keeping the default charset and collation of the source.
+func (e *Extractor) createSchemaSQL(db *gosql.DB, schemaName string, schemaRename string) (string, error) {
+	stmt, err := base.ShowCreateSchema(db, schemaName)
+	if err != nil {
+		return "", err
+	}
+	if schemaRename != "" {
+		stmt = strings.Replace(stmt, umconf.EscapeName(schemaName), umconf.EscapeName(schemaRename), 1)
+	}
+	return stmt, nil
+}
+
+// createTableSQL returns the statements to create a table on the destination.
+func (e *Extractor) createTableSQL(db *gosql.DB, tb *config.Table, schemaRename string) ([]string, error) {
+	tbSQL, err := base.ShowCreateTable(db, tb.TableSchema, tb.TableName, e.mysqlContext.DropTableIfExists, true)
+	if err != nil {
+		return nil, err
+	}
+	for num, sql := range tbSQL {
+		if schemaRename != "" && strings.Contains(sql, fmt.Sprintf("USE %s", umconf.EscapeName(tb.TableSchema))) {
+			tbSQL[num] = strings.Replace(sql, tb.TableSchema, schemaRename, 1)
+		}
+		if tb.TableRename != "" && (strings.Contains(sql, fmt.Sprintf("DROP TABLE IF EXISTS %s", umconf.EscapeName(tb.TableName))) || strings.Contains(sql, "CREATE TABLE")) {
+			tbSQL[num] = strings.Replace(sql, umconf.EscapeName(tb.TableName), tb.TableRename, 1)
+		}
+	}
+	return tbSQL, nil
+}
+
 // validateConnection issues a simple can-connect to MySQL
 func (e *Extractor) validateConnectionAndGetVersion() error {
 	query := `select @@global.version`
@@ -739,6 +1063,74 @@ func (e *Extractor) validateConnectionAndGetVersion() error {
 	return nil
 }
 
+// isMariaDB tells whether the source is MariaDB, whose GTID is domain-server-sequence.
+// See base.ParseGtidSet for how it is kept by dtle.
+func (e *Extractor) isMariaDB() bool {
+	return base.IsMariaDB(e.mysqlContext.MySQLVersion)
+}
+
+func (e *Extractor) validateMariadb() error {
+	if !e.isMariaDB() {
+		return nil
+	}
+	e.logger.Infof("mysql.extractor: the source is MariaDB %v. Gtid is in the form of domain-server-sequence",
+		e.mysqlContext.MySQLVersion)
+	if e.mysqlContext.BinlogRelay {
+		return fmt.Errorf("BinlogRelay is not supported with MariaDB")
+	}
+	if e.mysqlContext.GtidStart != "" {
+		return fmt.Errorf("GtidStart is not supported with MariaDB. Set Gtid to the position to start after")
+	}
+	if e.mysqlContext.Gtid != "" && !base.IsMariadbGtidSet(e.mysqlContext.Gtid) {
+		return fmt.Errorf("Gtid %v is not a MariaDB GTID position, e.g. 0-1-100", e.mysqlContext.Gtid)
+	}
+	return nil
+}
+
+// getSelfBinlogCoordinates returns the current binlog coordinates of the source.
+// For MariaDB, GtidSet is gtid_binlog_pos.
+func (e *Extractor) getSelfBinlogCoordinates() (*base.BinlogCoordinatesX, error) {
+	coord, err := base.GetSelfBinlogCoordinates(e.db)
+	if err != nil {
+		return nil, err
+	}
+	if coord == nil {
+		return nil, fmt.Errorf("binary log is not enabled on the source")
+	}
+	if e.isMariaDB() {
+		if coord.GtidSet, err = base.GetMariadbGtidBinlogPos(e.db); err != nil {
+			return nil, err
+		}
+	}
+	return coord, nil
+}
+
+// validateReplicaSource allows extracting from a read replica, which must log the changes it replicates.
+// Transactions replicated from the primary keep the primary's UUID in GTID, both in binlog and gtid_executed,
+// so a job can be moved between the primary and its replicas with the same Gtid.
+// Nothing is written to the source. The binlog heartbeat is a replication protocol event sent by the server.
+func (e *Extractor) validateReplicaSource() error {
+	status, err := base.GetReplicaStatus(e.db)
+	if err != nil {
+		return err
+	}
+	e.sourceUUID = status.ServerUUID
+	if !status.IsReplica() {
+		return nil
+	}
+	if err := status.ValidateAsBinlogSource(); err != nil {
+		e.logger.Errorf("mysql.extractor: %v", err)
+		return err
+	}
+	e.logger.Infof("mysql.extractor: extracting from a replica. master_uuid: %v, read_only: %v, super_read_only: %v",
+		status.MasterUUID, status.ReadOnly, status.SuperReadOnly)
+	if status.HasLocalTransactions() {
+		e.logger.Warnf("mysql.extractor: gtid_executed of the replica contains its own server_uuid %v."+
+			" Transactions executed directly on the replica will also be extracted", status.ServerUUID)
+	}
+	return nil
+}
+
 func (e *Extractor) selectSqlMode() error {
 	query := `select @@global.sql_mode`
 	if err := e.db.QueryRow(query).Scan(&e.mysqlContext.SqlMode); err != nil {
@@ -747,14 +1139,29 @@ func (e *Extractor) selectSqlMode() error {
 	return nil
 }
 
+// useMysqldumpCoordinate starts the incremental copy from the coordinate of an existing dump of the source
+// (MysqldumpCoordinate), instead of dumping the source again.
+func (e *Extractor) useMysqldumpCoordinate() error {
+	coord, err := base.ParseMysqldumpCoordinate(e.mysqlContext.MysqldumpCoordinate)
+	if err != nil {
+		return fmt.Errorf("MysqldumpCoordinate: %v", err)
+	}
+	e.mysqlContext.Gtid = coord.GtidSet
+	e.mysqlContext.BinlogFile = coord.LogFile
+	e.mysqlContext.BinlogPos = coord.LogPos
+	e.logger.Infof("mysql.extractor: skip full copy. start from the coordinate of the dump. gtid: %v, file: %v, pos: %v",
+		coord.GtidSet, coord.LogFile, coord.LogPos)
+	return nil
+}
+
 func (e *Extractor) setInitialBinlogCoordinates() error {
 	if e.mysqlContext.Gtid != "" {
-		gtidSet, err := gomysql.ParseMysqlGTIDSet(e.mysqlContext.Gtid)
+		gtidSet, err := base.ParseGtidSet(e.mysqlContext.Gtid)
 		if err != nil {
 			return err
 		}
 		e.initialBinlogCoordinates = &base.BinlogCoordinatesX{
-			GtidSet: gtidSet.String(),
+			GtidSet: base.GtidSetString(gtidSet),
 			LogFile: e.mysqlContext.BinlogFile,
 			LogPos:  e.mysqlContext.BinlogPos,
 		}
@@ -770,13 +1177,15 @@ func (e *Extractor) setInitialBinlogCoordinates() error {
 	return nil
 }
 
+// validateAndReadTimeZone reads the session time_zone, which is TimeZone if set.
 func (e *Extractor) validateAndReadTimeZone() error {
-	query := `select @@global.time_zone`
-	if err := e.db.QueryRow(query).Scan(&e.mysqlContext.TimeZone); err != nil {
+	var timeZone string
+	query := `select @@session.time_zone`
+	if err := e.db.QueryRow(query).Scan(&timeZone); err != nil {
 		return err
 	}
 
-	e.logger.Printf("mysql.extractor: Will use time_zone='%s' on extractor", e.mysqlContext.TimeZone)
+	e.logger.Printf("mysql.extractor: Will use time_zone='%s' on extractor", timeZone)
 	return nil
 }
 
@@ -794,11 +1203,16 @@ func (e *Extractor) CountTableRows(table *config.Table) (int64, error) {
 			table.TableSchema, table.TableName)
 	} else {
 		method = "COUNT"
+		where := table.Where
+		if table.Iteration > 0 && table.UseUniqueKey != nil {
+			// resuming from a checkpoint
+			where = fmt.Sprintf("(%s) and (%s)", buildRangeAfterLastMaxVals(table.UseUniqueKey), table.Where)
+		}
 		query = fmt.Sprintf(`select count(*) from %s.%s where (%s)`,
-			mysql.EscapeName(table.TableSchema), mysql.EscapeName(table.TableName), table.Where)
+			mysql.EscapeName(table.TableSchema), mysql.EscapeName(table.TableName), where)
 	}
 	var rowsEstimate int64
-	if err := e.db.QueryRow(query).Scan(&rowsEstimate); err != nil {
+	if err := e.adminDB.QueryRow(query).Scan(&rowsEstimate); err != nil {
 		return 0, err
 	}
 	atomic.AddInt64(&e.mysqlContext.RowsEstimate, rowsEstimate)
@@ -893,98 +1307,7 @@ func (e *Extractor) StreamEvents() error {
 	//tracer := opentracing.GlobalTracer()
 
 	if e.mysqlContext.ApproveHeterogeneous {
-		go func() {
-			defer e.logger.Debugf("extractor. StreamEvents goroutine exited")
-			entries := binlog.BinlogEntries{}
-			entriesSize := 0
-			sendEntries := func() error {
-				var gno int64 = 0
-				if len(entries.Entries) > 0 {
-					gno = entries.Entries[0].Coordinates.GNO
-				}
-
-				txMsg, err := Encode(entries)
-				if err != nil {
-					return err
-				}
-				e.logger.Debugf("mysql.extractor: sending gno: %v, n: %v", gno, len(entries.Entries))
-				if err = e.publish(ctx, fmt.Sprintf("%s_incr_hete", e.subject), "", txMsg); err != nil {
-					return err
-				}
-				e.logger.Debugf("mysql.extractor: send acked gno: %v, n: %v", gno, len(entries.Entries))
-
-				entries.Entries = nil
-				entriesSize = 0
-
-				return nil
-			}
-
-			keepGoing := true
-
-			groupTimeoutDuration := time.Duration(e.mysqlContext.GroupTimeout) * time.Millisecond
-			timer := time.NewTimer(groupTimeoutDuration)
-			defer timer.Stop()
-			natsips := strings.Split(e.mysqlContext.NatsAddr, ":")
-
-			for keepGoing && !e.shutdown {
-				var err error
-				var addrs []net.Addr
-				select {
-				case binlogEntry := <-e.dataChannel:
-					spanContext := binlogEntry.SpanContext
-					span := opentracing.GlobalTracer().StartSpan("nat send :begin  send binlogEntry from src dtle to desc dtle", opentracing.ChildOf(spanContext))
-					span.SetTag("time", time.Now().Unix())
-					ctx = opentracing.ContextWithSpan(ctx, span)
-					//span.SetTag("timetag", time.Now().Unix())
-					binlogEntry.SpanContext = nil
-					entries.Entries = append(entries.Entries, binlogEntry)
-					entriesSize += binlogEntry.OriginalSize
-					if int64(len(entries.Entries)) <= 1 {
-						v, _ := mem.VirtualMemory()
-						addrs, err = net.InterfaceAddrs()
-						if err != nil {
-							break
-						}
-						for _, ip := range addrs {
-							rip := ip.(*net.IPNet).IP.String()
-							e.logger.Debugf("mysql.extractor: self ip is  : %v,natsips is : %v", rip, natsips[0])
-							if rip == natsips[0] && entriesSize > int(v.Available/16) {
-								err = errors.Errorf("Too much entriesSize , not enough memory ")
-								break
-							}
-						}
-					}
-					if err != nil {
-						break
-					}
-					e.logger.Debugf("mysql.extractor: err is  : %v", err != nil)
-					if entriesSize >= e.mysqlContext.GroupMaxSize ||
-						int64(len(entries.Entries)) == e.mysqlContext.ReplChanBufferSize {
-						e.logger.Debugf("extractor. incr. send by GroupLimit. entriesSize: %v , groupMaxSize: %v,Entries.len: %v", entriesSize, e.mysqlContext.GroupMaxSize, len(entries.Entries))
-						err = sendEntries()
-						if !timer.Stop() {
-							<-timer.C
-						}
-						timer.Reset(groupTimeoutDuration)
-					}
-					span.Finish()
-				case <-timer.C:
-					nEntries := len(entries.Entries)
-					if nEntries > 0 {
-						e.logger.Debugf("extractor. incr. send by timeout. entriesSize: %v,timeout time: %v", entriesSize, e.mysqlContext.GroupTimeout)
-						err = sendEntries()
-					}
-					timer.Reset(groupTimeoutDuration)
-				}
-				if err != nil {
-					e.onError(TaskStateDead, err)
-					keepGoing = false
-				} else {
-					e.mysqlContext.Stage = models.StageSendingBinlogEventToSlave
-					atomic.AddInt64(&e.mysqlContext.DeltaEstimate, 1)
-				}
-			}
-		}()
+		go e.sendIncrEntries()
 		// region commented out
 		/*entryArray := make([]*binlog.BinlogEntry, 0)
 		subject := fmt.Sprintf("%s_incr_hete", e.subject)
@@ -1048,11 +1371,17 @@ func (e *Extractor) StreamEvents() error {
 		}()*/
 		// endregion
 		// The next should block and execute forever, unless there's a serious error
-		if err := e.binlogReader.DataStreamEvents(e.dataChannel); err != nil {
-			if e.shutdown {
-				return nil
+		for !e.shutdown {
+			err := e.binlogReader.DataStreamEvents(e.dataChannel)
+			if err == nil || e.shutdown {
+				break
+			}
+			if err = e.handleBinlogPurged(err); err != nil {
+				err = e.handleSourceFailover(err)
+			}
+			if err != nil {
+				return fmt.Errorf("mysql.extractor: StreamEvents encountered unexpected error: %+v", err)
 			}
-			return fmt.Errorf("mysql.extractor: StreamEvents encountered unexpected error: %+v", err)
 		}
 	} else {
 		// region homogeneous
@@ -1122,11 +1451,17 @@ func (e *Extractor) StreamEvents() error {
 			}
 		}()
 		// The next should block and execute forever, unless there's a serious error
-		if err := e.binlogReader.BinlogStreamEvents(e.binlogChannel); err != nil {
-			if e.shutdown {
-				return nil
+		for !e.shutdown {
+			err := e.binlogReader.BinlogStreamEvents(e.binlogChannel)
+			if err == nil || e.shutdown {
+				break
+			}
+			if err = e.handleBinlogPurged(err); err != nil {
+				err = e.handleSourceFailover(err)
+			}
+			if err != nil {
+				return fmt.Errorf("mysql.extractor: StreamEvents encountered unexpected error: %+v", err)
 			}
-			return fmt.Errorf("mysql.extractor: StreamEvents encountered unexpected error: %+v", err)
 		}
 		// endregion
 	}
@@ -1134,6 +1469,163 @@ func (e *Extractor) StreamEvents() error {
 	return nil
 }
 
+// sendIncrEntries groups the binlog entries of the incremental copy and sends them to the destinations.
+func (e *Extractor) sendIncrEntries() {
+	ctx := context.Background()
+	defer e.logger.Debugf("extractor. StreamEvents goroutine exited")
+	// the messages are numbered for the destinations to apply them in order. See incrSequencer.
+	entries := binlog.BinlogEntries{Stream: e.incrStream}
+	e.inFlight.reset(e.incrStream)
+	entriesSize := 0
+	// the spans of the transactions in entries, finished once they are sent. See binlog.StartTxSpan.
+	var txSpans []opentracing.Span
+	// the number of entries from dataChannel in entries. See binlog.SemiSyncTracker.
+	forwarded := 0
+	// the number of entries sent in the stream. See IncrAck.
+	var sentEntries int64
+	sendEntries := func() error {
+		var gno int64 = 0
+		if len(entries.Entries) > 0 {
+			gno = entries.Entries[0].Coordinates.GNO
+		}
+
+		entries.Seq++
+		entries.EntrySeq = sentEntries
+		txMsg, err := Encode(entries)
+		if err != nil {
+			return err
+		}
+		// Wait if InFlightWindow transactions are not applied by the destinations.
+		// Meanwhile dataChannel fills up and the binlog reader is held back.
+		if !e.inFlight.wait(e.shutdownCh) {
+			return nil // shutdown
+		}
+		e.logger.Debugf("mysql.extractor: sending gno: %v, n: %v", gno, len(entries.Entries))
+		if err = e.publish(ctx, fmt.Sprintf("%s_incr_hete", e.subject), "", txMsg); err != nil {
+			return err
+		}
+		sentEntries += int64(len(entries.Entries))
+		e.inFlight.add(len(entries.Entries))
+		e.semiSync.Sent(forwarded, len(entries.Entries))
+		forwarded = 0
+		e.logger.Debugf("mysql.extractor: send acked gno: %v, n: %v", gno, len(entries.Entries))
+		for _, txSpan := range txSpans {
+			txSpan.Finish()
+		}
+		txSpans = nil
+
+		entries.Entries = nil
+		entries.AckedSeq = entries.Seq
+		entriesSize = 0
+
+		return nil
+	}
+
+	keepGoing := true
+
+	groupTimeoutDuration := time.Duration(e.mysqlContext.GroupTimeout) * time.Millisecond
+	timer := time.NewTimer(groupTimeoutDuration)
+	defer timer.Stop()
+	// NatsAddr might list the servers of a nats cluster. Only the first one is checked.
+	natsips := strings.Split(strings.Split(e.mysqlContext.NatsAddr, ",")[0], ":")
+
+	// the resync of a table in progress, if any
+	var resync *tableResync
+	atomic.StoreInt32(&e.incrStarted, 1)
+	for keepGoing && !e.shutdown {
+		var err error
+		var addrs []net.Addr
+		var resyncChunks chan *binlog.BinlogEntry
+		if resync != nil {
+			resyncChunks = resync.chunksToSend()
+		}
+		select {
+		case r := <-e.resyncCh:
+			resync = r
+		case part, ok := <-resyncChunks:
+			if ok {
+				entries.Entries = append(entries.Entries, part)
+				entriesSize += part.OriginalSize
+			} else {
+				e.logger.Infof("mysql.extractor: resync of %v.%v: sending %v events held back",
+					resync.schemaName, resync.tableName, len(resync.held))
+				entries.Entries = append(entries.Entries, resync.done())
+				resync = nil
+				atomic.StoreInt32(&e.resyncing, 0)
+			}
+			// A chunk is as large as a group.
+			err = sendEntries()
+		case binlogEntry := <-e.dataChannel:
+			spanContext := binlogEntry.SpanContext
+			span := opentracing.GlobalTracer().StartSpan("nat send :begin  send binlogEntry from src dtle to desc dtle", opentracing.ChildOf(spanContext))
+			span.SetTag("time", time.Now().Unix())
+			ctx = opentracing.ContextWithSpan(ctx, span)
+			//span.SetTag("timetag", time.Now().Unix())
+			txSpans = append(txSpans, binlog.StartTxSpan(binlogEntry, span.Context()))
+			binlogEntry.SpanContext = nil
+			forwarded++
+			if resync != nil {
+				var parts []*binlog.BinlogEntry
+				if parts, err = resync.process(binlogEntry); err != nil {
+					break
+				}
+				entries.Entries = append(entries.Entries, parts...)
+			} else {
+				entries.Entries = append(entries.Entries, binlogEntry)
+			}
+			entriesSize += binlogEntry.OriginalSize
+			if int64(len(entries.Entries)) <= 1 {
+				v, _ := mem.VirtualMemory()
+				addrs, err = net.InterfaceAddrs()
+				if err != nil {
+					break
+				}
+				for _, ip := range addrs {
+					rip := ip.(*net.IPNet).IP.String()
+					e.logger.Debugf("mysql.extractor: self ip is  : %v,natsips is : %v", rip, natsips[0])
+					if rip == natsips[0] && entriesSize > int(v.Available/16) {
+						err = errors.Errorf("Too much entriesSize , not enough memory ")
+						break
+					}
+				}
+			}
+			if err != nil {
+				break
+			}
+			e.logger.Debugf("mysql.extractor: err is  : %v", err != nil)
+			if entriesSize >= e.mysqlContext.GroupMaxSize ||
+				int64(len(entries.Entries)) >= e.mysqlContext.ReplChanBufferSize {
+				e.logger.Debugf("extractor. incr. send by GroupLimit. entriesSize: %v , groupMaxSize: %v,Entries.len: %v", entriesSize, e.mysqlContext.GroupMaxSize, len(entries.Entries))
+				err = sendEntries()
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(groupTimeoutDuration)
+			}
+			span.Finish()
+		case <-timer.C:
+			if resync != nil && len(e.dataChannel) == 0 {
+				if part := resync.onIdle(e.binlogReader.GetCurrentBinlogCoordinates()); part != nil {
+					entries.Entries = append(entries.Entries, part)
+				}
+			}
+			nEntries := len(entries.Entries)
+			if nEntries > 0 {
+				e.logger.Debugf("extractor. incr. send by timeout. entriesSize: %v,timeout time: %v", entriesSize, e.mysqlContext.GroupTimeout)
+				err = sendEntries()
+			}
+			timer.Reset(groupTimeoutDuration)
+		}
+		if err != nil {
+			e.onError(TaskStateDead, err)
+			keepGoing = false
+		} else {
+			e.mysqlContext.Stage = models.StageSendingBinlogEventToSlave
+			atomic.AddInt64(&e.mysqlContext.DeltaEstimate, 1)
+		}
+	}
+}
+
 // retryOperation attempts up to `count` attempts at running given function,
 // exiting as soon as it returns with non-error.
 func (e *Extractor) publish(ctx context.Context, subject, gtid string, txMsg []byte) (err error) {
@@ -1161,17 +1653,26 @@ func (e *Extractor) publish(ctx context.Context, subject, gtid string, txMsg []b
 	defer span.Finish()
 	for {
 		e.logger.Debugf("mysql.extractor: publish. gtid: %v, msg_len: %v", gtid, len(txMsg))
-		_, err = e.natsConn.Request(subject, t.Bytes(), DefaultConnectWait)
+		err = requestAll(e.natsConn, subject, t.Bytes(), e.mysqlContext.DestCount, DefaultConnectWait)
 		if err == nil {
 			if gtid != "" {
 				e.mysqlContext.Gtid = gtid
 			}
+			err = e.writeEventSink(subject, txMsg)
 			break
 		} else if err == gonats.ErrTimeout {
 			e.logger.Debugf("mysql.extractor: publish timeout, got %v", err)
+			e.recordError(models.ErrorTypeNats, gtid, fmt.Errorf("publish to %v: %v. retrying", subject, err))
+			continue
+		} else if err == gonats.ErrReconnectBufExceeded {
+			// the nats server is lost, and the connection is failing over to another one of NatsAddr
+			e.logger.Warnf("mysql.extractor: publish while reconnecting to nats, got %v", err)
+			e.recordError(models.ErrorTypeNats, gtid, fmt.Errorf("publish to %v: %v. retrying", subject, err))
+			time.Sleep(1 * time.Second)
 			continue
 		} else {
 			e.logger.Errorf("mysql.extractor: unexpected error on publish, got %v", err)
+			e.recordError(models.ErrorTypeNats, gtid, fmt.Errorf("publish to %v: %v", subject, err))
 			break
 		}
 		// there's an error. Let's try again.
@@ -1215,6 +1716,7 @@ func (e *Extractor) sendSysVarAndSqlMode() error {
 //Perform the snapshot using the same logic as the "mysqldump" utility.
 func (e *Extractor) mysqlDump() error {
 	defer e.singletonDB.Close()
+	pastDeadline := e.dumpDeadline(time.Now())
 	var tx sql.QueryAble
 	var err error
 	step := 0
@@ -1235,123 +1737,24 @@ func (e *Extractor) mysqlDump() error {
 
 	step++
 
-	// ------
-	// STEP ?
-	// ------
-	// Obtain read lock on all tables. This statement closes all open tables and locks all tables
-	// for all databases with a global read lock, and it prevents ALL updates while we have this lock.
-	// It also ensures that everything we do while we have this lock will be consistent.
-	/*e.logger.Printf("mysql.extractor: Step %d: flush and obtain global read lock (preventing writes to database)", step)
-	query := "FLUSH TABLES WITH READ LOCK"
-	_, err = tx.Exec(query)
-	if err != nil {
-		e.logger.Printf("[ERR] mysql.extractor: exec %+v, error: %v", query, err)
-		return err
-	}
-	step++*/
-
 	// ------
 	// STEP 1
 	// ------
 	// First, start a transaction and request that a consistent MVCC snapshot is obtained immediately.
 	// See http://dev.mysql.com/doc/refman/5.7/en/commit.html
-
-	var needConsistentSnapshot = true // TODO determine by table characteristic (has-PK or not)
-	if needConsistentSnapshot {
-		e.logger.Printf("mysql.extractor: Step %d: start transaction with consistent snapshot", step)
-		gtidMatch := false
-		gtidMatchRound := 0
-		delayBetweenRetries := 200 * time.Millisecond
-		for !gtidMatch {
-			gtidMatchRound += 1
-
-			// 1
-			rows1, err := e.singletonDB.Query("show master status")
-			if err != nil {
-				e.logger.Errorf("mysql.extractor: get gtid, round: %v, phase 1, err: %v", gtidMatchRound, err)
-				return err
-			}
-
-			e.testStub1()
-
-			// 2
-			// TODO it seems that two 'start transaction' will be sent.
-			// https://github.com/golang/go/issues/19981
-			realTx, err := e.singletonDB.Begin()
-			tx = realTx
-			if err != nil {
-				return err
-			}
-			query := "START TRANSACTION WITH CONSISTENT SNAPSHOT"
-			_, err = realTx.Exec(query)
-			if err != nil {
-				e.logger.Printf("[ERR] mysql.extractor: exec %+v, error: %v", query, err)
-				return err
-			}
-
-			e.testStub1()
-
-			// 3
-			rows2, err := realTx.Query("show master status")
-
-			// 4
-			binlogCoordinates1, err := base.ParseBinlogCoordinatesFromRows(rows1)
-			if err != nil {
-				return err
-			}
-			binlogCoordinates2, err := base.ParseBinlogCoordinatesFromRows(rows2)
-			if err != nil {
-				return err
-			}
-			e.logger.Debugf("mysql.extractor: binlog coordinates 1: %+v", binlogCoordinates1)
-			e.logger.Debugf("mysql.extractor: binlog coordinates 2: %+v", binlogCoordinates2)
-
-			if binlogCoordinates1.GtidSet == binlogCoordinates2.GtidSet {
-				gtidMatch = true
-				e.logger.Infof("Got gtid after %v rounds", gtidMatchRound)
-
-				// Obtain the binlog position and update the SourceInfo in the context. This means that all source records generated
-				// as part of the snapshot will contain the binlog position of the snapshot.
-				//binlogCoordinates, err := base.GetSelfBinlogCoordinatesWithTx(tx)
-
-				e.initialBinlogCoordinates = binlogCoordinates2
-				e.logger.Printf("mysql.extractor: Step %d: read binlog coordinates of MySQL master: %+v", step, *e.initialBinlogCoordinates)
-
-				defer func() {
-					/*e.logger.Printf("mysql.extractor: Step %d: releasing global read lock to enable MySQL writes", step)
-					query := "UNLOCK TABLES"
-					_, err := tx.Exec(query)
-					if err != nil {
-						e.logger.Printf("[ERR] mysql.extractor: exec %+v, error: %v", query, err)
-					}
-					step++*/
-					e.logger.Printf("mysql.extractor: Step %d: committing transaction", step)
-					if err := realTx.Commit(); err != nil {
-						e.onError(TaskStateDead, err)
-					}
-				}()
-			} else {
-				e.logger.Warningf("Failed got a consistenct TX with GTID in %v rounds. Will retry.", gtidMatchRound)
-				err = realTx.Rollback()
-				if err != nil {
-					return err
-				}
-				time.Sleep(delayBetweenRetries)
-			}
-		}
-	} else {
-		e.logger.Debugf("mysql.extractor: no need to get consistent snapshot")
-		tx = e.singletonDB
-		rows1, err := tx.Query("show master status")
-		if err != nil {
-			return err
-		}
-		e.initialBinlogCoordinates, err = base.ParseBinlogCoordinatesFromRows(rows1)
-		if err != nil {
-			return err
-		}
-		e.logger.Debugf("mysql.extractor: got gtid")
+	e.logger.Printf("mysql.extractor: Step %d: start transaction with consistent snapshot", step)
+	realTx, err := e.startConsistentSnapshot()
+	if err != nil {
+		return err
 	}
+	tx = realTx
+	e.logger.Printf("mysql.extractor: Step %d: read binlog coordinates of MySQL master: %+v", step, *e.initialBinlogCoordinates)
+	defer func() {
+		e.logger.Printf("mysql.extractor: Step %d: committing transaction", step)
+		if err := realTx.Commit(); err != nil {
+			e.onError(TaskStateDead, err)
+		}
+	}()
 	step++
 
 	// ------
@@ -1367,6 +1770,30 @@ func (e *Extractor) mysqlDump() error {
 		return err
 	}
 
+	checkpoint := e.resumableDumpCheckpoint()
+	if checkpoint != nil {
+		e.logger.Infof("mysql.extractor: resuming the full copy from the checkpoint. %v tables copied."+
+			" table %v.%v copied to chunk %v. incremental replication will start from the first snapshot: %v",
+			len(checkpoint.DoneTables), checkpoint.TableSchema, checkpoint.TableName, checkpoint.Iteration, checkpoint.Gtid)
+		e.initialBinlogCoordinates = &base.BinlogCoordinatesX{
+			LogFile: checkpoint.BinlogFile,
+			LogPos:  checkpoint.BinlogPos,
+			GtidSet: checkpoint.Gtid,
+		}
+	} else {
+		checkpoint = &config.DumpCheckpoint{
+			Gtid:       e.initialBinlogCoordinates.GtidSet,
+			BinlogFile: e.initialBinlogCoordinates.LogFile,
+			BinlogPos:  e.initialBinlogCoordinates.LogPos,
+		}
+	}
+	// Also sent with the table definitions. The applier must forget the checkpoint of a previous copy
+	// before the tables are dropped.
+	startCheckpoint, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
 	e.gotCoordinateCh <- struct{}{}
 
 	// Transform the current schema so that it reflects the *current* state of the MySQL server's contents.
@@ -1375,52 +1802,53 @@ func (e *Extractor) mysqlDump() error {
 		e.logger.Printf("mysql.extractor: Step %d: - generating DROP and CREATE statements to reflect current database schemas:%v", step, e.replicateDoDb)
 	}
 	for _, db := range e.replicateDoDb {
+		var dbSQL string
+		if !e.mysqlContext.SkipCreateDbTable && strings.ToLower(db.TableSchema) != "mysql" {
+			var err error
+			if dbSQL, err = e.createSchemaSQL(e.singletonDB, db.TableSchema, db.TableSchemaRename); err != nil {
+				return err
+			}
+		}
 		if len(db.Tables) > 0 {
 			for _, tb := range db.Tables {
 				if tb.TableSchema != db.TableSchema {
 					continue
 				}
+				if checkpoint.IsTableDone(tb.TableSchema, tb.TableName) {
+					continue
+				}
+				// The table being copied is kept. Only the remaining rows are counted.
+				resumed := resumeTableFromCheckpoint(tb, checkpoint)
 				total, err := e.CountTableRows(tb)
 				if err != nil {
 					return err
 				}
 				tb.Counter = total
-				var dbSQL string
+				if resumed {
+					continue
+				}
 				var tbSQL []string
 				if !e.mysqlContext.SkipCreateDbTable {
 					var err error
-					if strings.ToLower(tb.TableSchema) != "mysql" {
-						if db.TableSchemaRename != "" {
-							dbSQL = fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", umconf.EscapeName(tb.TableSchemaRename))
-						} else {
-							dbSQL = fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", umconf.EscapeName(tb.TableSchema))
-						}
-					}
 					if strings.ToLower(tb.TableType) == "view" {
 						/*tbSQL, err = base.ShowCreateView(e.singletonDB, tb.TableSchema, tb.TableName, e.mysqlContext.DropTableIfExists)
 						if err != nil {
 							return err
 						}*/
 					} else if strings.ToLower(tb.TableSchema) != "mysql" {
-						tbSQL, err = base.ShowCreateTable(e.singletonDB, tb.TableSchema, tb.TableName, e.mysqlContext.DropTableIfExists, true)
-						for num, sql := range tbSQL {
-							if db.TableSchemaRename != "" && strings.Contains(sql, fmt.Sprintf("USE %s", umconf.EscapeName(tb.TableSchema))) {
-								tbSQL[num] = strings.Replace(sql, tb.TableSchema, db.TableSchemaRename, 1)
-							}
-							if tb.TableRename != "" && (strings.Contains(sql, fmt.Sprintf("DROP TABLE IF EXISTS %s", umconf.EscapeName(tb.TableName))) || strings.Contains(sql, "CREATE TABLE")) {
-								tbSQL[num] = strings.Replace(sql, umconf.EscapeName(tb.TableName), tb.TableRename, 1)
-							}
-						}
-						if err != nil {
+						if tbSQL, err = e.createTableSQL(e.singletonDB, tb, db.TableSchemaRename); err != nil {
 							return err
 						}
 					}
 				}
 				entry := &DumpEntry{
-					DbSQL:      dbSQL,
-					TbSQL:      tbSQL,
-					TotalCount: tb.Counter + 1,
-					RowsCount:  1,
+					DbSQL:       dbSQL,
+					TbSQL:       tbSQL,
+					TableSchema: tb.TableSchema,
+					TableName:   tb.TableName,
+					TotalCount:  tb.Counter + 1,
+					RowsCount:   1,
+					Checkpoint:  startCheckpoint,
 				}
 				atomic.AddInt64(&e.mysqlContext.RowsEstimate, 1)
 				atomic.AddInt64(&e.mysqlContext.TotalRowsCopied, 1)
@@ -1430,21 +1858,12 @@ func (e *Extractor) mysqlDump() error {
 			}
 			e.tableCount += len(db.Tables)
 		} else {
-			var dbSQL string
-			if !e.mysqlContext.SkipCreateDbTable {
-				if strings.ToLower(db.TableSchema) != "mysql" {
-					if db.TableSchemaRename != "" {
-						dbSQL = fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", umconf.EscapeName(db.TableSchemaRename))
-					} else {
-						dbSQL = fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", umconf.EscapeName(db.TableSchema))
-					}
-
-				}
-			}
 			entry := &DumpEntry{
-				DbSQL:      dbSQL,
-				TotalCount: 1,
-				RowsCount:  1,
+				DbSQL:       dbSQL,
+				TableSchema: db.TableSchema,
+				TotalCount:  1,
+				RowsCount:   1,
+				Checkpoint:  startCheckpoint,
 			}
 			atomic.AddInt64(&e.mysqlContext.RowsEstimate, 1)
 			atomic.AddInt64(&e.mysqlContext.TotalRowsCopied, 1)
@@ -1460,11 +1879,27 @@ func (e *Extractor) mysqlDump() error {
 	// ------
 	// Dump all of the tables and generate source records ...
 	e.logger.Printf("mysql.extractor: Step %d: scanning contents of %d tables", step, e.tableCount)
+	if e.mysqlVersionDigit >= 80000 {
+		// Otherwise information_schema.TABLES.AUTO_INCREMENT and TABLE_ROWS might be cached values.
+		if _, err := tx.Exec("set @@session.information_schema_stats_expiry = 0"); err != nil {
+			return err
+		}
+	}
 	startScan := utils.CurrentTimeMillis()
 	counter := 0
+	var incompleteTables []string
 	//pool := models.NewPool(10)
 	for _, db := range e.replicateDoDb {
 		for _, t := range db.Tables {
+			if checkpoint.IsTableDone(t.TableSchema, t.TableName) {
+				e.logger.Infof("mysql.extractor: Step %d: - skipping table '%s.%s' copied before the restart",
+					step, t.TableSchema, t.TableName)
+				continue
+			}
+			if pastDeadline != nil && pastDeadline() {
+				incompleteTables = append(incompleteTables, fmt.Sprintf("%s.%s", t.TableSchema, t.TableName))
+				continue
+			}
 			//pool.Add(1)
 			//go func(t *config.Table) {
 			counter++
@@ -1473,14 +1908,28 @@ func (e *Extractor) mysqlDump() error {
 			e.logger.Printf("mysql.extractor: Step %d: - scanning table '%s.%s' (%d of %d tables)", step, t.TableSchema, t.TableName, counter, e.tableCount)
 
 			d := NewDumper(tx, t, e.mysqlContext.ChunkSize, e.logger)
+			if e.adminDB != e.db {
+				d.checksumDB = e.adminDB
+			}
+			d.checkpoint = checkpoint
+			d.throttler = e.throttler
+			d.cipher = e.cipher
+			d.chunkSizeBytes = e.mysqlContext.ChunkSizeBytes
+			d.pastDeadline = pastDeadline
 			if err := d.Dump(); err != nil {
 				e.onError(TaskStateDead, err)
 			}
+			e.dumpersLock.Lock()
 			e.dumpers = append(e.dumpers, d)
+			e.dumpersLock.Unlock()
+			lastProgressLog := time.Now()
 			// Scan the rows in the table ...
 			for entry := range d.resultsChannel {
 				if entry.Err != "" {
-					e.onError(TaskStateDead, fmt.Errorf(entry.Err))
+					// Do not go on to the next table, which would mark this one copied.
+					err := fmt.Errorf(entry.Err)
+					e.onError(TaskStateDead, err)
+					return err
 				} else {
 					if !d.sentTableDef {
 						tableBs, err := GobEncode(d.table)
@@ -1497,6 +1946,41 @@ func (e *Extractor) mysqlDump() error {
 						e.onError(TaskStateRestart, err)
 					}
 					atomic.AddInt64(&e.mysqlContext.TotalRowsCopied, entry.RowsCount)
+					d.onRowsCopied(entry.RowsCount)
+					if time.Since(lastProgressLog) >= dumpProgressLogInterval {
+						lastProgressLog = time.Now()
+						p := d.progress()
+						e.logger.Infof("mysql.extractor: table '%s.%s' copied %d of about %d rows. chunk %d",
+							p.TableSchema, p.TableName, p.RowsCopied, p.RowsEstimate, p.Chunk)
+					}
+				}
+			}
+			if d.cutAtDeadline {
+				incompleteTables = append(incompleteTables, fmt.Sprintf("%s.%s", t.TableSchema, t.TableName))
+				continue
+			}
+			d.finishProgress()
+			// Carried by the next chunk, or the AUTO_INCREMENT entry below.
+			checkpoint.SetTableDone(t.TableSchema, t.TableName)
+
+			if e.mysqlContext.PreserveAutoIncrement && strings.ToLower(t.TableType) != "view" {
+				autoIncrement, ok, err := d.getAutoIncrement()
+				if err != nil {
+					e.onError(TaskStateDead, err)
+					return err
+				}
+				if ok {
+					e.logger.Debugf("mysql.extractor: table %v.%v AUTO_INCREMENT %v", t.TableSchema, t.TableName, autoIncrement)
+					atomic.AddInt64(&e.mysqlContext.RowsEstimate, 1)
+					atomic.AddInt64(&e.mysqlContext.TotalRowsCopied, 1)
+					entry := d.autoIncrementEntry(autoIncrement)
+					if entry.Checkpoint, err = json.Marshal(checkpoint); err != nil {
+						e.onError(TaskStateDead, err)
+						return err
+					}
+					if err := e.encodeDumpEntry(entry); err != nil {
+						e.onError(TaskStateRestart, err)
+					}
 				}
 			}
 
@@ -1505,6 +1989,9 @@ func (e *Extractor) mysqlDump() error {
 		}
 	}
 	//pool.Wait()
+	if len(incompleteTables) > 0 {
+		e.onDumpIncomplete(incompleteTables)
+	}
 	step++
 
 	// We've copied all of the tables, but our buffer holds onto the very last record.
@@ -1516,6 +2003,49 @@ func (e *Extractor) mysqlDump() error {
 
 	return nil
 }
+
+// resumableDumpCheckpoint returns the checkpoint saved by the applier if the full copy can resume from it.
+// It must be called after getting the tables.
+func (e *Extractor) resumableDumpCheckpoint() *config.DumpCheckpoint {
+	if e.mysqlContext.DestCount > 1 {
+		// Each destination has its own checkpoint.
+		e.logger.Infof("mysql.extractor: the full copy to %v destinations cannot resume. copying from the beginning",
+			e.mysqlContext.DestCount)
+		return nil
+	}
+	// The applier has the latest checkpoint if it keeps running. Otherwise the one saved in the job is used.
+	msg, err := e.natsConn.Request(fmt.Sprintf("%s_dump_checkpoint", e.subject), nil, DefaultConnectWait)
+	if err != nil {
+		e.logger.Debugf("mysql.extractor: failed to get the dump checkpoint from the applier. err: %v", err)
+	} else {
+		e.mysqlContext.DumpCheckpoint = string(msg.Data)
+	}
+
+	checkpoint, err := e.mysqlContext.GetDumpCheckpoint()
+	if err != nil {
+		e.logger.Warnf("mysql.extractor: %v. copying from the beginning", err)
+		return nil
+	}
+	if checkpoint == nil || checkpoint.Gtid == "" {
+		return nil
+	}
+	// Rows copied after the restart are from a later snapshot, and the binlog since the first snapshot
+	// will be replayed on them. It is only safe if the rows are replaced by a unique key.
+	for _, db := range e.replicateDoDb {
+		for _, tb := range db.Tables {
+			if checkpoint.IsTableDone(tb.TableSchema, tb.TableName) {
+				continue
+			}
+			if tb.UseUniqueKey == nil || os.Getenv(g.ENV_DUMP_OLDWAY) != "" {
+				e.logger.Warnf("mysql.extractor: table %v.%v is not copied by a unique key."+
+					" cannot resume from the checkpoint. copying from the beginning", tb.TableSchema, tb.TableName)
+				return nil
+			}
+		}
+	}
+	return checkpoint
+}
+
 func (e *Extractor) encodeDumpEntry(entry *DumpEntry) error {
 	var ctx context.Context
 	//tracer := opentracing.GlobalTracer()
@@ -1526,7 +2056,10 @@ func (e *Extractor) encodeDumpEntry(entry *DumpEntry) error {
 	if err != nil {
 		return err
 	}
-	txMsg := snappy.Encode(nil, bs)
+	txMsg, err := e.compressDump(bs)
+	if err != nil {
+		return err
+	}
 	if err := e.publish(ctx, fmt.Sprintf("%s_full", e.subject), "", txMsg); err != nil {
 		return err
 	}
@@ -1582,7 +2115,18 @@ func (e *Extractor) Stats() (*models.TaskStatistics, error) {
 			SendByTimeout:        e.sendByTimeoutCounter,
 			SendBySizeFull:       e.sendBySizeFullCounter,
 		},
-		Timestamp: time.Now().UTC().UnixNano(),
+		Timestamp:        time.Now().UTC().UnixNano(),
+		RecentErrors:     e.recentErrors.list(),
+		IncompleteTables: e.getIncompleteTables(),
+	}
+	e.dumpersLock.Lock()
+	for _, d := range e.dumpers {
+		taskResUsage.TableProgress = append(taskResUsage.TableProgress, d.progress())
+	}
+	e.dumpersLock.Unlock()
+	if e.throttler != nil {
+		throttleStats := e.throttler.getStats()
+		taskResUsage.ThrottleStats = &throttleStats
 	}
 	if e.natsConn != nil {
 		taskResUsage.MsgStat = e.natsConn.Statistics
@@ -1618,6 +2162,7 @@ func (e *Extractor) ID() string {
 			ReplicateDoDb:         e.mysqlContext.ReplicateDoDb,
 			ReplicateIgnoreDb:     e.mysqlContext.ReplicateIgnoreDb,
 			Gtid:                  e.mysqlContext.Gtid,
+			DumpCheckpoint:        e.mysqlContext.DumpCheckpoint,
 			NatsAddr:              e.mysqlContext.NatsAddr,
 			ConnectionConfig:      e.mysqlContext.ConnectionConfig,
 		},
@@ -1635,6 +2180,10 @@ func (e *Extractor) onError(state int, err error) {
 	if e.shutdown {
 		return
 	}
+	e.recordError(models.ErrorTypeTask, e.mysqlContext.Gtid, err)
+	if state == TaskStateDead {
+		e.failure.set(err)
+	}
 	e.waitCh <- models.NewWaitResult(state, err)
 	e.Shutdown()
 }
@@ -1659,9 +2208,11 @@ func (e *Extractor) Shutdown() error {
 		e.natsConn.Close()
 	}
 
+	e.dumpersLock.Lock()
 	for _, d := range e.dumpers {
 		d.Close()
 	}
+	e.dumpersLock.Unlock()
 
 	if err := sql.CloseDB(e.singletonDB); err != nil {
 		e.logger.Errorf("Extractor.Shutdown error close singletonDB. err %v", err)
@@ -1677,10 +2228,23 @@ func (e *Extractor) Shutdown() error {
 		}
 	}
 
+	if err := e.eventSink.Close(); err != nil {
+		e.logger.Errorf("Extractor.Shutdown error close eventSink. err %v", err)
+	}
+
+	if e.adminDB != e.db {
+		if err := sql.CloseDB(e.adminDB); err != nil {
+			e.logger.Errorf("Extractor.Shutdown error close e.adminDB. err %v", err)
+		}
+	}
 	if err := sql.CloseDB(e.db); err != nil {
 		e.logger.Errorf("Extractor.Shutdown error close e.db. err %v", err)
 	}
 
+	if err := e.tunnel.Close(); err != nil {
+		e.logger.Errorf("Extractor.Shutdown error close tunnel. err %v", err)
+	}
+
 	//close(e.binlogChannel)
 	e.logger.Printf("mysql.extractor: Shutting down")
 	return nil
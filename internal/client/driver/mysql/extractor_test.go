@@ -7,40 +7,65 @@
 package mysql
 
 import (
+	"context"
 	"reflect"
 	"testing"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
 	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
 	"github.com/actiontech/dtle/internal/config"
-	log "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/models"
+	"github.com/actiontech/dtle/utils"
+	gomysql "github.com/siddontang/go-mysql/mysql"
+	"github.com/sirupsen/logrus"
 )
 
 func TestGtidSetDiff(t *testing.T) {
-	// TODO
-	g, err := GtidSetDiff(
+	g, err := base.GtidSetDiff(
 		"113fa2ce-c8e6-11e7-b894-67ad30e6f107:1-100:200:300-400,f2a4aa16-c8e6-11e7-9ff0-e19f7778f563:100-200:300-400,8888aa16-c8e6-11e7-9ff0-e19f7778f563:1-1000",
 		"113fa2ce-c8e6-11e7-b894-67ad30e6f107:330,f2a4aa16-c8e6-11e7-9ff0-e19f7778f563:301",
 	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "113fa2ce-c8e6-11e7-b894-67ad30e6f107:1-100:200:300-329," +
+		"f2a4aa16-c8e6-11e7-9ff0-e19f7778f563:100-200:300," +
+		"8888aa16-c8e6-11e7-9ff0-e19f7778f563:1-1000"
+	gotSet, err := gomysql.ParseMysqlGTIDSet(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSet, err := gomysql.ParseMysqlGTIDSet(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotSet.Equal(wantSet) {
+		t.Errorf("GtidSetDiff() = %v, want %v", g, want)
+	}
 }
 
 func TestNewExtractor(t *testing.T) {
 	type args struct {
-		subject    string
-		tp         string
-		maxPayload int
-		cfg        *config.MySQLDriverConfig
-		logger     *log.Logger
+		execCtx *common.ExecContext
+		cfg     *config.MySQLDriverConfig
+		logger  *logrus.Logger
 	}
 	tests := []struct {
-		name string
-		args args
-		want *Extractor
+		name    string
+		args    args
+		want    *Extractor
+		wantErr bool
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewExtractor(tt.args.subject, tt.args.tp, tt.args.maxPayload, tt.args.cfg, tt.args.logger); !reflect.DeepEqual(got, tt.want) {
+			got, err := NewExtractor(tt.args.execCtx, tt.args.cfg, tt.args.logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewExtractor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewExtractor() = %v, want %v", got, tt.want)
 			}
 		})
@@ -174,21 +199,18 @@ func TestExtractor_initDBConnections(t *testing.T) {
 
 func TestExtractor_initBinlogReader(t *testing.T) {
 	type args struct {
-		binlogCoordinates *base.BinlogCoordinateTx
+		binlogCoordinates *base.BinlogCoordinatesX
 	}
 	tests := []struct {
-		name    string
-		e       *Extractor
-		args    args
-		wantErr bool
+		name string
+		e    *Extractor
+		args args
 	}{
 		// TODO: Add test cases.
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.e.initBinlogReader(tt.args.binlogCoordinates); (err != nil) != tt.wantErr {
-				t.Errorf("Extractor.initBinlogReader() error = %v, wantErr %v", err, tt.wantErr)
-			}
+			tt.e.initBinlogReader(tt.args.binlogCoordinates)
 		})
 	}
 }
@@ -324,8 +346,9 @@ func TestExtractor_StreamEvents(t *testing.T) {
 	}
 }
 
-func TestExtractor_requestMsg(t *testing.T) {
+func TestExtractor_publish(t *testing.T) {
 	type args struct {
+		ctx     context.Context
 		subject string
 		gtid    string
 		txMsg   []byte
@@ -340,8 +363,8 @@ func TestExtractor_requestMsg(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.e.requestMsg(tt.args.subject, tt.args.gtid, tt.args.txMsg); (err != nil) != tt.wantErr {
-				t.Errorf("Extractor.requestMsg() error = %v, wantErr %v", err, tt.wantErr)
+			if err := tt.e.publish(tt.args.ctx, tt.args.subject, tt.args.gtid, tt.args.txMsg); (err != nil) != tt.wantErr {
+				t.Errorf("Extractor.publish() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -385,7 +408,7 @@ func TestExtractor_encodeDumpEntry(t *testing.T) {
 	}
 }
 
-func Test_currentTimeMillis(t *testing.T) {
+func TestCurrentTimeMillis(t *testing.T) {
 	tests := []struct {
 		name string
 		want int64
@@ -394,8 +417,8 @@ func Test_currentTimeMillis(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := currentTimeMillis(); got != tt.want {
-				t.Errorf("currentTimeMillis() = %v, want %v", got, tt.want)
+			if got := utils.CurrentTimeMillis(); got != tt.want {
+				t.Errorf("CurrentTimeMillis() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -443,7 +466,8 @@ func TestExtractor_ID(t *testing.T) {
 
 func TestExtractor_onError(t *testing.T) {
 	type args struct {
-		err error
+		state int
+		err   error
 	}
 	tests := []struct {
 		name string
@@ -454,7 +478,7 @@ func TestExtractor_onError(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.e.onError(tt.args.err)
+			tt.e.onError(tt.args.state, tt.args.err)
 		})
 	}
 }
@@ -563,8 +587,7 @@ func TestExtractor_validateAndReadTimeZone(t *testing.T) {
 
 func TestExtractor_CountTableRows(t *testing.T) {
 	type args struct {
-		tableSchema string
-		tableName   string
+		table *config.Table
 	}
 	tests := []struct {
 		name    string
@@ -577,7 +600,7 @@ func TestExtractor_CountTableRows(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.e.CountTableRows(tt.args.tableSchema, tt.args.tableName)
+			got, err := tt.e.CountTableRows(tt.args.table)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Extractor.CountTableRows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+const sourceFailoverInterval = 2 * time.Second
+
+// how long the extractor looks for the new primary among SourceCandidates after the binlog stream fails.
+var sourceFailoverTimeout = 2 * time.Minute
+
+func validateSourceCandidates(cfg *config.MySQLDriverConfig) (errs []error) {
+	for _, addr := range cfg.SourceCandidates {
+		if _, _, err := parseHostPort("SourceCandidates", addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(cfg.SourceCandidates) > 0 {
+		if cfg.BinlogRelay {
+			errs = append(errs, fmt.Errorf("SourceCandidates is not supported with BinlogRelay"))
+		}
+		if cfg.ConnectionConfig != nil && cfg.ConnectionConfig.Tunnel != nil {
+			errs = append(errs, fmt.Errorf("SourceCandidates is not supported with ConnectionConfig.Tunnel"))
+		}
+	}
+	return errs
+}
+
+// checkSourceCandidate tells whether a server, by its status, can take over as the source of the binlog stream
+// which has extracted the transactions of gtidSet. It must have all of them, so that the stream goes on from
+// gtidSet without a gap, and be a valid binlog source if it is a replica. failover is true if it is another
+// server than the one of sourceUUID, e.g. a replica promoted to the primary, whose server_uuid is new in the
+// GTID set from now on.
+func checkSourceCandidate(status *base.ReplicaStatus, gtidSet string, sourceUUID string) (failover bool, err error) {
+	if err := status.ValidateAsBinlogSource(); err != nil {
+		return false, err
+	}
+	missing, err := base.GtidSetSubtract(gtidSet, status.GtidSet)
+	if err != nil {
+		return false, err
+	}
+	if missing != "" {
+		return false, fmt.Errorf("gtid_executed %v lacks the extracted transactions %v", status.GtidSet, missing)
+	}
+	return status.ServerUUID != sourceUUID, nil
+}
+
+// sourceCandidates returns the addresses to look for the source at: the source itself first, e.g. a VIP, then
+// SourceCandidates.
+func (e *Extractor) sourceCandidates() []string {
+	source := net.JoinHostPort(e.mysqlContext.ConnectionConfig.Host, strconv.Itoa(e.mysqlContext.ConnectionConfig.Port))
+	addrs := []string{source}
+	for _, addr := range e.mysqlContext.SourceCandidates {
+		if addr != source {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// handleSourceFailover resumes the binlog stream, which failed with streamErr, on the source or on one of
+// SourceCandidates. See checkSourceCandidate. streamErr is returned if SourceCandidates is not set or none
+// is found in sourceFailoverTimeout. It returns nil if streaming should go on with the reconnected binlog reader.
+func (e *Extractor) handleSourceFailover(streamErr error) error {
+	if len(e.mysqlContext.SourceCandidates) == 0 || e.isMariaDB() {
+		return streamErr
+	}
+	gtidSet := e.binlogReader.GetExtractedGtidSet()
+	if gtidSet == "" {
+		e.logger.Warnf("mysql.extractor: the binlog is not streamed by GTID. SourceCandidates is ignored")
+		return streamErr
+	}
+	e.logger.Warnf("mysql.extractor: the binlog stream from the source failed. looking for the source in %v."+
+		" extracted: %v. err: %v", e.sourceCandidates(), gtidSet, streamErr)
+
+	deadline := time.Now().Add(sourceFailoverTimeout)
+	for !e.shutdown {
+		for _, addr := range e.sourceCandidates() {
+			err := e.tryResumeOn(addr, gtidSet)
+			if err == nil {
+				return nil
+			}
+			e.logger.Warnf("mysql.extractor: SourceCandidates: %v can not be the source: %v", addr, err)
+		}
+		if time.Now().After(deadline) {
+			e.execCtx.Emit("the binlog stream from the source failed, and none of SourceCandidates can be the source")
+			return fmt.Errorf("none of SourceCandidates can be the source in %v: %v", sourceFailoverTimeout, streamErr)
+		}
+		time.Sleep(sourceFailoverInterval)
+	}
+	return nil
+}
+
+// tryResumeOn resumes the binlog stream from gtidSet on the server at addr, if it can be the source.
+func (e *Extractor) tryResumeOn(addr string, gtidSet string) error {
+	host, port, err := parseHostPort("SourceCandidates", addr)
+	if err != nil {
+		return err
+	}
+	connectionConfig := *e.mysqlContext.ConnectionConfig
+	connectionConfig.Host, connectionConfig.Port = host, port
+	db, err := e.connectSource(&connectionConfig, &connectionConfig)
+	if err != nil {
+		return err
+	}
+	status, err := base.GetReplicaStatus(db)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	failover, err := checkSourceCandidate(status, gtidSet, e.sourceUUID)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	adminDB := db
+	if connectionConfig.AdminUser != "" {
+		if adminDB, err = e.connectSource(&connectionConfig, connectionConfig.AdminConfig()); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
+	e.binlogReader.Close()
+	oldConfig, oldUUID := e.mysqlContext.ConnectionConfig, e.sourceUUID
+	e.mysqlContext.ConnectionConfig = &connectionConfig
+	binlogReader, err := e.newBinlogReader()
+	if err == nil {
+		err = binlogReader.ConnectBinlogStreamer(base.BinlogCoordinatesX{GtidSet: gtidSet})
+	}
+	if err != nil {
+		e.mysqlContext.ConnectionConfig = oldConfig
+		if adminDB != db {
+			adminDB.Close()
+		}
+		db.Close()
+		return err
+	}
+	e.binlogReader = binlogReader
+	oldDB, oldAdminDB := e.db, e.adminDB
+	e.db, e.adminDB, e.sourceUUID = db, adminDB, status.ServerUUID
+	if oldAdminDB != oldDB {
+		oldAdminDB.Close()
+	}
+	oldDB.Close()
+
+	if failover {
+		e.logger.Warnf("mysql.extractor: source failover from %v:%v (server_uuid %v) to %v (server_uuid %v)."+
+			" resumed from %v", oldConfig.Host, oldConfig.Port, oldUUID, addr, status.ServerUUID, gtidSet)
+		e.execCtx.Emit("source failover from %v:%v (server_uuid %v) to %v (server_uuid %v). resumed from %v",
+			oldConfig.Host, oldConfig.Port, oldUUID, addr, status.ServerUUID, gtidSet)
+	} else {
+		e.logger.Infof("mysql.extractor: reconnected to the source %v. resumed from %v", addr, gtidSet)
+	}
+	return nil
+}
+
+// connectSource connects to the source of connectionConfig as user, with the session settings of the extractor.
+func (e *Extractor) connectSource(connectionConfig *umconf.ConnectionConfig, user *umconf.ConnectionConfig) (*gosql.DB, error) {
+	uri := sql.UriWithTimeZone(user.GetDBUri(), e.mysqlContext.TimeZone)
+	uri, err := sql.UriWithSessionVars(uri, e.mysqlContext.SrcSessionVars)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.CreateDB(uri)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%v:%v: %v", connectionConfig.Host, connectionConfig.Port, err)
+	}
+	return db, nil
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+)
+
+// The source A fails over to its replica B, which then writes the transactions of its own server_uuid.
+func Test_checkSourceCandidate(t *testing.T) {
+	const (
+		uuidA = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+		uuidB = "4f22fb58-82db-22f2-af44-d91bb0530673"
+	)
+	extracted := uuidA + ":1-100"
+	tests := []struct {
+		name         string
+		status       *base.ReplicaStatus
+		wantFailover bool
+		// a part of the error
+		wantErr string
+	}{
+		{"reconnected to the source", &base.ReplicaStatus{ServerUUID: uuidA, GtidSet: uuidA + ":1-120"}, false, ""},
+		{"promoted replica", &base.ReplicaStatus{ServerUUID: uuidB, LogSlaveUpdates: true,
+			GtidSet: uuidA + ":1-100,\n" + uuidB + ":1-5"}, true, ""},
+		{"lagging replica", &base.ReplicaStatus{ServerUUID: uuidB, LogSlaveUpdates: true,
+			GtidSet: uuidA + ":1-90,\n" + uuidB + ":1-5"}, false, uuidA + ":91-100"},
+		{"replica without log_slave_updates", &base.ReplicaStatus{ServerUUID: uuidB, MasterUUID: uuidA,
+			GtidSet: uuidA + ":1-100"}, false, "log_slave_updates is OFF"},
+	}
+	for _, tt := range tests {
+		failover, err := checkSourceCandidate(tt.status, extracted, uuidA)
+		if tt.wantErr == "" {
+			if err != nil || failover != tt.wantFailover {
+				t.Errorf("%v: checkSourceCandidate() = %v, %v, want %v", tt.name, failover, err, tt.wantFailover)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: checkSourceCandidate() err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
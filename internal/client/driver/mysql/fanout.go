@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// PublishReply acknowledges m, a message of the extractor, as the destination task taskID, i.e. its task type
+// (e.g. "Dest"), which is unique in the job and kept by a restarted task. requestAll counts the distinct task
+// IDs of the replies.
+func PublishReply(nc *gonats.Conn, m *gonats.Msg, taskID string) error {
+	return nc.Publish(m.Reply, []byte(taskID))
+}
+
+// requestAll publishes data to subject and waits for the replies of nReplies subscribers, i.e. destination tasks
+// of the job. A reply carries the task ID of the destination (see PublishReply), so a destination replying twice,
+// e.g. a restarted one while the old one is still subscribed, counts once. It returns gonats.ErrTimeout if not all
+// of them reply in time.
+func requestAll(nc *gonats.Conn, subject string, data []byte, nReplies int, timeout time.Duration) error {
+	if nReplies <= 1 {
+		_, err := nc.Request(subject, data, timeout)
+		return err
+	}
+
+	inbox := gonats.NewInbox()
+	sub, err := nc.SubscribeSync(inbox)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	if err := nc.PublishRequest(subject, inbox, data); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	replied := make(map[string]bool, nReplies)
+	for len(replied) < nReplies {
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			return gonats.ErrTimeout
+		}
+		m, err := sub.NextMsg(remaining)
+		if err != nil {
+			return err
+		}
+		replied[string(m.Data)] = true
+	}
+	return nil
+}
+
+// filterDestDoDb removes the events of tables not in DestDoDb from binlogEntry. The (maybe empty) transaction
+// is still applied, so that its GTID is recorded as executed.
+func (a *Applier) filterDestDoDb(binlogEntry *binlog.BinlogEntry) {
+	if len(a.mysqlContext.DestDoDb) == 0 {
+		return
+	}
+	events := binlogEntry.Events[:0]
+	for _, event := range binlogEntry.Events {
+		schemaName := event.DatabaseName
+		if schemaName == "" {
+			schemaName = event.CurrentSchema
+		}
+		if schemaName == "" || config.MatchDestDoDb(a.mysqlContext.DestDoDb, schemaName, event.TableName) {
+			// A statement on no schema (e.g. CREATE USER) is applied by every destination.
+			events = append(events, event)
+		} else {
+			a.logger.Debugf("mysql.applier: skip an event on %v.%v not in DestDoDb", schemaName, event.TableName)
+		}
+	}
+	binlogEntry.Events = events
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gnatsd "github.com/nats-io/gnatsd/server"
+	gonats "github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func Test_requestAll(t *testing.T) {
+	s := gnatsd.New(&gnatsd.Options{Host: "127.0.0.1", Port: gnatsd.RANDOM_PORT, NoLog: true, NoSigs: true})
+	go s.Start()
+	defer s.Shutdown()
+	if !s.ReadyForConnections(10 * time.Second) {
+		t.Fatal("nats server is not ready")
+	}
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	// A MySQL and a Kafka destination subscribe as the appliers do (the real ones need a database and a broker).
+	// The Kafka one acks only when ready.
+	// Until ready, the MySQL one replies twice, as a restarted task still subscribed twice would.
+	received := make(chan string, 10)
+	var kafkaReady int32
+	if _, err := nc.Subscribe("job_incr_hete", func(m *gonats.Msg) {
+		received <- "mysql:" + string(m.Data)
+		PublishReply(nc, m, "Dest")
+		if atomic.LoadInt32(&kafkaReady) == 0 {
+			PublishReply(nc, m, "Dest")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := nc.Subscribe("job_incr_hete", func(m *gonats.Msg) {
+		received <- "kafka:" + string(m.Data)
+		if atomic.LoadInt32(&kafkaReady) == 1 {
+			PublishReply(nc, m, "Dest_kafka")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := requestAll(nc, "job_incr_hete", []byte("tx1"), 2, 200*time.Millisecond); err != gonats.ErrTimeout {
+		t.Errorf("requestAll() error = %v with 2 acks of mysql only, want %v", err, gonats.ErrTimeout)
+	}
+	atomic.StoreInt32(&kafkaReady, 1)
+	if err := requestAll(nc, "job_incr_hete", []byte("tx2"), 2, time.Second); err != nil {
+		t.Errorf("requestAll() error = %v", err)
+	}
+	// A single destination is requested as before.
+	if err := requestAll(nc, "job_incr_hete", []byte("tx3"), 0, time.Second); err != nil {
+		t.Errorf("requestAll() error = %v", err)
+	}
+
+	got := map[string]bool{}
+	timeout := time.After(time.Second)
+	for len(got) < 6 {
+		select {
+		case msg := <-received:
+			got[msg] = true
+		case <-timeout:
+			t.Fatalf("some messages are not received. received: %v", got)
+		}
+	}
+	for _, want := range []string{"mysql:tx1", "kafka:tx1", "mysql:tx2", "kafka:tx2", "mysql:tx3", "kafka:tx3"} {
+		if !got[want] {
+			t.Errorf("%v is not received. received: %v", want, got)
+		}
+	}
+}
+
+func TestApplier_filterDestDoDb(t *testing.T) {
+	a := &Applier{
+		logger: logrus.NewEntry(logrus.New()),
+		mysqlContext: &config.MySQLDriverConfig{
+			DestDoDb: []*config.DataSource{
+				{TableSchema: "db1"},
+				{TableSchema: "db2", Tables: []*config.Table{{TableName: "regex:^t\\d$"}}},
+			},
+		},
+	}
+	entry := &binlog.BinlogEntry{
+		Events: []binlog.DataEvent{
+			binlog.NewDataEvent("db1", "a", binlog.InsertDML, 1),
+			binlog.NewDataEvent("db2", "t1", binlog.InsertDML, 1),
+			binlog.NewDataEvent("db2", "a", binlog.InsertDML, 1),
+			binlog.NewDataEvent("db3", "t1", binlog.InsertDML, 1),
+			binlog.NewQueryEvent("db2", "create database db2_1", binlog.NotDML),
+			binlog.NewQueryEvent("", "create user u1", binlog.NotDML),
+		},
+	}
+	a.filterDestDoDb(entry)
+
+	var got []string
+	for _, event := range entry.Events {
+		got = append(got, event.DatabaseName+"."+event.TableName+event.Query)
+	}
+	want := []string{"db1.a", "db2.t1", ".create database db2_1", ".create user u1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
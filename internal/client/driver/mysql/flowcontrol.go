@@ -0,0 +1,280 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+)
+
+// IncrAck is published by a destination on "<subject>_incr_ack" when it has applied transactions
+// of the incremental copy. See inFlightWindow. It carries the position of the destination in the stream
+// rather than a difference, so a lost IncrAck is made up by the next one, and resending one is harmless.
+type IncrAck struct {
+	// the task type of the destination, e.g. "Dest"
+	Dest string
+	// the BinlogEntries.Stream of the entries applied
+	Stream string
+	// the number of the entries of Stream applied by the destination, including those sent before the
+	// destination took the first message of Stream (see BinlogEntries.EntrySeq)
+	Applied int64
+}
+
+// incrAckResendInterval is how often a destination publishes its last IncrAck again, in case it is lost.
+const incrAckResendInterval = 5 * time.Second
+
+// PublishIncrAck tells the extractor of the job the position of a destination.
+func PublishIncrAck(nc *gonats.Conn, subject string, ack *IncrAck) error {
+	bs, err := Encode(ack)
+	if err != nil {
+		return err
+	}
+	return nc.Publish(fmt.Sprintf("%s_incr_ack", subject), bs)
+}
+
+// IncrAcker keeps the position of a destination in the stream of the incremental copy, for its IncrAcks.
+// A nil IncrAcker acks nothing.
+type IncrAcker struct {
+	dest string
+
+	lock sync.Mutex
+	ack  *IncrAck
+}
+
+func NewIncrAcker(dest string) *IncrAcker {
+	return &IncrAcker{dest: dest}
+}
+
+// Receive is called with each message of the incremental copy taken by the destination, in order. The first
+// message of a stream, i.e. of a new run of the extractor or the first one taken by a restarted destination,
+// starts the position at the entries sent before it.
+func (k *IncrAcker) Receive(entries *binlog.BinlogEntries) {
+	if k == nil || entries.Seq == 0 {
+		return // not numbered
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.ack == nil || k.ack.Stream != entries.Stream {
+		k.ack = &IncrAck{Dest: k.dest, Stream: entries.Stream, Applied: entries.EntrySeq}
+	}
+}
+
+// Applied records n entries applied, and returns the IncrAck to publish, or nil if k is nil.
+func (k *IncrAcker) Applied(n int) *IncrAck {
+	if k == nil {
+		return nil
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.ack == nil {
+		k.ack = &IncrAck{Dest: k.dest}
+	}
+	k.ack.Applied += int64(n)
+	ack := *k.ack
+	return &ack
+}
+
+// Last returns the last IncrAck, or nil if nothing has been applied yet.
+func (k *IncrAcker) Last() *IncrAck {
+	if k == nil {
+		return nil
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.ack == nil {
+		return nil
+	}
+	ack := *k.ack
+	return &ack
+}
+
+// ResendIncrAck publishes the last IncrAck of k every incrAckResendInterval until shutdownCh is closed.
+func ResendIncrAck(nc *gonats.Conn, subject string, k *IncrAcker, shutdownCh chan struct{}, logger *logrus.Entry) {
+	ticker := time.NewTicker(incrAckResendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-shutdownCh:
+			return
+		case <-ticker.C:
+			if ack := k.Last(); ack != nil {
+				if err := PublishIncrAck(nc, subject, ack); err != nil {
+					logger.Warnf("failed to resend the incr ack: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// inFlightWindow limits the transactions which have been sent to the destinations but not applied
+// by all of them yet. See MySQLDriverConfig.InFlightWindow. A nil window has no limit.
+type inFlightWindow struct {
+	size  int64
+	nDest int
+
+	lock sync.Mutex
+	// the entries of stream sent, and applied by each destination. Those of other streams are ignored.
+	stream string
+	sent   int64
+	acked  map[string]int64
+	// signaled on each ack
+	ackCh chan struct{}
+}
+
+func newInFlightWindow(size int, nDest int) *inFlightWindow {
+	if size <= 0 {
+		return nil
+	}
+	if nDest < 1 {
+		nDest = 1
+	}
+	return &inFlightWindow{
+		size:  int64(size),
+		nDest: nDest,
+		acked: make(map[string]int64),
+		ackCh: make(chan struct{}, 1),
+	}
+}
+
+// inFlight returns the number of transactions not applied by the slowest destination.
+func (w *inFlightWindow) inFlight() int64 {
+	if w == nil {
+		return 0
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var minAcked int64
+	if len(w.acked) >= w.nDest {
+		first := true
+		for _, n := range w.acked {
+			if first || n < minAcked {
+				minAcked = n
+				first = false
+			}
+		}
+	}
+	// A message resent after a timeout might be applied (and acked) twice.
+	if minAcked > w.sent {
+		return 0
+	}
+	return w.sent - minAcked
+}
+
+// wait blocks while the window is full. It returns false on shutdown.
+func (w *inFlightWindow) wait(shutdownCh chan struct{}) bool {
+	if w == nil {
+		return true
+	}
+	for w.inFlight() >= w.size {
+		select {
+		case <-w.ackCh:
+		case <-shutdownCh:
+			return false
+		}
+	}
+	return true
+}
+
+// reset starts the window over for stream, a new run of the extractor.
+func (w *inFlightWindow) reset(stream string) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	w.stream = stream
+	w.sent = 0
+	w.acked = make(map[string]int64)
+	w.lock.Unlock()
+}
+
+// add records n transactions sent to the destinations.
+func (w *inFlightWindow) add(n int) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	w.sent += int64(n)
+	w.lock.Unlock()
+}
+
+func (w *inFlightWindow) ack(ack *IncrAck) {
+	if w == nil {
+		return
+	}
+	w.lock.Lock()
+	if ack.Stream != w.stream || ack.Applied <= w.acked[ack.Dest] {
+		w.lock.Unlock()
+		return // of another stream, or resent
+	}
+	w.acked[ack.Dest] = ack.Applied
+	w.lock.Unlock()
+	select {
+	case w.ackCh <- struct{}{}:
+	default:
+	}
+}
+
+// subscribeIncrAck counts the transactions applied by the destinations into the in-flight window and the
+// semisync tracker.
+func (e *Extractor) subscribeIncrAck() error {
+	_, err := e.natsConn.Subscribe(fmt.Sprintf("%s_incr_ack", e.subject), func(m *gonats.Msg) {
+		ack := &IncrAck{}
+		if err := Decode(m.Data, ack); err != nil {
+			e.logger.Errorf("mysql.extractor: bad incr ack: %v", err)
+			return
+		}
+		if ack.Stream != e.incrStream {
+			return // of a previous run of the extractor
+		}
+		e.inFlight.ack(ack)
+		e.semiSync.Applied(ack.Dest, ack.Applied)
+	})
+	return err
+}
+
+// ackIncr tells the extractor that n transactions have been applied (committed or skipped).
+func (a *Applier) ackIncr(n int) {
+	if n == 0 {
+		return
+	}
+	atomic.AddInt64(&a.incrAckStats.Transactions, int64(n))
+	a.publishIncrAck(n)
+}
+
+// ackIncrPiece tells the extractor that a piece of a big transaction has been applied, before the transaction
+// commits. It makes room in the in-flight window for the next pieces. See BigTxSplittingSize.
+func (a *Applier) ackIncrPiece() {
+	atomic.AddInt64(&a.incrAckStats.Pieces, 1)
+	a.publishIncrAck(1)
+}
+
+// ackIncrSkipped acks a transaction or a piece of one which is not applied.
+func (a *Applier) ackIncrSkipped(binlogEntry *binlog.BinlogEntry) {
+	if binlogEntry.Partial {
+		a.ackIncrPiece()
+	} else {
+		a.ackIncr(1)
+	}
+}
+
+func (a *Applier) publishIncrAck(n int) {
+	ack := a.incrAcker.Applied(n)
+	if a.natsConn == nil || ack == nil {
+		return
+	}
+	if err := PublishIncrAck(a.natsConn, a.subject, ack); err != nil {
+		a.logger.Warnf("mysql.applier: failed to ack %v transactions: %v", n, err)
+	}
+}
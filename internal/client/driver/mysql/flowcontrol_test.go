@@ -0,0 +1,315 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func TestInFlightWindow(t *testing.T) {
+	if w := newInFlightWindow(0, 1); w != nil {
+		t.Fatalf("newInFlightWindow(0) = %v, want nil", w)
+	}
+	var nilWindow *inFlightWindow
+	if !nilWindow.wait(nil) || nilWindow.inFlight() != 0 {
+		t.Errorf("a nil window limits nothing")
+	}
+
+	w := newInFlightWindow(3, 2)
+	w.reset("s1")
+	w.add(3)
+	w.ack(&IncrAck{Dest: "Dest", Stream: "s1", Applied: 2})
+	// Dest_kafka has not acked.
+	if got := w.inFlight(); got != 3 {
+		t.Errorf("inFlight() = %v, want 3", got)
+	}
+	w.ack(&IncrAck{Dest: "Dest_kafka", Stream: "s1", Applied: 1})
+	if got := w.inFlight(); got != 2 {
+		t.Errorf("inFlight() = %v, want 2", got)
+	}
+	// an ack resent, and one of a previous run of the extractor
+	w.ack(&IncrAck{Dest: "Dest_kafka", Stream: "s1", Applied: 1})
+	w.ack(&IncrAck{Dest: "Dest_kafka", Stream: "s0", Applied: 100})
+	if got := w.inFlight(); got != 2 {
+		t.Errorf("inFlight() = %v, want 2", got)
+	}
+	// an ack of Dest_kafka is lost. the next one makes it up.
+	w.ack(&IncrAck{Dest: "Dest_kafka", Stream: "s1", Applied: 3})
+	w.ack(&IncrAck{Dest: "Dest", Stream: "s1", Applied: 3})
+	if got := w.inFlight(); got != 0 {
+		t.Errorf("inFlight() = %v, want 0", got)
+	}
+	// a new run of the extractor
+	w.reset("s2")
+	w.add(1)
+	w.ack(&IncrAck{Dest: "Dest", Stream: "s1", Applied: 4})
+	w.ack(&IncrAck{Dest: "Dest_kafka", Stream: "s1", Applied: 4})
+	if got := w.inFlight(); got != 1 {
+		t.Errorf("inFlight() = %v, want 1", got)
+	}
+
+	w = newInFlightWindow(1, 1)
+	w.add(1)
+	shutdownCh := make(chan struct{})
+	close(shutdownCh)
+	if w.wait(shutdownCh) {
+		t.Errorf("wait() on a full window = true, want false on shutdown")
+	}
+}
+
+func TestIncrAcker(t *testing.T) {
+	k := NewIncrAcker("Dest")
+	if ack := k.Last(); ack != nil {
+		t.Errorf("Last() = %v, want nil", ack)
+	}
+	k.Receive(&binlog.BinlogEntries{Stream: "s1", Seq: 1, EntrySeq: 0})
+	k.Applied(2)
+	k.Receive(&binlog.BinlogEntries{Stream: "s1", Seq: 2, EntrySeq: 2})
+	if ack := k.Applied(1); *ack != (IncrAck{Dest: "Dest", Stream: "s1", Applied: 3}) {
+		t.Errorf("Applied() = %v, want 3 of s1", ack)
+	}
+	if ack := k.Last(); *ack != (IncrAck{Dest: "Dest", Stream: "s1", Applied: 3}) {
+		t.Errorf("Last() = %v, want 3 of s1", ack)
+	}
+
+	// a restarted destination takes message 5 of s1 first
+	k = NewIncrAcker("Dest")
+	k.Receive(&binlog.BinlogEntries{Stream: "s1", Seq: 5, EntrySeq: 10})
+	if ack := k.Applied(1); ack.Applied != 11 {
+		t.Errorf("Applied() = %v, want 11", ack)
+	}
+	// a new run of the extractor
+	k.Receive(&binlog.BinlogEntries{Stream: "s2", Seq: 1, EntrySeq: 0})
+	if ack := k.Applied(1); *ack != (IncrAck{Dest: "Dest", Stream: "s2", Applied: 1}) {
+		t.Errorf("Applied() = %v, want 1 of s2", ack)
+	}
+}
+
+// The applier takes the messages at once but applies the transactions slowly. The extractor must not
+// read more of the binlog than InFlightWindow and its buffer.
+func TestExtractor_inFlightWindow_slowApplier(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+
+	const window = 5
+	const nEntries = 30
+	cfg := (&config.MySQLDriverConfig{InFlightWindow: window, GroupMaxSize: 1, ReplChanBufferSize: 1}).SetDefault()
+	e := &Extractor{
+		logger:       logrus.NewEntry(logrus.New()),
+		subject:      "job",
+		mysqlContext: cfg,
+		dataChannel:  make(chan *binlog.BinlogEntry, cfg.ReplChanBufferSize),
+		waitCh:       make(chan *models.WaitResult, 1),
+		shutdownCh:   make(chan struct{}),
+		inFlight:     newInFlightWindow(cfg.InFlightWindow, cfg.DestCount),
+	}
+	var err error
+	if e.natsConn, err = gonats.Connect("nats://" + s.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.natsConn.Close()
+	if err := e.subscribeIncrAck(); err != nil {
+		t.Fatal(err)
+	}
+	e.natsConn.Flush()
+
+	// the slow applier
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	var received, applied int64
+	if _, err := nc.Subscribe("job_incr_hete", func(m *gonats.Msg) {
+		atomic.AddInt64(&received, 1)
+		nc.Publish(m.Reply, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nc.Flush()
+	stopApplier := make(chan struct{})
+	defer close(stopApplier)
+	go func() {
+		for {
+			select {
+			case <-stopApplier:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+			if atomic.LoadInt64(&applied) < atomic.LoadInt64(&received) {
+				n := atomic.AddInt64(&applied, 1)
+				// one ack in three is lost, and made up by the next one
+				if n%3 != 0 {
+					PublishIncrAck(nc, "job", &IncrAck{Dest: "Dest", Applied: n})
+				}
+			}
+		}
+	}()
+
+	go e.sendIncrEntries()
+
+	// the binlog reader
+	var read int64
+	go func() {
+		for i := 1; i <= nEntries; i++ {
+			e.dataChannel <- &binlog.BinlogEntry{Coordinates: base.BinlogCoordinateTx{GNO: int64(i)}}
+			atomic.AddInt64(&read, 1)
+		}
+	}()
+
+	// in flight: the window; buffered: dataChannel and the entry being sent.
+	maxBacklog := int64(window + cap(e.dataChannel) + 1)
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt64(&applied) < nEntries {
+		if time.Now().After(deadline) {
+			t.Fatalf("applied %v of %v transactions before timeout", atomic.LoadInt64(&applied), nEntries)
+		}
+		a, r, rd := atomic.LoadInt64(&applied), atomic.LoadInt64(&received), atomic.LoadInt64(&read)
+		if r-a > window {
+			t.Fatalf("%v transactions in flight, want at most %v", r-a, window)
+		}
+		if rd-a > maxBacklog {
+			t.Fatalf("%v transactions read and not applied, want at most %v", rd-a, maxBacklog)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestApplyBatch_checkPiece(t *testing.T) {
+	sid := uuid.Must(uuid.FromString("50000000-0000-0000-0000-000000000000"))
+	piece := func(gno int64, index int, partial bool) *binlog.BinlogEntry {
+		return &binlog.BinlogEntry{Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: gno}, Index: index, Partial: partial}
+	}
+	b := &applyBatch{}
+	if _, err := b.checkPiece(piece(1, 1, true)); err == nil {
+		t.Errorf("checkPiece() of a later piece without the first one: want an error")
+	}
+	if skip, err := b.checkPiece(piece(1, 0, true)); skip || err != nil {
+		t.Errorf("checkPiece() of the first piece = %v, %v, want false, nil", skip, err)
+	}
+
+	// the first piece has been applied
+	b.bigTxGtid, b.nextPiece = piece(1, 0, true).Coordinates.GetGtidForThisTx(), 1
+	tests := []struct {
+		name     string
+		entry    *binlog.BinlogEntry
+		wantSkip bool
+		wantErr  bool
+	}{
+		{"next piece", piece(1, 1, true), false, false},
+		{"sent again", piece(1, 0, true), true, false},
+		{"a piece missed", piece(1, 2, false), false, true},
+		{"another transaction", piece(2, 0, false), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skip, err := b.checkPiece(tt.entry)
+			if skip != tt.wantSkip || (err != nil) != tt.wantErr {
+				t.Errorf("checkPiece() = %v, %v, want %v, error %v", skip, err, tt.wantSkip, tt.wantErr)
+			}
+		})
+	}
+}
+
+// A big transaction is sent in more pieces than InFlightWindow. The applier acks each piece before it
+// commits the transaction at the last one, so the window does not stall.
+func TestExtractor_inFlightWindow_bigTx(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+
+	const window = 3
+	const nPieces = 20
+	cfg := (&config.MySQLDriverConfig{InFlightWindow: window, GroupMaxSize: 1, ReplChanBufferSize: 1}).SetDefault()
+	e := &Extractor{
+		logger:       logrus.NewEntry(logrus.New()),
+		subject:      "job",
+		mysqlContext: cfg,
+		dataChannel:  make(chan *binlog.BinlogEntry, cfg.ReplChanBufferSize),
+		waitCh:       make(chan *models.WaitResult, 1),
+		shutdownCh:   make(chan struct{}),
+		inFlight:     newInFlightWindow(cfg.InFlightWindow, cfg.DestCount),
+	}
+	var err error
+	if e.natsConn, err = gonats.Connect("nats://" + s.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.natsConn.Close()
+	if err := e.subscribeIncrAck(); err != nil {
+		t.Fatal(err)
+	}
+	e.natsConn.Flush()
+
+	// the applier, which holds the transaction open until the last piece
+	a := &Applier{
+		logger:    logrus.NewEntry(logrus.New()),
+		subject:   "job",
+		execCtx:   &common.ExecContext{TaskType: "Dest"},
+		incrAcker: NewIncrAcker("Dest"),
+	}
+	if a.natsConn, err = gonats.Connect("nats://" + s.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	defer a.natsConn.Close()
+	var received int64
+	if _, err := a.natsConn.Subscribe("job_incr_hete", func(m *gonats.Msg) {
+		a.natsConn.Publish(m.Reply, nil)
+		if atomic.AddInt64(&received, 1) < nPieces {
+			a.ackIncrPiece()
+		} else {
+			a.ackIncr(1)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	a.natsConn.Flush()
+
+	go e.sendIncrEntries()
+
+	go func() {
+		entry := binlog.NewBinlogEntryAt(base.BinlogCoordinateTx{GNO: 1})
+		for i := 0; i < nPieces; i++ {
+			entry.Partial = i < nPieces-1
+			e.dataChannel <- entry
+			entry = entry.NextPiece()
+		}
+	}()
+
+	// wait until the last piece is counted as sent too, so the extractor does not publish it on a closed connection
+	sent := func() int64 {
+		e.inFlight.lock.Lock()
+		defer e.inFlight.lock.Unlock()
+		return e.inFlight.sent
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for sent() < nPieces || e.inFlight.inFlight() > 0 || atomic.LoadInt64(&received) < nPieces {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %v of %v pieces before timeout", atomic.LoadInt64(&received), nPieces)
+		}
+		if n := e.inFlight.inFlight(); n > window {
+			t.Fatalf("%v pieces in flight, want at most %v", n, window)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := atomic.LoadInt64(&a.incrAckStats.Pieces); got != nPieces-1 {
+		t.Errorf("IncrAckStats.Pieces = %v, want %v", got, nPieces-1)
+	}
+	if got := atomic.LoadInt64(&a.incrAckStats.Transactions); got != 1 {
+		t.Errorf("IncrAckStats.Transactions = %v, want 1", got)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+)
+
+// healthPingTimeout bounds the ping of a database by CheckHealth, so that a probe gets an answer
+// before its own timeout.
+const healthPingTimeout = 3 * time.Second
+
+// latchedError keeps the first error set.
+type latchedError struct {
+	lock sync.Mutex
+	err  error
+}
+
+func (l *latchedError) set(err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+func (l *latchedError) get() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.err
+}
+
+// checkConnections returns an error if the connection to the database (named by side) or to nats is not alive.
+func checkConnections(side string, db *gosql.DB, natsConn *gonats.Conn) error {
+	if db == nil {
+		return fmt.Errorf("not connected to the %v", side)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), healthPingTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("lost the connection to the %v: %v", side, err)
+	}
+	if natsConn == nil || !natsConn.IsConnected() {
+		return fmt.Errorf("not connected to nats")
+	}
+	return nil
+}
+
+// CheckHealth implements driver.HealthChecker. The extractor is not healthy after an unrecoverable error,
+// or without its connections to the source and nats.
+func (e *Extractor) CheckHealth() error {
+	if err := e.failure.get(); err != nil {
+		return fmt.Errorf("failed: %v", err)
+	}
+	return checkConnections("source", e.db, e.natsConn)
+}
+
+// CheckHealth implements driver.HealthChecker. The applier is not healthy after an unrecoverable error,
+// without its connections to the destination and nats, or while the replication lag exceeds HealthMaxLag.
+func (a *Applier) CheckHealth() error {
+	if err := a.failure.get(); err != nil {
+		return fmt.Errorf("failed: %v", err)
+	}
+	if err := a.diskFull.get(); err != nil {
+		return fmt.Errorf("paused as the destination is out of space: %v", err)
+	}
+	if err := checkConnections("destination", a.db, a.natsConn); err != nil {
+		return err
+	}
+	if maxLag := time.Duration(a.mysqlContext.HealthMaxLag) * time.Second; maxLag > 0 {
+		if lag := a.replicationLag.get(time.Now()); lag > maxLag {
+			return fmt.Errorf("replication lag %v exceeds HealthMaxLag %v", lag.Truncate(time.Second), maxLag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// healthTestDriver is a database of which the connections answer pings only.
+type healthTestDriver struct{}
+
+type healthTestConn struct{}
+
+func (healthTestDriver) Open(name string) (driver.Conn, error) { return healthTestConn{}, nil }
+
+func (healthTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (healthTestConn) Close() error { return nil }
+
+func (healthTestConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func init() {
+	gosql.Register("healthtest", healthTestDriver{})
+}
+
+func TestApplier_CheckHealth(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+
+	newApplier := func(t *testing.T) *Applier {
+		db, err := gosql.Open("healthtest", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		nc, err := gonats.Connect("nats://" + s.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Applier{mysqlContext: &config.MySQLDriverConfig{HealthMaxLag: 10}, db: db, natsConn: nc}
+	}
+
+	tests := []struct {
+		name string
+		// breaks the applier
+		f    func(a *Applier)
+		want string
+	}{
+		{"healthy", func(a *Applier) {
+			// a lag under HealthMaxLag
+			a.replicationLag.received(time.Now().Add(-5 * time.Second).Unix())
+		}, ""},
+		{"not connected", func(a *Applier) { a.db = nil }, "not connected to the destination"},
+		{"lost destination", func(a *Applier) { a.db.Close() }, "lost the connection to the destination"},
+		{"lost nats", func(a *Applier) { a.natsConn.Close() }, "not connected to nats"},
+		{"lag", func(a *Applier) {
+			a.replicationLag.received(time.Now().Add(-time.Minute).Unix())
+		}, "exceeds HealthMaxLag 10s"},
+		{"failed", func(a *Applier) {
+			a.failure.set(fmt.Errorf("the first error"))
+			a.failure.set(fmt.Errorf("the second error"))
+		}, "failed: the first error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newApplier(t)
+			defer a.natsConn.Close()
+			if err := a.CheckHealth(); err != nil {
+				t.Fatalf("CheckHealth() of a new applier = %v", err)
+			}
+			tt.f(a)
+			err := a.CheckHealth()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("CheckHealth() = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("CheckHealth() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// the lag is not checked without HealthMaxLag
+	a := newApplier(t)
+	defer a.natsConn.Close()
+	a.mysqlContext.HealthMaxLag = 0
+	a.replicationLag.received(time.Now().Add(-time.Hour).Unix())
+	if err := a.CheckHealth(); err != nil {
+		t.Errorf("CheckHealth() without HealthMaxLag = %v", err)
+	}
+}
+
+func TestExtractor_CheckHealth(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+	db, err := gosql.Open("healthtest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &Extractor{db: db, natsConn: nc}
+	if err := e.CheckHealth(); err != nil {
+		t.Fatalf("CheckHealth() = %v", err)
+	}
+	nc.Close()
+	if err := e.CheckHealth(); err == nil || err.Error() != "not connected to nats" {
+		t.Errorf("CheckHealth() without nats = %v", err)
+	}
+	db.Close()
+	if err := e.CheckHealth(); err == nil || !strings.Contains(err.Error(), "lost the connection to the source") {
+		t.Errorf("CheckHealth() without the source = %v", err)
+	}
+	e.failure.set(fmt.Errorf("binlog purged"))
+	if err := e.CheckHealth(); err == nil || err.Error() != "failed: binlog purged" {
+		t.Errorf("CheckHealth() after a failure = %v", err)
+	}
+}
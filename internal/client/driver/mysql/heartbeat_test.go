@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	sqle "github.com/actiontech/dtle/internal/client/driver/mysql/sqle/inspector"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// On an idle source, the heartbeats keep the binlog connection alive. It is not reconnected, which would show
+// as a new binlog dump thread on the source.
+func TestBinlogReader_heartbeatOnIdleSource(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	var gtidMode, gtidExecuted string
+	if err := db.QueryRow("select @@global.gtid_mode, @@global.gtid_executed").Scan(&gtidMode, &gtidExecuted); err != nil {
+		t.Fatal(err)
+	}
+	if gtidMode != "ON" {
+		t.Skipf("gtid_mode is %v", gtidMode)
+	}
+	dumpThreads := func() map[int64]bool {
+		rows, err := db.Query("select ID from information_schema.PROCESSLIST where COMMAND like 'Binlog Dump%'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		ids := make(map[int64]bool)
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			ids[id] = true
+		}
+		return ids
+	}
+	newThreads := func(before map[int64]bool) (ids []int64) {
+		for id := range dumpThreads() {
+			if !before[id] {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+
+	before := dumpThreads()
+	cfg := (&config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		HeartbeatPeriod:  1,
+	}).SetDefault()
+	r, err := binlog.NewMySQLReader(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg,
+		logrus.NewEntry(logrus.New()), nil, sqle.NewContext(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.ConnectBinlogStreamer(base.BinlogCoordinatesX{GtidSet: gtidExecuted}); err != nil {
+		t.Fatal(err)
+	}
+	go r.DataStreamEvents(make(chan *binlog.BinlogEntry, 100))
+
+	var thread []int64
+	for i := 0; i < 50 && len(thread) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		thread = newThreads(before)
+	}
+	if len(thread) != 1 {
+		t.Fatalf("binlog dump threads of the reader = %v, want one", thread)
+	}
+
+	// idle for three times the read timeout
+	time.Sleep(3 * 2 * time.Duration(cfg.HeartbeatPeriod) * time.Second)
+	if got := newThreads(before); len(got) != 1 || got[0] != thread[0] {
+		t.Errorf("binlog dump threads of the reader after being idle = %v, want %v. the connection was reconnected",
+			got, thread)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func validateDumpInsertIgnore(cfg *config.MySQLDriverConfig) (errs []error) {
+	for _, name := range cfg.DumpInsertIgnore {
+		if parts := strings.Split(name, "."); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("bad job argument: DumpInsertIgnore: %v. should be schema.table", name))
+		}
+	}
+	return errs
+}
+
+// dumpInsertIgnore tells whether the full copy of a table keeps the rows already on the destination.
+// See MySQLDriverConfig.DumpInsertIgnore.
+func (a *Applier) dumpInsertIgnore(schemaName string, tableName string) bool {
+	name := fmt.Sprintf("%v.%v", schemaName, tableName)
+	for _, n := range a.mysqlContext.DumpInsertIgnore {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpInsertVerb returns how the full copy writes the rows of a table: `replace`, or `insert ignore`.
+func (a *Applier) dumpInsertVerb(schemaName string, tableName string) string {
+	if a.dumpInsertIgnore(schemaName, tableName) {
+		return "insert ignore"
+	}
+	return "replace"
+}
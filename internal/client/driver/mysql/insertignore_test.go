@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"testing"
+)
+
+func TestApplier_dumpInsertIgnore(t *testing.T) {
+	skipWithoutMySQL(t)
+	bs := func(s string) *[]byte {
+		b := []byte(s)
+		return &b
+	}
+	for _, tt := range []struct {
+		name           string
+		loadDataInfile bool
+	}{{"insert", false}, {"loaddata", true}} {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newLoadDataTestApplier(t, tt.loadDataInfile)
+			a.mysqlContext.DumpInsertIgnore = []string{"dtle_test.t_append"}
+			if _, err := a.db.Exec("create database if not exists dtle_test;" +
+				"drop table if exists dtle_test.t_append;" +
+				"create table dtle_test.t_append (id int primary key, c varchar(32), u int, unique key (u))"); err != nil {
+				t.Fatalf("create table error = %v", err)
+			}
+
+			entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_append", ValuesX: [][]*[]byte{
+				{bs("1"), bs("a"), bs("1")},
+				{bs("2"), bs("b"), bs("2")},
+			}}
+			if err := a.ApplyEventQueries(a.db, entry); err != nil {
+				t.Fatalf("ApplyEventQueries() error = %v", err)
+			}
+			// appended to on the destination since the full copy
+			if _, err := a.db.Exec("update dtle_test.t_append set c = 'changed' where id = 2;" +
+				"insert into dtle_test.t_append values (3, 'appended', 3)"); err != nil {
+				t.Fatal(err)
+			}
+
+			// the full copy again, with a row conflicting on the unique key only
+			entry.ValuesX = append(entry.ValuesX, []*[]byte{bs("4"), bs("d"), bs("3")})
+			if err := a.ApplyEventQueries(a.db, entry); err != nil {
+				t.Fatalf("ApplyEventQueries() again error = %v", err)
+			}
+
+			want := map[int]string{1: "a", 2: "changed", 3: "appended"}
+			rows, err := a.db.Query("select id, c from dtle_test.t_append")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			got := make(map[int]string)
+			for rows.Next() {
+				var id int
+				var c string
+				if err := rows.Scan(&id, &c); err != nil {
+					t.Fatal(err)
+				}
+				got[id] = c
+			}
+			if len(got) != len(want) {
+				t.Fatalf("rows = %v, want %v", got, want)
+			}
+			for id, c := range want {
+				if got[id] != c {
+					t.Errorf("row %v = %q, want %q", id, got[id], c)
+				}
+			}
+		})
+	}
+}
@@ -37,13 +37,24 @@ func NewInspector(ctx *uconf.MySQLDriverConfig, logger *logrus.Entry) *Inspector
 }
 
 func (i *Inspector) InitDBConnections() (err error) {
-	inspectorUri := i.mysqlContext.ConnectionConfig.GetDBUri()
+	// the queries of the inspector are not part of the replication
+	inspectorUri, err := usql.UriWithSessionVars(i.mysqlContext.ConnectionConfig.AdminConfig().GetDBUri(),
+		i.mysqlContext.SrcSessionVars)
+	if err != nil {
+		return err
+	}
 	if i.db, err = usql.CreateDB(inspectorUri); err != nil {
 		return err
 	}
 	if err := i.validateConnection(); err != nil {
 		return err
 	}
+	if err := i.readAuroraVersion(); err != nil {
+		return err
+	}
+	if err := i.readLowerCaseTableNames(); err != nil {
+		return err
+	}
 	if err := i.validateGrants(); err != nil {
 		i.logger.Errorf("mysql.inspector: Unexpected error on validateGrants, got %v", err)
 		return err
@@ -65,6 +76,9 @@ func (i *Inspector) InitDBConnections() (err error) {
 	if err := i.validateBinlogs(); err != nil {
 		return err
 	}
+	if err := i.validateHeartbeatPeriod(); err != nil {
+		return err
+	}
 	i.logger.Printf("mysql.inspector: Initiated on %s:%d, version %+v", i.mysqlContext.ConnectionConfig.Host, i.mysqlContext.ConnectionConfig.Port, i.mysqlContext.MySQLVersion)
 	return nil
 }
@@ -88,6 +102,9 @@ func (i *Inspector) ValidateOriginalTable(databaseName, tableName string, table
 	}
 	// TODO why assign OriginalTableColumns twice (later getSchemaTablesAndMeta->readTableColumns)?
 	table.ColumnMap = uconf.BuildColumnMapIndex(table.ColumnMapFrom, table.OriginalTableColumns.Ordinals)
+	if err := uconf.ValidateEncryptColumns(table); err != nil {
+		return err
+	}
 
 
 	i.logger.Debugf("table: %s.%s. n_unique_keys: %d", table.TableSchema, table.TableName, len(uniqueKeys))
@@ -186,63 +203,103 @@ func (i *Inspector) validateConnection() error {
 	return nil
 }
 
-// validateGrants verifies the user by which we're executing has necessary grants
-// to do its thang.
-func (i *Inspector) validateGrants() error {
-	if i.mysqlContext.SkipPrivilegeCheck {
-		i.logger.Debugf("mysql.inspector: skipping priv check")
-		return nil
+// readLowerCaseTableNames reads lower_case_table_names, which decides whether table names are case sensitive.
+func (i *Inspector) readLowerCaseTableNames() error {
+	query := `select @@global.lower_case_table_names`
+	if err := i.db.QueryRow(query).Scan(&i.mysqlContext.LowerCaseTableNames); err != nil {
+		return err
 	}
+	i.logger.Debugf("mysql.inspector: lower_case_table_names: %v", i.mysqlContext.LowerCaseTableNames)
+	return nil
+}
 
-	query := `show grants for current_user()`
-	foundAll := false
-	foundSuper := false
-	foundReplicationClient := false
-	foundReplicationSlave := false
-	foundDBAll := false
+// sourceGrants are the grants of a user on the source.
+type sourceGrants struct {
+	all               bool
+	super             bool
+	replicationClient bool
+	replicationSlave  bool
+	selectPrivilege   bool
+}
 
-	err := usql.QueryRowsMap(i.db, query, func(rowMap usql.RowMap) error {
+func readSourceGrants(db usql.QueryAble) (*sourceGrants, error) {
+	g := &sourceGrants{}
+	query := `show grants for current_user()`
+	err := usql.QueryRowsMap(db, query, func(rowMap usql.RowMap) error {
 		for _, grantData := range rowMap {
 			grant := grantData.String
 			if strings.Contains(grant, `GRANT ALL PRIVILEGES ON`) {
-				foundAll = true
+				g.all = true
 			}
 			if strings.Contains(grant, `SUPER`) {
-				foundSuper = true
+				g.super = true
 			}
 			if strings.Contains(grant, `REPLICATION CLIENT`) {
-				foundReplicationClient = true
+				g.replicationClient = true
 			}
 			if strings.Contains(grant, `REPLICATION SLAVE`) {
-				foundReplicationSlave = true
+				g.replicationSlave = true
 			}
 			if ubase.StringContainsAll(grant, `SELECT`) {
-				foundDBAll = true
+				g.selectPrivilege = true
 			}
 		}
 		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	i.mysqlContext.HasSuperPrivilege = foundSuper
+	return g, nil
+}
 
-	if foundAll {
-		i.logger.Printf("mysql.inspector: User has ALL privileges")
+// validateReplication checks the grants of the user replicating from the source.
+func (g *sourceGrants) validateReplication(logger *logrus.Entry) error {
+	if g.all {
+		logger.Printf("mysql.inspector: User has ALL privileges")
 		return nil
 	}
-	if foundSuper && foundReplicationSlave && foundDBAll {
-		i.logger.Printf("mysql.inspector: User has SUPER, REPLICATION SLAVE privileges, and has SELECT privileges")
+	if g.super && g.replicationSlave && g.selectPrivilege {
+		logger.Printf("mysql.inspector: User has SUPER, REPLICATION SLAVE privileges, and has SELECT privileges")
 		return nil
 	}
-	if foundReplicationClient && foundReplicationSlave && foundDBAll {
-		i.logger.Printf("mysql.inspector: User has REPLICATION CLIENT, REPLICATION SLAVE privileges, and has SELECT privileges")
+	if g.replicationClient && g.replicationSlave && g.selectPrivilege {
+		logger.Printf("mysql.inspector: User has REPLICATION CLIENT, REPLICATION SLAVE privileges, and has SELECT privileges")
 		return nil
 	}
-	i.logger.Debugf("mysql.inspector: Privileges: super: %t, REPLICATION CLIENT: %t, REPLICATION SLAVE: %t, ALL on *.*: %t, ALL on *.*: %t", foundSuper, foundReplicationClient, foundReplicationSlave, foundAll, foundDBAll)
+	logger.Debugf("mysql.inspector: Privileges: super: %t, REPLICATION CLIENT: %t, REPLICATION SLAVE: %t, ALL on *.*: %t, ALL on *.*: %t", g.super, g.replicationClient, g.replicationSlave, g.all, g.selectPrivilege)
 	return fmt.Errorf("user has insufficient privileges for extractor. Needed: SUPER|REPLICATION CLIENT, REPLICATION SLAVE and ALL on *.*")
 }
 
+// validateGrants verifies the user by which we're executing has necessary grants
+// to do its thang.
+// With AdminUser, the inspector connects by it and checks only SELECT, for the schema queries and
+// the checksum. The replicating user is checked by the extractor.
+func (i *Inspector) validateGrants() error {
+	if i.mysqlContext.SkipPrivilegeCheck {
+		i.logger.Debugf("mysql.inspector: skipping priv check")
+		return nil
+	}
+
+	g, err := readSourceGrants(i.db)
+	if err != nil {
+		return err
+	}
+	if i.mysqlContext.ConnectionConfig.AdminUser != "" {
+		if g.all || g.selectPrivilege {
+			i.logger.Printf("mysql.inspector: AdminUser has SELECT privileges")
+			return nil
+		}
+		return fmt.Errorf("AdminUser %v has insufficient privileges for schema queries. Needed: SELECT on *.*",
+			i.mysqlContext.ConnectionConfig.AdminUser)
+	}
+	i.mysqlContext.HasSuperPrivilege = g.super
+	if err := g.validateReplication(i.logger); err != nil {
+		return fmt.Errorf("%v%v", err, i.auroraHint("privileges granted by roles are not seen. set SkipPrivilegeCheck"+
+			" if the user has them by a role"))
+	}
+	return nil
+}
+
 func (i *Inspector) validateGTIDMode() error {
 	query := `SELECT @@GTID_MODE`
 	var gtidMode string
@@ -250,7 +307,8 @@ func (i *Inspector) validateGTIDMode() error {
 		return err
 	}
 	if gtidMode != "ON" {
-		return fmt.Errorf("must have GTID enabled: %+v", gtidMode)
+		return fmt.Errorf("must have GTID enabled: %+v%v", gtidMode,
+			i.auroraHint("set gtid-mode and enforce_gtid_consistency to ON in the DB cluster parameter group"))
 	}
 	return nil
 }
@@ -263,7 +321,8 @@ func (i *Inspector) validateBinlogs() error {
 		return err
 	}
 	if !hasBinaryLogs {
-		return fmt.Errorf("%s:%d must have binary logs enabled", i.mysqlContext.ConnectionConfig.Host, i.mysqlContext.ConnectionConfig.Port)
+		return fmt.Errorf("%s:%d must have binary logs enabled%v", i.mysqlContext.ConnectionConfig.Host, i.mysqlContext.ConnectionConfig.Port,
+			i.auroraHint("set binlog_format to ROW in the DB cluster parameter group"))
 	}
 	if i.mysqlContext.RequiresBinlogFormatChange() {
 		return fmt.Errorf("You must be using ROW binlog format. I can switch it for you, provided --switch-to-rbr and that %s:%d doesn't have replicas", i.mysqlContext.ConnectionConfig.Host, i.mysqlContext.ConnectionConfig.Port)
@@ -279,6 +338,21 @@ func (i *Inspector) validateBinlogs() error {
 	return nil
 }
 
+// validateHeartbeatPeriod checks that the source sends the heartbeats on the idle binlog connection
+// before it times out writing to it.
+func (i *Inspector) validateHeartbeatPeriod() error {
+	query := `select @@global.net_write_timeout`
+	var netWriteTimeout int
+	if err := i.db.QueryRow(query).Scan(&netWriteTimeout); err != nil {
+		return err
+	}
+	if i.mysqlContext.HeartbeatPeriod >= netWriteTimeout {
+		return fmt.Errorf("bad job argument: HeartbeatPeriod=%v. should be less than net_write_timeout (%v) of the source",
+			i.mysqlContext.HeartbeatPeriod, netWriteTimeout)
+	}
+	return nil
+}
+
 // validateTable makes sure the table we need to operate on actually exists
 func (i *Inspector) validateTable(databaseName, tableName string) error {
 	query := fmt.Sprintf(`show table status from %s like '%s'`, umconf.EscapeName(databaseName), tableName)
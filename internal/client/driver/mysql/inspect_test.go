@@ -9,15 +9,16 @@ package mysql
 import (
 	"reflect"
 	"testing"
+
 	uconf "github.com/actiontech/dtle/internal/config"
 	umconf "github.com/actiontech/dtle/internal/config/mysql"
-	log "github.com/actiontech/dtle/internal/logger"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewInspector(t *testing.T) {
 	type args struct {
 		ctx    *uconf.MySQLDriverConfig
-		logger *log.Entry
+		logger *logrus.Entry
 	}
 	tests := []struct {
 		name string
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/actiontech/dtle/internal/models"
+)
+
+const lagCheckInterval = time.Second
+
+// replicationLag measures the lag of the applier from the binlog timestamps of the transactions,
+// like Seconds_Behind_Master: the age of the last transaction received by the applier,
+// or 0 if all received transactions have been committed.
+type replicationLag struct {
+	lock sync.Mutex
+	// received but not yet committed transactions
+	pending int
+	// binlog timestamp (unix seconds) of the last received transaction
+	lastTimestamp int64
+}
+
+func (l *replicationLag) received(timestamp int64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.pending++
+	if timestamp > l.lastTimestamp {
+		l.lastTimestamp = timestamp
+	}
+}
+
+func (l *replicationLag) committed(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.pending -= n
+	if l.pending < 0 {
+		l.pending = 0
+	}
+}
+
+// get returns the lag as of now.
+func (l *replicationLag) get(now time.Time) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// lastTimestamp is 0 if the extractor does not send it.
+	if l.pending == 0 || l.lastTimestamp == 0 {
+		return 0
+	}
+	lag := now.Sub(time.Unix(l.lastTimestamp, 0))
+	if lag < 0 {
+		// clock skew between the source and dtle
+		return 0
+	}
+	return lag
+}
+
+// lagBreaker trips if the lag stays above maxLag for window. A lag back under maxLag resets the window.
+type lagBreaker struct {
+	maxLag time.Duration
+	window time.Duration
+	// when the lag went above maxLag. zero if it is not.
+	exceededSince time.Time
+}
+
+// check returns an error if the breaker trips with lag as of now.
+func (b *lagBreaker) check(lag time.Duration, now time.Time) error {
+	if lag <= b.maxLag {
+		b.exceededSince = time.Time{}
+		return nil
+	}
+	if b.exceededSince.IsZero() {
+		b.exceededSince = now
+	}
+	if now.Sub(b.exceededSince) < b.window {
+		return nil
+	}
+	return fmt.Errorf("replication lag %v has exceeded MaxLagBeforeStop %v since %v",
+		lag, b.maxLag, b.exceededSince.Format(time.RFC3339))
+}
+
+// checkReplicationLag stops the job if the lag stays above MaxLagBeforeStop for MaxLagWindow.
+// The lag is measured from the binlog timestamps. See replicationLag.
+func (a *Applier) checkReplicationLag() {
+	breaker := &lagBreaker{
+		maxLag: time.Duration(a.mysqlContext.MaxLagBeforeStop) * time.Second,
+		window: time.Duration(a.mysqlContext.MaxLagWindow) * time.Second,
+	}
+	a.logger.Infof("mysql.applier: MaxLagBeforeStop: %v, MaxLagWindow: %v", breaker.maxLag, breaker.window)
+
+	ticker := time.NewTicker(lagCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.shutdownCh:
+			return
+		case now := <-ticker.C:
+			if err := breaker.check(a.replicationLag.get(now), now); err != nil {
+				a.logger.Errorf("mysql.applier: %v. stopping the job", err)
+				a.execCtx.EmitTyped(models.TaskLagExceeded, "%v", err)
+				a.onError(TaskStateDead, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplicationLag(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := &replicationLag{}
+	if lag := l.get(now); lag != 0 {
+		t.Errorf("lag = %v with no transaction, want 0", lag)
+	}
+
+	l.received(990)
+	l.received(995)
+	if lag := l.get(now); lag != 5*time.Second {
+		t.Errorf("lag = %v, want 5s", lag)
+	}
+	// a stuck transaction keeps increasing the lag
+	if lag := l.get(now.Add(10 * time.Second)); lag != 15*time.Second {
+		t.Errorf("lag = %v, want 15s", lag)
+	}
+	l.committed(1)
+	if lag := l.get(now); lag != 5*time.Second {
+		t.Errorf("lag = %v with a pending transaction, want 5s", lag)
+	}
+	// caught up. an idle source is not lagging.
+	l.committed(1)
+	if lag := l.get(now.Add(time.Hour)); lag != 0 {
+		t.Errorf("lag = %v after all committed, want 0", lag)
+	}
+
+	l.received(1100)
+	if lag := l.get(now); lag != 0 {
+		t.Errorf("lag = %v with the source clock ahead, want 0", lag)
+	}
+}
+
+func TestLagBreaker_check(t *testing.T) {
+	b := &lagBreaker{maxLag: 10 * time.Second, window: 30 * time.Second}
+	start := time.Unix(1000, 0)
+	tests := []struct {
+		elapsed time.Duration
+		lag     time.Duration
+		wantErr bool
+	}{
+		{0, 5 * time.Second, false},
+		{10 * time.Second, 20 * time.Second, false},
+		{30 * time.Second, 20 * time.Second, false},
+		// back under maxLag. the window restarts.
+		{35 * time.Second, 10 * time.Second, false},
+		{40 * time.Second, 20 * time.Second, false},
+		{69 * time.Second, 20 * time.Second, false},
+		{70 * time.Second, 20 * time.Second, true},
+	}
+	for _, tt := range tests {
+		err := b.check(tt.lag, start.Add(tt.elapsed))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("check(%v) at %v error = %v, wantErr %v", tt.lag, tt.elapsed, err, tt.wantErr)
+		}
+	}
+}
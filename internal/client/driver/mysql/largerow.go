@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// A statement larger than max_allowed_packet of the destination is refused. A row larger than LargeRowSize,
+// e.g. with a BLOB of many megabytes, is written with its large values emptied, and then each of the
+// values is appended in pieces of LargeRowSize by `update ... set c = concat(c, ?)` in the same transaction.
+// A single value is still limited by max_allowed_packet, beyond which concat() returns NULL.
+
+// largeRowOverhead is the room in max_allowed_packet for a statement besides a piece of LargeRowSize,
+// i.e. the query text and the primary key values.
+const largeRowOverhead = 16 * 1024
+
+// largeValue is a value of a large row, which is appended to its column after the row is written.
+type largeValue struct {
+	column *umconf.Column
+	value  interface{} // string or []byte
+}
+
+// execer is a *gosql.Tx or a *gosql.Conn
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (gosql.Result, error)
+}
+
+// validateLargeRowSize reads max_allowed_packet of the destination, which limits the size of a statement.
+// LargeRowSize defaults to half of it.
+func (a *Applier) validateLargeRowSize() error {
+	query := `select @@global.max_allowed_packet`
+	if err := a.db.QueryRow(query).Scan(&a.maxAllowedPacket); err != nil {
+		return err
+	}
+	if a.mysqlContext.LargeRowSize == 0 {
+		a.mysqlContext.LargeRowSize = a.maxAllowedPacket / 2
+	} else if a.mysqlContext.LargeRowSize < 0 ||
+		a.mysqlContext.LargeRowSize > a.maxAllowedPacket-largeRowOverhead {
+		return fmt.Errorf("bad job argument: LargeRowSize=%v. should be positive and at most max_allowed_packet of the destination (%v) - %v",
+			a.mysqlContext.LargeRowSize, a.maxAllowedPacket, largeRowOverhead)
+	}
+	a.logger.Infof("mysql.applier: max_allowed_packet: %v, LargeRowSize: %v", a.maxAllowedPacket, a.mysqlContext.LargeRowSize)
+	return nil
+}
+
+// isAppendableColumn tells whether a value of the column can be written empty and then appended with concat().
+func isAppendableColumn(column *umconf.Column) bool {
+	if strings.ToUpper(column.Key) == "PRI" {
+		return false
+	}
+	switch column.Type {
+	case umconf.BlobColumnType, umconf.TextColumnType, umconf.TinytextColumnType,
+		umconf.VarcharColumnType, umconf.VarbinaryColumnType:
+		return true
+	default:
+		return false
+	}
+}
+
+func valueSize(value interface{}) int {
+	switch v := value.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	default:
+		return 8
+	}
+}
+
+// splitLargeRow checks the size of the args of a statement writing a row, which begin with the values of the row
+// in the order of columns. If it is larger than limit, the largest values of the row are emptied in args
+// until the rest fits in limit, and are returned to be written by appendLargeValues.
+// A row of a table without a primary key is not split.
+func splitLargeRow(columns *umconf.ColumnList, args []interface{}, limit int) (values []*largeValue) {
+	size := 0
+	for _, arg := range args {
+		size += valueSize(arg)
+	}
+	if size <= limit || !sql.HasPrimaryKey(columns) {
+		return nil
+	}
+
+	var ordinals []int
+	for i := range columns.Columns {
+		if i >= len(args) || !isAppendableColumn(&columns.Columns[i]) {
+			continue
+		}
+		switch args[i].(type) {
+		case string, []byte:
+			if valueSize(args[i]) > 0 {
+				ordinals = append(ordinals, i)
+			}
+		}
+	}
+	sort.SliceStable(ordinals, func(i, j int) bool {
+		return valueSize(args[ordinals[i]]) > valueSize(args[ordinals[j]])
+	})
+	for _, i := range ordinals {
+		if size <= limit {
+			break
+		}
+		size -= valueSize(args[i])
+		values = append(values, &largeValue{column: &columns.Columns[i], value: args[i]})
+		if _, ok := args[i].(string); ok {
+			args[i] = ""
+		} else {
+			args[i] = []byte{}
+		}
+	}
+	return values
+}
+
+// splitValue splits a value into pieces of at most size bytes. A string is split between UTF-8 characters.
+func splitValue(value interface{}, size int) (pieces []interface{}) {
+	switch v := value.(type) {
+	case string:
+		for len(v) > size {
+			n := size
+			for n > 0 && !utf8.RuneStart(v[n]) {
+				n--
+			}
+			if n == 0 {
+				n = size
+			}
+			pieces = append(pieces, v[:n])
+			v = v[n:]
+		}
+		return append(pieces, v)
+	case []byte:
+		for len(v) > size {
+			pieces = append(pieces, v[:size])
+			v = v[size:]
+		}
+		return append(pieces, v)
+	default:
+		return []interface{}{value}
+	}
+}
+
+// appendLargeValues writes the values returned by splitLargeRow, after the row has been written with args.
+func (a *Applier) appendLargeValues(db execer, schema string, table string, columns *umconf.ColumnList,
+	args []interface{}, values []*largeValue) error {
+
+	for _, v := range values {
+		if a.maxAllowedPacket > 0 && valueSize(v.value) > a.maxAllowedPacket {
+			return fmt.Errorf("a value of %v bytes of %v.%v.%v is larger than max_allowed_packet of the destination (%v)",
+				valueSize(v.value), schema, table, v.column.RawName, a.maxAllowedPacket)
+		}
+		query, keyArgs, err := sql.BuildDMLAppendQuery(schema, table, columns, v.column, args)
+		if err != nil {
+			return err
+		}
+		pieces := splitValue(v.value, a.mysqlContext.LargeRowSize)
+		a.logger.Debugf("mysql.applier: appending %v bytes to %v.%v.%v in %v pieces",
+			valueSize(v.value), schema, table, v.column.RawName, len(pieces))
+		for _, piece := range pieces {
+			if _, err := db.ExecContext(context.Background(), query, append([]interface{}{piece}, keyArgs...)...); err != nil {
+				return fmt.Errorf("append to large column %v: %v", v.column.RawName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// dumpRowSize returns the size of the values of a row of the full copy.
+func dumpRowSize(row []*[]byte) (size int) {
+	for _, colData := range row {
+		if colData != nil {
+			size += len(*colData)
+		}
+	}
+	return size
+}
+
+// writeLargeDumpRow writes a large row of the full copy, with insertPrefix being `replace into ... values (`
+// (or `insert ignore`, when the rest of the values is not appended to a row kept on the destination).
+// written is false if the row can not be split, when it is written as others.
+func (a *Applier) writeLargeDumpRow(tx applyTx, schema string, table string, columns *umconf.ColumnList,
+	insertPrefix string, columnExprs []*sql.ColumnExpr, row []*[]byte) (written bool, err error) {
+
+	if len(row) != columns.Len() {
+		a.logger.Warnf("mysql.applier: %v.%v has %v columns but %v values in a row. not splitting the large row",
+			schema, table, columns.Len(), len(row))
+		return false, nil
+	}
+	args := make([]interface{}, len(row))
+	for j, colData := range row {
+		if colData == nil {
+			args[j] = nil
+		} else if column := &columns.Columns[j]; column.Type == umconf.TextColumnType ||
+			column.Type == umconf.TinytextColumnType || column.Type == umconf.VarcharColumnType {
+			// split between characters
+			args[j] = string(*colData)
+		} else {
+			args[j] = *colData
+		}
+	}
+	values := splitLargeRow(columns, args, a.mysqlContext.LargeRowSize)
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	placeholders := make([]string, len(args))
+	for j := range placeholders {
+		placeholders[j] = "?"
+	}
+	for _, expr := range columnExprs {
+		placeholders = append(placeholders, expr.Expr)
+	}
+	query := insertPrefix + strings.Join(placeholders, ",") + ")"
+	a.logger.Debugf("mysql.applier: writing a row of %v bytes of %v.%v in pieces", dumpRowSize(row), schema, table)
+	result, err := tx.ExecContext(context.Background(), query, args...)
+	if err != nil {
+		return false, err
+	}
+	if a.dumpInsertIgnore(schema, table) {
+		if inserted, err := result.RowsAffected(); err != nil {
+			return false, err
+		} else if inserted == 0 {
+			return true, nil
+		}
+	}
+	return true, a.appendLargeValues(tx, schema, table, columns, args, values)
+}
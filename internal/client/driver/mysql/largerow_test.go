@@ -0,0 +1,315 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func newLargeRowTestColumns() *umconf.ColumnList {
+	return &umconf.ColumnList{
+		Columns: []umconf.Column{
+			{RawName: "id", EscapedName: "`id`", Key: "PRI", Type: umconf.BigIntColumnType},
+			{RawName: "b", EscapedName: "`b`", Type: umconf.BlobColumnType},
+			{RawName: "c", EscapedName: "`c`", Type: umconf.TextColumnType},
+			{RawName: "j", EscapedName: "`j`", Type: umconf.JSONColumnType},
+		},
+		Ordinals: umconf.ColumnsMap{"id": 0, "b": 1, "c": 2, "j": 3},
+	}
+}
+
+func TestSplitLargeRow(t *testing.T) {
+	columns := newLargeRowTestColumns()
+	tests := []struct {
+		name       string
+		args       []interface{}
+		limit      int
+		wantValues []string // column names
+		wantArgs   []interface{}
+	}{
+		{"small", []interface{}{int64(1), []byte("bb"), "cc", "{}"}, 100, nil,
+			[]interface{}{int64(1), []byte("bb"), "cc", "{}"}},
+		{"largest first", []interface{}{int64(1), make([]byte, 60), strings.Repeat("c", 50), "{}"}, 100,
+			[]string{"b"}, []interface{}{int64(1), []byte{}, strings.Repeat("c", 50), "{}"}},
+		{"both", []interface{}{int64(1), make([]byte, 60), strings.Repeat("c", 50), "{}"}, 20,
+			[]string{"b", "c"}, []interface{}{int64(1), []byte{}, "", "{}"}},
+		{"json is not split", []interface{}{int64(1), nil, "", strings.Repeat("1", 200)}, 100,
+			nil, []interface{}{int64(1), nil, "", strings.Repeat("1", 200)}},
+		{"update key args", []interface{}{int64(1), make([]byte, 60), "", "{}", strings.Repeat("k", 50)}, 100,
+			[]string{"b"}, []interface{}{int64(1), []byte{}, "", "{}", strings.Repeat("k", 50)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := splitLargeRow(columns, tt.args, tt.limit)
+			var gotValues []string
+			for _, v := range values {
+				gotValues = append(gotValues, v.column.RawName)
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("splitLargeRow() values = %v, want %v", gotValues, tt.wantValues)
+			}
+			if !reflect.DeepEqual(tt.args, tt.wantArgs) {
+				t.Errorf("splitLargeRow() args = %v, want %v", tt.args, tt.wantArgs)
+			}
+		})
+	}
+
+	keyless := &umconf.ColumnList{Columns: columns.Columns[1:], Ordinals: umconf.ColumnsMap{"b": 0, "c": 1, "j": 2}}
+	if values := splitLargeRow(keyless, []interface{}{make([]byte, 200), "", "{}"}, 100); len(values) != 0 {
+		t.Errorf("splitLargeRow() on a keyless table = %v, want none", values)
+	}
+}
+
+func TestSplitValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		size  int
+		want  []interface{}
+	}{
+		{"bytes", []byte("abcdefg"), 3, []interface{}{[]byte("abc"), []byte("def"), []byte("g")}},
+		{"small", []byte("ab"), 3, []interface{}{[]byte("ab")}},
+		// 3 bytes each
+		{"utf8", "数据数据", 7, []interface{}{"数据", "数据"}},
+		{"ascii", "abcdef", 3, []interface{}{"abc", "def"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitValue(tt.value, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplier_largeRow(t *testing.T) {
+	skipWithoutMySQL(t)
+	newApplier := func(largeRowSize int) (*Applier, error) {
+		cfg := &config.MySQLDriverConfig{
+			ConnectionConfig: &umconf.ConnectionConfig{
+				Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+			LargeRowSize: largeRowSize,
+		}
+		a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+		if err != nil {
+			t.Fatalf("NewApplier() error = %v", err)
+		}
+		return a, a.initDBConnections()
+	}
+	a, err := newApplier(0)
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	// max_allowed_packet of a session is the global one when it connects.
+	setMaxAllowedPacket := func(size int) {
+		if _, err := a.db.Exec(fmt.Sprintf("set global max_allowed_packet = %v", size)); err != nil {
+			t.Skipf("set max_allowed_packet: %v", err)
+		}
+	}
+	defer setMaxAllowedPacket(a.maxAllowedPacket)
+
+	var gno int64
+	apply := func(a *Applier, event binlog.DataEvent) error {
+		gno++
+		event.DatabaseName = "dtle_test"
+		event.TableName = "t_large_row"
+		entry := &binlog.BinlogEntry{
+			Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: gno},
+			Events:      []binlog.DataEvent{event},
+		}
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			return err
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			return err
+		}
+		return a.commitApplyBatch(0, a.applyBatches[0])
+	}
+	row := func(id int64, b []byte, c string) *umconf.ColumnValues {
+		var v1, v2, v3 interface{} = id, b, c
+		return &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2, &v3}}
+	}
+	check := func(a *Applier, id int64, b []byte, c string) {
+		var gotB, gotC string
+		if err := a.db.QueryRow("select md5(b), md5(c) from dtle_test.t_large_row where id = ?", id).
+			Scan(&gotB, &gotC); err != nil {
+			t.Fatalf("select row %v error = %v", id, err)
+		}
+		if want := fmt.Sprintf("%x", md5.Sum(b)); gotB != want {
+			t.Errorf("row %v: md5(b) = %v, want %v", id, gotB, want)
+		}
+		if want := fmt.Sprintf("%x", md5.Sum([]byte(c))); gotC != want {
+			t.Errorf("row %v: md5(c) = %v, want %v", id, gotC, want)
+		}
+	}
+	newBlob := func(size int) []byte {
+		b := make([]byte, size)
+		rand.Read(b)
+		return b
+	}
+
+	// a destination with a small packet limit
+	setMaxAllowedPacket(4 << 20)
+	if _, err := newApplier(4 << 20); err == nil || !strings.Contains(err.Error(), "LargeRowSize") {
+		t.Errorf("initDBConnections() with LargeRowSize=max_allowed_packet, error = %v", err)
+	}
+	a4m, err := newApplier(0)
+	if err != nil {
+		t.Fatalf("initDBConnections() error = %v", err)
+	}
+	if a4m.mysqlContext.LargeRowSize != 2<<20 {
+		t.Errorf("LargeRowSize = %v, want half of max_allowed_packet", a4m.mysqlContext.LargeRowSize)
+	}
+	if _, err := a4m.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_large_row;" +
+		"create table dtle_test.t_large_row (id bigint primary key, b longblob, c longtext)"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+
+	// 6MB in a row of two 3MB values
+	b3m := newBlob(3 << 20)
+	c3m := strings.Repeat("数据", 1<<19)
+	if err := apply(a4m, binlog.DataEvent{DML: binlog.InsertDML, NewColumnValues: row(1, b3m, c3m)}); err != nil {
+		t.Fatalf("insert error = %v", err)
+	}
+	check(a4m, 1, b3m, c3m)
+	b3m2 := newBlob(3 << 20)
+	if err := apply(a4m, binlog.DataEvent{DML: binlog.UpdateDML,
+		WhereColumnValues: row(1, b3m, c3m), NewColumnValues: row(2, b3m2, c3m)}); err != nil {
+		t.Fatalf("update error = %v", err)
+	}
+	check(a4m, 2, b3m2, c3m)
+
+	// A value larger than max_allowed_packet can not be stored.
+	b32m := newBlob(32 << 20)
+	err = apply(a4m, binlog.DataEvent{DML: binlog.InsertDML, NewColumnValues: row(3, b32m, "")})
+	if err == nil || !strings.Contains(err.Error(), "max_allowed_packet") {
+		t.Errorf("insert a 32MB value, error = %v, want max_allowed_packet", err)
+	}
+	var n int
+	if err := a4m.db.QueryRow("select count(*) from dtle_test.t_large_row where id = 3").Scan(&n); err != nil || n != 0 {
+		t.Errorf("rows of the failed insert = %v, %v, want none", n, err)
+	}
+
+	// The 32MB value is written in pieces, each smaller than the statements of the full copy.
+	setMaxAllowedPacket(64 << 20)
+	a64m, err := newApplier(1 << 20)
+	if err != nil {
+		t.Fatalf("initDBConnections() error = %v", err)
+	}
+	if err := apply(a64m, binlog.DataEvent{DML: binlog.InsertDML, NewColumnValues: row(3, b32m, c3m)}); err != nil {
+		t.Fatalf("insert a 32MB value error = %v", err)
+	}
+	check(a64m, 3, b32m, c3m)
+
+	// the full copy
+	id4, id5, small, c3mBytes := []byte("4"), []byte("5"), []byte("small"), []byte(c3m)
+	entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_large_row", ValuesX: [][]*[]byte{
+		{&id4, &b32m, &c3mBytes},
+		{&id5, &small, nil},
+	}}
+	if err := a64m.ApplyEventQueries(a64m.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+	check(a64m, 4, b32m, c3m)
+	var got []byte
+	if err := a64m.db.QueryRow("select b from dtle_test.t_large_row where id = 5").Scan(&got); err != nil ||
+		!bytes.Equal(got, small) {
+		t.Errorf("row 5: b = %v, %v, want %v", got, err, small)
+	}
+}
+
+// A column with ON UPDATE CURRENT_TIMESTAMP keeps the value of the source when a large row is appended to.
+func TestApplier_largeRowOnUpdate(t *testing.T) {
+	skipWithoutMySQL(t)
+	a := newLoadDataTestApplier(t, false)
+	a.mysqlContext.LargeRowSize = 1024
+	if _, err := a.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_on_update;" +
+		"create table dtle_test.t_on_update (id bigint primary key, b longblob, c varchar(32)," +
+		" ts timestamp(6) not null default current_timestamp(6) on update current_timestamp(6))"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+
+	var gno int64
+	apply := func(event binlog.DataEvent) {
+		gno++
+		event.DatabaseName = "dtle_test"
+		event.TableName = "t_on_update"
+		entry := &binlog.BinlogEntry{
+			Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: gno},
+			Events:      []binlog.DataEvent{event},
+		}
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			t.Fatalf("ApplyBinlogEvent() error = %v", err)
+		}
+		if err := a.commitApplyBatch(0, a.applyBatches[0]); err != nil {
+			t.Fatalf("commitApplyBatch() error = %v", err)
+		}
+	}
+	row := func(id int64, b []byte, c string, ts string) *umconf.ColumnValues {
+		var v1, v2, v3, v4 interface{} = id, b, c, ts
+		return &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2, &v3, &v4}}
+	}
+	check := func(name string, id int64, b []byte, ts string) {
+		var gotB, gotTs string
+		if err := a.db.QueryRow("select md5(b), date_format(ts, '%Y-%m-%d %H:%i:%s.%f') from dtle_test.t_on_update"+
+			" where id = ?", id).Scan(&gotB, &gotTs); err != nil {
+			t.Fatalf("%v: select row %v error = %v", name, id, err)
+		}
+		if want := fmt.Sprintf("%x", md5.Sum(b)); gotB != want {
+			t.Errorf("%v: md5(b) = %v, want %v", name, gotB, want)
+		}
+		if gotTs != ts {
+			t.Errorf("%v: ts = %v, want %v of the source", name, gotTs, ts)
+		}
+	}
+
+	large := bytes.Repeat([]byte("b"), 4096)
+	ts1, ts2 := "2001-02-03 04:05:06.123456", "2002-03-04 05:06:07.000001"
+	apply(binlog.DataEvent{DML: binlog.InsertDML, NewColumnValues: row(1, large, "a", ts1)})
+	check("large insert", 1, large, ts1)
+	// ts is not changed on the source, e.g. assigned itself
+	large2 := bytes.Repeat([]byte("c"), 4096)
+	apply(binlog.DataEvent{DML: binlog.UpdateDML,
+		WhereColumnValues: row(1, large, "a", ts1), NewColumnValues: row(1, large2, "b", ts1)})
+	check("large update", 1, large2, ts1)
+	apply(binlog.DataEvent{DML: binlog.UpdateDML,
+		WhereColumnValues: row(1, large2, "b", ts1), NewColumnValues: row(1, []byte("small"), "c", ts2)})
+	check("small update", 1, []byte("small"), ts2)
+	apply(binlog.DataEvent{DML: binlog.UpdateDML,
+		WhereColumnValues: row(1, []byte("small"), "c", ts2), NewColumnValues: row(1, []byte("small"), "d", ts2)})
+	check("update of another column", 1, []byte("small"), ts2)
+
+	// the full copy
+	id2, c, ts := []byte("2"), []byte("a"), []byte(ts1)
+	entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_on_update", ValuesX: [][]*[]byte{
+		{&id2, &large, &c, &ts},
+	}}
+	if err := a.ApplyEventQueries(a.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+	check("full copy", 2, large, ts1)
+}
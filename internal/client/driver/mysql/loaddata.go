@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// With LoadDataInfile, a chunk of the full copy is written as CSV in memory and sent with
+// `load data local infile 'Reader::...' replace into table ...` (or `ignore` with DumpInsertIgnore). Each row is a line, of which a field is
+//   \N       NULL
+//   hex      a value of a binary column, loaded with unhex()
+//   "..."    other values, with backslash, double quote, NUL, CR and LF escaped by a backslash
+// Unlike INSERT, LOAD DATA LOCAL turns data errors into warnings.
+
+// loadDataSeq makes the names of the readers unique.
+var loadDataSeq uint64
+
+// validateLoadDataInfile checks whether the destination allows LOAD DATA LOCAL INFILE if LoadDataInfile is set.
+// If it does not, the full copy is loaded with INSERT.
+func (a *Applier) validateLoadDataInfile() error {
+	if !a.mysqlContext.LoadDataInfile {
+		return nil
+	}
+	query := `select @@global.local_infile`
+	if err := a.db.QueryRow(query).Scan(&a.loadDataInfile); err != nil {
+		return err
+	}
+	if a.loadDataInfile {
+		a.logger.Infof("mysql.applier: LoadDataInfile: loading the full copy with LOAD DATA LOCAL INFILE")
+	} else {
+		a.logger.Warnf("mysql.applier: LoadDataInfile: local_infile is OFF on the destination. loading the full copy with INSERT")
+	}
+	return nil
+}
+
+// isHexColumn tells whether a value of the column is written in hex, to be loaded as binary.
+func isHexColumn(column *umconf.Column) bool {
+	switch column.Type {
+	case umconf.BinaryColumnType, umconf.VarbinaryColumnType, umconf.BlobColumnType, umconf.BitColumnType:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeLoadDataRow writes a row of the full copy to buf as a line of CSV. hexColumns tells the binary columns.
+func writeLoadDataRow(buf *bytes.Buffer, row []*[]byte, hexColumns []bool) {
+	for j, colData := range row {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+		if colData == nil {
+			buf.WriteString(`\N`)
+			continue
+		}
+		if hexColumns[j] {
+			dst := make([]byte, hex.EncodedLen(len(*colData)))
+			hex.Encode(dst, *colData)
+			buf.Write(dst)
+			continue
+		}
+		buf.WriteByte('"')
+		for _, c := range *colData {
+			switch c {
+			case '\\', '"':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case 0:
+				buf.WriteString(`\0`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\n':
+				buf.WriteString(`\n`)
+			default:
+				buf.WriteByte(c)
+			}
+		}
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('\n')
+}
+
+// buildLoadDataQuery builds the statement loading the reader of readerName into the table.
+// A binary column is loaded from a user variable with unhex(). exprs are the values of the columns not in the rows.
+// The rows already in the table are replaced, or kept if ignore is set.
+func buildLoadDataQuery(readerName string, schema string, table string, charset string, columns *umconf.ColumnList,
+	hexColumns []bool, exprs []*sql.ColumnExpr, ignore bool) string {
+
+	var fields, sets []string
+	for j, column := range columns.ColumnList() {
+		if hexColumns[j] {
+			variable := fmt.Sprintf("@dtle_%d", j)
+			fields = append(fields, variable)
+			sets = append(sets, fmt.Sprintf("%s = unhex(%s)", column.EscapedName, variable))
+		} else {
+			fields = append(fields, column.EscapedName)
+		}
+	}
+	for _, expr := range exprs {
+		sets = append(sets, fmt.Sprintf("%s = %s", expr.EscapedName, expr.Expr))
+	}
+	duplicates := "replace"
+	if ignore {
+		duplicates = "ignore"
+	}
+	query := fmt.Sprintf(`load data local infile 'Reader::%s' %s into table %s.%s character set %s `+
+		`fields terminated by ',' optionally enclosed by '"' escaped by '\\' lines terminated by '\n' (%s)`,
+		readerName, duplicates, columns.QuoteName(schema), columns.QuoteName(table), charset, strings.Join(fields, ","))
+	if len(sets) > 0 {
+		query += " set " + strings.Join(sets, ",")
+	}
+	return query
+}
+
+// loadDumpRows loads the rows of a chunk of the full copy into tableName with LOAD DATA LOCAL INFILE.
+// loaded is false if the rows do not match the columns of the destination table, when they are to be inserted.
+func (a *Applier) loadDumpRows(tx applyTx, entry *DumpEntry, tableName string) (loaded bool, err error) {
+	columns, exprs, err := a.getTableColumns(entry.TableSchema, entry.TableName)
+	if err != nil {
+		return false, err
+	}
+	hexColumns := make([]bool, columns.Len())
+	for j := range columns.Columns {
+		hexColumns[j] = isHexColumn(&columns.Columns[j])
+	}
+
+	var buf bytes.Buffer
+	for _, row := range entry.ValuesX {
+		if len(row) != columns.Len() {
+			a.logger.Warnf("mysql.applier: %v.%v has %v columns but %v values in a row. loading with INSERT",
+				entry.TableSchema, entry.TableName, columns.Len(), len(row))
+			return false, nil
+		}
+		writeLoadDataRow(&buf, row, hexColumns)
+	}
+
+	readerName := fmt.Sprintf("dtle_%v_%v", a.subject, atomic.AddUint64(&loadDataSeq, 1))
+	mysqldriver.RegisterReaderHandler(readerName, func() io.Reader {
+		return bytes.NewReader(buf.Bytes())
+	})
+	defer mysqldriver.DeregisterReaderHandler(readerName)
+
+	query := buildLoadDataQuery(readerName, entry.TableSchema, tableName,
+		a.mysqlContext.ConnectionConfig.Charset, columns, hexColumns, exprs,
+		a.dumpInsertIgnore(entry.TableSchema, entry.TableName))
+	a.logger.Debugf("mysql.applier: loading %v rows of %v bytes. query: %v", len(entry.ValuesX), buf.Len(), query)
+	if _, err := tx.Exec(query); err != nil {
+		a.logger.Errorf("mysql.applier: load data into %v.%v error: %v", entry.TableSchema, tableName, err)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func TestWriteLoadDataRow(t *testing.T) {
+	bs := func(s string) *[]byte {
+		b := []byte(s)
+		return &b
+	}
+	tests := []struct {
+		name       string
+		row        []*[]byte
+		hexColumns []bool
+		want       string
+	}{
+		{"plain", []*[]byte{bs("1"), bs("abc")}, []bool{false, false}, "\"1\",\"abc\"\n"},
+		{"null and empty", []*[]byte{nil, bs("")}, []bool{false, false}, "\\N,\"\"\n"},
+		{"quoting", []*[]byte{bs("a,\"b\"\\c"), bs("\\N")}, []bool{false, false}, "\"a,\\\"b\\\"\\\\c\",\"\\\\N\"\n"},
+		{"control", []*[]byte{bs("a\nb\r\x00")}, []bool{false}, "\"a\\nb\\r\\0\"\n"},
+		{"hex", []*[]byte{bs("\x00\xff\n"), nil, bs("")}, []bool{true, true, true}, "00ff0a,\\N,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeLoadDataRow(&buf, tt.row, tt.hexColumns)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeLoadDataRow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newLoadDataTestApplier returns an applier of the test destination, on which local_infile is ON.
+func newLoadDataTestApplier(tb testing.TB, loadDataInfile bool) *Applier {
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		LoadDataInfile: loadDataInfile,
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		tb.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		tb.Skipf("no mysql available: %v", err)
+	}
+	if loadDataInfile && !a.loadDataInfile {
+		if _, err := a.db.Exec("set global local_infile = 1"); err != nil {
+			tb.Skipf("set local_infile: %v", err)
+		}
+		if err := a.validateLoadDataInfile(); err != nil || !a.loadDataInfile {
+			tb.Fatalf("validateLoadDataInfile() = %v, %v, want local_infile ON", a.loadDataInfile, err)
+		}
+	}
+	return a
+}
+
+func TestApplier_loadData(t *testing.T) {
+	skipWithoutMySQL(t)
+	a := newLoadDataTestApplier(t, true)
+	if _, err := a.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_load_data;" +
+		"create table dtle_test.t_load_data (id int primary key, c varchar(32), b varbinary(32), d datetime)"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+
+	bs := func(s string) *[]byte {
+		b := []byte(s)
+		return &b
+	}
+	rows := [][]*[]byte{
+		{bs("1"), bs("数据,\"q\"\\\n"), bs("\x00\xff\"\n"), bs("2020-01-02 03:04:05")},
+		{bs("2"), nil, nil, nil},
+		{bs("3"), bs(""), bs(""), bs("2020-01-02 03:04:05")},
+		{bs("4"), bs("\\N"), bs("\\N"), nil},
+	}
+	entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_load_data", ValuesX: rows}
+	if err := a.ApplyEventQueries(a.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+	// loaded again, replacing the rows
+	if err := a.ApplyEventQueries(a.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() again error = %v", err)
+	}
+
+	for _, row := range rows {
+		var c, b, d []byte
+		if err := a.db.QueryRow("select c, b, d from dtle_test.t_load_data where id = ?", string(*row[0])).
+			Scan(&c, &b, &d); err != nil {
+			t.Fatalf("select row %s error = %v", *row[0], err)
+		}
+		for j, got := range [][]byte{c, b, d} {
+			want := row[j+1]
+			if (want == nil) != (got == nil) || want != nil && !bytes.Equal(got, *want) {
+				t.Errorf("row %s column %v = %q, want %q", *row[0], j+1, got, want)
+			}
+		}
+	}
+	var n int
+	if err := a.db.QueryRow("select count(*) from dtle_test.t_load_data").Scan(&n); err != nil || n != len(rows) {
+		t.Errorf("count = %v, %v, want %v", n, err, len(rows))
+	}
+}
+
+// BenchmarkApplier_loadDump compares loading a chunk of the full copy with INSERT and with LOAD DATA LOCAL INFILE.
+func BenchmarkApplier_loadDump(b *testing.B) {
+	skipWithoutMySQL(b)
+	const nRows = 2000
+	rows := make([][]*[]byte, nRows)
+	for i := range rows {
+		id, c, blob := []byte(fmt.Sprint(i)), []byte(fmt.Sprintf("row %v, \"quoted\"", i)), bytes.Repeat([]byte{0, 1, 2}, 100)
+		rows[i] = []*[]byte{&id, &c, &blob}
+	}
+
+	for _, bm := range []struct {
+		name           string
+		loadDataInfile bool
+	}{{"insert", false}, {"loaddata", true}} {
+		b.Run(bm.name, func(b *testing.B) {
+			a := newLoadDataTestApplier(b, bm.loadDataInfile)
+			if _, err := a.db.Exec("create database if not exists dtle_test;" +
+				"drop table if exists dtle_test.t_load_bench;" +
+				"create table dtle_test.t_load_bench (id int primary key, c varchar(64), b blob)"); err != nil {
+				b.Fatalf("create table error = %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_load_bench", ValuesX: rows}
+				if err := a.ApplyEventQueries(a.db, entry); err != nil {
+					b.Fatalf("ApplyEventQueries() error = %v", err)
+				}
+			}
+		})
+	}
+}
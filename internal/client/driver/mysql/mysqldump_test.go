@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// The incremental copy of a job seeded by a dump starts right after the GTID set recorded in the dump.
+func TestExtractor_useMysqldumpCoordinate(t *testing.T) {
+	e := &Extractor{
+		logger: logrus.NewEntry(logrus.New()),
+		mysqlContext: &config.MySQLDriverConfig{MysqldumpCoordinate: `
+-- CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000003', MASTER_LOG_POS=154;
+SET @@GLOBAL.GTID_PURGED=/*!80000 '+'*/ '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,
+4e11fa47-71ca-11e1-9e33-c80aa9429562:1-100';
+`},
+	}
+	if err := e.useMysqldumpCoordinate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.setInitialBinlogCoordinates(); err != nil {
+		t.Fatal(err)
+	}
+	got := *e.initialBinlogCoordinates
+	if got.LogFile != "mysql-bin.000003" || got.LogPos != 154 {
+		t.Errorf("initialBinlogCoordinates = %+v, want mysql-bin.000003:154", got)
+	}
+	gtidSet, err := base.ParseGtidSet(got.GtidSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGtidSet, err := base.ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,4e11fa47-71ca-11e1-9e33-c80aa9429562:1-100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gtidSet.Equal(wantGtidSet) {
+		t.Errorf("initialBinlogCoordinates.GtidSet = %v, want %v", got.GtidSet, wantGtidSet)
+	}
+	// the transactions of the dump are not extracted, and the next one is
+	for gtid, done := range map[string]bool{
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:5":   true,
+		"4e11fa47-71ca-11e1-9e33-c80aa9429562:100": true,
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:6":   false,
+		"4e11fa47-71ca-11e1-9e33-c80aa9429562:101": false,
+	} {
+		set, err := base.ParseGtidSet(gtid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gtidSet.Contain(set) != done {
+			t.Errorf("%v in the coordinate: %v, want %v", gtid, !done, done)
+		}
+	}
+
+	e.mysqlContext = &config.MySQLDriverConfig{MysqldumpCoordinate: "SET @@SESSION.SQL_LOG_BIN= 0;"}
+	if err := e.useMysqldumpCoordinate(); err == nil {
+		t.Errorf("useMysqldumpCoordinate() of a dump without a coordinate: no error")
+	}
+}
@@ -0,0 +1,20 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"os"
+	"testing"
+)
+
+// skipWithoutMySQL skips a test which needs the MySQL server at 192.168.99.100:13307 (root/rootroot),
+// unless DTLE_TEST_MYSQL is set.
+func skipWithoutMySQL(t testing.TB) {
+	if os.Getenv("DTLE_TEST_MYSQL") == "" {
+		t.Skip("DTLE_TEST_MYSQL is not set")
+	}
+}
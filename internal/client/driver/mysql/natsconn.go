@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+)
+
+// the wait between the attempts to reconnect to the nats servers. With the default max attempts of the
+// client, a task gives up after about two minutes without a nats server.
+const natsReconnectWait = 2 * time.Second
+
+// NatsURL returns the url of NatsAddr, which is a host:port or a comma-separated list of them, e.g. the
+// servers of a nats cluster.
+func NatsURL(natsAddr string) string {
+	var urls []string
+	for _, addr := range strings.Split(natsAddr, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if !strings.Contains(addr, "://") {
+			addr = "nats://" + addr
+		}
+		urls = append(urls, addr)
+	}
+	return strings.Join(urls, ",")
+}
+
+// ConnectNats connects to one of the nats servers of NatsAddr. On a failure of the server, the connection is
+// reconnected to any of them, and the subscriptions are restored on it. The messages sent meanwhile may be lost,
+// and are resent by the extractor after a timeout (see Extractor.publish). The disconnections and reconnections
+// are counted in the metrics of the task, and reconnections are reported as task events. execCtx might be nil.
+func ConnectNats(natsAddr string, execCtx *common.ExecContext, logger *logrus.Entry,
+	options ...gonats.Option) (*gonats.Conn, error) {
+
+	options = append(options,
+		gonats.ReconnectWait(natsReconnectWait),
+		gonats.DisconnectHandler(func(nc *gonats.Conn) {
+			logger.Warnf("nats: disconnected from %v. reconnecting", nc.ConnectedUrl())
+			if execCtx != nil {
+				execCtx.Incr([]string{"nats", "disconnects"}, 1)
+			}
+		}),
+		gonats.ReconnectHandler(func(nc *gonats.Conn) {
+			logger.Warnf("nats: reconnected to %v. reconnects: %v", nc.ConnectedUrl(), nc.Stats().Reconnects)
+			if execCtx != nil {
+				execCtx.Incr([]string{"nats", "reconnects"}, 1)
+				execCtx.Emit("reconnected to the nats server %v", nc.ConnectedUrl())
+			}
+		}),
+		gonats.ClosedHandler(func(nc *gonats.Conn) {
+			logger.Debugf("nats: connection closed")
+		}),
+	)
+	return gonats.Connect(NatsURL(natsAddr), options...)
+}
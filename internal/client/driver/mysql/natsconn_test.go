@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gnatsd "github.com/nats-io/gnatsd/server"
+	gonats "github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+)
+
+func TestNatsURL(t *testing.T) {
+	tests := []struct {
+		natsAddr string
+		want     string
+	}{
+		{"127.0.0.1:8193", "nats://127.0.0.1:8193"},
+		{"10.0.0.1:4222,10.0.0.2:4222", "nats://10.0.0.1:4222,nats://10.0.0.2:4222"},
+		{" 10.0.0.1:4222, nats://10.0.0.2:4222,", "nats://10.0.0.1:4222,nats://10.0.0.2:4222"},
+	}
+	for _, tt := range tests {
+		if got := NatsURL(tt.natsAddr); got != tt.want {
+			t.Errorf("NatsURL(%q) = %v, want %v", tt.natsAddr, got, tt.want)
+		}
+	}
+}
+
+// runTestNatsCluster runs n nats servers routed to each other.
+func runTestNatsCluster(t *testing.T, n int) (servers []*gnatsd.Server) {
+	var routes []*url.URL
+	for i := 0; i < n; i++ {
+		s := gnatsd.New(&gnatsd.Options{Host: "127.0.0.1", Port: gnatsd.RANDOM_PORT, NoLog: true, NoSigs: true,
+			Cluster: gnatsd.ClusterOpts{Host: "127.0.0.1", Port: gnatsd.RANDOM_PORT}, Routes: routes})
+		go s.Start()
+		if !s.ReadyForConnections(10 * time.Second) {
+			t.Fatal("nats server is not ready")
+		}
+		routes = append(routes, &url.URL{Scheme: "nats-route", Host: s.ClusterAddr().String()})
+		servers = append(servers, s)
+	}
+	for i := 0; i < 100; i++ {
+		routed := true
+		for _, s := range servers {
+			routed = routed && s.NumRoutes() == n-1
+		}
+		if routed {
+			return servers
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("nats servers are not routed")
+	return nil
+}
+
+// The replication goes on when the nats server of the destination fails, on another server of the cluster.
+func TestConnectNats_failover(t *testing.T) {
+	servers := runTestNatsCluster(t, 2)
+	var addrs []string
+	for _, s := range servers {
+		defer s.Shutdown()
+		addrs = append(addrs, s.Addr().String())
+	}
+	natsAddr := strings.Join(addrs, ",")
+
+	var lock sync.Mutex
+	counters := make(map[string]float32)
+	var events []string
+	execCtx := &common.ExecContext{
+		IncrCounter: func(key []string, val float32) {
+			lock.Lock()
+			counters[strings.Join(key, ".")] += val
+			lock.Unlock()
+		},
+		EmitEvent: func(m string, args ...interface{}) {
+			lock.Lock()
+			events = append(events, fmt.Sprintf(m, args...))
+			lock.Unlock()
+		},
+	}
+	a := newTestNatsApplier(t, natsAddr, execCtx)
+	defer a.Shutdown()
+	if err := a.subscribeData("job_incr_hete", func(m *gonats.Msg) {
+		a.natsConn.Publish(m.Reply, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nc, err := ConnectNats(natsAddr, nil, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	// the extractor resends a message until it is acknowledged
+	send := func() error {
+		var err error
+		for i := 0; i < 20; i++ {
+			if _, err = nc.Request("job_incr_hete", []byte("tx"), 500*time.Millisecond); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+	if err := send(); err != nil {
+		t.Fatal(err)
+	}
+
+	connected := a.natsConn.ConnectedUrl()
+	for _, s := range servers {
+		if "nats://"+s.Addr().String() == connected {
+			s.Shutdown()
+		}
+	}
+	if err := send(); err != nil {
+		t.Fatalf("the message is not acknowledged after the failure of %v: %v", connected, err)
+	}
+	if url := a.natsConn.ConnectedUrl(); url == connected || url == "" {
+		t.Errorf("ConnectedUrl() = %v, want another server than %v", url, connected)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if counters["nats.disconnects"] != 1 || counters["nats.reconnects"] != 1 {
+		t.Errorf("metrics = %v, want a disconnect and a reconnect", counters)
+	}
+	if len(events) != 1 || !strings.Contains(events[0], "reconnected to the nats server") {
+		t.Errorf("events = %v", events)
+	}
+}
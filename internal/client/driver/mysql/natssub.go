@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+
+	gonats "github.com/nats-io/go-nats"
+
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// subscribeData subscribes to the full or incremental data from the extractor. If the handler cannot keep up,
+// pending messages beyond the limits are dropped by nats and natsErrorHandler is called.
+func (a *Applier) subscribeData(subject string, cb gonats.MsgHandler) error {
+	sub, err := a.natsConn.Subscribe(subject, cb)
+	if err != nil {
+		return err
+	}
+	if err := sub.SetPendingLimits(a.subPendingMsgsLimit, a.subPendingBytesLimit); err != nil {
+		return err
+	}
+	a.natsDataSubsLock.Lock()
+	a.natsDataSubs = append(a.natsDataSubs, sub)
+	a.natsDataSubsLock.Unlock()
+	return nil
+}
+
+// natsErrorHandler handles the asynchronous errors of the nats connection. A slow consumer has dropped messages,
+// which are not to be skipped silently: the task is restarted and resumes from the last checkpoint.
+func (a *Applier) natsErrorHandler(nc *gonats.Conn, sub *gonats.Subscription, err error) {
+	if err != gonats.ErrSlowConsumer || sub == nil {
+		a.logger.Errorf("mysql.applier: nats error: %v", err)
+		a.recordError(models.ErrorTypeNats, "", err)
+		return
+	}
+	dropped, _ := sub.Dropped()
+	pendingMsgs, pendingBytes, _ := sub.Pending()
+	a.logger.Errorf("mysql.applier: nats slow consumer on %v. dropped: %v, pending: %v msgs, %v bytes. restarting",
+		sub.Subject, dropped, pendingMsgs, pendingBytes)
+	a.recordError(models.ErrorTypeNats, "", fmt.Errorf("slow consumer on %v. dropped: %v, pending: %v msgs, %v bytes",
+		sub.Subject, dropped, pendingMsgs, pendingBytes))
+	a.execCtx.Incr([]string{"nats", "dropped_msgs"}, float32(dropped))
+	a.execCtx.Emit("nats slow consumer on %v: %v messages dropped. restarting", sub.Subject, dropped)
+	// Not in the goroutine of nats callbacks, as the connection is closed on shutdown.
+	go a.onError(TaskStateRestart, fmt.Errorf("nats slow consumer on %v: %v messages dropped", sub.Subject, dropped))
+}
+
+func (a *Applier) natsSubStats() *models.NatsSubStats {
+	stats := &models.NatsSubStats{}
+	a.natsDataSubsLock.Lock()
+	defer a.natsDataSubsLock.Unlock()
+	for _, sub := range a.natsDataSubs {
+		// Errors are returned only if the subscription is closed.
+		if pendingMsgs, pendingBytes, err := sub.Pending(); err == nil {
+			stats.PendingMsgs += pendingMsgs
+			stats.PendingBytes += pendingBytes
+		}
+		if dropped, err := sub.Dropped(); err == nil {
+			stats.DroppedMsgs += dropped
+		}
+	}
+	return stats
+}
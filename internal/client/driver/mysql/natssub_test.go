@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	gnatsd "github.com/nats-io/gnatsd/server"
+	gonats "github.com/nats-io/go-nats"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func runTestNatsServer(t *testing.T) *gnatsd.Server {
+	s := gnatsd.New(&gnatsd.Options{Host: "127.0.0.1", Port: gnatsd.RANDOM_PORT, NoLog: true, NoSigs: true})
+	go s.Start()
+	if !s.ReadyForConnections(10 * time.Second) {
+		t.Fatal("nats server is not ready")
+	}
+	return s
+}
+
+func newTestNatsApplier(t *testing.T, natsAddr string, execCtx *common.ExecContext) *Applier {
+	a := &Applier{
+		logger:               logrus.NewEntry(logrus.New()),
+		execCtx:              execCtx,
+		subject:              "job",
+		mysqlContext:         &config.MySQLDriverConfig{NatsAddr: natsAddr},
+		waitCh:               make(chan *models.WaitResult, 1),
+		shutdownCh:           make(chan struct{}),
+		subPendingMsgsLimit:  gonats.DefaultSubPendingMsgsLimit,
+		subPendingBytesLimit: gonats.DefaultSubPendingBytesLimit,
+	}
+	if err := a.initNatSubClient(); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestApplier_natsSubStats(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+	a := newTestNatsApplier(t, s.Addr().String(), &common.ExecContext{})
+	defer a.Shutdown()
+
+	// The first message blocks the handler.
+	releaseCh := make(chan struct{})
+	if err := a.subscribeData("job_incr_hete", func(m *gonats.Msg) {
+		<-releaseCh
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	for i := 0; i < 3; i++ {
+		nc.Publish("job_incr_hete", make([]byte, 100))
+	}
+	nc.Flush()
+
+	var stats *models.NatsSubStats
+	for i := 0; i < 100; i++ {
+		if stats = a.natsSubStats(); stats.PendingMsgs == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(releaseCh)
+	if *stats != (models.NatsSubStats{PendingMsgs: 2, PendingBytes: 200}) {
+		t.Errorf("natsSubStats() = %+v, want 2 msgs and 200 bytes pending", *stats)
+	}
+}
+
+func TestApplier_natsErrorHandler_slowConsumer(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+
+	var droppedLock sync.Mutex
+	var dropped float32
+	execCtx := &common.ExecContext{
+		IncrCounter: func(key []string, val float32) {
+			if key[0] == "nats" && key[1] == "dropped_msgs" {
+				droppedLock.Lock()
+				dropped += val
+				droppedLock.Unlock()
+			}
+		},
+	}
+	a := newTestNatsApplier(t, s.Addr().String(), execCtx)
+	a.subPendingMsgsLimit = 2
+
+	// A throttled subscriber, which takes 100ms for a message.
+	if err := a.subscribeData("job_incr_hete", func(m *gonats.Msg) {
+		time.Sleep(100 * time.Millisecond)
+		a.natsConn.Publish(m.Reply, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	restartCh := make(chan struct{}, 1)
+	if _, err := nc.Subscribe("job_restart", func(m *gonats.Msg) {
+		restartCh <- struct{}{}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		nc.Publish("job_incr_hete", []byte("tx"))
+	}
+	nc.Flush()
+
+	select {
+	case result := <-a.waitCh:
+		if result.ExitCode != TaskStateRestart || result.Err == nil {
+			t.Errorf("wait result = %+v, want a restart", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the applier is not restarted after dropping messages")
+	}
+	select {
+	case <-restartCh:
+	case <-time.After(5 * time.Second):
+		t.Error("the extractor is not requested to restart")
+	}
+	droppedLock.Lock()
+	defer droppedLock.Unlock()
+	if dropped < 1 {
+		t.Errorf("dropped_msgs = %v, want at least 1", dropped)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+)
+
+// applyTx is what the changes are applied in on the destination: a *gosql.Tx, or an autocommitTx with
+// NonTransactionalApply.
+type applyTx interface {
+	Exec(query string, args ...interface{}) (gosql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (gosql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *gosql.Row
+	Commit() error
+	Rollback() error
+}
+
+// autocommitTx applies the statements on a connection in autocommit mode, each committed on its own.
+// Commit and Rollback only release the connection if it is taken from a pool: a statement applied is
+// never rolled back.
+type autocommitTx struct {
+	conn *gosql.Conn
+	// the conn is taken from a pool, and closed on the end
+	pooled bool
+}
+
+func (t *autocommitTx) Exec(query string, args ...interface{}) (gosql.Result, error) {
+	return t.conn.ExecContext(context.Background(), query, args...)
+}
+
+func (t *autocommitTx) ExecContext(ctx context.Context, query string, args ...interface{}) (gosql.Result, error) {
+	return t.conn.ExecContext(ctx, query, args...)
+}
+
+func (t *autocommitTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *gosql.Row {
+	return t.conn.QueryRowContext(ctx, query, args...)
+}
+
+func (t *autocommitTx) Commit() error {
+	return t.end()
+}
+
+func (t *autocommitTx) Rollback() error {
+	return t.end()
+}
+
+func (t *autocommitTx) end() error {
+	if t.pooled {
+		return t.conn.Close()
+	}
+	return nil
+}
+
+// beginApplyTx begins a transaction on db, or returns an autocommitTx on it with NonTransactionalApply.
+func (a *Applier) beginApplyTx(db txBeginner) (applyTx, error) {
+	if !a.mysqlContext.NonTransactionalApply {
+		tx, err := db.BeginTx(context.Background(), &gosql.TxOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return tx, nil
+	}
+	switch db := db.(type) {
+	case *gosql.Conn:
+		return &autocommitTx{conn: db}, nil
+	case *gosql.DB:
+		// the same session for all the statements, e.g. for foreign_key_checks
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &autocommitTx{conn: conn, pooled: true}, nil
+	default:
+		return nil, fmt.Errorf("NonTransactionalApply: unexpected connection %T", db)
+	}
+}
+
+// recordExecutedGtidError handles a failure to record the GTID of an applied transaction. With
+// NonTransactionalApply, the statements of the transaction are committed already: it is only logged,
+// and the transaction is applied again on a restart from before it.
+func (a *Applier) recordExecutedGtidError(gtid string, err error) error {
+	if !a.mysqlContext.NonTransactionalApply {
+		return err
+	}
+	a.logger.Warnf("mysql.applier: NonTransactionalApply: failed to record the executed gtid %v: %v", gtid, err)
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// A transaction failing in the middle on a non-transactional table is applied in part, and in full when
+// applied again after the restart.
+func TestApplier_nonTransactionalApply(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		NonTransactionalApply: true,
+		OnApplyError:          config.OnApplyErrorHalt,
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	if _, err := a.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_nontx;" +
+		"create table dtle_test.t_nontx (id bigint primary key, val varchar(4)) engine=MyISAM"); err != nil {
+		t.Fatalf("create table error = %v", err)
+	}
+	if _, err := a.dbs[0].Db.ExecContext(context.Background(), "set @@session.sql_mode = 'STRICT_ALL_TABLES'"); err != nil {
+		t.Fatalf("set sql_mode error = %v", err)
+	}
+
+	sid := uuid.NewV4()
+	insert := func(id int64, val string) binlog.DataEvent {
+		var v1, v2 interface{} = id, val
+		return binlog.DataEvent{DML: binlog.InsertDML, DatabaseName: "dtle_test", TableName: "t_nontx",
+			NewColumnValues: &umconf.ColumnValues{AbstractValues: []*interface{}{&v1, &v2}}}
+	}
+	apply := func(entry *binlog.BinlogEntry) error {
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			return err
+		}
+		return a.commitApplyBatch(0, a.applyBatches[0])
+	}
+	count := func() (n int) {
+		if err := a.db.QueryRow("select count(*) from dtle_test.t_nontx").Scan(&n); err != nil {
+			t.Fatalf("count error = %v", err)
+		}
+		return n
+	}
+	executed := func(entry *binlog.BinlogEntry) bool {
+		// as loaded on a restart
+		a.gtidExecuted = nil
+		executed, _, err := a.checkExecuted(entry)
+		if err != nil {
+			t.Fatalf("checkExecuted() error = %v", err)
+		}
+		return executed
+	}
+
+	tx1 := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: 1},
+		Events:      []binlog.DataEvent{insert(1, "a"), insert(2, "b")},
+	}
+	if err := apply(tx1); err != nil {
+		t.Fatalf("apply tx1 error = %v", err)
+	}
+	if n := count(); n != 2 || !executed(tx1) {
+		t.Errorf("after tx1: %v rows, executed %v. want 2 rows, executed", n, executed(tx1))
+	}
+
+	// the second row is too long
+	tx2 := &binlog.BinlogEntry{
+		Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: 2},
+		Events:      []binlog.DataEvent{insert(3, "c"), insert(4, "too long")},
+	}
+	if err := apply(tx2); err == nil {
+		t.Fatalf("apply tx2: want an error")
+	}
+	if n := count(); n != 3 || executed(tx2) {
+		t.Errorf("after the failure of tx2: %v rows, executed %v. want the first row applied, not executed", n, executed(tx2))
+	}
+
+	// the restart, after the destination is fixed. tx2 is received again from the checkpoint.
+	if _, err := a.db.Exec("alter table dtle_test.t_nontx modify val varchar(16)"); err != nil {
+		t.Fatalf("alter table error = %v", err)
+	}
+	a.getTableItem("dtle_test", "t_nontx").Reset()
+	if err := apply(tx2); err != nil {
+		t.Fatalf("apply tx2 again error = %v", err)
+	}
+	if n := count(); n != 4 || !executed(tx2) {
+		t.Errorf("after tx2 is applied again: %v rows, executed %v. want 4 rows, executed", n, executed(tx2))
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// TablePreview is a table to be replicated by a job. See PreviewTables.
+type TablePreview struct {
+	TableSchema string `json:"table_schema"`
+	TableName   string `json:"table_name"`
+	// TABLE_ROWS of information_schema.TABLES. It is an estimate for InnoDB.
+	RowsEstimate int64 `json:"rows_estimate"`
+}
+
+// PreviewTables resolves ReplicateDoDb and ReplicateIgnoreDb of a source task config on the source,
+// the same way the extractor does when the job starts. Nothing is changed on the source.
+func PreviewTables(cfg *config.MySQLDriverConfig, logger *logrus.Logger) ([]*TablePreview, error) {
+	var tables []*TablePreview
+	err := inspectSourceTables(cfg, logger, func(e *Extractor) error {
+		for _, db := range e.replicateDoDb {
+			for _, tb := range db.Tables {
+				rows, err := readRowsEstimate(e.db, db.TableSchema, tb.TableName)
+				if err != nil {
+					return err
+				}
+				tables = append(tables, &TablePreview{
+					TableSchema:  db.TableSchema,
+					TableName:    tb.TableName,
+					RowsEstimate: rows,
+				})
+			}
+		}
+		return nil
+	})
+	return tables, err
+}
+
+// inspectSourceTables connects to the source of a source task config, and calls f with an extractor on which
+// the tables to be replicated are resolved (e.replicateDoDb) and e.db is open.
+func inspectSourceTables(cfg *config.MySQLDriverConfig, logger *logrus.Logger, f func(e *Extractor) error) error {
+	e, err := NewExtractor(&common.ExecContext{Subject: "preview"}, cfg, logger)
+	if err != nil {
+		return err
+	}
+	if e.tunnel, err = sql.NewTunnel(e.mysqlContext.ConnectionConfig, e.logger); err != nil {
+		return fmt.Errorf("failed to establish the tunnel to the source: %v", err)
+	}
+	defer e.tunnel.Close()
+
+	// Only the connection is needed. The other validations of the inspector are not.
+	e.inspector = NewInspector(e.mysqlContext, e.logger)
+	if e.inspector.db, err = sql.CreateDB(e.mysqlContext.ConnectionConfig.GetDBUri()); err != nil {
+		return err
+	}
+	defer e.inspector.db.Close()
+	if err := e.inspector.validateConnection(); err != nil {
+		return err
+	}
+	if err := e.inspector.readLowerCaseTableNames(); err != nil {
+		return err
+	}
+	e.db = e.inspector.db
+
+	if err := e.inspectTables(); err != nil {
+		return err
+	}
+	return f(e)
+}
+
+func readRowsEstimate(db *gosql.DB, schemaName string, tableName string) (rows int64, err error) {
+	query := `select ifnull(TABLE_ROWS, 0) from information_schema.TABLES where TABLE_SCHEMA = ? and TABLE_NAME = ?`
+	err = db.QueryRow(query, schemaName, tableName).Scan(&rows)
+	if err == gosql.ErrNoRows {
+		return 0, fmt.Errorf("table %v.%v not found on the source", schemaName, tableName)
+	}
+	return rows, err
+}
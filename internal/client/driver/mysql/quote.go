@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// validateIdentifierQuote checks IdentifierQuote.
+func validateIdentifierQuote(cfg *config.MySQLDriverConfig) (errs []error) {
+	switch cfg.IdentifierQuote {
+	case "", config.IdentifierQuoteBacktick, config.IdentifierQuoteANSI:
+	default:
+		errs = append(errs, fmt.Errorf("bad job argument: IdentifierQuote=%v. should be one of %v, %v",
+			cfg.IdentifierQuote, config.IdentifierQuoteBacktick, config.IdentifierQuoteANSI))
+	}
+	return errs
+}
+
+// identifierQuote returns the quote of the names in the statements generated for the destination, by IdentifierQuote.
+// The columns of getTableColumns are quoted with it.
+func (a *Applier) identifierQuote() umconf.IdentifierQuote {
+	if a.mysqlContext.IdentifierQuote == config.IdentifierQuoteANSI {
+		return umconf.ANSIQuote
+	}
+	return umconf.BacktickQuote
+}
+
+// quoteName quotes a schema or table name in a statement generated for the destination.
+func (a *Applier) quoteName(name string) string {
+	return a.identifierQuote().Quote(name)
+}
+
+// sqlModeStatement returns setSqlMode, which sets the sql_mode of the source on a session for the full copy,
+// keeping ANSI_QUOTES with IdentifierQuoteANSI, as the names are quoted with it.
+func (a *Applier) sqlModeStatement(setSqlMode string) string {
+	if setSqlMode == "" || a.identifierQuote() != umconf.ANSIQuote {
+		return setSqlMode
+	}
+	return setSqlMode + ", @@session.sql_mode = CONCAT_WS(',', NULLIF(@@session.sql_mode, ''), 'ANSI_QUOTES')"
+}
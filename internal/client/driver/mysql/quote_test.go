@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func TestIdentifierQuote_Quote(t *testing.T) {
+	tests := []struct {
+		quote umconf.IdentifierQuote
+		name  string
+		want  string
+	}{
+		{umconf.BacktickQuote, "order", "`order`"},
+		{umconf.BacktickQuote, "a`b", "`a``b`"},
+		{umconf.BacktickQuote, `a"b`, "`a\"b`"},
+		{umconf.ANSIQuote, "order", `"order"`},
+		{umconf.ANSIQuote, `a"b`, `"a""b"`},
+		{umconf.ANSIQuote, "a`b", "\"a`b\""},
+	}
+	for _, tt := range tests {
+		if got := tt.quote.Quote(tt.name); got != tt.want {
+			t.Errorf("%c.Quote(%v) = %v, want %v", tt.quote, tt.name, got, tt.want)
+		}
+	}
+}
+
+// The statements on a table named and with columns named by reserved words.
+func TestIdentifierQuote_buildDML(t *testing.T) {
+	spaces := regexp.MustCompile(`\s+`)
+	for _, quote := range []umconf.IdentifierQuote{umconf.BacktickQuote, umconf.ANSIQuote} {
+		q := func(s string) string {
+			return strings.Replace(s, "`", string(quote), -1)
+		}
+		columns := umconf.NewColumnList(umconf.NewColumns([]string{"key", "order", "group"}))
+		columns.Columns[0].Key = "PRI"
+		columns.SetQuote(quote)
+		values := []interface{}{1, "a", "b"}
+		args := make([]*interface{}, len(values))
+		for i := range values {
+			args[i] = &values[i]
+		}
+
+		insert, _, err := sql.BuildDMLInsertQuery("select", "table", columns, columns, columns, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		update, _, _, _, err := sql.BuildDMLUpdateQuery("select", "table", columns, columns, columns, columns, args, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		del, _, _, err := sql.BuildDMLDeleteQuery("select", "table", columns, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		load := buildLoadDataQuery("r", "select", "table", "utf8mb4", columns, []bool{false, false, true}, nil, false)
+
+		tests := []struct {
+			name string
+			got  string
+			want string
+		}{
+			{"insert", insert, q("replace into `select`.`table` (`key`, `order`, `group`) values (?, ?, ?)")},
+			{"update", update, q("update `select`.`table` set `key`=?, `order`=?, `group`=? where ((`key` = ?)) limit 1")},
+			{"delete", del, q("delete from `select`.`table` where ((`key` = ?))")},
+			{"load", load, q("load data local infile 'Reader::r' replace into table `select`.`table` character set utf8mb4" +
+				" fields terminated by ',' optionally enclosed by '\"' escaped by '\\\\' lines terminated by '\\n'" +
+				" (`key`,`order`,@dtle_2) set `group` = unhex(@dtle_2)")},
+		}
+		for _, tt := range tests {
+			if got := strings.TrimSpace(spaces.ReplaceAllString(tt.got, " ")); got != tt.want {
+				t.Errorf("%c %v:\n got %v\nwant %v", quote, tt.name, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+// eventRateLimiter is a token bucket limiting the rate of events. The rate can be changed while it is in use.
+// A bucket holds up to 100ms of events, so that short bursts are allowed but the rate over a second is not exceeded much.
+type eventRateLimiter struct {
+	lock sync.Mutex
+	// events per second. 0: unlimited
+	rate   int
+	tokens float64
+	last   time.Time
+}
+
+func newEventRateLimiter(rate int) *eventRateLimiter {
+	l := &eventRateLimiter{}
+	l.setRate(rate)
+	return l
+}
+
+func (l *eventRateLimiter) burst() float64 {
+	burst := float64(l.rate) / 10
+	if burst < 1 {
+		burst = 1
+	}
+	return burst
+}
+
+func (l *eventRateLimiter) setRate(rate int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	wasUnlimited := l.rate <= 0
+	l.rate = rate
+	if burst := l.burst(); wasUnlimited || l.tokens > burst {
+		// Start with a full bucket.
+		l.tokens = burst
+	}
+}
+
+func (l *eventRateLimiter) getRate() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.rate
+}
+
+// refill adds the tokens since the last event. l.lock is held.
+func (l *eventRateLimiter) refill(now time.Time) {
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+		if burst := l.burst(); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+}
+
+// reserve takes a token for an event as of now and returns how long to wait before the event.
+func (l *eventRateLimiter) reserve(now time.Time) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	// The deficit is repaid while waiting.
+	return time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+}
+
+// allow takes a token for an event as of now if there is one, and tells whether the event is allowed.
+// Unlike reserve, an event which is not allowed is dropped rather than delayed.
+func (l *eventRateLimiter) allow(now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.rate <= 0 {
+		return true
+	}
+	l.refill(now)
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// wait blocks until an event is allowed. It returns false if shutdownCh is closed meanwhile.
+func (l *eventRateLimiter) wait(shutdownCh chan struct{}) bool {
+	delay := l.reserve(time.Now())
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-shutdownCh:
+		return false
+	}
+}
+
+func validateApplyEventRateLimit(rate int) error {
+	if rate < 0 {
+		return fmt.Errorf("bad job argument: ApplyEventRateLimit=%v. should be 0 (unlimited) or positive", rate)
+	}
+	return nil
+}
+
+// UpdateConfig implements driver.ConfigUpdater. ApplyEventRateLimit takes effect from the next transaction.
+func (a *Applier) UpdateConfig(m map[string]interface{}) error {
+	var cfg config.MySQLDriverConfig
+	if err := mapstructure.WeakDecode(m, &cfg); err != nil {
+		return err
+	}
+	if _, ok := m["ApplyEventRateLimit"]; ok {
+		if err := validateApplyEventRateLimit(cfg.ApplyEventRateLimit); err != nil {
+			return err
+		}
+		a.logger.Infof("mysql.applier: ApplyEventRateLimit: %v -> %v", a.eventRateLimiter.getRate(), cfg.ApplyEventRateLimit)
+		a.eventRateLimiter.setRate(cfg.ApplyEventRateLimit)
+	}
+	return nil
+}
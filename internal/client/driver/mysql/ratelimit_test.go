@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func Test_eventRateLimiter_reserve(t *testing.T) {
+	t0 := time.Unix(1500000000, 0)
+	l := newEventRateLimiter(10)
+	steps := []struct {
+		now  time.Time
+		want time.Duration
+	}{
+		{t0, 0}, // a full bucket of 1 event
+		{t0, 100 * time.Millisecond},
+		{t0, 200 * time.Millisecond},
+		{t0.Add(300 * time.Millisecond), 0},
+		{t0.Add(time.Hour), 0}, // the bucket does not hold more than 1 event
+		{t0.Add(time.Hour), 100 * time.Millisecond},
+	}
+	for i, step := range steps {
+		if got := l.reserve(step.now); got != step.want {
+			t.Errorf("step %v: reserve() = %v, want %v", i, got, step.want)
+		}
+	}
+
+	l = newEventRateLimiter(0)
+	for i := 0; i < 100; i++ {
+		if got := l.reserve(t0); got != 0 {
+			t.Fatalf("reserve() = %v when unlimited, want 0", got)
+		}
+	}
+}
+
+func Test_eventRateLimiter_allow(t *testing.T) {
+	t0 := time.Unix(1500000000, 0)
+	l := newEventRateLimiter(20)
+	steps := []struct {
+		now  time.Time
+		want bool
+	}{
+		{t0, true}, // a full bucket of 2 events
+		{t0, true},
+		{t0, false},
+		{t0.Add(20 * time.Millisecond), false}, // dropped events do not go into debt
+		{t0.Add(50 * time.Millisecond), true},
+		{t0.Add(time.Hour), true},
+		{t0.Add(time.Hour), true},
+		{t0.Add(time.Hour), false},
+	}
+	for i, step := range steps {
+		if got := l.allow(step.now); got != step.want {
+			t.Errorf("step %v: allow() = %v, want %v", i, got, step.want)
+		}
+	}
+
+	l = newEventRateLimiter(0)
+	for i := 0; i < 100; i++ {
+		if !l.allow(t0) {
+			t.Fatalf("allow() = false when unlimited")
+		}
+	}
+}
+
+func Test_eventRateLimiter_wait(t *testing.T) {
+	shutdownCh := make(chan struct{})
+	measure := func(l *eventRateLimiter, n int) float64 {
+		// drain the bucket first
+		for i := 0; i < int(l.burst()); i++ {
+			l.wait(shutdownCh)
+		}
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if !l.wait(shutdownCh) {
+				t.Fatal("wait() = false")
+			}
+		}
+		return float64(n) / time.Since(start).Seconds()
+	}
+
+	l := newEventRateLimiter(200)
+	if rate := measure(l, 200); rate < 180 || rate > 220 {
+		t.Errorf("measured rate = %.1f, want about 200", rate)
+	}
+	// adjusted while in use
+	l.setRate(500)
+	if rate := measure(l, 500); rate < 450 || rate > 550 {
+		t.Errorf("measured rate = %.1f after setRate(500), want about 500", rate)
+	}
+
+	l = newEventRateLimiter(1)
+	l.wait(shutdownCh)
+	close(shutdownCh)
+	if l.wait(shutdownCh) {
+		t.Errorf("wait() = true after shutdown, want false")
+	}
+}
+
+func TestApplier_UpdateConfig(t *testing.T) {
+	a := &Applier{
+		logger:           logrus.NewEntry(logrus.New()),
+		eventRateLimiter: newEventRateLimiter(0),
+	}
+	// values of a job file are strings or numbers
+	if err := a.UpdateConfig(map[string]interface{}{"ApplyEventRateLimit": "50"}); err != nil {
+		t.Fatalf("Applier.UpdateConfig() error = %v", err)
+	}
+	if got := a.eventRateLimiter.getRate(); got != 50 {
+		t.Errorf("rate = %v, want 50", got)
+	}
+	if err := a.UpdateConfig(map[string]interface{}{"ApplyEventRateLimit": -1}); err == nil {
+		t.Errorf("Applier.UpdateConfig() expects an error")
+	}
+	if got := a.eventRateLimiter.getRate(); got != 50 {
+		t.Errorf("rate = %v after a bad value, want 50", got)
+	}
+	if err := a.UpdateConfig(map[string]interface{}{"ApplyEventRateLimit": 0}); err != nil {
+		t.Fatalf("Applier.UpdateConfig() error = %v", err)
+	}
+	if got := a.eventRateLimiter.getRate(); got != 0 {
+		t.Errorf("rate = %v, want 0", got)
+	}
+}
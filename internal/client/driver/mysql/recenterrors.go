@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"sync"
+	"time"
+
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// recentErrors is a ring buffer of the last errors of a task. A nil one keeps nothing.
+type recentErrors struct {
+	lock    sync.Mutex
+	records []*models.ErrorRecord
+	// where the next record goes
+	next int
+	full bool
+}
+
+func newRecentErrors(size int) *recentErrors {
+	if size < 1 {
+		size = 1
+	}
+	return &recentErrors{records: make([]*models.ErrorRecord, size)}
+}
+
+func (r *recentErrors) add(record *models.ErrorRecord) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list returns the records, oldest first.
+func (r *recentErrors) list() []*models.ErrorRecord {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var result []*models.ErrorRecord
+	if r.full {
+		result = append(result, r.records[r.next:]...)
+	}
+	return append(result, r.records[:r.next]...)
+}
+
+// The errors are kept across restarts of a task, which are often caused by them.
+var recentErrorsByTask = make(map[string]*recentErrors)
+var recentErrorsByTaskLock sync.Mutex
+
+// getRecentErrors returns the errors of the task of the job, or a new buffer of them.
+func getRecentErrors(subject string, taskType string, size int) *recentErrors {
+	recentErrorsByTaskLock.Lock()
+	defer recentErrorsByTaskLock.Unlock()
+	key := subject + "/" + taskType
+	r, ok := recentErrorsByTask[key]
+	if !ok || len(r.records) != size {
+		newR := newRecentErrors(size)
+		if ok {
+			for _, record := range r.list() {
+				newR.add(record)
+			}
+		}
+		r = newR
+		recentErrorsByTask[key] = r
+	}
+	return r
+}
+
+func newErrorRecord(errorType string, gtid string, err error) *models.ErrorRecord {
+	return &models.ErrorRecord{
+		Time:    time.Now().UnixNano(),
+		Type:    errorType,
+		Gtid:    gtid,
+		Message: err.Error(),
+	}
+}
+
+func (a *Applier) recordError(errorType string, gtid string, err error) {
+	a.recentErrors.add(newErrorRecord(errorType, gtid, err))
+}
+
+func (e *Extractor) recordError(errorType string, gtid string, err error) {
+	e.recentErrors.add(newErrorRecord(errorType, gtid, err))
+}
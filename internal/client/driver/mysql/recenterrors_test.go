@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func messages(records []*models.ErrorRecord) (result []string) {
+	for _, record := range records {
+		result = append(result, record.Message)
+	}
+	return result
+}
+
+func TestRecentErrors(t *testing.T) {
+	r := newRecentErrors(3)
+	if got := r.list(); len(got) != 0 {
+		t.Errorf("list() = %v, want empty", got)
+	}
+	for i := 1; i <= 2; i++ {
+		r.add(newErrorRecord(models.ErrorTypeApply, "", fmt.Errorf("e%v", i)))
+	}
+	if got, want := messages(r.list()), []string{"e1", "e2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list() = %v, want %v", got, want)
+	}
+	for i := 3; i <= 7; i++ {
+		r.add(newErrorRecord(models.ErrorTypeApply, "", fmt.Errorf("e%v", i)))
+	}
+	if got, want := messages(r.list()), []string{"e5", "e6", "e7"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list() = %v, want %v", got, want)
+	}
+
+	var nilR *recentErrors
+	nilR.add(newErrorRecord(models.ErrorTypeApply, "", fmt.Errorf("e")))
+	if got := nilR.list(); got != nil {
+		t.Errorf("list() of nil = %v, want nil", got)
+	}
+}
+
+// The errors are kept when the task restarts, possibly with another MaxRecentErrors.
+func TestGetRecentErrors(t *testing.T) {
+	subject := "TestGetRecentErrors"
+	r := getRecentErrors(subject, models.TaskTypeDest, 3)
+	for i := 1; i <= 3; i++ {
+		r.add(newErrorRecord(models.ErrorTypeTask, "", fmt.Errorf("e%v", i)))
+	}
+	if got := getRecentErrors(subject, models.TaskTypeSrc, 3).list(); len(got) != 0 {
+		t.Errorf("errors of another task = %v, want empty", messages(got))
+	}
+	if got := getRecentErrors(subject, models.TaskTypeDest, 3); got != r {
+		t.Errorf("getRecentErrors() after a restart is a new buffer")
+	}
+	got := messages(getRecentErrors(subject, models.TaskTypeDest, 2).list())
+	if want := []string{"e2", "e3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list() after resizing = %v, want %v", got, want)
+	}
+}
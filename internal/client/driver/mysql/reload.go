@@ -0,0 +1,258 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// ReplicateDoDb is changed on a running task by adding and removing tables, listed by name. A removed table is
+// no longer replicated, and is left as is on the destinations. An added table is copied like a resync (see
+// tableResync): it is created on the destinations and dumped in a snapshot taken while the binlog reader is
+// held, so that it is replicated from exactly the snapshot on. The other tables are not held back.
+
+// diffReplicateDoDb returns the tables added to and removed from ReplicateDoDb, both as configured.
+// Changes other than adding and removing tables require a restart of the task.
+func diffReplicateDoDb(oldDoDb []*config.DataSource, newDoDb []*config.DataSource,
+	caseInsensitive bool) (added []*config.Table, removed []*config.Table, err error) {
+
+	for _, doDb := range [][]*config.DataSource{oldDoDb, newDoDb} {
+		if len(doDb) == 0 {
+			return nil, nil, fmt.Errorf("ReplicateDoDb: all the schemas are replicated." +
+				" only tables listed by name can be added or removed on a running task")
+		}
+		for _, db := range doDb {
+			if err := checkListedTables(db); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	find := func(doDb []*config.DataSource, schemaName string, tableName string) (*config.DataSource, *config.Table) {
+		for _, db := range doDb {
+			if db.TableSchema != schemaName {
+				continue
+			}
+			for _, tb := range db.Tables {
+				if config.MatchTableName(tb.TableName, tableName, caseInsensitive) {
+					return db, tb
+				}
+			}
+		}
+		return nil, nil
+	}
+
+	for _, db := range newDoDb {
+		for _, tb := range db.Tables {
+			oldDb, oldTb := find(oldDoDb, db.TableSchema, tb.TableName)
+			if oldTb == nil {
+				table := *tb
+				table.TableSchema = db.TableSchema
+				table.TableSchemaRename = db.TableSchemaRename
+				if table.Where == "" {
+					table.Where = "true"
+				}
+				added = append(added, &table)
+				continue
+			}
+			if oldDb.TableSchemaRename != db.TableSchemaRename || oldTb.TableRename != tb.TableRename ||
+				whereOrTrue(oldTb.Where) != whereOrTrue(tb.Where) || oldTb.SampleRate != tb.SampleRate ||
+				!reflect.DeepEqual(oldTb.ColumnMapFrom, tb.ColumnMapFrom) ||
+				!reflect.DeepEqual(oldTb.EncryptColumns, tb.EncryptColumns) {
+				return nil, nil, fmt.Errorf("ReplicateDoDb: %v.%v is changed. only adding and removing tables"+
+					" take effect on a running task. restart the task for the other changes", db.TableSchema, tb.TableName)
+			}
+		}
+	}
+	for _, db := range oldDoDb {
+		for _, tb := range db.Tables {
+			if _, newTb := find(newDoDb, db.TableSchema, tb.TableName); newTb == nil {
+				removed = append(removed, config.NewTable(db.TableSchema, tb.TableName))
+			}
+		}
+	}
+	return added, removed, nil
+}
+
+// checkListedTables checks that db lists its tables by name, for them to be added or removed at runtime.
+func checkListedTables(db *config.DataSource) error {
+	if db.TableSchema == "" || db.TableSchemaRegex != "" {
+		return fmt.Errorf("ReplicateDoDb: schema %v is matched by TableSchemaRegex."+
+			" only tables listed by name can be added or removed on a running task", db.TableSchemaRegex)
+	}
+	if len(db.Tables) == 0 {
+		return fmt.Errorf("ReplicateDoDb: all the tables of %v are replicated."+
+			" only tables listed by name can be added or removed on a running task", db.TableSchema)
+	}
+	for _, tb := range db.Tables {
+		if tb.TableName == "" || tb.TableRegex != "" || config.IsTableNameRegex(tb.TableName) {
+			return fmt.Errorf("ReplicateDoDb: tables of %v are matched by a regex."+
+				" only tables listed by name can be added or removed on a running task", db.TableSchema)
+		}
+	}
+	return nil
+}
+
+func whereOrTrue(where string) string {
+	if where == "" {
+		return "true"
+	}
+	return where
+}
+
+// updateReplicateDoDb applies a new ReplicateDoDb to the running task. The removed tables are no longer
+// replicated at once, and the added ones are copied one by one in the background.
+func (e *Extractor) updateReplicateDoDb(doDb []*config.DataSource) error {
+	bs, err := json.Marshal(doDb)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bs, e.replicateDoDbConfig) {
+		return nil
+	}
+	var oldDoDb []*config.DataSource
+	if err := json.Unmarshal(e.replicateDoDbConfig, &oldDoDb); err != nil {
+		return err
+	}
+	added, removed, err := diffReplicateDoDb(oldDoDb, doDb, e.mysqlContext.LowerCaseTableNames != 0)
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		if err := e.checkResync(); err != nil {
+			return fmt.Errorf("changing ReplicateDoDb on a running task: %v", err)
+		}
+		if atomic.LoadInt32(&e.resyncing) != 0 || atomic.LoadInt32(&e.addingTables) != 0 {
+			return fmt.Errorf("changing ReplicateDoDb on a running task: a table is being copied. retry later")
+		}
+	}
+	for _, table := range added {
+		// The table is checked now, and inspected again when it is copied.
+		if err := e.inspector.ValidateOriginalTable(table.TableSchema, table.TableName, table); err != nil {
+			return fmt.Errorf("ReplicateDoDb: %v.%v: %v", table.TableSchema, table.TableName, err)
+		}
+	}
+
+	if len(removed) > 0 {
+		e.binlogReader.LockTables()
+		for _, table := range removed {
+			e.binlogReader.RemoveTable(table.TableSchema, table.TableName)
+		}
+		e.binlogReader.UnlockTables()
+		e.replicateDoDbLock.Lock()
+		for _, table := range removed {
+			e.replicateDoDb = config.WithoutTable(e.replicateDoDb, table.TableSchema, table.TableName,
+				e.mysqlContext.LowerCaseTableNames != 0)
+			e.logger.Infof("mysql.extractor: ReplicateDoDb: stopped replicating %v.%v", table.TableSchema, table.TableName)
+		}
+		e.replicateDoDbLock.Unlock()
+	}
+	e.replicateDoDbConfig = bs
+	if len(added) > 0 {
+		atomic.StoreInt32(&e.addingTables, 1)
+		go e.addTables(added)
+	}
+	return nil
+}
+
+// addTables copies the added tables one by one, each after the resync before it is done.
+func (e *Extractor) addTables(tables []*config.Table) {
+	defer atomic.StoreInt32(&e.addingTables, 0)
+	for _, table := range tables {
+		for !atomic.CompareAndSwapInt32(&e.resyncing, 0, 1) {
+			select {
+			case <-e.shutdownCh:
+				return
+			case <-time.After(time.Second):
+			}
+		}
+		if err := e.addTable(table); err != nil {
+			e.logger.Errorf("mysql.extractor: ReplicateDoDb: adding %v.%v failed. err: %v",
+				table.TableSchema, table.TableName, err)
+			e.recordError(models.ErrorTypeResync, "",
+				fmt.Errorf("adding %v.%v to ReplicateDoDb: %v", table.TableSchema, table.TableName, err))
+			atomic.StoreInt32(&e.resyncing, 0)
+		}
+	}
+}
+
+// addTable starts replicating table and copies it like a resync. An error is returned if nothing has changed.
+// The snapshot is taken and the table is added to the binlog reader while the reader is held, and the resync
+// is passed to sendIncrEntries before the reader goes on: the events of the table are read from the snapshot on,
+// and are all taken by the resync.
+func (e *Extractor) addTable(table *config.Table) error {
+	if err := e.inspector.ValidateOriginalTable(table.TableSchema, table.TableName, table); err != nil {
+		return err
+	}
+	id := fmt.Sprintf("add-%v.%v", table.TableSchema, table.TableName)
+	createEntry := &DumpEntry{TableSchema: table.TableSchema, TableName: table.TableName}
+	if !e.mysqlContext.SkipCreateDbTable {
+		var err error
+		if createEntry.DbSQL, err = e.createSchemaSQL(e.db, table.TableSchema, table.TableSchemaRename); err != nil {
+			return err
+		}
+		if createEntry.TbSQL, err = e.createTableSQL(e.db, table, table.TableSchemaRename); err != nil {
+			return err
+		}
+	}
+
+	e.binlogReader.LockTables()
+	tx, coordinates, err := e.beginConsistentSnapshot(e.db)
+	if err != nil {
+		e.binlogReader.UnlockTables()
+		return err
+	}
+	defer tx.Rollback()
+	r, err := newTableResyncAt(id, table, coordinates)
+	if err == nil {
+		r.create, err = e.resyncPart(r, createEntry)
+	}
+	if err == nil {
+		err = e.binlogReader.AddTable(table)
+	}
+	if err != nil {
+		e.binlogReader.UnlockTables()
+		return err
+	}
+	registered := e.registerResync(r)
+	e.binlogReader.UnlockTables()
+	if !registered {
+		return nil // shutdown
+	}
+	e.replicateDoDbLock.Lock()
+	e.replicateDoDb = config.WithTable(e.replicateDoDb, table)
+	e.replicateDoDbLock.Unlock()
+	e.logger.Infof("mysql.extractor: ReplicateDoDb: added %v.%v. dumping the table at %v",
+		table.TableSchema, table.TableName, coordinates.GtidSet)
+
+	// From now on, errors fail the task, as the table is replicated but incomplete on the destinations.
+	// A copy, as the dumper keeps its position in it.
+	t := *table
+	d := NewDumper(tx, &t, e.mysqlContext.ChunkSize, e.logger)
+	if e.adminDB != e.db {
+		d.checksumDB = e.adminDB
+	}
+	d.throttler = e.throttler
+	d.cipher = e.cipher
+	d.chunkSizeBytes = e.mysqlContext.ChunkSizeBytes
+	if err := d.Dump(); err != nil {
+		e.onError(TaskStateDead, err)
+		return nil
+	}
+	e.dumpersLock.Lock()
+	e.dumpers = append(e.dumpers, d)
+	e.dumpersLock.Unlock()
+	e.sendResyncChunks(r, d)
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func newReloadTestDoDb(t *testing.T, s string) []*config.DataSource {
+	var doDb []*config.DataSource
+	if err := json.Unmarshal([]byte(s), &doDb); err != nil {
+		t.Fatal(err)
+	}
+	return doDb
+}
+
+func reloadTestNames(tables []*config.Table) []string {
+	var names []string
+	for _, tb := range tables {
+		names = append(names, tb.TableSchema+"."+tb.TableName)
+	}
+	return names
+}
+
+func TestDiffReplicateDoDb(t *testing.T) {
+	old := `[{"TableSchema":"db1","Tables":[{"TableName":"t1"},{"TableName":"t2","Where":"id>1"}]}]`
+	tests := []struct {
+		name        string
+		newDoDb     string
+		wantAdded   []string
+		wantRemoved []string
+		wantErr     string
+	}{
+		{"unchanged", old, nil, nil, ""},
+		{"add a table and a schema",
+			`[{"TableSchema":"db1","Tables":[{"TableName":"t1"},{"TableName":"t2","Where":"id>1"},{"TableName":"t3"}]},
+			  {"TableSchema":"db2","TableSchemaRename":"db2r","Tables":[{"TableName":"t1"}]}]`,
+			[]string{"db1.t3", "db2.t1"}, nil, ""},
+		{"remove a table",
+			`[{"TableSchema":"db1","Tables":[{"TableName":"t2","Where":"id>1"}]}]`,
+			nil, []string{"db1.t1"}, ""},
+		{"add and remove",
+			`[{"TableSchema":"db1","Tables":[{"TableName":"t1","Where":"true"},{"TableName":"t3"}]}]`,
+			[]string{"db1.t3"}, []string{"db1.t2"}, ""},
+		{"change a table",
+			`[{"TableSchema":"db1","Tables":[{"TableName":"t1"},{"TableName":"t2","Where":"id>2"}]}]`,
+			nil, nil, "restart the task"},
+		{"rename a schema",
+			`[{"TableSchema":"db1","TableSchemaRename":"x","Tables":[{"TableName":"t1"},{"TableName":"t2","Where":"id>1"}]}]`,
+			nil, nil, "restart the task"},
+		{"all the tables of a schema", `[{"TableSchema":"db1"}]`, nil, nil, "listed by name"},
+		{"a regex", `[{"TableSchema":"db1","Tables":[{"TableName":"t1"},{"TableRegex":"^t"}]}]`, nil, nil, "listed by name"},
+		{"all the schemas", `[]`, nil, nil, "listed by name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed, err := diffReplicateDoDb(newReloadTestDoDb(t, old), newReloadTestDoDb(t, tt.newDoDb), false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("diffReplicateDoDb() err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reloadTestNames(added); !reflect.DeepEqual(got, tt.wantAdded) {
+				t.Errorf("added = %v, want %v", got, tt.wantAdded)
+			}
+			if got := reloadTestNames(removed); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", got, tt.wantRemoved)
+			}
+			for _, tb := range added {
+				if tb.Where != "true" {
+					t.Errorf("Where of added %v = %q, want \"true\"", tb.TableName, tb.Where)
+				}
+				if tb.TableSchema == "db2" && tb.TableSchemaRename != "db2r" {
+					t.Errorf("TableSchemaRename of added %v = %q, want db2r", tb.TableName, tb.TableSchemaRename)
+				}
+			}
+		})
+	}
+}
+
+// Adding a table to the ReplicateDoDb of a running task and removing it again.
+func TestWithTable_WithoutTable(t *testing.T) {
+	doDb := newReloadTestDoDb(t, `[{"TableSchema":"db1","Tables":[{"TableName":"t1"}]}]`)
+	orig := newReloadTestDoDb(t, `[{"TableSchema":"db1","Tables":[{"TableName":"t1"}]}]`)
+
+	added := config.WithTable(doDb, config.NewTable("db1", "t2"))
+	added = config.WithTable(added, config.NewTable("db2", "t1"))
+	if len(added) != 2 || len(added[0].Tables) != 2 || added[1].TableSchema != "db2" || len(added[1].Tables) != 1 {
+		t.Fatalf("WithTable() = %v", added)
+	}
+	if !reflect.DeepEqual(doDb, orig) {
+		t.Errorf("WithTable() changed its argument")
+	}
+	for _, name := range []string{"t1", "t2", "t3"} {
+		if got, want := config.MatchDestDoDb(added, "db1", name), name != "t3"; got != want {
+			t.Errorf("db1.%v matched = %v, want %v", name, got, want)
+		}
+	}
+
+	removed := config.WithoutTable(added, "db1", "T2", true)
+	removed = config.WithoutTable(removed, "db2", "t1", false)
+	if !reflect.DeepEqual(removed, orig) {
+		t.Errorf("WithoutTable() = %v, want the original ReplicateDoDb", removed)
+	}
+	if len(added) != 2 || len(added[0].Tables) != 2 {
+		t.Errorf("WithoutTable() changed its argument")
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+const (
+	replicaLagCheckInterval = time.Second
+	// While the replicas lag, the delay before each transaction (or chunk of the full copy) starts at
+	// replicaThrottleMinDelay and doubles on each check, up to replicaThrottleMaxDelay. When they have caught up,
+	// it halves on each check until it is under replicaThrottleMinDelay, and then the applier runs at full speed.
+	replicaThrottleMinDelay = 10 * time.Millisecond
+	replicaThrottleMaxDelay = 2 * time.Second
+)
+
+type throttleReplica struct {
+	// "host:port"
+	addr string
+	db   *gosql.DB
+}
+
+// replicaLagThrottler slows down the applier while the replicas of the destination lag, like the
+// --max-lag of pt-online-schema-change. See MySQLDriverConfig.ThrottleReplicas.
+// A nil throttler never throttles.
+type replicaLagThrottler struct {
+	logger   *logrus.Entry
+	maxLag   time.Duration
+	replicas []throttleReplica
+
+	lock  sync.Mutex
+	delay time.Duration
+}
+
+// newReplicaLagThrottler connects to ThrottleReplicas. It returns nil if none is set.
+func newReplicaLagThrottler(cfg *config.MySQLDriverConfig, logger *logrus.Entry) (*replicaLagThrottler, error) {
+	if len(cfg.ThrottleReplicas) == 0 {
+		return nil, nil
+	}
+	t := &replicaLagThrottler{
+		logger: logger,
+		maxLag: time.Duration(cfg.ThrottleReplicaMaxLag) * time.Second,
+	}
+	for _, addr := range cfg.ThrottleReplicas {
+		host, port, err := parseHostPort("ThrottleReplicas", addr)
+		if err != nil {
+			t.close()
+			return nil, err
+		}
+		conn := &umconf.ConnectionConfig{
+			Host:     host,
+			Port:     port,
+			User:     cfg.ConnectionConfig.User,
+			Password: cfg.ConnectionConfig.Password,
+			Charset:  cfg.ConnectionConfig.Charset,
+		}
+		db, err := sql.CreateDB(conn.GetDBUri())
+		if err != nil {
+			t.close()
+			return nil, err
+		}
+		db.SetMaxOpenConns(1)
+		t.replicas = append(t.replicas, throttleReplica{addr: addr, db: db})
+	}
+	return t, nil
+}
+
+// parseHostPort parses addr of the job argument name as host:port.
+func parseHostPort(name string, addr string) (string, int, error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("bad job argument: %v: %v. should be host:port", name, addr)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil || port <= 0 || port > 65535 || host == "" {
+		return "", 0, fmt.Errorf("bad job argument: %v: %v. should be host:port", name, addr)
+	}
+	return host, port, nil
+}
+
+func validateThrottleReplicas(cfg *config.MySQLDriverConfig) (errs []error) {
+	for _, addr := range cfg.ThrottleReplicas {
+		if _, _, err := parseHostPort("ThrottleReplicas", addr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.ThrottleReplicaMaxLag < 0 {
+		errs = append(errs, fmt.Errorf("bad job argument: ThrottleReplicaMaxLag=%v. should be positive",
+			cfg.ThrottleReplicaMaxLag))
+	}
+	return errs
+}
+
+// readReplicaLag returns Seconds_Behind_Master of SHOW SLAVE STATUS, or false if the replication is stopped,
+// for which it is NULL.
+func readReplicaLag(db *gosql.DB) (lag time.Duration, running bool, err error) {
+	rows, err := db.Query("SHOW SLAVE STATUS")
+	if err != nil {
+		return 0, false, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, false, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, false, err
+		}
+		return 0, false, fmt.Errorf("not a replica: SHOW SLAVE STATUS is empty")
+	}
+	values := make([]gosql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, false, err
+	}
+	for i, column := range columns {
+		if column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, false, nil
+		}
+		seconds, err := strconv.ParseInt(values[i].String, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("bad Seconds_Behind_Master %v: %v", values[i].String, err)
+		}
+		return time.Duration(seconds) * time.Second, true, nil
+	}
+	return 0, false, fmt.Errorf("no Seconds_Behind_Master in SHOW SLAVE STATUS")
+}
+
+// run checks the replicas every replicaLagCheckInterval until shutdownCh is closed.
+func (t *replicaLagThrottler) run(shutdownCh chan struct{}) {
+	defer t.close()
+	ticker := time.NewTicker(replicaLagCheckInterval)
+	defer ticker.Stop()
+	for {
+		t.check()
+		select {
+		case <-shutdownCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check queries the replicas, and adjusts the delay by whether any of them lags.
+func (t *replicaLagThrottler) check() {
+	lagging := false
+	for _, replica := range t.replicas {
+		lag, running, err := readReplicaLag(replica.db)
+		switch {
+		case err != nil:
+			t.logger.Warnf("mysql.applier: failed to get the lag of replica %v. ignoring it. err: %v", replica.addr, err)
+		case !running:
+			t.logger.Warnf("mysql.applier: the replication of replica %v is stopped. throttling", replica.addr)
+			lagging = true
+		case lag > t.maxLag:
+			t.logger.Debugf("mysql.applier: replica %v lags %v", replica.addr, lag)
+			lagging = true
+		}
+	}
+	t.update(lagging)
+}
+
+func (t *replicaLagThrottler) update(lagging bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	old := t.delay
+	if lagging {
+		t.delay *= 2
+		if t.delay < replicaThrottleMinDelay {
+			t.delay = replicaThrottleMinDelay
+		} else if t.delay > replicaThrottleMaxDelay {
+			t.delay = replicaThrottleMaxDelay
+		}
+	} else {
+		t.delay /= 2
+		if t.delay < replicaThrottleMinDelay {
+			t.delay = 0
+		}
+	}
+	if old == 0 && t.delay > 0 {
+		t.logger.Infof("mysql.applier: replicas lag more than ThrottleReplicaMaxLag %v. throttling", t.maxLag)
+	} else if old > 0 && t.delay == 0 {
+		t.logger.Infof("mysql.applier: replicas have caught up. stop throttling")
+	}
+}
+
+func (t *replicaLagThrottler) getDelay() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.delay
+}
+
+// wait is called before applying a transaction or a chunk. It returns false if shutdownCh is closed meanwhile.
+func (t *replicaLagThrottler) wait(shutdownCh chan struct{}) bool {
+	delay := t.getDelay()
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-shutdownCh:
+		return false
+	}
+}
+
+func (t *replicaLagThrottler) close() {
+	for _, replica := range t.replicas {
+		replica.db.Close()
+	}
+}
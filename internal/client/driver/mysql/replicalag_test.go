@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// replicaTestDriver simulates replicas, of which SHOW SLAVE STATUS returns Seconds_Behind_Master of
+// replicaTestLags by the name of the database. A missing replica fails, and a nil lag is a stopped replication.
+type replicaTestDriver struct{}
+
+var (
+	replicaTestLock sync.Mutex
+	replicaTestLags = map[string]interface{}{}
+)
+
+func setReplicaTestLag(name string, lag interface{}) {
+	replicaTestLock.Lock()
+	defer replicaTestLock.Unlock()
+	replicaTestLags[name] = lag
+}
+
+type replicaTestConn struct{ name string }
+
+type replicaTestRows struct {
+	lag  interface{}
+	done bool
+}
+
+func (replicaTestDriver) Open(name string) (driver.Conn, error) { return replicaTestConn{name}, nil }
+
+func (replicaTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (replicaTestConn) Close() error { return nil }
+
+func (replicaTestConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (c replicaTestConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	if query != "SHOW SLAVE STATUS" {
+		return nil, fmt.Errorf("not supported: %v", query)
+	}
+	replicaTestLock.Lock()
+	defer replicaTestLock.Unlock()
+	lag, ok := replicaTestLags[c.name]
+	if !ok {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &replicaTestRows{lag: lag}, nil
+}
+
+func (r *replicaTestRows) Columns() []string {
+	return []string{"Slave_IO_State", "Master_Host", "Seconds_Behind_Master", "Last_Error"}
+}
+
+func (r *replicaTestRows) Close() error { return nil }
+
+func (r *replicaTestRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0], dest[1], dest[2], dest[3] = []byte("Waiting for master to send event"), []byte("master"), r.lag, []byte("")
+	return nil
+}
+
+func init() {
+	gosql.Register("replicatest", replicaTestDriver{})
+}
+
+func TestReplicaLagThrottler(t *testing.T) {
+	newThrottler := func(names ...string) *replicaLagThrottler {
+		th := &replicaLagThrottler{logger: logrus.NewEntry(logrus.New()), maxLag: 10 * time.Second}
+		for _, name := range names {
+			db, err := gosql.Open("replicatest", name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			th.replicas = append(th.replicas, throttleReplica{addr: name, db: db})
+		}
+		return th
+	}
+
+	setReplicaTestLag("r1", []byte("0"))
+	setReplicaTestLag("r2", []byte("3"))
+	th := newThrottler("r1", "r2", "unreachable")
+	defer th.close()
+	th.check()
+	if got := th.getDelay(); got != 0 {
+		t.Fatalf("delay without lag = %v, want 0", got)
+	}
+
+	// r2 falls behind. The delay grows on each check up to the max.
+	setReplicaTestLag("r2", []byte("30"))
+	var delays []time.Duration
+	for i := 0; i < 10; i++ {
+		th.check()
+		delays = append(delays, th.getDelay())
+	}
+	if delays[0] != replicaThrottleMinDelay || delays[len(delays)-1] != replicaThrottleMaxDelay {
+		t.Fatalf("delays while lagging = %v", delays)
+	}
+	for i := 1; i < len(delays); i++ {
+		if delays[i] < delays[i-1] {
+			t.Fatalf("delays while lagging = %v, want growing", delays)
+		}
+	}
+	start := time.Now()
+	if !th.wait(make(chan struct{})) {
+		t.Fatalf("wait() = false")
+	}
+	if elapsed := time.Since(start); elapsed < replicaThrottleMaxDelay {
+		t.Errorf("wait() took %v, want at least %v", elapsed, replicaThrottleMaxDelay)
+	}
+	// a shutdown ends the wait
+	shutdownCh := make(chan struct{})
+	close(shutdownCh)
+	if th.wait(shutdownCh) {
+		t.Errorf("wait() after shutdown = true")
+	}
+
+	// r2 catches up. The delay eases off, and then there is none.
+	setReplicaTestLag("r2", []byte("5"))
+	th.check()
+	if got := th.getDelay(); got != replicaThrottleMaxDelay/2 {
+		t.Errorf("delay after catching up = %v, want %v", got, replicaThrottleMaxDelay/2)
+	}
+	for i := 0; i < 10; i++ {
+		th.check()
+	}
+	if got := th.getDelay(); got != 0 {
+		t.Errorf("delay after catching up for a while = %v, want 0", got)
+	}
+
+	// a stopped replication counts as lagging
+	setReplicaTestLag("r1", nil)
+	th.check()
+	if got := th.getDelay(); got != replicaThrottleMinDelay {
+		t.Errorf("delay with a stopped replica = %v, want %v", got, replicaThrottleMinDelay)
+	}
+
+	// a nil throttler never waits
+	var none *replicaLagThrottler
+	if !none.wait(shutdownCh) {
+		t.Errorf("wait() of a nil throttler = false")
+	}
+}
@@ -0,0 +1,392 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mitchellh/mapstructure"
+	gomysql "github.com/siddontang/go-mysql/mysql"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// tableResync is the resync of a table, see MySQLDriverConfig.ResyncTable. The table is dumped again in
+// a consistent snapshot while the incremental copy goes on, and sendIncrEntries merges the dump into
+// the binlog entries sent to the destinations:
+//   - the transactions in the snapshot are sent as usual. Before the first one after the snapshot, the table
+//     is truncated on the destinations.
+//   - then the events of the table are taken out of the transactions. Those of the transactions in the snapshot
+//     (still to be read, if the truncation is on the binlog position) are dropped, the others are held back.
+//     The chunks of the dump are sent meanwhile.
+//   - after the last chunk, the held events are sent in order.
+//
+// The held events are kept in memory. The task fails if there are more than resyncMaxHeldEvents of them.
+//
+// So the table gets the rows of the snapshot and then all changes after it, while the other tables are not
+// held back. The parts are sent between the transactions, never among the pieces of a big one.
+// resyncMaxHeldEvents is the most events of the table held back during a resync.
+const resyncMaxHeldEvents = 1000000
+
+type tableResync struct {
+	id string
+	// names on the destination
+	schemaName string
+	tableName  string
+
+	snapshotGtid *gomysql.MysqlGTIDSet
+	snapshotPos  base.BinlogCoordinateTx
+	// entries of the chunks of the dump. Closed after the last one.
+	chunks chan *binlog.BinlogEntry
+
+	truncated bool
+	// for a table added to ReplicateDoDb, which might not exist on the destinations: the part creating it,
+	// sent instead of the truncation. See Extractor.addTable.
+	create *binlog.BinlogEntry
+	held   []binlog.DataEvent
+	// see resyncMaxHeldEvents
+	maxHeld int
+	// the last entry sent is a piece of a big transaction, but not the last one
+	inBigTx bool
+}
+
+func newTableResync(id string, schemaName string, tableName string) *tableResync {
+	return &tableResync{
+		id:         id,
+		schemaName: schemaName,
+		tableName:  tableName,
+		chunks:     make(chan *binlog.BinlogEntry),
+		maxHeld:    resyncMaxHeldEvents,
+	}
+}
+
+func (r *tableResync) newPart() *binlog.BinlogEntry {
+	entry := binlog.NewBinlogEntryAt(base.BinlogCoordinateTx{})
+	entry.Resync = &binlog.ResyncPart{
+		ID:          r.id,
+		TableSchema: r.schemaName,
+		TableName:   r.tableName,
+	}
+	return entry
+}
+
+func (r *tableResync) inSnapshot(entry *binlog.BinlogEntry) bool {
+	uuidSet, ok := r.snapshotGtid.Sets[entry.Coordinates.SID.String()]
+	return ok && base.IntervalSlicesContainOne(uuidSet.Intervals, entry.Coordinates.GNO)
+}
+
+func (r *tableResync) truncate() *binlog.BinlogEntry {
+	r.truncated = true
+	if r.create != nil {
+		return r.create
+	}
+	part := r.newPart()
+	part.Events = append(part.Events, binlog.NewTruncateEvent(r.schemaName, r.schemaName, r.tableName))
+	return part
+}
+
+// process returns the entries to be sent for entry, a transaction or a piece of it read from the binlog.
+// It fails if too many events of the table are held back, see resyncMaxHeldEvents.
+func (r *tableResync) process(entry *binlog.BinlogEntry) ([]*binlog.BinlogEntry, error) {
+	var parts []*binlog.BinlogEntry
+	inSnapshot := r.inSnapshot(entry)
+	if !r.truncated {
+		if inSnapshot && r.create == nil {
+			r.inBigTx = entry.Partial
+			return []*binlog.BinlogEntry{entry}, nil
+		}
+		// An added table is not on the destinations yet. Its events in the snapshot are dropped below.
+		if !inSnapshot {
+			parts = append(parts, r.truncate())
+		}
+	}
+
+	events := make([]binlog.DataEvent, 0, len(entry.Events))
+	for i := range entry.Events {
+		event := &entry.Events[i]
+		if event.DatabaseName != r.schemaName || event.TableName != r.tableName {
+			events = append(events, *event)
+		} else if !inSnapshot {
+			if len(r.held) >= r.maxHeld {
+				return nil, fmt.Errorf("resync of %v.%v: more than %v events of the table are held back until"+
+					" the dump is done. request the resync again when the table is less written to",
+					r.schemaName, r.tableName, r.maxHeld)
+			}
+			r.held = append(r.held, *event)
+		}
+	}
+	entry.Events = events
+	r.inBigTx = entry.Partial
+	return append(parts, entry), nil
+}
+
+// onIdle returns the truncation if the binlog has been read past the snapshot, with nothing after it to send,
+// e.g. while there are no writes to the replicated tables. current is the position of the binlog reader.
+func (r *tableResync) onIdle(current *base.BinlogCoordinateTx) *binlog.BinlogEntry {
+	if r.truncated || r.inBigTx || current.SmallerThan(&r.snapshotPos) {
+		return nil
+	}
+	return r.truncate()
+}
+
+// chunksToSend returns the channel of the chunks when they can be sent, or nil.
+func (r *tableResync) chunksToSend() chan *binlog.BinlogEntry {
+	if !r.truncated || r.inBigTx {
+		return nil
+	}
+	return r.chunks
+}
+
+// done returns the last part, with the held events.
+func (r *tableResync) done() *binlog.BinlogEntry {
+	part := r.newPart()
+	part.Resync.Done = true
+	part.Events = r.held
+	r.held = nil
+	return part
+}
+
+// UpdateConfig implements driver.ConfigUpdater. A new ResyncTable starts the resync of the table.
+// Tables added to ReplicateDoDb are copied and then replicated, and the removed ones are no longer replicated.
+func (e *Extractor) UpdateConfig(m map[string]interface{}) error {
+	var cfg config.MySQLDriverConfig
+	if err := mapstructure.WeakDecode(m, &cfg); err != nil {
+		return err
+	}
+	if _, ok := m["ReplicateDoDb"]; ok {
+		if err := e.updateReplicateDoDb(cfg.ReplicateDoDb); err != nil {
+			return err
+		}
+	}
+	if cfg.ResyncTable != nil && cfg.ResyncTable.ID != e.resyncID {
+		e.resyncID = cfg.ResyncTable.ID
+		return e.startResync(cfg.ResyncTable)
+	}
+	return nil
+}
+
+func (e *Extractor) findReplicatedTable(schemaName string, tableName string) *config.Table {
+	e.replicateDoDbLock.Lock()
+	defer e.replicateDoDbLock.Unlock()
+	for _, db := range e.replicateDoDb {
+		if db.TableSchema != schemaName {
+			continue
+		}
+		for _, tb := range db.Tables {
+			if tb.TableName == tableName {
+				return tb
+			}
+		}
+	}
+	return nil
+}
+
+// checkResync checks the requirements of copying a table while the incremental copy goes on.
+func (e *Extractor) checkResync() error {
+	if !e.mysqlContext.ApproveHeterogeneous {
+		return fmt.Errorf("it requires ApproveHeterogeneous")
+	}
+	if e.isMariaDB() {
+		return fmt.Errorf("it is not supported on MariaDB")
+	}
+	if atomic.LoadInt32(&e.incrStarted) == 0 {
+		return fmt.Errorf("the incremental copy has not started")
+	}
+	var gtidMode string
+	if err := e.db.QueryRow("select @@global.gtid_mode").Scan(&gtidMode); err != nil {
+		return err
+	}
+	if gtidMode != "ON" {
+		return fmt.Errorf("it requires gtid_mode=ON. got %v", gtidMode)
+	}
+	return nil
+}
+
+func (e *Extractor) startResync(req *config.ResyncTable) error {
+	if err := e.checkResync(); err != nil {
+		return fmt.Errorf("resync of %v.%v: %v", req.TableSchema, req.TableName, err)
+	}
+	table := e.findReplicatedTable(req.TableSchema, req.TableName)
+	if table == nil {
+		return fmt.Errorf("resync of %v.%v: the table is not replicated by the job", req.TableSchema, req.TableName)
+	}
+	if !atomic.CompareAndSwapInt32(&e.resyncing, 0, 1) {
+		return fmt.Errorf("resync of %v.%v: the resync of another table is in progress", req.TableSchema, req.TableName)
+	}
+
+	// A copy, as the dumper keeps its position in it.
+	t := *table
+	t.Iteration = 0
+	t.UseUniqueKey = nil
+	go func() {
+		if err := e.resyncTable(req.ID, &t); err != nil {
+			e.logger.Errorf("mysql.extractor: resync of %v.%v failed. err: %v", req.TableSchema, req.TableName, err)
+			e.recordError(models.ErrorTypeResync, "", fmt.Errorf("resync of %v.%v: %v", req.TableSchema, req.TableName, err))
+			atomic.StoreInt32(&e.resyncing, 0)
+		}
+	}()
+	return nil
+}
+
+// resyncTable dumps table in a new snapshot and passes the resync to sendIncrEntries. An error is returned
+// if nothing has been sent. Later errors fail the task, as the table is incomplete on the destinations.
+func (e *Extractor) resyncTable(id string, table *config.Table) error {
+	// The columns and the unique key as of now. The table might have been altered.
+	if err := e.inspector.ValidateOriginalTable(table.TableSchema, table.TableName, table); err != nil {
+		return err
+	}
+	tx, coordinates, err := e.beginConsistentSnapshot(e.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	r, err := newTableResyncAt(id, table, coordinates)
+	if err != nil {
+		return err
+	}
+
+	d := NewDumper(tx, table, e.mysqlContext.ChunkSize, e.logger)
+	if e.adminDB != e.db {
+		d.checksumDB = e.adminDB
+	}
+	d.throttler = e.throttler
+	d.cipher = e.cipher
+	d.chunkSizeBytes = e.mysqlContext.ChunkSizeBytes
+	if err := d.Dump(); err != nil {
+		return err
+	}
+	e.dumpersLock.Lock()
+	e.dumpers = append(e.dumpers, d)
+	e.dumpersLock.Unlock()
+
+	e.logger.Infof("mysql.extractor: resync of %v.%v: dumping the table at %v",
+		table.TableSchema, table.TableName, coordinates.GtidSet)
+	if !e.registerResync(r) {
+		return nil
+	}
+	e.sendResyncChunks(r, d)
+	return nil
+}
+
+// newTableResyncAt returns the resync of table, dumped in the snapshot of coordinates.
+func newTableResyncAt(id string, table *config.Table, coordinates *base.BinlogCoordinatesX) (*tableResync, error) {
+	snapshotGtid, err := base.ParseGtidSet(coordinates.GtidSet)
+	if err != nil {
+		return nil, err
+	}
+	schemaName, tableName := table.TableSchema, table.TableName
+	if table.TableSchemaRename != "" {
+		schemaName = table.TableSchemaRename
+	}
+	if table.TableRename != "" {
+		tableName = table.TableRename
+	}
+	r := newTableResync(id, schemaName, tableName)
+	r.snapshotGtid = snapshotGtid
+	r.snapshotPos = base.BinlogCoordinateTx{LogFile: coordinates.LogFile, LogPos: coordinates.LogPos}
+	return r, nil
+}
+
+// registerResync passes r to sendIncrEntries. It returns false on shutdown.
+func (e *Extractor) registerResync(r *tableResync) bool {
+	select {
+	case e.resyncCh <- r:
+		return true
+	case <-e.shutdownCh:
+		return false
+	}
+}
+
+// resyncPart returns a part of r carrying entry, a chunk of the dump.
+func (e *Extractor) resyncPart(r *tableResync, entry *DumpEntry) (*binlog.BinlogEntry, error) {
+	bs, err := entry.Marshal(nil)
+	if err != nil {
+		return nil, err
+	}
+	part := r.newPart()
+	part.Resync.Chunk, err = e.compressDump(bs)
+	if err != nil {
+		return nil, err
+	}
+	part.OriginalSize = len(part.Resync.Chunk)
+	return part, nil
+}
+
+// sendResyncChunks passes the chunks of d to sendIncrEntries, which sends them when the table has been
+// truncated. Errors fail the task.
+func (e *Extractor) sendResyncChunks(r *tableResync, d *dumper) {
+	for entry := range d.resultsChannel {
+		if entry.Err != "" {
+			err := fmt.Errorf(entry.Err)
+			e.onError(TaskStateDead, err)
+			return
+		}
+		part, err := e.resyncPart(r, entry)
+		if err != nil {
+			e.onError(TaskStateDead, err)
+			return
+		}
+		select {
+		case r.chunks <- part:
+		case <-e.shutdownCh:
+			return
+		}
+		d.onRowsCopied(entry.RowsCount)
+	}
+	d.finishProgress()
+	close(r.chunks)
+	e.onTableResynced(d.TableSchema, d.TableName)
+}
+
+// applyResyncPart applies a part of the resync of a table after all the previous transactions, and before
+// the next ones. It is not recorded as an executed transaction.
+func (a *Applier) applyResyncPart(ctx context.Context, serialBatch *applyBatch, entry *binlog.BinlogEntry) error {
+	part := entry.Resync
+	// It takes a place in the in-flight window, but is not counted as a transaction.
+	defer a.publishIncrAck(1)
+	if !config.MatchDestDoDb(a.mysqlContext.DestDoDb, part.TableSchema, part.TableName) {
+		return nil
+	}
+	if err := a.commitApplyBatch(0, serialBatch); err != nil {
+		return err
+	}
+	if !a.mtsManager.WaitForAllCommitted() {
+		return nil // shutdown
+	}
+
+	if part.Chunk != nil {
+		dumpEntry, err := DecodeDumpEntry(part.Chunk)
+		if err != nil {
+			return err
+		}
+		return a.ApplyEventQueries(a.db, dumpEntry)
+	}
+	batch := &applyBatch{}
+	if err := a.applySerially(ctx, batch, entry); err != nil {
+		return err
+	}
+	if batch.isPending() {
+		err := batch.tx.Commit()
+		batch.reset()
+		a.dbs[0].DbMutex.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	if part.Done {
+		a.logger.Infof("mysql.applier: resync of %v.%v: done. applied %v events held back",
+			part.TableSchema, part.TableName, len(entry.Events))
+	} else {
+		a.logger.Infof("mysql.applier: resync of %v.%v: truncated the table", part.TableSchema, part.TableName)
+	}
+	return nil
+}
@@ -0,0 +1,552 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	gonats "github.com/nats-io/go-nats"
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+// resyncTestTables are the rows (id to value) of the tables of the resync tests, by table name.
+// The table "t" is resynced, and "o" is another table of the job.
+type resyncTestTables map[string]map[int64]int64
+
+func newResyncTestTables() resyncTestTables {
+	return resyncTestTables{"t": {}, "o": {}}
+}
+
+func (tables resyncTestTables) copy() resyncTestTables {
+	c := newResyncTestTables()
+	for name, rows := range tables {
+		for id, v := range rows {
+			c[name][id] = v
+		}
+	}
+	return c
+}
+
+func (tables resyncTestTables) applyEvents(events []binlog.DataEvent) {
+	for _, event := range events {
+		rows := tables[event.TableName]
+		switch event.DML {
+		case binlog.NotDML:
+			if event.Truncate {
+				for id := range rows {
+					delete(rows, id)
+				}
+			}
+		case binlog.DeleteDML:
+			delete(rows, (*event.WhereColumnValues.AbstractValues[0]).(int64))
+		default:
+			values := event.NewColumnValues.AbstractValues
+			rows[(*values[0]).(int64)] = (*values[1]).(int64)
+		}
+	}
+}
+
+// applyEntry applies an entry as a destination does.
+func (tables resyncTestTables) applyEntry(t *testing.T, entry *binlog.BinlogEntry) {
+	if entry.Resync == nil || entry.Resync.Chunk == nil {
+		tables.applyEvents(entry.Events)
+		return
+	}
+	dumpEntry, err := DecodeDumpEntry(entry.Resync.Chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range dumpEntry.ValuesX {
+		id, _ := strconv.ParseInt(string(*row[0]), 10, 64)
+		v, _ := strconv.ParseInt(string(*row[1]), 10, 64)
+		tables[dumpEntry.TableName][id] = v
+	}
+}
+
+func resyncTestValues(id int64, v int64) *umconf.ColumnValues {
+	var idValue, vValue interface{} = id, v
+	return &umconf.ColumnValues{AbstractValues: []*interface{}{&idValue, &vValue}}
+}
+
+// resyncTestSource is the transactions of a source writing to both tables. Transaction i has GNO i.
+type resyncTestSource struct {
+	sid    uuid.UUID
+	events [][]binlog.DataEvent
+	// the tables after transaction i
+	states []resyncTestTables
+}
+
+func newResyncTestSource(nTx int) *resyncTestSource {
+	src := &resyncTestSource{
+		sid:    uuid.NewV4(),
+		events: make([][]binlog.DataEvent, nTx+1),
+		states: []resyncTestTables{newResyncTestTables()},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 1; i <= nTx; i++ {
+		for j := 0; j < 1+rnd.Intn(3); j++ {
+			table := []string{"t", "o"}[rnd.Intn(2)]
+			dml := []binlog.EventDML{binlog.InsertDML, binlog.UpdateDML, binlog.DeleteDML}[rnd.Intn(3)]
+			event := binlog.NewDataEvent("db", table, dml, 2)
+			values := resyncTestValues(int64(1+rnd.Intn(20)), int64(i))
+			if dml != binlog.InsertDML {
+				event.WhereColumnValues = values
+			}
+			if dml != binlog.DeleteDML {
+				event.NewColumnValues = values
+			}
+			src.events[i] = append(src.events[i], event)
+		}
+		state := src.states[i-1].copy()
+		state.applyEvents(src.events[i])
+		src.states = append(src.states, state)
+	}
+	return src
+}
+
+func (src *resyncTestSource) entry(i int) *binlog.BinlogEntry {
+	entry := binlog.NewBinlogEntryAt(base.BinlogCoordinateTx{
+		LogFile: "mysql-bin.000001",
+		LogPos:  int64(i * 100),
+		SID:     src.sid,
+		GNO:     int64(i),
+	})
+	entry.Events = append(entry.Events, src.events[i]...)
+	return entry
+}
+
+// newResync returns the resync of table "t" dumped after transaction snapshot, with its chunks.
+func (src *resyncTestSource) newResync(t *testing.T, snapshot int, chunkSize int) (*tableResync, []*binlog.BinlogEntry) {
+	r := newTableResync("resync-1", "db", "t")
+	var err error
+	if r.snapshotGtid, err = base.ParseGtidSet(fmt.Sprintf("%v:1-%v", src.sid, snapshot)); err != nil {
+		t.Fatal(err)
+	}
+	r.snapshotPos = base.BinlogCoordinateTx{LogFile: "mysql-bin.000001", LogPos: int64(snapshot * 100)}
+
+	rows := src.states[snapshot]["t"]
+	var ids []int64
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var chunks []*binlog.BinlogEntry
+	for len(ids) > 0 {
+		n := chunkSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+		dumpEntry := &DumpEntry{TableSchema: "db", TableName: "t", RowsCount: int64(n)}
+		for _, id := range ids[:n] {
+			idBytes := []byte(strconv.FormatInt(id, 10))
+			vBytes := []byte(strconv.FormatInt(rows[id], 10))
+			dumpEntry.ValuesX = append(dumpEntry.ValuesX, []*[]byte{&idBytes, &vBytes})
+		}
+		ids = ids[n:]
+		bs, err := dumpEntry.Marshal(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part := r.newPart()
+		part.Resync.Chunk = snappy.Encode(nil, bs)
+		chunks = append(chunks, part)
+	}
+	return r, chunks
+}
+
+func processResync(t *testing.T, r *tableResync, entry *binlog.BinlogEntry) []*binlog.BinlogEntry {
+	t.Helper()
+	parts, err := r.process(entry)
+	if err != nil {
+		t.Fatalf("process() error = %v", err)
+	}
+	return parts
+}
+
+// The table is resynced while the source goes on writing to it and to another table. The destination
+// starts with a broken copy of the table and ends with the same rows as the source.
+func TestTableResync(t *testing.T) {
+	const nTx = 200
+	src := newResyncTestSource(nTx)
+	tests := []struct {
+		name string
+		// transactions sent before the resync
+		started int
+		// transactions in the snapshot
+		snapshot int
+		// transactions read between two chunks
+		txPerChunk int
+	}{
+		{"snapshot ahead of the reader", 20, 60, 3},
+		{"snapshot at the reader", 60, 60, 1},
+		{"chunks after all transactions", 60, 80, 1000},
+		{"no transaction after the snapshot", 100, nTx, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := newResyncTestTables()
+			for i := 1; i <= tt.started; i++ {
+				dest.applyEntry(t, src.entry(i))
+			}
+			dest["t"][999] = 1
+			delete(dest["t"], 1)
+
+			r, chunks := src.newResync(t, tt.snapshot, 3)
+			r.chunks = make(chan *binlog.BinlogEntry, len(chunks))
+			for _, chunk := range chunks {
+				r.chunks <- chunk
+			}
+			close(r.chunks)
+
+			var sent []*binlog.BinlogEntry
+			takeChunk := func() bool {
+				ch := r.chunksToSend()
+				if ch == nil {
+					return false
+				}
+				part, ok := <-ch
+				if !ok {
+					sent = append(sent, r.done())
+					r = nil
+					return false
+				}
+				sent = append(sent, part)
+				return true
+			}
+			for i := tt.started + 1; i <= nTx; i++ {
+				if r == nil {
+					sent = append(sent, src.entry(i))
+					continue
+				}
+				sent = append(sent, processResync(t, r, src.entry(i))...)
+				if i%tt.txPerChunk == 0 {
+					takeChunk()
+				}
+			}
+			if r != nil {
+				current := &base.BinlogCoordinateTx{LogFile: "mysql-bin.000001", LogPos: nTx * 100}
+				if part := r.onIdle(current); part != nil {
+					sent = append(sent, part)
+				}
+				for takeChunk() {
+				}
+			}
+			if r != nil {
+				t.Fatalf("the resync is not done")
+			}
+
+			nTruncate := 0
+			for _, entry := range sent {
+				if entry.Resync != nil && len(entry.Events) == 1 && entry.Events[0].Truncate {
+					nTruncate++
+				}
+				dest.applyEntry(t, entry)
+			}
+			if nTruncate != 1 {
+				t.Errorf("sent %v truncations, want 1", nTruncate)
+			}
+			if !reflect.DeepEqual(dest, src.states[nTx]) {
+				t.Errorf("destination: %v\nwant: %v", dest, src.states[nTx])
+			}
+		})
+	}
+}
+
+// A table added to ReplicateDoDb is replicated from the snapshot on. Before, the binlog reader filters it out,
+// and the destination does not have it until the resync creates it.
+func TestTableResync_addedTable(t *testing.T) {
+	const nTx = 100
+	src := newResyncTestSource(nTx)
+	for _, snapshot := range []int{20, 40} {
+		const started = 20
+		dest := resyncTestTables{"o": {}}
+		for i := 1; i <= started; i++ {
+			entry := src.entry(i)
+			var events []binlog.DataEvent
+			for _, event := range entry.Events {
+				if event.TableName != "t" {
+					events = append(events, event)
+				}
+			}
+			entry.Events = events
+			dest.applyEntry(t, entry)
+		}
+
+		r, chunks := src.newResync(t, snapshot, 3)
+		create := r.newPart()
+		r.create = create
+		r.chunks = make(chan *binlog.BinlogEntry, len(chunks))
+		for _, chunk := range chunks {
+			r.chunks <- chunk
+		}
+		close(r.chunks)
+
+		var sent []*binlog.BinlogEntry
+		for i := started + 1; i <= nTx; i++ {
+			if r == nil {
+				sent = append(sent, src.entry(i))
+				continue
+			}
+			sent = append(sent, processResync(t, r, src.entry(i))...)
+			if ch := r.chunksToSend(); ch != nil && i%2 == 0 {
+				if part, ok := <-ch; ok {
+					sent = append(sent, part)
+				} else {
+					sent = append(sent, r.done())
+					r = nil
+				}
+			}
+		}
+		if r != nil {
+			t.Fatalf("snapshot %v: the resync is not done", snapshot)
+		}
+
+		for _, entry := range sent {
+			if entry == create {
+				dest["t"] = map[int64]int64{}
+				continue
+			}
+			if dest["t"] == nil {
+				for _, event := range entry.Events {
+					if event.TableName == "t" {
+						t.Fatalf("snapshot %v: an event of the table is sent before it is created", snapshot)
+					}
+				}
+			}
+			dest.applyEntry(t, entry)
+		}
+		if !reflect.DeepEqual(dest, src.states[nTx]) {
+			t.Errorf("snapshot %v: destination: %v\nwant: %v", snapshot, dest, src.states[nTx])
+		}
+	}
+}
+
+// The parts of a resync are not sent among the pieces of a big transaction.
+func TestTableResync_bigTx(t *testing.T) {
+	src := newResyncTestSource(10)
+	r, chunks := src.newResync(t, 5, 100)
+	r.chunks = make(chan *binlog.BinlogEntry, len(chunks))
+
+	piece := src.entry(6)
+	piece.Partial = true
+	parts := processResync(t, r, piece)
+	if len(parts) != 2 || parts[0].Resync == nil || !parts[0].Events[0].Truncate {
+		t.Fatalf("process() of the first piece after the snapshot = %v, want the truncation and the piece", parts)
+	}
+	if r.chunksToSend() != nil {
+		t.Errorf("chunksToSend() among the pieces != nil")
+	}
+	last := src.entry(6)
+	last.Index = 1
+	if parts := processResync(t, r, last); len(parts) != 1 {
+		t.Errorf("process() of the last piece returned %v entries, want 1", len(parts))
+	}
+	if r.chunksToSend() == nil {
+		t.Errorf("chunksToSend() after the big transaction = nil")
+	}
+
+	r, _ = src.newResync(t, 5, 100)
+	piece = src.entry(5)
+	piece.Partial = true
+	processResync(t, r, piece)
+	current := &base.BinlogCoordinateTx{LogFile: "mysql-bin.000001", LogPos: 1000}
+	if part := r.onIdle(current); part != nil {
+		t.Errorf("onIdle() among the pieces = %v, want nil", part)
+	}
+	current.LogPos = 400
+	r.inBigTx = false
+	if part := r.onIdle(current); part != nil {
+		t.Errorf("onIdle() before the snapshot position = %v, want nil", part)
+	}
+}
+
+// The resync fails if too many events of the table are held back.
+func TestTableResync_maxHeld(t *testing.T) {
+	const nTx = 100
+	src := newResyncTestSource(nTx)
+	r, _ := src.newResync(t, 10, 100)
+	r.maxHeld = 20
+	for i := 11; i <= nTx; i++ {
+		if _, err := r.process(src.entry(i)); err != nil {
+			if len(r.held) != r.maxHeld {
+				t.Errorf("process() error with %v events held, want %v", len(r.held), r.maxHeld)
+			}
+			return
+		}
+	}
+	t.Errorf("process() of %v events held expects an error", len(r.held))
+}
+
+// Transactions are read and sent while the resync is passed to sendIncrEntries and the table is dumped.
+func TestExtractor_sendIncrEntries_resync(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+
+	const nTx = 300
+	const started = 40
+	src := newResyncTestSource(nTx)
+	// The group is sent on each entry. The group timeout, which might truncate on the binlog position, is not reached.
+	cfg := (&config.MySQLDriverConfig{GroupMaxSize: 1, ReplChanBufferSize: 2, GroupTimeout: 3600 * 1000}).SetDefault()
+	e := &Extractor{
+		logger:       logrus.NewEntry(logrus.New()),
+		subject:      "job",
+		mysqlContext: cfg,
+		dataChannel:  make(chan *binlog.BinlogEntry, cfg.ReplChanBufferSize),
+		waitCh:       make(chan *models.WaitResult, 1),
+		shutdownCh:   make(chan struct{}),
+		resyncCh:     make(chan *tableResync),
+	}
+	var err error
+	if e.natsConn, err = gonats.Connect("nats://" + s.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.natsConn.Close()
+
+	// the destination
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	var destLock sync.Mutex
+	dest := newResyncTestTables()
+	dest["t"][999] = 1
+	done := false
+	if _, err := nc.Subscribe("job_incr_hete", func(m *gonats.Msg) {
+		var entries binlog.BinlogEntries
+		if err := Decode(m.Data, &entries); err != nil {
+			t.Error(err)
+		}
+		destLock.Lock()
+		for _, entry := range entries.Entries {
+			dest.applyEntry(t, entry)
+			if entry.Resync != nil && entry.Resync.Done {
+				done = true
+			}
+		}
+		destLock.Unlock()
+		nc.Publish(m.Reply, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nc.Flush()
+
+	go e.sendIncrEntries()
+
+	// the binlog reader, and the resync dumped at a snapshot ahead of it
+	r, chunks := src.newResync(t, started+20, 2)
+	go func() {
+		for i := 1; i <= nTx; i++ {
+			e.dataChannel <- src.entry(i)
+			if i == started {
+				e.resyncCh <- r
+				go func() {
+					for _, chunk := range chunks {
+						r.chunks <- chunk
+						time.Sleep(3 * time.Millisecond)
+					}
+					close(r.chunks)
+				}()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	deadline := time.Now().Add(20 * time.Second)
+	for {
+		destLock.Lock()
+		ok := done && reflect.DeepEqual(dest, src.states[nTx])
+		destLock.Unlock()
+		// all sent and replied to: the transactions, the resync and its parts
+		if ok && atomic.LoadInt64(&e.mysqlContext.DeltaEstimate) == int64(nTx+1+len(chunks)+1) {
+			break
+		}
+		if time.Now().After(deadline) {
+			destLock.Lock()
+			t.Fatalf("done: %v. destination: %v\nwant: %v", done, dest, src.states[nTx])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestApplier_applyResyncPart(t *testing.T) {
+	skipWithoutMySQL(t)
+	cfg := &config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{
+			Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+	}
+	a, err := NewApplier(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg, logrus.New())
+	if err != nil {
+		t.Fatalf("NewApplier() error = %v", err)
+	}
+	if err := a.initDBConnections(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	if _, err := a.db.Exec("create database if not exists dtle_test; drop table if exists dtle_test.t_resync;" +
+		"create table dtle_test.t_resync (id int primary key, v int); insert into dtle_test.t_resync values (1, 1), (999, 1)"); err != nil {
+		t.Fatalf("prepare error = %v", err)
+	}
+
+	r := newTableResync("resync-1", "dtle_test", "t_resync")
+	chunk := r.newPart()
+	dumpEntry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_resync", RowsCount: 2}
+	for _, row := range [][]string{{"1", "10"}, {"2", "20"}} {
+		id, v := []byte(row[0]), []byte(row[1])
+		dumpEntry.ValuesX = append(dumpEntry.ValuesX, []*[]byte{&id, &v})
+	}
+	bs, err := dumpEntry.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk.Resync.Chunk = snappy.Encode(nil, bs)
+	update := binlog.NewDataEvent("dtle_test", "t_resync", binlog.UpdateDML, 2)
+	update.WhereColumnValues = resyncTestValues(2, 20)
+	update.NewColumnValues = resyncTestValues(2, 21)
+	insert := binlog.NewDataEvent("dtle_test", "t_resync", binlog.InsertDML, 2)
+	insert.NewColumnValues = resyncTestValues(3, 30)
+	r.held = []binlog.DataEvent{update, insert}
+
+	for _, part := range []*binlog.BinlogEntry{r.truncate(), chunk, r.done()} {
+		if err := a.applyResyncPart(nil, a.applyBatches[0], part); err != nil {
+			t.Fatalf("applyResyncPart() error = %v", err)
+		}
+	}
+
+	rows, err := a.db.Query("select id, v from dtle_test.t_resync order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got [][2]int
+	for rows.Next() {
+		var row [2]int
+		if err := rows.Scan(&row[0], &row[1]); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+	if want := [][2]int{{1, 10}, {2, 21}, {3, 30}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func newSampleTestTable(rate float64) *config.Table {
+	table := config.NewTable("db1", "tb1")
+	table.SampleRate = rate
+	table.OriginalTableColumns = umconf.NewColumnList([]umconf.Column{
+		{RawName: "id", Key: "PRI", Type: umconf.IntColumnType},
+		{RawName: "name", Type: umconf.VarcharColumnType},
+	})
+	return table
+}
+
+func TestSampleKeyIndexes(t *testing.T) {
+	table := newSampleTestTable(0.1)
+	if got, err := config.SampleKeyIndexes(table); err != nil || !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("SampleKeyIndexes() = %v, %v", got, err)
+	}
+	for _, rate := range []float64{0, 1} {
+		if got, err := config.SampleKeyIndexes(newSampleTestTable(rate)); err != nil || got != nil {
+			t.Errorf("SampleKeyIndexes() of SampleRate %v = %v, %v, want all rows", rate, got, err)
+		}
+	}
+	table.OriginalTableColumns.Columns[0].Type = umconf.DecimalColumnType
+	if _, err := config.SampleKeyIndexes(table); err == nil {
+		t.Errorf("SampleKeyIndexes() of a DECIMAL key: want an error")
+	}
+	table.OriginalTableColumns.Columns[0].Key = ""
+	if _, err := config.SampleKeyIndexes(table); err == nil {
+		t.Errorf("SampleKeyIndexes() without a primary key: want an error")
+	}
+}
+
+// The destination of a sampled table is built by the full copy and the changes replicated after it.
+// It must have exactly the source rows in the sample, and each replicated update or delete must find its row.
+func TestSampleRate_consistency(t *testing.T) {
+	const rate = 0.3
+	table := newSampleTestTable(rate)
+	source := make(map[int32]string)
+	dest := make(map[int32]string)
+
+	// full copy, read as text
+	d := NewDumper(nil, table, 1000, logrus.NewEntry(logrus.New()))
+	if err := d.prepareForDumping(); err != nil {
+		t.Fatal(err)
+	}
+	entry := &DumpEntry{TableSchema: "db1", TableName: "tb1"}
+	for id := int32(1); id <= 1000; id++ {
+		source[id] = fmt.Sprintf("name%v", id)
+		idText, name := []byte(strconv.Itoa(int(id))), []byte(source[id])
+		entry.ValuesX = append(entry.ValuesX, []*[]byte{&idText, &name})
+		entry.RowsCount++
+	}
+	d.sampleRows(entry)
+	for _, row := range entry.ValuesX {
+		id, err := strconv.Atoi(string(*row[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest[int32(id)] = string(*row[1])
+	}
+	if n := len(dest); n < 250 || n > 350 {
+		t.Errorf("the full copy sampled %v of 1000 rows, want about %v", n, 1000*rate)
+	}
+
+	// incremental, with the values as decoded from the binlog
+	tableCtx := config.NewTableContext(table, nil)
+	var err error
+	if tableCtx.SampleKey, err = config.SampleKeyIndexes(table); err != nil {
+		t.Fatal(err)
+	}
+	row := func(id int32, name string) *umconf.ColumnValues {
+		values := []interface{}{id, name}
+		return &umconf.ColumnValues{AbstractValues: []*interface{}{&values[0], &values[1]}}
+	}
+	apply := func(dml binlog.EventDML, where *umconf.ColumnValues, newValues *umconf.ColumnValues) {
+		event := binlog.DataEvent{WhereColumnValues: where, NewColumnValues: newValues}
+		if !binlog.SampleRowChange(tableCtx, &event, dml) {
+			return
+		}
+		if event.WhereColumnValues != nil {
+			id := (*event.WhereColumnValues.AbstractValues[0]).(int32)
+			if _, ok := dest[id]; !ok {
+				t.Errorf("%v of row %v, which is not on the destination", event.DML, id)
+			}
+			delete(dest, id)
+		}
+		if event.NewColumnValues != nil {
+			id := (*event.NewColumnValues.AbstractValues[0]).(int32)
+			if _, ok := dest[id]; ok && event.DML == binlog.InsertDML {
+				t.Errorf("insert of row %v, which is on the destination", id)
+			}
+			dest[id] = (*event.NewColumnValues.AbstractValues[1]).(string)
+		}
+	}
+	for id := int32(1001); id <= 1500; id++ {
+		source[id] = fmt.Sprintf("name%v", id)
+		apply(binlog.InsertDML, nil, row(id, source[id]))
+	}
+	for id := int32(1); id <= 1500; id += 2 {
+		name := source[id] + "-updated"
+		apply(binlog.UpdateDML, row(id, source[id]), row(id, name))
+		source[id] = name
+	}
+	// a change of the key moves the row into or out of the sample
+	for id := int32(2); id <= 1500; id += 10 {
+		apply(binlog.UpdateDML, row(id, source[id]), row(id+10000, source[id]))
+		source[id+10000] = source[id]
+		delete(source, id)
+	}
+	for id := int32(4); id <= 1500; id += 4 {
+		if name, ok := source[id]; ok {
+			apply(binlog.DeleteDML, row(id, name), nil)
+			delete(source, id)
+		}
+	}
+
+	want := make(map[int32]string)
+	for id, name := range source {
+		if in, _ := tableCtx.SampledIn(row(id, name)); in {
+			want[id] = name
+		}
+	}
+	if !reflect.DeepEqual(dest, want) {
+		t.Errorf("the destination has %v rows, want the %v sampled rows of %v", len(dest), len(want), len(source))
+	}
+}
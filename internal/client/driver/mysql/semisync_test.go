@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	sqle "github.com/actiontech/dtle/internal/client/driver/mysql/sqle/inspector"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// With SemiSync, a commit on the source waits until the transaction is applied by the destinations.
+func TestBinlogReader_semiSync(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	var semiSyncEnabled, semiSyncTimeout, gtidExecuted string
+	if err := db.QueryRow("select @@global.rpl_semi_sync_master_enabled, @@global.rpl_semi_sync_master_timeout,"+
+		" @@global.gtid_executed").Scan(&semiSyncEnabled, &semiSyncTimeout, &gtidExecuted); err != nil {
+		t.Skipf("no semisync on the source: %v", err)
+	}
+	if timeout, _ := strconv.Atoi(semiSyncTimeout); semiSyncEnabled != "1" || timeout < 10000 {
+		t.Skipf("rpl_semi_sync_master_enabled=%v rpl_semi_sync_master_timeout=%v", semiSyncEnabled, semiSyncTimeout)
+	}
+	semiSyncClients := func() int {
+		var name string
+		var n int
+		if err := db.QueryRow("show global status like 'Rpl_semi_sync_master_clients'").Scan(&name, &n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := semiSyncClients(); n > 0 {
+		t.Skipf("%v other semisync replicas on the source", n)
+	}
+	if _, err := db.Exec("create database if not exists dtle_test;" +
+		"create table if not exists dtle_test.t_semisync (id int primary key auto_increment)"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := (&config.MySQLDriverConfig{
+		ConnectionConfig: &umconf.ConnectionConfig{Host: "192.168.99.100", Port: 13307, User: "root", Password: "rootroot"},
+		HeartbeatPeriod:  1,
+		SemiSync:         true,
+	}).SetDefault()
+	r, err := binlog.NewMySQLReader(&common.ExecContext{Subject: uuid.NewV4().String()}, cfg,
+		logrus.NewEntry(logrus.New()), nil, sqle.NewContext(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	tracker := binlog.NewSemiSyncTracker(1)
+	r.SetSemiSync(tracker)
+	if err := r.ConnectBinlogStreamer(base.BinlogCoordinatesX{GtidSet: gtidExecuted}); err != nil {
+		t.Fatal(err)
+	}
+	entries := make(chan *binlog.BinlogEntry, 100)
+	go r.DataStreamEvents(entries)
+	for i := 0; i < 50 && semiSyncClients() == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if n := semiSyncClients(); n != 1 {
+		t.Fatalf("Rpl_semi_sync_master_clients = %v, want 1", n)
+	}
+
+	committed := make(chan error, 1)
+	go func() {
+		_, err := db.Exec("insert into dtle_test.t_semisync values (null)")
+		committed <- err
+	}()
+	// the transaction is read, but not applied yet
+	forwarded := 0
+	for found := false; !found; {
+		select {
+		case entry := <-entries:
+			forwarded++
+			for _, event := range entry.Events {
+				found = found || event.TableName == "t_semisync"
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the insert is not read")
+		}
+	}
+	select {
+	case err := <-committed:
+		t.Fatalf("the insert is committed before it is applied. err: %v", err)
+	case <-time.After(2 * time.Second):
+	}
+
+	tracker.Sent(forwarded, forwarded)
+	tracker.Applied("Dest", int64(forwarded))
+	select {
+	case err := <-committed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the insert is not committed after it is applied")
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+)
+
+// incrSequencer puts the messages of the incremental copy back in the order they are sent by the extractor
+// (see BinlogEntries.Seq), so that the transactions, and with them the checkpoint, only go forward. A message
+// received ahead of a missing one is held until the gap is filled, and one received again after a timeout
+// of the extractor is dropped. It is not safe for concurrent use: the messages are handled one by one.
+type incrSequencer struct {
+	// the max number of messages held. See MySQLDriverConfig.ReorderWindow.
+	window int
+	stream string
+	// the Seq of the next message to be applied
+	next    int64
+	pending map[int64]*binlog.BinlogEntries
+	// the streams of the previous runs of the extractor, whose late messages are dropped. The new run
+	// resends what is not applied, from the checkpoint on.
+	oldStreams map[string]bool
+	// the messages which are not numbered, in the order received
+	unordered []*binlog.BinlogEntries
+}
+
+func newIncrSequencer(window int) *incrSequencer {
+	return &incrSequencer{
+		window:     window,
+		pending:    make(map[int64]*binlog.BinlogEntries),
+		oldStreams: make(map[string]bool),
+	}
+}
+
+// add holds a received message until it is its turn. An error is returned if the window is exceeded, i.e.
+// a message has not been received while the next ones have.
+func (s *incrSequencer) add(entries *binlog.BinlogEntries) error {
+	if entries.Seq == 0 {
+		s.unordered = append(s.unordered, entries)
+		return nil
+	}
+	if entries.Stream != s.stream {
+		if s.oldStreams[entries.Stream] {
+			return nil
+		}
+		if s.stream != "" {
+			s.oldStreams[s.stream] = true
+		}
+		// The messages up to AckedSeq have been received, e.g. by this task before a restart.
+		s.stream = entries.Stream
+		s.next = entries.AckedSeq + 1
+		s.pending = make(map[int64]*binlog.BinlogEntries)
+	}
+	if entries.Seq < s.next {
+		return nil // resent
+	}
+	if entries.Seq >= s.next+int64(s.window) {
+		return fmt.Errorf("the incremental copy is out of order: message %v is not received, while message %v is."+
+			" the gap is not filled within ReorderWindow %v", s.next, entries.Seq, s.window)
+	}
+	s.pending[entries.Seq] = entries
+	return nil
+}
+
+// peek returns the next message to be applied, or nil if it is not received yet.
+func (s *incrSequencer) peek() *binlog.BinlogEntries {
+	if len(s.unordered) > 0 {
+		return s.unordered[0]
+	}
+	return s.pending[s.next]
+}
+
+// pop removes the message returned by peek, when it has been taken.
+func (s *incrSequencer) pop() {
+	if len(s.unordered) > 0 {
+		s.unordered = s.unordered[1:]
+		return
+	}
+	if _, ok := s.pending[s.next]; ok {
+		delete(s.pending, s.next)
+		s.next++
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gonats "github.com/nats-io/go-nats"
+	"github.com/satori/go.uuid"
+
+	"github.com/actiontech/dtle/internal/client/driver/common"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func TestIncrSequencer(t *testing.T) {
+	msg := func(stream string, seq int64, ackedSeq int64) *binlog.BinlogEntries {
+		return &binlog.BinlogEntries{Stream: stream, Seq: seq, AckedSeq: ackedSeq}
+	}
+	tests := []struct {
+		name     string
+		received []*binlog.BinlogEntries
+		// the Seq of the messages taken, in order
+		want    []int64
+		wantErr string
+	}{
+		{"in order", []*binlog.BinlogEntries{msg("a", 1, 0), msg("a", 2, 1), msg("a", 3, 2)}, []int64{1, 2, 3}, ""},
+		{"out of order", []*binlog.BinlogEntries{msg("a", 2, 0), msg("a", 3, 0), msg("a", 1, 0), msg("a", 5, 3), msg("a", 4, 3)},
+			[]int64{1, 2, 3, 4, 5}, ""},
+		{"resent", []*binlog.BinlogEntries{msg("a", 1, 0), msg("a", 1, 0), msg("a", 3, 1), msg("a", 2, 1), msg("a", 3, 1)},
+			[]int64{1, 2, 3}, ""},
+		{"restarted destination", []*binlog.BinlogEntries{msg("a", 8, 6), msg("a", 7, 6), msg("a", 6, 5)},
+			[]int64{7, 8}, ""},
+		{"restarted source", []*binlog.BinlogEntries{msg("a", 1, 0), msg("a", 2, 1), msg("b", 2, 0), msg("a", 3, 2),
+			msg("b", 1, 0)}, []int64{1, 2, 1, 2}, ""},
+		{"not numbered", []*binlog.BinlogEntries{msg("", 0, 0), msg("a", 2, 0), msg("", 0, 0)}, []int64{0, 0}, ""},
+		{"gap", []*binlog.BinlogEntries{msg("a", 1, 0), msg("a", 3, 1), msg("a", 4, 1), msg("a", 5, 1), msg("a", 6, 1)},
+			[]int64{1}, "message 2 is not received, while message 6 is"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIncrSequencer(4)
+			var got []int64
+			var err error
+			for _, m := range tt.received {
+				if err = s.add(m); err != nil {
+					break
+				}
+				for next := s.peek(); next != nil; next = s.peek() {
+					got = append(got, next.Seq)
+					s.pop()
+				}
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("add() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("add() error = %v, want %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taken = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The transactions delivered out of order are applied in order, and the checkpoint only goes forward.
+func TestApplier_handleIncrEntries_outOfOrder(t *testing.T) {
+	s := runTestNatsServer(t)
+	defer s.Shutdown()
+	a := newTestNatsApplier(t, s.Addr().String(), &common.ExecContext{})
+	defer a.Shutdown()
+	a.applyDataEntryQueue = make(chan *binlog.BinlogEntry, 10)
+	a.currentCoordinates = &models.CurrentCoordinates{}
+	a.incrSequencer = newIncrSequencer(4)
+	if err := a.subscribeData("job_incr_hete", func(m *gonats.Msg) {
+		var entries binlog.BinlogEntries
+		if err := Decode(m.Data, &entries); err != nil {
+			t.Error(err)
+			return
+		}
+		a.handleIncrEntries(m, &entries, nil)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	nc, err := gonats.Connect("nats://" + s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	sid := uuid.NewV4()
+	send := func(seq int64, ackedSeq int64) {
+		entries := binlog.BinlogEntries{Stream: "s1", Seq: seq, AckedSeq: ackedSeq, Entries: []*binlog.BinlogEntry{{
+			Coordinates: base.BinlogCoordinateTx{SID: sid, GNO: seq * 10},
+		}}}
+		bs, err := Encode(entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := nc.Request("job_incr_hete", bs, 5*time.Second); err != nil {
+			t.Fatalf("message %v is not acknowledged: %v", seq, err)
+		}
+	}
+	checkpoints := func() (gnos []int64) {
+		for {
+			select {
+			case entry := <-a.applyDataEntryQueue:
+				gnos = append(gnos, entry.Coordinates.GNO)
+			default:
+				return gnos
+			}
+		}
+	}
+
+	// 2 and 3 are delivered before 1, and 2 again after a timeout of the extractor.
+	send(2, 0)
+	send(3, 0)
+	if got := checkpoints(); len(got) != 0 {
+		t.Fatalf("applied before message 1: %v", got)
+	}
+	send(1, 0)
+	send(2, 0)
+	if got := checkpoints(); !reflect.DeepEqual(got, []int64{10, 20, 30}) {
+		t.Errorf("applied = %v, want [10 20 30]", got)
+	}
+	if want := sid.String() + ":30"; a.currentCoordinates.RetrievedGtidSet != want {
+		t.Errorf("RetrievedGtidSet = %v, want %v", a.currentCoordinates.RetrievedGtidSet, want)
+	}
+
+	// 4 is lost, and the window of 4 messages is exceeded.
+	for seq := int64(5); seq < 8; seq++ {
+		send(seq, 3)
+	}
+	entries := binlog.BinlogEntries{Stream: "s1", Seq: 8, AckedSeq: 3}
+	bs, err := Encode(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Publish("job_incr_hete", bs); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case result := <-a.waitCh:
+		if result.ExitCode != TaskStateRestart || result.Err == nil ||
+			!strings.Contains(result.Err.Error(), "message 4 is not received, while message 8 is") {
+			t.Errorf("wait result = %+v, want a restart for the gap", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the applier is not restarted for the gap")
+	}
+	if got := checkpoints(); len(got) != 0 {
+		t.Errorf("applied after the gap: %v", got)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func validateShardBy(cfg *config.MySQLDriverConfig) (errs []error) {
+	seen := make(map[string]bool)
+	for i, s := range cfg.ShardBy {
+		if s.TableSchema == "" || s.TableName == "" || s.Column == "" {
+			errs = append(errs, fmt.Errorf("bad job argument: ShardBy[%v]: TableSchema, TableName and Column are required", i))
+			continue
+		}
+		if s.Shards < 2 {
+			errs = append(errs, fmt.Errorf("bad job argument: ShardBy[%v].Shards=%v. should be 2 or more", i, s.Shards))
+		}
+		if s.NameTemplate != "" && !strings.Contains(s.NameTemplate, "{shard}") {
+			errs = append(errs, fmt.Errorf("bad job argument: ShardBy[%v].NameTemplate=%v. should contain {shard}",
+				i, s.NameTemplate))
+		}
+		name := fmt.Sprintf("%v.%v", s.TableSchema, s.TableName)
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("bad job argument: ShardBy: %v is listed more than once", name))
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// setShardBy sets the ShardBy of a table, after its columns are got.
+func (a *Applier) setShardBy(tableItem *applierTableItem, schemaName string, tableName string) (err error) {
+	tableItem.shardBy = config.FindShardBy(a.mysqlContext.ShardBy, schemaName, tableName)
+	if tableItem.shardBy == nil {
+		return nil
+	}
+	tableItem.shardColumn, err = tableItem.shardBy.ShardColumnIndex(tableItem.columns)
+	return err
+}
+
+// shardItem returns the table item of a shard of the table, with the columns of the table.
+// Its statements are prepared on the shard table.
+func (ait *applierTableItem) shardItem(shard int) *applierTableItem {
+	for len(ait.shards) <= shard {
+		ait.shards = append(ait.shards, newApplierTableItem(len(ait.psInsert)))
+	}
+	item := ait.shards[shard]
+	item.columns = ait.columns
+	item.columnExprs = ait.columnExprs
+	item.keyless = ait.keyless
+	item.sourceColumns = ait.sourceColumns
+	item.dateColumns = ait.dateColumns
+	return item
+}
+
+// shardOfValues returns the shard of a row of the table. ok is false if the row does not have the value of
+// the column, e.g. the before image of an update with binlog_row_image=minimal.
+func (ait *applierTableItem) shardOfValues(values *umconf.ColumnValues) (shard int, ok bool) {
+	if values == nil || ait.shardColumn >= len(values.AbstractValues) || values.AbstractValues[ait.shardColumn] == nil {
+		return 0, false
+	}
+	v := *values.AbstractValues[ait.shardColumn]
+	if v == nil {
+		return ait.shardBy.ShardOf(nil), true
+	}
+	return ait.shardBy.ShardOf(config.SampleKeyValue(v)), true
+}
+
+// routeShardEvents routes the rows of the tables with ShardBy to the shard tables, after
+// setTableItemForBinlogEntry. An update which moves a row to another shard is split into a delete from
+// the old shard and an insert into the new one.
+func routeShardEvents(events []binlog.DataEvent) ([]binlog.DataEvent, error) {
+	var routed []binlog.DataEvent
+	for i := range events {
+		event := events[i]
+		tableItem, ok := event.TableItem.(*applierTableItem)
+		if event.DML == binlog.NotDML || !ok || tableItem.shardBy == nil {
+			routed = append(routed, event)
+			continue
+		}
+		shardBy := tableItem.shardBy
+		var oldShard, newShard int
+		var oldOk, newOk bool
+		if event.DML != binlog.InsertDML {
+			oldShard, oldOk = tableItem.shardOfValues(event.WhereColumnValues)
+		}
+		if event.DML != binlog.DeleteDML {
+			newShard, newOk = tableItem.shardOfValues(event.NewColumnValues)
+		}
+		switch event.DML {
+		case binlog.InsertDML:
+			oldShard, oldOk = newShard, newOk
+		case binlog.DeleteDML:
+			newShard, newOk = oldShard, oldOk
+		case binlog.UpdateDML:
+			if !newOk {
+				// the column is not updated
+				newShard, newOk = oldShard, oldOk
+			}
+		}
+		if !oldOk || !newOk {
+			return nil, fmt.Errorf("%v.%v: ShardBy: a row without the value of %v. binlog_row_image=full is required",
+				event.DatabaseName, event.TableName, shardBy.Column)
+		}
+
+		if oldShard != newShard {
+			deleteEvent := event
+			deleteEvent.DML = binlog.DeleteDML
+			deleteEvent.NewColumnValues = nil
+			deleteEvent.TableName = shardBy.ShardTableName(oldShard)
+			deleteEvent.TableItem = tableItem.shardItem(oldShard)
+			routed = append(routed, deleteEvent)
+
+			event.DML = binlog.InsertDML
+			event.WhereColumnValues = nil
+		}
+		event.TableName = shardBy.ShardTableName(newShard)
+		event.TableItem = tableItem.shardItem(newShard)
+		routed = append(routed, event)
+	}
+	return routed, nil
+}
+
+// createShardTables creates the shard tables of a table with ShardBy like it, on the full copy.
+func (a *Applier) createShardTables(execQuery func(string) error, shardBy *config.ShardBy) error {
+	for shard := 0; shard < shardBy.Shards; shard++ {
+		query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.%s LIKE %s.%s",
+			a.quoteName(shardBy.TableSchema), a.quoteName(shardBy.ShardTableName(shard)),
+			a.quoteName(shardBy.TableSchema), a.quoteName(shardBy.TableName))
+		if err := execQuery(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardDumpRows splits the rows of the full copy of a table with ShardBy by shard.
+func (a *Applier) shardDumpRows(entry *DumpEntry, shardBy *config.ShardBy) ([][][]*[]byte, error) {
+	columns, _, err := a.getTableColumns(entry.TableSchema, entry.TableName)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := shardBy.ShardColumnIndex(columns)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][][]*[]byte, shardBy.Shards)
+	for _, row := range entry.ValuesX {
+		if idx >= len(row) {
+			return nil, fmt.Errorf("%v.%v: ShardBy: %v columns but %v values in a row",
+				entry.TableSchema, entry.TableName, columns.Len(), len(row))
+		}
+		var shard int
+		if row[idx] == nil {
+			shard = shardBy.ShardOf(nil)
+		} else {
+			shard = shardBy.ShardOf(*row[idx])
+		}
+		rows[shard] = append(rows[shard], row)
+	}
+	return rows, nil
+}
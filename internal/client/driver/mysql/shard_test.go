@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// shardTestKeys returns two values of the column in different shards.
+func shardTestKeys(shardBy *config.ShardBy) (int64, int64) {
+	first := shardBy.ShardOf([]byte("1"))
+	for k := int64(2); ; k++ {
+		if shardBy.ShardOf([]byte(strconv.FormatInt(k, 10))) != first {
+			return 1, k
+		}
+	}
+}
+
+func shardTestValues(values ...interface{}) *umconf.ColumnValues {
+	abstractValues := make([]*interface{}, len(values))
+	for i := range values {
+		abstractValues[i] = &values[i]
+	}
+	return &umconf.ColumnValues{AbstractValues: abstractValues}
+}
+
+func Test_routeShardEvents(t *testing.T) {
+	shardBy := &config.ShardBy{TableSchema: "db1", TableName: "t1", Column: "k", Shards: 4}
+	tableItem := newApplierTableItem(1)
+	tableItem.columns = umconf.NewColumnList([]umconf.Column{{RawName: "id"}, {RawName: "k"}})
+	tableItem.shardBy = shardBy
+	tableItem.shardColumn = 1
+	k1, k2 := shardTestKeys(shardBy)
+	shard1 := shardBy.ShardTableName(shardBy.ShardOf([]byte(strconv.FormatInt(k1, 10))))
+	shard2 := shardBy.ShardTableName(shardBy.ShardOf([]byte(strconv.FormatInt(k2, 10))))
+
+	row := shardTestValues
+	event := func(dml binlog.EventDML, where *umconf.ColumnValues, new *umconf.ColumnValues) binlog.DataEvent {
+		return binlog.DataEvent{DML: dml, DatabaseName: "db1", TableName: "t1", TableItem: tableItem,
+			WhereColumnValues: where, NewColumnValues: new}
+	}
+	events := []binlog.DataEvent{
+		event(binlog.InsertDML, nil, row(int64(1), k1)),
+		event(binlog.UpdateDML, row(int64(1), k1), row(int64(1), k1)),
+		// moved to the other shard
+		event(binlog.UpdateDML, row(int64(1), k1), row(int64(1), k2)),
+		event(binlog.DeleteDML, row(int64(1), k2), nil),
+		binlog.NewQueryEvent("db1", "alter table t1 add column c int", binlog.NotDML),
+		// NULL
+		event(binlog.InsertDML, nil, row(int64(2), nil)),
+	}
+	routed, err := routeShardEvents(events)
+	if err != nil {
+		t.Fatalf("routeShardEvents() error = %v", err)
+	}
+	want := []struct {
+		dml   binlog.EventDML
+		table string
+	}{
+		{binlog.InsertDML, shard1},
+		{binlog.UpdateDML, shard1},
+		{binlog.DeleteDML, shard1},
+		{binlog.InsertDML, shard2},
+		{binlog.DeleteDML, shard2},
+		{binlog.NotDML, ""},
+		{binlog.InsertDML, "t1_0"},
+	}
+	if len(routed) != len(want) {
+		t.Fatalf("routeShardEvents() = %v events, want %v", len(routed), len(want))
+	}
+	for i, w := range want {
+		if routed[i].DML != w.dml || routed[i].TableName != w.table {
+			t.Errorf("event %v = %v on %q, want %v on %q", i, routed[i].DML, routed[i].TableName, w.dml, w.table)
+		}
+		if item, ok := routed[i].TableItem.(*applierTableItem); w.dml != binlog.NotDML &&
+			(!ok || item == tableItem || item.columns != tableItem.columns) {
+			t.Errorf("event %v is not on the item of a shard", i)
+		}
+	}
+	if routed[2].NewColumnValues != nil || routed[3].WhereColumnValues != nil {
+		t.Errorf("the update moving the row is not split into a delete and an insert")
+	}
+
+	// binlog_row_image=minimal
+	_, err = routeShardEvents([]binlog.DataEvent{event(binlog.DeleteDML, row(int64(1)), nil)})
+	if err == nil {
+		t.Errorf("routeShardEvents() of a row without the column: no error")
+	}
+}
+
+func TestApplier_shardBy(t *testing.T) {
+	skipWithoutMySQL(t)
+	a := newLoadDataTestApplier(t, false)
+	shardBy := &config.ShardBy{TableSchema: "dtle_test", TableName: "t_shard", Column: "id", Shards: 3,
+		NameTemplate: "{table}_s{shard}"}
+	a.mysqlContext.ShardBy = []*config.ShardBy{shardBy}
+	if _, err := a.db.Exec("create database if not exists dtle_test;" +
+		"drop table if exists dtle_test.t_shard, dtle_test.t_shard_s0, dtle_test.t_shard_s1, dtle_test.t_shard_s2"); err != nil {
+		t.Fatalf("drop table error = %v", err)
+	}
+
+	bs := func(s string) *[]byte {
+		b := []byte(s)
+		return &b
+	}
+	entry := &DumpEntry{TableSchema: "dtle_test", TableName: "t_shard",
+		TbSQL: []string{"create table dtle_test.t_shard (id bigint primary key, c varchar(32))"}}
+	for id := 1; id <= 30; id++ {
+		entry.ValuesX = append(entry.ValuesX, []*[]byte{bs(strconv.Itoa(id)), bs("dump")})
+	}
+	if err := a.ApplyEventQueries(a.db, entry); err != nil {
+		t.Fatalf("ApplyEventQueries() error = %v", err)
+	}
+
+	// the shard table of each row
+	rowsByTable := func() map[int64]string {
+		got := make(map[int64]string)
+		for _, table := range []string{"t_shard", "t_shard_s0", "t_shard_s1", "t_shard_s2"} {
+			rows, err := a.db.Query(fmt.Sprintf("select id, c from dtle_test.%v", table))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for rows.Next() {
+				var id int64
+				var c string
+				if err := rows.Scan(&id, &c); err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := got[id]; ok {
+					t.Errorf("row %v is in more than one table", id)
+				}
+				got[id] = table + ":" + c
+			}
+			rows.Close()
+		}
+		return got
+	}
+	wantTable := func(id int64) string {
+		return shardBy.ShardTableName(shardBy.ShardOf([]byte(strconv.FormatInt(id, 10))))
+	}
+	got := rowsByTable()
+	for id := int64(1); id <= 30; id++ {
+		if want := wantTable(id) + ":dump"; got[id] != want {
+			t.Errorf("row %v of the full copy = %v, want %v", id, got[id], want)
+		}
+	}
+
+	// an id of another shard than row 2
+	moveTo := int64(100)
+	for shardBy.ShardOf([]byte(strconv.FormatInt(moveTo, 10))) == shardBy.ShardOf([]byte("2")) {
+		moveTo++
+	}
+	row := func(id int64, c string) *umconf.ColumnValues {
+		return shardTestValues(id, c)
+	}
+	entries := []*binlog.BinlogEntry{{
+		Coordinates: base.BinlogCoordinateTx{SID: uuid.NewV4(), GNO: 1},
+		Events: []binlog.DataEvent{
+			{DML: binlog.InsertDML, DatabaseName: "dtle_test", TableName: "t_shard", NewColumnValues: row(31, "incr")},
+			{DML: binlog.UpdateDML, DatabaseName: "dtle_test", TableName: "t_shard",
+				WhereColumnValues: row(1, "dump"), NewColumnValues: row(1, "updated")},
+			{DML: binlog.UpdateDML, DatabaseName: "dtle_test", TableName: "t_shard",
+				WhereColumnValues: row(2, "dump"), NewColumnValues: row(moveTo, "moved")},
+			{DML: binlog.DeleteDML, DatabaseName: "dtle_test", TableName: "t_shard", WhereColumnValues: row(3, "dump")},
+		},
+	}}
+	for _, entry := range entries {
+		if err := a.setTableItemForBinlogEntry(entry); err != nil {
+			t.Fatalf("setTableItemForBinlogEntry() error = %v", err)
+		}
+		if err := a.ApplyBinlogEvent(nil, 0, a.applyBatches[0], entry); err != nil {
+			t.Fatalf("ApplyBinlogEvent() error = %v", err)
+		}
+		if err := a.commitApplyBatch(0, a.applyBatches[0]); err != nil {
+			t.Fatalf("commitApplyBatch() error = %v", err)
+		}
+	}
+
+	got = rowsByTable()
+	want := map[int64]string{
+		31:     wantTable(31) + ":incr",
+		1:      wantTable(1) + ":updated",
+		moveTo: wantTable(moveTo) + ":moved",
+	}
+	for id, w := range want {
+		if got[id] != w {
+			t.Errorf("row %v = %v, want %v", id, got[id], w)
+		}
+	}
+	for _, id := range []int64{2, 3} {
+		if c, ok := got[id]; ok {
+			t.Errorf("row %v = %v, want it deleted", id, c)
+		}
+	}
+	if len(got) != 30 {
+		t.Errorf("%v rows, want 30", len(got))
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"time"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+)
+
+// startConsistentSnapshot starts a transaction with a consistent snapshot on e.singletonDB, whose
+// connections are REPEATABLE READ, and sets e.initialBinlogCoordinates to those of the snapshot.
+//
+// With GTID enabled, no lock is taken: @@gtid_executed is read before the snapshot is started and
+// compared with the one read in the snapshot, which is retried until no transaction is committed
+// meanwhile. Otherwise the binlog position is read under FLUSH TABLES WITH READ LOCK.
+// MariaDB tells the binlog position of the snapshot itself (see startMariadbSnapshot). Aurora MySQL allows
+// no lock, and requires GTID.
+// Only InnoDB tables are consistent in the snapshot in either way.
+func (e *Extractor) startConsistentSnapshot() (*gosql.Tx, error) {
+	if e.isMariaDB() {
+		return e.startMariadbSnapshot()
+	}
+
+	var gtidMode string
+	if err := e.singletonDB.QueryRow("select @@global.gtid_mode").Scan(&gtidMode); err != nil {
+		return nil, err
+	}
+	if gtidMode != "ON" {
+		if e.isAurora() {
+			return nil, fmt.Errorf("gtid_mode is %v. FLUSH TABLES WITH READ LOCK is not allowed on Aurora MySQL."+
+				" set gtid-mode to ON in the DB cluster parameter group", gtidMode)
+		}
+		e.logger.Warnf("mysql.extractor: gtid_mode is %v. taking the snapshot under FLUSH TABLES WITH READ LOCK", gtidMode)
+		return e.startSnapshotWithReadLock()
+	}
+
+	tx, coordinates, err := e.beginConsistentSnapshot(e.singletonDB)
+	if err != nil {
+		return nil, err
+	}
+	e.initialBinlogCoordinates = coordinates
+	return tx, nil
+}
+
+// beginConsistentSnapshot starts a snapshot on db with gtid_mode=ON, retrying until no transaction
+// commits while it is being started, so that the GTID set of the coordinates is that of the snapshot.
+func (e *Extractor) beginConsistentSnapshot(db *gosql.DB) (*gosql.Tx, *base.BinlogCoordinatesX, error) {
+	delayBetweenRetries := 200 * time.Millisecond
+	for round := 1; ; round++ {
+		var gtidBefore string
+		if err := db.QueryRow("select @@global.gtid_executed").Scan(&gtidBefore); err != nil {
+			e.logger.Errorf("mysql.extractor: get gtid, round: %v, err: %v", round, err)
+			return nil, nil, err
+		}
+
+		e.testStub1()
+
+		tx, coordinates, err := e.beginSnapshot(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.logger.Debugf("mysql.extractor: gtid before the snapshot: %v, binlog coordinates of the snapshot: %+v",
+			gtidBefore, coordinates)
+		if coordinates.GtidSet == gtidBefore {
+			e.logger.Infof("mysql.extractor: got a consistent snapshot with GTID after %v rounds", round)
+			return tx, coordinates, nil
+		}
+
+		e.logger.Warnf("mysql.extractor: transactions committed while starting the snapshot in round %v. Will retry.", round)
+		if err := tx.Rollback(); err != nil {
+			return nil, nil, err
+		}
+		time.Sleep(delayBetweenRetries)
+	}
+}
+
+// startSnapshotWithReadLock starts the snapshot while writes are blocked, as mysqldump --single-transaction
+// --master-data does. The lock is released once the snapshot is started.
+func (e *Extractor) startSnapshotWithReadLock() (*gosql.Tx, error) {
+	ctx := context.Background()
+	conn, err := e.singletonDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	query := "FLUSH TABLES WITH READ LOCK"
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		e.logger.Errorf("mysql.extractor: exec %+v, error: %v", query, err)
+		return nil, err
+	}
+	defer func() {
+		query := "UNLOCK TABLES"
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			e.logger.Errorf("mysql.extractor: exec %+v, error: %v", query, err)
+		}
+	}()
+
+	tx, coordinates, err := e.beginSnapshot(e.singletonDB)
+	if err != nil {
+		return nil, err
+	}
+	e.initialBinlogCoordinates = coordinates
+	return tx, nil
+}
+
+// beginSnapshot starts a transaction with a consistent snapshot and reads the binlog coordinates in it.
+func (e *Extractor) beginSnapshot(db *gosql.DB) (*gosql.Tx, *base.BinlogCoordinatesX, error) {
+	// TODO it seems that two 'start transaction' will be sent.
+	// https://github.com/golang/go/issues/19981
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	query := "START TRANSACTION WITH CONSISTENT SNAPSHOT"
+	if _, err := tx.Exec(query); err != nil {
+		e.logger.Errorf("mysql.extractor: exec %+v, error: %v", query, err)
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	e.testStub1()
+
+	rows, err := tx.Query("show master status")
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	coordinates, err := base.ParseBinlogCoordinatesFromRows(rows)
+	if err == nil && coordinates == nil {
+		err = fmt.Errorf("binary log is not enabled on the source")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	return tx, coordinates, nil
+}
+
+// startMariadbSnapshot starts the snapshot on MariaDB, where binlog_snapshot_file and binlog_snapshot_position
+// are the binlog position of the snapshot of the session. No lock is taken.
+// The GTID position is from BINLOG_GTID_POS() of the binlog position.
+func (e *Extractor) startMariadbSnapshot() (*gosql.Tx, error) {
+	tx, err := e.singletonDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	query := "START TRANSACTION WITH CONSISTENT SNAPSHOT"
+	if _, err := tx.Exec(query); err != nil {
+		e.logger.Errorf("mysql.extractor: exec %+v, error: %v", query, err)
+		tx.Rollback()
+		return nil, err
+	}
+
+	e.testStub1()
+
+	coordinates := &base.BinlogCoordinatesX{}
+	query = "select variable_value from information_schema.session_status where variable_name = ?"
+	if err := tx.QueryRow(query, "binlog_snapshot_file").Scan(&coordinates.LogFile); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.QueryRow(query, "binlog_snapshot_position").Scan(&coordinates.LogPos); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if coordinates.LogFile == "" {
+		tx.Rollback()
+		return nil, fmt.Errorf("binary log is not enabled on the source")
+	}
+	var gtidPos gosql.NullString
+	if err := tx.QueryRow("select BINLOG_GTID_POS(?, ?)", coordinates.LogFile, coordinates.LogPos).Scan(&gtidPos); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !gtidPos.Valid {
+		tx.Rollback()
+		return nil, fmt.Errorf("cannot get the GTID position of binlog %v:%v", coordinates.LogFile, coordinates.LogPos)
+	}
+	coordinates.GtidSet = gtidPos.String
+
+	e.logger.Infof("mysql.extractor: got a consistent snapshot of MariaDB at %+v", coordinates)
+	e.initialBinlogCoordinates = coordinates
+	return tx, nil
+}
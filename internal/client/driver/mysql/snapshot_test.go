@@ -0,0 +1,269 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	gomysql "github.com/siddontang/go-mysql/mysql"
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func countGtids(t *testing.T, gtidSet string) (n int64) {
+	set, err := gomysql.ParseMysqlGTIDSet(gtidSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uuidSet := range set.(*gomysql.MysqlGTIDSet).Sets {
+		for _, interval := range uuidSet.Intervals {
+			n += interval.Stop - interval.Start
+		}
+	}
+	return n
+}
+
+// Rows in the snapshot (the full copy) and transactions after its GTID set (the incremental copy) make up
+// the table, with no row in both or neither.
+func TestExtractor_startConsistentSnapshot(t *testing.T) {
+	skipWithoutMySQL(t)
+	uri := "root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&transaction_isolation='REPEATABLE-READ'"
+	db, err := sql.CreateDB(uri)
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	var gtidMode string
+	if err := db.QueryRow("select @@global.gtid_mode").Scan(&gtidMode); err != nil {
+		t.Fatal(err)
+	}
+	if gtidMode != "ON" {
+		t.Skipf("gtid_mode is %v", gtidMode)
+	}
+	for _, query := range []string{
+		"drop database if exists dtle_test_snapshot",
+		"create database dtle_test_snapshot",
+		"create table dtle_test_snapshot.t (id int auto_increment primary key) engine=InnoDB",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer db.Exec("drop database if exists dtle_test_snapshot")
+
+	// Transactions are committed while the snapshot is taken.
+	startWriter := func() (stop func()) {
+		stopCh := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+				if _, err := db.Exec("insert into dtle_test_snapshot.t values ()"); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		}()
+		return func() {
+			close(stopCh)
+			wg.Wait()
+		}
+	}
+
+	e := &Extractor{logger: logrus.NewEntry(logrus.New()), singletonDB: db}
+	for i := 0; i < 5; i++ {
+		stopWriter := startWriter()
+		time.Sleep(50 * time.Millisecond)
+		tx, err := e.startConsistentSnapshot()
+		if err != nil {
+			stopWriter()
+			t.Fatalf("startConsistentSnapshot() error = %v", err)
+		}
+		// The rows written in the meantime are not seen.
+		time.Sleep(50 * time.Millisecond)
+		var snapshotRows int64
+		if err := tx.QueryRow("select count(*) from dtle_test_snapshot.t").Scan(&snapshotRows); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		snapshotGtid := e.initialBinlogCoordinates.GtidSet
+		stopWriter()
+
+		var totalRows int64
+		if err := db.QueryRow("select count(*) from dtle_test_snapshot.t").Scan(&totalRows); err != nil {
+			t.Fatal(err)
+		}
+		var gtidNow string
+		if err := db.QueryRow("select @@global.gtid_executed").Scan(&gtidNow); err != nil {
+			t.Fatal(err)
+		}
+		incremental, err := base.GtidSetSubtract(gtidNow, snapshotGtid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Each insert is a transaction.
+		if n := countGtids(t, incremental); snapshotRows+n != totalRows {
+			t.Fatalf("round %v: %v rows in the snapshot + %v transactions after %v, want %v rows",
+				i, snapshotRows, n, snapshotGtid, totalRows)
+		}
+	}
+}
+
+// The table is dumped in chunks while several writers update the rows, including those of the chunks already
+// dumped, and insert new ones. Each transaction adds 1 to sum(v), so the dump and the transactions after the
+// GTID set of the snapshot (those replayed by the incremental copy) make up the final table, whatever the chunk
+// a row is in: a write is neither lost nor counted twice.
+func TestExtractor_dumpUnderConcurrentWrites(t *testing.T) {
+	skipWithoutMySQL(t)
+	uri := "root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&transaction_isolation='REPEATABLE-READ'"
+	db, err := sql.CreateDB(uri)
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	var gtidMode string
+	if err := db.QueryRow("select @@global.gtid_mode").Scan(&gtidMode); err != nil {
+		t.Fatal(err)
+	}
+	if gtidMode != "ON" {
+		t.Skipf("gtid_mode is %v", gtidMode)
+	}
+	const nRows = 2000
+	for _, query := range []string{
+		"drop database if exists dtle_test_dump",
+		"create database dtle_test_dump",
+		"create table dtle_test_dump.t (id int primary key, v int not null) engine=InnoDB",
+		fmt.Sprintf("insert into dtle_test_dump.t (id, v) select n, 0 from (select @n := @n + 1 n"+
+			" from information_schema.COLUMNS a, information_schema.COLUMNS b, (select @n := 0) c limit %v) x", nRows),
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer db.Exec("drop database if exists dtle_test_dump")
+
+	stopCh := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; ; i++ {
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+				var err error
+				if i%10 == 0 {
+					// after the last row, i.e. in a chunk not dumped yet
+					_, err = db.Exec("insert into dtle_test_dump.t values (?, 1)", nRows+1+w*1000000+i)
+				} else {
+					_, err = db.Exec("update dtle_test_dump.t set v = v + 1 where id = ?", 1+r.Intn(nRows))
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	stopWriters := func() {
+		if stopCh != nil {
+			close(stopCh)
+			wg.Wait()
+			stopCh = nil
+		}
+	}
+	defer stopWriters()
+	time.Sleep(100 * time.Millisecond)
+
+	e := &Extractor{logger: logrus.NewEntry(logrus.New()), singletonDB: db}
+	tx, err := e.startConsistentSnapshot()
+	if err != nil {
+		t.Fatalf("startConsistentSnapshot() error = %v", err)
+	}
+	defer tx.Rollback()
+	table := config.NewTable("dtle_test_dump", "t")
+	table.Where = "true"
+	table.OriginalTableColumns = umconf.NewColumnList(umconf.NewColumns([]string{"id", "v"}))
+	table.UseUniqueKey = &umconf.UniqueKey{
+		Name:        "PRIMARY",
+		Columns:     *umconf.NewColumnList(umconf.NewColumns([]string{"id"})),
+		LastMaxVals: make([]string, 1),
+	}
+	d := NewDumper(tx, table, 100, e.logger)
+	if err := d.Dump(); err != nil {
+		t.Fatalf("dumper.Dump() error = %v", err)
+	}
+	dumped := make(map[int64]bool)
+	var dumpedSum int64
+	for entry := range d.resultsChannel {
+		if entry.Err != "" {
+			t.Fatalf("dump error = %v", entry.Err)
+		}
+		for _, row := range entry.ValuesX {
+			id, _ := strconv.ParseInt(string(*row[0]), 10, 64)
+			v, _ := strconv.ParseInt(string(*row[1]), 10, 64)
+			if dumped[id] {
+				t.Fatalf("row %v dumped twice", id)
+			}
+			dumped[id] = true
+			dumpedSum += v
+		}
+		// let the writers change the chunks dumped
+		time.Sleep(10 * time.Millisecond)
+	}
+	stopWriters()
+	snapshotGtid := e.initialBinlogCoordinates.GtidSet
+
+	var finalSum int64
+	if err := db.QueryRow("select sum(v) from dtle_test_dump.t").Scan(&finalSum); err != nil {
+		t.Fatal(err)
+	}
+	var gtidNow string
+	if err := db.QueryRow("select @@global.gtid_executed").Scan(&gtidNow); err != nil {
+		t.Fatal(err)
+	}
+	incremental, err := base.GtidSetSubtract(gtidNow, snapshotGtid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := countGtids(t, incremental)
+	if n == 0 {
+		t.Fatalf("no transaction during the dump")
+	}
+	if dumpedSum+n != finalSum {
+		t.Errorf("%v rows dumped with sum(v) %v + %v transactions after %v, want sum(v) %v",
+			len(dumped), dumpedSum, n, snapshotGtid, finalSum)
+	}
+}
@@ -65,16 +65,37 @@ func EscapeValue(colValue string) string {
 	return colBuffer.String()
 }
 
+// bitPreparedValue is the placeholder of a value of a BIT column, which is a uint64.
+// The driver sends a uint64 with the highest bit set as a string, which a BIT column takes as bytes.
+const bitPreparedValue = "cast(? as unsigned)"
+
+// preparedValue returns the placeholder of the value of a column written in a statement.
+func preparedValue(column *umconf.Column) string {
+	token := "?"
+	if column.Type == umconf.BitColumnType {
+		token = bitPreparedValue
+	}
+	if column.TimezoneConversion != nil {
+		token = fmt.Sprintf("convert_tz(?, '%s', '%s')", column.TimezoneConversion.ToTimezone, "+00:00")
+	}
+	if column.CastType != "" {
+		token = fmt.Sprintf("cast(%s as %s)", token, column.CastType)
+	}
+	return token
+}
+
+// comparisonPreparedValue returns the placeholder of the value of a column compared in a WHERE clause.
+func comparisonPreparedValue(column *umconf.Column) string {
+	if column.Type == umconf.BitColumnType {
+		return bitPreparedValue
+	}
+	return "?"
+}
+
 func buildColumnsPreparedValues(columns *umconf.ColumnList) []string {
 	values := make([]string, columns.Len(), columns.Len())
-	for i, column := range columns.ColumnList() {
-		var token string
-		if column.TimezoneConversion != nil {
-			token = fmt.Sprintf("convert_tz(?, '%s', '%s')", column.TimezoneConversion.ToTimezone, "+00:00")
-		} else {
-			token = "?"
-		}
-		values[i] = token
+	for i := range columns.Columns {
+		values[i] = preparedValue(&columns.Columns[i])
 	}
 	return values
 }
@@ -101,19 +122,25 @@ func BuildSetPreparedClause(columns *umconf.ColumnList) (result string, err erro
 		return "", fmt.Errorf("Got 0 columns in BuildSetPreparedClause")
 	}
 	setTokens := []string{}
-	for _, column := range columns.ColumnList() {
-		var setToken string
-		if column.TimezoneConversion != nil {
-			setToken = fmt.Sprintf("%s=convert_tz(?, '%s', '%s')", column.EscapedName, column.TimezoneConversion.ToTimezone, "+00:00")
-		} else {
-			setToken = fmt.Sprintf("%s=?", column.EscapedName)
-		}
-		setTokens = append(setTokens, setToken)
+	for i := range columns.Columns {
+		column := &columns.Columns[i]
+		setTokens = append(setTokens, fmt.Sprintf("%s=%s", column.EscapedName, preparedValue(column)))
 	}
 	return strings.Join(setTokens, ", "), nil
 }
 
 func BuildDMLDeleteQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, args []*interface{}) (result string, columnArgs []interface{}, hasUK bool, err error) {
+	return buildDMLDeleteQuery(databaseName, tableName, tableColumns, args, false)
+}
+
+// BuildDMLKeylessDeleteQuery builds a delete on a table without a primary key, where the row is matched
+// by all the columns. With limitOne, only one of duplicate rows is deleted.
+func BuildDMLKeylessDeleteQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, args []*interface{}, limitOne bool) (result string, columnArgs []interface{}, err error) {
+	result, columnArgs, _, err = buildDMLDeleteQuery(databaseName, tableName, tableColumns, args, limitOne)
+	return result, columnArgs, err
+}
+
+func buildDMLDeleteQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, args []*interface{}, limitOne bool) (result string, columnArgs []interface{}, hasUK bool, err error) {
 	if len(args) < tableColumns.Len() {
 		return result, columnArgs, hasUK, fmt.Errorf("args count differs from table column count in BuildDMLDeleteQuery %v, %v",
 			len(args), tableColumns.Len())
@@ -143,7 +170,7 @@ func BuildDMLDeleteQuery(databaseName, tableName string, tableColumns *umconf.Co
 				}
 			} else {
 				arg := column.ConvertArg(*args[tableOrdinal])
-				comparison, err := BuildValueComparison(column.EscapedName, "?", EqualsComparisonSign)
+				comparison, err := BuildValueComparison(column.EscapedName, comparisonPreparedValue(&column), EqualsComparisonSign)
 				if err != nil {
 					return result, columnArgs, hasUK, err
 				}
@@ -163,8 +190,8 @@ func BuildDMLDeleteQuery(databaseName, tableName string, tableColumns *umconf.Co
 		columnArgs = uniqueKeyArgs
 	}
 
-	databaseName = umconf.EscapeName(databaseName)
-	tableName = umconf.EscapeName(tableName)
+	databaseName = tableColumns.QuoteName(databaseName)
+	tableName = tableColumns.QuoteName(tableName)
 	if err != nil {
 		return result, columnArgs, hasUK, err
 	}
@@ -174,13 +201,107 @@ func BuildDMLDeleteQuery(databaseName, tableName string, tableColumns *umconf.Co
 					%s.%s
 				where
 					%s
+				%s
 		`, databaseName, tableName,
 		fmt.Sprintf("(%s)", strings.Join(comparisons, " and ")),
+		buildLimitClause(limitOne),
 	)
 	return result, columnArgs, hasUK, nil
 }
 
+func buildLimitClause(limitOne bool) string {
+	if limitOne {
+		return "limit 1"
+	}
+	return ""
+}
+
+// HasPrimaryKey tells whether any of the columns is PRI, by which BuildDMLDeleteQuery and
+// BuildDMLUpdateQuery match a row. Otherwise rows are matched by all the columns.
+func HasPrimaryKey(tableColumns *umconf.ColumnList) bool {
+	for _, column := range tableColumns.Columns {
+		if strings.ToUpper(column.Key) == "PRI" {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSingleColumnPrimaryKey returns the only PRI column of the table, or nil
+// if the table has no primary key or a composite one. Binary columns are
+// compared with a cast literal in BuildDMLDeleteQuery and are not supported either.
+func GetSingleColumnPrimaryKey(tableColumns *umconf.ColumnList) *umconf.Column {
+	var pk *umconf.Column
+	for i := range tableColumns.Columns {
+		column := &tableColumns.Columns[i]
+		if strings.ToUpper(column.Key) != "PRI" {
+			continue
+		}
+		if pk != nil {
+			return nil
+		}
+		pk = column
+	}
+	if pk != nil && pk.Type == umconf.BinaryColumnType {
+		return nil
+	}
+	return pk
+}
+
+// BuildDMLBatchDeleteQuery builds one `delete ... where pk in (...)` for several rows of
+// a table with a single-column primary key. It is equivalent to calling BuildDMLDeleteQuery
+// on each row in turn.
+func BuildDMLBatchDeleteQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, argsList [][]*interface{}) (result string, columnArgs []interface{}, err error) {
+	if len(argsList) == 0 {
+		return result, columnArgs, fmt.Errorf("No rows found in BuildDMLBatchDeleteQuery")
+	}
+	pk := GetSingleColumnPrimaryKey(tableColumns)
+	if pk == nil {
+		return result, columnArgs, fmt.Errorf("table %s.%s does not have a single-column primary key in BuildDMLBatchDeleteQuery",
+			databaseName, tableName)
+	}
+	pkOrdinal := tableColumns.Ordinals[pk.RawName]
+
+	for _, args := range argsList {
+		if len(args) < tableColumns.Len() {
+			return result, columnArgs, fmt.Errorf("args count differs from table column count in BuildDMLBatchDeleteQuery %v, %v",
+				len(args), tableColumns.Len())
+		}
+		if *args[pkOrdinal] == nil {
+			return result, columnArgs, fmt.Errorf("NULL primary key value in BuildDMLBatchDeleteQuery")
+		}
+		columnArgs = append(columnArgs, pk.ConvertArg(*args[pkOrdinal]))
+	}
+
+	placeholders := make([]string, len(columnArgs))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	result = fmt.Sprintf(`
+			delete
+				from
+					%s.%s
+				where
+					(%s in (%s))
+		`, tableColumns.QuoteName(databaseName), tableColumns.QuoteName(tableName),
+		pk.EscapedName, strings.Join(placeholders, ", "),
+	)
+	return result, columnArgs, nil
+}
+
 func BuildDMLInsertQuery(databaseName, tableName string, tableColumns, sharedColumns, mappedSharedColumns *umconf.ColumnList, args []*interface{}) (result string, sharedArgs []interface{}, err error) {
+	return BuildDMLInsertQueryWithExprs(databaseName, tableName, tableColumns, sharedColumns, mappedSharedColumns, args, nil)
+}
+
+// ColumnExpr is a column whose value is an SQL expression rather than an argument, e.g. `NOW()`.
+type ColumnExpr struct {
+	EscapedName string
+	Expr        string
+}
+
+// BuildDMLInsertQueryWithExprs is BuildDMLInsertQuery which also sets the columns of exprs, which are not in tableColumns.
+func BuildDMLInsertQueryWithExprs(databaseName, tableName string, tableColumns, sharedColumns, mappedSharedColumns *umconf.ColumnList, args []*interface{}, exprs []*ColumnExpr) (result string, sharedArgs []interface{}, err error) {
 	if len(args) < tableColumns.Len() {
 		return result, sharedArgs, fmt.Errorf("args count differs from table column count in BuildDMLInsertQuery %v, %v",
 			len(args), tableColumns.Len())
@@ -192,8 +313,8 @@ func BuildDMLInsertQuery(databaseName, tableName string, tableColumns, sharedCol
 	if sharedColumns.Len() == 0 {
 		return result, sharedArgs, fmt.Errorf("No shared columns found in BuildDMLInsertQuery")
 	}
-	databaseName = umconf.EscapeName(databaseName)
-	tableName = umconf.EscapeName(tableName)
+	databaseName = tableColumns.QuoteName(databaseName)
+	tableName = tableColumns.QuoteName(tableName)
 
 	for _, column := range tableColumns.ColumnList() {
 		tableOrdinal := tableColumns.Ordinals[column.RawName]
@@ -207,6 +328,10 @@ func BuildDMLInsertQuery(databaseName, tableName string, tableColumns, sharedCol
 
 	mappedSharedColumnNames := duplicateNames(tableColumns.EscapedNames())
 	preparedValues := buildColumnsPreparedValues(tableColumns)
+	for _, expr := range exprs {
+		mappedSharedColumnNames = append(mappedSharedColumnNames, expr.EscapedName)
+		preparedValues = append(preparedValues, expr.Expr)
+	}
 
 	result = fmt.Sprintf(`
 			replace into
@@ -222,6 +347,20 @@ func BuildDMLInsertQuery(databaseName, tableName string, tableColumns, sharedCol
 }
 
 func BuildDMLUpdateQuery(databaseName, tableName string, tableColumns, sharedColumns, mappedSharedColumns, uniqueKeyColumns *umconf.ColumnList, valueArgs, whereArgs []*interface{}) (result string, sharedArgs, columnArgs []interface{}, hasUK bool, err error) {
+	return buildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, mappedSharedColumns, valueArgs, whereArgs, true)
+}
+
+// BuildDMLKeylessUpdateQuery builds an update on a table without a primary key, where the row is matched
+// by all the columns. With limitOne, only one of duplicate rows is updated.
+func BuildDMLKeylessUpdateQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, valueArgs, whereArgs []*interface{}, limitOne bool) (result string, args []interface{}, err error) {
+	result, sharedArgs, columnArgs, _, err := buildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, valueArgs, whereArgs, limitOne)
+	if err != nil {
+		return result, nil, err
+	}
+	return result, append(sharedArgs, columnArgs...), nil
+}
+
+func buildDMLUpdateQuery(databaseName, tableName string, tableColumns, sharedColumns, mappedSharedColumns *umconf.ColumnList, valueArgs, whereArgs []*interface{}, limitOne bool) (result string, sharedArgs, columnArgs []interface{}, hasUK bool, err error) {
 
 	if len(valueArgs) < tableColumns.Len() {
 		return result, sharedArgs, columnArgs, hasUK, fmt.Errorf("value args count differs from table column count in BuildDMLUpdateQuery %v, %v",
@@ -237,8 +376,8 @@ func BuildDMLUpdateQuery(databaseName, tableName string, tableColumns, sharedCol
 	if sharedColumns.Len() == 0 {
 		return result, sharedArgs, columnArgs, hasUK, fmt.Errorf("No shared columns found in BuildDMLUpdateQuery")
 	}
-	databaseName = umconf.EscapeName(databaseName)
-	tableName = umconf.EscapeName(tableName)
+	databaseName = tableColumns.QuoteName(databaseName)
+	tableName = tableColumns.QuoteName(tableName)
 
 	for _, column := range tableColumns.ColumnList() {
 		tableOrdinal := tableColumns.Ordinals[column.RawName]
@@ -276,7 +415,7 @@ func BuildDMLUpdateQuery(databaseName, tableName string, tableColumns, sharedCol
 				}
 			} else {
 				arg := column.ConvertArg(*whereArgs[tableOrdinal])
-				comparison, err := BuildValueComparison(column.EscapedName, "?", EqualsComparisonSign)
+				comparison, err := BuildValueComparison(column.EscapedName, comparisonPreparedValue(&column), EqualsComparisonSign)
 				if err != nil {
 					return result, sharedArgs, columnArgs, hasUK, err
 				}
@@ -305,10 +444,104 @@ func BuildDMLUpdateQuery(databaseName, tableName string, tableColumns, sharedCol
 					%s
 				where
  					%s
- 				limit 1
+ 				%s
  		`, databaseName, tableName,
 		setClause,
 		fmt.Sprintf("(%s)", strings.Join(comparisons, " and ")),
+		buildLimitClause(limitOne),
 	)
 	return result, sharedArgs, columnArgs, hasUK, nil
 }
+
+// BuildDMLAppendQuery builds an update appending a value to a column of the row, which is matched by the primary key.
+// It writes a large value in pieces. args are the values of the row in the order of tableColumns,
+// of which the primary key ones are returned as keyArgs. The columns with ON UPDATE CURRENT_TIMESTAMP are assigned
+// themselves, so that they keep the values written with the row rather than being set to the current time.
+func BuildDMLAppendQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, column *umconf.Column, args []interface{}) (result string, keyArgs []interface{}, err error) {
+	if len(args) < tableColumns.Len() {
+		return result, keyArgs, fmt.Errorf("args count differs from table column count in BuildDMLAppendQuery %v, %v",
+			len(args), tableColumns.Len())
+	}
+	comparisons := []string{}
+	for i, keyColumn := range tableColumns.ColumnList() {
+		if strings.ToUpper(keyColumn.Key) != "PRI" {
+			continue
+		}
+		comparison, err := BuildValueComparison(keyColumn.EscapedName, "?", EqualsComparisonSign)
+		if err != nil {
+			return result, keyArgs, err
+		}
+		comparisons = append(comparisons, comparison)
+		keyArgs = append(keyArgs, args[i])
+	}
+	if len(comparisons) == 0 {
+		return result, keyArgs, fmt.Errorf("No primary key found in BuildDMLAppendQuery")
+	}
+	assignments := []string{fmt.Sprintf("%s = concat(%s, ?)", column.EscapedName, column.EscapedName)}
+	for _, onUpdateColumn := range tableColumns.ColumnList() {
+		if onUpdateColumn.OnUpdate && onUpdateColumn.RawName != column.RawName {
+			assignments = append(assignments, fmt.Sprintf("%s = %s", onUpdateColumn.EscapedName, onUpdateColumn.EscapedName))
+		}
+	}
+
+	result = fmt.Sprintf(`
+			update
+					%s.%s
+				set
+					%s
+				where
+					%s
+		`, tableColumns.QuoteName(databaseName), tableColumns.QuoteName(tableName),
+		strings.Join(assignments, ", "),
+		fmt.Sprintf("(%s)", strings.Join(comparisons, " and ")),
+	)
+	return result, keyArgs, nil
+}
+
+// BuildConflictCheckQuery builds a query telling whether a row, of which resolutionValue is the value of
+// resolutionColumn, is newer than the row of the table matched by the primary key in args (the values of a row
+// in the order of tableColumns). A NULL is older than any value. On a tie, the result is tieWins.
+// The matched row is locked. No row is returned if there is none.
+func BuildConflictCheckQuery(databaseName, tableName string, tableColumns *umconf.ColumnList, resolutionColumn *umconf.Column,
+	resolutionValue interface{}, args []*interface{}, tieWins bool) (result string, queryArgs []interface{}, err error) {
+
+	if len(args) < tableColumns.Len() {
+		return result, queryArgs, fmt.Errorf("args count differs from table column count in BuildConflictCheckQuery %v, %v",
+			len(args), tableColumns.Len())
+	}
+	if resolutionValue != nil {
+		resolutionValue = resolutionColumn.ConvertArg(resolutionValue)
+	}
+	queryArgs = []interface{}{resolutionValue, resolutionValue, resolutionValue, tieWins}
+
+	comparisons := []string{}
+	for i, keyColumn := range tableColumns.ColumnList() {
+		if strings.ToUpper(keyColumn.Key) != "PRI" {
+			continue
+		}
+		comparison, err := BuildValueComparison(keyColumn.EscapedName, "?", EqualsComparisonSign)
+		if err != nil {
+			return result, queryArgs, err
+		}
+		comparisons = append(comparisons, comparison)
+		queryArgs = append(queryArgs, keyColumn.ConvertArg(*args[i]))
+	}
+	if len(comparisons) == 0 {
+		return result, queryArgs, fmt.Errorf("No primary key found in BuildConflictCheckQuery")
+	}
+
+	rc := resolutionColumn.EscapedName
+	result = fmt.Sprintf(`
+			select
+					(? is not null and (%s is null or ? > %s)) or (? <=> %s and ?)
+				from
+					%s.%s
+				where
+					%s
+				for update
+		`, rc, rc, rc,
+		tableColumns.QuoteName(databaseName), tableColumns.QuoteName(tableName),
+		fmt.Sprintf("(%s)", strings.Join(comparisons, " and ")),
+	)
+	return result, queryArgs, nil
+}
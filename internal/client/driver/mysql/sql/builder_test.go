@@ -7,6 +7,7 @@
 package sql
 
 import (
+	"fmt"
 	"github.com/actiontech/dtle/internal/config/mysql"
 	"testing"
 
@@ -29,224 +30,72 @@ func normalizeQuery(name string) string {
 }
 
 func TestEscapeName(t *testing.T) {
-	names := []string{"my_table", `"my_table"`, "`my_table`"}
-	for _, name := range names {
+	names := map[string]string{
+		"my_table":   "`my_table`",
+		`"my_table"`: "`\"my_table\"`",
+		"`my_table`": "```my_table```",
+	}
+	for name, expected := range names {
 		escaped := mysql.EscapeName(name)
-		test.S(t).ExpectEquals(escaped, "`my_table`")
+		test.S(t).ExpectEquals(escaped, expected)
+	}
+}
+
+// newColumnList returns a list of the columns of names, of which those of primaryKey are PRI.
+func newColumnList(names []string, primaryKey ...string) *mysql.ColumnList {
+	columns := mysql.NewColumns(names)
+	for i := range columns {
+		for _, name := range primaryKey {
+			if columns[i].RawName == name {
+				columns[i].Key = "PRI"
+			}
+		}
+	}
+	return mysql.NewColumnList(columns)
+}
+
+// newArgs returns the values of a row as the binlog reader passes them.
+func newArgs(values ...interface{}) []*interface{} {
+	args := make([]*interface{}, len(values))
+	for i := range values {
+		args[i] = &values[i]
 	}
+	return args
 }
 
 func TestBuildSetPreparedClause(t *testing.T) {
 	{
-		columns := NewColumnList([]string{"c1"})
+		columns := newColumnList([]string{"c1"})
 		clause, err := BuildSetPreparedClause(columns)
 		test.S(t).ExpectNil(err)
 		test.S(t).ExpectEquals(clause, "`c1`=?")
 	}
 	{
-		columns := NewColumnList([]string{"c1", "c2"})
+		columns := newColumnList([]string{"c1", "c2"})
 		clause, err := BuildSetPreparedClause(columns)
 		test.S(t).ExpectNil(err)
 		test.S(t).ExpectEquals(clause, "`c1`=?, `c2`=?")
 	}
 	{
-		columns := NewColumnList([]string{})
+		columns := newColumnList([]string{})
 		_, err := BuildSetPreparedClause(columns)
 		test.S(t).ExpectNotNil(err)
 	}
 }
 
-func TestBuildRangeInsertQuery(t *testing.T) {
-	databaseName := "mydb"
-	originalTableName := "tbl"
-	ghostTableName := "ghost"
-	sharedColumns := []string{"id", "name", "position"}
-	{
-		uniqueKey := "PRIMARY"
-		uniqueKeyColumns := NewColumnList([]string{"id"})
-		rangeStartValues := []string{"@v1s"}
-		rangeEndValues := []string{"@v1e"}
-		rangeStartArgs := []interface{}{3}
-		rangeEndArgs := []interface{}{103}
-
-		query, explodedArgs, err := BuildRangeInsertQuery(databaseName, originalTableName, ghostTableName, sharedColumns, sharedColumns, uniqueKey, uniqueKeyColumns, rangeStartValues, rangeEndValues, rangeStartArgs, rangeEndArgs, true, false)
-		test.S(t).ExpectNil(err)
-		expected := `
-				insert /* udup mydb.tbl */ ignore into mydb.ghost (id, name, position)
-				(select id, name, position from mydb.tbl force index (PRIMARY)
-					where (((id > @v1s) or ((id = @v1s))) and ((id < @v1e) or ((id = @v1e))))
-				)
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(explodedArgs, []interface{}{3, 3, 103, 103}))
-	}
-	{
-		uniqueKey := "name_position_uidx"
-		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
-		rangeStartValues := []string{"@v1s", "@v2s"}
-		rangeEndValues := []string{"@v1e", "@v2e"}
-		rangeStartArgs := []interface{}{3, 17}
-		rangeEndArgs := []interface{}{103, 117}
-
-		query, explodedArgs, err := BuildRangeInsertQuery(databaseName, originalTableName, ghostTableName, sharedColumns, sharedColumns, uniqueKey, uniqueKeyColumns, rangeStartValues, rangeEndValues, rangeStartArgs, rangeEndArgs, true, false)
-		test.S(t).ExpectNil(err)
-		expected := `
-				insert /* udup mydb.tbl */ ignore into mydb.ghost (id, name, position)
-				(select id, name, position from mydb.tbl force index (name_position_uidx)
-				  where (((name > @v1s) or (((name = @v1s)) AND (position > @v2s)) or ((name = @v1s) and (position = @v2s))) and ((name < @v1e) or (((name = @v1e)) AND (position < @v2e)) or ((name = @v1e) and (position = @v2e))))
-				)
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(explodedArgs, []interface{}{3, 3, 17, 3, 17, 103, 103, 117, 103, 117}))
-	}
-}
-
-func TestBuildRangeInsertQueryRenameMap(t *testing.T) {
-	databaseName := "mydb"
-	originalTableName := "tbl"
-	ghostTableName := "ghost"
-	sharedColumns := []string{"id", "name", "position"}
-	mappedSharedColumns := []string{"id", "name", "location"}
-	{
-		uniqueKey := "PRIMARY"
-		uniqueKeyColumns := NewColumnList([]string{"id"})
-		rangeStartValues := []string{"@v1s"}
-		rangeEndValues := []string{"@v1e"}
-		rangeStartArgs := []interface{}{3}
-		rangeEndArgs := []interface{}{103}
-
-		query, explodedArgs, err := BuildRangeInsertQuery(databaseName, originalTableName, ghostTableName, sharedColumns, mappedSharedColumns, uniqueKey, uniqueKeyColumns, rangeStartValues, rangeEndValues, rangeStartArgs, rangeEndArgs, true, false)
-		test.S(t).ExpectNil(err)
-		expected := `
-				insert /* udup mydb.tbl */ ignore into mydb.ghost (id, name, location)
-				(select id, name, position from mydb.tbl force index (PRIMARY)
-					where (((id > @v1s) or ((id = @v1s))) and ((id < @v1e) or ((id = @v1e))))
-				)
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(explodedArgs, []interface{}{3, 3, 103, 103}))
-	}
-	{
-		uniqueKey := "name_position_uidx"
-		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
-		rangeStartValues := []string{"@v1s", "@v2s"}
-		rangeEndValues := []string{"@v1e", "@v2e"}
-		rangeStartArgs := []interface{}{3, 17}
-		rangeEndArgs := []interface{}{103, 117}
-
-		query, explodedArgs, err := BuildRangeInsertQuery(databaseName, originalTableName, ghostTableName, sharedColumns, mappedSharedColumns, uniqueKey, uniqueKeyColumns, rangeStartValues, rangeEndValues, rangeStartArgs, rangeEndArgs, true, false)
-		test.S(t).ExpectNil(err)
-		expected := `
-				insert /* udup mydb.tbl */ ignore into mydb.ghost (id, name, location)
-				(select id, name, position from mydb.tbl force index (name_position_uidx)
-				  where (((name > @v1s) or (((name = @v1s)) AND (position > @v2s)) or ((name = @v1s) and (position = @v2s))) and ((name < @v1e) or (((name = @v1e)) AND (position < @v2e)) or ((name = @v1e) and (position = @v2e))))
-				)
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(explodedArgs, []interface{}{3, 3, 17, 3, 17, 103, 103, 117, 103, 117}))
-	}
-}
-
-func TestBuildRangeInsertPreparedQuery(t *testing.T) {
-	databaseName := "mydb"
-	originalTableName := "tbl"
-	ghostTableName := "ghost"
-	sharedColumns := []string{"id", "name", "position"}
-	{
-		uniqueKey := "name_position_uidx"
-		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
-		rangeStartArgs := []interface{}{3, 17}
-		rangeEndArgs := []interface{}{103, 117}
-
-		query, explodedArgs, err := BuildRangeInsertPreparedQuery(databaseName, originalTableName, ghostTableName, sharedColumns, sharedColumns, uniqueKey, uniqueKeyColumns, rangeStartArgs, rangeEndArgs, true, true)
-		test.S(t).ExpectNil(err)
-		expected := `
-				insert /* udup mydb.tbl */ ignore into mydb.ghost (id, name, position)
-				(select id, name, position from mydb.tbl force index (name_position_uidx)
-				  where (((name > ?) or (((name = ?)) AND (position > ?)) or ((name = ?) and (position = ?))) and ((name < ?) or (((name = ?)) AND (position < ?)) or ((name = ?) and (position = ?))))
-				lock in share mode )
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(explodedArgs, []interface{}{3, 3, 17, 3, 17, 103, 103, 117, 103, 117}))
-	}
-}
-
-func TestBuildUniqueKeyRangeEndPreparedQuery(t *testing.T) {
-	databaseName := "mydb"
-	originalTableName := "tbl"
-	var chunkSize int64 = 500
-	{
-		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
-		rangeStartArgs := []interface{}{3, 17}
-		rangeEndArgs := []interface{}{103, 117}
-
-		query, explodedArgs, err := BuildUniqueKeyRangeEndPreparedQuery(databaseName, originalTableName, uniqueKeyColumns, rangeStartArgs, rangeEndArgs, chunkSize, false, "test")
-		test.S(t).ExpectNil(err)
-		expected := `
-				select /* udup mydb.tbl test */ name, position
-				  from (
-				    select
-				        name, position
-				      from
-				        mydb.tbl
-				      where ((name > ?) or (((name = ?)) AND (position > ?))) and ((name < ?) or (((name = ?)) AND (position < ?)) or ((name = ?) and (position = ?)))
-				      order by
-				        name asc, position asc
-				      limit 500
-				  ) select_osc_chunk
-				order by
-				  name desc, position desc
-				limit 1
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(explodedArgs, []interface{}{3, 3, 17, 103, 103, 117, 103, 117}))
-	}
-}
-
-func TestBuildUniqueKeyMinValuesPreparedQuery(t *testing.T) {
-	databaseName := "mydb"
-	originalTableName := "tbl"
-	uniqueKeyColumns := NewColumnList([]string{"name", "position"})
-	{
-		query, err := BuildUniqueKeyMinValuesPreparedQuery(databaseName, originalTableName, uniqueKeyColumns)
-		test.S(t).ExpectNil(err)
-		expected := `
-			select /* udup mydb.tbl */ name, position
-			  from
-			    mydb.tbl
-			  order by
-			    name asc, position asc
-			  limit 1
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-	}
-	{
-		query, err := BuildUniqueKeyMaxValuesPreparedQuery(databaseName, originalTableName, uniqueKeyColumns)
-		test.S(t).ExpectNil(err)
-		expected := `
-			select /* udup mydb.tbl */ name, position
-			  from
-			    mydb.tbl
-			  order by
-			    name desc, position desc
-			  limit 1
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-	}
-}
-
 func TestBuildDMLDeleteQuery(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
-	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
-	args := []interface{}{3, "testname", "first", 17, 23}
+	names := []string{"id", "name", "rank", "position", "age"}
+	args := newArgs(3, "testname", "first", 17, 23)
 	{
-		uniqueKeyColumns := NewColumnList([]string{"position"})
+		tableColumns := newColumnList(names, "position")
 
-		query, uniqueKeyArgs, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, uniqueKeyColumns, args)
+		query, uniqueKeyArgs, hasUK, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, args)
 		test.S(t).ExpectNil(err)
+		test.S(t).ExpectTrue(hasUK)
 		expected := `
-			delete /* udup mydb.tbl */
+			delete
 				from
 					mydb.tbl
 				where
@@ -256,12 +105,13 @@ func TestBuildDMLDeleteQuery(t *testing.T) {
 		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{17}))
 	}
 	{
-		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
+		tableColumns := newColumnList(names, "name", "position")
 
-		query, uniqueKeyArgs, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, uniqueKeyColumns, args)
+		query, uniqueKeyArgs, hasUK, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, args)
 		test.S(t).ExpectNil(err)
+		test.S(t).ExpectTrue(hasUK)
 		expected := `
-			delete /* udup mydb.tbl */
+			delete
 				from
 					mydb.tbl
 				where
@@ -271,25 +121,28 @@ func TestBuildDMLDeleteQuery(t *testing.T) {
 		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{"testname", 17}))
 	}
 	{
-		uniqueKeyColumns := NewColumnList([]string{"position", "name"})
+		// without a primary key, the row is matched by all the columns
+		tableColumns := newColumnList(names)
+		args := newArgs(3, "testname", nil, 17, 23)
 
-		query, uniqueKeyArgs, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, uniqueKeyColumns, args)
+		query, columnArgs, hasUK, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, args)
 		test.S(t).ExpectNil(err)
+		test.S(t).ExpectFalse(hasUK)
 		expected := `
-			delete /* udup mydb.tbl */
+			delete
 				from
 					mydb.tbl
 				where
-					((position = ?) and (name = ?))
+					((id = ?) and (name = ?) and (rank is NULL) and (position = ?) and (age = ?))
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{17, "testname"}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(columnArgs, []interface{}{3, "testname", 17, 23}))
 	}
 	{
-		uniqueKeyColumns := NewColumnList([]string{"position", "name"})
-		args := []interface{}{"first", 17}
+		tableColumns := newColumnList(names, "position", "name")
+		args := newArgs("first", 17)
 
-		_, _, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, uniqueKeyColumns, args)
+		_, _, _, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, args)
 		test.S(t).ExpectNotNil(err)
 	}
 }
@@ -297,15 +150,14 @@ func TestBuildDMLDeleteQuery(t *testing.T) {
 func TestBuildDMLDeleteQuerySignedUnsigned(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
-	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
-	uniqueKeyColumns := NewColumnList([]string{"position"})
+	tableColumns := newColumnList([]string{"id", "name", "rank", "position", "age"}, "position")
 	{
 		// test signed (expect no change)
-		args := []interface{}{3, "testname", "first", -1, 23}
-		query, uniqueKeyArgs, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, uniqueKeyColumns, args)
+		args := newArgs(3, "testname", "first", -1, 23)
+		query, uniqueKeyArgs, _, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, args)
 		test.S(t).ExpectNil(err)
 		expected := `
-			delete /* udup mydb.tbl */
+			delete
 				from
 					mydb.tbl
 				where
@@ -316,12 +168,12 @@ func TestBuildDMLDeleteQuerySignedUnsigned(t *testing.T) {
 	}
 	{
 		// test unsigned
-		args := []interface{}{3, "testname", "first", int8(-1), 23}
-		uniqueKeyColumns.SetUnsigned("position")
-		query, uniqueKeyArgs, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, uniqueKeyColumns, args)
+		args := newArgs(3, "testname", "first", int8(-1), 23)
+		tableColumns.SetUnsigned("position")
+		query, uniqueKeyArgs, _, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, args)
 		test.S(t).ExpectNil(err)
 		expected := `
-			delete /* udup mydb.tbl */
+			delete
 				from
 					mydb.tbl
 				where
@@ -335,43 +187,42 @@ func TestBuildDMLDeleteQuerySignedUnsigned(t *testing.T) {
 func TestBuildDMLInsertQuery(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
-	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
-	args := []interface{}{3, "testname", "first", 17, 23}
+	tableColumns := newColumnList([]string{"id", "name", "rank", "position", "age"})
+	args := newArgs(3, "testname", "first", 17, 23)
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
+		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, args)
 		test.S(t).ExpectNil(err)
 		expected := `
-			replace /* udup mydb.tbl */
-				into mydb.tbl
-					(id, name, position, age)
+			replace into
+				mydb.tbl
+					(id, name, rank, position, age)
 				values
-					(?, ?, ?, ?)
+					(?, ?, ?, ?, ?)
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", 17, 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "first", 17, 23}))
 	}
 	{
-		sharedColumns := NewColumnList([]string{"position", "name", "age", "id"})
-		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
+		exprs := []*ColumnExpr{{EscapedName: "`created`", Expr: "now()"}}
+		query, sharedArgs, err := BuildDMLInsertQueryWithExprs(databaseName, tableName, tableColumns, tableColumns, tableColumns, args, exprs)
 		test.S(t).ExpectNil(err)
 		expected := `
-			replace /* udup mydb.tbl */
-				into mydb.tbl
-					(position, name, age, id)
+			replace into
+				mydb.tbl
+					(id, name, rank, position, age, created)
 				values
-					(?, ?, ?, ?)
+					(?, ?, ?, ?, ?, now())
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{17, "testname", 23, 3}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "first", 17, 23}))
 	}
 	{
-		sharedColumns := NewColumnList([]string{"position", "name", "surprise", "id"})
+		sharedColumns := newColumnList([]string{"position", "name", "surprise", "id"})
 		_, _, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
 		test.S(t).ExpectNotNil(err)
 	}
 	{
-		sharedColumns := NewColumnList([]string{})
+		sharedColumns := newColumnList([]string{})
 		_, _, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
 		test.S(t).ExpectNotNil(err)
 	}
@@ -380,155 +231,127 @@ func TestBuildDMLInsertQuery(t *testing.T) {
 func TestBuildDMLInsertQuerySignedUnsigned(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
-	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
-	sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
+	tableColumns := newColumnList([]string{"id", "name", "rank", "position", "age"})
+	expected := `
+		replace into
+			mydb.tbl
+				(id, name, rank, position, age)
+			values
+				(?, ?, ?, ?, ?)
+	`
 	{
 		// testing signed
-		args := []interface{}{3, "testname", "first", int8(-1), 23}
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
+		args := newArgs(3, "testname", "first", int8(-1), 23)
+		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, args)
 		test.S(t).ExpectNil(err)
-		expected := `
-			replace /* udup mydb.tbl */
-				into mydb.tbl
-					(id, name, position, age)
-				values
-					(?, ?, ?, ?)
-		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", int8(-1), 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "first", int8(-1), 23}))
 	}
 	{
 		// testing unsigned
-		args := []interface{}{3, "testname", "first", int8(-1), 23}
-		sharedColumns.SetUnsigned("position")
-		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
+		args := newArgs(3, "testname", "first", int8(-1), 23)
+		tableColumns.SetUnsigned("position")
+		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, args)
 		test.S(t).ExpectNil(err)
-		expected := `
-			replace /* udup mydb.tbl */
-				into mydb.tbl
-					(id, name, position, age)
-				values
-					(?, ?, ?, ?)
-		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", uint8(255), 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "first", uint8(255), 23}))
 	}
 	{
 		// testing unsigned
-		args := []interface{}{3, "testname", "first", int32(-1), 23}
-		sharedColumns.SetUnsigned("position")
-		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, args)
+		args := newArgs(3, "testname", "first", int32(-1), 23)
+		tableColumns.SetUnsigned("position")
+		query, sharedArgs, err := BuildDMLInsertQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, args)
 		test.S(t).ExpectNil(err)
-		expected := `
-			replace /* udup mydb.tbl */
-				into mydb.tbl
-					(id, name, position, age)
-				values
-					(?, ?, ?, ?)
-		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", uint32(4294967295), 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "first", uint32(4294967295), 23}))
 	}
 }
 
 func TestBuildDMLUpdateQuery(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
-	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
-	valueArgs := []interface{}{3, "testname", "newval", 17, 23}
-	whereArgs := []interface{}{3, "testname", "findme", 17, 56}
+	names := []string{"id", "name", "rank", "position", "age"}
+	valueArgs := newArgs(3, "testname", "newval", 17, 23)
+	whereArgs := newArgs(3, "testname", "findme", 17, 56)
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		uniqueKeyColumns := NewColumnList([]string{"position"})
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		tableColumns := newColumnList(names, "position")
+		query, sharedArgs, uniqueKeyArgs, hasUK, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNil(err)
+		test.S(t).ExpectTrue(hasUK)
 		expected := `
-			update /* udup mydb.tbl */
+			update
 			  mydb.tbl
-					set id=?, name=?, position=?, age=?
+					set id=?, name=?, rank=?, position=?, age=?
 				where
 					((position = ?))
+				limit 1
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", 17, 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "newval", 17, 23}))
 		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{17}))
 	}
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		uniqueKeyColumns := NewColumnList([]string{"position", "name"})
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		tableColumns := newColumnList(names, "name", "position")
+		query, sharedArgs, uniqueKeyArgs, hasUK, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNil(err)
+		test.S(t).ExpectTrue(hasUK)
 		expected := `
-			update /* udup mydb.tbl */
+			update
 			  mydb.tbl
-					set id=?, name=?, position=?, age=?
+					set id=?, name=?, rank=?, position=?, age=?
 				where
-					((position = ?) and (name = ?))
-		`
-		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", 17, 23}))
-		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{17, "testname"}))
-	}
-	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		uniqueKeyColumns := NewColumnList([]string{"age"})
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
-		test.S(t).ExpectNil(err)
-		expected := `
-			update /* udup mydb.tbl */
-			  mydb.tbl
-					set id=?, name=?, position=?, age=?
-				where
-					((age = ?))
+					((name = ?) and (position = ?))
+				limit 1
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", 17, 23}))
-		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{56}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "newval", 17, 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{"testname", 17}))
 	}
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		uniqueKeyColumns := NewColumnList([]string{"age", "position", "id", "name"})
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		// without a primary key, the row is matched by all the columns
+		tableColumns := newColumnList(names)
+		query, sharedArgs, columnArgs, hasUK, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNil(err)
+		test.S(t).ExpectFalse(hasUK)
 		expected := `
-			update /* udup mydb.tbl */
+			update
 			  mydb.tbl
-					set id=?, name=?, position=?, age=?
+					set id=?, name=?, rank=?, position=?, age=?
 				where
-					((age = ?) and (position = ?) and (id = ?) and (name = ?))
+					((id = ?) and (name = ?) and (rank = ?) and (position = ?) and (age = ?))
+				limit 1
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", 17, 23}))
-		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{56, 17, 3, "testname"}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "newval", 17, 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(columnArgs, []interface{}{3, "testname", "findme", 17, 56}))
 	}
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		uniqueKeyColumns := NewColumnList([]string{"age", "surprise"})
-		_, _, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		tableColumns := newColumnList(names, "id")
+		sharedColumns := newColumnList([]string{"id", "name", "position", "surprise"})
+		_, _, _, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNotNil(err)
 	}
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		uniqueKeyColumns := NewColumnList([]string{})
-		_, _, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		tableColumns := newColumnList(names, "id")
+		sharedColumns := newColumnList([]string{})
+		_, _, _, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNotNil(err)
 	}
 	{
-		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-		mappedColumns := NewColumnList([]string{"id", "name", "role", "age"})
-		uniqueKeyColumns := NewColumnList([]string{"id"})
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, mappedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		tableColumns := newColumnList(names, "id")
+		mappedColumns := newColumnList([]string{"id", "name", "rank", "role", "age"})
+		query, sharedArgs, uniqueKeyArgs, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, mappedColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNil(err)
 		expected := `
-			update /* udup mydb.tbl */
+			update
 			  mydb.tbl
-					set id=?, name=?, role=?, age=?
+					set id=?, name=?, rank=?, role=?, age=?
 				where
 					((id = ?))
+				limit 1
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", 17, 23}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "newval", 17, 23}))
 		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{3}))
 	}
 }
@@ -536,41 +359,115 @@ func TestBuildDMLUpdateQuery(t *testing.T) {
 func TestBuildDMLUpdateQuerySignedUnsigned(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
-	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
-	valueArgs := []interface{}{3, "testname", "newval", int8(-17), int8(-2)}
-	whereArgs := []interface{}{3, "testname", "findme", int8(-3), 56}
-	sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
-	uniqueKeyColumns := NewColumnList([]string{"position"})
+	tableColumns := newColumnList([]string{"id", "name", "rank", "position", "age"}, "position")
+	valueArgs := newArgs(3, "testname", "newval", int8(-17), int8(-2))
+	whereArgs := newArgs(3, "testname", "findme", int8(-3), 56)
+	expected := `
+		update
+		  mydb.tbl
+				set id=?, name=?, rank=?, position=?, age=?
+			where
+				((position = ?))
+			limit 1
+	`
 	{
 		// test signed
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		query, sharedArgs, uniqueKeyArgs, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, nil, valueArgs, whereArgs)
 		test.S(t).ExpectNil(err)
-		expected := `
-			update /* udup mydb.tbl */
-			  mydb.tbl
-					set id=?, name=?, position=?, age=?
-				where
-					((position = ?))
-		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", int8(-17), int8(-2)}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "newval", int8(-17), int8(-2)}))
 		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{int8(-3)}))
 	}
 	{
 		// test unsigned
-		sharedColumns.SetUnsigned("age")
-		uniqueKeyColumns.SetUnsigned("position")
-		query, sharedArgs, uniqueKeyArgs, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns, valueArgs, whereArgs)
+		tableColumns.SetUnsigned("age")
+		tableColumns.SetUnsigned("position")
+		query, sharedArgs, uniqueKeyArgs, _, err := BuildDMLUpdateQuery(databaseName, tableName, tableColumns, tableColumns, tableColumns, nil, valueArgs, whereArgs)
+		test.S(t).ExpectNil(err)
+		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
+		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", "newval", uint8(239), uint8(254)}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{uint8(253)}))
+	}
+}
+
+func TestBuildDMLBatchDeleteQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	tableColumns := mysql.NewColumnList([]mysql.Column{
+		{RawName: "id", EscapedName: "`id`", Key: "PRI", Type: mysql.IntColumnType},
+		{RawName: "name", EscapedName: "`name`", Type: mysql.VarcharColumnType},
+	})
+	rowArgs := func(id int) []*interface{} {
+		var idArg, nameArg interface{} = id, fmt.Sprintf("name%d", id)
+		return []*interface{}{&idArg, &nameArg}
+	}
+	{
+		query, args, err := BuildDMLBatchDeleteQuery(databaseName, tableName, tableColumns, [][]*interface{}{rowArgs(1), rowArgs(2), rowArgs(3)})
 		test.S(t).ExpectNil(err)
 		expected := `
-			update /* udup mydb.tbl */
-			  mydb.tbl
-					set id=?, name=?, position=?, age=?
+			delete
+				from
+					mydb.tbl
 				where
-					((position = ?))
+					(id in (?, ?, ?))
 		`
 		test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
-		test.S(t).ExpectTrue(reflect.DeepEqual(sharedArgs, []interface{}{3, "testname", int8(-17), uint8(254)}))
-		test.S(t).ExpectTrue(reflect.DeepEqual(uniqueKeyArgs, []interface{}{uint8(253)}))
+		test.S(t).ExpectTrue(reflect.DeepEqual(args, []interface{}{1, 2, 3}))
+	}
+	{
+		// 10k deleted rows, batched as the applier does by default.
+		const nRows = 10000
+		const batchSize = 500
+		var rowByRowArgs, batchedArgs []interface{}
+		nRowByRow, nBatched := 0, 0
+		for start := 0; start < nRows; start += batchSize {
+			var argsList [][]*interface{}
+			for id := start; id < start+batchSize && id < nRows; id++ {
+				argsList = append(argsList, rowArgs(id))
+
+				_, uniqueKeyArgs, hasUK, err := BuildDMLDeleteQuery(databaseName, tableName, tableColumns, rowArgs(id))
+				test.S(t).ExpectNil(err)
+				test.S(t).ExpectTrue(hasUK)
+				rowByRowArgs = append(rowByRowArgs, uniqueKeyArgs...)
+				nRowByRow++
+			}
+			_, args, err := BuildDMLBatchDeleteQuery(databaseName, tableName, tableColumns, argsList)
+			test.S(t).ExpectNil(err)
+			batchedArgs = append(batchedArgs, args...)
+			nBatched++
+		}
+		test.S(t).ExpectEquals(nRowByRow, nRows)
+		test.S(t).ExpectEquals(nBatched, nRows/batchSize)
+		test.S(t).ExpectTrue(reflect.DeepEqual(batchedArgs, rowByRowArgs))
+	}
+	{
+		compositeColumns := mysql.NewColumnList([]mysql.Column{
+			{RawName: "id", EscapedName: "`id`", Key: "PRI", Type: mysql.IntColumnType},
+			{RawName: "name", EscapedName: "`name`", Key: "PRI", Type: mysql.VarcharColumnType},
+		})
+		test.S(t).ExpectTrue(GetSingleColumnPrimaryKey(compositeColumns) == nil)
+		_, _, err := BuildDMLBatchDeleteQuery(databaseName, tableName, compositeColumns, [][]*interface{}{rowArgs(1)})
+		test.S(t).ExpectNotNil(err)
 	}
 }
+
+func TestBuildDMLAppendQuery(t *testing.T) {
+	tableColumns := mysql.NewColumnList([]mysql.Column{
+		{RawName: "id", EscapedName: "`id`", Key: "PRI", Type: mysql.IntColumnType},
+		{RawName: "b", EscapedName: "`b`", Type: mysql.BlobColumnType},
+		{RawName: "ts", EscapedName: "`ts`", Type: mysql.TimestampColumnType, OnUpdate: true},
+	})
+	query, keyArgs, err := BuildDMLAppendQuery("mydb", "tbl", tableColumns, &tableColumns.Columns[1],
+		[]interface{}{3, []byte{}, "2001-02-03 04:05:06"})
+	test.S(t).ExpectNil(err)
+	expected := `
+		update
+				mydb.tbl
+			set
+				b = concat(b, ?), ts = ts
+			where
+				((id = ?))
+	`
+	test.S(t).ExpectEquals(normalizeQuery(query), normalizeQuery(expected))
+	test.S(t).ExpectTrue(reflect.DeepEqual(keyArgs, []interface{}{3}))
+}
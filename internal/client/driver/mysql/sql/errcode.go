@@ -7,6 +7,9 @@
 package sql
 
 import (
+	"context"
+	"strings"
+
 	"github.com/go-sql-driver/mysql"
 )
 
@@ -894,6 +897,12 @@ const (
 	ErrErrorLast                                                    = 1863
 )
 
+// Error codes of MySQL 5.7 and later.
+const (
+	// a SELECT interrupted by max_execution_time
+	ErrQueryTimeout uint16 = 3024
+)
+
 func IgnoreError(err error) bool {
 	mysqlErr, ok := err.(*mysql.MySQLError)
 	if !ok {
@@ -925,3 +934,71 @@ func IgnoreExistsError(err error) bool {
 		return false
 	}
 }
+
+// IsTruncateIllegalFk returns true if TRUNCATE TABLE is refused as the table is referenced by a foreign key.
+func IsTruncateIllegalFk(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && mysqlErr.Number == ErrTruncateIllegalFk
+}
+
+// IsUnknownSystemVariable returns true if err is of a variable the server does not have, e.g. @@aurora_version
+// on MySQL.
+func IsUnknownSystemVariable(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && mysqlErr.Number == ErrUnknownSystemVariable
+}
+
+// ErrEEWrite is EE_WRITE of mysys, e.g. "Error writing file ... (Errcode: 28)" of the binlog cache.
+const ErrEEWrite = 3
+
+// errnoNoSpace is ENOSPC, as in "Got error 28 from storage engine"
+const errnoNoSpace = "28"
+
+// IsDiskFull returns true if err is of the server failing to write for lack of space, e.g. on its disk.
+// It is not of the row being applied: the transaction can be applied again when space is freed.
+func IsDiskFull(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	if !ok {
+		return false
+	}
+	switch mysqlErr.Number {
+	case ErrDiskFull, ErrRecordFileFull:
+		return true
+	case ErrEEWrite, ErrErrorOnWrite, ErrGetErrno:
+		return strings.Contains(mysqlErr.Message, errnoNoSpace)
+	default:
+		return false
+	}
+}
+
+// IsRowError returns true if err is caused by the row being applied (e.g. a constraint violation),
+// and the transaction can continue without it.
+// Connection errors, lack of space, and lock errors which roll back the transaction or which are transient,
+// return false.
+func IsRowError(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	if !ok {
+		return false
+	}
+	if IsDiskFull(err) {
+		return false
+	}
+
+	switch mysqlErr.Number {
+	case ErrLockDeadlock, ErrLockWaitTimeout, ErrQueryInterrupted, ErrQueryTimeout:
+		return false
+	default:
+		return true
+	}
+}
+
+// IsStatementTimeout returns true if err is of a statement which has been waiting or running for too long, e.g.
+// on a lock held by another session, or interrupted by ApplyStatementTimeout. It is transient: the transaction
+// can be applied again.
+func IsStatementTimeout(err error) bool {
+	if err == context.DeadlineExceeded {
+		return true
+	}
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && (mysqlErr.Number == ErrLockWaitTimeout || mysqlErr.Number == ErrQueryTimeout)
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// sessionInitConnector opens connections by driver, and executes the statements on each of them
+// before it joins the pool.
+type sessionInitConnector struct {
+	driver     driver.Driver
+	dsn        string
+	statements []string
+}
+
+func (c *sessionInitConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	execer, ok := conn.(driver.Execer)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("session init: driver %T does not support Exec", c.driver)
+	}
+	for _, statement := range c.statements {
+		if _, err := execer.Exec(statement, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("session init: %v: %v", statement, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *sessionInitConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func openDBWithSessionInit(d driver.Driver, dsn string, statements []string) *gosql.DB {
+	db := gosql.OpenDB(&sessionInitConnector{driver: d, dsn: dsn, statements: statements})
+	db.SetConnMaxLifetime(ConnMaxLifetime)
+	return db
+}
+
+// CreateDBWithSessionInit is CreateDB, with the statements executed on each new connection
+// (including those replacing expired or broken ones).
+func CreateDBWithSessionInit(mysql_uri string, statements []string) (*gosql.DB, error) {
+	if len(statements) == 0 {
+		return CreateDB(mysql_uri)
+	}
+	if _, err := mysql.ParseDSN(mysql_uri); err != nil {
+		return nil, err
+	}
+	return openDBWithSessionInit(mysql.MySQLDriver{}, mysql_uri, statements), nil
+}
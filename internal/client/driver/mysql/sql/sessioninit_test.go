@@ -0,0 +1,151 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeBackend records the statements executed on each connection, like the backends behind a proxy.
+type fakeBackend struct {
+	lock  sync.Mutex
+	conns []*fakeConn
+	// the statement failing on Exec
+	failOn string
+}
+
+func (b *fakeBackend) Open(name string) (driver.Conn, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	conn := &fakeConn{backend: b}
+	b.conns = append(b.conns, conn)
+	return conn, nil
+}
+
+func (b *fakeBackend) nConns() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.conns)
+}
+
+func (b *fakeBackend) statements(id int) []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]string(nil), b.conns[id].statements...)
+}
+
+func (b *fakeBackend) breakConn(id int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.conns[id].broken = true
+}
+
+type fakeConn struct {
+	backend    *fakeBackend
+	broken     bool
+	statements []string
+}
+
+func (c *fakeConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	c.backend.lock.Lock()
+	defer c.backend.lock.Unlock()
+	if c.broken {
+		return nil, driver.ErrBadConn
+	}
+	if query == c.backend.failOn {
+		return nil, errors.New("failed")
+	}
+	c.statements = append(c.statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.backend.lock.Lock()
+	defer c.backend.lock.Unlock()
+	if c.broken {
+		return nil, driver.ErrBadConn
+	}
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func TestSessionInit_connChurn(t *testing.T) {
+	backend := &fakeBackend{}
+	sessionInit := []string{"SET @@session.foreign_key_checks = 0", "SET @a = 1"}
+	db := openDBWithSessionInit(backend, "", sessionInit)
+	defer db.Close()
+
+	conns, err := CreateConns(db, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := conns[0].Db
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("insert 1"); err != nil {
+		t.Fatal(err)
+	}
+	// The connection breaks mid-transaction, e.g. the backend is restarted.
+	backend.breakConn(0)
+	if _, err := tx.Exec("insert 2"); err == nil {
+		t.Fatal("Exec() on a broken connection succeeded")
+	}
+	tx.Rollback()
+	if _, err := conn.ExecContext(context.Background(), "insert 3"); err == nil {
+		t.Fatal("Exec() on a broken connection succeeded")
+	}
+	conn.Close()
+
+	// The rest of the transaction has not been moved to another connection.
+	if got, want := backend.statements(0), append(sessionInit, "insert 1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements on the first connection = %v, want %v", got, want)
+	}
+	nConns := backend.nConns()
+	for id := 1; id < nConns; id++ {
+		if got := backend.statements(id); len(got) != 0 {
+			t.Errorf("statements on connection %v = %v, want none", id, got)
+		}
+	}
+
+	// A new connection replacing the broken one has the session initialized.
+	conns, err = CreateConns(db, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conns[0].Db.Close()
+	if _, err := conns[0].Db.ExecContext(context.Background(), "insert 4"); err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]string{}, sessionInit...), "SET @@session.foreign_key_checks = 0", "insert 4")
+	if got := backend.statements(nConns); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements on the new connection = %v, want %v", got, want)
+	}
+}
+
+func TestSessionInit_fail(t *testing.T) {
+	backend := &fakeBackend{failOn: "SET @a = 1"}
+	db := openDBWithSessionInit(backend, "", []string{"SET @a = 1"})
+	defer db.Close()
+	if err := db.Ping(); err == nil || err.Error() != "session init: SET @a = 1: failed" {
+		t.Errorf("Ping() error = %v, want the failure of session init", err)
+	}
+}
@@ -15,6 +15,9 @@ import (
 	"github.com/actiontech/dtle/internal/config"
 	"github.com/actiontech/dtle/internal/config/mysql"
 	"github.com/actiontech/dtle/internal/g"
+	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -169,7 +172,44 @@ func CreateDB(mysql_uri string) (*gosql.DB, error) {
 	return db, nil
 }
 
-func CreateConns(db *gosql.DB, count int) ([]*Conn, error) {
+// UriWithTimeZone sets the session time_zone of connections of uri, if timeZone is not empty.
+func UriWithTimeZone(uri string, timeZone string) string {
+	if timeZone == "" {
+		return uri
+	}
+	// https://github.com/go-sql-driver/mysql#system-variables
+	return fmt.Sprintf("%s&time_zone=%s", uri, url.QueryEscape("'"+timeZone+"'"))
+}
+
+var sessionVarNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// UriWithSessionVars sets the session variables of connections of uri, e.g. {"sql_mode": "STRICT_ALL_TABLES"}.
+// Values other than numbers are quoted, unless already quoted.
+// An unknown variable fails the connection, and so the first query.
+func UriWithSessionVars(uri string, vars map[string]string) (string, error) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if !sessionVarNameRegexp.MatchString(name) {
+			return "", fmt.Errorf("bad session variable name: %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := vars[name]
+		if _, err := strconv.ParseFloat(value, 64); err != nil && !strings.HasPrefix(value, "'") {
+			value = "'" + EscapeValue(value) + "'"
+		}
+		// https://github.com/go-sql-driver/mysql#system-variables
+		uri = fmt.Sprintf("%s&%s=%s", uri, name, url.QueryEscape(value))
+	}
+	return uri, nil
+}
+
+// CreateConns takes count connections out of the pool of db. A Conn stays on its connection: transactions and
+// session variables are not moved to another one (or, behind a proxy, another backend). If the connection
+// breaks, statements fail rather than being retried on another connection, and the Conn is to be replaced.
+func CreateConns(db *gosql.DB, count int, disableForeignKeyChecks bool) ([]*Conn, error) {
 	conns := make([]*Conn, count)
 	for i := 0; i < count; i++ {
 		conn, err := db.Conn(context.Background())
@@ -177,9 +217,11 @@ func CreateConns(db *gosql.DB, count int) ([]*Conn, error) {
 			return nil, err
 		}
 
-		_, err = conn.ExecContext(context.Background(), "SET @@session.foreign_key_checks = 0")
-		if err != nil {
-			return nil, err
+		if disableForeignKeyChecks {
+			_, err = conn.ExecContext(context.Background(), "SET @@session.foreign_key_checks = 0")
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		conns[i] = &Conn{
@@ -353,7 +395,13 @@ func Args(args ...interface{}) []interface{} {
 //INSERT INTO {{ .Name }} VALUES {{ .Values }};
 //UNLOCK TABLES;
 
+// ShowDatabases returns the schemas on the server, except config.SystemSchemas and the dtle schema.
 func ShowDatabases(db *gosql.DB) ([]string, error) {
+	return ShowDatabasesExcept(db, config.SystemSchemas)
+}
+
+// ShowDatabasesExcept returns the schemas on the server, except the excluded ones and the dtle schema.
+func ShowDatabasesExcept(db *gosql.DB, excluded []string) ([]string, error) {
 	dbs := make([]string, 0)
 
 	// Get table list
@@ -369,16 +417,23 @@ func ShowDatabases(db *gosql.DB) ([]string, error) {
 		if err := rows.Scan(&database); err != nil {
 			return dbs, err
 		}
-		switch strings.ToLower(database.String) {
-		case "sys", "mysql", "information_schema", "performance_schema", g.DtleSchemaName:
+		if strings.ToLower(database.String) == g.DtleSchemaName || containsFold(excluded, database.String) {
 			continue
-		default:
-			dbs = append(dbs, database.String)
 		}
+		dbs = append(dbs, database.String)
 	}
 	return dbs, rows.Err()
 }
 
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
 func ShowTables(db *gosql.DB, dbName string, showType bool) (tables []*config.Table, err error) {
 	// Get table list
 	var query string
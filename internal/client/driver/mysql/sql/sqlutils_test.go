@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestUriWithSessionVars(t *testing.T) {
+	uri := "root:pass@tcp(127.0.0.1:3306)/?timeout=5s"
+	got, err := UriWithSessionVars(uri, map[string]string{
+		"sql_mode":                 "STRICT_ALL_TABLES,NO_ZERO_DATE",
+		"net_read_timeout":         "60",
+		"time_zone":                "'+08:00'",
+		"innodb_lock_wait_timeout": "it's",
+	})
+	if err != nil {
+		t.Fatalf("UriWithSessionVars() error = %v", err)
+	}
+	cfg, err := mysql.ParseDSN(got)
+	if err != nil {
+		t.Fatalf("ParseDSN(%v) error = %v", got, err)
+	}
+	want := map[string]string{
+		"sql_mode":                 "'STRICT_ALL_TABLES,NO_ZERO_DATE'",
+		"net_read_timeout":         "60",
+		"time_zone":                "'+08:00'",
+		"innodb_lock_wait_timeout": `'it\'s'`,
+	}
+	for name, value := range want {
+		if cfg.Params[name] != value {
+			t.Errorf("%v = %v, want %v", name, cfg.Params[name], value)
+		}
+	}
+
+	if got, err := UriWithSessionVars(uri, nil); err != nil || got != uri {
+		t.Errorf("UriWithSessionVars(nil) = %v, %v, want %v", got, err, uri)
+	}
+	if _, err := UriWithSessionVars(uri, map[string]string{"sql_mode=''&x": "1"}); err == nil {
+		t.Error("UriWithSessionVars() with a bad name, want an error")
+	}
+}
@@ -0,0 +1,359 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+
+	"github.com/actiontech/dtle/internal/config/mysql"
+)
+
+const (
+	tunnelDialTimeout = 10 * time.Second
+	// the interval of the keepalive requests to an SSH jump host. A channel through SSH has no deadline,
+	// so a broken SSH connection is found by them, and closed to fail the reads of the channels.
+	sshKeepAliveInterval = 30 * time.Second
+)
+
+// tunnelSeq numbers the networks registered to go-sql-driver/mysql, which cannot be unregistered.
+var tunnelSeq int64
+
+// tunnels are the open tunnels by their network.
+var tunnels = struct {
+	sync.Mutex
+	m map[string]*Tunnel
+}{m: map[string]*Tunnel{}}
+
+// Tunnel dials a MySQL server through a SOCKS5 proxy or an SSH jump host.
+// It is registered as a network of go-sql-driver/mysql, used by the DSNs of the connection config,
+// and is the Dialer of the binlog connection (see Dialer). All connections to the server
+// (e.g. the dump and the binlog connections) share it.
+type Tunnel struct {
+	logger  *logrus.Entry
+	cfg     *mysql.TunnelConfig
+	target  string
+	network string
+
+	// socks5 dials through the SOCKS5 proxy, if Socks5 is set
+	socks5 proxy.Dialer
+	// sshConfig and sshAddr are those of the SSH jump host, if SSHHost is set
+	sshConfig *ssh.ClientConfig
+	sshAddr   string
+
+	lock      sync.Mutex
+	sshClient *ssh.Client
+	closed    bool
+}
+
+// NewTunnel establishes the tunnel of the connection config, if any, and makes
+// the connection config use it. It returns nil if there is no tunnel.
+func NewTunnel(cfg *mysql.ConnectionConfig, logger *logrus.Entry) (*Tunnel, error) {
+	if cfg.Tunnel == nil {
+		return nil, nil
+	}
+	t := &Tunnel{
+		logger: logger,
+		cfg:    cfg.Tunnel,
+		target: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+	}
+	var err error
+	switch {
+	case cfg.Tunnel.Socks5 != "" && cfg.Tunnel.SSHHost != "":
+		return nil, fmt.Errorf("tunnel: only one of Socks5 and SSHHost should be set")
+	case cfg.Tunnel.Socks5 != "":
+		var auth *proxy.Auth
+		if cfg.Tunnel.Socks5User != "" {
+			auth = &proxy.Auth{User: cfg.Tunnel.Socks5User, Password: cfg.Tunnel.Socks5Password}
+		}
+		t.socks5, err = proxy.SOCKS5("tcp", cfg.Tunnel.Socks5, auth, &net.Dialer{Timeout: tunnelDialTimeout})
+		if err != nil {
+			return nil, fmt.Errorf("tunnel: %v", err)
+		}
+	case cfg.Tunnel.SSHHost != "":
+		if err := t.initSSH(); err != nil {
+			return nil, fmt.Errorf("tunnel: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("tunnel: either Socks5 or SSHHost should be set")
+	}
+
+	// Fail at startup if the server is unreachable.
+	if err := t.check(); err != nil {
+		t.Close()
+		return nil, fmt.Errorf("tunnel: cannot reach %v: %v", t.target, err)
+	}
+
+	t.network = fmt.Sprintf("dtle-tunnel-%d", atomic.AddInt64(&tunnelSeq, 1))
+	tunnels.Lock()
+	tunnels.m[t.network] = t
+	tunnels.Unlock()
+	gomysql.RegisterDial(t.network, t.dial)
+	cfg.SetTunnelNetwork(t.network)
+	t.logger.Infof("tunnel: connecting to %v through %v", t.target, t.via())
+	return t, nil
+}
+
+func (t *Tunnel) via() string {
+	if t.socks5 != nil {
+		return "SOCKS5 proxy " + t.cfg.Socks5
+	}
+	return "SSH host " + t.sshAddr
+}
+
+// Dialer returns the dialer of the binlog connection (replication.BinlogSyncerConfig.Dialer) of
+// a connection config on which NewTunnel has been called, or nil if it has no tunnel.
+func Dialer(cfg *mysql.ConnectionConfig) func(ctx context.Context, network, address string) (net.Conn, error) {
+	tunnels.Lock()
+	t, ok := tunnels.m[cfg.TunnelNetwork()]
+	tunnels.Unlock()
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context, _, address string) (net.Conn, error) {
+		return t.dial(address)
+	}
+}
+
+// dial is the DialFunc of go-sql-driver/mysql. It connects to addr, i.e. Host:Port of the connection config.
+func (t *Tunnel) dial(addr string) (net.Conn, error) {
+	t.lock.Lock()
+	closed := t.closed
+	t.lock.Unlock()
+	if closed {
+		return nil, fmt.Errorf("tunnel: closed")
+	}
+	if t.socks5 != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tunnelDialTimeout)
+		defer cancel()
+		return t.socks5.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	}
+	return t.dialSSH(addr)
+}
+
+// check dials the server through the tunnel and waits for the server greeting.
+func (t *Tunnel) check() error {
+	conn, err := t.dial(t.target)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		errCh <- err
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(tunnelDialTimeout):
+		return fmt.Errorf("timeout waiting for the server greeting")
+	}
+}
+
+// Close closes the SSH connection, if any. The connections through the tunnel are closed with it.
+// The network stays registered to go-sql-driver/mysql and fails to dial.
+func (t *Tunnel) Close() error {
+	if t == nil {
+		return nil
+	}
+	if t.network != "" {
+		tunnels.Lock()
+		delete(tunnels.m, t.network)
+		tunnels.Unlock()
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	if t.sshClient != nil {
+		err := t.sshClient.Close()
+		t.sshClient = nil
+		return err
+	}
+	return nil
+}
+
+func (t *Tunnel) initSSH() error {
+	t.sshAddr = t.cfg.SSHHost
+	if _, _, err := net.SplitHostPort(t.sshAddr); err != nil {
+		t.sshAddr = net.JoinHostPort(t.sshAddr, "22")
+	}
+
+	hostKeyCallback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		t.logger.Warnf("tunnel: SSHHostKey is not set. accepting the host key of %v %v %v",
+			hostname, key.Type(), ssh.FingerprintSHA256(key))
+		return nil
+	}
+	if t.cfg.SSHHostKey != "" {
+		hostKey, err := parseSSHHostKey(t.cfg.SSHHostKey)
+		if err != nil {
+			return err
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+
+	userName := t.cfg.SSHUser
+	if userName == "" {
+		u, err := user.Current()
+		if err != nil {
+			return fmt.Errorf("SSHUser is not set: %v", err)
+		}
+		userName = u.Username
+	}
+	signer, err := loadSSHKey(t.cfg.SSHKeyFile)
+	if err != nil {
+		return err
+	}
+
+	t.sshConfig = &ssh.ClientConfig{
+		User:            userName,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         tunnelDialTimeout,
+	}
+	return nil
+}
+
+// loadSSHKey reads the private key of keyFile, or of the default key files of the agent user if empty.
+func loadSSHKey(keyFile string) (ssh.Signer, error) {
+	files := []string{keyFile}
+	if keyFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("SSHKeyFile is not set: %v", err)
+		}
+		files = nil
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			files = append(files, filepath.Join(home, ".ssh", name))
+		}
+	}
+	var lastErr error
+	for _, file := range files {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(pem)
+		if err != nil {
+			return nil, fmt.Errorf("bad SSH key %v: %v", file, err)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("no SSH key: %v", lastErr)
+}
+
+// parseSSHHostKey parses a public key as a line of authorized_keys, or of known_hosts.
+func parseSSHHostKey(s string) (ssh.PublicKey, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(s))
+	if err != nil {
+		_, _, key, _, _, err = ssh.ParseKnownHosts([]byte(s))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad SSHHostKey: %v", err)
+	}
+	return key, nil
+}
+
+// dialSSH connects to addr through the SSH jump host. The SSH connection is shared by the connections
+// through it, and established again once if it is broken.
+func (t *Tunnel) dialSSH(addr string) (net.Conn, error) {
+	var err error
+	for i := 0; i < 2; i++ {
+		var client *ssh.Client
+		client, err = t.getSSHClient()
+		if err != nil {
+			return nil, err
+		}
+		var conn net.Conn
+		conn, err = client.Dial("tcp", addr)
+		if err == nil {
+			return conn, nil
+		}
+		t.dropSSHClient(client)
+	}
+	return nil, err
+}
+
+func (t *Tunnel) getSSHClient() (*ssh.Client, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return nil, fmt.Errorf("tunnel: closed")
+	}
+	if t.sshClient != nil {
+		return t.sshClient, nil
+	}
+	client, err := ssh.Dial("tcp", t.sshAddr, t.sshConfig)
+	if err != nil {
+		return nil, err
+	}
+	t.sshClient = client
+	go t.keepAlive(client)
+	return client, nil
+}
+
+// dropSSHClient closes a broken SSH connection, for the next dial to establish it again.
+func (t *Tunnel) dropSSHClient(client *ssh.Client) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.sshClient == client {
+		t.sshClient = nil
+	}
+	client.Close()
+}
+
+func (t *Tunnel) keepAlive(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(sshKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			replied := make(chan error, 1)
+			go func() {
+				_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+				replied <- err
+			}()
+			select {
+			case err := <-replied:
+				if err == nil {
+					continue
+				}
+				t.logger.Errorf("tunnel: SSH host %v is lost: %v", t.sshAddr, err)
+			case <-time.After(tunnelDialTimeout):
+				t.logger.Errorf("tunnel: SSH host %v is lost: no reply to keepalive", t.sshAddr)
+			}
+			t.dropSSHClient(client)
+			return
+		}
+	}
+}
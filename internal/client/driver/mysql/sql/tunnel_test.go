@@ -0,0 +1,328 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package sql
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// fakeServer sends a greeting and echoes.
+func fakeServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte("G"))
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return l
+}
+
+// fakeSocks5 is a SOCKS5 proxy requiring user "u" and password "p".
+// It records the requested target.
+func fakeSocks5(t *testing.T, targets chan<- string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 512)
+				// greeting
+				io.ReadFull(conn, buf[:2])
+				io.ReadFull(conn, buf[:buf[1]])
+				conn.Write([]byte{0x05, 0x02})
+				// user/password
+				io.ReadFull(conn, buf[:2])
+				user := make([]byte, buf[1])
+				io.ReadFull(conn, user)
+				io.ReadFull(conn, buf[:1])
+				password := make([]byte, buf[0])
+				io.ReadFull(conn, password)
+				if string(user) != "u" || string(password) != "p" {
+					conn.Write([]byte{0x01, 0x01})
+					return
+				}
+				conn.Write([]byte{0x01, 0x00})
+				// connect with an IPv4 address
+				io.ReadFull(conn, buf[:4])
+				io.ReadFull(conn, buf[:6])
+				target := net.JoinHostPort(net.IP(buf[:4]).String(), strconv.Itoa(int(buf[4])<<8|int(buf[5])))
+				targets <- target
+				remote, err := net.Dial("tcp", target)
+				if err != nil {
+					conn.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer remote.Close()
+				conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0})
+				go io.Copy(remote, conn)
+				io.Copy(conn, remote)
+			}()
+		}
+	}()
+	return l
+}
+
+// fakeSSH is an SSH server accepting clientKey, and forwarding direct-tcpip channels.
+// It records the requested targets.
+func fakeSSH(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey, targets chan<- string) net.Listener {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "dtle" && string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(hostKey)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					if newChan.ChannelType() != "direct-tcpip" {
+						newChan.Reject(ssh.UnknownChannelType, "")
+						continue
+					}
+					// host string, port uint32, origin host string, origin port uint32
+					data := newChan.ExtraData()
+					hostLen := binary.BigEndian.Uint32(data)
+					host := string(data[4 : 4+hostLen])
+					port := binary.BigEndian.Uint32(data[4+hostLen:])
+					target := net.JoinHostPort(host, strconv.Itoa(int(port)))
+					targets <- target
+					remote, err := net.Dial("tcp", target)
+					if err != nil {
+						newChan.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					ch, chReqs, err := newChan.Accept()
+					if err != nil {
+						remote.Close()
+						continue
+					}
+					go ssh.DiscardRequests(chReqs)
+					go func() {
+						defer ch.Close()
+						defer remote.Close()
+						go io.Copy(remote, ch)
+						io.Copy(ch, remote)
+					}()
+				}
+			}()
+		}
+	}()
+	return l
+}
+
+func newSSHKey(t *testing.T) (*ecdsa.PrivateKey, ssh.Signer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, signer
+}
+
+// pingThroughTunnel dials the fake server through the tunnel of cfg.
+func pingThroughTunnel(t *testing.T, cfg *mysql.ConnectionConfig) {
+	dialer := Dialer(cfg)
+	if dialer == nil {
+		t.Fatalf("Dialer() = nil, want the tunnel")
+	}
+	conn, err := dialer(context.Background(), "tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("ping"))
+	got := make([]byte, 5)
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "Gping" {
+		t.Errorf("got %q through the tunnel, want %q", got, "Gping")
+	}
+}
+
+func TestNewTunnel_Socks5(t *testing.T) {
+	server := fakeServer(t)
+	defer server.Close()
+	targets := make(chan string, 10)
+	proxy := fakeSocks5(t, targets)
+	defer proxy.Close()
+
+	serverAddr := server.Addr().(*net.TCPAddr)
+	cfg := &mysql.ConnectionConfig{
+		Host: "127.0.0.1",
+		Port: serverAddr.Port,
+		Tunnel: &mysql.TunnelConfig{
+			Socks5:         proxy.Addr().String(),
+			Socks5User:     "u",
+			Socks5Password: "p",
+		},
+	}
+	tunnel, err := NewTunnel(cfg, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("NewTunnel() error = %v", err)
+	}
+	defer tunnel.Close()
+
+	if cfg.TunnelNetwork() == "" {
+		t.Fatalf("TunnelNetwork() is empty, want the tunnel")
+	}
+	pingThroughTunnel(t, cfg)
+	// one for the check at startup, one for the connection
+	for i := 0; i < 2; i++ {
+		if target := <-targets; target != serverAddr.String() {
+			t.Errorf("proxy target = %v, want %v", target, serverAddr)
+		}
+	}
+
+	tunnel.Close()
+	if Dialer(cfg) != nil {
+		t.Errorf("Dialer() of a closed tunnel, want nil")
+	}
+}
+
+func TestNewTunnel_SSH(t *testing.T) {
+	server := fakeServer(t)
+	defer server.Close()
+	_, hostKey := newSSHKey(t)
+	clientKey, clientSigner := newSSHKey(t)
+	targets := make(chan string, 10)
+	jumpHost := fakeSSH(t, hostKey, clientSigner.PublicKey(), targets)
+	defer jumpHost.Close()
+
+	dir, err := ioutil.TempDir("", "dtle-tunnel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	der, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "id_ecdsa")
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	serverAddr := server.Addr().(*net.TCPAddr)
+	cfg := &mysql.ConnectionConfig{
+		Host: "127.0.0.1",
+		Port: serverAddr.Port,
+		Tunnel: &mysql.TunnelConfig{
+			SSHHost:    jumpHost.Addr().String(),
+			SSHUser:    "dtle",
+			SSHKeyFile: keyFile,
+			SSHHostKey: string(ssh.MarshalAuthorizedKey(hostKey.PublicKey())),
+		},
+	}
+	tunnel, err := NewTunnel(cfg, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("NewTunnel() error = %v", err)
+	}
+	defer tunnel.Close()
+
+	pingThroughTunnel(t, cfg)
+	for i := 0; i < 2; i++ {
+		if target := <-targets; target != serverAddr.String() {
+			t.Errorf("SSH target = %v, want %v", target, serverAddr)
+		}
+	}
+
+	// the SSH connection is established again if it is broken
+	tunnel.lock.Lock()
+	tunnel.sshClient.Close()
+	tunnel.lock.Unlock()
+	pingThroughTunnel(t, cfg)
+
+	_, otherKey := newSSHKey(t)
+	cfg.Tunnel.SSHHostKey = string(ssh.MarshalAuthorizedKey(otherKey.PublicKey()))
+	if _, err := NewTunnel(cfg, logrus.NewEntry(logrus.New())); err == nil {
+		t.Errorf("NewTunnel() with a wrong SSHHostKey expects an error")
+	}
+}
+
+func TestNewTunnel_Fail(t *testing.T) {
+	targets := make(chan string, 10)
+	proxy := fakeSocks5(t, targets)
+	defer proxy.Close()
+
+	tests := []struct {
+		name   string
+		tunnel *mysql.TunnelConfig
+	}{
+		{"wrong password", &mysql.TunnelConfig{Socks5: proxy.Addr().String(), Socks5User: "u", Socks5Password: "x"}},
+		{"proxy unreachable", &mysql.TunnelConfig{Socks5: "127.0.0.1:1"}},
+		{"nothing set", &mysql.TunnelConfig{}},
+		{"both set", &mysql.TunnelConfig{Socks5: proxy.Addr().String(), SSHHost: "bastion"}},
+		{"ssh key missing", &mysql.TunnelConfig{SSHHost: "127.0.0.1:1", SSHUser: "dtle", SSHKeyFile: "/nonexistent"}},
+		{"bad ssh host key", &mysql.TunnelConfig{SSHHost: "127.0.0.1:1", SSHUser: "dtle", SSHHostKey: "bad"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mysql.ConnectionConfig{Host: "127.0.0.1", Port: 3306, Tunnel: tt.tunnel}
+			if _, err := NewTunnel(cfg, logrus.NewEntry(logrus.New())); err == nil {
+				t.Errorf("NewTunnel() expects an error")
+			}
+			if network := cfg.TunnelNetwork(); network != "" {
+				t.Errorf("TunnelNetwork() = %v, want none", network)
+			}
+		})
+	}
+}
@@ -365,10 +365,43 @@ func removeDuplicate(c []string) []string {
 	return result
 }
 
+// moveColumn moves col of table to position, i.e. FIRST or AFTER a column. It returns false if
+// the column after which to move is not found.
+func moveColumn(table *ast.CreateTableStmt, col *ast.ColumnDef, position *ast.ColumnPosition) bool {
+	if position == nil || position.Tp == ast.ColumnPositionNone {
+		return true
+	}
+	var cols []*ast.ColumnDef
+	for _, c := range table.Cols {
+		if c != col {
+			cols = append(cols, c)
+		}
+	}
+	i := 0
+	if position.Tp == ast.ColumnPositionAfter {
+		i = -1
+		for j, c := range cols {
+			if c.Name.Name.L == position.RelativeColumn.Name.L {
+				i = j + 1
+				break
+			}
+		}
+		if i < 0 {
+			return false
+		}
+	}
+	cols = append(cols, nil)
+	copy(cols[i+1:], cols[i:])
+	cols[i] = col
+	table.Cols = cols
+	return true
+}
+
 func mergeAlterToTable(oldTable *ast.CreateTableStmt, alterTable *ast.AlterTableStmt) (*ast.CreateTableStmt, error) {
 	newTable := &ast.CreateTableStmt{
-		Table:       oldTable.Table,
-		Cols:        oldTable.Cols,
+		Table: oldTable.Table,
+		// a copy, as the columns are moved in place
+		Cols:        append([]*ast.ColumnDef{}, oldTable.Cols...),
 		Constraints: oldTable.Constraints,
 		Options:     oldTable.Options,
 		Partition:   oldTable.Partition,
@@ -399,6 +432,9 @@ func mergeAlterToTable(oldTable *ast.CreateTableStmt, alterTable *ast.AlterTable
 		if !colExists {
 			return oldTable, nil
 		}
+		if !moveColumn(newTable, spec.NewColumns[0], spec.Position) {
+			return oldTable, nil
+		}
 	}
 	for _, spec := range getAlterTableSpecByTp(alterTable.Specs, ast.AlterTableModifyColumn) {
 		colExists := false
@@ -411,6 +447,9 @@ func mergeAlterToTable(oldTable *ast.CreateTableStmt, alterTable *ast.AlterTable
 		if !colExists {
 			return oldTable, nil
 		}
+		if !moveColumn(newTable, spec.NewColumns[0], spec.Position) {
+			return oldTable, nil
+		}
 	}
 	for _, spec := range getAlterTableSpecByTp(alterTable.Specs, ast.AlterTableAlterColumn) {
 		colExists := false
@@ -455,6 +494,9 @@ func mergeAlterToTable(oldTable *ast.CreateTableStmt, alterTable *ast.AlterTable
 				return oldTable, nil
 			}
 			newTable.Cols = append(newTable.Cols, newCol)
+			if !moveColumn(newTable, newCol, spec.Position) {
+				return oldTable, nil
+			}
 		}
 	}
 
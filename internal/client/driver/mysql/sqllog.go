@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+const (
+	// the default of MySQLDriverConfig.LogAppliedSQLRateLimit, statements per second
+	logAppliedSQLRateLimitDefault = 100
+	// what the values of the LogAppliedSQLRedact columns are logged as
+	redactedValue = "***"
+)
+
+func validateLogAppliedSQL(cfg *config.MySQLDriverConfig) (errs []error) {
+	if cfg.LogAppliedSQLRateLimit < 0 {
+		errs = append(errs, fmt.Errorf("bad job argument: LogAppliedSQLRateLimit=%v. should be 0 (default) or positive",
+			cfg.LogAppliedSQLRateLimit))
+	}
+	for _, name := range cfg.LogAppliedSQLRedact {
+		if parts := strings.Split(name, "."); len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			errs = append(errs, fmt.Errorf("bad job argument: LogAppliedSQLRedact: %v. should be schema.table.column", name))
+		}
+	}
+	return errs
+}
+
+// appliedSQLLogger logs the statements applied by the incremental copy, see MySQLDriverConfig.LogAppliedSQL.
+// The statements over the rate limit are not logged, but counted in the next one logged.
+type appliedSQLLogger struct {
+	logger  *logrus.Entry
+	limiter *eventRateLimiter
+	// schema.table.column
+	redact map[string]bool
+	// the number of statements not logged since the last one logged
+	skipped int64
+}
+
+// newAppliedSQLLogger returns nil if LogAppliedSQL is not set.
+func newAppliedSQLLogger(cfg *config.MySQLDriverConfig, logger *logrus.Entry) *appliedSQLLogger {
+	if !cfg.LogAppliedSQL {
+		return nil
+	}
+	rate := cfg.LogAppliedSQLRateLimit
+	if rate == 0 {
+		rate = logAppliedSQLRateLimitDefault
+	}
+	l := &appliedSQLLogger{
+		logger:  logger,
+		limiter: newEventRateLimiter(rate),
+		redact:  make(map[string]bool),
+	}
+	for _, name := range cfg.LogAppliedSQLRedact {
+		l.redact[name] = true
+	}
+	return l
+}
+
+// logStatement logs a statement applied on schemaName.tableName, with its args, of the transaction gtid.
+// rows are the values of the rows of the statement, in columns, by which the args of the LogAppliedSQLRedact
+// columns are found.
+func (l *appliedSQLLogger) logStatement(gtid string, schemaName string, tableName string, columns *umconf.ColumnList,
+	rows []*umconf.ColumnValues, query string, args []interface{}) {
+
+	if l == nil {
+		return
+	}
+	if !l.limiter.allow(time.Now()) {
+		atomic.AddInt64(&l.skipped, 1)
+		return
+	}
+	if columns != nil {
+		query, args = l.redactValues(schemaName, tableName, columns, rows, query, args)
+	}
+	fields := logrus.Fields{
+		"gtid":  gtid,
+		"table": fmt.Sprintf("%v.%v", schemaName, tableName),
+		"sql":   query,
+	}
+	if len(args) > 0 {
+		fields["args"] = fmt.Sprintf("%v", args)
+	}
+	if skipped := atomic.SwapInt64(&l.skipped, 0); skipped > 0 {
+		fields["skipped"] = skipped
+	}
+	l.logger.WithFields(fields).Debugf("mysql.applier: applied sql")
+}
+
+// redactValues replaces the values of the LogAppliedSQLRedact columns in args, and in query if written in it
+// (e.g. a BINARY primary key). A value is matched as the argument it is converted to, so other arguments of
+// the same value are replaced as well.
+func (l *appliedSQLLogger) redactValues(schemaName string, tableName string, columns *umconf.ColumnList,
+	rows []*umconf.ColumnValues, query string, args []interface{}) (string, []interface{}) {
+
+	var redacted []interface{}
+	for i := range columns.Columns {
+		column := &columns.Columns[i]
+		if !l.redact[fmt.Sprintf("%v.%v.%v", schemaName, tableName, column.RawName)] {
+			continue
+		}
+		for _, row := range rows {
+			if row == nil || i >= len(row.AbstractValues) || row.AbstractValues[i] == nil || *row.AbstractValues[i] == nil {
+				continue
+			}
+			redacted = append(redacted, column.ConvertArg(*row.AbstractValues[i]))
+		}
+	}
+	if len(redacted) == 0 {
+		return query, args
+	}
+
+	logged := make([]interface{}, len(args))
+	copy(logged, args)
+	for _, value := range redacted {
+		for j := range logged {
+			if reflect.DeepEqual(logged[j], value) {
+				logged[j] = redactedValue
+			}
+		}
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case []byte:
+			text = string(v)
+		default:
+			continue
+		}
+		if text != "" {
+			query = strings.Replace(query, fmt.Sprintf("'%v'", text), fmt.Sprintf("'%v'", redactedValue), -1)
+		}
+	}
+	return query, logged
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func Test_appliedSQLLogger(t *testing.T) {
+	if l := newAppliedSQLLogger(&config.MySQLDriverConfig{}, nil); l != nil {
+		t.Fatalf("newAppliedSQLLogger() without LogAppliedSQL = %v, want nil", l)
+	}
+	// nil-safe
+	var nilLogger *appliedSQLLogger
+	nilLogger.logStatement("", "db1", "t1", nil, nil, "delete from t1", nil)
+
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Level = logrus.DebugLevel
+	l := newAppliedSQLLogger(&config.MySQLDriverConfig{
+		LogAppliedSQL:          true,
+		LogAppliedSQLRateLimit: 1,
+		LogAppliedSQLRedact:    []string{"db1.t1.secret"},
+	}, logrus.NewEntry(logger))
+
+	columns := umconf.NewColumnList([]umconf.Column{{RawName: "id"}, {RawName: "secret"}})
+	l.logStatement("sid:1", "db1", "t1", columns, []*umconf.ColumnValues{nil, shardTestValues(int64(1), "p@ss")},
+		"replace into `db1`.`t1` (`id`, `secret`) values (?, ?)", []interface{}{int64(1), "p@ss"})
+	logged := buf.String()
+	for _, want := range []string{"gtid=\"sid:1\"", "table=db1.t1", "replace into", "args=\"[1 ***]\""} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("logged %q, want %q", logged, want)
+		}
+	}
+	if strings.Contains(logged, "p@ss") {
+		t.Errorf("logged %q, want the value of secret redacted", logged)
+	}
+
+	// over the rate limit
+	buf.Reset()
+	l.logStatement("sid:2", "db1", "t1", nil, nil, "delete from t1", nil)
+	l.logStatement("sid:3", "db1", "t1", nil, nil, "delete from t1", nil)
+	if buf.Len() != 0 {
+		t.Errorf("logged %q over the rate limit", buf.String())
+	}
+	if l.skipped != 2 {
+		t.Errorf("skipped = %v, want 2", l.skipped)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func validateTablePriority(cfg *config.MySQLDriverConfig) (errs []error) {
+	for name := range cfg.TablePriority {
+		if parts := strings.Split(name, "."); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("bad job argument: TablePriority: %v. should be schema.table", name))
+		}
+	}
+	return errs
+}
+
+// orderByTablePriority returns the events of a transaction ordered by MySQLDriverConfig.TablePriority.
+// The rows are reordered only within runs of inserts and updates, in the order of priority, and within runs
+// of deletes, in the reverse order. Statements (e.g. a SAVEPOINT) and the runs are kept in place, and the rows
+// of a table keep their order. So a row deleted and then inserted again stays so, but an update changing a
+// key referenced by another table might still be applied too early or too late.
+// The order is the same when applied again to the result.
+func orderByTablePriority(events []binlog.DataEvent, priority map[string]int) []binlog.DataEvent {
+	if len(priority) == 0 {
+		return events
+	}
+	ordered := make([]binlog.DataEvent, len(events))
+	copy(ordered, events)
+	priorityOf := func(event *binlog.DataEvent) int {
+		p := priority[fmt.Sprintf("%v.%v", event.DatabaseName, event.TableName)]
+		if event.DML == binlog.DeleteDML {
+			return -p
+		}
+		return p
+	}
+	for start := 0; start < len(ordered); {
+		if ordered[start].DML == binlog.NotDML {
+			start++
+			continue
+		}
+		isDelete := ordered[start].DML == binlog.DeleteDML
+		end := start + 1
+		for end < len(ordered) && ordered[end].DML != binlog.NotDML &&
+			(ordered[end].DML == binlog.DeleteDML) == isDelete {
+			end++
+		}
+		run := ordered[start:end]
+		sort.SliceStable(run, func(i, j int) bool {
+			return priorityOf(&run[i]) < priorityOf(&run[j])
+		})
+		start = end
+	}
+	return ordered
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+)
+
+// fkTestDest is a destination with a parent table and a child table referencing it, with foreign key checks on.
+// The rows are id to parent id (child) or to 0 (parent).
+type fkTestDest map[string]map[int64]int64
+
+func (dest fkTestDest) apply(event *binlog.DataEvent) error {
+	var id, parentID int64
+	if event.NewColumnValues != nil {
+		values := event.NewColumnValues.AbstractValues
+		id, parentID = (*values[0]).(int64), (*values[1]).(int64)
+	} else {
+		id = (*event.WhereColumnValues.AbstractValues[0]).(int64)
+	}
+	rows := dest[event.TableName]
+	switch event.DML {
+	case binlog.InsertDML:
+		if event.TableName == "child" {
+			if _, ok := dest["parent"][parentID]; !ok {
+				return fmt.Errorf("insert of child %v: no parent %v", id, parentID)
+			}
+		}
+		rows[id] = parentID
+	case binlog.DeleteDML:
+		if event.TableName == "parent" {
+			for childID, p := range dest["child"] {
+				if p == id {
+					return fmt.Errorf("delete of parent %v: referenced by child %v", id, childID)
+				}
+			}
+		}
+		delete(rows, id)
+	}
+	return nil
+}
+
+func newFKTestEvent(table string, dml binlog.EventDML, id int64, parentID int64) binlog.DataEvent {
+	event := binlog.NewDataEvent("db1", table, dml, 2)
+	values := binlog.ToColumnValuesV2([]interface{}{id, parentID}, nil)
+	if dml == binlog.DeleteDML {
+		event.WhereColumnValues = values
+	} else {
+		event.NewColumnValues = values
+	}
+	return event
+}
+
+func TestOrderByTablePriority(t *testing.T) {
+	priority := map[string]int{"db1.parent": 0, "db1.child": 1}
+	insertParent := func(id int64) binlog.DataEvent { return newFKTestEvent("parent", binlog.InsertDML, id, 0) }
+	deleteParent := func(id int64) binlog.DataEvent { return newFKTestEvent("parent", binlog.DeleteDML, id, 0) }
+	insertChild := func(id, p int64) binlog.DataEvent { return newFKTestEvent("child", binlog.InsertDML, id, p) }
+	deleteChild := func(id, p int64) binlog.DataEvent { return newFKTestEvent("child", binlog.DeleteDML, id, p) }
+	savepoint := binlog.NewQueryEvent("db1", "SAVEPOINT s1", binlog.NotDML)
+	savepoint.Savepoint = true
+
+	// transactions of a source without the foreign key, in an order failing on the destination
+	txs := [][]binlog.DataEvent{
+		{insertChild(10, 1), insertChild(11, 1), insertParent(1), insertChild(20, 2), insertParent(2)},
+		{deleteParent(1), deleteChild(10, 1), deleteChild(11, 1)},
+		// parent 2 is deleted and inserted again: the order of its rows is kept
+		{deleteParent(2), deleteChild(20, 2), insertParent(2), insertChild(21, 2)},
+		// rows are not moved across a savepoint
+		{insertChild(30, 3), savepoint, insertParent(3)},
+	}
+	wantErr := []bool{false, false, false, true}
+
+	apply := func(dest fkTestDest, events []binlog.DataEvent) error {
+		for j := range events {
+			if events[j].DML == binlog.NotDML {
+				continue
+			}
+			if err := dest.apply(&events[j]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	dest := fkTestDest{"parent": {}, "child": {}}
+	for i, tx := range txs {
+		unordered := fkTestDest{"parent": {}, "child": {}}
+		for table, rows := range dest {
+			for id, p := range rows {
+				unordered[table][id] = p
+			}
+		}
+		if err := apply(unordered, tx); err == nil {
+			t.Errorf("tx %v without TablePriority: want a foreign key error", i)
+		}
+
+		events := orderByTablePriority(tx, priority)
+		if again := orderByTablePriority(events, priority); !reflect.DeepEqual(again, events) {
+			t.Errorf("tx %v: the order changes when applied again", i)
+		}
+		if err := apply(dest, events); (err != nil) != wantErr[i] {
+			t.Errorf("tx %v with TablePriority: err = %v, want error %v", i, err, wantErr[i])
+		}
+	}
+	want := fkTestDest{"parent": {2: 0}, "child": {21: 2}}
+	if !reflect.DeepEqual(dest, want) {
+		t.Errorf("destination = %v, want %v", dest, want)
+	}
+
+	events := txs[0]
+	if got := orderByTablePriority(events, nil); &got[0] != &events[0] {
+		t.Errorf("orderByTablePriority() without TablePriority copied the events")
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+const (
+	cgroupRoot            = "/sys/fs/cgroup"
+	throttleCheckInterval = time.Second
+	// while the memory is short, a chunk of the full copy has at most 1/throttledChunkDivisor of ChunkSize rows,
+	// and the next chunk is read only after the previous ones are sent.
+	throttledChunkDivisor = 4
+	// while the CPU is short, the full copy waits this long before reading each chunk.
+	throttledChunkDelay = 200 * time.Millisecond
+	// a cgroup v1 limit at least this large means no limit.
+	cgroupNoLimit = int64(1) << 62
+)
+
+// cgroupStats is the resources of the cgroup dtle runs in. A limit of 0 means no limit.
+type cgroupStats struct {
+	memoryUsage int64 // bytes
+	memoryLimit int64
+	cpuUsage    time.Duration // accumulated CPU time
+	cpuLimit    float64       // CPUs
+}
+
+// readCgroupStats reads the cgroup mounted at root, which is that of the container dtle runs in.
+// Both cgroup v1 and v2 are supported.
+func readCgroupStats(root string) (s cgroupStats, err error) {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return readCgroupV2Stats(root)
+	}
+	if s.memoryUsage, err = readCgroupInt(filepath.Join(root, "memory", "memory.usage_in_bytes")); err != nil {
+		return s, err
+	}
+	if s.memoryLimit, err = readCgroupInt(filepath.Join(root, "memory", "memory.limit_in_bytes")); err != nil {
+		return s, err
+	}
+	if s.memoryLimit >= cgroupNoLimit {
+		s.memoryLimit = 0
+	}
+	cpuUsage, err := readCgroupInt(filepath.Join(root, "cpuacct", "cpuacct.usage"))
+	if err != nil {
+		return s, err
+	}
+	s.cpuUsage = time.Duration(cpuUsage)
+	quota, err := readCgroupInt(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return s, err
+	}
+	period, err := readCgroupInt(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return s, err
+	}
+	if quota > 0 && period > 0 {
+		s.cpuLimit = float64(quota) / float64(period)
+	}
+	return s, nil
+}
+
+func readCgroupV2Stats(root string) (s cgroupStats, err error) {
+	if s.memoryUsage, err = readCgroupInt(filepath.Join(root, "memory.current")); err != nil {
+		return s, err
+	}
+	if s.memoryLimit, err = readCgroupInt(filepath.Join(root, "memory.max")); err != nil {
+		return s, err
+	}
+	bs, err := ioutil.ReadFile(filepath.Join(root, "cpu.stat"))
+	if err != nil {
+		return s, err
+	}
+	for _, line := range strings.Split(string(bs), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "usage_usec" {
+			usec, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return s, fmt.Errorf("bad cpu.stat %v: %v", line, err)
+			}
+			s.cpuUsage = time.Duration(usec) * time.Microsecond
+		}
+	}
+	// "$MAX $PERIOD", where $MAX may be "max". The file is absent in the root cgroup.
+	bs, err = ioutil.ReadFile(filepath.Join(root, "cpu.max"))
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	if fields := strings.Fields(string(bs)); len(fields) == 2 && fields[0] != "max" {
+		quota, err1 := strconv.ParseInt(fields[0], 10, 64)
+		period, err2 := strconv.ParseInt(fields[1], 10, 64)
+		if err1 != nil || err2 != nil || period <= 0 {
+			return s, fmt.Errorf("bad cpu.max %v", string(bs))
+		}
+		s.cpuLimit = float64(quota) / float64(period)
+	}
+	return s, nil
+}
+
+// readCgroupInt reads a file of a single number. "max" is read as 0, i.e. no limit.
+func readCgroupInt(path string) (int64, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(bs))
+	if s == "max" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad %v: %v", path, err)
+	}
+	return n, nil
+}
+
+// resourceThrottler watches the memory and CPU usage of the cgroup, and slows down the full copy when
+// they exceed ThrottleMemoryPct / ThrottleCPUPct of the limits. See dumper.throttle.
+type resourceThrottler struct {
+	logger    *logrus.Entry
+	root      string
+	memoryPct int
+	cpuPct    int
+
+	lock  sync.Mutex
+	stats models.ThrottleStats
+	// of the last check, for the CPU usage over the interval
+	lastCPUUsage time.Duration
+	lastCheck    time.Time
+}
+
+// newResourceThrottler returns nil if neither ThrottleMemoryPct nor ThrottleCPUPct is set.
+func newResourceThrottler(cfg *config.MySQLDriverConfig, root string, logger *logrus.Entry) *resourceThrottler {
+	if cfg.ThrottleMemoryPct <= 0 && cfg.ThrottleCPUPct <= 0 {
+		return nil
+	}
+	return &resourceThrottler{
+		logger:    logger,
+		root:      root,
+		memoryPct: cfg.ThrottleMemoryPct,
+		cpuPct:    cfg.ThrottleCPUPct,
+	}
+}
+
+func validateThrottlePct(name string, pct int) error {
+	if pct < 0 || pct > 100 {
+		return fmt.Errorf("bad job argument: %v=%v. should be 0 (disabled) to 100", name, pct)
+	}
+	return nil
+}
+
+// run checks the usage every throttleCheckInterval until shutdownCh is closed.
+func (t *resourceThrottler) run(shutdownCh chan struct{}) {
+	ticker := time.NewTicker(throttleCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := t.check(time.Now()); err != nil {
+			t.logger.Warnf("mysql.extractor: failed to read the cgroup for throttling. not throttling. err: %v", err)
+			return
+		}
+		select {
+		case <-shutdownCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *resourceThrottler) check(now time.Time) error {
+	s, err := readCgroupStats(t.root)
+	if err != nil {
+		return err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	old := t.stats
+	t.stats.MemoryUsageMB = s.memoryUsage / 1024 / 1024
+	t.stats.MemoryLimitMB = s.memoryLimit / 1024 / 1024
+	t.stats.Memory = t.memoryPct > 0 && s.memoryLimit > 0 && s.memoryUsage*100 >= s.memoryLimit*int64(t.memoryPct)
+
+	cpuLimit := s.cpuLimit
+	if cpuLimit == 0 {
+		cpuLimit = float64(runtime.NumCPU())
+	}
+	if !t.lastCheck.IsZero() && now.After(t.lastCheck) {
+		t.stats.CPUPct = 100 * float64(s.cpuUsage-t.lastCPUUsage) / float64(now.Sub(t.lastCheck)) / cpuLimit
+		t.stats.CPU = t.cpuPct > 0 && t.stats.CPUPct >= float64(t.cpuPct)
+	}
+	t.lastCPUUsage = s.cpuUsage
+	t.lastCheck = now
+
+	if t.stats.Memory != old.Memory || t.stats.CPU != old.CPU {
+		t.logger.WithFields(logrus.Fields{
+			"memory_mb":       t.stats.MemoryUsageMB,
+			"memory_limit_mb": t.stats.MemoryLimitMB,
+			"cpu_pct":         int(t.stats.CPUPct),
+		}).Infof("mysql.extractor: throttling the full copy. memory: %v, cpu: %v", t.stats.Memory, t.stats.CPU)
+	}
+	return nil
+}
+
+func (t *resourceThrottler) getStats() models.ThrottleStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.stats
+}
+
+// throttle is called before reading each chunk. While the memory is short, it reduces the chunk size and
+// waits for the chunks read to be sent. While the CPU is short, it waits a little.
+// It returns false if the dumper is closed meanwhile.
+func (d *dumper) throttle() bool {
+	if d.throttler == nil {
+		return true
+	}
+	stats := d.throttler.getStats()
+	if stats.CPU {
+		if !d.sleep(throttledChunkDelay) {
+			return false
+		}
+	}
+	if !d.oldWayDump {
+		// OFFSET of the old way depends on the chunk size, which must not change then.
+		d.chunkSize = d.fullChunkSize
+		if stats.Memory {
+			d.chunkSize = d.fullChunkSize / throttledChunkDivisor
+			if d.chunkSize < 1 {
+				d.chunkSize = 1
+			}
+		}
+	}
+	for stats.Memory && len(d.resultsChannel) > 0 {
+		if !d.sleep(throttleCheckInterval / 10) {
+			return false
+		}
+		stats = d.throttler.getStats()
+	}
+	return true
+}
+
+func (d *dumper) sleep(duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.shutdownCh:
+		return false
+	}
+}
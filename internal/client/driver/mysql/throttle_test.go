@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func writeCgroupFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_readCgroupStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	v1 := filepath.Join(dir, "v1")
+	writeCgroupFiles(t, v1, map[string]string{
+		"memory/memory.usage_in_bytes": "104857600\n",
+		"memory/memory.limit_in_bytes": "9223372036854771712\n", // no limit
+		"cpuacct/cpuacct.usage":        "3000000000\n",
+		"cpu/cpu.cfs_quota_us":         "200000\n",
+		"cpu/cpu.cfs_period_us":        "100000\n",
+	})
+	v2 := filepath.Join(dir, "v2")
+	writeCgroupFiles(t, v2, map[string]string{
+		"cgroup.controllers": "cpu memory\n",
+		"memory.current":     "104857600\n",
+		"memory.max":         "209715200\n",
+		"cpu.stat":           "usage_usec 3000000\nuser_usec 2000000\nsystem_usec 1000000\n",
+		"cpu.max":            "max 100000\n",
+	})
+
+	tests := []struct {
+		root string
+		want cgroupStats
+	}{
+		{v1, cgroupStats{memoryUsage: 100 << 20, memoryLimit: 0, cpuUsage: 3 * time.Second, cpuLimit: 2}},
+		{v2, cgroupStats{memoryUsage: 100 << 20, memoryLimit: 200 << 20, cpuUsage: 3 * time.Second, cpuLimit: 0}},
+	}
+	for _, tt := range tests {
+		got, err := readCgroupStats(tt.root)
+		if err != nil {
+			t.Errorf("readCgroupStats(%v) error = %v", tt.root, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("readCgroupStats(%v) = %+v, want %+v", tt.root, got, tt.want)
+		}
+	}
+
+	if _, err := readCgroupStats(filepath.Join(dir, "none")); err == nil {
+		t.Errorf("readCgroupStats() of no cgroup: want an error")
+	}
+}
+
+// The memory target is set artificially low, so that the full copy is throttled.
+func Test_dumper_throttle(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers": "cpu memory\n",
+		"memory.current":     "20971520\n",
+		"memory.max":         "1073741824\n",
+		"cpu.stat":           "usage_usec 1000000\n",
+		"cpu.max":            "100000 100000\n",
+	})
+
+	logger := logrus.NewEntry(logrus.New())
+	throttler := newResourceThrottler(&config.MySQLDriverConfig{ThrottleMemoryPct: 1, ThrottleCPUPct: 90}, root, logger)
+	t0 := time.Unix(1500000000, 0)
+	if err := throttler.check(t0); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	stats := throttler.getStats()
+	if !stats.Memory || stats.CPU || stats.MemoryUsageMB != 20 || stats.MemoryLimitMB != 1024 {
+		t.Fatalf("getStats() = %+v, want memory throttled at 20 of 1024 MB", stats)
+	}
+
+	d := NewDumper(nil, config.NewTable("db1", "t1"), 2000, logger)
+	d.throttler = throttler
+	if !d.throttle() || d.chunkSize != 2000/throttledChunkDivisor {
+		t.Fatalf("throttle(): chunkSize = %v, want %v", d.chunkSize, 2000/throttledChunkDivisor)
+	}
+
+	// The next chunk waits for the buffered ones to be sent.
+	d.resultsChannel <- &DumpEntry{}
+	done := make(chan bool)
+	go func() {
+		done <- d.throttle()
+	}()
+	select {
+	case <-done:
+		t.Fatalf("throttle() returned with a chunk buffered")
+	case <-time.After(300 * time.Millisecond):
+	}
+	<-d.resultsChannel
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatalf("throttle() = false")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("throttle() did not return after the chunk was sent")
+	}
+
+	// 0.95s of CPU in 1s of a limit of 1 CPU
+	writeCgroupFiles(t, root, map[string]string{
+		"memory.current": "1048576\n",
+		"cpu.stat":       "usage_usec 1950000\n",
+	})
+	if err := throttler.check(t0.Add(time.Second)); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	stats = throttler.getStats()
+	if stats.Memory || !stats.CPU || stats.CPUPct < 94 || stats.CPUPct > 96 {
+		t.Fatalf("getStats() = %+v, want CPU throttled at 95%%", stats)
+	}
+	start := time.Now()
+	if !d.throttle() || d.chunkSize != 2000 {
+		t.Fatalf("throttle(): chunkSize = %v, want 2000", d.chunkSize)
+	}
+	if elapsed := time.Since(start); elapsed < throttledChunkDelay {
+		t.Errorf("throttle() waited %v with the CPU throttled, want %v", elapsed, throttledChunkDelay)
+	}
+
+	d.Close()
+	if d.throttle() {
+		t.Errorf("throttle() = true after Close()")
+	}
+}
@@ -24,6 +24,8 @@ type DumpEntry struct {
 	RowsCount                int64
 	Err                      string
 	Table                    []byte
+	Checkpoint               []byte
+	EncryptedColumns         []byte
 }
 
 func (d *DumpEntry) Size() (s uint64) {
@@ -231,6 +233,36 @@ func (d *DumpEntry) Size() (s uint64) {
 		}
 		s += l
 	}
+	{
+		l := uint64(len(d.Checkpoint))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+		l := uint64(len(d.EncryptedColumns))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
 	s += 16
 	return
 }
@@ -528,6 +560,44 @@ func (d *DumpEntry) Marshal(buf []byte) ([]byte, error) {
 		copy(buf[i+16:], d.Table)
 		i += l
 	}
+	{
+		l := uint64(len(d.Checkpoint))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+16] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+16] = byte(t)
+			i++
+
+		}
+		copy(buf[i+16:], d.Checkpoint)
+		i += l
+	}
+	{
+		l := uint64(len(d.EncryptedColumns))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+16] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+16] = byte(t)
+			i++
+
+		}
+		copy(buf[i+16:], d.EncryptedColumns)
+		i += l
+	}
 	return buf[:i+16], nil
 }
 
@@ -828,5 +898,55 @@ func (d *DumpEntry) Unmarshal(buf []byte) (uint64, error) {
 		copy(d.Table, buf[i+16:])
 		i += l
 	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+16] & 0x7F)
+			for buf[i+16]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+16]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.Checkpoint)) >= l {
+			d.Checkpoint = d.Checkpoint[:l]
+		} else {
+			d.Checkpoint = make([]byte, l)
+		}
+		copy(d.Checkpoint, buf[i+16:])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+16] & 0x7F)
+			for buf[i+16]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+16]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.EncryptedColumns)) >= l {
+			d.EncryptedColumns = d.EncryptedColumns[:l]
+		} else {
+			d.EncryptedColumns = make([]byte, l)
+		}
+		copy(d.EncryptedColumns, buf[i+16:])
+		i += l
+	}
 	return i + 16, nil
 }
@@ -0,0 +1,331 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// Values of ColumnTypeCheck.Level
+const (
+	// the values are written to the destination as they are, e.g. INT to BIGINT
+	TypeCheckSafe = "safe"
+	// some values might be truncated, rounded or out of range, e.g. BIGINT to INT
+	TypeCheckWarn = "warn"
+	// the values can not be written to the destination, e.g. DATETIME to INT, or a missing column
+	TypeCheckError = "error"
+)
+
+var typeCheckLevelRanks = map[string]int{TypeCheckSafe: 0, TypeCheckWarn: 1, TypeCheckError: 2}
+
+// ColumnTypeCheck is a column of a replicated table which differs between the source and the destination.
+// See CheckColumnTypes.
+type ColumnTypeCheck struct {
+	// the source table
+	TableSchema string `json:"table_schema"`
+	TableName   string `json:"table_name"`
+	ColumnName  string `json:"column_name"`
+	// COLUMN_TYPE of information_schema.COLUMNS. Empty if the column is missing on the side.
+	SourceType string `json:"source_type"`
+	DestType   string `json:"dest_type"`
+	// TypeCheckSafe, TypeCheckWarn or TypeCheckError
+	Level  string `json:"level"`
+	Reason string `json:"reason"`
+}
+
+// columnSchema is a column in information_schema.COLUMNS.
+type columnSchema struct {
+	name       string
+	columnType string
+	charset    string
+	nullable   bool
+	// the column gets a value without being written, by a default, AUTO_INCREMENT or as a generated column
+	hasDefault bool
+}
+
+// CheckColumnTypes compares the columns of each table to be replicated by a job, in information_schema.COLUMNS
+// of the source of srcCfg and of the destination of destCfg, and returns the columns which differ. Columns
+// of the same type are not returned. The tables are resolved like PreviewTables does, and renamed by
+// TableSchemaRename and TableRename. ColumnDefault and ColumnTypeOverride of destCfg are taken into account.
+// Nothing is changed on the source or the destination.
+func CheckColumnTypes(srcCfg *config.MySQLDriverConfig, destCfg *config.MySQLDriverConfig,
+	logger *logrus.Logger) ([]*ColumnTypeCheck, error) {
+
+	destDB, err := sql.CreateDB(destCfg.ConnectionConfig.GetDBUri())
+	if err != nil {
+		return nil, err
+	}
+	defer destDB.Close()
+
+	var checks []*ColumnTypeCheck
+	err = inspectSourceTables(srcCfg, logger, func(e *Extractor) error {
+		for _, db := range e.replicateDoDb {
+			for _, tb := range db.Tables {
+				destSchema, destTable := db.TableSchema, tb.TableName
+				if tb.TableSchemaRename != "" {
+					destSchema = tb.TableSchemaRename
+				}
+				if tb.TableRename != "" {
+					destTable = tb.TableRename
+				}
+				source, err := readColumnSchemas(e.db, db.TableSchema, tb.TableName)
+				if err != nil {
+					return err
+				}
+				dest, err := readColumnSchemas(destDB, destSchema, destTable)
+				if err != nil {
+					return err
+				}
+				if len(dest) == 0 {
+					checks = append(checks, missingTableCheck(srcCfg, db.TableSchema, tb.TableName, destSchema, destTable))
+					continue
+				}
+				checks = append(checks, compareTableColumns(db.TableSchema, tb.TableName, source, dest,
+					config.FindColumnDefault(destCfg.ColumnDefault, destSchema, destTable),
+					config.FindColumnTypeOverride(destCfg.ColumnTypeOverride, destSchema, destTable))...)
+			}
+		}
+		return nil
+	})
+	return checks, err
+}
+
+func readColumnSchemas(db *gosql.DB, schemaName string, tableName string) (columns []*columnSchema, err error) {
+	query := `select COLUMN_NAME, COLUMN_TYPE, ifnull(CHARACTER_SET_NAME, '') as CHARACTER_SET_NAME,
+		IS_NULLABLE = 'YES' as NULLABLE, COLUMN_DEFAULT is not null or EXTRA <> '' as HAS_DEFAULT
+		from information_schema.columns where table_schema = ? and table_name = ? order by ORDINAL_POSITION`
+	err = sql.QueryRowsMap(db, query, func(m sql.RowMap) error {
+		columns = append(columns, &columnSchema{
+			name:       m.GetString("COLUMN_NAME"),
+			columnType: m.GetString("COLUMN_TYPE"),
+			charset:    m.GetString("CHARACTER_SET_NAME"),
+			nullable:   m.GetBool("NULLABLE"),
+			hasDefault: m.GetBool("HAS_DEFAULT"),
+		})
+		return nil
+	}, schemaName, tableName)
+	return columns, err
+}
+
+// missingTableCheck reports a table to be replicated which does not exist on the destination.
+func missingTableCheck(srcCfg *config.MySQLDriverConfig, schemaName string, tableName string,
+	destSchema string, destTable string) *ColumnTypeCheck {
+
+	check := &ColumnTypeCheck{TableSchema: schemaName, TableName: tableName, Level: TypeCheckSafe,
+		Reason: fmt.Sprintf("table %v.%v is not on the destination. created by the full copy", destSchema, destTable)}
+	if srcCfg.SkipCreateDbTable {
+		check.Level = TypeCheckError
+		check.Reason = fmt.Sprintf("table %v.%v is not on the destination, and SkipCreateDbTable is set",
+			destSchema, destTable)
+	}
+	return check
+}
+
+// compareTableColumns matches the columns of a table on the source and on the destination by name, and
+// returns the ones which differ. Destination columns in columnDefault are filled by the applier.
+func compareTableColumns(schemaName string, tableName string, source []*columnSchema, dest []*columnSchema,
+	columnDefault *config.ColumnDefault, override *config.ColumnTypeOverride) (checks []*ColumnTypeCheck) {
+
+	destColumns := make(map[string]*columnSchema, len(dest))
+	for _, c := range dest {
+		destColumns[strings.ToLower(c.name)] = c
+	}
+	sourceColumns := make(map[string]bool, len(source))
+	for _, s := range source {
+		sourceColumns[strings.ToLower(s.name)] = true
+		check := &ColumnTypeCheck{TableSchema: schemaName, TableName: tableName, ColumnName: s.name,
+			SourceType: s.columnType}
+		d, ok := destColumns[strings.ToLower(s.name)]
+		if !ok {
+			check.Level, check.Reason = TypeCheckError, "not on the destination"
+			checks = append(checks, check)
+			continue
+		}
+		check.DestType = d.columnType
+		if castType, ok := columnTypeOverrideOf(override, s.name); ok {
+			check.Level, check.Reason = TypeCheckSafe, fmt.Sprintf("converted by ColumnTypeOverride %v", castType)
+			if s.nullable && !d.nullable {
+				check.Level, check.Reason = TypeCheckError, check.Reason+"; NULL is not allowed on the destination"
+			}
+			checks = append(checks, check)
+			continue
+		}
+		if check.Level, check.Reason = classifyColumn(s, d); check.Reason != "" {
+			checks = append(checks, check)
+		}
+	}
+	for _, d := range dest {
+		if sourceColumns[strings.ToLower(d.name)] || d.nullable || d.hasDefault {
+			continue
+		}
+		if columnDefault != nil {
+			if _, ok := columnDefault.Columns[d.name]; ok {
+				continue
+			}
+		}
+		checks = append(checks, &ColumnTypeCheck{TableSchema: schemaName, TableName: tableName, ColumnName: d.name,
+			DestType: d.columnType, Level: TypeCheckError,
+			Reason: "not on the source, and NOT NULL without a default on the destination. set it in ColumnDefault"})
+	}
+	return checks
+}
+
+func columnTypeOverrideOf(override *config.ColumnTypeOverride, columnName string) (string, bool) {
+	if override == nil {
+		return "", false
+	}
+	castType, ok := override.Columns[columnName]
+	return castType, ok
+}
+
+// classifyColumn compares the type, the nullability and the charset of a source column with the destination
+// column. reason is empty if they are the same.
+func classifyColumn(source *columnSchema, dest *columnSchema) (level string, reason string) {
+	level = TypeCheckSafe
+	var reasons []string
+	add := func(l string, r string) {
+		if typeCheckLevelRanks[l] > typeCheckLevelRanks[level] {
+			level = l
+		}
+		reasons = append(reasons, r)
+	}
+
+	if l, r := classifyColumnType(source.columnType, dest.columnType); r != "" {
+		add(l, r)
+	}
+	if source.nullable && !dest.nullable {
+		add(TypeCheckError, "NULL is not allowed on the destination")
+	}
+	if source.charset != "" && dest.charset != "" && !strings.EqualFold(source.charset, dest.charset) {
+		if isCharsetSuperset(dest.charset, source.charset) {
+			add(TypeCheckSafe, fmt.Sprintf("charset %v -> %v", source.charset, dest.charset))
+		} else {
+			add(TypeCheckWarn, fmt.Sprintf("charset %v -> %v. some characters might not be representable",
+				source.charset, dest.charset))
+		}
+	}
+	return level, strings.Join(reasons, "; ")
+}
+
+// isCharsetSuperset tells whether all characters of charset are in superset.
+func isCharsetSuperset(superset string, charset string) bool {
+	superset, charset = strings.ToLower(superset), strings.ToLower(charset)
+	switch {
+	case superset == "utf8mb4" || charset == "ascii":
+		return true
+	case superset == "utf8" || superset == "utf8mb3":
+		return charset != "utf8mb4"
+	}
+	return false
+}
+
+var (
+	integerTypeDigits = map[string]int{"tinyint": 3, "smallint": 5, "mediumint": 8, "int": 10, "integer": 10,
+		"bigint": 20}
+	numberTypes = map[string]bool{"decimal": true, "numeric": true, "float": true, "double": true, "real": true}
+	binaryTypes = map[string]bool{"binary": true, "varbinary": true, "tinyblob": true, "blob": true,
+		"mediumblob": true, "longblob": true}
+	textTypes = map[string]bool{"char": true, "varchar": true, "tinytext": true, "text": true,
+		"mediumtext": true, "longtext": true, "enum": true, "set": true}
+	dateTypes = map[string]bool{"date": true, "datetime": true, "timestamp": true, "time": true, "year": true}
+)
+
+// columnTypeKind returns the kind of a type name, within which the values are converted by MySQL.
+func columnTypeKind(name string) string {
+	switch {
+	case integerTypeDigits[name] > 0 || numberTypes[name] || name == "bit":
+		return "number"
+	case textTypes[name]:
+		return "text"
+	case binaryTypes[name]:
+		return "binary"
+	case dateTypes[name]:
+		return "temporal"
+	}
+	return name
+}
+
+// classifyColumnType compares a type of the source with the one of the destination (COLUMN_TYPE).
+// reason is empty if they are the same.
+func classifyColumnType(sourceType string, destType string) (level string, reason string) {
+	if strings.EqualFold(strings.TrimSpace(sourceType), strings.TrimSpace(destType)) {
+		return TypeCheckSafe, ""
+	}
+	src, dest := parseColumnType(sourceType), parseColumnType(destType)
+	srcKind, destKind := columnTypeKind(src.name), columnTypeKind(dest.name)
+	switch {
+	case src.name == dest.name && (src.name == "enum" || src.name == "set"):
+		// the values are written by their names, see Column.DecodeEnumValue
+		if missing := missingEnumValues(sourceType, destType); len(missing) > 0 {
+			return TypeCheckWarn, fmt.Sprintf("%v members missing on the destination: %v",
+				src.name, strings.Join(missing, ","))
+		}
+		return TypeCheckSafe, fmt.Sprintf("%v members added or reordered", src.name)
+	case isLossyNarrowing(sourceType, destType):
+		return TypeCheckWarn, "narrowed. values might be truncated, rounded or out of range"
+	case isWidening(src, dest):
+		return TypeCheckSafe, "widened"
+	case src.name == "datetime" && dest.name == "timestamp":
+		return TypeCheckWarn, "TIMESTAMP is limited to 1970-2038, and converted by the time zone"
+	case srcKind == destKind:
+		return TypeCheckWarn, fmt.Sprintf("converted from %v to %v", src.name, dest.name)
+	case srcKind == "number" && destKind == "text",
+		srcKind == "text" && destKind == "binary", srcKind == "binary" && destKind == "text",
+		srcKind == "json" && (destKind == "text" || destKind == "binary"),
+		(srcKind == "text" || srcKind == "binary") && destKind == "json":
+		return TypeCheckWarn, fmt.Sprintf("converted from %v to %v", src.name, dest.name)
+	}
+	return TypeCheckError, fmt.Sprintf("%v can not be converted to %v", src.name, dest.name)
+}
+
+// isWidening tells whether all values of the type src are kept in dest, which is not narrowing (see isLossyNarrowing).
+func isWidening(src columnTypeInfo, dest columnTypeInfo) bool {
+	srcDigits, destDigits := integerTypeDigits[src.name], integerTypeDigits[dest.name]
+	switch {
+	case srcDigits > 0 && destDigits > 0:
+		return true
+	case srcDigits > 0 && (dest.name == "decimal" || dest.name == "numeric"):
+		return dest.length-dest.scale >= srcDigits && (!dest.unsigned || src.unsigned)
+	case binaryTypes[src.name] != binaryTypes[dest.name]:
+		return false
+	case stringTypes[src.name] && stringTypes[dest.name], lobTypeRanks[src.name] > 0 && lobTypeRanks[dest.name] > 0:
+		return true
+	case stringTypes[src.name] && lobTypeRanks[dest.name] > 0:
+		return lobTypeRanks[dest.name] > 1 // TINYTEXT and TINYBLOB are of 255 bytes
+	case (src.name == "decimal" || src.name == "numeric") && (dest.name == "decimal" || dest.name == "numeric"):
+		return true
+	case src.name == "float" && dest.name == "double":
+		return true
+	case src.name == "date" && dest.name == "datetime":
+		return true
+	case src.name == "bit" && dest.name == "bit":
+		return dest.length >= src.length
+	}
+	return src.name == dest.name && temporalTypes[src.name]
+}
+
+// missingEnumValues returns the members of an ENUM or SET of the source which are not in the one of the destination.
+func missingEnumValues(sourceType string, destType string) (missing []string) {
+	members := make(map[string]bool)
+	for _, v := range umconf.ParseEnumValues(destType) {
+		members[v] = true
+	}
+	for _, v := range umconf.ParseEnumValues(sourceType) {
+		if !members[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/actiontech/dtle/internal/config"
+)
+
+func Test_classifyColumnType(t *testing.T) {
+	tests := []struct {
+		source string
+		dest   string
+		want   string
+	}{
+		{"int(11)", "INT(11)", TypeCheckSafe},
+		{"int(11)", "bigint(20)", TypeCheckSafe},
+		{"bigint(20)", "int(11)", TypeCheckWarn},
+		{"int(11)", "int(10) unsigned", TypeCheckWarn},
+		{"int(11)", "decimal(12,2)", TypeCheckSafe},
+		{"bigint(20)", "decimal(12,2)", TypeCheckWarn},
+		{"decimal(10,2)", "decimal(12,4)", TypeCheckSafe},
+		{"decimal(10,2)", "double", TypeCheckWarn},
+		{"varchar(10)", "varchar(20)", TypeCheckSafe},
+		{"varchar(100)", "text", TypeCheckSafe},
+		{"varchar(100)", "tinytext", TypeCheckWarn},
+		{"text", "varchar(100)", TypeCheckWarn},
+		{"varchar(10)", "varbinary(10)", TypeCheckWarn},
+		{"datetime(6)", "datetime(3)", TypeCheckWarn},
+		{"date", "datetime", TypeCheckSafe},
+		{"datetime", "timestamp", TypeCheckWarn},
+		{"enum('a','b')", "enum('b','a','c')", TypeCheckSafe},
+		{"enum('a','b')", "enum('a')", TypeCheckWarn},
+		{"int(11)", "varchar(20)", TypeCheckWarn},
+		{"json", "longtext", TypeCheckWarn},
+		{"datetime", "int(11)", TypeCheckError},
+		{"varchar(10)", "date", TypeCheckError},
+		{"geometry", "blob", TypeCheckError},
+	}
+	for _, tt := range tests {
+		if got, reason := classifyColumnType(tt.source, tt.dest); got != tt.want {
+			t.Errorf("classifyColumnType(%v, %v) = %v (%v), want %v", tt.source, tt.dest, got, reason, tt.want)
+		}
+	}
+}
+
+// A table with deliberate mismatches between the source and the destination.
+func Test_compareTableColumns(t *testing.T) {
+	source := []*columnSchema{
+		{name: "id", columnType: "int(11)"},
+		{name: "amount", columnType: "decimal(10,2)", nullable: true},
+		{name: "name", columnType: "varchar(100)", charset: "utf8mb4", nullable: true},
+		{name: "note", columnType: "varchar(100)", charset: "latin1", nullable: true},
+		{name: "created", columnType: "datetime(6)"},
+		{name: "state", columnType: "enum('a','b')"},
+		{name: "price", columnType: "varchar(20)"},
+		{name: "gone", columnType: "int(11)", nullable: true},
+	}
+	dest := []*columnSchema{
+		{name: "ID", columnType: "bigint(20)"},
+		{name: "amount", columnType: "decimal(10,2)"},
+		{name: "name", columnType: "varchar(50)", charset: "latin1", nullable: true},
+		{name: "note", columnType: "varchar(100)", charset: "utf8mb4", nullable: true},
+		{name: "created", columnType: "int(11)"},
+		{name: "state", columnType: "enum('a','b')"},
+		{name: "price", columnType: "decimal(10,2)"},
+		{name: "region", columnType: "varchar(10)"},
+		{name: "shard", columnType: "int(11)"},
+		{name: "updated", columnType: "timestamp", hasDefault: true},
+		{name: "remark", columnType: "text", nullable: true},
+	}
+	columnDefault := &config.ColumnDefault{Columns: map[string]string{"shard": "1"}}
+	override := &config.ColumnTypeOverride{Columns: map[string]string{"price": "DECIMAL(10,2)"}}
+	checks := compareTableColumns("db1", "t1", source, dest, columnDefault, override)
+
+	want := []struct {
+		column string
+		level  string
+		reason string
+	}{
+		{"id", TypeCheckSafe, "widened"},
+		{"amount", TypeCheckError, "NULL is not allowed"},
+		{"name", TypeCheckWarn, "narrowed"},
+		{"note", TypeCheckSafe, "charset latin1 -> utf8mb4"},
+		{"created", TypeCheckError, "datetime can not be converted to int"},
+		{"price", TypeCheckSafe, "ColumnTypeOverride"},
+		{"gone", TypeCheckError, "not on the destination"},
+		{"region", TypeCheckError, "not on the source"},
+	}
+	if len(checks) != len(want) {
+		for _, c := range checks {
+			t.Logf("%+v", *c)
+		}
+		t.Fatalf("got %v checks, want %v", len(checks), len(want))
+	}
+	for i, w := range want {
+		c := checks[i]
+		if c.TableSchema != "db1" || c.TableName != "t1" || c.ColumnName != w.column || c.Level != w.level ||
+			!strings.Contains(c.Reason, w.reason) {
+			t.Errorf("checks[%v] = %+v, want %v %v %v", i, *c, w.column, w.level, w.reason)
+		}
+	}
+	if checks[2].Reason != "narrowed. values might be truncated, rounded or out of range;"+
+		" charset utf8mb4 -> latin1. some characters might not be representable" {
+		t.Errorf("reason of %v: %v", checks[2].ColumnName, checks[2].Reason)
+	}
+}
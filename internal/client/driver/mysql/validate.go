@@ -0,0 +1,306 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+	"github.com/actiontech/dtle/utils"
+)
+
+// ValidateConfig checks the job arguments of a task without connecting to the databases,
+// so that config errors are found when the job is registered rather than on a node.
+// All errors are returned at once in a *multierror.Error.
+func ValidateConfig(taskType string, cfg *config.MySQLDriverConfig) error {
+	var errs []error
+	if taskType == models.TaskTypeSrc {
+		errs = append(errs, validateStartPosition(cfg)...)
+		errs = append(errs, validateDataSources("ReplicateDoDb", cfg.ReplicateDoDb, true)...)
+		errs = append(errs, validateDataSources("ReplicateIgnoreDb", cfg.ReplicateIgnoreDb, false)...)
+		errs = append(errs, validateRenames(cfg.ReplicateDoDb)...)
+		errs = append(errs, validateSampleRates(cfg.ReplicateDoDb)...)
+		errs = append(errs, validateEncryption(cfg)...)
+		errs = append(errs, validateTableNameRegexes(cfg.LowerCaseTableNames != 0, cfg.ReplicateDoDb, cfg.ReplicateIgnoreDb)...)
+		if err := binlog.ValidateSqlFilter(cfg.SqlFilter); err != nil {
+			errs = append(errs, fmt.Errorf("SqlFilter: %v", err))
+		}
+		if _, err := sql.UriWithSessionVars("", cfg.SrcSessionVars); err != nil {
+			errs = append(errs, fmt.Errorf("SrcSessionVars: %v", err))
+		}
+		if cfg.ChunkSizeBytes < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: ChunkSizeBytes=%v. should be 0 or positive", cfg.ChunkSizeBytes))
+		}
+		if cfg.MaxDumpDuration < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: MaxDumpDuration=%v. should be 0 or positive", cfg.MaxDumpDuration))
+		}
+		if cfg.HeartbeatPeriod < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: HeartbeatPeriod=%v. should be positive", cfg.HeartbeatPeriod))
+		}
+		errs = append(errs, validateSourceCandidates(cfg)...)
+		if cfg.SemiSync && cfg.BinlogRelay {
+			errs = append(errs, fmt.Errorf("SemiSync is not supported with BinlogRelay"))
+		}
+		if cfg.BinlogRelay && cfg.ConnectionConfig != nil && cfg.ConnectionConfig.Tunnel != nil {
+			// the relay connects to the source by itself
+			errs = append(errs, fmt.Errorf("ConnectionConfig.Tunnel is not supported with BinlogRelay"))
+		}
+		if err := validateThrottlePct("ThrottleMemoryPct", cfg.ThrottleMemoryPct); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validateThrottlePct("ThrottleCPUPct", cfg.ThrottleCPUPct); err != nil {
+			errs = append(errs, err)
+		}
+		if _, err := newDumpCompressor(cfg.DumpMsgCompression, cfg.DumpMsgCompressionLevel); err != nil {
+			errs = append(errs, err)
+		}
+	} else if models.IsDestTaskType(taskType) {
+		errs = append(errs, validateDataSources("DestDoDb", cfg.DestDoDb, false)...)
+		errs = append(errs, validateTableNameRegexes(false, cfg.DestDoDb)...)
+		if err := validateApplyEventRateLimit(cfg.ApplyEventRateLimit); err != nil {
+			errs = append(errs, err)
+		}
+		if cfg.ReorderWindow < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: ReorderWindow=%v. should be 0 or positive", cfg.ReorderWindow))
+		}
+		if cfg.LargeRowSize < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: LargeRowSize=%v. should be 0 or positive", cfg.LargeRowSize))
+		}
+		if cfg.ApplyStatementTimeout < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: ApplyStatementTimeout=%v. should be 0 or positive",
+				cfg.ApplyStatementTimeout))
+		}
+		if cfg.ReplicationDelay < 0 {
+			errs = append(errs, fmt.Errorf("bad job argument: ReplicationDelay=%v. should be 0 or positive",
+				cfg.ReplicationDelay))
+		}
+		if _, err := sql.UriWithSessionVars("", cfg.DestSessionVars); err != nil {
+			errs = append(errs, fmt.Errorf("DestSessionVars: %v", err))
+		}
+		if _, err := newDdlRewriter(cfg.DdlRewrite); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, validateColumnTypeOverrides(cfg.ColumnTypeOverride)...)
+		errs = append(errs, validateZeroDatePolicy(cfg)...)
+		switch cfg.CollationMismatch {
+		case "", config.CollationMismatchWarn, config.CollationMismatchError, config.CollationMismatchEquivalent:
+		default:
+			errs = append(errs, fmt.Errorf("bad job argument: CollationMismatch=%v. should be one of %v, %v, %v",
+				cfg.CollationMismatch, config.CollationMismatchWarn, config.CollationMismatchError,
+				config.CollationMismatchEquivalent))
+		}
+		errs = append(errs, validateIdentifierQuote(cfg)...)
+		errs = append(errs, validateThrottleReplicas(cfg)...)
+		errs = append(errs, validateTablePriority(cfg)...)
+		errs = append(errs, validateDumpInsertIgnore(cfg)...)
+		errs = append(errs, validateShardBy(cfg)...)
+		errs = append(errs, validateLogAppliedSQL(cfg)...)
+		if cfg.EncryptionKey != "" {
+			if _, err := binlog.ParseEncryptionKey(cfg.EncryptionKey, false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	mErr := multierror.Error{Errors: errs}
+	return mErr.ErrorOrNil()
+}
+
+// validateStartPosition checks the options telling where the incremental copy starts.
+// A non-empty Gtid (also written back by the job as its checkpoint) overrides AutoGtid, GtidStart, BinlogFile
+// and MysqldumpCoordinate, of which at most one may be set otherwise.
+func validateStartPosition(cfg *config.MySQLDriverConfig) (errs []error) {
+	if cfg.Gtid != "" {
+		if _, err := base.ParseGtidSet(cfg.Gtid); err != nil {
+			errs = append(errs, fmt.Errorf("bad Gtid %v: %v", cfg.Gtid, err))
+		}
+		if cfg.BinlogRelay && cfg.BinlogFile == "" {
+			errs = append(errs, fmt.Errorf("BinlogFile,BinlogPos is required with Gtid when BinlogRelay is enabled"))
+		}
+	} else {
+		var options []string
+		if cfg.AutoGtid {
+			options = append(options, "AutoGtid")
+		}
+		if cfg.GtidStart != "" {
+			options = append(options, "GtidStart")
+		}
+		if cfg.BinlogFile != "" {
+			options = append(options, "BinlogFile")
+		}
+		if cfg.MysqldumpCoordinate != "" {
+			options = append(options, "MysqldumpCoordinate")
+			if coord, err := base.ParseMysqldumpCoordinate(cfg.MysqldumpCoordinate); err != nil {
+				errs = append(errs, fmt.Errorf("bad MysqldumpCoordinate: %v", err))
+			} else if cfg.BinlogRelay && coord.LogFile == "" {
+				errs = append(errs, fmt.Errorf("MysqldumpCoordinate has no CHANGE MASTER TO, which is required"+
+					" when BinlogRelay is enabled. dump the source with --master-data"))
+			}
+		}
+		if len(options) > 1 {
+			errs = append(errs, fmt.Errorf("%v are mutually exclusive. set only one of them", options))
+		}
+	}
+	if cfg.GtidStart != "" {
+		if _, err := base.ParseGtidSet(cfg.GtidStart); err != nil {
+			errs = append(errs, fmt.Errorf("bad GtidStart %v: %v", cfg.GtidStart, err))
+		}
+	}
+	if cfg.BinlogPos != 0 && cfg.BinlogFile == "" {
+		errs = append(errs, fmt.Errorf("BinlogPos %v is set without BinlogFile", cfg.BinlogPos))
+	}
+	return errs
+}
+
+// validateDataSources checks the schemas and tables of ReplicateDoDb, ReplicateIgnoreDb or DestDoDb.
+// Only ReplicateDoDb takes TableSchemaRegex and TableRegex. See Extractor.inspectTables for how they are used.
+func validateDataSources(name string, dss []*config.DataSource, withRegex bool) (errs []error) {
+	for i, ds := range dss {
+		if ds == nil {
+			errs = append(errs, fmt.Errorf("%v[%v] is empty", name, i))
+			continue
+		}
+		if !withRegex {
+			if ds.TableSchema == "" {
+				errs = append(errs, fmt.Errorf("%v[%v]: TableSchema is blank", name, i))
+			}
+			for j, tb := range ds.Tables {
+				if tb == nil || tb.TableName == "" {
+					errs = append(errs, fmt.Errorf("%v[%v].Tables[%v]: TableName is blank", name, i, j))
+				}
+			}
+			continue
+		}
+		if ds.TableSchema == "" && ds.TableSchemaRegex == "" {
+			errs = append(errs, fmt.Errorf("%v[%v]: TableSchema or TableSchemaRegex can not both be blank", name, i))
+		}
+		if ds.TableSchemaRegex != "" {
+			if _, err := regexp.Compile(ds.TableSchemaRegex); err != nil {
+				errs = append(errs, fmt.Errorf("%v[%v]: bad TableSchemaRegex %v: %v", name, i, ds.TableSchemaRegex, err))
+			}
+			if ds.TableSchema != "" || ds.TableSchemaRename == "" {
+				errs = append(errs, fmt.Errorf("%v[%v]: TableSchemaRegex requires TableSchemaRename and an empty TableSchema",
+					name, i))
+			}
+		}
+		for j, tb := range ds.Tables {
+			if tb == nil || (tb.TableName == "" && tb.TableRegex == "") {
+				errs = append(errs, fmt.Errorf("%v[%v].Tables[%v]: TableName or TableRegex can not both be blank", name, i, j))
+				continue
+			}
+			if tb.TableRegex != "" {
+				if _, err := regexp.Compile(tb.TableRegex); err != nil {
+					errs = append(errs, fmt.Errorf("%v[%v].Tables[%v]: bad TableRegex %v: %v", name, i, j, tb.TableRegex, err))
+				}
+				if tb.TableName != "" || tb.TableRename == "" {
+					errs = append(errs, fmt.Errorf("%v[%v].Tables[%v]: TableRegex requires TableRename and an empty TableName",
+						name, i, j))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateRenames checks that no two schemas or tables of ReplicateDoDb are renamed to the same one on the destination.
+// Names matched by a regex are known only on the source and are not checked.
+func validateRenames(dss []*config.DataSource) (errs []error) {
+	// the destination name to the source name
+	wholeSchemas := make(map[string]string)
+	tables := make(map[string]string)
+	for _, ds := range dss {
+		if ds == nil || ds.TableSchema == "" || ds.TableSchemaRegex != "" {
+			continue
+		}
+		destSchema := utils.StringElse(ds.TableSchemaRename, ds.TableSchema)
+		if len(ds.Tables) == 0 {
+			if src, ok := wholeSchemas[destSchema]; ok && src != ds.TableSchema {
+				errs = append(errs, fmt.Errorf("schemas %v and %v are both replicated to schema %v",
+					src, ds.TableSchema, destSchema))
+			}
+			wholeSchemas[destSchema] = ds.TableSchema
+			continue
+		}
+		for _, tb := range ds.Tables {
+			if tb == nil || tb.TableName == "" || tb.TableRegex != "" || config.IsTableNameRegex(tb.TableName) {
+				continue
+			}
+			src := fmt.Sprintf("%v.%v", ds.TableSchema, tb.TableName)
+			dest := fmt.Sprintf("%v.%v", destSchema, utils.StringElse(tb.TableRename, tb.TableName))
+			if other, ok := tables[dest]; ok && other != src {
+				errs = append(errs, fmt.Errorf("tables %v and %v are both replicated to table %v", other, src, dest))
+			}
+			tables[dest] = src
+		}
+	}
+	return errs
+}
+
+// validateSampleRates checks that SampleRate of each table is a fraction.
+func validateSampleRates(dss []*config.DataSource) (errs []error) {
+	for i, ds := range dss {
+		if ds == nil {
+			continue
+		}
+		for j, tb := range ds.Tables {
+			if tb != nil && (tb.SampleRate < 0 || tb.SampleRate > 1) {
+				errs = append(errs, fmt.Errorf("bad job argument: ReplicateDoDb[%v].Tables[%v].SampleRate=%v. should be 0 to 1",
+					i, j, tb.SampleRate))
+			}
+		}
+	}
+	return errs
+}
+
+// validateEncryption checks EncryptionKey, which is required by the EncryptColumns of ReplicateDoDb. The key in
+// the environment of the agent is not checked here.
+func validateEncryption(cfg *config.MySQLDriverConfig) (errs []error) {
+	if cfg.EncryptionKey != "" {
+		if _, err := binlog.ParseEncryptionKey(cfg.EncryptionKey, false); err != nil {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+	for i, ds := range cfg.ReplicateDoDb {
+		if ds == nil {
+			continue
+		}
+		for j, tb := range ds.Tables {
+			if tb != nil && len(tb.EncryptColumns) > 0 {
+				errs = append(errs, fmt.Errorf("ReplicateDoDb[%v].Tables[%v].EncryptColumns is set without EncryptionKey", i, j))
+			}
+		}
+	}
+	return errs
+}
+
+// validateTableNameRegexes checks the table names with config.TableNameRegexPrefix.
+func validateTableNameRegexes(caseInsensitive bool, dsss ...[]*config.DataSource) (errs []error) {
+	for _, dss := range dsss {
+		for _, ds := range dss {
+			if ds == nil {
+				continue
+			}
+			for _, tb := range ds.Tables {
+				if tb == nil || !config.IsTableNameRegex(tb.TableName) {
+					continue
+				}
+				if _, err := config.TableNameRegex(tb.TableName, caseInsensitive); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errs
+}
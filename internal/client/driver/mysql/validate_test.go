@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		taskType string
+		cfg      *config.MySQLDriverConfig
+		// a part of each error
+		wantErrs []string
+	}{
+		{"ok", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			Gtid:       "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10",
+			BinlogFile: "bin.000003", // written back with Gtid
+			ReplicateDoDb: []*config.DataSource{
+				{TableSchema: "a", TableSchemaRename: "c"},
+				{TableSchema: "b", Tables: []*config.Table{{TableName: "t"}, {TableName: "regex:^log_"}}},
+			},
+			ReplicateIgnoreDb: []*config.DataSource{{TableSchema: "a", Tables: []*config.Table{{TableName: "u"}}}},
+			SqlFilter:         []string{"NoDDL"},
+		}, nil},
+		{"position", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			AutoGtid:  true,
+			GtidStart: "bad",
+			BinlogPos: 4,
+		}, []string{"[AutoGtid GtidStart] are mutually exclusive", "bad GtidStart", "BinlogPos 4 is set without BinlogFile"}},
+		{"mysqldump coordinate", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			BinlogFile:          "bin.000003",
+			MysqldumpCoordinate: "SET @@GLOBAL.GTID_PURGED='3e11fa47:1-5';",
+		}, []string{"[BinlogFile MysqldumpCoordinate] are mutually exclusive", "bad MysqldumpCoordinate"}},
+		{"mysqldump coordinate without file", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			MysqldumpCoordinate: "SET @@GLOBAL.GTID_PURGED='3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5';",
+			BinlogRelay:         true,
+		}, []string{"MysqldumpCoordinate has no CHANGE MASTER TO"}},
+		{"gtid", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			Gtid:        "3e11fa47:1-10",
+			BinlogRelay: true,
+		}, []string{"bad Gtid", "BinlogFile,BinlogPos is required"}},
+		{"filters", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			ReplicateDoDb: []*config.DataSource{
+				{},
+				{TableSchemaRegex: "(", TableSchemaRename: "b"},
+				{TableSchema: "c", Tables: []*config.Table{{}, {TableRegex: "t.*"}, {TableName: "regex:("}}},
+			},
+			ReplicateIgnoreDb: []*config.DataSource{{Tables: []*config.Table{{TableName: "t"}}}},
+			SqlFilter:         []string{"NoDDL", "NoSuchFilter"},
+			SrcSessionVars:    map[string]string{"bad name": "1"},
+			ThrottleCPUPct:    101,
+			HeartbeatPeriod:   -1,
+			ChunkSizeBytes:    -1,
+			MaxDumpDuration:   -1,
+		}, []string{
+			"ReplicateDoDb[0]: TableSchema or TableSchemaRegex can not both be blank",
+			"ReplicateDoDb[1]: bad TableSchemaRegex",
+			"ReplicateDoDb[2].Tables[0]: TableName or TableRegex can not both be blank",
+			"ReplicateDoDb[2].Tables[1]: TableRegex requires TableRename",
+			"ReplicateIgnoreDb[0]: TableSchema is blank",
+			"regex:(",
+			"unknown sql filter item: NoSuchFilter",
+			"SrcSessionVars",
+			"HeartbeatPeriod=-1",
+			"ChunkSizeBytes=-1",
+			"MaxDumpDuration=-1",
+			"ThrottleCPUPct=101",
+		}},
+		{"compression", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			DumpMsgCompression:      config.DumpMsgCompressionGzip,
+			DumpMsgCompressionLevel: 10,
+		}, []string{"DumpMsgCompressionLevel=10"}},
+		{"zero date policy", models.TaskTypeDest, &config.MySQLDriverConfig{
+			ZeroDatePolicy:    "zero",
+			ZeroDateConvertTo: "1970-01-01 00:00:00",
+		}, []string{"ZeroDatePolicy=zero", "ZeroDateConvertTo=1970-01-01 00:00:00"}},
+		{"dump insert ignore", models.TaskTypeDest, &config.MySQLDriverConfig{
+			DumpInsertIgnore: []string{"db1.t1", "t2", "db1."},
+		}, []string{"DumpInsertIgnore: t2", "DumpInsertIgnore: db1."}},
+		{"shard by", models.TaskTypeDest, &config.MySQLDriverConfig{
+			ShardBy: []*config.ShardBy{
+				{TableSchema: "db1", TableName: "t1", Column: "id", Shards: 4},
+				{TableSchema: "db1", TableName: "t1", Column: "id", Shards: 1, NameTemplate: "{table}_x"},
+				{TableSchema: "db1", TableName: "t2", Shards: 4},
+			},
+		}, []string{"ShardBy[1].Shards=1", "ShardBy[1].NameTemplate={table}_x", "db1.t1 is listed more than once",
+			"ShardBy[2]: TableSchema, TableName and Column are required"}},
+		{"log applied sql", models.TaskTypeDest, &config.MySQLDriverConfig{
+			LogAppliedSQL:          true,
+			LogAppliedSQLRateLimit: -1,
+			LogAppliedSQLRedact:    []string{"db1.t1.c", "db1.t1"},
+		}, []string{"LogAppliedSQLRateLimit=-1", "LogAppliedSQLRedact: db1.t1."}},
+		{"collation mismatch", models.TaskTypeDest, &config.MySQLDriverConfig{
+			CollationMismatch: "ignore",
+		}, []string{"CollationMismatch=ignore"}},
+		{"throttle replicas", models.TaskTypeDest, &config.MySQLDriverConfig{
+			ThrottleReplicas:      []string{"10.0.0.2:3306", "[::1]:3307", "10.0.0.3", "10.0.0.4:port"},
+			ThrottleReplicaMaxLag: -1,
+		}, []string{"ThrottleReplicas: 10.0.0.3", "ThrottleReplicas: 10.0.0.4:port", "ThrottleReplicaMaxLag=-1"}},
+		{"source candidates", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			SourceCandidates: []string{"10.0.0.2:3306", "10.0.0.3"},
+			BinlogRelay:      true,
+			ConnectionConfig: &umconf.ConnectionConfig{Tunnel: &umconf.TunnelConfig{}},
+		}, []string{"SourceCandidates: 10.0.0.3", "SourceCandidates is not supported with BinlogRelay",
+			"not supported with ConnectionConfig.Tunnel", "ConnectionConfig.Tunnel is not supported with BinlogRelay"}},
+		{"semisync", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			SemiSync:    true,
+			BinlogRelay: true,
+		}, []string{"SemiSync is not supported with BinlogRelay"}},
+		{"tunnel", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			BinlogRelay:      true,
+			ConnectionConfig: &umconf.ConnectionConfig{Tunnel: &umconf.TunnelConfig{Socks5: "10.0.0.2:1080"}},
+		}, []string{"ConnectionConfig.Tunnel is not supported with BinlogRelay"}},
+		{"replication delay", models.TaskTypeDest, &config.MySQLDriverConfig{
+			ReplicationDelay: -1,
+		}, []string{"ReplicationDelay=-1"}},
+		{"identifier quote", models.TaskTypeDest, &config.MySQLDriverConfig{
+			IdentifierQuote: "double",
+		}, []string{"IdentifierQuote=double"}},
+		{"table priority", models.TaskTypeDest, &config.MySQLDriverConfig{
+			TablePriority: map[string]int{"db1.parent": 0, "db1.child": 1, "child": 2},
+		}, []string{"TablePriority: child."}},
+		{"sample rate", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			ReplicateDoDb: []*config.DataSource{{TableSchema: "a", Tables: []*config.Table{
+				{TableName: "t1", SampleRate: 0.1}, {TableName: "t2", SampleRate: 10}}}},
+		}, []string{"ReplicateDoDb[0].Tables[1].SampleRate=10"}},
+		{"encryption without key", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			ReplicateDoDb: []*config.DataSource{{TableSchema: "a", Tables: []*config.Table{
+				{TableName: "t1"}, {TableName: "t2", EncryptColumns: []string{"c"}}}}},
+		}, []string{"ReplicateDoDb[0].Tables[1].EncryptColumns is set without EncryptionKey"}},
+		{"encryption key", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			EncryptionKey: "AAAA",
+		}, []string{"EncryptionKey is of 3 bytes"}},
+		{"encryption key on the destination", models.TaskTypeDest, &config.MySQLDriverConfig{
+			EncryptionKey: "not base64",
+		}, []string{"EncryptionKey. should be base64"}},
+		{"renames", models.TaskTypeSrc, &config.MySQLDriverConfig{
+			ReplicateDoDb: []*config.DataSource{
+				{TableSchema: "a", TableSchemaRename: "c"},
+				{TableSchema: "b", TableSchemaRename: "c"},
+				{TableSchema: "d", Tables: []*config.Table{{TableName: "t1", TableRename: "t"}, {TableName: "t"}}},
+				{TableSchema: "e", TableSchemaRename: "f", Tables: []*config.Table{{TableName: "t"}}},
+				{TableSchema: "f", Tables: []*config.Table{{TableName: "t"}}},
+			},
+		}, []string{
+			"schemas a and b are both replicated to schema c",
+			"tables d.t1 and d.t are both replicated to table d.t",
+			"tables e.t and f.t are both replicated to table f.t",
+		}},
+		{"dest", models.TaskTypeDest, &config.MySQLDriverConfig{
+			// not checked on a destination task
+			ReplicateDoDb:         []*config.DataSource{{}},
+			DestDoDb:              []*config.DataSource{{Tables: []*config.Table{{TableName: "regex:("}}}},
+			ApplyEventRateLimit:   -1,
+			DestSessionVars:       map[string]string{"a;b": "1"},
+			LargeRowSize:          -1,
+			ApplyStatementTimeout: -1,
+			ReorderWindow:         -1,
+			DdlRewrite:            []*config.DdlRewriteRule{{Pattern: "ENGINE=MyISAM", Replacement: "ENGINE=InnoDB"}, {Pattern: "("}},
+			ColumnTypeOverride:    []*config.ColumnTypeOverride{{Columns: map[string]string{"id": "BIGINT"}}},
+		}, []string{"DestDoDb[0]: TableSchema is blank", "regex:(", "ApplyEventRateLimit=-1", "DestSessionVars", "LargeRowSize=-1",
+			"ApplyStatementTimeout=-1", "ReorderWindow=-1",
+			"DdlRewrite[1].Pattern=(", "ColumnTypeOverride[0].Columns[id]=BIGINT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(tt.taskType, tt.cfg)
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateConfig() error = %v", err)
+				}
+				return
+			}
+			mErr, ok := err.(*multierror.Error)
+			if !ok {
+				t.Fatalf("ValidateConfig() error = %v, want a *multierror.Error", err)
+			}
+			if len(mErr.Errors) != len(tt.wantErrs) {
+				t.Errorf("ValidateConfig() got %v errors, want %v: %v", len(mErr.Errors), len(tt.wantErrs), err)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("ValidateConfig() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
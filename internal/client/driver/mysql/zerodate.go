@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// A source without NO_ZERO_DATE or NO_ZERO_IN_DATE in its sql_mode, or with ALLOW_INVALID_DATES, keeps dates
+// such as 0000-00-00 or 2019-02-30, which a destination in strict mode rejects. ZeroDatePolicy replaces them
+// by the destination column types, after the values are put in the order of the destination columns.
+
+// validateZeroDatePolicy checks ZeroDatePolicy and ZeroDateConvertTo.
+func validateZeroDatePolicy(cfg *config.MySQLDriverConfig) (errs []error) {
+	switch cfg.ZeroDatePolicy {
+	case "", config.ZeroDatePolicyError, config.ZeroDatePolicyNull, config.ZeroDatePolicyConvert:
+	default:
+		errs = append(errs, fmt.Errorf("bad job argument: ZeroDatePolicy=%v. should be one of %v, %v, %v",
+			cfg.ZeroDatePolicy, config.ZeroDatePolicyError, config.ZeroDatePolicyNull, config.ZeroDatePolicyConvert))
+	}
+	if cfg.ZeroDateConvertTo != "" {
+		if _, err := time.Parse("2006-01-02", cfg.ZeroDateConvertTo); err != nil {
+			errs = append(errs, fmt.Errorf("bad job argument: ZeroDateConvertTo=%v. should be a date as 1970-01-01",
+				cfg.ZeroDateConvertTo))
+		}
+	}
+	return errs
+}
+
+// zeroDateColumns returns whether each of columns is of DATE, DATETIME or TIMESTAMP, or nil if none is.
+func zeroDateColumns(columns *umconf.ColumnList) []bool {
+	var dateColumns []bool
+	for j := range columns.Columns {
+		switch columns.Columns[j].Type {
+		case umconf.DateColumnType, umconf.DateTimeColumnType, umconf.TimestampColumnType:
+			if dateColumns == nil {
+				dateColumns = make([]bool, columns.Len())
+			}
+			dateColumns[j] = true
+		}
+	}
+	return dateColumns
+}
+
+// isInvalidDate tells whether v, the text of a DATE, DATETIME or TIMESTAMP value, is a zero date or has
+// a zero or out-of-range month or day. Year 0000 is valid, as in MySQL.
+func isInvalidDate(v string) bool {
+	if len(v) < 10 || v[4] != '-' || v[7] != '-' {
+		return false
+	}
+	year, errY := strconv.Atoi(v[0:4])
+	month, errM := strconv.Atoi(v[5:7])
+	day, errD := strconv.Atoi(v[8:10])
+	if errY != nil || errM != nil || errD != nil {
+		return false
+	}
+	if month < 1 || month > 12 || day < 1 {
+		return true
+	}
+	// the day normalizes into the next month if out of range
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).Day() != day
+}
+
+// zeroDatePolicyApplies tells whether the values of date columns are to be checked, i.e. ZeroDatePolicy
+// replaces them.
+func (a *Applier) zeroDatePolicyApplies() bool {
+	return a.mysqlContext.ZeroDatePolicy == config.ZeroDatePolicyNull ||
+		a.mysqlContext.ZeroDatePolicy == config.ZeroDatePolicyConvert
+}
+
+// zeroDateReplacement returns the value written for an invalid date of column by ZeroDatePolicy,
+// or false for NULL.
+func (a *Applier) zeroDateReplacement(column *umconf.Column) (string, bool) {
+	if a.mysqlContext.ZeroDatePolicy != config.ZeroDatePolicyConvert {
+		return "", false
+	}
+	if column.Type == umconf.DateColumnType {
+		return a.mysqlContext.ZeroDateConvertTo, true
+	}
+	return a.mysqlContext.ZeroDateConvertTo + " 00:00:00", true
+}
+
+// replaceDumpZeroDates applies ZeroDatePolicy to the rows of the full copy, in place.
+func (a *Applier) replaceDumpZeroDates(entry *DumpEntry) error {
+	if len(entry.ValuesX) == 0 || !a.zeroDatePolicyApplies() {
+		return nil
+	}
+	columns, _, err := a.getTableColumns(entry.TableSchema, entry.TableName)
+	if err != nil {
+		return err
+	}
+	dateColumns := zeroDateColumns(columns)
+	if dateColumns == nil {
+		return nil
+	}
+	replaced := 0
+	for _, row := range entry.ValuesX {
+		for j, v := range row {
+			if j >= len(dateColumns) || !dateColumns[j] || v == nil || !isInvalidDate(string(*v)) {
+				continue
+			}
+			if s, ok := a.zeroDateReplacement(&columns.Columns[j]); ok {
+				bs := []byte(s)
+				row[j] = &bs
+			} else {
+				row[j] = nil
+			}
+			replaced++
+		}
+	}
+	if replaced > 0 {
+		a.logger.Debugf("mysql.applier: %v.%v: replaced %v invalid dates by ZeroDatePolicy %v",
+			entry.TableSchema, entry.TableName, replaced, a.mysqlContext.ZeroDatePolicy)
+	}
+	return nil
+}
+
+// replaceZeroDates applies ZeroDatePolicy to the values of a row, in the order of columns.
+// dateColumns is zeroDateColumns(columns).
+func (a *Applier) replaceZeroDates(columns *umconf.ColumnList, dateColumns []bool, values *umconf.ColumnValues) {
+	if values == nil {
+		return
+	}
+	for j, v := range values.AbstractValues {
+		if j >= len(dateColumns) || !dateColumns[j] || v == nil || *v == nil {
+			continue
+		}
+		var text string
+		switch x := (*v).(type) {
+		case string:
+			text = x
+		case []byte:
+			text = string(x)
+		default:
+			continue
+		}
+		if !isInvalidDate(text) {
+			continue
+		}
+		var newValue interface{}
+		if s, ok := a.zeroDateReplacement(&columns.Columns[j]); ok {
+			newValue = s
+		}
+		values.AbstractValues[j] = &newValue
+	}
+}
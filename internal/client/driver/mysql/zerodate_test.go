@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package mysql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
+	"github.com/actiontech/dtle/internal/client/driver/mysql/sql"
+	"github.com/actiontech/dtle/internal/config"
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+func Test_isInvalidDate(t *testing.T) {
+	for v, want := range map[string]bool{
+		"0000-00-00":                 true,
+		"0000-00-00 00:00:00":        true,
+		"0000-00-00 00:00:00.000000": true,
+		"2019-00-10":                 true,
+		"2019-10-00 12:00:00":        true,
+		"2019-02-30":                 true,
+		"2019-13-01":                 true,
+		"2020-02-29":                 false,
+		"0000-01-01":                 false,
+		"2019-10-10 12:00:00":        false,
+		"12:00:00":                   false,
+		"":                           false,
+	} {
+		if got := isInvalidDate(v); got != want {
+			t.Errorf("isInvalidDate(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestApplier_replaceZeroDates(t *testing.T) {
+	columns := umconf.NewColumnList([]umconf.Column{
+		{RawName: "id"},
+		{RawName: "d", Type: umconf.DateColumnType},
+		{RawName: "dt", Type: umconf.DateTimeColumnType},
+		{RawName: "c"},
+	})
+	tests := []struct {
+		policy string
+		want   []interface{}
+	}{
+		{config.ZeroDatePolicyNull, []interface{}{int64(1), nil, nil, "0000-00-00"}},
+		{config.ZeroDatePolicyConvert, []interface{}{int64(1), "1970-01-01", "1970-01-01 00:00:00", "0000-00-00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			a := &Applier{mysqlContext: (&config.MySQLDriverConfig{ZeroDatePolicy: tt.policy}).SetDefault()}
+			dateColumns := zeroDateColumns(columns)
+			if !reflect.DeepEqual(dateColumns, []bool{false, true, true, false}) {
+				t.Fatalf("zeroDateColumns() = %v", dateColumns)
+			}
+			values := []interface{}{int64(1), "0000-00-00", []byte("2019-02-30 10:00:00"), "0000-00-00"}
+			row := &umconf.ColumnValues{}
+			for i := range values {
+				row.AbstractValues = append(row.AbstractValues, &values[i])
+			}
+			a.replaceZeroDates(columns, dateColumns, row)
+			var got []interface{}
+			for _, v := range row.AbstractValues {
+				got = append(got, *v)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replaceZeroDates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A zero date of each type is inserted by the full copy and the incremental replication
+// into a destination in strict mode.
+func TestApplier_zeroDatePolicy(t *testing.T) {
+	skipWithoutMySQL(t)
+	db, err := sql.CreateDB("root:rootroot@tcp(192.168.99.100:13307)/?timeout=5s&multiStatements=true" +
+		"&sql_mode='STRICT_ALL_TABLES,NO_ZERO_DATE,NO_ZERO_IN_DATE'")
+	if err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Skipf("no mysql available: %v", err)
+	}
+	defer db.Exec("drop database if exists dtle_test_zerodate")
+
+	bs := func(s string) *[]byte {
+		b := []byte(s)
+		return &b
+	}
+	tests := []struct {
+		policy string
+		// the dates of each row. empty if the rows fail.
+		want []string
+	}{
+		{config.ZeroDatePolicyError, nil},
+		{config.ZeroDatePolicyNull, []string{"1 NULL NULL NULL", "2 NULL NULL NULL"}},
+		{config.ZeroDatePolicyConvert, []string{"1 1970-01-02 1970-01-02 00:00:00 1970-01-02 00:00:00",
+			"2 1970-01-02 1970-01-02 00:00:00 1970-01-02 00:00:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			_, err = db.Exec("drop database if exists dtle_test_zerodate;" +
+				"create database dtle_test_zerodate;" +
+				"create table dtle_test_zerodate.tb1 (id int primary key, d date, dt datetime, ts timestamp null)")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a TIMESTAMP of 1970-01-01 00:00:00 is out of range
+			a := &Applier{
+				logger:     logrus.NewEntry(logrus.New()),
+				db:         db,
+				tableItems: make(mapSchemaTableItems),
+				mysqlContext: (&config.MySQLDriverConfig{
+					ZeroDatePolicy: tt.policy, ZeroDateConvertTo: "1970-01-02", ParallelWorkers: 1}).SetDefault(),
+			}
+
+			// full copy
+			entry := &DumpEntry{
+				TableSchema: "dtle_test_zerodate",
+				TableName:   "tb1",
+				ValuesX:     [][]*[]byte{{bs("1"), bs("0000-00-00"), bs("2019-00-10 10:00:00"), bs("0000-00-00 00:00:00")}},
+				RowsCount:   1,
+			}
+			if err := a.ApplyEventQueries(db, entry); (err != nil) != (tt.want == nil) {
+				t.Fatalf("ApplyEventQueries() error = %v", err)
+			}
+
+			// incremental
+			values := []interface{}{int64(2), "0000-00-00", "2019-02-30 10:00:00", "0000-00-00 00:00:00"}
+			row := &umconf.ColumnValues{}
+			for i := range values {
+				row.AbstractValues = append(row.AbstractValues, &values[i])
+			}
+			binlogEntry := &binlog.BinlogEntry{Events: []binlog.DataEvent{{DatabaseName: "dtle_test_zerodate",
+				TableName: "tb1", DML: binlog.InsertDML, NewColumnValues: row}}}
+			if err := a.setTableItemForBinlogEntry(binlogEntry); err != nil {
+				t.Fatal(err)
+			}
+			columns := binlogEntry.Events[0].TableItem.(*applierTableItem).columns
+			query, args, err := sql.BuildDMLInsertQuery("dtle_test_zerodate", "tb1", columns, columns, columns,
+				binlogEntry.Events[0].NewColumnValues.AbstractValues)
+			if err != nil {
+				t.Fatalf("BuildDMLInsertQuery() error = %v", err)
+			}
+			if _, err := db.Exec(query, args...); (err != nil) != (tt.want == nil) {
+				t.Fatalf("insert error = %v. query: %v", err, query)
+			}
+
+			rows, err := db.Query("select id, ifnull(d, 'NULL'), ifnull(dt, 'NULL'), ifnull(ts, 'NULL')" +
+				" from dtle_test_zerodate.tb1 order by id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			var got []string
+			for rows.Next() {
+				var id int
+				var d, dt, ts string
+				if err := rows.Scan(&id, &d, &dt, &ts); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, fmt.Sprintf("%v %v %v %v", id, d, dt, ts))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
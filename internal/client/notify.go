@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+const (
+	// notifySignatureHeader carries the HMAC-SHA256 of the body if a secret is set
+	notifySignatureHeader = "X-Dtle-Signature"
+
+	notifyTimeout     = 5 * time.Second
+	notifyMaxAttempts = 3
+	notifyRetryDelay  = 2 * time.Second
+)
+
+// notifyHTTPClient is shared by all notifiers
+var notifyHTTPClient = &http.Client{Timeout: notifyTimeout}
+
+// Notification is the JSON body posted to the webhooks
+type Notification struct {
+	JobID   string    `json:"job_id"`
+	JobName string    `json:"job_name"`
+	AllocID string    `json:"alloc_id"`
+	Task    string    `json:"task"`
+	Event   string    `json:"event"`
+	Type    string    `json:"type"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// notifier posts job lifecycle events to the configured webhooks.
+// It is best-effort: failures are logged and never block the caller.
+type notifier struct {
+	logger   *logrus.Logger
+	webhooks []*config.NotificationConfig
+	client   *http.Client
+}
+
+func newNotifier(logger *logrus.Logger, webhooks []*config.NotificationConfig) *notifier {
+	return &notifier{
+		logger:   logger,
+		webhooks: webhooks,
+		client:   notifyHTTPClient,
+	}
+}
+
+// notificationEvent maps a task event to a lifecycle event.
+// It returns "" if the task event is not notified.
+func notificationEvent(event *models.TaskEvent) string {
+	switch event.Type {
+	case models.TaskStarted:
+		return config.NotificationEventStarted
+	case models.TaskFullCopyComplete:
+		return config.NotificationEventDumpComplete
+	case models.TaskFullCopyIncomplete:
+		return config.NotificationEventDumpIncomplete
+	case models.TaskLagExceeded:
+		return config.NotificationEventLagExceeded
+	case models.TaskDestinationDiskFull:
+		return config.NotificationEventDiskFull
+	case models.TaskDestinationRecovered:
+		return config.NotificationEventRecovered
+	case models.TaskKilled:
+		return config.NotificationEventStopped
+	case models.TaskSetupFailure, models.TaskDriverFailure, models.TaskNotRestarting:
+		return config.NotificationEventFailed
+	case models.TaskTerminated:
+		if event.ExitCode != 0 {
+			return config.NotificationEventFailed
+		}
+	}
+	if event.FailsTask {
+		return config.NotificationEventFailed
+	}
+	return ""
+}
+
+func taskEventMessage(event *models.TaskEvent) string {
+	for _, m := range []string{event.Message, event.DriverMessage, event.DriverError,
+		event.SetupError, event.KillReason, event.KillError, event.RestartReason} {
+		if m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
+// Notify sends the task event to the webhooks interested in it, if any.
+func (n *notifier) Notify(alloc *models.Allocation, taskName string, event *models.TaskEvent) {
+	if n == nil || len(n.webhooks) == 0 || event == nil {
+		return
+	}
+	e := notificationEvent(event)
+	if e == "" {
+		return
+	}
+
+	notification := &Notification{
+		AllocID: alloc.ID,
+		Task:    taskName,
+		Event:   e,
+		Type:    event.Type,
+		Message: taskEventMessage(event),
+		Time:    event.Time,
+	}
+	if alloc.Job != nil {
+		notification.JobID = alloc.Job.ID
+		notification.JobName = alloc.Job.Name
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		n.logger.Errorf("client: failed to encode notification: %v", err)
+		return
+	}
+
+	for _, webhook := range n.webhooks {
+		if !webhook.Wants(e) {
+			continue
+		}
+		go n.post(webhook, body)
+	}
+}
+
+func (n *notifier) post(webhook *config.NotificationConfig, body []byte) {
+	var err error
+	for i := 0; i < notifyMaxAttempts; i++ {
+		if i > 0 {
+			time.Sleep(notifyRetryDelay)
+		}
+		if err = n.postOnce(webhook, body); err == nil {
+			return
+		}
+		n.logger.Debugf("client: notification to %v failed (attempt %v): %v", webhook.URL, i+1, err)
+	}
+	n.logger.Warnf("client: giving up notification to %v: %v", webhook.URL, err)
+}
+
+func (n *notifier) postOnce(webhook *config.NotificationConfig, body []byte) error {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if webhook.Secret != "" {
+		req.Header.Set(notifySignatureHeader, "sha256="+signNotification(webhook.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %v", resp.Status)
+	}
+	return nil
+}
+
+// signNotification returns the hex encoded HMAC-SHA256 of body
+func signNotification(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
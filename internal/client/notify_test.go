@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/actiontech/dtle/internal/config"
+	"github.com/actiontech/dtle/internal/models"
+)
+
+var errTest = errors.New("connection refused")
+
+func Test_notificationEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *models.TaskEvent
+		want  string
+	}{
+		{"started", models.NewTaskEvent(models.TaskStarted), config.NotificationEventStarted},
+		{"full copy complete", models.NewTaskEvent(models.TaskFullCopyComplete), config.NotificationEventDumpComplete},
+		{"full copy incomplete", models.NewTaskEvent(models.TaskFullCopyIncomplete), config.NotificationEventDumpIncomplete},
+		{"lag exceeded", models.NewTaskEvent(models.TaskLagExceeded), config.NotificationEventLagExceeded},
+		{"disk full", models.NewTaskEvent(models.TaskDestinationDiskFull), config.NotificationEventDiskFull},
+		{"recovered", models.NewTaskEvent(models.TaskDestinationRecovered), config.NotificationEventRecovered},
+		{"killed", models.NewTaskEvent(models.TaskKilled), config.NotificationEventStopped},
+		{"driver failure", models.NewTaskEvent(models.TaskDriverFailure), config.NotificationEventFailed},
+		{"terminated ok", models.NewTaskEvent(models.TaskTerminated), ""},
+		{"terminated with error", models.NewTaskEvent(models.TaskTerminated).SetExitCode(1), config.NotificationEventFailed},
+		{"driver message", models.NewTaskEvent(models.TaskDriverMessage), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notificationEvent(tt.event); got != tt.want {
+				t.Errorf("notificationEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	type received struct {
+		body      []byte
+		signature string
+	}
+	ch := make(chan received, 4)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		ch <- received{body, r.Header.Get(notifySignatureHeader)}
+	}))
+	defer ts.Close()
+
+	n := newNotifier(logrus.New(), []*config.NotificationConfig{
+		{URL: ts.URL, Events: []string{config.NotificationEventFailed}, Secret: "s3cret"},
+	})
+	alloc := &models.Allocation{ID: "alloc1", Job: &models.Job{ID: "job1", Name: "job-name"}}
+
+	// Not subscribed.
+	n.Notify(alloc, "Src", models.NewTaskEvent(models.TaskStarted))
+	n.Notify(alloc, "Src", models.NewTaskEvent(models.TaskDriverFailure).SetDriverError(errTest))
+
+	select {
+	case r := <-ch:
+		if want := "sha256=" + signNotification("s3cret", r.body); r.signature != want {
+			t.Errorf("signature = %v, want %v", r.signature, want)
+		}
+		var got Notification
+		if err := json.Unmarshal(r.body, &got); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if got.JobID != "job1" || got.JobName != "job-name" || got.AllocID != "alloc1" || got.Task != "Src" ||
+			got.Event != config.NotificationEventFailed || got.Message != errTest.Error() {
+			t.Errorf("notification = %+v", got)
+		}
+	case <-time.After(notifyTimeout):
+		t.Fatalf("no notification received")
+	}
+
+	select {
+	case r := <-ch:
+		t.Errorf("unexpected notification %s", r.body)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -9,9 +9,9 @@ package client
 import (
 	"math/rand"
 	"reflect"
-	"sync"
 	"testing"
 	"time"
+
 	"github.com/actiontech/dtle/internal/models"
 )
 
@@ -41,7 +41,6 @@ func TestRestartTracker_SetStartError(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	type args struct {
 		err error
@@ -65,7 +64,6 @@ func TestRestartTracker_SetStartError(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			if got := r.SetStartError(tt.args.err); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("RestartTracker.SetStartError() = %v, want %v", got, tt.want)
@@ -84,7 +82,6 @@ func TestRestartTracker_SetWaitResult(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	type args struct {
 		res *models.WaitResult
@@ -108,7 +105,6 @@ func TestRestartTracker_SetWaitResult(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			if got := r.SetWaitResult(tt.args.res); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("RestartTracker.SetWaitResult() = %v, want %v", got, tt.want)
@@ -127,7 +123,6 @@ func TestRestartTracker_SetRestartTriggered(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -147,7 +142,6 @@ func TestRestartTracker_SetRestartTriggered(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			if got := r.SetRestartTriggered(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("RestartTracker.SetRestartTriggered() = %v, want %v", got, tt.want)
@@ -166,7 +160,6 @@ func TestRestartTracker_GetReason(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -186,7 +179,6 @@ func TestRestartTracker_GetReason(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			if got := r.GetReason(); got != tt.want {
 				t.Errorf("RestartTracker.GetReason() = %v, want %v", got, tt.want)
@@ -205,7 +197,6 @@ func TestRestartTracker_GetState(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -226,7 +217,6 @@ func TestRestartTracker_GetState(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			got, got1 := r.GetState()
 			if got != tt.want {
@@ -249,7 +239,6 @@ func TestRestartTracker_handleStartError(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -270,7 +259,6 @@ func TestRestartTracker_handleStartError(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			got, got1 := r.handleStartError()
 			if got != tt.want {
@@ -293,7 +281,6 @@ func TestRestartTracker_handleWaitResult(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -314,7 +301,6 @@ func TestRestartTracker_handleWaitResult(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			got, got1 := r.handleWaitResult()
 			if got != tt.want {
@@ -337,7 +323,6 @@ func TestRestartTracker_getDelay(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -357,7 +342,6 @@ func TestRestartTracker_getDelay(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			if got := r.getDelay(); got != tt.want {
 				t.Errorf("RestartTracker.getDelay() = %v, want %v", got, tt.want)
@@ -376,7 +360,6 @@ func TestRestartTracker_jitter(t *testing.T) {
 		startTime        time.Time
 		reason           string
 		rand             *rand.Rand
-		lock             sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -396,7 +379,6 @@ func TestRestartTracker_jitter(t *testing.T) {
 				startTime:        tt.fields.startTime,
 				reason:           tt.fields.reason,
 				rand:             tt.fields.rand,
-				lock:             tt.fields.lock,
 			}
 			if got := r.jitter(); got != tt.want {
 				t.Errorf("RestartTracker.jitter() = %v, want %v", got, tt.want)
@@ -180,12 +180,13 @@ func (r *Worker) SaveState() error {
 				TaskType: r.task.Type,
 				NatsAddr: id.DriverConfig.NatsAddr,
 			}
-			if r.task.Type == models.TaskTypeDest {
+			if models.IsDestTaskType(r.task.Type) {
 				if id.DriverConfig.Gtid != "" {
 					tu.Gtid = id.DriverConfig.Gtid
 				}
 				tu.BinlogFile = id.DriverConfig.BinlogFile
 				tu.BinlogPos = id.DriverConfig.BinlogPos
+				tu.DumpCheckpoint = id.DriverConfig.DumpCheckpoint
 			} else { // TaskTypeSrc
 				// nothing yet
 			}
@@ -203,6 +204,7 @@ func (r *Worker) SaveState() error {
 		}
 		r.task.ConfigLock.Lock()
 		r.task.Config["Gtid"] = id.DriverConfig.Gtid
+		r.task.Config["DumpCheckpoint"] = id.DriverConfig.DumpCheckpoint
 		r.task.Config["NatsAddr"] = id.DriverConfig.NatsAddr
 		r.task.ConfigLock.Unlock()
 		r.logger.WithFields(logrus.Fields{
@@ -235,6 +237,23 @@ func (r *Worker) setState(state string, event *models.TaskEvent) {
 	// Indicate the task has been updated.
 	r.logger.Debugf("updater")
 	r.updater(r.task.Type, state, event)
+
+	if event != nil {
+		entry := r.logger.WithFields(r.logFields()).WithField("event", event.Type)
+		if event.DriverMessage != "" {
+			entry = entry.WithField("driver_message", event.DriverMessage)
+		}
+		entry.Infof("agent: task event. state: %v", state)
+	}
+}
+
+// logFields returns the fields to correlate logs of the task.
+func (r *Worker) logFields() logrus.Fields {
+	return logrus.Fields{
+		"job":   r.alloc.Job.ID,
+		"alloc": r.alloc.ID,
+		"task":  r.task.Type,
+	}
 }
 
 // createDriver makes a driver for the task
@@ -569,7 +588,17 @@ func (r *Worker) startTask() error {
 	}
 
 	// Run prestart
-	ctx := &common.ExecContext{r.alloc.Job.ID, r.alloc.Job.Type, r.config.MaxPayload, r.config.StateDir}
+	ctx := &common.ExecContext{
+		Subject:       r.alloc.Job.ID,
+		Tp:            r.alloc.Job.Type,
+		MaxPayload:    r.config.MaxPayload,
+		StateDir:      r.config.StateDir,
+		AllocID:       r.alloc.ID,
+		TaskType:      r.task.Type,
+		EmitEvent:     r.emitDriverEvent,
+		EmitTaskEvent: r.emitTaskEvent,
+		IncrCounter:   r.incrCounter,
+	}
 
 	// Start the job
 	handle, err := drv.Start(ctx, r.task)
@@ -589,6 +618,74 @@ func (r *Worker) startTask() error {
 	return nil
 }
 
+// Update passes models.RuntimeConfigKeys of the updated task to the running driver.
+// They are also kept for the next start of the task.
+func (r *Worker) Update(task *models.Task) {
+	config := make(map[string]interface{})
+	r.task.ConfigLock.Lock()
+	for _, k := range models.RuntimeConfigKeys {
+		v, ok := task.Config[k]
+		if !ok {
+			continue
+		}
+		config[k] = v
+		r.task.Config[k] = v
+	}
+	r.task.ConfigLock.Unlock()
+
+	r.handleLock.Lock()
+	handle := r.handle
+	r.handleLock.Unlock()
+	if handle == nil || len(config) == 0 {
+		return
+	}
+	updater, ok := handle.(driver.ConfigUpdater)
+	if !ok {
+		return
+	}
+	if err := updater.UpdateConfig(config); err != nil {
+		r.logger.Errorf("agent: Failed to update the config of task %q for alloc %q: %v",
+			r.task.Type, r.alloc.ID, err)
+		return
+	}
+	r.logger.Infof("agent: Updated the config of task %q for alloc %q: %v", r.task.Type, r.alloc.ID, config)
+}
+
+// CheckHealth returns the reason why the task is not healthy, or nil. See driver.HealthChecker.
+func (r *Worker) CheckHealth() error {
+	r.handleLock.Lock()
+	handle := r.handle
+	r.handleLock.Unlock()
+	if handle == nil {
+		return fmt.Errorf("task %v is not running", r.task.Type)
+	}
+	checker, ok := handle.(driver.HealthChecker)
+	if !ok {
+		return nil
+	}
+	if err := checker.CheckHealth(); err != nil {
+		return fmt.Errorf("task %v: %v", r.task.Type, err)
+	}
+	return nil
+}
+
+// emitDriverEvent records a message from the driver as a task event.
+func (r *Worker) emitDriverEvent(m string, args ...interface{}) {
+	r.emitTaskEvent(models.TaskDriverMessage, m, args...)
+}
+
+func (r *Worker) emitTaskEvent(eventType string, m string, args ...interface{}) {
+	msg := fmt.Sprintf(m, args...)
+	r.setState("", models.NewTaskEvent(eventType).SetDriverMessage(msg))
+}
+
+// incrCounter increments a metric of the task, e.g. the messages dropped by the driver.
+func (r *Worker) incrCounter(key []string, val float32) {
+	if r.config.PublishAllocationMetrics {
+		metrics.IncrCounterWithLabels(key, val, r.metricsLabels())
+	}
+}
+
 // collectResourceUsageStats starts collecting resource usage stats of a Task.
 // Collection ends when the passed channel is closed
 func (r *Worker) collectResourceUsageStats(stopCollection <-chan struct{}) {
@@ -724,7 +821,7 @@ func (r *Worker) UnblockStart(source string) {
 		"taskType": r.task.Type,
 		"allocId":  r.alloc.ID,
 		"source":   source,
-	}).Debugf("agent: Unblocking task for alloc ", r.task.Type, r.alloc.ID, source)
+	}).Debugf("agent: Unblocking task %v for alloc %v", r.task.Type, r.alloc.ID)
 	r.unblocked = true
 	close(r.unblockCh)
 }
@@ -753,7 +850,7 @@ func (r *Worker) Destroy(event *models.TaskEvent) {
 // emitStats emits resource usage stats of tasks to remote metrics collector
 // sinks
 func (r *Worker) emitStats(ru *models.TaskStatistics) {
-	labels := []metrics.Label{{"task_name", fmt.Sprintf("%s_%s", r.alloc.Job.Name, r.alloc.Task)}}
+	labels := r.metricsLabels()
 	if r.config.PublishAllocationMetrics {
 		metrics.SetGaugeWithLabels([]string{"network", "in_msgs"}, float32(ru.MsgStat.InMsgs), labels)
 		metrics.SetGaugeWithLabels([]string{"network", "out_msgs"}, float32(ru.MsgStat.OutMsgs), labels)
@@ -780,4 +877,32 @@ func (r *Worker) emitStats(ru *models.TaskStatistics) {
 		metrics.SetGaugeWithLabels([]string{"throughput", "num"}, float32(ru.ThroughputStat.Num), labels)
 		metrics.SetGaugeWithLabels([]string{"throughput", "time"}, float32(ru.ThroughputStat.Time), labels)
 	}
+
+	if ru.NatsSubStats != nil && r.config.PublishAllocationMetrics {
+		metrics.SetGaugeWithLabels([]string{"nats", "pending_msgs"}, float32(ru.NatsSubStats.PendingMsgs), labels)
+		metrics.SetGaugeWithLabels([]string{"nats", "pending_bytes"}, float32(ru.NatsSubStats.PendingBytes), labels)
+	}
+
+	if ru.IncrAckStats != nil && r.config.PublishAllocationMetrics {
+		metrics.SetGaugeWithLabels([]string{"incr_ack", "transactions"}, float32(ru.IncrAckStats.Transactions), labels)
+		metrics.SetGaugeWithLabels([]string{"incr_ack", "pieces"}, float32(ru.IncrAckStats.Pieces), labels)
+	}
+
+	if ru.ThrottleStats != nil && r.config.PublishAllocationMetrics {
+		metrics.SetGaugeWithLabels([]string{"throttle", "memory"}, boolGauge(ru.ThrottleStats.Memory), labels)
+		metrics.SetGaugeWithLabels([]string{"throttle", "cpu"}, boolGauge(ru.ThrottleStats.CPU), labels)
+		metrics.SetGaugeWithLabels([]string{"throttle", "memory_usage_mb"}, float32(ru.ThrottleStats.MemoryUsageMB), labels)
+		metrics.SetGaugeWithLabels([]string{"throttle", "cpu_pct"}, float32(ru.ThrottleStats.CPUPct), labels)
+	}
+}
+
+func boolGauge(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (r *Worker) metricsLabels() []metrics.Label {
+	return []metrics.Label{{"task_name", fmt.Sprintf("%s_%s", r.alloc.Job.Name, r.alloc.Task)}}
 }
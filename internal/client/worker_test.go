@@ -8,17 +8,17 @@ package client
 
 import (
 	"reflect"
-	"sync"
 	"testing"
+
 	"github.com/actiontech/dtle/internal/client/driver"
 	"github.com/actiontech/dtle/internal/config"
-	log "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/models"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewWorker(t *testing.T) {
 	type args struct {
-		logger      *log.Logger
+		logger      *logrus.Logger
 		config      *config.ClientConfig
 		updater     TaskStateUpdater
 		alloc       *models.Allocation
@@ -45,29 +45,23 @@ func TestWorker_MarkReceived(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -84,25 +78,19 @@ func TestWorker_MarkReceived(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.MarkReceived()
 		})
@@ -113,29 +101,23 @@ func TestWorker_WaitCh(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -153,25 +135,19 @@ func TestWorker_WaitCh(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if got := r.WaitCh(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Worker.WaitCh() = %v, want %v", got, tt.want)
@@ -184,29 +160,23 @@ func TestWorker_stateFilePath(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -224,25 +194,19 @@ func TestWorker_stateFilePath(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if got := r.stateFilePath(); got != tt.want {
 				t.Errorf("Worker.stateFilePath() = %v, want %v", got, tt.want)
@@ -255,29 +219,23 @@ func TestWorker_SaveState(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -295,25 +253,19 @@ func TestWorker_SaveState(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if err := r.SaveState(); (err != nil) != tt.wantErr {
 				t.Errorf("Worker.SaveState() error = %v, wantErr %v", err, tt.wantErr)
@@ -326,29 +278,23 @@ func TestWorker_DestroyState(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -366,25 +312,19 @@ func TestWorker_DestroyState(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if err := r.DestroyState(); (err != nil) != tt.wantErr {
 				t.Errorf("Worker.DestroyState() error = %v, wantErr %v", err, tt.wantErr)
@@ -397,29 +337,23 @@ func TestWorker_setState(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		state string
@@ -441,25 +375,19 @@ func TestWorker_setState(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.setState(tt.args.state, tt.args.event)
 		})
@@ -470,29 +398,23 @@ func TestWorker_createDriver(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -511,25 +433,19 @@ func TestWorker_createDriver(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			got, err := r.createDriver()
 			if (err != nil) != tt.wantErr {
@@ -547,29 +463,23 @@ func TestWorker_Run(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -586,25 +496,19 @@ func TestWorker_Run(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.Run()
 		})
@@ -615,29 +519,23 @@ func TestWorker_prestart(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		resultCh chan bool
@@ -658,25 +556,19 @@ func TestWorker_prestart(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.prestart(tt.args.resultCh)
 		})
@@ -687,29 +579,23 @@ func TestWorker_run(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -726,25 +612,19 @@ func TestWorker_run(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.run()
 		})
@@ -755,29 +635,23 @@ func TestWorker_shouldRestart(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -795,25 +669,19 @@ func TestWorker_shouldRestart(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if got := r.shouldRestart(); got != tt.want {
 				t.Errorf("Worker.shouldRestart() = %v, want %v", got, tt.want)
@@ -826,29 +694,23 @@ func TestWorker_killTask(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		killingEvent *models.TaskEvent
@@ -869,25 +731,19 @@ func TestWorker_killTask(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.killTask(tt.args.killingEvent)
 		})
@@ -898,29 +754,23 @@ func TestWorker_startTask(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name    string
@@ -938,25 +788,19 @@ func TestWorker_startTask(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if err := r.startTask(); (err != nil) != tt.wantErr {
 				t.Errorf("Worker.startTask() error = %v, wantErr %v", err, tt.wantErr)
@@ -969,29 +813,23 @@ func TestWorker_collectResourceUsageStats(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		stopCollection <-chan struct{}
@@ -1012,25 +850,19 @@ func TestWorker_collectResourceUsageStats(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.collectResourceUsageStats(tt.args.stopCollection)
 		})
@@ -1041,29 +873,23 @@ func TestWorker_LatestTaskStats(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name   string
@@ -1081,25 +907,19 @@ func TestWorker_LatestTaskStats(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if got := r.LatestTaskStats(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Worker.LatestTaskStats() = %v, want %v", got, tt.want)
@@ -1112,29 +932,23 @@ func TestWorker_handleDestroy(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	tests := []struct {
 		name          string
@@ -1153,25 +967,19 @@ func TestWorker_handleDestroy(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			gotDestroyed, err := r.handleDestroy()
 			if (err != nil) != tt.wantErr {
@@ -1189,29 +997,23 @@ func TestWorker_Restart(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		source string
@@ -1233,25 +1035,19 @@ func TestWorker_Restart(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.Restart(tt.args.source, tt.args.reason)
 		})
@@ -1262,29 +1058,23 @@ func TestWorker_Kill(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		source string
@@ -1307,25 +1097,19 @@ func TestWorker_Kill(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.Kill(tt.args.source, tt.args.reason, tt.args.fail)
 		})
@@ -1336,29 +1120,23 @@ func TestWorker_UnblockStart(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		source string
@@ -1379,25 +1157,19 @@ func TestWorker_UnblockStart(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.UnblockStart(tt.args.source)
 		})
@@ -1408,29 +1180,23 @@ func TestWorker_waitErrorToEvent(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		res *models.WaitResult
@@ -1452,25 +1218,19 @@ func TestWorker_waitErrorToEvent(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			if got := r.waitErrorToEvent(tt.args.res); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Worker.waitErrorToEvent() = %v, want %v", got, tt.want)
@@ -1483,29 +1243,23 @@ func TestWorker_Destroy(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		event *models.TaskEvent
@@ -1526,25 +1280,19 @@ func TestWorker_Destroy(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.Destroy(tt.args.event)
 		})
@@ -1555,29 +1303,23 @@ func TestWorker_emitStats(t *testing.T) {
 	type fields struct {
 		config          *config.ClientConfig
 		updater         TaskStateUpdater
-		logger          *log.Logger
+		logger          *logrus.Logger
 		alloc           *models.Allocation
 		restartTracker  *RestartTracker
 		running         bool
-		runningLock     sync.Mutex
 		taskStats       *models.TaskStatistics
-		taskStatsLock   sync.RWMutex
 		task            *models.Task
 		handle          driver.DriverHandle
-		handleLock      sync.Mutex
 		payloadRendered bool
 		startCh         chan struct{}
 		unblockCh       chan struct{}
 		unblocked       bool
-		unblockLock     sync.Mutex
 		restartCh       chan *models.TaskEvent
 		destroy         bool
 		destroyCh       chan struct{}
-		destroyLock     sync.Mutex
 		destroyEvent    *models.TaskEvent
 		workUpdates     chan *models.TaskUpdate
 		waitCh          chan struct{}
-		persistLock     sync.Mutex
 	}
 	type args struct {
 		ru *models.TaskStatistics
@@ -1598,25 +1340,19 @@ func TestWorker_emitStats(t *testing.T) {
 				alloc:           tt.fields.alloc,
 				restartTracker:  tt.fields.restartTracker,
 				running:         tt.fields.running,
-				runningLock:     tt.fields.runningLock,
 				taskStats:       tt.fields.taskStats,
-				taskStatsLock:   tt.fields.taskStatsLock,
 				task:            tt.fields.task,
 				handle:          tt.fields.handle,
-				handleLock:      tt.fields.handleLock,
 				payloadRendered: tt.fields.payloadRendered,
 				startCh:         tt.fields.startCh,
 				unblockCh:       tt.fields.unblockCh,
 				unblocked:       tt.fields.unblocked,
-				unblockLock:     tt.fields.unblockLock,
 				restartCh:       tt.fields.restartCh,
 				destroy:         tt.fields.destroy,
 				destroyCh:       tt.fields.destroyCh,
-				destroyLock:     tt.fields.destroyLock,
 				destroyEvent:    tt.fields.destroyEvent,
 				workUpdates:     tt.fields.workUpdates,
 				waitCh:          tt.fields.waitCh,
-				persistLock:     tt.fields.persistLock,
 			}
 			r.emitStats(tt.args.ru)
 		})
@@ -7,9 +7,13 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -33,7 +37,67 @@ const (
 	defaultNumRetries = 5
 	defaultChunkSize  = 2000
 	defaultNumWorkers = 1
+	defaultSpareConns = 10
 	defaultMsgBytes   = 20 * 1024
+
+	defaultDeleteBatchSize   = 500
+	defaultApplyBatchSize    = 1
+	defaultApplyBatchTimeout = 100
+	defaultMaxLagWindow      = 60
+	defaultReplicaMaxLag     = 10
+	defaultHeartbeatPeriod   = 3
+	defaultReorderWindow     = 64
+	defaultMaxRecentErrors   = 20
+	defaultZeroDateConvertTo = "1970-01-01"
+)
+
+// Values of MySQLDriverConfig.OnPurgedGtid
+const (
+	OnPurgedGtidError       = "error"
+	OnPurgedGtidEarliest    = "earliest"
+	OnPurgedGtidRestartDump = "restart-dump"
+)
+
+// Values of MySQLDriverConfig.DisableForeignKeyChecks
+const (
+	DisableForeignKeyChecksAll  = "all"  // the full copy and the incremental replication
+	DisableForeignKeyChecksDump = "dump" // the full copy only
+	DisableForeignKeyChecksNone = "none"
+)
+
+// Values of MySQLDriverConfig.CollationMismatch
+const (
+	CollationMismatchWarn  = "warn"  // log and record the mismatch
+	CollationMismatchError = "error" // fail the task
+	// replace the collations the destination does not support with supported equivalents before creating
+	// the tables, e.g. utf8mb4_0900_ai_ci with utf8mb4_unicode_520_ci, and warn on a remaining mismatch.
+	CollationMismatchEquivalent = "equivalent"
+)
+
+// Values of MySQLDriverConfig.OnApplyError
+const (
+	OnApplyErrorHalt       = "halt"
+	OnApplyErrorSkip       = "skip"
+	OnApplyErrorDeadLetter = "deadletter"
+)
+
+// Values of MySQLDriverConfig.DumpMsgCompression
+const (
+	DumpMsgCompressionSnappy = "snappy" // fast, as the incremental copy
+	DumpMsgCompressionGzip   = "gzip"   // smaller and slower, by DumpMsgCompressionLevel
+)
+
+// Values of MySQLDriverConfig.ZeroDatePolicy
+const (
+	ZeroDatePolicyError   = "error"   // write the values as they are. A destination in strict mode fails on them
+	ZeroDatePolicyNull    = "null"    // write NULL
+	ZeroDatePolicyConvert = "convert" // write ZeroDateConvertTo
+)
+
+// Values of MySQLDriverConfig.IdentifierQuote
+const (
+	IdentifierQuoteBacktick = "backtick" // `name`, MySQL's own, valid in any sql_mode
+	IdentifierQuoteANSI     = "ansi"     // "name", standard SQL, for a destination in ANSI_QUOTES sql_mode
 )
 
 // RPCHandler can be provided to the Client if there is a local server
@@ -101,6 +165,47 @@ type ClientConfig struct {
 	// NoHostUUID disables using the host's UUID and will force generation of a
 	// random UUID.
 	NoHostUUID bool
+
+	// Notifications are webhooks notified of job lifecycle events
+	Notifications []*NotificationConfig
+}
+
+// Job lifecycle events for NotificationConfig.Events
+const (
+	NotificationEventStarted        = "started"
+	NotificationEventDumpComplete   = "dump_complete"
+	NotificationEventDumpIncomplete = "dump_incomplete"
+	NotificationEventFailed         = "failed"
+	NotificationEventStopped        = "stopped"
+	NotificationEventLagExceeded    = "lag_exceeded"
+	NotificationEventDiskFull       = "disk_full"
+	NotificationEventRecovered      = "recovered"
+)
+
+// NotificationConfig is a webhook notified of job lifecycle events
+type NotificationConfig struct {
+	// URL receives a POST with a JSON body for each event
+	URL string `mapstructure:"url"`
+
+	// Events to be notified. Empty means all events.
+	Events []string `mapstructure:"events"`
+
+	// Secret, if set, is used to sign the body with HMAC-SHA256.
+	// The signature is in the header X-Dtle-Signature.
+	Secret string `mapstructure:"secret"`
+}
+
+// Wants returns true if the webhook is to be notified of the event
+func (n *NotificationConfig) Wants(event string) bool {
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, e := range n.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *ClientConfig) Copy() *ClientConfig {
@@ -132,20 +237,76 @@ type MySQLDriverConfig struct {
 	TotalTransferredBytes               int
 	MaxRetries                          int64
 	ChunkSize                           int64
+	ChunkSizeBytes                      int64 // bytes of a chunk of the full copy, instead of ChunkSize rows. 0: by ChunkSize.
 	SqlFilter                           []string
 	RowsEstimate                        int64
 	DeltaEstimate                       int64
-	TimeZone                            string
+	TimeZone                            string // session time_zone on both sides. empty: the global time_zone of each server.
 	GroupCount                          int
 	GroupMaxSize                        int
 	GroupTimeout                        int // millisecond
+	DeleteBatchSize                     int // max rows in one batched delete. 1 disables batching.
+	ApplyBatchSize                      int // rows. commit the destination transaction when reached.
+	ApplyBatchTimeout                   int // millisecond. commit the destination transaction when reached.
+	ApplyEventRateLimit                 int // transactions per second applied on the destination. 0: unlimited.
+	// seconds. on a source task: the full copy stops at a chunk boundary once it has run for this long, and the
+	// incremental copy starts. The tables not fully copied are reported, to be copied again by ResyncTable.
+	// 0 (default): no limit.
+	MaxDumpDuration int
+	// bytes. on a destination task: a row larger than it is written in pieces of this size,
+	// by appending to its large columns. 0: half of max_allowed_packet of the destination.
+	LargeRowSize int
+	// seconds. on a destination task: a statement applying a transaction of the incremental copy is interrupted
+	// if it runs for longer, e.g. waiting on a lock. The transaction is rolled back, and the task restarts from
+	// its checkpoint. DDL and commits are not interrupted. 0 (default): no timeout.
+	ApplyStatementTimeout int
+	// seconds. on a destination task: apply each transaction of the incremental copy only when it is this old
+	// by its binlog timestamp, like MASTER_DELAY of MySQL, e.g. to stop the task before a bad change is applied.
+	// The transactions held are kept in memory up to a limit, and spilled to files under the state dir beyond it.
+	// 0 (default): no delay.
+	ReplicationDelay int
+	// on a destination task: apply each statement in autocommit mode instead of in transactions, for a
+	// destination which does not support them, e.g. a non-transactional storage engine. A source transaction
+	// might be applied in part on a failure, and is applied again from its start on the restart, so that a
+	// table without a primary key might get duplicate rows. The GTID of a transaction is recorded after its
+	// statements, and a failure to record it is only logged: the transaction is then applied again on a
+	// restart. The checkpoint still advances by whole source transactions.
+	NonTransactionalApply bool
+	// on a destination task: the task is paused rather than restarted over and over when the destination fails
+	// to write for lack of space, e.g. on MySQL error 1021 (disk full). If set, the destination is checked
+	// periodically, and the task resumes from its checkpoint when it can be written to again. Otherwise the task
+	// stays paused until the job is restarted.
+	AutoResumeOnRecover bool
 
-	Gtid                     string
+	Gtid                    string
 	BinlogFile               string
 	BinlogPos                int64
 	GtidStart                string
+	// on a source task: the statements recording the coordinate of an existing mysqldump of the source,
+	// i.e. "SET @@GLOBAL.GTID_PURGED='...'" (--set-gtid-purged) and/or "CHANGE MASTER TO MASTER_LOG_FILE='...',
+	// MASTER_LOG_POS=..." (--master-data), as at the head of the dump. The full copy is skipped, and the
+	// incremental copy starts from the coordinate. The dump is to be loaded into the destination by the user.
+	MysqldumpCoordinate string
 	AutoGtid                 bool // For internal use. Might be changed without notification.
+	DumpCheckpoint           string // For internal use. JSON of DumpCheckpoint.
 	BinlogRelay              bool
+	ServerId                 uint32 // server_id of the binlog connection to the source. 0: a random one.
+	// on a source task: seconds. the source sends a heartbeat on the binlog connection after being idle for
+	// so long, and the connection is reconnected if nothing is received for twice as long. Must be less than
+	// net_write_timeout of the source. default 3.
+	HeartbeatPeriod          int
+	// on a source task: servers which may take over as the primary of the source, as "host:port", e.g. the other
+	// members of an HA group, or a VIP. If the binlog stream from the source fails, the extractor looks for the
+	// source (ConnectionConfig) or the first of them which has all transactions extracted so far, and resumes
+	// the stream on it from the extracted GTID set. A switch to another server is reported as a task event.
+	// The user and password of ConnectionConfig are used. Not supported with BinlogRelay, Tunnel or MariaDB.
+	SourceCandidates []string
+	// on a source task: read the binlog as a semisync replica, if rpl_semi_sync_master_enabled is ON on the source.
+	// A transaction is ACKed once it has been applied by all the destination tasks, i.e. it is in the checkpoints
+	// committed there, or it is not replicated. Commits on the source wait for the ACK, up to
+	// rpl_semi_sync_master_timeout, and with rpl_semi_sync_master_wait_for_slave_count=1 no longer than for the
+	// fastest replica. Requires MySQL 5.7 or later. Not supported with BinlogRelay.
+	SemiSync bool
 	NatsAddr                 string
 	ParallelWorkers          int
 	ConnectionConfig         *umconf.ConnectionConfig
@@ -155,7 +316,11 @@ type MySQLDriverConfig struct {
 	BinlogRowImage           string
 	SqlMode                  string
 	MySQLVersion             string
+	// aurora_version of the source if it is Aurora MySQL. See Inspector.readAuroraVersion.
+	AuroraVersion            string
 	MySQLServerUuid          string
+	// lower_case_table_names of the source. Table names are compared case-insensitively if not 0.
+	LowerCaseTableNames      int
 	StartTime                time.Time
 	RowCopyStartTime         time.Time
 	RowCopyEndTime           time.Time
@@ -167,10 +332,255 @@ type MySQLDriverConfig struct {
 	ApproveHeterogeneous bool
 	SkipCreateDbTable    bool
 
+	// replace ENGINE / the default charset of the tables created by the full copy if not empty.
+	CreateTableEngine  string
+	CreateTableCharset string
+	// on a destination task: what to do if a table created by the full copy does not get the collations of
+	// its statement, e.g. as a collation the destination does not support is stripped. The collations of the
+	// table and its columns are read back after creating it. One of CollationMismatchWarn etc. Empty: warn.
+	CollationMismatch string
+
 	CountingRowsFlag            int64
 
 	SkipPrivilegeCheck  bool
 	SkipIncrementalCopy bool
+	// what to do if the binlog to be extracted has been purged on the source. See OnPurgedGtidError etc.
+	OnPurgedGtid string
+	// set AUTO_INCREMENT of destination tables to that of the source after full copy.
+	PreserveAutoIncrement bool
+	// replicate row events (DML) / query events (DDL) in incremental copy. Both default to true.
+	// The full copy is skipped if only DDL is replicated.
+	ReplicateDML *bool
+	ReplicateDDL *bool
+	// on a source task: do not replicate DROP DATABASE, DROP TABLE and TRUNCATE, so that the data on the
+	// destination is not lost by such a statement on the source. The other DDL and DML are replicated.
+	// A skipped statement is reported as a task event.
+	SkipDropStatements bool
+	// on a source task: do not replicate the DML transactions of these users of the source, e.g. an archiver
+	// deleting old rows. Best-effort: the binlog does not record the user, so it is looked up in the processlist
+	// of the source by the thread of the transaction, when the transaction is read. See binlog.sourceUserFilter.
+	IgnoreSourceUsers []string
+	// statements executed on the destination before the first load and after the last table of the full copy.
+	PreDumpSQL  []*DumpSQL
+	PostDumpSQL []*DumpSQL
+	// what to do if a row fails to apply on the destination. See OnApplyErrorHalt etc.
+	OnApplyError string
+	// when the destination sessions run with foreign_key_checks=0. See DisableForeignKeyChecksAll etc.
+	DisableForeignKeyChecks string
+	// where OnApplyError=deadletter records the failing rows. A table ("table" in the dtle schema,
+	// or "schema.table") on the destination takes precedence over a local file.
+	// If neither is set, rows are written to a file in the state dir.
+	DeadLetterFile  string
+	DeadLetterTable string
+	// connection pool of the destination. Each worker holds a connection of it.
+	// MaxOpenConns defaults to ParallelWorkers + 10, and MaxIdleConns to MaxOpenConns.
+	MaxOpenConns int
+	MaxIdleConns int
+	// seconds. stop the job if the replication lag stays above MaxLagBeforeStop for MaxLagWindow.
+	// 0 (default) disables the check. MaxLagWindow defaults to 60.
+	MaxLagBeforeStop int
+	MaxLagWindow     int
+	// on a destination task: seconds. GET /v1/agent/allocation/<ID>/health reports the task unhealthy while
+	// the replication lag exceeds it. 0 (default): the lag is not checked.
+	HealthMaxLag int
+	// on a destination task: replicas of the destination, as "host:port", connected to with the user and
+	// password of ConnectionConfig. While the Seconds_Behind_Master of any of them exceeds ThrottleReplicaMaxLag
+	// (seconds, default 10), the applier slows down more and more, and it speeds up again as they catch up.
+	// A replica whose replication is stopped counts as lagging. One which cannot be queried is ignored.
+	ThrottleReplicas      []string
+	ThrottleReplicaMaxLag int
+	// on a destination task: priorities of the tables, as "schema.table" (names on the destination), e.g. 0 for
+	// a parent table and 1 for its child. Unlisted tables have priority 0. Within each transaction, the rows of
+	// the incremental copy are inserted and updated in the order of priority, and deleted in the reverse order,
+	// so that they satisfy foreign keys. Only with foreign key checks on, i.e. DisableForeignKeyChecks=dump or none.
+	TablePriority map[string]int
+	// on a destination task: tables, as "schema.table" on the destination, of which the full copy is written with
+	// INSERT IGNORE instead of REPLACE, so that the rows already on the destination (e.g. appended to since an
+	// earlier full copy) are kept rather than overwritten. The incremental replication is not affected.
+	DumpInsertIgnore []string
+	// on a destination task: log each statement applied by the incremental copy, with its arguments and the gtid
+	// of its transaction, at debug level. At most LogAppliedSQLRateLimit (default 100) statements are logged per
+	// second; the others are skipped and counted in the next one logged. The values of LogAppliedSQLRedact
+	// columns, as "schema.table.column" on the destination, are logged as "***".
+	LogAppliedSQL          bool
+	LogAppliedSQLRateLimit int
+	LogAppliedSQLRedact    []string
+	// on both the source and the destination tasks: the key of the EncryptColumns of the tables (AES-GCM), the
+	// same on all the tasks of a job. base64 of 16, 24 or 32 bytes, or "env:NAME" to read it from the environment
+	// variable NAME of the agent, e.g. as set by a key management service.
+	EncryptionKey string
+	// For internal use. The number of destination tasks of the job, set on the source task if more than 1.
+	// See models.IsDestTaskType.
+	DestCount int
+	// on a destination task: apply only the changes of these schemas and tables, as named on the destination
+	// (i.e. after TableSchemaRename / TableRename). Empty: all the changes from the source.
+	DestDoDb []*DataSource
+	// on a destination task: values of the columns which the destination tables have and the source ones do not.
+	ColumnDefault []*ColumnDefault
+	// on a destination task: tables split into shard tables, e.g. t into t_0 ... t_3, by the hash of a column.
+	// The shard tables are created with CREATE TABLE ... LIKE the table on the full copy. See ShardBy.
+	ShardBy []*ShardBy
+	// on a destination task: cast the values of these columns explicitly when writing them, for columns of
+	// different types on the source and the destination. Narrowings which may lose data are warned about
+	// in the log when the applier first meets a table.
+	ColumnTypeOverride []*ColumnTypeOverride
+	// on a destination task: apply UPDATE and DELETE on tables without a primary key, matching the rows by
+	// all the columns. It is slow, and imprecise for e.g. FLOAT columns. If KeylessLimitOne (default true),
+	// only one of duplicate rows is changed.
+	AllowKeylessTables bool
+	KeylessLimitOne    *bool
+	// on a destination task: what is written for the invalid values of DATE, DATETIME and TIMESTAMP columns,
+	// i.e. zero dates (0000-00-00) and dates with a zero or out-of-range month or day, in both the full copy
+	// and the incremental replication. See ZeroDatePolicyError (default) and the others. ZeroDateConvertTo is
+	// a date (default 1970-01-01), to which DATETIME and TIMESTAMP values get the time 00:00:00.
+	ZeroDatePolicy    string
+	ZeroDateConvertTo string
+	// on a destination task: how the schema, table and column names are quoted in the statements generated for
+	// the destination, in both the full copy and the incremental replication. See IdentifierQuoteBacktick
+	// (default) and IdentifierQuoteANSI. DDL statements are applied as they are written on the source.
+	IdentifierQuote string
+	// on a source task: the SystemSchemas to be replicated. The others are excluded even if in ReplicateDoDb.
+	ReplicateSystemSchemas []string
+	// on a source task: the max number of transactions of the incremental copy sent to the destinations and
+	// not applied by them yet. Reading the binlog pauses when it is reached. 0 (default): no limit.
+	InFlightWindow int
+	// on a source task: send a transaction of more than this number of rows in pieces. A destination applies
+	// the pieces in one transaction, and acknowledges each of them for InFlightWindow before it commits,
+	// so that a big transaction does not stall the window. 0 (default): no splitting.
+	BigTxSplittingSize int
+	// on a destination task: the max number of messages of the incremental copy received ahead of a missing one,
+	// e.g. delivered out of order after a reconnect, which are held to be applied in order. The task restarts
+	// from its checkpoint if the gap is not filled within them. default 64.
+	ReorderWindow int
+	// on a destination task: statements executed on each new connection to the destination, e.g. SET of
+	// session variables. Behind a proxy such as ProxySQL, a statement executed once may land on another backend.
+	SessionInit []string
+	// session variables of the connections to the source and the destination, e.g. "sql_mode", "wait_timeout".
+	// An unknown variable fails the task on start.
+	SrcSessionVars  map[string]string
+	DestSessionVars map[string]string
+	// the number of the last errors of a task kept for GET /v1/job/<ID>/errors. default 20.
+	MaxRecentErrors int
+	// on a source task: also write the data sent to the destinations to this file, to be replayed with ReplayFile.
+	EventSinkFile string
+	// on a destination task: apply the data in this file written with EventSinkFile, instead of receiving it
+	// from the source task.
+	ReplayFile string
+	// on a destination task: resolve conflicts of active-active replication by this column, e.g. a version or
+	// an update time. An insert or update of the incremental copy is applied only if its value of the column is
+	// newer than that of the existing row. A NULL is older than any value. On a tie, the change from the server
+	// with the greater server_uuid wins. Tables without the column or a primary key are applied as usual.
+	// Empty (default): the changes always overwrite.
+	ConflictResolutionColumn string
+	// on a destination task: load the rows of the full copy with LOAD DATA LOCAL INFILE, which is faster than INSERT.
+	// It requires local_infile=ON on the destination, otherwise INSERT is used.
+	LoadDataInfile bool
+	// on a destination task: rules rewriting the DDL statements, of both the full copy and the incremental copy,
+	// before they are executed, e.g. to force ENGINE=InnoDB. The rules are applied in order.
+	DdlRewrite []*DdlRewriteRule
+	// on a source task: percentages of the memory / CPU limits of the cgroup dtle runs in (e.g. of its container).
+	// When the usage exceeds it, the full copy reads smaller chunks and buffers fewer of them (memory), or
+	// pauses between the chunks (CPU). Without a CPU limit, the CPU usage is of all the CPUs. 0 (default): disabled.
+	ThrottleMemoryPct int
+	ThrottleCPUPct    int
+	// on a source task: copy this table again while the incremental copy of the others goes on. Set with
+	// POST /v1/job/<ID>/resync. It is taken when changed on a running task, not on the start of a task.
+	ResyncTable *ResyncTable
+	// on a source task: the compression of the messages of the full copy (and of a resync), which carry far more
+	// data than those of the incremental copy, always compressed by snappy. See DumpMsgCompressionSnappy etc.
+	// DumpMsgCompressionLevel is the level of gzip, 1 (fastest) to 9 (smallest). 0: the default of gzip (6).
+	// The destination tells the method of each message.
+	DumpMsgCompression      string
+	DumpMsgCompressionLevel int
+}
+
+// ResyncTable is a request to copy a table again. See MySQLDriverConfig.ResyncTable.
+type ResyncTable struct {
+	// unique to each request
+	ID          string
+	TableSchema string
+	TableName   string
+}
+
+// DdlRewriteRule replaces the matches of Pattern, a regular expression of Go (RE2), in a DDL statement
+// with Replacement, which may refer to the groups of Pattern as $1 or ${name}.
+type DdlRewriteRule struct {
+	Pattern     string
+	Replacement string
+}
+
+// SystemSchemas are the schemas of MySQL itself. Replicating them usually breaks the destination,
+// so they are excluded unless listed in MySQLDriverConfig.ReplicateSystemSchemas.
+var SystemSchemas = []string{"mysql", "sys", "performance_schema", "information_schema"}
+
+// IsExcludedSystemSchema tells whether schemaName is one of SystemSchemas not in ReplicateSystemSchemas.
+func (m *MySQLDriverConfig) IsExcludedSystemSchema(schemaName string) bool {
+	for _, s := range SystemSchemas {
+		if strings.EqualFold(s, schemaName) {
+			for _, r := range m.ReplicateSystemSchemas {
+				if strings.EqualFold(r, schemaName) {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludedSystemSchemas returns the SystemSchemas not in ReplicateSystemSchemas.
+func (m *MySQLDriverConfig) ExcludedSystemSchemas() (schemas []string) {
+	for _, s := range SystemSchemas {
+		if m.IsExcludedSystemSchema(s) {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+// ColumnDefault is the values of the destination-only columns of a table, named as on the destination.
+// The other columns of the destination table are matched with those of the source in order.
+type ColumnDefault struct {
+	TableSchema string
+	TableName   string
+	// column name to value. A value is a literal, or one of the functions like NOW() (see the applier).
+	Columns map[string]string
+}
+
+// FindColumnDefault returns the ColumnDefault of schemaName.tableName in columnDefaults, or nil.
+func FindColumnDefault(columnDefaults []*ColumnDefault, schemaName string, tableName string) *ColumnDefault {
+	for _, cd := range columnDefaults {
+		if cd.TableSchema == schemaName && cd.TableName == tableName {
+			return cd
+		}
+	}
+	return nil
+}
+
+// ColumnTypeOverride is the types of the columns of a table, named as on the destination, which their values
+// are cast to.
+type ColumnTypeOverride struct {
+	TableSchema string
+	TableName   string
+	// column name to a type of CAST, e.g. SIGNED, UNSIGNED, DECIMAL(20,4), CHAR(10), DATETIME(6).
+	Columns map[string]string
+}
+
+// FindColumnTypeOverride returns the ColumnTypeOverride of schemaName.tableName in overrides, or nil.
+func FindColumnTypeOverride(overrides []*ColumnTypeOverride, schemaName string, tableName string) *ColumnTypeOverride {
+	for _, o := range overrides {
+		if o.TableSchema == schemaName && o.TableName == tableName {
+			return o
+		}
+	}
+	return nil
+}
+
+// DumpSQL is a statement in PreDumpSQL or PostDumpSQL
+type DumpSQL struct {
+	SQL string
+	// If true, an error of the statement is logged and the job continues.
+	IgnoreError bool
 }
 
 func (a *MySQLDriverConfig) SetDefault() *MySQLDriverConfig {
@@ -185,9 +595,30 @@ func (a *MySQLDriverConfig) SetDefault() *MySQLDriverConfig {
 	if result.ReplChanBufferSize <= 0 {
 		result.ReplChanBufferSize = channelBufferSize
 	}
+	if result.MaxRecentErrors <= 0 {
+		result.MaxRecentErrors = defaultMaxRecentErrors
+	}
 	if result.ParallelWorkers <= 0 {
 		result.ParallelWorkers = defaultNumWorkers
 	}
+	if result.MaxOpenConns <= 0 {
+		result.MaxOpenConns = result.ParallelWorkers + defaultSpareConns
+	}
+	if result.MaxIdleConns <= 0 {
+		result.MaxIdleConns = result.MaxOpenConns
+	}
+	if result.MaxLagWindow <= 0 {
+		result.MaxLagWindow = defaultMaxLagWindow
+	}
+	if result.ThrottleReplicaMaxLag <= 0 {
+		result.ThrottleReplicaMaxLag = defaultReplicaMaxLag
+	}
+	if result.HeartbeatPeriod <= 0 {
+		result.HeartbeatPeriod = defaultHeartbeatPeriod
+	}
+	if result.ReorderWindow <= 0 {
+		result.ReorderWindow = defaultReorderWindow
+	}
 	if result.MsgBytesLimit <= 0 {
 		result.MsgBytesLimit = defaultMsgBytes
 	}
@@ -200,6 +631,42 @@ func (a *MySQLDriverConfig) SetDefault() *MySQLDriverConfig {
 	if result.GroupTimeout == 0 {
 		result.GroupTimeout = 100
 	}
+	if result.DeleteBatchSize <= 0 {
+		result.DeleteBatchSize = defaultDeleteBatchSize
+	}
+	if result.ApplyBatchSize <= 0 {
+		result.ApplyBatchSize = defaultApplyBatchSize
+	}
+	if result.ApplyBatchTimeout <= 0 {
+		result.ApplyBatchTimeout = defaultApplyBatchTimeout
+	}
+	if result.OnPurgedGtid == "" {
+		result.OnPurgedGtid = OnPurgedGtidError
+	}
+	if result.OnApplyError == "" {
+		result.OnApplyError = OnApplyErrorHalt
+	}
+	if result.ZeroDatePolicy == "" {
+		result.ZeroDatePolicy = ZeroDatePolicyError
+	}
+	if result.ZeroDateConvertTo == "" {
+		result.ZeroDateConvertTo = defaultZeroDateConvertTo
+	}
+	if result.IdentifierQuote == "" {
+		result.IdentifierQuote = IdentifierQuoteBacktick
+	}
+	if result.DisableForeignKeyChecks == "" {
+		result.DisableForeignKeyChecks = DisableForeignKeyChecksAll
+	}
+	if result.ReplicateDML == nil {
+		result.ReplicateDML = internal.BoolToPtr(true)
+	}
+	if result.ReplicateDDL == nil {
+		result.ReplicateDDL = internal.BoolToPtr(true)
+	}
+	if result.KeylessLimitOne == nil {
+		result.KeylessLimitOne = internal.BoolToPtr(true)
+	}
 
 	// TODO temporarily (or permanently) disable homogeneous replication, hetero only.
 	result.ApproveHeterogeneous = true
@@ -218,6 +685,95 @@ func (m *MySQLDriverConfig) RequiresBinlogFormatChange() bool {
 	return m.BinlogFormat != "ROW"
 }
 
+var timeZoneOffsetRegex = regexp.MustCompile(`^([+-])([0-9]{1,2}):([0-9]{2})$`)
+
+// TimeZoneLocation parses TimeZone, which is either an offset ('+08:00') or a named zone ('Asia/Shanghai').
+// It returns nil if TimeZone is not set.
+// 'SYSTEM' is rejected, as the system zones of the source, the destination and dtle might differ.
+func (m *MySQLDriverConfig) TimeZoneLocation() (*time.Location, error) {
+	if m.TimeZone == "" {
+		return nil, nil
+	}
+	if sub := timeZoneOffsetRegex.FindStringSubmatch(m.TimeZone); sub != nil {
+		hour, _ := strconv.Atoi(sub[2])
+		minute, _ := strconv.Atoi(sub[3])
+		if hour > 14 || minute > 59 {
+			return nil, fmt.Errorf("bad job argument: TimeZone=%v. offset out of range", m.TimeZone)
+		}
+		offset := hour*3600 + minute*60
+		if sub[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(m.TimeZone, offset), nil
+	}
+	if strings.EqualFold(m.TimeZone, "SYSTEM") {
+		return nil, fmt.Errorf("bad job argument: TimeZone=%v. should be an offset or a named zone", m.TimeZone)
+	}
+	loc, err := time.LoadLocation(m.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("bad job argument: TimeZone=%v. %v", m.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// GetDumpCheckpoint parses DumpCheckpoint. It returns nil if there is no checkpoint.
+func (m *MySQLDriverConfig) GetDumpCheckpoint() (*DumpCheckpoint, error) {
+	if m.DumpCheckpoint == "" {
+		return nil, nil
+	}
+	c := &DumpCheckpoint{}
+	if err := json.Unmarshal([]byte(m.DumpCheckpoint), c); err != nil {
+		return nil, fmt.Errorf("bad DumpCheckpoint: %v", err)
+	}
+	return c, nil
+}
+
+// DumpCheckpoint is the progress of a full copy. It is sent with each chunk and saved by the applier
+// after the chunk is applied, so that a restarted full copy resumes from the next chunk
+// rather than the beginning.
+type DumpCheckpoint struct {
+	// Coordinates of the snapshot of the first (interrupted) copy. As rows are replaced by
+	// the unique key, the incremental replication starts from here even if the rest of
+	// the rows are copied from a later snapshot.
+	Gtid       string
+	BinlogFile string
+	BinlogPos  int64
+	// "schema.table" of the tables which have been copied
+	DoneTables []string
+	// the table being copied and the boundary of its last copied chunk
+	TableSchema string
+	TableName   string
+	UniqueKey   string
+	Iteration   int64
+	LastMaxVals []string
+}
+
+func (c *DumpCheckpoint) IsTableDone(schemaName, tableName string) bool {
+	name := fmt.Sprintf("%s.%s", schemaName, tableName)
+	for _, t := range c.DoneTables {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DumpCheckpoint) IsCurrentTable(schemaName, tableName string) bool {
+	return c.TableSchema == schemaName && c.TableName == tableName
+}
+
+// SetTableDone adds the table to DoneTables. There is no table being copied afterwards.
+func (c *DumpCheckpoint) SetTableDone(schemaName, tableName string) {
+	if !c.IsTableDone(schemaName, tableName) {
+		c.DoneTables = append(c.DoneTables, fmt.Sprintf("%s.%s", schemaName, tableName))
+	}
+	c.TableSchema = ""
+	c.TableName = ""
+	c.UniqueKey = ""
+	c.Iteration = 0
+	c.LastMaxVals = nil
+}
+
 // ElapsedRowCopyTime returns time since starting to copy chunks of rows
 func (m *MySQLDriverConfig) MarkRowCopyEndTime() {
 	m.RowCopyEndTime = time.Now()
@@ -264,6 +820,142 @@ type DataSource struct {
 	TableSchemaRename      string
 	TableSchemaScope       string
 	Tables                 []*Table
+	// Tables named with TableNameRegexPrefix, moved here by the extractor which adds the matching
+	// tables to Tables. Tables created later are matched against them.
+	TablePatterns []*Table
+}
+
+// TableNameRegexPrefix marks a Table.TableName as a regular expression, e.g. "regex:^log_[0-9]{6}$"
+const TableNameRegexPrefix = "regex:"
+
+// IsTableNameRegex returns true if name is a regular expression with TableNameRegexPrefix.
+func IsTableNameRegex(name string) bool {
+	return strings.HasPrefix(name, TableNameRegexPrefix)
+}
+
+// compiled table name regexes. key: the expression, with "(?i)" if case-insensitive.
+var tableNameRegexes sync.Map
+
+// TableNameRegex compiles a table name with TableNameRegexPrefix.
+func TableNameRegex(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	expr := strings.TrimPrefix(pattern, TableNameRegexPrefix)
+	if caseInsensitive {
+		expr = "(?i)" + expr
+	}
+	if re, ok := tableNameRegexes.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("bad table name regex %v: %v", pattern, err)
+	}
+	tableNameRegexes.Store(expr, re)
+	return re, nil
+}
+
+// MatchTableName returns true if name matches pattern, which is a name,
+// or a regular expression with TableNameRegexPrefix. A bad regular expression matches nothing.
+func MatchTableName(pattern string, name string, caseInsensitive bool) bool {
+	if IsTableNameRegex(pattern) {
+		re, err := TableNameRegex(pattern, caseInsensitive)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(name)
+	}
+	if caseInsensitive {
+		return strings.EqualFold(pattern, name)
+	}
+	return pattern == name
+}
+
+// MatchDestDoDb tells whether a change of schemaName.tableName is applied by a destination with doDb.
+// See MySQLDriverConfig.DestDoDb. A change without tableName (e.g. CREATE DATABASE) matches by the schema.
+func MatchDestDoDb(doDb []*DataSource, schemaName string, tableName string) bool {
+	if len(doDb) == 0 {
+		return true
+	}
+	for _, db := range doDb {
+		if db.TableSchema != schemaName {
+			continue
+		}
+		if len(db.Tables) == 0 || tableName == "" {
+			return true
+		}
+		for _, tb := range db.Tables {
+			if MatchTableName(tb.TableName, tableName, false) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewTableFromPattern returns a table matching pattern, a table with a regex name, with the
+// configuration of pattern. A TableRename of pattern may refer to submatches, e.g. "${1}_new".
+func NewTableFromPattern(pattern *Table, tableName string, caseInsensitive bool) *Table {
+	table := &Table{}
+	*table = *pattern
+	table.TableName = tableName
+	if pattern.TableRename != "" {
+		if re, err := TableNameRegex(pattern.TableName, caseInsensitive); err == nil {
+			if match := re.FindStringSubmatchIndex(tableName); match != nil {
+				table.TableRename = string(re.ExpandString(nil, pattern.TableRename, tableName, match))
+			}
+		}
+	}
+	return table
+}
+
+// WithTable returns a copy of doDb with table added to the DataSource of table.TableSchema, which is added
+// if missing. doDb and its DataSources are not changed, so that they can still be read by others.
+func WithTable(doDb []*DataSource, table *Table) []*DataSource {
+	result := make([]*DataSource, 0, len(doDb)+1)
+	added := false
+	for _, db := range doDb {
+		if !added && db.TableSchema == table.TableSchema {
+			newDb := *db
+			newDb.Tables = append(append([]*Table{}, db.Tables...), table)
+			db = &newDb
+			added = true
+		}
+		result = append(result, db)
+	}
+	if !added {
+		result = append(result, &DataSource{
+			TableSchema:       table.TableSchema,
+			TableSchemaRename: table.TableSchemaRename,
+			TableSchemaScope:  "table",
+			Tables:            []*Table{table},
+		})
+	}
+	return result
+}
+
+// WithoutTable returns a copy of doDb without the table. A DataSource left without tables is removed,
+// as it would mean all the tables of the schema. doDb and its DataSources are not changed.
+func WithoutTable(doDb []*DataSource, schemaName string, tableName string, caseInsensitive bool) []*DataSource {
+	result := make([]*DataSource, 0, len(doDb))
+	for _, db := range doDb {
+		if db.TableSchema == schemaName {
+			var tables []*Table
+			for _, tb := range db.Tables {
+				if !MatchTableName(tb.TableName, tableName, caseInsensitive) {
+					tables = append(tables, tb)
+				}
+			}
+			if len(tables) < len(db.Tables) {
+				if len(tables) == 0 && len(db.TablePatterns) == 0 {
+					continue
+				}
+				newDb := *db
+				newDb.Tables = tables
+				db = &newDb
+			}
+		}
+		result = append(result, db)
+	}
+	return result
 }
 
 type Table struct {
@@ -288,6 +980,12 @@ type Table struct {
 	RowsEstimate int64
 
 	Where string // TODO load from job description
+	// replicate only this fraction (0 to 1) of the rows, chosen by the hash of the primary key. See SampledIn.
+	// 0 (default): all the rows.
+	SampleRate float64
+	// columns of which the values are encrypted between the source and the destination tasks.
+	// See EncryptedColumnIndexes. Requires MySQLDriverConfig.EncryptionKey.
+	EncryptColumns []string
 }
 
 func BuildColumnMapIndex(from []string, ordinals umconf.ColumnsMap) (mapIndex []int) {
@@ -303,6 +1001,10 @@ type TableContext struct {
 	Table          *Table
 	WhereCtx       *WhereContext
 	DefChangedSent bool
+	// the indexes of the primary key columns, if the table is sampled. See SampleKeyIndexes.
+	SampleKey []int
+	// the indexes of the EncryptColumns in the rows as sent. See EncryptedColumnIndexes.
+	EncryptedColumns []int
 }
 
 func NewTableContext(table *Table, whereCtx *WhereContext) *TableContext {
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMySQLDriverConfig_TimeZoneLocation(t *testing.T) {
+	utc := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		timeZone string
+		want     string // wall clock of utc in the zone
+		wantErr  bool
+	}{
+		{timeZone: "", want: ""},
+		{timeZone: "+08:00", want: "2020-01-01 08:00:00"},
+		{timeZone: "-05:30", want: "2019-12-31 18:30:00"},
+		{timeZone: "+0:00", want: "2020-01-01 00:00:00"},
+		{timeZone: "UTC", want: "2020-01-01 00:00:00"},
+		{timeZone: "+15:00", wantErr: true},
+		{timeZone: "+08:60", wantErr: true},
+		{timeZone: "SYSTEM", wantErr: true},
+		{timeZone: "No/Such_Zone", wantErr: true},
+	}
+	for _, tt := range tests {
+		m := &MySQLDriverConfig{TimeZone: tt.timeZone}
+		loc, err := m.TimeZoneLocation()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TimeZoneLocation(%v) error = %v, wantErr %v", tt.timeZone, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if tt.want == "" {
+			if loc != nil {
+				t.Errorf("TimeZoneLocation(%v) = %v, want nil", tt.timeZone, loc)
+			}
+			continue
+		}
+		if got := utc.In(loc).Format("2006-01-02 15:04:05"); got != tt.want {
+			t.Errorf("TimeZoneLocation(%v): %v in the zone is %v, want %v", tt.timeZone, utc, got, tt.want)
+		}
+	}
+}
+
+func TestDumpCheckpoint_SetTableDone(t *testing.T) {
+	c := &DumpCheckpoint{
+		Gtid:        "00000000-0000-0000-0000-000000000001:1-10",
+		DoneTables:  []string{"db1.t1"},
+		TableSchema: "db1",
+		TableName:   "t2",
+		UniqueKey:   "PRIMARY",
+		Iteration:   3,
+		LastMaxVals: []string{"'10'"},
+	}
+	c.SetTableDone("db1", "t2")
+	c.SetTableDone("db1", "t2")
+	if !c.IsTableDone("db1", "t1") || !c.IsTableDone("db1", "t2") || len(c.DoneTables) != 2 {
+		t.Errorf("DoneTables = %v", c.DoneTables)
+	}
+	if c.IsCurrentTable("db1", "t2") || c.Iteration != 0 || c.LastMaxVals != nil {
+		t.Errorf("the table being copied is not cleared: %+v", c)
+	}
+	if c.Gtid == "" {
+		t.Errorf("Gtid is cleared")
+	}
+
+	m := &MySQLDriverConfig{}
+	if got, err := m.GetDumpCheckpoint(); got != nil || err != nil {
+		t.Errorf("GetDumpCheckpoint() = %v, %v, want nil", got, err)
+	}
+	m.DumpCheckpoint = `{"Gtid":"g","DoneTables":["db1.t1"],"TableSchema":"db1","TableName":"t2","Iteration":3}`
+	got, err := m.GetDumpCheckpoint()
+	if err != nil || !got.IsTableDone("db1", "t1") || !got.IsCurrentTable("db1", "t2") || got.Iteration != 3 {
+		t.Errorf("GetDumpCheckpoint() = %+v, %v", got, err)
+	}
+}
+
+func TestMatchDestDoDb(t *testing.T) {
+	doDb := []*DataSource{
+		{TableSchema: "db1"},
+		{TableSchema: "db2", Tables: []*Table{{TableName: "t1"}, {TableName: "regex:^log_[0-9]+$"}}},
+	}
+	tests := []struct {
+		doDb       []*DataSource
+		schemaName string
+		tableName  string
+		want       bool
+	}{
+		{nil, "db3", "t1", true},
+		{doDb, "db1", "any", true},
+		{doDb, "db2", "t1", true},
+		{doDb, "db2", "log_201901", true},
+		{doDb, "db2", "t2", false},
+		{doDb, "db2", "", true},
+		{doDb, "db3", "t1", false},
+		{doDb, "db3", "", false},
+	}
+	for _, tt := range tests {
+		if got := MatchDestDoDb(tt.doDb, tt.schemaName, tt.tableName); got != tt.want {
+			t.Errorf("MatchDestDoDb(%v, %v) = %v, want %v", tt.schemaName, tt.tableName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The values of the EncryptColumns of a table are encrypted by the source task before they are sent, and
+// decrypted by the destination tasks before they are applied, with the EncryptionKey shared by the tasks.
+// The other columns, and the schema and table names, are sent in plain text.
+
+// EncryptedColumnIndexes returns the indexes of the EncryptColumns of table in its rows as sent, i.e. after
+// ColumnMap. Columns which the table does not have (any more) are skipped.
+func EncryptedColumnIndexes(table *Table) []int {
+	if len(table.EncryptColumns) == 0 || table.OriginalTableColumns == nil {
+		return nil
+	}
+	var indexes []int
+	columns := table.OriginalTableColumns.Columns
+	n := len(columns)
+	if len(table.ColumnMap) > 0 {
+		n = len(table.ColumnMap)
+	}
+	for i := 0; i < n; i++ {
+		ordinal := i
+		if len(table.ColumnMap) > 0 {
+			ordinal = table.ColumnMap[i]
+		}
+		if ordinal < len(columns) && isEncryptColumn(table, columns[ordinal].RawName) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// ValidateEncryptColumns checks that the EncryptColumns of table are columns of it, and are replicated.
+func ValidateEncryptColumns(table *Table) error {
+	if len(table.EncryptColumns) == 0 {
+		return nil
+	}
+	for _, name := range table.EncryptColumns {
+		found := false
+		for _, column := range table.OriginalTableColumns.Columns {
+			if strings.EqualFold(column.RawName, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v.%v: EncryptColumns: no column %v", table.TableSchema, table.TableName, name)
+		}
+		if len(table.ColumnMapFrom) > 0 {
+			mapped := false
+			for _, from := range table.ColumnMapFrom {
+				if strings.EqualFold(from, name) {
+					mapped = true
+					break
+				}
+			}
+			if !mapped {
+				return fmt.Errorf("%v.%v: EncryptColumns: column %v is not in ColumnMapFrom",
+					table.TableSchema, table.TableName, name)
+			}
+		}
+	}
+	return nil
+}
+
+func isEncryptColumn(table *Table, name string) bool {
+	for _, column := range table.EncryptColumns {
+		if strings.EqualFold(column, name) {
+			return true
+		}
+	}
+	return false
+}
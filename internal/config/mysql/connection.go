@@ -17,19 +17,77 @@ type ConnectionConfig struct {
 	User     string
 	Password string
 	Charset  string
+	// AdminUser, if set, is used instead of User for the queries other than the replication itself,
+	// e.g. schema queries and checksum, so that User needs only the grants for replicating.
+	AdminUser     string
+	AdminPassword string
+	// Tunnel, if set, is used to reach the server.
+	Tunnel *TunnelConfig
+
+	// tunnelNetwork is the network registered to go-sql-driver/mysql, dialing Host:Port through Tunnel
+	tunnelNetwork string
+}
+
+// TunnelConfig is a SOCKS5 proxy or an SSH jump host to reach a MySQL server.
+// Only one of them should be set.
+type TunnelConfig struct {
+	// Socks5 is the address of a SOCKS5 proxy, as "host:port"
+	Socks5         string
+	Socks5User     string
+	Socks5Password string
+
+	// SSHHost is the address of an SSH jump host, as "host" or "host:port".
+	// Authentication is by the private key of SSHKeyFile (default: ~/.ssh/id_ed25519, id_ecdsa
+	// or id_rsa of the agent user). SSHUser defaults to the agent user.
+	SSHHost    string
+	SSHUser    string
+	SSHKeyFile string
+	// SSHHostKey is the public key of SSHHost, in the format of authorized_keys or known_hosts,
+	// e.g. "ssh-ed25519 AAAA...". Empty: any host key is accepted, and its fingerprint is logged.
+	SSHHostKey string
+}
+
+// SetTunnelNetwork makes connections go through an established tunnel, registered to go-sql-driver/mysql
+// as network.
+func (c *ConnectionConfig) SetTunnelNetwork(network string) {
+	c.tunnelNetwork = network
+}
+
+// TunnelNetwork returns the network set by SetTunnelNetwork, or "" without a tunnel.
+func (c *ConnectionConfig) TunnelNetwork() string {
+	return c.tunnelNetwork
+}
+
+// dsnNetwork is the network of the DSN of go-sql-driver/mysql.
+func (c *ConnectionConfig) dsnNetwork() string {
+	if c.tunnelNetwork != "" {
+		return c.tunnelNetwork
+	}
+	return "tcp"
+}
+
+// AdminConfig returns the configuration to connect by AdminUser, or c itself if AdminUser is not set.
+func (c *ConnectionConfig) AdminConfig() *ConnectionConfig {
+	if c.AdminUser == "" {
+		return c
+	}
+	admin := *c
+	admin.User = c.AdminUser
+	admin.Password = c.AdminPassword
+	return &admin
 }
 
 func (c *ConnectionConfig) GetDBUriByDbName(databaseName string) string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%v&maxAllowedPacket=0", c.User, c.Password, c.Host, c.Port, databaseName, c.Charset)
+	return fmt.Sprintf("%s:%s@%s(%s:%d)/%s?charset=%v&maxAllowedPacket=0", c.User, c.Password, c.dsnNetwork(), c.Host, c.Port, databaseName, c.Charset)
 }
 
 func (c *ConnectionConfig) GetDBUri() string {
 	if "" == c.Charset {
 		c.Charset = "utf8mb4"
 	}
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/?timeout=5s&tls=false&autocommit=true&charset=%v&multiStatements=true&maxAllowedPacket=0", c.User, c.Password, c.Host, c.Port, c.Charset)
+	return fmt.Sprintf("%s:%s@%s(%s:%d)/?timeout=5s&tls=false&autocommit=true&charset=%v&multiStatements=true&maxAllowedPacket=0", c.User, c.Password, c.dsnNetwork(), c.Host, c.Port, c.Charset)
 }
 
 func (c *ConnectionConfig) GetSingletonDBUri() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/?timeout=5s&tls=false&autocommit=false&charset=%v&multiStatements=true&maxAllowedPacket=0", c.User, c.Password, c.Host, c.Port, c.Charset)
+	return fmt.Sprintf("%s:%s@%s(%s:%d)/?timeout=5s&tls=false&autocommit=false&charset=%v&multiStatements=true&maxAllowedPacket=0", c.User, c.Password, c.dsnNetwork(), c.Host, c.Port, c.Charset)
 }
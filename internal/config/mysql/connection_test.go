@@ -12,42 +12,39 @@ import (
 	test "github.com/outbrain/golib/tests"
 )
 
-func TestNewConnectionConfig(t *testing.T) {
-	c := NewConnectionConfig()
-	test.S(t).ExpectEquals(c.Key.Hostname, "")
-	test.S(t).ExpectEquals(c.Key.Port, 0)
-	test.S(t).ExpectEquals(c.ImpliedKey.Hostname, "")
-	test.S(t).ExpectEquals(c.ImpliedKey.Port, 0)
+func TestConnectionConfig(t *testing.T) {
+	c := &ConnectionConfig{}
+	test.S(t).ExpectEquals(c.Host, "")
+	test.S(t).ExpectEquals(c.Port, 0)
 	test.S(t).ExpectEquals(c.User, "")
 	test.S(t).ExpectEquals(c.Password, "")
+	test.S(t).ExpectEquals(c.TunnelNetwork(), "")
+	test.S(t).ExpectEquals(c.dsnNetwork(), "tcp")
 }
 
-func TestDuplicateCredentials(t *testing.T) {
-	c := NewConnectionConfig()
-	c.Key = InstanceKey{Hostname: "myhost", Port: 3306}
-	c.User = "gromit"
-	c.Password = "penguin"
-
-	dup := c.DuplicateCredentials(InstanceKey{Hostname: "otherhost", Port: 3310})
-	test.S(t).ExpectEquals(dup.Key.Hostname, "otherhost")
-	test.S(t).ExpectEquals(dup.Key.Port, 3310)
-	test.S(t).ExpectEquals(dup.ImpliedKey.Hostname, "otherhost")
-	test.S(t).ExpectEquals(dup.ImpliedKey.Port, 3310)
-	test.S(t).ExpectEquals(dup.User, "gromit")
-	test.S(t).ExpectEquals(dup.Password, "penguin")
+func TestGetDBUri(t *testing.T) {
+	c := &ConnectionConfig{Host: "myhost", Port: 3306, User: "gromit", Password: "penguin"}
+	test.S(t).ExpectEquals(c.GetDBUri(), "gromit:penguin@tcp(myhost:3306)/?timeout=5s&tls=false&autocommit=true"+
+		"&charset=utf8mb4&multiStatements=true&maxAllowedPacket=0")
+	test.S(t).ExpectEquals(c.Charset, "utf8mb4")
+
+	c.SetTunnelNetwork("dtle-tunnel-1")
+	test.S(t).ExpectEquals(c.GetDBUriByDbName("db1"), "gromit:penguin@dtle-tunnel-1(myhost:3306)/db1?charset=utf8mb4&maxAllowedPacket=0")
 }
 
-func TestDuplicate(t *testing.T) {
-	c := NewConnectionConfig()
-	c.Key = InstanceKey{Hostname: "myhost", Port: 3306}
-	c.User = "gromit"
-	c.Password = "penguin"
-
-	dup := c.Duplicate()
-	test.S(t).ExpectEquals(dup.Key.Hostname, "myhost")
-	test.S(t).ExpectEquals(dup.Key.Port, 3306)
-	test.S(t).ExpectEquals(dup.ImpliedKey.Hostname, "myhost")
-	test.S(t).ExpectEquals(dup.ImpliedKey.Port, 3306)
-	test.S(t).ExpectEquals(dup.User, "gromit")
-	test.S(t).ExpectEquals(dup.Password, "penguin")
+func TestAdminConfig(t *testing.T) {
+	c := &ConnectionConfig{Host: "myhost", Port: 3306, User: "gromit", Password: "penguin"}
+	test.S(t).ExpectTrue(c.AdminConfig() == c)
+
+	c.AdminUser = "wallace"
+	c.AdminPassword = "cheese"
+	c.SetTunnelNetwork("dtle-tunnel-1")
+	admin := c.AdminConfig()
+	test.S(t).ExpectEquals(admin.Host, "myhost")
+	test.S(t).ExpectEquals(admin.Port, 3306)
+	test.S(t).ExpectEquals(admin.User, "wallace")
+	test.S(t).ExpectEquals(admin.Password, "cheese")
+	test.S(t).ExpectEquals(admin.TunnelNetwork(), "dtle-tunnel-1")
+	test.S(t).ExpectEquals(c.User, "gromit")
+	test.S(t).ExpectEquals(c.Password, "penguin")
 }
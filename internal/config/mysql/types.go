@@ -68,14 +68,105 @@ type Column struct {
 	Key                string
 	TimezoneConversion *TimezoneConvertion
 	Nullable           bool
-	Precision          int // for decimal, time or datetime
-	Scale              int // for decimal
+	Precision          int      // for decimal, time or datetime
+	Scale              int      // for decimal
+	EnumValues         []string // for enum or set, members in definition order
+	// the value is written as CAST(value AS CastType) on the destination. See config.ColumnTypeOverride.
+	CastType string
+	// the column has ON UPDATE CURRENT_TIMESTAMP. Set on the destination only. See sql.BuildDMLAppendQuery.
+	OnUpdate bool
 	// somehow ugly. A better solution might be MetaInfo with subtypes
 }
 
 func (c *Column) IsPk() bool {
 	return c.Key == "PRI"
 }
+
+// DecodeEnumValue converts an enum index or a set bitmap from a binlog row to its string value,
+// which is independent of the member order on the destination.
+// The value is returned unchanged if it is not an integer or the members are unknown.
+func (c *Column) DecodeEnumValue(value interface{}) interface{} {
+	if len(c.EnumValues) == 0 {
+		return value
+	}
+	var n uint64
+	switch v := value.(type) {
+	case int8:
+		n = uint64(uint8(v))
+	case int16:
+		n = uint64(uint16(v))
+	case int32:
+		n = uint64(uint32(v))
+	case int64:
+		n = uint64(v)
+	case uint64:
+		n = v
+	default:
+		return value
+	}
+
+	switch c.Type {
+	case EnumColumnType:
+		// 0 is the index of the empty string stored for an invalid value.
+		if n == 0 || n > uint64(len(c.EnumValues)) {
+			return ""
+		}
+		return c.EnumValues[n-1]
+	case SetColumnType:
+		var members []string
+		for i := range c.EnumValues {
+			if n&(1<<uint(i)) != 0 {
+				members = append(members, c.EnumValues[i])
+			}
+		}
+		return strings.Join(members, ",")
+	default:
+		return value
+	}
+}
+
+// ParseEnumValues returns the members of an enum or set COLUMN_TYPE, e.g. `enum('a','b')`.
+// It returns nil for other types.
+func ParseEnumValues(columnType string) []string {
+	lower := strings.ToLower(columnType)
+	var body string
+	switch {
+	case strings.HasPrefix(lower, "enum(") && strings.HasSuffix(lower, ")"):
+		body = columnType[5 : len(columnType)-1]
+	case strings.HasPrefix(lower, "set(") && strings.HasSuffix(lower, ")"):
+		body = columnType[4 : len(columnType)-1]
+	default:
+		return nil
+	}
+
+	var values []string
+	var value []byte
+	inQuote := false
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		if !inQuote {
+			if ch == '\'' {
+				inQuote = true
+				value = value[:0]
+			}
+			continue
+		}
+		switch {
+		case ch == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			value = append(value, '\'')
+			i++
+		case ch == '\\' && i+1 < len(body):
+			value = append(value, body[i+1])
+			i++
+		case ch == '\'':
+			inQuote = false
+			values = append(values, string(value))
+		default:
+			value = append(value, ch)
+		}
+	}
+	return values
+}
 func (c *Column) ConvertArg(arg interface{}) interface{} {
 	if fmt.Sprintf("%s", arg) == "" {
 		return ""
@@ -157,6 +248,8 @@ func NewColumnsMap(orderedColumns []Column) ColumnsMap {
 type ColumnList struct {
 	Columns  []Column
 	Ordinals ColumnsMap
+	// quotes the names in SQL built with the list. See SetQuote.
+	quote IdentifierQuote
 }
 
 // NewColumnList creates an object given ordered list of column names
@@ -205,6 +298,27 @@ func (c *ColumnList) EscapedNames() []string {
 	return names
 }
 
+// SetQuote sets EscapedName of the columns quoted with quote, and the quote of QuoteName.
+func (c *ColumnList) SetQuote(quote IdentifierQuote) {
+	c.quote = quote
+	for i := range c.Columns {
+		c.Columns[i].EscapedName = quote.Quote(c.Columns[i].RawName)
+	}
+}
+
+// Quote returns the quote set by SetQuote. default: BacktickQuote.
+func (c *ColumnList) Quote() IdentifierQuote {
+	if c.quote == 0 {
+		return BacktickQuote
+	}
+	return c.quote
+}
+
+// QuoteName quotes the name of a schema or table as the columns are.
+func (c *ColumnList) QuoteName(name string) string {
+	return c.Quote().Quote(name)
+}
+
 // TODO caller doesn't handle nil.
 func (c *ColumnList) GetColumn(columnName string) *Column {
 	if ordinal, ok := c.Ordinals[columnName]; ok {
@@ -343,19 +457,32 @@ func (c *ColumnValues) String() string {
 	return strings.Join(stringValues, ",")
 }
 
-func EscapeName(name string) string {
+// IdentifierQuote is the character quoting identifiers, i.e. schema, table and column names, in generated SQL.
+type IdentifierQuote byte
+
+const (
+	BacktickQuote IdentifierQuote = '`' // MySQL's own, valid in any sql_mode
+	ANSIQuote     IdentifierQuote = '"' // standard SQL. for a destination in ANSI_QUOTES sql_mode
+)
+
+// Quote quotes name, doubling the quote character in it.
+func (q IdentifierQuote) Quote(name string) string {
 	sb := strings.Builder{}
-	sb.WriteByte('`')
+	sb.WriteByte(byte(q))
 	for i := range name {
-		if name[i] == '`' {
-			sb.WriteByte('`')
-			sb.WriteByte('`')
+		if name[i] == byte(q) {
+			sb.WriteByte(byte(q))
+			sb.WriteByte(byte(q))
 		} else {
 			sb.WriteByte(name[i])
 		}
 	}
-	sb.WriteByte('`')
+	sb.WriteByte(byte(q))
 
 	return sb.String()
 }
 
+func EscapeName(name string) string {
+	return BacktickQuote.Quote(name)
+}
+
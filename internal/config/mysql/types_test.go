@@ -38,3 +38,21 @@ func TestGetColumn(t *testing.T) {
 		test.S(t).ExpectTrue(column == nil)
 	}
 }
+
+func TestParseEnumValues(t *testing.T) {
+	test.S(t).ExpectTrue(reflect.DeepEqual(ParseEnumValues("enum('a','b','c')"), []string{"a", "b", "c"}))
+	test.S(t).ExpectTrue(reflect.DeepEqual(ParseEnumValues("set('x,y','it''s','')"), []string{"x,y", "it's", ""}))
+	test.S(t).ExpectTrue(ParseEnumValues("varchar(10)") == nil)
+}
+
+func TestColumn_DecodeEnumValue(t *testing.T) {
+	enum := Column{Type: EnumColumnType, EnumValues: []string{"a", "b", "c"}}
+	test.S(t).ExpectEquals(enum.DecodeEnumValue(int64(2)), "b")
+	test.S(t).ExpectEquals(enum.DecodeEnumValue(int64(0)), "")
+	test.S(t).ExpectEquals(enum.DecodeEnumValue("b"), "b")
+	test.S(t).ExpectEquals(enum.DecodeEnumValue(nil), nil)
+
+	set := Column{Type: SetColumnType, EnumValues: []string{"a", "b", "c"}}
+	test.S(t).ExpectEquals(set.DecodeEnumValue(int64(5)), "a,c")
+	test.S(t).ExpectEquals(set.DecodeEnumValue(int64(0)), "")
+}
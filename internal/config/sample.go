@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package config
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// A table with SampleRate is replicated partially, e.g. for a scaled-down staging database. Whether a row is
+// in the sample depends only on its primary key, so the full copy and the incremental replication take the
+// same rows, and the updates and deletes of a row are replicated exactly if its insert is.
+// The key is hashed as text: the full copy reads the values as text, and the binlog values are formatted as
+// MySQL does. So keys of DECIMAL, FLOAT, DOUBLE, BIT or JSON columns, formatted differently, are not supported.
+
+// sampleBuckets is the precision of SampleRate.
+const sampleBuckets = 10000
+
+// SampleKeyIndexes returns the indexes of the primary key columns of table in its columns, or nil if
+// the table is not sampled, i.e. SampleRate is 0 or 1.
+func SampleKeyIndexes(table *Table) ([]int, error) {
+	if table.SampleRate <= 0 || table.SampleRate >= 1 || table.OriginalTableColumns == nil {
+		return nil, nil
+	}
+	var indexes []int
+	for i, column := range table.OriginalTableColumns.Columns {
+		if !column.IsPk() {
+			continue
+		}
+		switch column.Type {
+		case umconf.DecimalColumnType, umconf.FloatColumnType, umconf.DoubleColumnType, umconf.BitColumnType,
+			umconf.JSONColumnType:
+			return nil, fmt.Errorf("%v.%v: SampleRate does not support the primary key column %v of type %v",
+				table.TableSchema, table.TableName, column.RawName, column.ColumnType)
+		}
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("%v.%v: SampleRate requires a primary key", table.TableSchema, table.TableName)
+	}
+	return indexes, nil
+}
+
+// SampledIn tells whether a row is in the sample of rate by key, the text of its primary key values.
+func SampledIn(rate float64, key [][]byte) bool {
+	h := crc32.NewIEEE()
+	var n [binary.MaxVarintLen64]byte
+	for _, k := range key {
+		// by length, so that e.g. ("a", "bc") and ("ab", "c") differ
+		h.Write(n[:binary.PutUvarint(n[:], uint64(len(k)))])
+		h.Write(k)
+	}
+	return h.Sum32()%sampleBuckets < uint32(rate*sampleBuckets+0.5)
+}
+
+// SampleKeyValue returns the text of a value of a primary key column from the binlog, as the full copy reads it.
+func SampleKeyValue(v interface{}) []byte {
+	switch x := v.(type) {
+	case []byte:
+		return x
+	case string:
+		return []byte(x)
+	case int8:
+		return strconv.AppendInt(nil, int64(x), 10)
+	case int16:
+		return strconv.AppendInt(nil, int64(x), 10)
+	case int32:
+		return strconv.AppendInt(nil, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(nil, x, 10)
+	case uint8:
+		return strconv.AppendUint(nil, uint64(x), 10)
+	case uint16:
+		return strconv.AppendUint(nil, uint64(x), 10)
+	case uint32:
+		return strconv.AppendUint(nil, uint64(x), 10)
+	case uint64:
+		return strconv.AppendUint(nil, x, 10)
+	default:
+		return []byte(fmt.Sprint(x))
+	}
+}
+
+// SampledIn tells whether the row of values is in the sample of the table. It returns false for ok if the
+// values do not have the primary key, e.g. the after image of an update with binlog_row_image=minimal.
+// All the rows of a table without SampleKey are in.
+func (t *TableContext) SampledIn(values *umconf.ColumnValues) (in bool, ok bool) {
+	if t.SampleKey == nil {
+		return true, true
+	}
+	if values == nil {
+		return false, false
+	}
+	key := make([][]byte, len(t.SampleKey))
+	for i, idx := range t.SampleKey {
+		if idx >= len(values.AbstractValues) || values.AbstractValues[idx] == nil || *values.AbstractValues[idx] == nil {
+			return false, false
+		}
+		key[i] = SampleKeyValue(*values.AbstractValues[idx])
+	}
+	return SampledIn(t.Table.SampleRate, key), true
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2016-2018. ActionTech.
+ * Based on: github.com/hashicorp/nomad, github.com/github/gh-ost .
+ * License: MPL version 2: https://www.mozilla.org/en-US/MPL/2.0 .
+ */
+
+package config
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	umconf "github.com/actiontech/dtle/internal/config/mysql"
+)
+
+// ShardBy splits the rows of a table, named as on the destination, into Shards tables by the hash of the
+// value of Column. The full copy and the incremental replication route a row the same way: as SampleRate,
+// the value is hashed as text, so DECIMAL, FLOAT, DOUBLE, BIT and JSON columns are not supported.
+type ShardBy struct {
+	TableSchema string
+	TableName   string
+	Column      string
+	Shards      int
+	// the names of the shard tables, with {table} for TableName and {shard} for the number of the shard,
+	// from 0 to Shards-1. Default ShardNameTemplateDefault.
+	NameTemplate string
+}
+
+const ShardNameTemplateDefault = "{table}_{shard}"
+
+// FindShardBy returns the ShardBy of schemaName.tableName in shardBys, or nil.
+func FindShardBy(shardBys []*ShardBy, schemaName string, tableName string) *ShardBy {
+	for _, s := range shardBys {
+		if s.TableSchema == schemaName && s.TableName == tableName {
+			return s
+		}
+	}
+	return nil
+}
+
+// ShardTableName returns the name of the table of a shard.
+func (s *ShardBy) ShardTableName(shard int) string {
+	template := s.NameTemplate
+	if template == "" {
+		template = ShardNameTemplateDefault
+	}
+	return strings.NewReplacer("{table}", s.TableName, "{shard}", strconv.Itoa(shard)).Replace(template)
+}
+
+// ShardOf returns the shard of a row by the text of its value of Column (see SampleKeyValue).
+// The rows with NULL are in shard 0.
+func (s *ShardBy) ShardOf(value []byte) int {
+	if value == nil {
+		return 0
+	}
+	return int(crc32.ChecksumIEEE(value) % uint32(s.Shards))
+}
+
+// ShardColumnIndex returns the index of Column in the columns of the table.
+func (s *ShardBy) ShardColumnIndex(columns *umconf.ColumnList) (int, error) {
+	idx, ok := columns.Ordinals[s.Column]
+	if !ok {
+		return 0, fmt.Errorf("%v.%v: ShardBy: no column %v", s.TableSchema, s.TableName, s.Column)
+	}
+	switch column := &columns.Columns[idx]; column.Type {
+	case umconf.DecimalColumnType, umconf.FloatColumnType, umconf.DoubleColumnType, umconf.BitColumnType,
+		umconf.JSONColumnType:
+		return 0, fmt.Errorf("%v.%v: ShardBy does not support the column %v of type %v",
+			s.TableSchema, s.TableName, column.RawName, column.ColumnType)
+	}
+	return idx, nil
+}
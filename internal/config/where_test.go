@@ -1,7 +1,6 @@
 package config
 
 import (
-	"github.com/actiontech/dtle/internal/client/driver/mysql/binlog"
 	"github.com/actiontech/dtle/internal/config/mysql"
 	"testing"
 )
@@ -19,7 +18,11 @@ func newTableContextWithWhere(t *testing.T,
 	return tbCtx
 }
 func buildColumnValues(vals ...interface{}) *mysql.ColumnValues {
-	return binlog.ToColumnValuesV2(vals, nil)
+	result := &mysql.ColumnValues{AbstractValues: make([]*interface{}, len(vals))}
+	for i := range vals {
+		result.AbstractValues[i] = &vals[i]
+	}
+	return result
 }
 
 func TestWhereTrue(t *testing.T) {
@@ -31,7 +34,7 @@ func TestWhereTrue(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if r != (i % 2 == 0) {
+		if r != (i%2 == 0) {
 			t.Fatalf("i: %v, r: %v", i, r)
 		}
 	}
@@ -65,8 +68,8 @@ func TestWhereTrue(t *testing.T) {
 	////////
 	tbCtx = newTableContextWithWhere(t, "db1", "tb1", "a >= 5 or b <=5 ", "id", "a", "b")
 	{
-		a,b := 2,3
-		r, err := tbCtx.WhereTrue(buildColumnValues(1,2,3))
+		a, b := 2, 3
+		r, err := tbCtx.WhereTrue(buildColumnValues(1, 2, 3))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -80,6 +83,8 @@ func TestWhereTrueText(t *testing.T) {
 	var tbCtx *TableContext
 
 	tbCtx = newTableContextWithWhere(t, "db1", "tb1", "a = 'hello'", "id", "a")
+	// binlog gives []byte for a text column
+	tbCtx.Table.OriginalTableColumns.Columns[1].Type = mysql.TextColumnType
 	r, err := tbCtx.WhereTrue(buildColumnValues(1, []byte("hello")))
 	if err != nil {
 		t.Fatal(err)
@@ -88,8 +93,7 @@ func TestWhereTrueText(t *testing.T) {
 		t.Fatalf("it is hello")
 	}
 
-	tbCtx = newTableContextWithWhere(t, "db1", "tb1", "a = 'hello'", "id", "a")
-	r, err = tbCtx.WhereTrue(buildColumnValues(2, "hello2"))
+	r, err = tbCtx.WhereTrue(buildColumnValues(2, []byte("hello2")))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -66,6 +66,10 @@ type Job struct {
 	// Datacenters contains all the datacenters this job is allowed to span
 	Datacenters []string
 
+	// Tags are arbitrary key-value pairs, e.g. env=prod, for listing the jobs
+	// with a filter. See HasTags.
+	Tags map[string]string
+
 	// Constraints can be specified at a job level and apply to
 	// all the tasks.
 	Constraints []*Constraint
@@ -105,6 +109,7 @@ func (j *Job) Copy() *Job {
 	nj := new(Job)
 	*nj = *j
 	nj.Datacenters = internal.CopySliceString(nj.Datacenters)
+	nj.Tags = internal.CopyMapStringString(nj.Tags)
 	nj.Constraints = CopySliceConstraints(nj.Constraints)
 
 	if j.Tasks != nil {
@@ -142,6 +147,11 @@ func (j *Job) Validate() error {
 	if len(j.Tasks) == 0 {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing job tasks"))
 	}
+	for k, v := range j.Tags {
+		if k == "" || strings.ContainsAny(k, ":,") || strings.Contains(v, ",") {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Bad job tag %q=%q: the key must be non-empty without ':' or ',', and the value without ','", k, v))
+		}
+	}
 	for idx, constr := range j.Constraints {
 		if err := constr.Validate(); err != nil {
 			outer := fmt.Errorf("Constraint %d validation failed: %s", idx+1, err)
@@ -161,6 +171,14 @@ func (j *Job) Validate() error {
 		}
 	}
 
+	// Additional destinations share the NATS server on the node of TaskTypeDest.
+	_, hasDest := tasks[TaskTypeDest]
+	for _, t := range j.Tasks {
+		if IsDestTaskType(t.Type) && !hasDest {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Job task %s requires a task %s", t.Type, TaskTypeDest))
+		}
+	}
+
 	// Validate the task
 	for _, t := range j.Tasks {
 		if err := t.Validate(); err != nil {
@@ -182,12 +200,41 @@ func (j *Job) LookupTask(tp string) *Task {
 	return nil
 }
 
+// HasTags tells whether the job has all of tags, with the same values.
+func (j *Job) HasTags(tags map[string]string) bool {
+	for k, v := range tags {
+		if value, ok := j.Tags[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTagFilter parses a tag filter of the form "k1:v1,k2:v2", which matches
+// the jobs having all of the tags.
+func ParseTagFilter(filter string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(filter, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("bad tag filter %q: should be key:value", pair)
+		}
+		tags[kv[0]] = kv[1]
+	}
+	return tags, nil
+}
+
 // Stub is used to return a summary of the job
 func (j *Job) Stub(job *Job) *JobListStub {
 	return &JobListStub{
 		ID:                j.ID,
 		Name:              j.Name,
 		Type:              j.Type,
+		Tags:              j.Tags,
 		Status:            j.Status,
 		StatusDescription: j.StatusDescription,
 		CreateIndex:       j.CreateIndex,
@@ -203,6 +250,7 @@ type JobListStub struct {
 	ID                string
 	Name              string
 	Type              string
+	Tags              map[string]string
 	Status            string
 	StatusDescription string
 	JobSummary        *Job
@@ -304,6 +352,8 @@ type JobSpecificRequest struct {
 
 // JobListRequest is used to parameterize a list request
 type JobListRequest struct {
+	// Tags filters the jobs to those having all of them. See Job.HasTags.
+	Tags map[string]string
 	QueryOptions
 }
 
@@ -177,6 +177,10 @@ type Node struct {
 	NatsAddr string
 	NatsAdvertiseAddr string
 
+	// MaxRunningTasks is the most tasks placed on the node at a time, counting
+	// the pending and running ones. 0 means no limit.
+	MaxRunningTasks int
+
 	// Attributes is an arbitrary set of key/value
 	// data that can be used for constraints. Examples
 	// include "kernel.name=linux", "arch=386", "driver.docker=1",
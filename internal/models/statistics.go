@@ -13,6 +13,7 @@ import (
 const (
 	StageFinishedReadingOneBinlogSwitchingToNextBinlog = "Finished reading one binlog; switching to next binlog"
 	StageMasterHasSentAllBinlogToSlave                 = "Master has sent all binlog to slave; waiting for more updates"
+	StagePausedOnDiskFull                              = "Paused; the destination is out of space"
 	StageRegisteringSlaveOnMaster                      = "Registering slave on master"
 	StageRequestingBinlogDump                          = "Requesting binlog dump"
 	StageSearchingRowsForUpdate                        = "Searching rows for update"
@@ -30,6 +31,83 @@ type TableStats struct {
 	DelCount    int64
 }
 
+// TableProgress is the progress of a table in the full copy
+type TableProgress struct {
+	TableSchema string
+	TableName   string
+	// RowsEstimate is from information_schema. It is refreshed if found too small.
+	RowsEstimate int64
+	RowsCopied   int64
+	// Chunk is the number of chunks read from the table
+	Chunk int64
+}
+
+// ConnPoolStats is the connection pool of the destination
+type ConnPoolStats struct {
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	Idle               int
+	// WaitCount and WaitDurationMs are the connections waited for, and the total time waited
+	WaitCount         int64
+	WaitDurationMs    int64
+	MaxIdleClosed     int64
+	MaxLifetimeClosed int64
+}
+
+// NatsSubStats is the subscriptions of the full and incremental data on the destination.
+// Messages are dropped if the pending ones exceed the limits of a subscription.
+type NatsSubStats struct {
+	PendingMsgs  int
+	PendingBytes int
+	DroppedMsgs  int
+}
+
+// IncrAckStats is the acknowledgements of the incremental copy sent by the destination for InFlightWindow.
+// A transaction is acknowledged when committed or skipped. A transaction split by BigTxSplittingSize is
+// acknowledged piece by piece, each piece when applied and the last one when committed.
+type IncrAckStats struct {
+	Transactions int64
+	// pieces of big transactions acknowledged before the transactions are committed
+	Pieces int64
+}
+
+// ThrottleStats is the throttling of the full copy by the resources of the cgroup, with ThrottleMemoryPct
+// or ThrottleCPUPct. A limit of 0 means no limit.
+type ThrottleStats struct {
+	// whether the full copy is throttled for the memory / the CPU
+	Memory        bool
+	CPU           bool
+	MemoryUsageMB int64
+	MemoryLimitMB int64
+	// usage of the CPU limit, or of all the CPUs if there is no limit, over the last second
+	CPUPct float64
+}
+
+// Values of ErrorRecord.Type
+const (
+	ErrorTypeApply  = "apply"  // a transaction or a row fails to apply on the destination
+	ErrorTypeSchema = "schema" // e.g. a DDL failing on the destination, or a table the source and the destination differ on
+	ErrorTypeNats   = "nats"   // e.g. a publish timeout, or dropped messages
+	ErrorTypeTask   = "task"   // the task fails or is restarted
+	ErrorTypeResync = "resync" // a resync of a table fails to start
+)
+
+// ErrorRecord is an error, or a recoverable one, of a task.
+type ErrorRecord struct {
+	Time    int64 // unix nano
+	Type    string
+	Gtid    string // the transaction, or the executed gtid set for ErrorTypeTask
+	Message string
+}
+
+// JobError is an ErrorRecord of a task of a job.
+type JobError struct {
+	AllocID string
+	Task    string
+	ErrorRecord
+}
+
 type DelayCount struct {
 	Num  uint64
 	Time uint64
@@ -82,6 +160,23 @@ type TaskStatistics struct {
 	BufferStat         BufferStat
 	Stage              string
 	Timestamp          int64
+	// TableProgress is the progress of tables in the full copy
+	TableProgress []*TableProgress
+	// ConnPoolStats is set by the applier
+	ConnPoolStats *ConnPoolStats
+	// NatsSubStats is set by the applier
+	NatsSubStats *NatsSubStats
+	// IncrAckStats is set by the applier
+	IncrAckStats *IncrAckStats
+	// ThrottleStats is set by the extractor if throttling is enabled
+	ThrottleStats *ThrottleStats
+	// RecentErrors are the last errors of the task, oldest first. See MySQLDriverConfig.MaxRecentErrors.
+	RecentErrors []*ErrorRecord
+	// ReplicationLag is the lag of the incremental copy in seconds, by the binlog timestamps. It is set by the applier.
+	ReplicationLag int64
+	// IncompleteTables are the tables ("schema.table") not fully copied as the full copy stopped at
+	// MaxDumpDuration, until they are resynced. It is set by the extractor.
+	IncompleteTables []string
 }
 
 type AllocStatistics struct {
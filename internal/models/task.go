@@ -27,6 +27,17 @@ const (
 	TaskDriverOracle = "Oracle"
 )
 
+// IsDestTaskType tells whether a task type is a destination: TaskTypeDest, or an additional destination named
+// "Dest_<name>" which receives the same changes from the source. The NATS server of the job is on the node of
+// TaskTypeDest.
+func IsDestTaskType(tp string) bool {
+	return tp == TaskTypeDest || strings.HasPrefix(tp, TaskTypeDest+"_")
+}
+
+// RuntimeConfigKeys are the keys of Task.Config which take effect on a running task when the job is updated.
+// Changing only these does not restart the task.
+var RuntimeConfigKeys = []string{"ApplyEventRateLimit", "ResyncTable", "ReplicateDoDb"}
+
 // Task is a single process typically that is executed as part of a task.
 type Task struct {
 	// Type of the task
@@ -219,6 +230,30 @@ const (
 
 	// TaskLeaderDead indicates that the leader task within the has finished.
 	TaskLeaderDead = "Leader Task Dead"
+
+	// TaskDriverMessage is an informational event message emitted by
+	// drivers such as when they're performing a long running action.
+	TaskDriverMessage = "Driver"
+
+	// TaskFullCopyComplete indicates that the full copy of the job is
+	// complete and incremental replication begins.
+	TaskFullCopyComplete = "Full Copy Complete"
+
+	// TaskFullCopyIncomplete indicates that the full copy of the job has
+	// exceeded MaxDumpDuration and some tables are not fully copied.
+	TaskFullCopyIncomplete = "Full Copy Incomplete"
+
+	// TaskLagExceeded indicates that the replication lag has exceeded
+	// MaxLagBeforeStop and the job is stopped.
+	TaskLagExceeded = "Lag Exceeded"
+
+	// TaskDestinationDiskFull indicates that the destination is out of space
+	// and the task is paused.
+	TaskDestinationDiskFull = "Destination Disk Full"
+
+	// TaskDestinationRecovered indicates that the destination can be written
+	// to again and the paused task resumes.
+	TaskDestinationRecovered = "Destination Recovered"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
@@ -383,6 +418,14 @@ func (e *TaskEvent) SetDriverMessage(m string) *TaskEvent {
 	return e
 }
 
+// NatsAddrIsList tells whether the NatsAddr of a task lists several nats servers, e.g. of a nats cluster. Such
+// a NatsAddr is set by the job, and is kept when the tasks are placed. Otherwise the tasks use the nats server
+// of the agent of the destination task.
+func NatsAddrIsList(natsAddr interface{}) bool {
+	s, ok := natsAddr.(string)
+	return ok && strings.Contains(s, ",")
+}
+
 type TaskUpdate struct {
 	JobID    string
 	TaskType string
@@ -390,6 +433,7 @@ type TaskUpdate struct {
 	NatsAddr string
 	BinlogFile string
 	BinlogPos int64
+	DumpCheckpoint string
 }
 
 const (
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/raft"
 	"github.com/ugorji/go/codec"
 
+	"github.com/actiontech/dtle/internal/client/driver/mysql/base"
 	"github.com/actiontech/dtle/internal/models"
 	"github.com/actiontech/dtle/internal/server/store"
 	"github.com/sirupsen/logrus"
@@ -275,7 +276,9 @@ func (n *udupFSM) applyStatusUpdate(buf []byte, index uint64) interface{} {
 								//      <- (rpc) Client.updateNodeStatus <- Client.registerAndHeartbeat
 								// Why it should be updated and only updated for dest task?
 								for i, t := range job.Tasks {
-									t.Config["NatsAddr"] = out[0].NatsAdvertiseAddr
+									if !models.NatsAddrIsList(t.Config["NatsAddr"]) {
+										t.Config["NatsAddr"] = out[0].NatsAdvertiseAddr
+									}
 									job.Tasks[i] = t
 								}
 							}
@@ -535,8 +538,18 @@ func (n *udupFSM) applyJobClientUpdate(buf []byte, index uint64) interface{} {
 			existing.ModifyIndex = index
 			existing.JobModifyIndex = index
 
+			// With several destinations, each one has its own position and the source resumes
+			// from what all of them have applied.
+			destCount := 0
+			for _, t := range existing.Tasks {
+				if models.IsDestTaskType(t.Type) {
+					destCount++
+				}
+			}
+			fanOut := destCount > 1
+
 			for _, t := range existing.Tasks {
-				{ // these should be updated regardless of task type
+				if !fanOut || t.Type == ju.TaskType { // these should be updated regardless of task type
 					if ju.Gtid != "" {
 						n.logger.Debugf("*** write gtid %v", ju.Gtid)
 						t.Config["Gtid"] = ju.Gtid
@@ -550,6 +563,10 @@ func (n *udupFSM) applyJobClientUpdate(buf []byte, index uint64) interface{} {
 						n.logger.Debugf("*** udupFSM.applyJobClientUpdate. update BinlogPos %v", ju.BinlogPos)
 						t.Config["BinlogPos"] = ju.BinlogPos
 					}
+					// It is cleared after the full copy, so an empty one is written as well.
+					if ju.TaskType == models.TaskTypeDest {
+						t.Config["DumpCheckpoint"] = ju.DumpCheckpoint
+					}
 				}
 
 				if t.Type == ju.TaskType {
@@ -557,6 +574,9 @@ func (n *udupFSM) applyJobClientUpdate(buf []byte, index uint64) interface{} {
 
 				}
 			}
+			if fanOut && ju.Gtid != "" {
+				n.updateFanOutSrcGtid(existing)
+			}
 			// Update all the client allocations
 			if err := n.state.UpdateJobFromClient(index, existing); err != nil {
 				n.logger.Errorf("server.fsm: UpdateJobFromClient failed: %v", err)
@@ -568,6 +588,39 @@ func (n *udupFSM) applyJobClientUpdate(buf []byte, index uint64) interface{} {
 	return nil
 }
 
+// updateFanOutSrcGtid sets the Gtid of the Src task to the intersection of those of the destination tasks.
+func (n *udupFSM) updateFanOutSrcGtid(job *models.Job) {
+	var gtid string
+	first := true
+	for _, t := range job.Tasks {
+		if !models.IsDestTaskType(t.Type) {
+			continue
+		}
+		destGtid, _ := t.Config["Gtid"].(string)
+		if destGtid == "" {
+			// The destination has not reported a position.
+			continue
+		}
+		if first {
+			gtid = destGtid
+			first = false
+			continue
+		}
+		var err error
+		gtid, err = base.GtidSetIntersect(gtid, destGtid)
+		if err != nil {
+			n.logger.Errorf("server.fsm: intersect gtid of job %v: %v", job.ID, err)
+			return
+		}
+	}
+	for _, t := range job.Tasks {
+		if t.Type == models.TaskTypeSrc {
+			n.logger.Debugf("*** write gtid %v to %v", gtid, t.Type)
+			t.Config["Gtid"] = gtid
+		}
+	}
+}
+
 func (n *udupFSM) applyAllocClientUpdate(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"server", "fsm", "alloc_client_update"}, time.Now())
 	var req models.AllocUpdateRequest
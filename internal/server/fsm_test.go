@@ -10,12 +10,12 @@ import (
 	"bytes"
 	"io"
 	"reflect"
-	"sync"
 	"testing"
-	log "github.com/actiontech/dtle/internal/logger"
+
 	"github.com/actiontech/dtle/internal/server/store"
 
 	"github.com/hashicorp/raft"
+	"github.com/sirupsen/logrus"
 	"github.com/ugorji/go/codec"
 )
 
@@ -23,7 +23,7 @@ func TestNewFSM(t *testing.T) {
 	type args struct {
 		evalBroker *EvalBroker
 		blocked    *BlockedEvals
-		logger     *log.Logger
+		logger     *logrus.Logger
 	}
 	tests := []struct {
 		name          string
@@ -57,10 +57,9 @@ func Test_udupFSM_Close(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	tests := []struct {
 		name    string
@@ -78,7 +77,6 @@ func Test_udupFSM_Close(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if err := n.Close(); (err != nil) != tt.wantErr {
 				t.Errorf("udupFSM.Close() error = %v, wantErr %v", err, tt.wantErr)
@@ -92,10 +90,9 @@ func Test_udupFSM_State(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	tests := []struct {
 		name   string
@@ -113,7 +110,6 @@ func Test_udupFSM_State(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.State(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.State() = %v, want %v", got, tt.want)
@@ -127,10 +123,9 @@ func Test_udupFSM_TimeTable(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	tests := []struct {
 		name   string
@@ -148,7 +143,6 @@ func Test_udupFSM_TimeTable(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.TimeTable(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.TimeTable() = %v, want %v", got, tt.want)
@@ -162,10 +156,9 @@ func Test_udupFSM_Apply(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		log *raft.Log
@@ -187,7 +180,6 @@ func Test_udupFSM_Apply(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.Apply(tt.args.log); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.Apply() = %v, want %v", got, tt.want)
@@ -201,10 +193,9 @@ func Test_udupFSM_applyUpsertNode(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -227,7 +218,6 @@ func Test_udupFSM_applyUpsertNode(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyUpsertNode(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyUpsertNode() = %v, want %v", got, tt.want)
@@ -241,10 +231,9 @@ func Test_udupFSM_applyDeregisterNode(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -267,7 +256,6 @@ func Test_udupFSM_applyDeregisterNode(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyDeregisterNode(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyDeregisterNode() = %v, want %v", got, tt.want)
@@ -281,10 +269,9 @@ func Test_udupFSM_applyStatusUpdate(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -307,7 +294,6 @@ func Test_udupFSM_applyStatusUpdate(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyStatusUpdate(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyStatusUpdate() = %v, want %v", got, tt.want)
@@ -321,10 +307,9 @@ func Test_udupFSM_applyJobStatusUpdate(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -347,7 +332,6 @@ func Test_udupFSM_applyJobStatusUpdate(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyJobStatusUpdate(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyJobStatusUpdate() = %v, want %v", got, tt.want)
@@ -361,10 +345,9 @@ func Test_udupFSM_applyUpsertJob(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -387,7 +370,6 @@ func Test_udupFSM_applyUpsertJob(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyUpsertJob(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyUpsertJob() = %v, want %v", got, tt.want)
@@ -401,10 +383,9 @@ func Test_udupFSM_applyDeregisterJob(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -427,7 +408,6 @@ func Test_udupFSM_applyDeregisterJob(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyDeregisterJob(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyDeregisterJob() = %v, want %v", got, tt.want)
@@ -441,10 +421,9 @@ func Test_udupFSM_applyUpdateEval(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -467,7 +446,6 @@ func Test_udupFSM_applyUpdateEval(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyUpdateEval(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyUpdateEval() = %v, want %v", got, tt.want)
@@ -481,10 +459,9 @@ func Test_udupFSM_applyDeleteEval(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -507,7 +484,6 @@ func Test_udupFSM_applyDeleteEval(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyDeleteEval(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyDeleteEval() = %v, want %v", got, tt.want)
@@ -521,10 +497,9 @@ func Test_udupFSM_applyAllocUpdate(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -547,7 +522,6 @@ func Test_udupFSM_applyAllocUpdate(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyAllocUpdate(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyAllocUpdate() = %v, want %v", got, tt.want)
@@ -561,10 +535,9 @@ func Test_udupFSM_applyJobClientUpdate(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -587,7 +560,6 @@ func Test_udupFSM_applyJobClientUpdate(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyJobClientUpdate(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyJobClientUpdate() = %v, want %v", got, tt.want)
@@ -601,10 +573,9 @@ func Test_udupFSM_applyAllocClientUpdate(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		buf   []byte
@@ -627,7 +598,6 @@ func Test_udupFSM_applyAllocClientUpdate(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if got := n.applyAllocClientUpdate(tt.args.buf, tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("udupFSM.applyAllocClientUpdate() = %v, want %v", got, tt.want)
@@ -641,10 +611,9 @@ func Test_udupFSM_Snapshot(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	tests := []struct {
 		name    string
@@ -663,7 +632,6 @@ func Test_udupFSM_Snapshot(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			got, err := n.Snapshot()
 			if (err != nil) != tt.wantErr {
@@ -682,10 +650,9 @@ func Test_udupFSM_Restore(t *testing.T) {
 		evalBroker   *EvalBroker
 		blockedEvals *BlockedEvals
 		logOutput    io.Writer
-		logger       *log.Logger
+		logger       *logrus.Logger
 		state        *store.StateStore
 		timetable    *TimeTable
-		stateLock    sync.RWMutex
 	}
 	type args struct {
 		old io.ReadCloser
@@ -707,7 +674,6 @@ func Test_udupFSM_Restore(t *testing.T) {
 				logger:       tt.fields.logger,
 				state:        tt.fields.state,
 				timetable:    tt.fields.timetable,
-				stateLock:    tt.fields.stateLock,
 			}
 			if err := n.Restore(tt.args.old); (err != nil) != tt.wantErr {
 				t.Errorf("udupFSM.Restore() error = %v, wantErr %v", err, tt.wantErr)
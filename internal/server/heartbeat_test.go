@@ -9,53 +9,50 @@ package server
 import (
 	"net"
 	"net/rpc"
-	"sync"
 	"testing"
 	"time"
+
 	uconf "github.com/actiontech/dtle/internal/config"
-	ulog "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/server/store"
 
 	"github.com/docker/leadership"
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
+	"github.com/sirupsen/logrus"
 )
 
 func TestServer_initializeHeartbeatTimers(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name    string
@@ -67,38 +64,35 @@ func TestServer_initializeHeartbeatTimers(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.initializeHeartbeatTimers(); (err != nil) != tt.wantErr {
 				t.Errorf("Server.initializeHeartbeatTimers() error = %v, wantErr %v", err, tt.wantErr)
@@ -109,38 +103,35 @@ func TestServer_initializeHeartbeatTimers(t *testing.T) {
 
 func TestServer_resetHeartbeatTimer(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		id string
@@ -157,38 +148,35 @@ func TestServer_resetHeartbeatTimer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			got, err := s.resetHeartbeatTimer(tt.args.id)
 			if (err != nil) != tt.wantErr {
@@ -204,38 +192,35 @@ func TestServer_resetHeartbeatTimer(t *testing.T) {
 
 func TestServer_resetHeartbeatTimerLocked(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		id  string
@@ -251,38 +236,35 @@ func TestServer_resetHeartbeatTimerLocked(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.resetHeartbeatTimerLocked(tt.args.id, tt.args.ttl)
 		})
@@ -291,38 +273,35 @@ func TestServer_resetHeartbeatTimerLocked(t *testing.T) {
 
 func TestServer_invalidateHeartbeat(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		id string
@@ -337,38 +316,35 @@ func TestServer_invalidateHeartbeat(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.invalidateHeartbeat(tt.args.id)
 		})
@@ -377,38 +353,35 @@ func TestServer_invalidateHeartbeat(t *testing.T) {
 
 func TestServer_clearHeartbeatTimer(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		id string
@@ -424,38 +397,35 @@ func TestServer_clearHeartbeatTimer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.clearHeartbeatTimer(tt.args.id); (err != nil) != tt.wantErr {
 				t.Errorf("Server.clearHeartbeatTimer() error = %v, wantErr %v", err, tt.wantErr)
@@ -466,38 +436,35 @@ func TestServer_clearHeartbeatTimer(t *testing.T) {
 
 func TestServer_clearAllHeartbeatTimers(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name    string
@@ -509,38 +476,35 @@ func TestServer_clearAllHeartbeatTimers(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.clearAllHeartbeatTimers(); (err != nil) != tt.wantErr {
 				t.Errorf("Server.clearAllHeartbeatTimers() error = %v, wantErr %v", err, tt.wantErr)
@@ -551,38 +515,35 @@ func TestServer_clearAllHeartbeatTimers(t *testing.T) {
 
 func TestServer_heartbeatStats(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -593,38 +554,35 @@ func TestServer_heartbeatStats(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.heartbeatStats()
 		})
@@ -8,10 +8,12 @@ package server
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-memdb"
+	"github.com/hashicorp/go-multierror"
 
 	"github.com/actiontech/dtle/internal/client/driver"
 	"github.com/actiontech/dtle/internal/models"
@@ -19,15 +21,58 @@ import (
 	"github.com/actiontech/dtle/internal/server/store"
 
 	"github.com/mitchellh/copystructure"
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	// RegisterEnforceIndexErrPrefix is the prefix to use in errors caused by
 	// enforcing the job modify index during registers.
 	RegisterEnforceIndexErrPrefix = "Enforcing job modify index"
-	MaskedPassword                = "*"
+	// RegisterValidationErrPrefix is the prefix to use in errors caused by
+	// invalid job arguments during registers. The errors follow one per line.
+	RegisterValidationErrPrefix = "Job validation failed"
+	MaskedPassword              = "*"
 )
 
+// secretConfigKeys are the keys, at any depth of Task.Config, of which the values are masked in
+// the job list: the passwords of ConnectionConfig (including AdminPassword and the SOCKS5 password
+// of Tunnel) and of SASL of a Kafka destination, and EncryptionKey.
+var secretConfigKeys = []string{"Password", "AdminPassword", "Socks5Password", "EncryptionKey"}
+
+// maskSecrets replaces the values of secretConfigKeys in a task config with MaskedPassword.
+func maskSecrets(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if isSecretConfigValue(k, e) {
+				v[k] = MaskedPassword
+			} else {
+				maskSecrets(e)
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			maskSecrets(e)
+		}
+	}
+}
+
+// isSecretConfigValue tells whether the value of key is to be masked. An EncryptionKey of the form
+// "env:NAME" names an environment variable of the agents and is not masked.
+func isSecretConfigValue(key string, value interface{}) bool {
+	if key == "EncryptionKey" {
+		if s, ok := value.(string); ok && strings.HasPrefix(s, "env:") {
+			return false
+		}
+	}
+	for _, k := range secretConfigKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
 // Job endpoint is used for job interactions
 type Job struct {
 	srv *Server
@@ -50,10 +95,11 @@ func (j *Job) Register(args *models.JobRegisterRequest, reply *models.JobRespons
 	args.Job.Canonicalize()
 
 	// Validate the job.
-	/*if err := validateJob(args.Job); err != nil {
+	if err := validateJob(args.Job); err != nil {
+		j.srv.logger.Warnf("server.job: Register job %v: %v", args.Job.ID, err)
 		reply.Success = false
 		return err
-	}*/
+	}
 
 	if args.EnforceIndex {
 		// Lookup the job
@@ -122,6 +168,54 @@ func (j *Job) Register(args *models.JobRegisterRequest, reply *models.JobRespons
 	return nil
 }
 
+// validateJob checks the job and the job arguments of its tasks before it is scheduled,
+// without connecting to the databases. All problems are returned at once.
+func validateJob(job *models.Job) error {
+	errs := JobValidationErrors(job)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = "* " + err.Error()
+	}
+	return fmt.Errorf("%s:\n%s", RegisterValidationErrPrefix, strings.Join(msgs, "\n"))
+}
+
+// JobValidationErrors returns the problems found by validateJob, one by one. It connects to nothing,
+// and is also run offline by the job-validate command.
+func JobValidationErrors(job *models.Job) []error {
+	var mErr multierror.Error
+	if err := job.Validate(); err != nil {
+		multierror.Append(&mErr, err)
+	}
+	for _, task := range job.Tasks {
+		if task.Driver == "" {
+			continue // reported by job.Validate()
+		}
+		d, err := driver.NewDriver(task.Driver, driver.NewEmptyDriverContext())
+		if err != nil {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("task %v: %v", task.Type, err))
+			continue
+		}
+		validator, ok := d.(driver.ConfigValidator)
+		if !ok {
+			continue
+		}
+		if err := validator.ValidateConfig(task); err != nil {
+			errs := []error{err}
+			if merr, ok := err.(*multierror.Error); ok {
+				errs = merr.Errors
+			}
+			for _, err := range errs {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("task %v: %v", task.Type, err))
+			}
+		}
+	}
+	return mErr.Errors
+}
+
 func (j *Job) Renewal(args *models.JobRenewalRequest, reply *models.JobResponse) error {
 	if done, err := j.srv.forward("Job.Renewal", args, args, reply); done {
 		return err
@@ -266,9 +360,16 @@ func (j *Job) Validate(args *models.JobValidateRequest,
 		}
 
 		rep, err := d.Validate(task)
+		entry := j.srv.logger.WithFields(logrus.Fields{
+			"job":   args.Job.ID,
+			"task":  task.Type,
+			"event": "validate",
+		})
 		if err != nil {
+			entry.WithError(err).Warnf("server.job: task config validation failed")
 			return fmt.Errorf("task %q -> config: %v", task.Type, err)
 		}
+		entry.Infof("server.job: task config validated")
 		rep.Type = task.Type
 		reply.ValidationTasks = append(reply.ValidationTasks, rep)
 	}
@@ -457,6 +558,9 @@ func (j *Job) List(args *models.JobListRequest,
 					break
 				}
 				job := raw.(*models.Job)
+				if job != nil && !job.HasTags(args.Tags) {
+					continue
+				}
 				if job != nil && job.Status == models.JobStatusRunning {
 					job.StatusDescription = ""
 				}
@@ -468,14 +572,8 @@ func (j *Job) List(args *models.JobListRequest,
 				if !ok {
 					return fmt.Errorf("failed to deep copy job")
 				}
-				//fmt.Printf("**** mask password\n")
 				for _, t := range jobCopy.Tasks {
-					if connCfg, ok := t.Config["ConnectionConfig"]; ok {
-						if connCfgMap, ok := connCfg.(map[string]interface{}); ok {
-							//getStar := func() string { return "*" }
-							connCfgMap["Password"] = MaskedPassword
-						}
-					}
+					maskSecrets(t.Config)
 				}
 				jobs = append(jobs, job.Stub(jobCopy))
 			}
@@ -7,10 +7,52 @@
 package server
 
 import (
+	"reflect"
 	"testing"
+
 	"github.com/actiontech/dtle/internal/models"
 )
 
+func TestMaskSecrets(t *testing.T) {
+	config := map[string]interface{}{
+		"ConnectionConfig": map[string]interface{}{
+			"Host":          "127.0.0.1",
+			"User":          "repl",
+			"Password":      "p1",
+			"AdminUser":     "admin",
+			"AdminPassword": "p2",
+			"Tunnel":        map[string]interface{}{"Socks5User": "u", "Socks5Password": "p4"},
+		},
+		"SASL":          map[string]interface{}{"User": "u", "Password": "p3"},
+		"ReplicateDoDb": []interface{}{map[string]interface{}{"TableSchema": "db1"}},
+		"EncryptionKey": "MDEyMzQ1Njc4OWFiY2RlZg==",
+	}
+	maskSecrets(config)
+	want := map[string]interface{}{
+		"ConnectionConfig": map[string]interface{}{
+			"Host":          "127.0.0.1",
+			"User":          "repl",
+			"Password":      MaskedPassword,
+			"AdminUser":     "admin",
+			"AdminPassword": MaskedPassword,
+			"Tunnel":        map[string]interface{}{"Socks5User": "u", "Socks5Password": MaskedPassword},
+		},
+		"SASL":          map[string]interface{}{"User": "u", "Password": MaskedPassword},
+		"ReplicateDoDb": []interface{}{map[string]interface{}{"TableSchema": "db1"}},
+		"EncryptionKey": MaskedPassword,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("maskSecrets() = %v, want %v", config, want)
+	}
+
+	// a reference to an environment variable is not a secret
+	config = map[string]interface{}{"EncryptionKey": "env:DTLE_KEY"}
+	maskSecrets(config)
+	if config["EncryptionKey"] != "env:DTLE_KEY" {
+		t.Errorf("maskSecrets() masked EncryptionKey %v", config["EncryptionKey"])
+	}
+}
+
 func TestJob_Register(t *testing.T) {
 	type fields struct {
 		srv *Server
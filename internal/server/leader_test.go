@@ -9,53 +9,50 @@ package server
 import (
 	"net"
 	"net/rpc"
-	"sync"
 	"testing"
 	"time"
+
 	uconf "github.com/actiontech/dtle/internal/config"
-	ulog "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/server/store"
 
 	"github.com/docker/leadership"
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
+	"github.com/sirupsen/logrus"
 )
 
 func TestServer_monitorLeadership(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -66,38 +63,35 @@ func TestServer_monitorLeadership(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.monitorLeadership()
 		})
@@ -106,38 +100,35 @@ func TestServer_monitorLeadership(t *testing.T) {
 
 func TestServer_leaderLoop(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		stopCh chan struct{}
@@ -152,38 +143,35 @@ func TestServer_leaderLoop(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.leaderLoop(tt.args.stopCh)
 		})
@@ -192,38 +180,35 @@ func TestServer_leaderLoop(t *testing.T) {
 
 func TestServer_establishLeadership(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		stopCh chan struct{}
@@ -239,38 +224,35 @@ func TestServer_establishLeadership(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.establishLeadership(tt.args.stopCh); (err != nil) != tt.wantErr {
 				t.Errorf("Server.establishLeadership() error = %v, wantErr %v", err, tt.wantErr)
@@ -281,38 +263,35 @@ func TestServer_establishLeadership(t *testing.T) {
 
 func TestServer_restoreEvals(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name    string
@@ -324,38 +303,35 @@ func TestServer_restoreEvals(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.restoreEvals(); (err != nil) != tt.wantErr {
 				t.Errorf("Server.restoreEvals() error = %v, wantErr %v", err, tt.wantErr)
@@ -366,38 +342,35 @@ func TestServer_restoreEvals(t *testing.T) {
 
 func TestServer_reapFailedEvaluations(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		stopCh chan struct{}
@@ -412,38 +385,35 @@ func TestServer_reapFailedEvaluations(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.reapFailedEvaluations(tt.args.stopCh)
 		})
@@ -452,38 +422,35 @@ func TestServer_reapFailedEvaluations(t *testing.T) {
 
 func TestServer_reapDupBlockedEvaluations(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		stopCh chan struct{}
@@ -498,38 +465,35 @@ func TestServer_reapDupBlockedEvaluations(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.reapDupBlockedEvaluations(tt.args.stopCh)
 		})
@@ -538,38 +502,35 @@ func TestServer_reapDupBlockedEvaluations(t *testing.T) {
 
 func TestServer_periodicUnblockFailedEvals(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		stopCh chan struct{}
@@ -584,38 +545,35 @@ func TestServer_periodicUnblockFailedEvals(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.periodicUnblockFailedEvals(tt.args.stopCh)
 		})
@@ -624,38 +582,35 @@ func TestServer_periodicUnblockFailedEvals(t *testing.T) {
 
 func TestServer_revokeLeadership(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name    string
@@ -667,38 +622,35 @@ func TestServer_revokeLeadership(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.revokeLeadership(); (err != nil) != tt.wantErr {
 				t.Errorf("Server.revokeLeadership() error = %v, wantErr %v", err, tt.wantErr)
@@ -709,38 +661,35 @@ func TestServer_revokeLeadership(t *testing.T) {
 
 func TestServer_reconcile(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name    string
@@ -752,38 +701,35 @@ func TestServer_reconcile(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.reconcile(); (err != nil) != tt.wantErr {
 				t.Errorf("Server.reconcile() error = %v, wantErr %v", err, tt.wantErr)
@@ -794,38 +740,35 @@ func TestServer_reconcile(t *testing.T) {
 
 func TestServer_reconcileMember(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		member serf.Member
@@ -841,38 +784,35 @@ func TestServer_reconcileMember(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.reconcileMember(tt.args.member); (err != nil) != tt.wantErr {
 				t.Errorf("Server.reconcileMember() error = %v, wantErr %v", err, tt.wantErr)
@@ -883,38 +823,35 @@ func TestServer_reconcileMember(t *testing.T) {
 
 func TestServer_addRaftPeer(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		m     serf.Member
@@ -931,38 +868,35 @@ func TestServer_addRaftPeer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.addRaftPeer(tt.args.m, tt.args.parts); (err != nil) != tt.wantErr {
 				t.Errorf("Server.addRaftPeer() error = %v, wantErr %v", err, tt.wantErr)
@@ -973,38 +907,35 @@ func TestServer_addRaftPeer(t *testing.T) {
 
 func TestServer_removeRaftPeer(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		m     serf.Member
@@ -1021,38 +952,35 @@ func TestServer_removeRaftPeer(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.removeRaftPeer(tt.args.m, tt.args.parts); (err != nil) != tt.wantErr {
 				t.Errorf("Server.removeRaftPeer() error = %v, wantErr %v", err, tt.wantErr)
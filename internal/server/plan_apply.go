@@ -330,5 +330,11 @@ func evaluateNodePlan(snap *store.StateSnapshot, plan *models.Plan, nodeID strin
 	proposed = models.RemoveAllocs(existingAlloc, remove)
 	proposed = append(proposed, plan.NodeAllocation[nodeID]...)
 
+	// The schedulers check MaxRunningTasks on their own snapshots, so plans of
+	// concurrent evaluations are checked here again.
+	if node.MaxRunningTasks > 0 && len(proposed) > node.MaxRunningTasks {
+		return false, nil
+	}
+
 	return true, err
 }
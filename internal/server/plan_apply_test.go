@@ -10,11 +10,10 @@ import (
 	"net"
 	"net/rpc"
 	"reflect"
-	"sync"
 	"testing"
 	"time"
+
 	uconf "github.com/actiontech/dtle/internal/config"
-	ulog "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/models"
 	"github.com/actiontech/dtle/internal/server/store"
 
@@ -22,42 +21,40 @@ import (
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
+	"github.com/sirupsen/logrus"
 )
 
 func TestServer_planApply(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -68,38 +65,35 @@ func TestServer_planApply(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.planApply()
 		})
@@ -108,38 +102,35 @@ func TestServer_planApply(t *testing.T) {
 
 func TestServer_applyPlan(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		job    *models.Job
@@ -158,38 +149,35 @@ func TestServer_applyPlan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			got, err := s.applyPlan(tt.args.job, tt.args.result, tt.args.snap)
 			if (err != nil) != tt.wantErr {
@@ -205,38 +193,35 @@ func TestServer_applyPlan(t *testing.T) {
 
 func TestServer_asyncPlanWait(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		waitCh  chan struct{}
@@ -254,38 +239,35 @@ func TestServer_asyncPlanWait(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.asyncPlanWait(tt.args.waitCh, tt.args.future, tt.args.result, tt.args.pending)
 		})
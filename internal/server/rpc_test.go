@@ -11,11 +11,10 @@ import (
 	"net"
 	"net/rpc"
 	"reflect"
-	"sync"
 	"testing"
 	"time"
+
 	uconf "github.com/actiontech/dtle/internal/config"
-	ulog "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/models"
 	"github.com/actiontech/dtle/internal/server/store"
 
@@ -23,6 +22,7 @@ import (
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewClientCodec(t *testing.T) {
@@ -67,38 +67,35 @@ func TestNewServerCodec(t *testing.T) {
 
 func TestServer_listen(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -109,38 +106,35 @@ func TestServer_listen(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.listen()
 		})
@@ -149,38 +143,35 @@ func TestServer_listen(t *testing.T) {
 
 func TestServer_handleConn(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		conn net.Conn
@@ -195,38 +186,35 @@ func TestServer_handleConn(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.handleConn(tt.args.conn)
 		})
@@ -235,38 +223,35 @@ func TestServer_handleConn(t *testing.T) {
 
 func TestServer_handleMultiplex(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		conn net.Conn
@@ -281,38 +266,35 @@ func TestServer_handleMultiplex(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.handleMultiplex(tt.args.conn)
 		})
@@ -321,38 +303,35 @@ func TestServer_handleMultiplex(t *testing.T) {
 
 func TestServer_handleUdupConn(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		conn net.Conn
@@ -367,38 +346,35 @@ func TestServer_handleUdupConn(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.handleUdupConn(tt.args.conn)
 		})
@@ -407,38 +383,35 @@ func TestServer_handleUdupConn(t *testing.T) {
 
 func TestServer_forward(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		method string
@@ -458,38 +431,35 @@ func TestServer_forward(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			got, err := s.forward(tt.args.method, tt.args.info, tt.args.args, tt.args.reply)
 			if (err != nil) != tt.wantErr {
@@ -505,38 +475,35 @@ func TestServer_forward(t *testing.T) {
 
 func TestServer_getLeader(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -549,38 +516,35 @@ func TestServer_getLeader(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			got, got1 := s.getLeader()
 			if got != tt.want {
@@ -595,38 +559,35 @@ func TestServer_getLeader(t *testing.T) {
 
 func TestServer_forwardLeader(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		server *serverParts
@@ -645,38 +606,35 @@ func TestServer_forwardLeader(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.forwardLeader(tt.args.server, tt.args.method, tt.args.args, tt.args.reply); (err != nil) != tt.wantErr {
 				t.Errorf("Server.forwardLeader() error = %v, wantErr %v", err, tt.wantErr)
@@ -687,38 +645,35 @@ func TestServer_forwardLeader(t *testing.T) {
 
 func TestServer_forwardRegion(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		region string
@@ -737,38 +692,35 @@ func TestServer_forwardRegion(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.forwardRegion(tt.args.region, tt.args.method, tt.args.args, tt.args.reply); (err != nil) != tt.wantErr {
 				t.Errorf("Server.forwardRegion() error = %v, wantErr %v", err, tt.wantErr)
@@ -779,38 +731,35 @@ func TestServer_forwardRegion(t *testing.T) {
 
 func TestServer_raftApplyFuture(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		t   models.MessageType
@@ -828,38 +777,35 @@ func TestServer_raftApplyFuture(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			got, err := s.raftApplyFuture(tt.args.t, tt.args.msg)
 			if (err != nil) != tt.wantErr {
@@ -875,38 +821,35 @@ func TestServer_raftApplyFuture(t *testing.T) {
 
 func TestServer_raftApply(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		t   models.MessageType
@@ -925,38 +868,35 @@ func TestServer_raftApply(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			got, got1, err := s.raftApply(tt.args.t, tt.args.msg)
 			if (err != nil) != tt.wantErr {
@@ -975,38 +915,35 @@ func TestServer_raftApply(t *testing.T) {
 
 func TestServer_setQueryMeta(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		m *models.QueryMeta
@@ -1021,38 +958,35 @@ func TestServer_setQueryMeta(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.setQueryMeta(tt.args.m)
 		})
@@ -1061,38 +995,35 @@ func TestServer_setQueryMeta(t *testing.T) {
 
 func TestServer_blockingRPC(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		opts *blockingOptions
@@ -1108,38 +1039,35 @@ func TestServer_blockingRPC(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			if err := s.blockingRPC(tt.args.opts); (err != nil) != tt.wantErr {
 				t.Errorf("Server.blockingRPC() error = %v, wantErr %v", err, tt.wantErr)
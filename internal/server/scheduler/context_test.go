@@ -10,10 +10,11 @@ import (
 	"reflect"
 	"regexp"
 	"testing"
-	log "github.com/actiontech/dtle/internal/logger"
+
 	"github.com/actiontech/dtle/internal/models"
 
 	version "github.com/hashicorp/go-version"
+	"github.com/sirupsen/logrus"
 )
 
 func TestEvalCache_RegexpCache(t *testing.T) {
@@ -70,7 +71,7 @@ func TestNewEvalContext(t *testing.T) {
 	type args struct {
 		s   State
 		p   *models.Plan
-		log *log.Logger
+		log *logrus.Logger
 	}
 	tests := []struct {
 		name string
@@ -93,7 +94,7 @@ func TestEvalContext_State(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -126,7 +127,7 @@ func TestEvalContext_Plan(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -159,14 +160,14 @@ func TestEvalContext_Logger(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
 	tests := []struct {
 		name   string
 		fields fields
-		want   *log.Logger
+		want   *logrus.Logger
 	}{
 		// TODO: Add test cases.
 	}
@@ -192,7 +193,7 @@ func TestEvalContext_Metrics(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -225,7 +226,7 @@ func TestEvalContext_SetState(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -259,7 +260,7 @@ func TestEvalContext_Reset(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -289,7 +290,7 @@ func TestEvalContext_ProposedAllocs(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -332,7 +333,7 @@ func TestEvalContext_Eligibility(t *testing.T) {
 		EvalCache   EvalCache
 		state       State
 		plan        *models.Plan
-		logger      *log.Logger
+		logger      *logrus.Logger
 		metrics     *models.AllocMetric
 		eligibility *EvalEligibility
 	}
@@ -40,6 +40,10 @@ const (
 	// blockedEvalFailedPlacements is the description used for blocked evals
 	// that are a result of failing to place all allocations.
 	blockedEvalFailedPlacements = "created to place remaining allocations"
+
+	// maxRunningTasksDimension is the dimension of the nodes exhausted as they
+	// run their MaxRunningTasks already
+	maxRunningTasksDimension = "max running tasks"
 )
 
 // SetStatusError is used to set the status of the evaluation to the given error
@@ -389,16 +393,40 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 		}
 
 		if preferredNode != nil {
-			// do nothing
-		} else {
-			nodeId := nodes[rand.Intn(len(nodes))].ID
-			s.logger.Debugf("sched: no preferred node. Auto selected node %v for task %v", nodeId, missing.Name)
-
-			ws := memdb.NewWatchSet() // TODO what is ws used for?
-			preferredNode, err = s.state.NodeByID(ws, nodeId)
+			hasRoom, err := nodeHasRoom(s.state, s.plan, preferredNode)
 			if err != nil {
 				return err
 			}
+			if !hasRoom {
+				s.logger.Debugf("sched: preferred node %v of task %v runs %v tasks already",
+					preferredNode.ID, missing.Name, preferredNode.MaxRunningTasks)
+				s.ctx.Metrics().ExhaustedNode(preferredNode, maxRunningTasksDimension)
+				preferredNode = nil
+			}
+		} else {
+			var candidates []*models.Node
+			for _, node := range nodes {
+				hasRoom, err := nodeHasRoom(s.state, s.plan, node)
+				if err != nil {
+					return err
+				}
+				if hasRoom {
+					candidates = append(candidates, node)
+				} else {
+					s.ctx.Metrics().ExhaustedNode(node, maxRunningTasksDimension)
+				}
+			}
+
+			if len(candidates) > 0 {
+				nodeId := candidates[rand.Intn(len(candidates))].ID
+				s.logger.Debugf("sched: no preferred node. Auto selected node %v for task %v", nodeId, missing.Name)
+
+				ws := memdb.NewWatchSet() // TODO what is ws used for?
+				preferredNode, err = s.state.NodeByID(ws, nodeId)
+				if err != nil {
+					return err
+				}
+			}
 		}
 
 		// Store the available nodes by datacenter
@@ -426,7 +454,9 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 
 			if missing.Task.Type == models.TaskTypeDest {
 				for i, task := range s.job.Tasks {
-					task.Config["NatsAddr"] = preferredNode.NatsAdvertiseAddr
+					if !models.NatsAddrIsList(task.Config["NatsAddr"]) {
+						task.Config["NatsAddr"] = preferredNode.NatsAdvertiseAddr
+					}
 					s.job.Tasks[i] = task
 				}
 			}
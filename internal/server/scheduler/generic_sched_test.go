@@ -7,10 +7,16 @@
 package scheduler
 
 import (
+	"fmt"
+	"os"
 	"reflect"
 	"testing"
-	log "github.com/actiontech/dtle/internal/logger"
+
+	memdb "github.com/hashicorp/go-memdb"
+
 	"github.com/actiontech/dtle/internal/models"
+	"github.com/actiontech/dtle/internal/server/store"
+	"github.com/sirupsen/logrus"
 )
 
 func TestSetStatusError_Error(t *testing.T) {
@@ -40,7 +46,7 @@ func TestSetStatusError_Error(t *testing.T) {
 
 func TestNewGenericScheduler(t *testing.T) {
 	type args struct {
-		logger  *log.Logger
+		logger  *logrus.Logger
 		state   State
 		planner Planner
 	}
@@ -62,7 +68,7 @@ func TestNewGenericScheduler(t *testing.T) {
 
 func TestGenericScheduler_Process(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -111,7 +117,7 @@ func TestGenericScheduler_Process(t *testing.T) {
 
 func TestGenericScheduler_createBlockedEval(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -160,7 +166,7 @@ func TestGenericScheduler_createBlockedEval(t *testing.T) {
 
 func TestGenericScheduler_process(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -211,7 +217,7 @@ func TestGenericScheduler_process(t *testing.T) {
 
 func TestGenericScheduler_filterCompleteAllocs(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -265,7 +271,7 @@ func TestGenericScheduler_filterCompleteAllocs(t *testing.T) {
 
 func TestGenericScheduler_computeJobAllocs(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -310,7 +316,7 @@ func TestGenericScheduler_computeJobAllocs(t *testing.T) {
 
 func TestGenericScheduler_computePlacements(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -359,7 +365,7 @@ func TestGenericScheduler_computePlacements(t *testing.T) {
 
 func TestGenericScheduler_findPreferredNode(t *testing.T) {
 	type fields struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		state          State
 		planner        Planner
 		eval           *models.Evaluation
@@ -411,3 +417,76 @@ func TestGenericScheduler_findPreferredNode(t *testing.T) {
 		})
 	}
 }
+
+func TestGenericScheduler_maxRunningTasks(t *testing.T) {
+	state, err := store.NewStateStore(os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Harness{State: state, nextIndex: 1}
+
+	node := &models.Node{
+		ID:              models.GenerateUUID(),
+		Datacenter:      "dc1",
+		Name:            "node1",
+		Status:          models.NodeStatusReady,
+		MaxRunningTasks: 2,
+	}
+	if err := state.UpsertNode(h.NextIndex(), node); err != nil {
+		t.Fatal(err)
+	}
+
+	// place N+1 jobs of a task each on the node running at most N tasks
+	var evals []*models.Evaluation
+	for i := 0; i <= node.MaxRunningTasks; i++ {
+		task := models.NewTask()
+		task.Type = models.TaskTypeSrc
+		task.Config = map[string]interface{}{}
+		job := &models.Job{
+			ID:          models.GenerateUUID(),
+			Name:        fmt.Sprintf("job%d", i),
+			Type:        models.JobTypeSync,
+			Datacenters: []string{"dc1"},
+			Tasks:       []*models.Task{task},
+			Status:      models.JobStatusPending,
+		}
+		if err := state.UpsertJob(h.NextIndex(), job); err != nil {
+			t.Fatal(err)
+		}
+		eval := &models.Evaluation{
+			ID:          models.GenerateUUID(),
+			Type:        job.Type,
+			TriggeredBy: models.EvalTriggerJobRegister,
+			JobID:       job.ID,
+			Status:      models.EvalStatusPending,
+		}
+		if err := h.Process(NewGenericScheduler, eval); err != nil {
+			t.Fatalf("job%d: %v", i, err)
+		}
+		evals = append(evals, eval)
+	}
+
+	ws := memdb.NewWatchSet()
+	allocs, err := state.AllocsByNodeTerminal(ws, node.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allocs) != node.MaxRunningTasks {
+		t.Fatalf("got %v tasks on the node, want %v", len(allocs), node.MaxRunningTasks)
+	}
+	for _, alloc := range allocs {
+		if alloc.JobID == evals[len(evals)-1].JobID {
+			t.Fatalf("the last job is placed: %v", alloc.ID)
+		}
+	}
+
+	// the last job is queued until a task of the node stops
+	if len(h.CreateEvals) != 1 || h.CreateEvals[0].Status != models.EvalStatusBlocked ||
+		h.CreateEvals[0].JobID != evals[len(evals)-1].JobID {
+		t.Fatalf("got created evals %v, want a blocked eval of the last job", h.CreateEvals)
+	}
+	metric := h.Evals[len(h.Evals)-1].FailedTGAllocs[models.TaskTypeSrc]
+	if metric == nil || metric.DimensionExhausted[maxRunningTasksDimension] != 1 {
+		t.Fatalf("got failed allocs %v, want the node exhausted", metric)
+	}
+}
@@ -8,23 +8,21 @@ package scheduler
 
 import (
 	"reflect"
-	"sync"
 	"testing"
+
 	"github.com/actiontech/dtle/internal/models"
 	"github.com/actiontech/dtle/internal/server/store"
 )
 
 func TestHarness_SubmitPlan(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		plan *models.Plan
@@ -42,15 +40,13 @@ func TestHarness_SubmitPlan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			got, got1, err := h.SubmitPlan(tt.args.plan)
 			if (err != nil) != tt.wantErr {
@@ -69,15 +65,13 @@ func TestHarness_SubmitPlan(t *testing.T) {
 
 func TestHarness_UpdateEval(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		eval *models.Evaluation
@@ -93,15 +87,13 @@ func TestHarness_UpdateEval(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if err := h.UpdateEval(tt.args.eval); (err != nil) != tt.wantErr {
 				t.Errorf("Harness.UpdateEval() error = %v, wantErr %v", err, tt.wantErr)
@@ -112,15 +104,13 @@ func TestHarness_UpdateEval(t *testing.T) {
 
 func TestHarness_CreateEval(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		eval *models.Evaluation
@@ -136,15 +126,13 @@ func TestHarness_CreateEval(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if err := h.CreateEval(tt.args.eval); (err != nil) != tt.wantErr {
 				t.Errorf("Harness.CreateEval() error = %v, wantErr %v", err, tt.wantErr)
@@ -155,15 +143,13 @@ func TestHarness_CreateEval(t *testing.T) {
 
 func TestHarness_ReblockEval(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		eval *models.Evaluation
@@ -179,15 +165,13 @@ func TestHarness_ReblockEval(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if err := h.ReblockEval(tt.args.eval); (err != nil) != tt.wantErr {
 				t.Errorf("Harness.ReblockEval() error = %v, wantErr %v", err, tt.wantErr)
@@ -198,15 +182,13 @@ func TestHarness_ReblockEval(t *testing.T) {
 
 func TestHarness_NextIndex(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	tests := []struct {
 		name   string
@@ -218,15 +200,13 @@ func TestHarness_NextIndex(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if got := h.NextIndex(); got != tt.want {
 				t.Errorf("Harness.NextIndex() = %v, want %v", got, tt.want)
@@ -237,15 +217,13 @@ func TestHarness_NextIndex(t *testing.T) {
 
 func TestHarness_Snapshot(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	tests := []struct {
 		name   string
@@ -257,15 +235,13 @@ func TestHarness_Snapshot(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if got := h.Snapshot(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Harness.Snapshot() = %v, want %v", got, tt.want)
@@ -276,15 +252,13 @@ func TestHarness_Snapshot(t *testing.T) {
 
 func TestHarness_Scheduler(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		factory Factory
@@ -300,15 +274,13 @@ func TestHarness_Scheduler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if got := h.Scheduler(tt.args.factory); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Harness.Scheduler() = %v, want %v", got, tt.want)
@@ -319,15 +291,13 @@ func TestHarness_Scheduler(t *testing.T) {
 
 func TestHarness_Process(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		factory Factory
@@ -344,15 +314,13 @@ func TestHarness_Process(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			if err := h.Process(tt.args.factory, tt.args.eval); (err != nil) != tt.wantErr {
 				t.Errorf("Harness.Process() error = %v, wantErr %v", err, tt.wantErr)
@@ -363,15 +331,13 @@ func TestHarness_Process(t *testing.T) {
 
 func TestHarness_AssertEvalStatus(t *testing.T) {
 	type fields struct {
-		State         *store.StateStore
-		Planner       Planner
-		planLock      sync.Mutex
-		Plans         []*models.Plan
-		Evals         []*models.Evaluation
-		CreateEvals   []*models.Evaluation
-		ReblockEvals  []*models.Evaluation
-		nextIndex     uint64
-		nextIndexLock sync.Mutex
+		State        *store.StateStore
+		Planner      Planner
+		Plans        []*models.Plan
+		Evals        []*models.Evaluation
+		CreateEvals  []*models.Evaluation
+		ReblockEvals []*models.Evaluation
+		nextIndex    uint64
 	}
 	type args struct {
 		t     *testing.T
@@ -387,15 +353,13 @@ func TestHarness_AssertEvalStatus(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Harness{
-				State:         tt.fields.State,
-				Planner:       tt.fields.Planner,
-				planLock:      tt.fields.planLock,
-				Plans:         tt.fields.Plans,
-				Evals:         tt.fields.Evals,
-				CreateEvals:   tt.fields.CreateEvals,
-				ReblockEvals:  tt.fields.ReblockEvals,
-				nextIndex:     tt.fields.nextIndex,
-				nextIndexLock: tt.fields.nextIndexLock,
+				State:        tt.fields.State,
+				Planner:      tt.fields.Planner,
+				Plans:        tt.fields.Plans,
+				Evals:        tt.fields.Evals,
+				CreateEvals:  tt.fields.CreateEvals,
+				ReblockEvals: tt.fields.ReblockEvals,
+				nextIndex:    tt.fields.nextIndex,
 			}
 			h.AssertEvalStatus(tt.args.t, tt.args.state)
 		})
@@ -9,13 +9,14 @@ package scheduler
 import (
 	"reflect"
 	"testing"
-	ulog "github.com/actiontech/dtle/internal/logger"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewScheduler(t *testing.T) {
 	type args struct {
 		name    string
-		logger  *ulog.Logger
+		logger  *logrus.Logger
 		state   State
 		planner Planner
 	}
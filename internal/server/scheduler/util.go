@@ -206,6 +206,29 @@ func readyNodesInDCs(state State, dcs []string) ([]*models.Node, map[string]int,
 	return out, dcMap, nil
 }
 
+// nodeHasRoom returns whether a node can take one more task under its
+// MaxRunningTasks, counting its non-terminal allocations together with those
+// the plan places on it and without those the plan stops.
+func nodeHasRoom(state State, plan *models.Plan, node *models.Node) (bool, error) {
+	if node.MaxRunningTasks <= 0 {
+		return true, nil
+	}
+
+	ws := memdb.NewWatchSet()
+	existingAlloc, err := state.AllocsByNodeTerminal(ws, node.ID, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to get existing allocations for '%s': %v", node.ID, err)
+	}
+
+	var remove []*models.Allocation
+	remove = append(remove, plan.NodeUpdate[node.ID]...)
+	remove = append(remove, plan.NodeAllocation[node.ID]...)
+	proposed := models.RemoveAllocs(existingAlloc, remove)
+	proposed = append(proposed, plan.NodeAllocation[node.ID]...)
+
+	return len(proposed) < node.MaxRunningTasks, nil
+}
+
 // retryMax is used to retry a callback until it returns success or
 // a maximum number of attempts is reached. An optional reset function may be
 // passed which is called after each failed iteration. If the reset function is
@@ -278,6 +301,7 @@ func shuffleNodes(nodes []*models.Node) {
 // tasksUpdated does a diff between tasks to see if the
 // tasks, their drivers, environment variables or config have updated. The
 // inputs are the task name to diff and two jobs to diff.
+// Changes of models.RuntimeConfigKeys are applied in-place.
 func tasksUpdated(jobA, jobB *models.Job, task string) bool {
 	a := jobA.LookupTask(task)
 	b := jobB.LookupTask(task)
@@ -287,13 +311,25 @@ func tasksUpdated(jobA, jobB *models.Job, task string) bool {
 		return true
 	}
 
-	if !reflect.DeepEqual(a.Config, b.Config) {
+	if !reflect.DeepEqual(withoutRuntimeConfig(a.Config), withoutRuntimeConfig(b.Config)) {
 		return true
 	}
 
 	return false
 }
 
+// withoutRuntimeConfig returns a copy of the task config without models.RuntimeConfigKeys.
+func withoutRuntimeConfig(config map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		result[k] = v
+	}
+	for _, k := range models.RuntimeConfigKeys {
+		delete(result, k)
+	}
+	return result
+}
+
 // setStatus is used to update the status of the evaluation
 func setStatus(logger *logrus.Logger, planner Planner,
 	eval, nextEval, spawnedBlocked *models.Evaluation,
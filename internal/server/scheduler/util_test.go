@@ -9,8 +9,9 @@ package scheduler
 import (
 	"reflect"
 	"testing"
-	log "github.com/actiontech/dtle/internal/logger"
+
 	"github.com/actiontech/dtle/internal/models"
+	"github.com/sirupsen/logrus"
 )
 
 func Test_materializeTasks(t *testing.T) {
@@ -255,12 +256,36 @@ func Test_tasksUpdated(t *testing.T) {
 		jobB *models.Job
 		task string
 	}
+	job := func(config map[string]interface{}) *models.Job {
+		return &models.Job{Tasks: []*models.Task{{Type: models.TaskTypeDest, Driver: models.TaskDriverMySQL, Config: config}}}
+	}
 	tests := []struct {
 		name string
 		args args
 		want bool
 	}{
-		// TODO: Add test cases.
+		{
+			name: "same",
+			args: args{job(map[string]interface{}{"ChunkSize": 2000}), job(map[string]interface{}{"ChunkSize": 2000}), models.TaskTypeDest},
+			want: false,
+		},
+		{
+			name: "config",
+			args: args{job(map[string]interface{}{"ChunkSize": 2000}), job(map[string]interface{}{"ChunkSize": 1000}), models.TaskTypeDest},
+			want: true,
+		},
+		{
+			name: "runtime config only",
+			args: args{job(map[string]interface{}{"ChunkSize": 2000}),
+				job(map[string]interface{}{"ChunkSize": 2000, "ApplyEventRateLimit": 100}), models.TaskTypeDest},
+			want: false,
+		},
+		{
+			name: "runtime config and config",
+			args: args{job(map[string]interface{}{"ChunkSize": 2000, "ApplyEventRateLimit": 50}),
+				job(map[string]interface{}{"ChunkSize": 1000, "ApplyEventRateLimit": 100}), models.TaskTypeDest},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -273,7 +298,7 @@ func Test_tasksUpdated(t *testing.T) {
 
 func Test_setStatus(t *testing.T) {
 	type args struct {
-		logger         *log.Logger
+		logger         *logrus.Logger
 		planner        Planner
 		eval           *models.Evaluation
 		nextEval       *models.Evaluation
@@ -371,7 +396,7 @@ func Test_desiredUpdates(t *testing.T) {
 
 func Test_adjustQueuedAllocations(t *testing.T) {
 	type args struct {
-		logger       *log.Logger
+		logger       *logrus.Logger
 		result       *models.PlanResult
 		queuedAllocs map[string]int
 	}
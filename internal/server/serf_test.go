@@ -9,53 +9,50 @@ package server
 import (
 	"net"
 	"net/rpc"
-	"sync"
 	"testing"
 	"time"
+
 	uconf "github.com/actiontech/dtle/internal/config"
-	ulog "github.com/actiontech/dtle/internal/logger"
 	"github.com/actiontech/dtle/internal/server/store"
 
 	"github.com/docker/leadership"
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 	"github.com/hashicorp/serf/serf"
+	"github.com/sirupsen/logrus"
 )
 
 func TestServer_serfEventHandler(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -66,38 +63,35 @@ func TestServer_serfEventHandler(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.serfEventHandler()
 		})
@@ -106,38 +100,35 @@ func TestServer_serfEventHandler(t *testing.T) {
 
 func TestServer_nodeJoin(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		me serf.MemberEvent
@@ -152,38 +143,35 @@ func TestServer_nodeJoin(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.nodeJoin(tt.args.me)
 		})
@@ -192,38 +180,35 @@ func TestServer_nodeJoin(t *testing.T) {
 
 func TestServer_maybeBootstrap(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	tests := []struct {
 		name   string
@@ -234,38 +219,35 @@ func TestServer_maybeBootstrap(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.maybeBootstrap()
 		})
@@ -274,38 +256,35 @@ func TestServer_maybeBootstrap(t *testing.T) {
 
 func TestServer_nodeFailed(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		me serf.MemberEvent
@@ -320,38 +299,35 @@ func TestServer_nodeFailed(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.nodeFailed(tt.args.me)
 		})
@@ -360,38 +336,35 @@ func TestServer_nodeFailed(t *testing.T) {
 
 func TestServer_localMemberEvent(t *testing.T) {
 	type fields struct {
-		config              *uconf.ServerConfig
-		logger              *ulog.Logger
-		connPool            *ConnPool
-		endpoints           endpoints
-		leaderCh            <-chan bool
-		raft                *raft.Raft
-		raftLayer           *RaftLayer
-		raftStore           *raftboltdb.BoltStore
-		raftInmem           *raft.InmemStore
-		raftTransport       *raft.NetworkTransport
-		fsm                 *udupFSM
-		store               *store.Store
-		candidate           *leadership.Candidate
-		rpcListener         net.Listener
-		rpcServer           *rpc.Server
-		rpcAdvertise        net.Addr
-		peers               map[string][]*serverParts
-		localPeers          map[raft.ServerAddress]*serverParts
-		peerLock            sync.RWMutex
-		serf                *serf.Serf
-		reconcileCh         chan serf.Member
-		eventCh             chan serf.Event
-		evalBroker          *EvalBroker
-		blockedEvals        *BlockedEvals
-		planQueue           *PlanQueue
-		heartbeatTimers     map[string]*time.Timer
-		heartbeatTimersLock sync.Mutex
-		workers             []*Worker
-		left                bool
-		shutdown            bool
-		shutdownCh          chan struct{}
-		shutdownLock        sync.Mutex
+		config          *uconf.ServerConfig
+		logger          *logrus.Logger
+		connPool        *ConnPool
+		endpoints       endpoints
+		leaderCh        <-chan bool
+		raft            *raft.Raft
+		raftLayer       *RaftLayer
+		raftStore       *raftboltdb.BoltStore
+		raftInmem       *raft.InmemStore
+		raftTransport   *raft.NetworkTransport
+		fsm             *udupFSM
+		store           *store.Store
+		candidate       *leadership.Candidate
+		rpcListener     net.Listener
+		rpcServer       *rpc.Server
+		rpcAdvertise    net.Addr
+		peers           map[string][]*serverParts
+		localPeers      map[raft.ServerAddress]*serverParts
+		serf            *serf.Serf
+		reconcileCh     chan serf.Member
+		eventCh         chan serf.Event
+		evalBroker      *EvalBroker
+		blockedEvals    *BlockedEvals
+		planQueue       *PlanQueue
+		heartbeatTimers map[string]*time.Timer
+		workers         []*Worker
+		left            bool
+		shutdown        bool
+		shutdownCh      chan struct{}
 	}
 	type args struct {
 		me serf.MemberEvent
@@ -406,38 +379,35 @@ func TestServer_localMemberEvent(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Server{
-				config:              tt.fields.config,
-				logger:              tt.fields.logger,
-				connPool:            tt.fields.connPool,
-				endpoints:           tt.fields.endpoints,
-				leaderCh:            tt.fields.leaderCh,
-				raft:                tt.fields.raft,
-				raftLayer:           tt.fields.raftLayer,
-				raftStore:           tt.fields.raftStore,
-				raftInmem:           tt.fields.raftInmem,
-				raftTransport:       tt.fields.raftTransport,
-				fsm:                 tt.fields.fsm,
-				store:               tt.fields.store,
-				candidate:           tt.fields.candidate,
-				rpcListener:         tt.fields.rpcListener,
-				rpcServer:           tt.fields.rpcServer,
-				rpcAdvertise:        tt.fields.rpcAdvertise,
-				peers:               tt.fields.peers,
-				localPeers:          tt.fields.localPeers,
-				peerLock:            tt.fields.peerLock,
-				serf:                tt.fields.serf,
-				reconcileCh:         tt.fields.reconcileCh,
-				eventCh:             tt.fields.eventCh,
-				evalBroker:          tt.fields.evalBroker,
-				blockedEvals:        tt.fields.blockedEvals,
-				planQueue:           tt.fields.planQueue,
-				heartbeatTimers:     tt.fields.heartbeatTimers,
-				heartbeatTimersLock: tt.fields.heartbeatTimersLock,
-				workers:             tt.fields.workers,
-				left:                tt.fields.left,
-				shutdown:            tt.fields.shutdown,
-				shutdownCh:          tt.fields.shutdownCh,
-				shutdownLock:        tt.fields.shutdownLock,
+				config:          tt.fields.config,
+				logger:          tt.fields.logger,
+				connPool:        tt.fields.connPool,
+				endpoints:       tt.fields.endpoints,
+				leaderCh:        tt.fields.leaderCh,
+				raft:            tt.fields.raft,
+				raftLayer:       tt.fields.raftLayer,
+				raftStore:       tt.fields.raftStore,
+				raftInmem:       tt.fields.raftInmem,
+				raftTransport:   tt.fields.raftTransport,
+				fsm:             tt.fields.fsm,
+				store:           tt.fields.store,
+				candidate:       tt.fields.candidate,
+				rpcListener:     tt.fields.rpcListener,
+				rpcServer:       tt.fields.rpcServer,
+				rpcAdvertise:    tt.fields.rpcAdvertise,
+				peers:           tt.fields.peers,
+				localPeers:      tt.fields.localPeers,
+				serf:            tt.fields.serf,
+				reconcileCh:     tt.fields.reconcileCh,
+				eventCh:         tt.fields.eventCh,
+				evalBroker:      tt.fields.evalBroker,
+				blockedEvals:    tt.fields.blockedEvals,
+				planQueue:       tt.fields.planQueue,
+				heartbeatTimers: tt.fields.heartbeatTimers,
+				workers:         tt.fields.workers,
+				left:            tt.fields.left,
+				shutdown:        tt.fields.shutdown,
+				shutdownCh:      tt.fields.shutdownCh,
 			}
 			s.localMemberEvent(tt.args.me)
 		})
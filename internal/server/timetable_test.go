@@ -8,7 +8,6 @@ package server
 
 import (
 	"reflect"
-	"sync"
 	"testing"
 	"time"
 
@@ -41,7 +40,6 @@ func TestTimeTable_Serialize(t *testing.T) {
 		granularity time.Duration
 		limit       time.Duration
 		table       []TimeTableEntry
-		l           sync.RWMutex
 	}
 	type args struct {
 		enc *codec.Encoder
@@ -56,13 +54,12 @@ func TestTimeTable_Serialize(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t := &TimeTable{
+			tbl := &TimeTable{
 				granularity: tt.fields.granularity,
 				limit:       tt.fields.limit,
 				table:       tt.fields.table,
-				l:           tt.fields.l,
 			}
-			if err := t.Serialize(tt.args.enc); (err != nil) != tt.wantErr {
+			if err := tbl.Serialize(tt.args.enc); (err != nil) != tt.wantErr {
 				t.Errorf("TimeTable.Serialize() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -74,7 +71,6 @@ func TestTimeTable_Deserialize(t *testing.T) {
 		granularity time.Duration
 		limit       time.Duration
 		table       []TimeTableEntry
-		l           sync.RWMutex
 	}
 	type args struct {
 		dec *codec.Decoder
@@ -89,13 +85,12 @@ func TestTimeTable_Deserialize(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t := &TimeTable{
+			tbl := &TimeTable{
 				granularity: tt.fields.granularity,
 				limit:       tt.fields.limit,
 				table:       tt.fields.table,
-				l:           tt.fields.l,
 			}
-			if err := t.Deserialize(tt.args.dec); (err != nil) != tt.wantErr {
+			if err := tbl.Deserialize(tt.args.dec); (err != nil) != tt.wantErr {
 				t.Errorf("TimeTable.Deserialize() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -107,7 +102,6 @@ func TestTimeTable_Witness(t *testing.T) {
 		granularity time.Duration
 		limit       time.Duration
 		table       []TimeTableEntry
-		l           sync.RWMutex
 	}
 	type args struct {
 		index uint64
@@ -122,13 +116,12 @@ func TestTimeTable_Witness(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t := &TimeTable{
+			tbl := &TimeTable{
 				granularity: tt.fields.granularity,
 				limit:       tt.fields.limit,
 				table:       tt.fields.table,
-				l:           tt.fields.l,
 			}
-			t.Witness(tt.args.index, tt.args.when)
+			tbl.Witness(tt.args.index, tt.args.when)
 		})
 	}
 }
@@ -138,7 +131,6 @@ func TestTimeTable_NearestIndex(t *testing.T) {
 		granularity time.Duration
 		limit       time.Duration
 		table       []TimeTableEntry
-		l           sync.RWMutex
 	}
 	type args struct {
 		when time.Time
@@ -153,13 +145,12 @@ func TestTimeTable_NearestIndex(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t := &TimeTable{
+			tbl := &TimeTable{
 				granularity: tt.fields.granularity,
 				limit:       tt.fields.limit,
 				table:       tt.fields.table,
-				l:           tt.fields.l,
 			}
-			if got := t.NearestIndex(tt.args.when); got != tt.want {
+			if got := tbl.NearestIndex(tt.args.when); got != tt.want {
 				t.Errorf("TimeTable.NearestIndex() = %v, want %v", got, tt.want)
 			}
 		})
@@ -171,7 +162,6 @@ func TestTimeTable_NearestTime(t *testing.T) {
 		granularity time.Duration
 		limit       time.Duration
 		table       []TimeTableEntry
-		l           sync.RWMutex
 	}
 	type args struct {
 		index uint64
@@ -186,13 +176,12 @@ func TestTimeTable_NearestTime(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			t := &TimeTable{
+			tbl := &TimeTable{
 				granularity: tt.fields.granularity,
 				limit:       tt.fields.limit,
 				table:       tt.fields.table,
-				l:           tt.fields.l,
 			}
-			if got := t.NearestTime(tt.args.index); !reflect.DeepEqual(got, tt.want) {
+			if got := tbl.NearestTime(tt.args.index); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("TimeTable.NearestTime() = %v, want %v", got, tt.want)
 			}
 		})
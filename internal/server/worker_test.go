@@ -11,9 +11,10 @@ import (
 	"sync"
 	"testing"
 	"time"
-	log "github.com/actiontech/dtle/internal/logger"
+
 	"github.com/actiontech/dtle/internal/models"
 	"github.com/actiontech/dtle/internal/server/scheduler"
+	"github.com/sirupsen/logrus"
 )
 
 func TestNewWorker(t *testing.T) {
@@ -45,10 +46,9 @@ func TestNewWorker(t *testing.T) {
 func TestWorker_SetPause(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -71,7 +71,6 @@ func TestWorker_SetPause(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -85,10 +84,9 @@ func TestWorker_SetPause(t *testing.T) {
 func TestWorker_checkPaused(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -107,7 +105,6 @@ func TestWorker_checkPaused(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -121,10 +118,9 @@ func TestWorker_checkPaused(t *testing.T) {
 func TestWorker_run(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -143,7 +139,6 @@ func TestWorker_run(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -157,10 +152,9 @@ func TestWorker_run(t *testing.T) {
 func TestWorker_dequeueEvaluation(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -186,7 +180,6 @@ func TestWorker_dequeueEvaluation(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -209,10 +202,9 @@ func TestWorker_dequeueEvaluation(t *testing.T) {
 func TestWorker_sendAck(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -237,7 +229,6 @@ func TestWorker_sendAck(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -251,10 +242,9 @@ func TestWorker_sendAck(t *testing.T) {
 func TestWorker_waitForIndex(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -279,7 +269,6 @@ func TestWorker_waitForIndex(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -295,10 +284,9 @@ func TestWorker_waitForIndex(t *testing.T) {
 func TestWorker_invokeScheduler(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -323,7 +311,6 @@ func TestWorker_invokeScheduler(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -339,10 +326,9 @@ func TestWorker_invokeScheduler(t *testing.T) {
 func TestWorker_SubmitPlan(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -368,7 +354,6 @@ func TestWorker_SubmitPlan(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -392,10 +377,9 @@ func TestWorker_SubmitPlan(t *testing.T) {
 func TestWorker_UpdateEval(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -419,7 +403,6 @@ func TestWorker_UpdateEval(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -435,10 +418,9 @@ func TestWorker_UpdateEval(t *testing.T) {
 func TestWorker_CreateEval(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -462,7 +444,6 @@ func TestWorker_CreateEval(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -478,10 +459,9 @@ func TestWorker_CreateEval(t *testing.T) {
 func TestWorker_ReblockEval(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -505,7 +485,6 @@ func TestWorker_ReblockEval(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -521,10 +500,9 @@ func TestWorker_ReblockEval(t *testing.T) {
 func TestWorker_shouldResubmit(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -548,7 +526,6 @@ func TestWorker_shouldResubmit(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -564,10 +541,9 @@ func TestWorker_shouldResubmit(t *testing.T) {
 func TestWorker_backoffErr(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -592,7 +568,6 @@ func TestWorker_backoffErr(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -608,10 +583,9 @@ func TestWorker_backoffErr(t *testing.T) {
 func TestWorker_backoffReset(t *testing.T) {
 	type fields struct {
 		srv           *Server
-		logger        *log.Logger
+		logger        *logrus.Logger
 		start         time.Time
 		paused        bool
-		pauseLock     sync.Mutex
 		pauseCond     *sync.Cond
 		failures      uint
 		evalToken     string
@@ -630,7 +604,6 @@ func TestWorker_backoffReset(t *testing.T) {
 				logger:        tt.fields.logger,
 				start:         tt.fields.start,
 				paused:        tt.fields.paused,
-				pauseLock:     tt.fields.pauseLock,
 				pauseCond:     tt.fields.pauseCond,
 				failures:      tt.fields.failures,
 				evalToken:     tt.fields.evalToken,
@@ -0,0 +1,11 @@
+module github.com/siddontang/go-mysql
+
+go 1.12
+
+require (
+	github.com/opentracing/opentracing-go v1.1.0
+	github.com/pingcap/errors v0.11.4
+	github.com/satori/go.uuid v1.2.0
+	github.com/siddontang/go v0.0.0-20180604090527-bdc77568d726
+	github.com/siddontang/go-log v0.0.0-20180807004314-8d05993dda07
+)